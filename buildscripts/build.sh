@@ -29,6 +29,8 @@ _init() {
     CP=`which cp`
     SHASUM=`which shasum`
     SED=`which sed`
+    MINISIGN=`which minisign`
+    BSDIFF=`which bsdiff`
 }
 
 go_build() {
@@ -51,9 +53,29 @@ go_build() {
 
     # Create copy
     if [ $os == "windows" ]; then
-        $CP -p $release_bin ${release_real_bin}.exe
-    else
-        $CP -p $release_bin $release_real_bin
+        release_real_bin=${release_real_bin}.exe
+    fi
+    $CP -p $release_bin $release_real_bin
+
+    # Sign the downloadable binary, verified by ‘minio update --apply’
+    # with the key embedded through MINIO_RELEASE_PUBLIC_KEY. The
+    # trusted comment names the release as minio.shasum does.
+    if [ -n "$MINIO_RELEASE_SECRET_KEY" ]; then
+        $MINISIGN -S -l -s $MINIO_RELEASE_SECRET_KEY -t "$(basename $package).$release_tag" -m $release_real_bin -x ${release_real_bin}.minisig
+    fi
+
+    # Publish patches from the previous releases kept in the release
+    # directory, fetched by ‘minio update --apply’ instead of the whole
+    # binary when available.
+    if [ -n "$BSDIFF" ]; then
+        for old_bin in $release_str/$os-$arch/$(basename $package).RELEASE.*; do
+            [ -f "$old_bin" ] || continue
+            old_tag=${old_bin#$release_str/$os-$arch/$(basename $package).}
+            case $old_tag in
+                $release_tag|*.bsdiff|*.minisig) continue ;;
+            esac
+            $BSDIFF $old_bin $release_real_bin ${release_real_bin}.${old_tag}.bsdiff
+        done
     fi
 
     # Calculate shasum
@@ -33,6 +33,9 @@ func genLDFlags(version string) string {
 	ldflagsStr += " -X github.com/mf-00/newgo/cmd.CommitID=" + commitID()
 	ldflagsStr += " -X github.com/mf-00/newgo/cmd.ShortCommitID=" + commitID()[:12]
 	ldflagsStr += " -X github.com/mf-00/newgo/cmd.GOPATH=" + os.Getenv("GOPATH")
+	if pubKey := os.Getenv("MINIO_RELEASE_PUBLIC_KEY"); pubKey != "" {
+		ldflagsStr += " -X github.com/mf-00/newgo/cmd.ReleasePublicKey=" + pubKey
+	}
 	return ldflagsStr
 }
 
@@ -31,10 +31,11 @@ type credential struct {
 const (
 	minioAccessID = 20
 	minioSecretID = 40
-)
 
-// isValidSecretKey - validate secret key.
-var isValidSecretKey = regexp.MustCompile(`^.{8,40}$`)
+	// Number of secret keys generated in search of one meeting the
+	// secret key policy.
+	maxSecretKeyAttempts = 10
+)
 
 // isValidAccessKey - validate access key.
 var isValidAccessKey = regexp.MustCompile(`^[a-zA-Z0-9\\-\\.\\_\\~]{5,20}$`)
@@ -46,13 +47,22 @@ func mustGenAccessKeys() (creds credential) {
 	return creds
 }
 
-// genAccessKeys - generate access credentials.
+// genAccessKeys - generate access credentials, the secret key meets
+// the secret key policy.
 func genAccessKeys() (credential, error) {
 	accessKeyID, err := genAccessKeyID()
 	if err != nil {
 		return credential{}, err
 	}
-	secretAccessKey, err := genSecretAccessKey()
+	var secretAccessKey []byte
+	for i := 0; i < maxSecretKeyAttempts; i++ {
+		if secretAccessKey, err = genSecretAccessKey(); err != nil {
+			return credential{}, err
+		}
+		if err = globalSecretKeyPolicy.validate(string(secretAccessKey)); err == nil {
+			break
+		}
+	}
 	if err != nil {
 		return credential{}, err
 	}
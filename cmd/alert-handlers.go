@@ -0,0 +1,109 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package cmd
+
+// AlertRulesArgs - argument for AlertRules RPC handler.
+type AlertRulesArgs struct {
+	// Authentication token generated by Login.
+	GenericArgs
+
+	// Rule to add, replacing the rule of the same name if any.
+	Set *alertRule
+
+	// Name of the rule to remove.
+	Remove string
+}
+
+// AlertRulesReply - reply by AlertRules RPC handler.
+type AlertRulesReply struct {
+	// Alert rules of the cluster.
+	Rules []alertRule
+
+	// Errors of the nodes which failed to load the rules, keyed by
+	// node.
+	Errors map[string]string
+}
+
+// AlertRulesHandler - RPC control handler for `minio control alerts`,
+// adds or removes a stored alert rule as asked, then loads the rules
+// on this node, and on all the reachable nodes if args.Remote is set.
+func (c *controlAPIHandlers) AlertRulesHandler(args *AlertRulesArgs, reply *AlertRulesReply) (err error) {
+	defer encodeRPCError(&err)
+
+	if !isRPCTokenValid(args.Token, jwtAudienceAdmin) {
+		return errInvalidToken
+	}
+	objAPI := c.ObjectAPI()
+	if objAPI == nil {
+		return errServerNotInitialized
+	}
+	if args.Set != nil && args.Remove != "" {
+		return errInvalidArgument
+	}
+	if args.Set != nil {
+		if err = args.Set.validate(); err != nil {
+			return err
+		}
+	}
+	// Retries get the result of the first call.
+	if replayed, replayErr := globalIdempotentCalls.Begin("Control.AlertRulesHandler", args.IdempotencyKey, reply); replayed {
+		return replayErr
+	}
+	defer globalIdempotentCalls.End("Control.AlertRulesHandler", args.IdempotencyKey, reply, &err)
+
+	if args.Set != nil || args.Remove != "" {
+		err = updateAlertRules(objAPI, func(rules *alertRules) error {
+			for i, rule := range rules.Rules {
+				if args.Set != nil && rule.Name == args.Set.Name {
+					rules.Rules[i] = *args.Set
+					return nil
+				}
+				if rule.Name == args.Remove {
+					rules.Rules = append(rules.Rules[:i], rules.Rules[i+1:]...)
+					return nil
+				}
+			}
+			if args.Set == nil {
+				return errAlertRuleNotFound
+			}
+			rules.Rules = append(rules.Rules, *args.Set)
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+	if err = loadAlertRules(objAPI); err != nil {
+		return err
+	}
+
+	if args.Remote {
+		// Remote nodes load the rules just stored.
+		remoteControls := c.getRemoteControls()
+		remoteArgs := &AlertRulesArgs{}
+		errsMap := callRemoteControls(remoteControls, "Control.AlertRulesHandler", remoteArgs, func(index int) interface{} {
+			return &AlertRulesReply{}
+		})
+		for node, nodeErr := range errsMap {
+			if reply.Errors == nil {
+				reply.Errors = make(map[string]string)
+			}
+			reply.Errors[node] = nodeErr.Error()
+		}
+	}
+	reply.Rules = globalAlertEngine.Rules()
+	return nil
+}
@@ -0,0 +1,367 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+)
+
+const (
+	// Alert rules of the cluster are stored as 'alerts.json' in
+	// '.minio.sys/'.
+	alertRulesConfigFile = "alerts.json"
+
+	// Lock serializing updates of the alert rules, the object layer
+	// already locks 'alerts.json' itself.
+	alertRulesLockPath = "alerts.lock"
+
+	// Interval request metrics are evaluated at, thresholds of
+	// counts are per interval.
+	alertEvaluationInterval = time.Minute
+
+	// Time to wait for webhooks to accept an alert.
+	alertWebhookTimeout = 10 * time.Second
+)
+
+// Metrics alert rules are evaluated on.
+const (
+	// Percentage of the requests answered with a 5xx status.
+	alertMetric5xxRate = "5xx-rate"
+
+	// Failed authentications per minute.
+	alertMetricAuthFailures = "auth-failures"
+
+	// Bytes sent per minute by successful requests of a bucket.
+	alertMetricEgress = "egress"
+)
+
+// States of an alert.
+const (
+	alertFiring   = "firing"
+	alertResolved = "resolved"
+)
+
+var (
+	errInvalidAlertRule  = errors.New("Invalid alert rule")
+	errAlertRuleNotFound = errors.New("Alert rule not found")
+)
+
+// alertRule - fires an alert to target when a metric of this node
+// crosses the threshold, and again once it is resolved.
+type alertRule struct {
+	Name   string `json:"name"`
+	Metric string `json:"metric"`
+
+	// Bucket of egress rules, all the buckets if empty.
+	Bucket string `json:"bucket,omitempty"`
+
+	Threshold float64 `json:"threshold"`
+
+	// ARN of a notification target configured in config.json, or
+	// URL of a webhook alerts are POSTed to as JSON.
+	Target string `json:"target"`
+}
+
+// isWebhookTarget - returns true if alerts of the rule are sent to a
+// webhook rather than to a notification target.
+func (r alertRule) isWebhookTarget() bool {
+	u, err := url.Parse(r.Target)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
+
+// validate - returns errInvalidAlertRule unless the rule is valid.
+func (r alertRule) validate() error {
+	if r.Name == "" || r.Threshold < 0 {
+		return errInvalidAlertRule
+	}
+	switch r.Metric {
+	case alertMetric5xxRate, alertMetricAuthFailures:
+		if r.Bucket != "" {
+			return errInvalidAlertRule
+		}
+	case alertMetricEgress:
+		if r.Bucket != "" && !IsValidBucketName(r.Bucket) {
+			return errInvalidAlertRule
+		}
+	default:
+		return errInvalidAlertRule
+	}
+	if r.isWebhookTarget() {
+		return nil
+	}
+	if checkQueueARN(r.Target) != ErrNone || !isValidQueueID(r.Target) {
+		return errInvalidAlertRule
+	}
+	return nil
+}
+
+// alertRules - content of 'alerts.json'.
+type alertRules struct {
+	Version string      `json:"version"`
+	Rules   []alertRule `json:"rules"`
+}
+
+// alert - sent to the target of a rule when it fires or is resolved.
+type alert struct {
+	Rule      string  `json:"rule"`
+	Metric    string  `json:"metric"`
+	Bucket    string  `json:"bucket,omitempty"`
+	Value     float64 `json:"value"`
+	Threshold float64 `json:"threshold"`
+	State     string  `json:"state"`
+	Node      string  `json:"node"`
+	Time      string  `json:"time"`
+
+	target string
+}
+
+// alertEngine - evaluates the alert rules on the request metrics of
+// this node.
+type alertEngine struct {
+	mutex *sync.Mutex
+	rules []alertRule
+
+	// Buckets of the firing alerts keyed by rule name, alerts of
+	// rules on all the requests have an empty bucket.
+	firing map[string]map[string]bool
+}
+
+// Variable holding the alert rules of this node.
+var globalAlertEngine = newAlertEngine()
+
+// newAlertEngine - returns an alert engine without any rules.
+func newAlertEngine() *alertEngine {
+	return &alertEngine{
+		mutex:  &sync.Mutex{},
+		firing: make(map[string]map[string]bool),
+	}
+}
+
+// SetRules replaces the rules, alerts of the rules left unchanged
+// keep firing.
+func (e *alertEngine) SetRules(rules []alertRule) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	firing := make(map[string]map[string]bool)
+	for _, newRule := range rules {
+		for _, rule := range e.rules {
+			if rule == newRule && e.firing[rule.Name] != nil {
+				firing[rule.Name] = e.firing[rule.Name]
+			}
+		}
+	}
+	e.rules = rules
+	e.firing = firing
+}
+
+// Rules returns the current rules.
+func (e *alertEngine) Rules() []alertRule {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	return append([]alertRule(nil), e.rules...)
+}
+
+// Returns the value of the metric of rule for bucket in window.
+func getAlertMetric(window metricsWindow, rule alertRule, bucket string) float64 {
+	switch rule.Metric {
+	case alertMetric5xxRate:
+		if window.Requests == 0 {
+			return 0
+		}
+		return 100 * float64(window.ServerErrors) / float64(window.Requests)
+	case alertMetricAuthFailures:
+		return float64(window.AuthFailures)
+	case alertMetricEgress:
+		return float64(window.Egress[bucket])
+	}
+	return 0
+}
+
+// Evaluate returns the alerts fired or resolved by the metrics of window.
+func (e *alertEngine) Evaluate(window metricsWindow) []alert {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	var alerts []alert
+	for _, rule := range e.rules {
+		buckets := []string{rule.Bucket}
+		if rule.Metric == alertMetricEgress && rule.Bucket == "" {
+			// All the buckets sending data or with a firing alert.
+			seen := make(map[string]bool)
+			for bucket := range window.Egress {
+				seen[bucket] = true
+			}
+			for bucket := range e.firing[rule.Name] {
+				seen[bucket] = true
+			}
+			buckets = nil
+			for bucket := range seen {
+				buckets = append(buckets, bucket)
+			}
+			sort.Strings(buckets)
+		}
+		for _, bucket := range buckets {
+			value := getAlertMetric(window, rule, bucket)
+			firing := e.firing[rule.Name][bucket]
+			var state string
+			if value > rule.Threshold && !firing {
+				state = alertFiring
+				if e.firing[rule.Name] == nil {
+					e.firing[rule.Name] = make(map[string]bool)
+				}
+				e.firing[rule.Name][bucket] = true
+			} else if value <= rule.Threshold && firing {
+				state = alertResolved
+				delete(e.firing[rule.Name], bucket)
+			} else {
+				continue
+			}
+			alerts = append(alerts, alert{
+				Rule:      rule.Name,
+				Metric:    rule.Metric,
+				Bucket:    bucket,
+				Value:     value,
+				Threshold: rule.Threshold,
+				State:     state,
+				Node:      globalMinioAddr,
+				Time:      window.End.Format(timeFormatAMZ),
+				target:    rule.Target,
+			})
+		}
+	}
+	return alerts
+}
+
+// HTTP client for alert webhooks.
+var alertWebhookClient = &http.Client{
+	Timeout:   alertWebhookTimeout,
+	Transport: &outboundTransport{proxy: getNotifyProxyURL},
+}
+
+// sendAlert - sends an alert to the webhook or notification target of
+// its rule.
+func sendAlert(a alert) error {
+	if (alertRule{Target: a.target}).isWebhookTarget() {
+		data, err := json.Marshal(a)
+		if err != nil {
+			return err
+		}
+		resp, err := alertWebhookClient.Post(a.target, "application/json", bytes.NewReader(data))
+		if err != nil {
+			return err
+		}
+		resp.Body.Close()
+		if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+			return fmt.Errorf("Alert webhook %s returned %s", a.target, resp.Status)
+		}
+		return nil
+	}
+	if globalEventNotifier == nil {
+		return errInvalidAlertRule
+	}
+	targetLog := globalEventNotifier.GetExternalTarget(a.target)
+	if targetLog == nil {
+		return errInvalidAlertRule
+	}
+	// Key-value targets keep the last alert of every rule and bucket.
+	targetLog.WithFields(logrus.Fields{
+		"Key":       path.Join(minioMetaBucket, "alerts", a.Rule, a.Bucket),
+		"EventType": "minio:Alert:" + a.State,
+		"Records":   []alert{a},
+	}).Info()
+	return nil
+}
+
+// evaluateAlerts - evaluates the alert rules on the request metrics
+// every interval, never returns.
+func evaluateAlerts(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for now := range ticker.C {
+		for _, a := range globalAlertEngine.Evaluate(globalRequestMetrics.Rotate(now)) {
+			go func(a alert) {
+				errorIf(sendAlert(a), "Unable to send alert %s of rule %s.", a.State, a.Rule)
+			}(a)
+		}
+	}
+}
+
+// readAlertRules - returns the alert rules of the cluster, none if
+// they were never set.
+func readAlertRules(objAPI ObjectLayer) (*alertRules, error) {
+	rules := &alertRules{Version: "1"}
+	objInfo, err := objAPI.GetObjectInfo(minioMetaBucket, alertRulesConfigFile)
+	if err != nil {
+		if _, ok := errorCause(err).(ObjectNotFound); ok {
+			return rules, nil
+		}
+		return nil, errorCause(err)
+	}
+	var buffer bytes.Buffer
+	if err = objAPI.GetObject(minioMetaBucket, alertRulesConfigFile, 0, objInfo.Size, &buffer); err != nil {
+		return nil, errorCause(err)
+	}
+	if err = json.Unmarshal(buffer.Bytes(), rules); err != nil {
+		return nil, err
+	}
+	return rules, nil
+}
+
+// writeAlertRules - saves the alert rules of the cluster.
+func writeAlertRules(objAPI ObjectLayer, rules *alertRules) error {
+	buf, err := json.Marshal(rules)
+	if err != nil {
+		return err
+	}
+	_, err = objAPI.PutObject(minioMetaBucket, alertRulesConfigFile, int64(len(buf)), bytes.NewReader(buf), nil, "")
+	return errorCause(err)
+}
+
+// updateAlertRules - applies update to the stored alert rules.
+func updateAlertRules(objAPI ObjectLayer, update func(rules *alertRules) error) error {
+	opsID := getOpsID()
+	nsMutex.Lock(minioMetaBucket, alertRulesLockPath, opsID)
+	defer nsMutex.Unlock(minioMetaBucket, alertRulesLockPath, opsID)
+
+	rules, err := readAlertRules(objAPI)
+	if err != nil {
+		return err
+	}
+	if err = update(rules); err != nil {
+		return err
+	}
+	return writeAlertRules(objAPI, rules)
+}
+
+// loadAlertRules - loads the stored alert rules into the alert engine.
+func loadAlertRules(objAPI ObjectLayer) error {
+	rules, err := readAlertRules(objAPI)
+	if err != nil {
+		return err
+	}
+	globalAlertEngine.SetRules(rules.Rules)
+	return nil
+}
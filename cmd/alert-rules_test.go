@@ -0,0 +1,180 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package cmd
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// Tests validating alert rules.
+func TestAlertRuleValidate(t *testing.T) {
+	root, err := newTestConfig("us-east-1")
+	if err != nil {
+		t.Fatalf("Unable to initialize test config %s", err)
+	}
+	defer removeAll(root)
+
+	webhook := "https://alerts.example.com/minio"
+	testCases := []struct {
+		rule  alertRule
+		valid bool
+	}{
+		{alertRule{Name: "errors", Metric: alertMetric5xxRate, Threshold: 5, Target: webhook}, true},
+		{alertRule{Name: "auth", Metric: alertMetricAuthFailures, Threshold: 100, Target: "http://localhost:8080/"}, true},
+		{alertRule{Name: "egress", Metric: alertMetricEgress, Bucket: "photos", Threshold: 1 << 30, Target: webhook}, true},
+		{alertRule{Name: "egress", Metric: alertMetricEgress, Target: webhook}, true},
+		// Missing name.
+		{alertRule{Metric: alertMetric5xxRate, Threshold: 5, Target: webhook}, false},
+		// Unknown metric.
+		{alertRule{Name: "latency", Metric: "latency", Threshold: 5, Target: webhook}, false},
+		// Negative threshold.
+		{alertRule{Name: "errors", Metric: alertMetric5xxRate, Threshold: -1, Target: webhook}, false},
+		// Bucket of a metric of all the requests.
+		{alertRule{Name: "errors", Metric: alertMetric5xxRate, Bucket: "photos", Target: webhook}, false},
+		{alertRule{Name: "egress", Metric: alertMetricEgress, Bucket: "ph", Target: webhook}, false},
+		// Targets which are neither webhooks nor configured ARNs.
+		{alertRule{Name: "errors", Metric: alertMetric5xxRate, Target: "ftp://alerts.example.com/"}, false},
+		{alertRule{Name: "errors", Metric: alertMetric5xxRate, Target: "arn:minio:sqs:us-east-1:1:amqp"}, false},
+	}
+	for i, testCase := range testCases {
+		err := testCase.rule.validate()
+		if testCase.valid && err != nil {
+			t.Errorf("Test %d: Expected rule to be valid, got %v", i+1, err)
+		}
+		if !testCase.valid && err != errInvalidAlertRule {
+			t.Errorf("Test %d: Expected %v, got %v", i+1, errInvalidAlertRule, err)
+		}
+	}
+}
+
+// Tests alerts fire once their metric crosses the threshold and are
+// resolved once it is back under.
+func TestAlertEngineEvaluate(t *testing.T) {
+	e := newAlertEngine()
+	errorsRule := alertRule{Name: "errors", Metric: alertMetric5xxRate, Threshold: 10, Target: "http://localhost/"}
+	egressRule := alertRule{Name: "egress", Metric: alertMetricEgress, Threshold: 100, Target: "http://localhost/"}
+	e.SetRules([]alertRule{errorsRule, egressRule})
+
+	getStates := func(alerts []alert) map[string]string {
+		states := make(map[string]string)
+		for _, a := range alerts {
+			states[a.Rule+"/"+a.Bucket] = a.State
+		}
+		return states
+	}
+	testCases := []struct {
+		window metricsWindow
+		states map[string]string
+	}{
+		// Under the thresholds.
+		{metricsWindow{Requests: 100, ServerErrors: 10, Egress: map[string]int64{"photos": 100}}, map[string]string{}},
+		// Crossing the thresholds.
+		{
+			metricsWindow{Requests: 100, ServerErrors: 11, Egress: map[string]int64{"photos": 101, "songs": 50}},
+			map[string]string{"errors/": alertFiring, "egress/photos": alertFiring},
+		},
+		// Still beyond the thresholds, alerts are not repeated.
+		{metricsWindow{Requests: 10, ServerErrors: 5, Egress: map[string]int64{"photos": 500}}, map[string]string{}},
+		// Back under the thresholds, egress of photos stopped.
+		{
+			metricsWindow{Egress: map[string]int64{"songs": 200}},
+			map[string]string{"errors/": alertResolved, "egress/photos": alertResolved, "egress/songs": alertFiring},
+		},
+	}
+	for i, testCase := range testCases {
+		states := getStates(e.Evaluate(testCase.window))
+		if !reflect.DeepEqual(states, testCase.states) {
+			t.Errorf("Test %d: Expected %v, got %v", i+1, testCase.states, states)
+		}
+	}
+
+	// Unchanged rules keep firing, changed ones start over.
+	egressRule.Threshold = 300
+	e.SetRules([]alertRule{egressRule})
+	states := getStates(e.Evaluate(metricsWindow{Egress: map[string]int64{"songs": 400}}))
+	if !reflect.DeepEqual(states, map[string]string{"egress/songs": alertFiring}) {
+		t.Errorf("Unexpected alerts %v", states)
+	}
+	e.SetRules([]alertRule{egressRule})
+	states = getStates(e.Evaluate(metricsWindow{Egress: map[string]int64{"songs": 400}}))
+	if len(states) != 0 {
+		t.Errorf("Unexpected alerts %v", states)
+	}
+}
+
+// Tests alerts are POSTed to webhooks.
+func TestSendAlertWebhook(t *testing.T) {
+	alerts := make(chan alert, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var a alert
+		if err := json.NewDecoder(r.Body).Decode(&a); err != nil || r.Method != "POST" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		alerts <- a
+	}))
+	defer server.Close()
+
+	e := newAlertEngine()
+	e.SetRules([]alertRule{{Name: "auth", Metric: alertMetricAuthFailures, Threshold: 2, Target: server.URL}})
+	fired := e.Evaluate(metricsWindow{AuthFailures: 3, End: time.Now()})
+	if len(fired) != 1 {
+		t.Fatalf("Expected 1 alert, got %d", len(fired))
+	}
+	if err := sendAlert(fired[0]); err != nil {
+		t.Fatalf("Unable to send alert: %v", err)
+	}
+	a := <-alerts
+	if a.Rule != "auth" || a.State != alertFiring || a.Value != 3 || a.Threshold != 2 {
+		t.Errorf("Unexpected alert %+v", a)
+	}
+
+	// Failures of webhooks are reported.
+	fired[0].target = server.URL + "/missing"
+	server.Config.Handler = http.NotFoundHandler()
+	if err := sendAlert(fired[0]); err == nil {
+		t.Error("Expected sending the alert to fail")
+	}
+}
+
+// Wrapper for calling alert rules store tests for both XL and FS.
+func TestAlertRulesStore(t *testing.T) {
+	ExecObjectLayerTest(t, testAlertRulesStore)
+}
+
+// Tests alert rules are stored and loaded.
+func testAlertRulesStore(obj ObjectLayer, instanceType string, t TestErrHandler) {
+	defer globalAlertEngine.SetRules(nil)
+	rule := alertRule{Name: "errors", Metric: alertMetric5xxRate, Threshold: 5, Target: "http://localhost/"}
+	err := updateAlertRules(obj, func(rules *alertRules) error {
+		rules.Rules = append(rules.Rules, rule)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("%s: Unable to update alert rules: %v", instanceType, err)
+	}
+	if err = loadAlertRules(obj); err != nil {
+		t.Fatalf("%s: Unable to load alert rules: %v", instanceType, err)
+	}
+	if rules := globalAlertEngine.Rules(); !reflect.DeepEqual(rules, []alertRule{rule}) {
+		t.Errorf("%s: Expected %v, got %v", instanceType, []alertRule{rule}, rules)
+	}
+}
@@ -108,6 +108,12 @@ const (
 	ErrInvalidQuerySignatureAlgo
 	ErrInvalidQueryParams
 	ErrBucketAlreadyOwnedByYou
+	ErrBucketAlreadyExists
+	ErrSlowDown
+	ErrTooManyBuckets
+	ErrInvalidEncryptionMethod
+	ErrNoSuchEncryptionConfiguration
+	ErrIncorrectContinuationToken
 	// Add new error codes here.
 
 	// Bucket notification related errors.
@@ -120,6 +126,7 @@ const (
 	ErrFilterNameSuffix
 	ErrFilterValueInvalid
 	ErrOverlappingConfigs
+	ErrNotificationDuplicateID
 
 	// S3 extended errors.
 	ErrContentSHA256Mismatch
@@ -134,6 +141,24 @@ const (
 	ErrPolicyNesting
 	ErrInvalidObjectName
 	ErrServerNotInitialized
+	ErrNoSuchBucketSettings
+	ErrInvalidBucketSettings
+	ErrTransformFailed
+	ErrInvalidThumbnailSize
+	ErrThumbnailNotSupported
+	ErrInvalidDedupSettings
+	ErrObjectImmutable
+	ErrInvalidRetentionSettings
+	ErrInvalidSearchQuery
+	ErrSearchNotEnabled
+	ErrObjectAlreadyExists
+	ErrBucketNameNotAllowed
+	ErrInvalidContentRange
+	ErrResumableOffsetMismatch
+	ErrInvalidArchiveFormat
+	ErrInvalidArchive
+	ErrSSENotConfigured
+	ErrObjectCorrupted
 	// Add new extended error codes here.
 	// Please open a https://github.com/minio/minio/issues before adding
 	// new error codes here.
@@ -465,6 +490,36 @@ var errorCodeResponse = map[APIErrorCode]APIError{
 		Description:    "Your previous request to create the named bucket succeeded and you already own it.",
 		HTTPStatusCode: http.StatusConflict,
 	},
+	ErrBucketAlreadyExists: {
+		Code:           "BucketAlreadyExists",
+		Description:    "The requested bucket name is not available. The bucket namespace is shared by all users of the system. Please select a different name and try again.",
+		HTTPStatusCode: http.StatusConflict,
+	},
+	ErrSlowDown: {
+		Code:           "SlowDown",
+		Description:    "Too many failed authentication attempts, please reduce your request rate.",
+		HTTPStatusCode: http.StatusServiceUnavailable,
+	},
+	ErrTooManyBuckets: {
+		Code:           "TooManyBuckets",
+		Description:    "You have attempted to create more buckets than allowed.",
+		HTTPStatusCode: http.StatusBadRequest,
+	},
+	ErrInvalidEncryptionMethod: {
+		Code:           "InvalidEncryptionAlgorithmError",
+		Description:    "The encryption request you specified is not valid. The valid value is AES256.",
+		HTTPStatusCode: http.StatusBadRequest,
+	},
+	ErrNoSuchEncryptionConfiguration: {
+		Code:           "ServerSideEncryptionConfigurationNotFoundError",
+		Description:    "The server side encryption configuration was not found.",
+		HTTPStatusCode: http.StatusNotFound,
+	},
+	ErrIncorrectContinuationToken: {
+		Code:           "InvalidArgument",
+		Description:    "The continuation token provided is incorrect",
+		HTTPStatusCode: http.StatusBadRequest,
+	},
 
 	/// Bucket notification related errors.
 	ErrEventNotification: {
@@ -512,6 +567,11 @@ var errorCodeResponse = map[APIErrorCode]APIError{
 		Description:    "Configurations overlap. Configurations on the same bucket cannot share a common event type.",
 		HTTPStatusCode: http.StatusBadRequest,
 	},
+	ErrNotificationDuplicateID: {
+		Code:           "InvalidArgument",
+		Description:    "Configuration Ids must be unique.",
+		HTTPStatusCode: http.StatusBadRequest,
+	},
 
 	/// S3 extensions.
 	ErrContentSHA256Mismatch: {
@@ -524,7 +584,7 @@ var errorCodeResponse = map[APIErrorCode]APIError{
 	ErrStorageFull: {
 		Code:           "XMinioStorageFull",
 		Description:    "Storage backend has reached its minimum free disk threshold. Please delete few objects to proceed.",
-		HTTPStatusCode: http.StatusInternalServerError,
+		HTTPStatusCode: http.StatusInsufficientStorage,
 	},
 	ErrObjectExistsAsDirectory: {
 		Code:           "XMinioObjectExistsAsDirectory",
@@ -556,6 +616,96 @@ var errorCodeResponse = map[APIErrorCode]APIError{
 		Description:    "Server not initialized, please try again.",
 		HTTPStatusCode: http.StatusServiceUnavailable,
 	},
+	ErrNoSuchBucketSettings: {
+		Code:           "XMinioNoSuchBucketSettings",
+		Description:    "The bucket settings do not exist.",
+		HTTPStatusCode: http.StatusNotFound,
+	},
+	ErrInvalidBucketSettings: {
+		Code:           "XMinioInvalidBucketSettings",
+		Description:    "The bucket settings document is malformed or has invalid values.",
+		HTTPStatusCode: http.StatusBadRequest,
+	},
+	ErrTransformFailed: {
+		Code:           "XMinioTransformFailed",
+		Description:    "The transformation webhook of the bucket failed to transform the object.",
+		HTTPStatusCode: http.StatusBadGateway,
+	},
+	ErrInvalidThumbnailSize: {
+		Code:           "XMinioInvalidThumbnailSize",
+		Description:    "The thumbnail size must be of the form WxH, with a width and height between 1 and 1024.",
+		HTTPStatusCode: http.StatusBadRequest,
+	},
+	ErrThumbnailNotSupported: {
+		Code:           "XMinioThumbnailNotSupported",
+		Description:    "Thumbnails are only generated for JPEG, PNG and GIF images up to 20MiB and 50 megapixels.",
+		HTTPStatusCode: http.StatusBadRequest,
+	},
+	ErrInvalidDedupSettings: {
+		Code:           "XMinioInvalidDedupSettings",
+		Description:    "Deduplication can only be enabled on empty buckets and cannot be disabled.",
+		HTTPStatusCode: http.StatusConflict,
+	},
+	ErrObjectImmutable: {
+		Code:           "XMinioObjectImmutable",
+		Description:    "Objects of this bucket cannot be overwritten, nor deleted before their retention elapses.",
+		HTTPStatusCode: http.StatusForbidden,
+	},
+	ErrInvalidRetentionSettings: {
+		Code:           "XMinioInvalidRetentionSettings",
+		Description:    "The retention of a bucket cannot be removed or shortened.",
+		HTTPStatusCode: http.StatusConflict,
+	},
+	ErrInvalidSearchQuery: {
+		Code:           "XMinioInvalidSearchQuery",
+		Description:    "The search query is malformed or has invalid values.",
+		HTTPStatusCode: http.StatusBadRequest,
+	},
+	ErrSearchNotEnabled: {
+		Code:           "XMinioSearchNotEnabled",
+		Description:    "Search is not enabled in the settings of this bucket.",
+		HTTPStatusCode: http.StatusBadRequest,
+	},
+	ErrObjectAlreadyExists: {
+		Code:           "XMinioObjectAlreadyExists",
+		Description:    "The object was created again since it was deleted and cannot be undeleted.",
+		HTTPStatusCode: http.StatusConflict,
+	},
+	ErrBucketNameNotAllowed: {
+		Code:           "XMinioBucketNameNotAllowed",
+		Description:    "The specified bucket name is not allowed by the bucket naming policy of the server.",
+		HTTPStatusCode: http.StatusBadRequest,
+	},
+	ErrInvalidContentRange: {
+		Code:           "XMinioInvalidContentRange",
+		Description:    "The Content-Range header is missing, invalid or does not match the size of the object or the chunk.",
+		HTTPStatusCode: http.StatusBadRequest,
+	},
+	ErrResumableOffsetMismatch: {
+		Code:           "XMinioResumableOffsetMismatch",
+		Description:    "The chunk does not start at the end of the uploaded data, resume from the end of the Range returned.",
+		HTTPStatusCode: http.StatusConflict,
+	},
+	ErrInvalidArchiveFormat: {
+		Code:           "XMinioInvalidArchiveFormat",
+		Description:    "The archive format must be tar or zip.",
+		HTTPStatusCode: http.StatusBadRequest,
+	},
+	ErrInvalidArchive: {
+		Code:           "XMinioInvalidArchive",
+		Description:    "The archive is malformed or has entries named outside of the target prefix.",
+		HTTPStatusCode: http.StatusBadRequest,
+	},
+	ErrSSENotConfigured: {
+		Code:           "XMinioSSENotConfigured",
+		Description:    "Server side encryption is not enabled on this server, MINIO_SSE_MASTER_KEY is not set.",
+		HTTPStatusCode: http.StatusNotImplemented,
+	},
+	ErrObjectCorrupted: {
+		Code:           "XMinioObjectCorrupted",
+		Description:    "The object is corrupted on some disks, it is being healed. Please try again later.",
+		HTTPStatusCode: http.StatusInternalServerError,
+	},
 	// Add your error structure here.
 }
 
@@ -573,6 +723,26 @@ func toAPIErrorCode(err error) (apiErr APIErrorCode) {
 		apiErr = ErrSignatureDoesNotMatch
 	case errContentSHA256Mismatch:
 		apiErr = ErrContentSHA256Mismatch
+	case errDedupSettingsChange:
+		apiErr = ErrInvalidDedupSettings
+	case errRetentionSettingsChange:
+		apiErr = ErrInvalidRetentionSettings
+	case errInvalidSearchQuery:
+		apiErr = ErrInvalidSearchQuery
+	case errSearchNotEnabled:
+		apiErr = ErrSearchNotEnabled
+	case errInvalidContentRange:
+		apiErr = ErrInvalidContentRange
+	case errResumableOffsetMismatch:
+		apiErr = ErrResumableOffsetMismatch
+	case errInvalidArchive:
+		apiErr = ErrInvalidArchive
+	case errInvalidEncryptionMethod:
+		apiErr = ErrInvalidEncryptionMethod
+	case errSSENotConfigured:
+		apiErr = ErrSSENotConfigured
+	case errUnencryptedUpload:
+		apiErr = ErrAccessDenied
 	}
 	if apiErr != ErrNone {
 		// If there was a match in the above switch case.
@@ -595,8 +765,20 @@ func toAPIErrorCode(err error) (apiErr APIErrorCode) {
 		apiErr = ErrBucketNotEmpty
 	case BucketExists:
 		apiErr = ErrBucketAlreadyOwnedByYou
+	case BucketAlreadyExists:
+		apiErr = ErrBucketAlreadyExists
+	case BucketNameNotAllowed:
+		apiErr = ErrBucketNameNotAllowed
+	case TooManyBuckets:
+		apiErr = ErrTooManyBuckets
 	case ObjectNotFound:
 		apiErr = ErrNoSuchKey
+	case ObjectImmutable:
+		apiErr = ErrObjectImmutable
+	case ObjectCorrupted:
+		apiErr = ErrObjectCorrupted
+	case ObjectAlreadyExists:
+		apiErr = ErrObjectAlreadyExists
 	case ObjectNameInvalid:
 		apiErr = ErrInvalidObjectName
 	case InvalidUploadID:
@@ -613,6 +795,8 @@ func toAPIErrorCode(err error) (apiErr APIErrorCode) {
 		apiErr = ErrNotImplemented
 	case InvalidUploadIDKeyCombination:
 		apiErr = ErrNotImplemented
+	case NotImplemented:
+		apiErr = ErrNotImplemented
 	case MalformedUploadID:
 		apiErr = ErrNoSuchUpload
 	case PartTooSmall:
@@ -17,8 +17,11 @@
 package cmd
 
 import (
+	"encoding/base64"
+	"net/http"
 	"net/url"
 	"strconv"
+	"strings"
 )
 
 // Parse bucket url queries
@@ -51,6 +54,30 @@ func getListObjectsV2Args(values url.Values) (prefix, token, startAfter, delimit
 	return
 }
 
+// Version of the continuation token format, bumped whenever the
+// cursor embedded in it changes.
+const continuationTokenVersion = "1"
+
+// encodeContinuationToken - encodes the marker to resume listing from
+// as an opaque and URL safe ListObjects V2 continuation token, so that
+// key names round-trip regardless of the characters in them.
+func encodeContinuationToken(marker string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(continuationTokenVersion + marker))
+}
+
+// decodeContinuationToken - returns the marker embedded in a token
+// generated by encodeContinuationToken.
+func decodeContinuationToken(token string) (marker string, err error) {
+	buf, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return "", errInvalidArgument
+	}
+	if !strings.HasPrefix(string(buf), continuationTokenVersion) {
+		return "", errInvalidArgument
+	}
+	return strings.TrimPrefix(string(buf), continuationTokenVersion), nil
+}
+
 // Parse bucket url queries for ?uploads
 func getBucketMultipartResources(values url.Values) (prefix, keyMarker, uploadIDMarker, delimiter string, maxUploads int, encodingType string) {
 	prefix = values.Get("prefix")
@@ -79,6 +106,36 @@ func getObjectResources(values url.Values) (uploadID string, partNumberMarker, m
 	return
 }
 
+// Parse object attributes request headers.
+func getObjectAttributesResources(header http.Header) (attributes map[string]bool, partNumberMarker, maxParts int, apiErr APIErrorCode) {
+	attributes = make(map[string]bool)
+	for _, attrs := range header["X-Amz-Object-Attributes"] {
+		for _, attr := range strings.Split(attrs, ",") {
+			attributes[strings.TrimSpace(attr)] = true
+		}
+	}
+	// All attributes are returned if none were requested.
+	if len(attributes) == 0 {
+		for _, attr := range []string{"ETag", "ObjectParts", "StorageClass", "ObjectSize"} {
+			attributes[attr] = true
+		}
+	}
+	maxParts = maxPartsList
+	if maxPartsStr := header.Get("X-Amz-Max-Parts"); maxPartsStr != "" {
+		var err error
+		if maxParts, err = strconv.Atoi(maxPartsStr); err != nil || maxParts < 0 {
+			return nil, 0, 0, ErrInvalidMaxParts
+		}
+	}
+	if markerStr := header.Get("X-Amz-Part-Number-Marker"); markerStr != "" {
+		var err error
+		if partNumberMarker, err = strconv.Atoi(markerStr); err != nil || partNumberMarker < 0 {
+			return nil, 0, 0, ErrInvalidPartNumberMarker
+		}
+	}
+	return attributes, partNumberMarker, maxParts, ErrNone
+}
+
 // Parse listen bucket notification resources.
 func getListenBucketNotificationResources(values url.Values) (prefix []string, suffix []string, events []string) {
 	prefix = values["prefix"]
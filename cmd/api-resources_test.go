@@ -224,3 +224,27 @@ func TestValidateFilterValues(t *testing.T) {
 		}
 	}
 }
+
+// Tests round-tripping markers through continuation tokens.
+func TestContinuationToken(t *testing.T) {
+	for i, marker := range []string{"", "object", "a/b c&d=e+f", "a%2Fb?c#d", "été/文件"} {
+		token := encodeContinuationToken(marker)
+		if url.QueryEscape(token) != token {
+			t.Errorf("Test %d: Expected token %s to be URL safe", i+1, token)
+		}
+		decoded, err := decodeContinuationToken(token)
+		if err != nil {
+			t.Fatalf("Test %d: Unexpected error %v", i+1, err)
+		}
+		if decoded != marker {
+			t.Errorf("Test %d: Expected marker %s, got %s", i+1, marker, decoded)
+		}
+	}
+
+	// Tokens not generated by encodeContinuationToken are rejected.
+	for i, token := range []string{"object", "b2JqZWN0", "!!"} {
+		if _, err := decodeContinuationToken(token); err != errInvalidArgument {
+			t.Errorf("Test %d: Expected %v, got %v", i+1, errInvalidArgument, err)
+		}
+	}
+}
@@ -196,6 +196,10 @@ type Object struct {
 
 	// The class of storage used to store the object.
 	StorageClass string
+
+	// User metadata of the object, only set on requests with
+	// X-Minio-Extract (Minio extension).
+	UserMetadata *UserMetadata `xml:",omitempty"`
 }
 
 // CopyObjectResponse container returns ETag and LastModified of the successfully copied object
@@ -233,6 +237,37 @@ type CompleteMultipartUploadResponse struct {
 	ETag     string
 }
 
+// ObjectAttributesPart container for part metadata of a multipart object.
+type ObjectAttributesPart struct {
+	PartNumber int
+	Size       int64
+	// Minio extension, md5sum of the part used to compute the
+	// multipart ETag of the object.
+	ETag string
+}
+
+// ObjectAttributesParts container for all the parts of a multipart object.
+type ObjectAttributesParts struct {
+	TotalPartsCount      int
+	PartNumberMarker     int
+	NextPartNumberMarker int
+	MaxParts             int
+	IsTruncated          bool
+
+	// List of parts.
+	Parts []ObjectAttributesPart `xml:"Part"`
+}
+
+// GetObjectAttributesResponse - format for get object attributes response.
+type GetObjectAttributesResponse struct {
+	XMLName xml.Name `xml:"http://s3.amazonaws.com/doc/2006-03-01/ GetObjectAttributesOutput" json:"-"`
+
+	ETag         string                 `xml:"ETag,omitempty"`
+	ObjectParts  *ObjectAttributesParts `xml:"ObjectParts,omitempty"`
+	StorageClass string                 `xml:"StorageClass,omitempty"`
+	ObjectSize   *int64                 `xml:"ObjectSize,omitempty"`
+}
+
 // DeleteError structure.
 type DeleteError struct {
 	Code    string
@@ -369,7 +404,9 @@ func generateListObjectsV2Response(bucket, prefix, token, startAfter, delimiter
 	data.Prefix = prefix
 	data.MaxKeys = maxKeys
 	data.ContinuationToken = token
-	data.NextContinuationToken = resp.NextMarker
+	if resp.IsTruncated && resp.NextMarker != "" {
+		data.NextContinuationToken = encodeContinuationToken(resp.NextMarker)
+	}
 	data.IsTruncated = resp.IsTruncated
 	for _, prefix := range resp.Prefixes {
 		var prefixItem = CommonPrefix{}
@@ -438,6 +475,47 @@ func generateListPartsResponse(partsInfo ListPartsInfo) ListPartsResponse {
 	return listPartsResponse
 }
 
+// generateGetObjectAttributesResponse
+func generateGetObjectAttributesResponse(objInfo ObjectInfo, parts []objectPartInfo, attributes map[string]bool, partNumberMarker, maxParts int) GetObjectAttributesResponse {
+	attrsResponse := GetObjectAttributesResponse{}
+	if attributes["ETag"] {
+		attrsResponse.ETag = objInfo.MD5Sum
+	}
+	if attributes["StorageClass"] {
+		attrsResponse.StorageClass = "STANDARD"
+	}
+	if attributes["ObjectSize"] {
+		size := objInfo.Size
+		attrsResponse.ObjectSize = &size
+	}
+	// Parts are only meaningful for objects uploaded using multipart.
+	if !attributes["ObjectParts"] || !isMultipartETag(objInfo.MD5Sum) {
+		return attrsResponse
+	}
+	objectParts := &ObjectAttributesParts{
+		TotalPartsCount:  len(parts),
+		PartNumberMarker: partNumberMarker,
+		MaxParts:         maxParts,
+	}
+	for _, part := range parts {
+		if part.Number <= partNumberMarker {
+			continue
+		}
+		if len(objectParts.Parts) == maxParts {
+			objectParts.IsTruncated = true
+			break
+		}
+		objectParts.Parts = append(objectParts.Parts, ObjectAttributesPart{
+			PartNumber: part.Number,
+			Size:       part.Size,
+			ETag:       part.ETag,
+		})
+		objectParts.NextPartNumberMarker = part.Number
+	}
+	attrsResponse.ObjectParts = objectParts
+	return attrsResponse
+}
+
 // generateListMultipartUploadsResponse
 func generateListMultipartUploadsResponse(bucket string, multipartsInfo ListMultipartsInfo) ListMultipartUploadsResponse {
 	listMultipartUploadsResponse := ListMultipartUploadsResponse{}
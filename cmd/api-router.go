@@ -41,16 +41,32 @@ func registerAPIRouter(mux *router.Router) {
 
 	/// Object operations
 
+	// HeadResumableUpload (minio extension)
+	bucket.Methods("HEAD").Path("/{object:.+}").HandlerFunc(api.HeadResumableUploadHandler).Queries("resumable", "{resumable:.*}")
 	// HeadObject
 	bucket.Methods("HEAD").Path("/{object:.+}").HandlerFunc(api.HeadObjectHandler)
+	// PutResumableUpload (minio extension)
+	bucket.Methods("PUT").Path("/{object:.+}").HandlerFunc(api.PutResumableUploadHandler).Queries("resumable", "{resumable:.*}")
 	// PutObjectPart
 	bucket.Methods("PUT").Path("/{object:.+}").HandlerFunc(api.PutObjectPartHandler).Queries("partNumber", "{partNumber:[0-9]+}", "uploadId", "{uploadId:.*}")
+	// GetObjectAttributes
+	bucket.Methods("GET").Path("/{object:.+}").HandlerFunc(api.GetObjectAttributesHandler).Queries("attributes", "")
+	// GetArchive (minio extension)
+	bucket.Methods("GET").Path("/{object:.+}").HandlerFunc(api.GetArchiveHandler).Queries("archive", "{archive:.*}")
+	// GetObjectThumbnail
+	bucket.Methods("GET").Path("/{object:.+}").HandlerFunc(api.GetObjectThumbnailHandler).Queries("thumbnail", "{thumbnail:.*}")
 	// ListObjectPxarts
 	bucket.Methods("GET").Path("/{object:.+}").HandlerFunc(api.ListObjectPartsHandler).Queries("uploadId", "{uploadId:.*}")
 	// CompleteMultipartUpload
 	bucket.Methods("POST").Path("/{object:.+}").HandlerFunc(api.CompleteMultipartUploadHandler).Queries("uploadId", "{uploadId:.*}")
 	// NewMultipartUpload
 	bucket.Methods("POST").Path("/{object:.+}").HandlerFunc(api.NewMultipartUploadHandler).Queries("uploads", "")
+	// NewResumableUpload (minio extension)
+	bucket.Methods("POST").Path("/{object:.+}").HandlerFunc(api.NewResumableUploadHandler).Queries("resumable", "")
+	// UndeleteObject (minio extension)
+	bucket.Methods("POST").Path("/{object:.+}").HandlerFunc(api.UndeleteObjectHandler).Queries("undelete", "")
+	// AbortResumableUpload (minio extension)
+	bucket.Methods("DELETE").Path("/{object:.+}").HandlerFunc(api.AbortResumableUploadHandler).Queries("resumable", "{resumable:.*}")
 	// AbortMultipartUpload
 	bucket.Methods("DELETE").Path("/{object:.+}").HandlerFunc(api.AbortMultipartUploadHandler).Queries("uploadId", "{uploadId:.*}")
 	// GetObject
@@ -68,6 +84,14 @@ func registerAPIRouter(mux *router.Router) {
 	bucket.Methods("GET").HandlerFunc(api.GetBucketLocationHandler).Queries("location", "")
 	// GetBucketPolicy
 	bucket.Methods("GET").HandlerFunc(api.GetBucketPolicyHandler).Queries("policy", "")
+	// GetBucketSettings (minio extension)
+	bucket.Methods("GET").HandlerFunc(api.GetBucketSettingsHandler).Queries("settings", "")
+	// GetBucketEncryption
+	bucket.Methods("GET").HandlerFunc(api.GetBucketEncryptionHandler).Queries("encryption", "")
+	// ListBucketTrash (minio extension)
+	bucket.Methods("GET").HandlerFunc(api.ListBucketTrashHandler).Queries("trash", "")
+	// GetArchive of the whole bucket (minio extension)
+	bucket.Methods("GET").HandlerFunc(api.GetArchiveHandler).Queries("archive", "{archive:.*}")
 	// GetBucketNotification
 	bucket.Methods("GET").HandlerFunc(api.GetBucketNotificationHandler).Queries("notification", "")
 	// ListenBucketNotification
@@ -80,23 +104,35 @@ func registerAPIRouter(mux *router.Router) {
 	bucket.Methods("GET").HandlerFunc(api.ListObjectsV1Handler)
 	// PutBucketPolicy
 	bucket.Methods("PUT").HandlerFunc(api.PutBucketPolicyHandler).Queries("policy", "")
+	// PutBucketSettings (minio extension)
+	bucket.Methods("PUT").HandlerFunc(api.PutBucketSettingsHandler).Queries("settings", "")
+	// PutBucketEncryption
+	bucket.Methods("PUT").HandlerFunc(api.PutBucketEncryptionHandler).Queries("encryption", "")
 	// PutBucketNotification
 	bucket.Methods("PUT").HandlerFunc(api.PutBucketNotificationHandler).Queries("notification", "")
 	// PutBucket
 	bucket.Methods("PUT").HandlerFunc(api.PutBucketHandler)
 	// HeadBucket
 	bucket.Methods("HEAD").HandlerFunc(api.HeadBucketHandler)
+	// SearchObjects (minio extension)
+	bucket.Methods("POST").HandlerFunc(api.SearchObjectsHandler).Queries("search", "")
 	// PostPolicy
 	bucket.Methods("POST").HeadersRegexp("Content-Type", "multipart/form-data*").HandlerFunc(api.PostPolicyBucketHandler)
 	// DeleteMultipleObjects
 	bucket.Methods("POST").HandlerFunc(api.DeleteMultipleObjectsHandler)
 	// DeleteBucketPolicy
 	bucket.Methods("DELETE").HandlerFunc(api.DeleteBucketPolicyHandler).Queries("policy", "")
+	// DeleteBucketSettings (minio extension)
+	bucket.Methods("DELETE").HandlerFunc(api.DeleteBucketSettingsHandler).Queries("settings", "")
+	// DeleteBucketEncryption
+	bucket.Methods("DELETE").HandlerFunc(api.DeleteBucketEncryptionHandler).Queries("encryption", "")
 	// DeleteBucket
 	bucket.Methods("DELETE").HandlerFunc(api.DeleteBucketHandler)
 
 	/// Root operation
 
+	// ListErrorCodes
+	apiRouter.Methods("GET").HandlerFunc(api.ListErrorCodesHandler).Queries("errors", "")
 	// ListBuckets
 	apiRouter.Methods("GET").HandlerFunc(api.ListBucketsHandler)
 
@@ -19,7 +19,6 @@ package cmd
 import (
 	"bytes"
 	"crypto/md5"
-	"crypto/sha256"
 	"encoding/base64"
 	"encoding/hex"
 	"io/ioutil"
@@ -110,7 +109,7 @@ func getRequestAuthType(r *http.Request) authType {
 
 // sum256 calculate sha256 sum for an input byte array
 func sum256(data []byte) []byte {
-	hash := sha256.New()
+	hash := newSHA256()
 	hash.Write(data)
 	return hash.Sum(nil)
 }
@@ -124,6 +123,7 @@ func sumMD5(data []byte) []byte {
 
 // Verify if request has valid AWS Signature Version '2'.
 func isReqAuthenticatedV2(r *http.Request) (s3Error APIErrorCode) {
+	defer func() { recordAuthResult(r, s3Error) }()
 	if isRequestSignatureV2(r) {
 		return doesSignV2Match(r)
 	}
@@ -131,6 +131,7 @@ func isReqAuthenticatedV2(r *http.Request) (s3Error APIErrorCode) {
 }
 
 func reqSignatureV4Verify(r *http.Request) (s3Error APIErrorCode) {
+	defer func() { recordAuthResult(r, s3Error) }()
 	sha256sum := r.Header.Get("X-Amz-Content-Sha256")
 	// Skips calculating sha256 on the payload on server,
 	// if client requested for it.
@@ -150,6 +151,7 @@ func isReqAuthenticated(r *http.Request, region string) (s3Error APIErrorCode) {
 	if r == nil {
 		return ErrInternalError
 	}
+	defer func() { recordAuthResult(r, s3Error) }()
 	payload, err := ioutil.ReadAll(r.Body)
 	if err != nil {
 		errorIf(err, "Unable to read request body for signature verification")
@@ -238,8 +240,9 @@ func (a authHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		a.handler.ServeHTTP(w, r)
 		return
 	} else if aType == authTypeJWT {
-		// Validate Authorization header if its valid for JWT request.
-		if !isJWTReqAuthenticated(r) {
+		// Validate Authorization header if its valid for JWT request,
+		// scoped tokens are further validated by the web handlers.
+		if !isJWTReqAuthenticated(r) && getJWTReqScope(r) == nil {
 			w.WriteHeader(http.StatusUnauthorized)
 			return
 		}
@@ -0,0 +1,278 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+)
+
+const (
+	// Number of consecutive authentication failures after which a
+	// source IP or access key is locked out.
+	authLockoutThreshold = 5
+
+	// Lockout duration once the threshold is reached, doubled on
+	// every further failure up to authLockoutMaxDuration.
+	authLockoutBaseDuration = time.Second
+	authLockoutMaxDuration  = 5 * time.Minute
+
+	// Failures older than this are forgotten.
+	authLockoutExpiry = 15 * time.Minute
+
+	// Number of tracked entries beyond which expired ones are purged.
+	authLockoutPurgeThreshold = 10000
+
+	// Failed authentication attempts are logged at most once per interval.
+	authLockoutLogInterval = 10 * time.Second
+)
+
+// parseAuthLockout - parses MINIO_AUTH_LOCKOUT, 'on' locks out source
+// IPs, 'keys' additionally locks out access keys and 'off' disables
+// lockouts.
+func parseAuthLockout(mode string) (enabled, keys bool, err error) {
+	switch strings.ToLower(mode) {
+	case "", "on":
+		return true, false, nil
+	case "keys":
+		return true, true, nil
+	case "off":
+		return false, false, nil
+	}
+	return false, false, errInvalidArgument
+}
+
+// authAttempts - failed authentication attempts of a single source IP
+// or access key.
+type authAttempts struct {
+	failures    int
+	lastFailure time.Time
+	lockedUntil time.Time
+}
+
+// authLockout - tracks failed authentication attempts per source IP
+// and, if enabled, per access key, locking them out with an
+// exponential backoff.
+type authLockout struct {
+	mu       sync.Mutex
+	attempts map[string]*authAttempts
+
+	// Failures not logged yet and when they were last logged.
+	unlogged int64
+	lastLog  time.Time
+
+	// Counters since server start.
+	failures int64 // Failed authentication attempts.
+	lockouts int64 // Source IPs and access keys locked out.
+	rejected int64 // Requests rejected while locked out.
+}
+
+// Global authentication lockout, only consulted when enabled.
+var globalAuthLockout = newAuthLockout()
+
+func newAuthLockout() *authLockout {
+	return &authLockout{attempts: make(map[string]*authAttempts)}
+}
+
+// Returns the tracking keys of a source IP and an access key. Access
+// keys are only tracked if enabled, any client can claim the access
+// key of a presigned URL and would otherwise lock out everyone.
+func authLockoutKeys(sourceIP, accessKey string) (keys []string) {
+	if sourceIP != "" {
+		keys = append(keys, "ip:"+sourceIP)
+	}
+	if accessKey != "" && globalAuthLockoutKeys {
+		keys = append(keys, "key:"+accessKey)
+	}
+	return keys
+}
+
+// isLocked - returns true if either the source IP or the access key
+// is currently locked out.
+func (l *authLockout) isLocked(sourceIP, accessKey string) bool {
+	if !globalAuthLockoutEnabled {
+		return false
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := time.Now().UTC()
+	for _, key := range authLockoutKeys(sourceIP, accessKey) {
+		if a, ok := l.attempts[key]; ok && now.Before(a.lockedUntil) {
+			l.rejected++
+			return true
+		}
+	}
+	return false
+}
+
+// failed - records a failed authentication attempt, locking out the
+// source IP and access key once they reach the threshold.
+func (l *authLockout) failed(sourceIP, accessKey string) {
+	if !globalAuthLockoutEnabled {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := time.Now().UTC()
+	if len(l.attempts) >= authLockoutPurgeThreshold {
+		l.purge(now)
+	}
+	l.failures++
+
+	fields := logrus.Fields{
+		"sourceIP":  sourceIP,
+		"accessKey": accessKey,
+	}
+	for _, key := range authLockoutKeys(sourceIP, accessKey) {
+		a, ok := l.attempts[key]
+		if !ok || now.Sub(a.lastFailure) > authLockoutExpiry {
+			a = &authAttempts{}
+			l.attempts[key] = a
+		}
+		a.failures++
+		a.lastFailure = now
+		if a.failures < authLockoutThreshold {
+			continue
+		}
+		lockout := authLockoutMaxDuration
+		if shift := uint(a.failures - authLockoutThreshold); shift < 32 && authLockoutBaseDuration<<shift < lockout {
+			lockout = authLockoutBaseDuration << shift
+		}
+		a.lockedUntil = now.Add(lockout)
+		l.lockouts++
+		log.WithFields(fields).Errorf("%s locked out for %s after %d failed authentication attempts.", key, lockout, a.failures)
+	}
+
+	// Log failures at most once per interval, reporting how many
+	// happened meanwhile, to not flood the log during an attack.
+	l.unlogged++
+	if now.Sub(l.lastLog) >= authLockoutLogInterval {
+		fields["failures"] = l.unlogged
+		log.WithFields(fields).Warn("Authentication failed.")
+		l.unlogged = 0
+		l.lastLog = now
+	}
+}
+
+// succeeded - forgets previous failures of the source IP and access key.
+func (l *authLockout) succeeded(sourceIP, accessKey string) {
+	if !globalAuthLockoutEnabled {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for _, key := range authLockoutKeys(sourceIP, accessKey) {
+		delete(l.attempts, key)
+	}
+}
+
+// purge - removes entries which are neither locked nor recently failed.
+func (l *authLockout) purge(now time.Time) {
+	for key, a := range l.attempts {
+		if now.After(a.lockedUntil) && now.Sub(a.lastFailure) > authLockoutExpiry {
+			delete(l.attempts, key)
+		}
+	}
+}
+
+// getStats - returns the number of failed attempts, lockouts and
+// requests rejected while locked out.
+func (l *authLockout) getStats() (failures, lockouts, rejected int64) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.failures, l.lockouts, l.rejected
+}
+
+// getSourceIP - returns the IP address the request originates from.
+func getSourceIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// getRequestAccessKey - returns the access key a signed or presigned
+// request claims, without validating it.
+func getRequestAccessKey(r *http.Request) string {
+	var credential string
+	switch getRequestAuthType(r) {
+	case authTypeSigned, authTypeStreamingSigned:
+		// Authorization: AWS4-HMAC-SHA256 Credential=<access-key>/<scope>, ...
+		auth := r.Header.Get("Authorization")
+		if i := strings.Index(auth, "Credential="); i >= 0 {
+			credential = auth[i+len("Credential="):]
+		}
+	case authTypePresigned:
+		credential = r.URL.Query().Get("X-Amz-Credential")
+	case authTypeSignedV2:
+		// Authorization: AWS <access-key>:<signature>
+		credential = strings.TrimPrefix(r.Header.Get("Authorization"), signV2Algorithm+" ")
+		if i := strings.LastIndex(credential, ":"); i >= 0 {
+			credential = credential[:i]
+		}
+	case authTypePresignedV2:
+		credential = r.URL.Query().Get("AWSAccessKeyId")
+	}
+	if i := strings.Index(credential, "/"); i >= 0 {
+		credential = credential[:i]
+	}
+	return strings.TrimSpace(credential)
+}
+
+// recordAuthResult - records the outcome of a request signature
+// verification, only signature mismatches and unknown access keys
+// count as failures.
+func recordAuthResult(r *http.Request, s3Error APIErrorCode) {
+	switch s3Error {
+	case ErrNone:
+		globalAuthLockout.succeeded(getSourceIP(r), getRequestAccessKey(r))
+	case ErrSignatureDoesNotMatch, ErrInvalidAccessKeyID:
+		globalRequestMetrics.AuthFailed()
+		globalAuthLockout.failed(getSourceIP(r), getRequestAccessKey(r))
+	}
+}
+
+// authLockoutHandler - rejects signed requests from locked out source
+// IPs or for locked out access keys.
+type authLockoutHandler struct {
+	handler http.Handler
+}
+
+// setAuthLockoutHandler to reject requests during authentication lockouts.
+func setAuthLockoutHandler(h http.Handler) http.Handler {
+	return authLockoutHandler{h}
+}
+
+func (h authLockoutHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch getRequestAuthType(r) {
+	case authTypeAnonymous, authTypeJWT, authTypeUnknown:
+		// No credentials to be guessed, JWT tokens are validated as a
+		// whole and web logins are checked by the handler.
+	default:
+		if globalAuthLockout.isLocked(getSourceIP(r), getRequestAccessKey(r)) {
+			writeErrorResponse(w, r, ErrSlowDown, r.URL.Path)
+			return
+		}
+	}
+	h.handler.ServeHTTP(w, r)
+}
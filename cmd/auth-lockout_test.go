@@ -0,0 +1,217 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// Tests locking out source IPs and access keys after repeated failures.
+func TestAuthLockout(t *testing.T) {
+	globalAuthLockoutEnabled = true
+	globalAuthLockoutKeys = true
+	defer func() {
+		globalAuthLockoutEnabled = false
+		globalAuthLockoutKeys = false
+	}()
+
+	l := newAuthLockout()
+	for i := 1; i < authLockoutThreshold; i++ {
+		l.failed("10.0.0.1", "minio")
+		if l.isLocked("10.0.0.1", "") || l.isLocked("", "minio") {
+			t.Fatalf("Expected no lockout after %d failures", i)
+		}
+	}
+	l.failed("10.0.0.1", "minio")
+	if !l.isLocked("10.0.0.1", "") {
+		t.Error("Expected source IP to be locked out")
+	}
+	if !l.isLocked("10.0.0.2", "minio") {
+		t.Error("Expected access key to be locked out from any source IP")
+	}
+	if l.isLocked("10.0.0.2", "other") {
+		t.Error("Expected other source IPs and access keys not to be locked out")
+	}
+
+	// Lockouts double with every further failure.
+	lockout := l.attempts["ip:10.0.0.1"].lockedUntil.Sub(l.attempts["ip:10.0.0.1"].lastFailure)
+	if lockout != authLockoutBaseDuration {
+		t.Errorf("Expected lockout of %s, got %s", authLockoutBaseDuration, lockout)
+	}
+	l.failed("10.0.0.1", "")
+	lockout = l.attempts["ip:10.0.0.1"].lockedUntil.Sub(l.attempts["ip:10.0.0.1"].lastFailure)
+	if lockout != 2*authLockoutBaseDuration {
+		t.Errorf("Expected lockout of %s, got %s", 2*authLockoutBaseDuration, lockout)
+	}
+	for i := 0; i < 64; i++ {
+		l.failed("10.0.0.1", "")
+	}
+	lockout = l.attempts["ip:10.0.0.1"].lockedUntil.Sub(l.attempts["ip:10.0.0.1"].lastFailure)
+	if lockout != authLockoutMaxDuration {
+		t.Errorf("Expected lockout of %s, got %s", authLockoutMaxDuration, lockout)
+	}
+
+	// Expired lockouts no longer reject requests.
+	l.attempts["key:minio"].lockedUntil = time.Now().UTC().Add(-time.Second)
+	if l.isLocked("", "minio") {
+		t.Error("Expected expired lockout to be lifted")
+	}
+
+	// Success forgets previous failures.
+	l.succeeded("10.0.0.1", "minio")
+	if l.isLocked("10.0.0.1", "minio") {
+		t.Error("Expected lockout to be lifted after success")
+	}
+
+	failures, lockouts, rejected := l.getStats()
+	if failures != authLockoutThreshold+65 || lockouts != 67 || rejected != 2 {
+		t.Errorf("Unexpected stats, failures: %d, lockouts: %d, rejected: %d", failures, lockouts, rejected)
+	}
+
+	// Only the first failure within the log interval is logged.
+	if l.unlogged != authLockoutThreshold+64 {
+		t.Errorf("Expected %d unlogged failures, got %d", authLockoutThreshold+64, l.unlogged)
+	}
+
+	// Access keys are not locked out unless enabled.
+	globalAuthLockoutKeys = false
+	l = newAuthLockout()
+	for i := 0; i < authLockoutThreshold; i++ {
+		l.failed("10.0.0.1", "minio")
+	}
+	if !l.isLocked("10.0.0.1", "minio") {
+		t.Error("Expected source IP to be locked out")
+	}
+	if l.isLocked("10.0.0.2", "minio") {
+		t.Error("Expected access key not to be locked out from other source IPs")
+	}
+
+	// Nothing is tracked while disabled.
+	globalAuthLockoutEnabled = false
+	l = newAuthLockout()
+	for i := 0; i < authLockoutThreshold; i++ {
+		l.failed("10.0.0.1", "minio")
+	}
+	if len(l.attempts) != 0 || l.isLocked("10.0.0.1", "minio") {
+		t.Error("Expected lockouts to be disabled")
+	}
+}
+
+// Tests parsing of MINIO_AUTH_LOCKOUT.
+func TestParseAuthLockout(t *testing.T) {
+	testCases := []struct {
+		mode       string
+		enabled    bool
+		keys       bool
+		shouldPass bool
+	}{
+		{"", true, false, true},
+		{"on", true, false, true},
+		{"KEYS", true, true, true},
+		{"off", false, false, true},
+		{"ip", false, false, false},
+	}
+	for i, testCase := range testCases {
+		enabled, keys, err := parseAuthLockout(testCase.mode)
+		if testCase.shouldPass && err != nil {
+			t.Errorf("Test %d: Expected to pass, got %s", i+1, err)
+		}
+		if !testCase.shouldPass && err == nil {
+			t.Errorf("Test %d: Expected to fail", i+1)
+		}
+		if enabled != testCase.enabled || keys != testCase.keys {
+			t.Errorf("Test %d: Expected %v, %v, got %v, %v", i+1, testCase.enabled, testCase.keys, enabled, keys)
+		}
+	}
+}
+
+// Tests extracting the claimed access key from requests.
+func TestGetRequestAccessKey(t *testing.T) {
+	testCases := []struct {
+		header    string
+		query     string
+		accessKey string
+	}{
+		{"", "", ""},
+		{"AWS4-HMAC-SHA256 Credential=minio/20161015/us-east-1/s3/aws4_request, SignedHeaders=host, Signature=abcd", "", "minio"},
+		{"", "X-Amz-Algorithm=AWS4-HMAC-SHA256&X-Amz-Credential=minio%2F20161015%2Fus-east-1%2Fs3%2Faws4_request", "minio"},
+		{"AWS minio:signature", "", "minio"},
+		{"", "AWSAccessKeyId=minio&Signature=abcd&Expires=1", "minio"},
+		{"Bearer token", "", ""},
+	}
+	for i, testCase := range testCases {
+		req, err := http.NewRequest("GET", "http://localhost:9000/bucket/object?"+testCase.query, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if testCase.header != "" {
+			req.Header.Set("Authorization", testCase.header)
+		}
+		if accessKey := getRequestAccessKey(req); accessKey != testCase.accessKey {
+			t.Errorf("Test %d: Expected access key %q, got %q", i+1, testCase.accessKey, accessKey)
+		}
+	}
+}
+
+// Tests signed requests are rejected during lockouts.
+func TestAuthLockoutHandler(t *testing.T) {
+	globalAuthLockoutEnabled = true
+	savedAuthLockout := globalAuthLockout
+	defer func() {
+		globalAuthLockoutEnabled = false
+		globalAuthLockout = savedAuthLockout
+	}()
+	globalAuthLockout = newAuthLockout()
+
+	handler := setAuthLockoutHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	newRequest := func(authorization string) *http.Request {
+		req, err := http.NewRequest("GET", "http://localhost:9000/bucket/object", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.RemoteAddr = "10.0.0.1:12345"
+		if authorization != "" {
+			req.Header.Set("Authorization", authorization)
+		}
+		return req
+	}
+
+	for i := 0; i < authLockoutThreshold; i++ {
+		recordAuthResult(newRequest("AWS minio:signature"), ErrSignatureDoesNotMatch)
+	}
+
+	testCases := []struct {
+		authorization  string
+		expectedStatus int
+	}{
+		{"AWS minio:signature", http.StatusServiceUnavailable},
+		// Anonymous requests carry no credentials to be guessed.
+		{"", http.StatusOK},
+	}
+	for i, testCase := range testCases {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, newRequest(testCase.authorization))
+		if rec.Code != testCase.expectedStatus {
+			t.Errorf("Test %d: Expected status %d, got %d", i+1, testCase.expectedStatus, rec.Code)
+		}
+	}
+}
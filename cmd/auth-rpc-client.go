@@ -19,6 +19,7 @@ package cmd
 import (
 	"fmt"
 	"net/rpc"
+	"sync"
 	"time"
 
 	jwtgo "github.com/dgrijalva/jwt-go"
@@ -36,6 +37,19 @@ type GenericArgs struct {
 
 	// Indicates if args should be sent to remote peers as well.
 	Remote bool
+
+	// Time after which the caller abandons the call, long running
+	// handlers stop their work by then. Zero for no deadline.
+	Deadline time.Time
+
+	// Identifies the call to cancel it with Control.CancelHandler,
+	// empty if the call is not meant to be cancelled.
+	CallID string
+
+	// Retries of a mutating call with the same key get the result of
+	// the first call instead of applying the change again. Empty if
+	// the call is not retried.
+	IdempotencyKey string
 }
 
 // SetToken - sets the token to the supplied value.
@@ -48,6 +62,16 @@ func (ga *GenericArgs) SetTimestamp(tstamp time.Time) {
 	ga.Timestamp = tstamp
 }
 
+// SetDeadline - sets the deadline to the supplied value.
+func (ga *GenericArgs) SetDeadline(deadline time.Time) {
+	ga.Deadline = deadline
+}
+
+// GetDeadline - returns the deadline of the call.
+func (ga *GenericArgs) GetDeadline() time.Time {
+	return ga.Deadline
+}
+
 // RPCLoginArgs - login username and password for RPC.
 type RPCLoginArgs struct {
 	Username string
@@ -62,12 +86,13 @@ type RPCLoginReply struct {
 	ServerVersion string
 }
 
-// Validates if incoming token is valid.
-func isRPCTokenValid(tokenStr string) bool {
+// Parses an incoming RPC token, returns nil if it is not a valid
+// token signed with the credentials of the server.
+func parseRPCToken(tokenStr string) *jwtgo.Token {
 	jwt, err := newJWT(defaultInterNodeJWTExpiry)
 	if err != nil {
 		errorIf(err, "Unable to initialize JWT")
-		return false
+		return nil
 	}
 	token, err := jwtgo.Parse(tokenStr, func(token *jwtgo.Token) (interface{}, error) {
 		if _, ok := token.Method.(*jwtgo.SigningMethodHMAC); !ok {
@@ -77,30 +102,73 @@ func isRPCTokenValid(tokenStr string) bool {
 	})
 	if err != nil {
 		errorIf(err, "Unable to parse JWT token string")
+		return nil
+	}
+	if !token.Valid {
+		return nil
+	}
+	return token
+}
+
+// Validates if incoming token is valid, tokens are only valid for the
+// audience they were issued for and on the node which issued them.
+func isRPCTokenValid(tokenStr, audience string) bool {
+	token := parseRPCToken(tokenStr)
+	// Return if token is valid, scoped tokens are meant for the browser only.
+	return token != nil && hasJWTAudience(token, audience) && isJWTIssuedByNode(token) && getJWTScope(token) == nil
+}
+
+// Validates if incoming token is allowed to heal, either an admin
+// token or a heal token. Heal tokens are printed by the server
+// waiting for heal and are accepted by all nodes, the heal command
+// may be run against any of them.
+func isHealTokenValid(tokenStr string) bool {
+	if isRPCTokenValid(tokenStr, jwtAudienceAdmin) {
+		return true
+	}
+	token := parseRPCToken(tokenStr)
+	return token != nil && hasJWTAudience(token, jwtAudienceHeal) && getJWTScope(token) == nil
+}
+
+// RPC clients login again this long before their token expires.
+const rpcTokenRenewBefore = 5 * time.Minute
+
+// isInvalidTokenErr - returns true if err returned by a RPC call
+// means the token was rejected.
+func isInvalidTokenErr(err error) bool {
+	if err == nil {
 		return false
 	}
-	// Return if token is valid.
-	return token.Valid
+	return err.Error() == errInvalidToken.Error() || fromRPCError(err) == errInvalidToken
 }
 
 // Auth config represents authentication credentials and Login method name to be used
 // for fetching JWT tokens from the RPC server.
 type authConfig struct {
-	accessKey   string // Username for the server.
-	secretKey   string // Password for the server.
-	secureConn  bool   // Ask for a secured connection
-	address     string // Network address path of RPC server.
-	path        string // Network path for HTTP dial.
-	loginMethod string // RPC service name for authenticating using JWT
+	accessKey   string        // Username for the server.
+	secretKey   string        // Password for the server.
+	secureConn  bool          // Ask for a secured connection
+	address     string        // Network address path of RPC server.
+	path        string        // Network path for HTTP dial.
+	loginMethod string        // RPC service name for authenticating using JWT
+	compress    bool          // Ask for a compressed connection, for large replies.
+	timeout     time.Duration // Maximum duration of a call, zero for no timeout.
+	retryDrain  bool          // Retry calls while the server is draining for a restart.
+	token       string        // Token used instead of logging in, e.g a heal token.
 }
 
 // AuthRPCClient is a wrapper type for RPCClient which provides JWT based authentication across reconnects.
 type AuthRPCClient struct {
-	config        *authConfig
-	rpc           *RPCClient // reconnect'able rpc client built on top of net/rpc Client
-	isLoggedIn    bool       // Indicates if the auth client has been logged in and token is valid.
-	token         string     // JWT based token
-	serverVersion string     // Server version exchanged by the RPC.
+	config *authConfig
+	rpc    *RPCClient // reconnect'able rpc client built on top of net/rpc Client
+
+	// The client is shared by concurrent callers, mu guards the
+	// login state below.
+	mu            sync.Mutex
+	isLoggedIn    bool      // Indicates if the auth client has been logged in and token is valid.
+	token         string    // JWT based token
+	tokenExpiry   time.Time // Time after which the token is renewed by a new login.
+	serverVersion string    // Server version exchanged by the RPC.
 }
 
 // newAuthClient - returns a jwt based authenticated (go) rpc client, which does automatic reconnect.
@@ -109,7 +177,7 @@ func newAuthClient(cfg *authConfig) *AuthRPCClient {
 		// Save the config.
 		config: cfg,
 		// Initialize a new reconnectable rpc client.
-		rpc: newClient(cfg.address, cfg.path, cfg.secureConn),
+		rpc: newClient(cfg.address, cfg.path, cfg.secureConn, cfg.compress, cfg.timeout),
 		// Allocated auth client not logged in yet.
 		isLoggedIn: false,
 	}
@@ -118,14 +186,51 @@ func newAuthClient(cfg *authConfig) *AuthRPCClient {
 // Close - closes underlying rpc connection.
 func (authClient *AuthRPCClient) Close() error {
 	// reset token on closing a connection
-	authClient.isLoggedIn = false
+	authClient.logout("")
 	return authClient.rpc.Close()
 }
 
 // Login - a jwt based authentication is performed with rpc server.
 func (authClient *AuthRPCClient) Login() error {
-	// Return if already logged in.
-	if authClient.isLoggedIn {
+	_, err := authClient.loginToken()
+	return err
+}
+
+// loginToken - logs in if needed and returns the token to use for
+// calls. Concurrent callers wait for a single login.
+func (authClient *AuthRPCClient) loginToken() (string, error) {
+	authClient.mu.Lock()
+	defer authClient.mu.Unlock()
+	if err := authClient.login(); err != nil {
+		return "", err
+	}
+	return authClient.token, nil
+}
+
+// logout - marks the client for a new login. If token is not empty
+// the client is only marked if it still uses that token, callers
+// rejected with a token renewed by another call meanwhile keep the
+// new one.
+func (authClient *AuthRPCClient) logout(token string) {
+	authClient.mu.Lock()
+	if token == "" || token == authClient.token {
+		authClient.isLoggedIn = false
+	}
+	authClient.mu.Unlock()
+}
+
+// login - performs the login, mu must be held by the caller.
+func (authClient *AuthRPCClient) login() error {
+	// Return if already logged in, unless the token is about to expire.
+	if authClient.isLoggedIn && time.Now().UTC().Before(authClient.tokenExpiry) {
+		return nil
+	}
+	// Tokens handed out by the server are used as is, the server
+	// rejects them once expired.
+	if authClient.config.token != "" {
+		authClient.token = authClient.config.token
+		authClient.tokenExpiry = time.Now().UTC().Add(defaultHealJWTExpiry)
+		authClient.isLoggedIn = true
 		return nil
 	}
 	reply := RPCLoginReply{}
@@ -145,6 +250,7 @@ func (authClient *AuthRPCClient) Login() error {
 	}
 	// Set token, time stamp as received from a successful login call.
 	authClient.token = reply.Token
+	authClient.tokenExpiry = curTime.Add(defaultInterNodeJWTExpiry - rpcTokenRenewBefore)
 	authClient.serverVersion = reply.ServerVersion
 	authClient.isLoggedIn = true
 	return nil
@@ -156,20 +262,61 @@ func (authClient *AuthRPCClient) Login() error {
 func (authClient *AuthRPCClient) Call(serviceMethod string, args interface {
 	SetToken(token string)
 	SetTimestamp(tstamp time.Time)
+}, reply interface{}) (err error) {
+	err = authClient.call(serviceMethod, args, reply)
+	if !authClient.config.retryDrain {
+		return err
+	}
+	// The server announced a restart, retry with backoff until it is
+	// back instead of failing right away.
+	backoff := peerDrainBackoff
+	for isPeerDownErr(err) && globalDrainingPeers.IsDraining(authClient.config.address) {
+		time.Sleep(backoff)
+		if backoff *= 2; backoff > peerDrainMaxBackoff {
+			backoff = peerDrainMaxBackoff
+		}
+		err = authClient.call(serviceMethod, args, reply)
+	}
+	return err
+}
+
+// Makes a single call, logging in first if needed.
+func (authClient *AuthRPCClient) call(serviceMethod string, args interface {
+	SetToken(token string)
+	SetTimestamp(tstamp time.Time)
 }, reply interface{}) (err error) {
 	// On successful login, attempt the call.
-	if err = authClient.Login(); err == nil {
+	token, err := authClient.loginToken()
+	if err == nil {
 		// Set token and timestamp before the rpc call.
-		args.SetToken(authClient.token)
+		args.SetToken(token)
 		args.SetTimestamp(time.Now().UTC())
 
+		// Let the remote handler know when the call is abandoned.
+		setRPCDeadline(args, authClient.config.timeout)
+
+		// Delay the call if faults are injected for chaos testing.
+		globalFaultInjector.delayRPC()
+
 		// Call the underlying rpc.
 		err = authClient.rpc.Call(serviceMethod, args, reply)
 
+		// Login again and retry once if the token was rejected, e.g
+		// it expired or the server restarted.
+		if isInvalidTokenErr(err) {
+			authClient.logout(token)
+			if token, err = authClient.loginToken(); err != nil {
+				return err
+			}
+			args.SetToken(token)
+			args.SetTimestamp(time.Now().UTC())
+			err = authClient.rpc.Call(serviceMethod, args, reply)
+		}
+
 		// Invalidate token to mark for re-login on subsequent reconnect.
 		if err != nil {
 			if err.Error() == rpc.ErrShutdown.Error() {
-				authClient.isLoggedIn = false
+				authClient.logout(token)
 			}
 		}
 	}
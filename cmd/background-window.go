@@ -0,0 +1,149 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// backgroundWindow - daily local time window in which background
+// tasks are allowed to run, end is exclusive and may be before start
+// for windows wrapping midnight.
+type backgroundWindow struct {
+	start time.Duration
+	end   time.Duration
+}
+
+// String - returns the window in HH:MM-HH:MM form.
+func (w backgroundWindow) String() string {
+	format := func(d time.Duration) string {
+		return fmt.Sprintf("%02d:%02d", int(d/time.Hour), int(d%time.Hour/time.Minute))
+	}
+	return format(w.start) + "-" + format(w.end)
+}
+
+// contains - returns if the local time of t lies in the window.
+func (w backgroundWindow) contains(t time.Time) bool {
+	sinceMidnight := time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute
+	if w.start <= w.end {
+		return sinceMidnight >= w.start && sinceMidnight < w.end
+	}
+	return sinceMidnight >= w.start || sinceMidnight < w.end
+}
+
+// nextOpening - returns the first time the window opens after t, in
+// the location of t.
+func (w backgroundWindow) nextOpening(t time.Time) time.Time {
+	hour, min := int(w.start/time.Hour), int(w.start%time.Hour/time.Minute)
+	opening := time.Date(t.Year(), t.Month(), t.Day(), hour, min, 0, 0, t.Location())
+	if !opening.After(t) {
+		opening = time.Date(t.Year(), t.Month(), t.Day()+1, hour, min, 0, 0, t.Location())
+	}
+	return opening
+}
+
+// parseBackgroundWindow - parses a window of the form HH:MM-HH:MM
+// in local time, for example "01:00-05:00" or "22:00-02:00".
+func parseBackgroundWindow(window string) (backgroundWindow, error) {
+	parts := strings.Split(window, "-")
+	if len(parts) != 2 {
+		return backgroundWindow{}, errInvalidArgument
+	}
+	var bounds [2]time.Duration
+	for i, part := range parts {
+		t, err := time.Parse("15:04", strings.TrimSpace(part))
+		if err != nil {
+			return backgroundWindow{}, err
+		}
+		bounds[i] = time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute
+	}
+	if bounds[0] == bounds[1] {
+		return backgroundWindow{}, errInvalidArgument
+	}
+	return backgroundWindow{start: bounds[0], end: bounds[1]}, nil
+}
+
+// backgroundSchedule - decides if background tasks like tmp cleanup,
+// dedup GC and purges run, they run always unless a window is set.
+// The window can be overridden for a while with 'minio control
+// schedule'.
+type backgroundSchedule struct {
+	mutex *sync.Mutex
+	// Window set by MINIO_BACKGROUND_WINDOW, nil if none.
+	window *backgroundWindow
+	// Background tasks run regardless of the window until then.
+	overrideUntil time.Time
+}
+
+// Variable holding the schedule of background tasks.
+var globalBackgroundSchedule = newBackgroundSchedule()
+
+// newBackgroundSchedule - returns a schedule without any window.
+func newBackgroundSchedule() *backgroundSchedule {
+	return &backgroundSchedule{mutex: &sync.Mutex{}}
+}
+
+// SetWindow - sets the window background tasks run in.
+func (s *backgroundSchedule) SetWindow(window backgroundWindow) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.window = &window
+}
+
+// Override - runs background tasks regardless of the window until.
+func (s *backgroundSchedule) Override(until time.Time) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.overrideUntil = until
+}
+
+// IsOpen - returns if background tasks are allowed to run at t.
+func (s *backgroundSchedule) IsOpen(t time.Time) bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.window == nil || t.Before(s.overrideUntil) {
+		return true
+	}
+	return s.window.contains(t)
+}
+
+// NextOpen - returns the first time at or after t background tasks
+// are allowed to run, unless the window is overridden meanwhile.
+func (s *backgroundSchedule) NextOpen(t time.Time) time.Time {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.window == nil || t.Before(s.overrideUntil) || s.window.contains(t) {
+		return t
+	}
+	return s.window.nextOpening(t)
+}
+
+// Status - returns the window, empty if none, and the time until
+// which it is overridden, zero if not overridden.
+func (s *backgroundSchedule) Status(now time.Time) (window string, overrideUntil time.Time) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.window != nil {
+		window = s.window.String()
+	}
+	if now.Before(s.overrideUntil) {
+		overrideUntil = s.overrideUntil
+	}
+	return window, overrideUntil
+}
@@ -0,0 +1,124 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package cmd
+
+import (
+	"testing"
+	"time"
+)
+
+// Tests parsing background windows.
+func TestParseBackgroundWindow(t *testing.T) {
+	testCases := []struct {
+		window     string
+		expected   string
+		shouldPass bool
+	}{
+		{"01:00-05:00", "01:00-05:00", true},
+		{"22:30-02:15", "22:30-02:15", true},
+		{" 1:00 - 5:00 ", "01:00-05:00", true},
+		{"01:00", "", false},
+		{"01:00-01:00", "", false},
+		{"25:00-05:00", "", false},
+		{"01:00-05:00-06:00", "", false},
+	}
+	for i, testCase := range testCases {
+		window, err := parseBackgroundWindow(testCase.window)
+		if testCase.shouldPass && err != nil {
+			t.Errorf("Test %d: Unexpected error %s", i+1, err)
+			continue
+		}
+		if !testCase.shouldPass {
+			if err == nil {
+				t.Errorf("Test %d: Expected window %q to be invalid", i+1, testCase.window)
+			}
+			continue
+		}
+		if window.String() != testCase.expected {
+			t.Errorf("Test %d: Expected %s, got %s", i+1, testCase.expected, window)
+		}
+	}
+}
+
+// Tests background tasks run only in the window unless overridden.
+func TestBackgroundSchedule(t *testing.T) {
+	at := func(hour, min int) time.Time {
+		return time.Date(2016, 10, 1, hour, min, 0, 0, time.Local)
+	}
+
+	schedule := newBackgroundSchedule()
+	if !schedule.IsOpen(at(12, 0)) {
+		t.Fatal("Expected background tasks to run always without a window")
+	}
+
+	// Window wrapping midnight.
+	window, err := parseBackgroundWindow("22:00-02:00")
+	if err != nil {
+		t.Fatal(err)
+	}
+	schedule.SetWindow(window)
+	testCases := []struct {
+		now  time.Time
+		open bool
+	}{
+		{at(21, 59), false},
+		{at(22, 0), true},
+		{at(23, 59), true},
+		{at(1, 59), true},
+		{at(2, 0), false},
+		{at(12, 0), false},
+	}
+	for i, testCase := range testCases {
+		if open := schedule.IsOpen(testCase.now); open != testCase.open {
+			t.Errorf("Test %d: Expected open %v at %s, got %v", i+1, testCase.open, testCase.now, open)
+		}
+	}
+
+	nextOpenCases := []struct {
+		now      time.Time
+		nextOpen time.Time
+	}{
+		{at(12, 0), at(22, 0)},
+		{at(21, 59), at(22, 0)},
+		{at(22, 30), at(22, 30)},
+		{at(1, 0), at(1, 0)},
+		{at(2, 0), at(22, 0)},
+	}
+	for i, testCase := range nextOpenCases {
+		if nextOpen := schedule.NextOpen(testCase.now); !nextOpen.Equal(testCase.nextOpen) {
+			t.Errorf("Test %d: Expected next open %s at %s, got %s", i+1, testCase.nextOpen, testCase.now, nextOpen)
+		}
+	}
+	morning := backgroundWindow{start: 3 * time.Hour, end: 4 * time.Hour}
+	if opening := morning.nextOpening(at(12, 0)); !opening.Equal(time.Date(2016, 10, 2, 3, 0, 0, 0, time.Local)) {
+		t.Errorf("Expected window to open the next day, got %s", opening)
+	}
+
+	schedule.Override(at(13, 0))
+	if !schedule.IsOpen(at(12, 0)) {
+		t.Fatal("Expected overridden window to be open")
+	}
+	if schedule.IsOpen(at(13, 0)) {
+		t.Fatal("Expected expired override to be ignored")
+	}
+	if _, until := schedule.Status(at(12, 0)); !until.Equal(at(13, 0)) {
+		t.Fatalf("Expected override until %s, got %s", at(13, 0), until)
+	}
+	schedule.Override(time.Time{})
+	if windowStr, until := schedule.Status(at(12, 0)); windowStr != "22:00-02:00" || !until.IsZero() {
+		t.Fatalf("Unexpected status %s, %s after reset", windowStr, until)
+	}
+}
@@ -0,0 +1,152 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package cmd
+
+import (
+	"fmt"
+	"hash"
+	"os"
+	"strings"
+	"time"
+
+	humanize "github.com/dustin/go-humanize"
+	"github.com/minio/blake2b-simd"
+	"github.com/minio/cli"
+	"github.com/minio/mc/pkg/console"
+)
+
+var benchmarkFlags = []cli.Flag{
+	cli.StringFlag{
+		Name:  "size",
+		Value: "256MB",
+		Usage: "Size in NN[GB|MB|KB] of the data hashed by every implementation.",
+	},
+}
+
+// "minio benchmark" command.
+var benchmarkCmd = cli.Command{
+	Name:   "benchmark",
+	Usage:  "Measure the throughput of the hashing implementations on this host.",
+	Flags:  append(benchmarkFlags, globalFlags...),
+	Action: mainBenchmark,
+	CustomHelpTemplate: `NAME:
+   minio {{.Name}} - {{.Usage}}
+
+USAGE:
+   minio {{.Name}} [FLAGS]
+
+FLAGS:
+  {{range .Flags}}{{.}}
+  {{end}}
+DESCRIPTION:
+   Hashes the same data with every SHA-256 implementation usable for
+   signature V4 payload hashing and with the bitrot hash, and prints
+   their throughput. The implementations the server selects from the
+   CPU features, or from MINIO_SHA256_IMPL, are marked.
+
+EXAMPLES:
+   1. Measure the throughput on 256MB of data.
+      $ minio {{.Name}}
+
+   2. Measure the throughput on 1GB of data.
+      $ minio {{.Name}} --size 1GB
+`,
+}
+
+// hashBenchmark - a hashing implementation measured by the benchmark.
+type hashBenchmark struct {
+	Name     string
+	Usage    string
+	New      func() hash.Hash
+	Selected bool
+}
+
+// Returns the hashing implementations to measure, selected marks the
+// ones the server uses.
+func getHashBenchmarks(selected sha256Impl) []hashBenchmark {
+	return []hashBenchmark{
+		{
+			Name:     sha256ImplSIMD,
+			Usage:    "payload",
+			New:      newSHA256Impl(sha256ImplSIMD, "").New,
+			Selected: selected.Name == sha256ImplSIMD,
+		},
+		{
+			Name:     sha256ImplStdlib,
+			Usage:    "payload",
+			New:      newSHA256Impl(sha256ImplStdlib, "").New,
+			Selected: selected.Name == sha256ImplStdlib,
+		},
+		{
+			Name:     "blake2b-simd",
+			Usage:    "bitrot",
+			New:      blake2b.New512,
+			Selected: true,
+		},
+	}
+}
+
+// Returns the throughput in bytes per second of hashing data with a
+// hash of newHash, in writes of blockSizeV1 bytes like the object
+// layers do.
+func runHashBenchmark(newHash func() hash.Hash, data []byte) float64 {
+	h := newHash()
+	start := time.Now()
+	for offset := 0; offset < len(data); offset += blockSizeV1 {
+		end := offset + blockSizeV1
+		if end > len(data) {
+			end = len(data)
+		}
+		h.Write(data[offset:end])
+	}
+	h.Sum(nil)
+	elapsed := time.Since(start)
+	if elapsed <= 0 {
+		elapsed = time.Nanosecond
+	}
+	return float64(len(data)) / elapsed.Seconds()
+}
+
+func mainBenchmark(ctx *cli.Context) {
+	if len(ctx.Args()) != 0 {
+		cli.ShowCommandHelpAndExit(ctx, "benchmark", 1)
+	}
+	size, err := strconvBytes(ctx.String("size"))
+	fatalIf(err, "Invalid size %s.", ctx.String("size"))
+	if size == 0 {
+		fatalIf(errInvalidArgument, "Size has to be greater than zero.")
+	}
+
+	features := getCPUFeatures()
+	selected := selectSHA256Impl(features)
+	if impl := os.Getenv("MINIO_SHA256_IMPL"); impl != "" {
+		selected, err = parseSHA256Impl(impl, features)
+		fatalIf(err, "Invalid MINIO_SHA256_IMPL=%s environment variable.", impl)
+	}
+
+	console.Println("CPU features: " + strings.Join(features, " "))
+	console.Println(fmt.Sprintf("Selected: SHA-256 %s (%s) | Bitrot %s", selected.Name, selected.Reason, getBitrotImpl(features)))
+
+	data := make([]byte, size)
+	for _, b := range getHashBenchmarks(selected) {
+		mark := ""
+		if b.Selected {
+			mark = " *"
+		}
+		throughput := runHashBenchmark(b.New, data)
+		console.Println(fmt.Sprintf("%-14s %-8s %10s/s%s", b.Name, b.Usage, humanize.IBytes(uint64(throughput)), mark))
+	}
+}
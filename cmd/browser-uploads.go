@@ -0,0 +1,131 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"path"
+	"time"
+)
+
+// Multipart uploads started by the browser are recorded in the meta
+// bucket, under .minio.sys/browser-uploads/<bucket>/<uploadID>, and
+// touched with every part uploaded. Uploads abandoned by the browser,
+// e.g. when the page is closed for good, are aborted once they have
+// not been touched for browserUploadExpiry. Multipart uploads of S3
+// clients are left alone.
+const (
+	browserUploadsPrefix = "browser-uploads"
+
+	browserUploadExpiry        = 24 * time.Hour
+	browserUploadPurgeInterval = time.Hour
+)
+
+// browserUpload - record of a multipart upload started by the browser.
+type browserUpload struct {
+	Object string `json:"object"`
+}
+
+func browserUploadPath(bucket, uploadID string) string {
+	return path.Join(browserUploadsPrefix, bucket, uploadID)
+}
+
+// saveBrowserUpload - records, or touches, a multipart upload of the
+// browser.
+func saveBrowserUpload(objAPI ObjectLayer, bucket, object, uploadID string) error {
+	buf, err := json.Marshal(browserUpload{Object: object})
+	if err != nil {
+		return err
+	}
+	_, err = objAPI.PutObject(minioMetaBucket, browserUploadPath(bucket, uploadID), int64(len(buf)), bytes.NewReader(buf), nil, "")
+	return err
+}
+
+// removeBrowserUpload - removes the record of a completed or aborted
+// multipart upload of the browser.
+func removeBrowserUpload(objAPI ObjectLayer, bucket, uploadID string) error {
+	err := objAPI.DeleteObject(minioMetaBucket, browserUploadPath(bucket, uploadID))
+	if _, ok := errorCause(err).(ObjectNotFound); ok {
+		return nil
+	}
+	return err
+}
+
+// readBrowserUpload - reads the record of a multipart upload of the
+// browser.
+func readBrowserUpload(objAPI ObjectLayer, objInfo ObjectInfo) (upload browserUpload, err error) {
+	var buffer bytes.Buffer
+	if err = objAPI.GetObject(minioMetaBucket, objInfo.Name, 0, objInfo.Size, &buffer); err != nil {
+		return upload, err
+	}
+	err = json.Unmarshal(buffer.Bytes(), &upload)
+	return upload, err
+}
+
+// purgeBrowserUploads - aborts the multipart uploads of the browser
+// which were not touched for expiry, returns the number of aborted
+// uploads.
+func purgeBrowserUploads(objAPI ObjectLayer, expiry time.Duration) (purged int, err error) {
+	var expired []ObjectInfo
+	marker := ""
+	for {
+		result, err := objAPI.ListObjects(minioMetaBucket, browserUploadsPrefix+slashSeparator, marker, "", maxObjectList)
+		if err != nil {
+			return purged, err
+		}
+		for _, objInfo := range result.Objects {
+			if time.Since(objInfo.ModTime) >= expiry {
+				expired = append(expired, objInfo)
+			}
+		}
+		if !result.IsTruncated {
+			break
+		}
+		marker = result.NextMarker
+	}
+
+	for _, objInfo := range expired {
+		bucket := path.Base(path.Dir(objInfo.Name))
+		uploadID := path.Base(objInfo.Name)
+		upload, err := readBrowserUpload(objAPI, objInfo)
+		if err != nil {
+			return purged, err
+		}
+		err = objAPI.AbortMultipartUpload(bucket, upload.Object, uploadID)
+		switch errorCause(err).(type) {
+		case nil, InvalidUploadID, BucketNotFound:
+			// Uploads already gone only leave their record behind.
+		default:
+			return purged, err
+		}
+		if err = removeBrowserUpload(objAPI, bucket, uploadID); err != nil {
+			return purged, err
+		}
+		purged++
+	}
+	return purged, nil
+}
+
+// startBrowserUploadPurge - periodically aborts abandoned multipart
+// uploads of the browser.
+func startBrowserUploadPurge(objAPI ObjectLayer, interval, expiry time.Duration) {
+	globalTaskManager.Start(browserUploadPurgeTask, interval, func() error {
+		_, err := purgeBrowserUploads(objAPI, expiry)
+		errorIf(err, "Unable to purge abandoned browser uploads.")
+		return err
+	})
+}
@@ -0,0 +1,63 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package cmd
+
+import (
+	"testing"
+	"time"
+)
+
+// Wrapper for calling browser upload purge tests for both XL and FS.
+func TestPurgeBrowserUploads(t *testing.T) {
+	ExecObjectLayerTest(t, testPurgeBrowserUploads)
+}
+
+// Tests only multipart uploads of the browser which were not touched
+// for the expiry are aborted.
+func testPurgeBrowserUploads(obj ObjectLayer, instanceType string, t TestErrHandler) {
+	bucket := "browser-bucket"
+	if err := obj.MakeBucket(bucket); err != nil {
+		t.Fatalf("%s: Unable to make bucket: %v", instanceType, err)
+	}
+	browserUploadID, err := obj.NewMultipartUpload(bucket, "browser", nil)
+	if err != nil {
+		t.Fatalf("%s: Unable to start multipart upload: %v", instanceType, err)
+	}
+	if err = saveBrowserUpload(obj, bucket, "browser", browserUploadID); err != nil {
+		t.Fatalf("%s: Unable to record browser upload: %v", instanceType, err)
+	}
+	s3UploadID, err := obj.NewMultipartUpload(bucket, "s3", nil)
+	if err != nil {
+		t.Fatalf("%s: Unable to start multipart upload: %v", instanceType, err)
+	}
+	// Uploads already gone only leave their record behind.
+	if err = saveBrowserUpload(obj, bucket, "gone", "gone-upload-id"); err != nil {
+		t.Fatalf("%s: Unable to record browser upload: %v", instanceType, err)
+	}
+
+	if purged, pErr := purgeBrowserUploads(obj, time.Hour); pErr != nil || purged != 0 {
+		t.Fatalf("%s: Expected no upload to be purged, got %d: %v", instanceType, purged, pErr)
+	}
+	if purged, pErr := purgeBrowserUploads(obj, 0); pErr != nil || purged != 2 {
+		t.Fatalf("%s: Expected two uploads to be purged, got %d: %v", instanceType, purged, pErr)
+	}
+	if _, err = obj.ListObjectParts(bucket, "browser", browserUploadID, 0, maxPartsList); !isInvalidUploadID(err) {
+		t.Errorf("%s: Expected browser upload to be aborted, got %v", instanceType, err)
+	}
+	if _, err = obj.ListObjectParts(bucket, "s3", s3UploadID, 0, maxPartsList); err != nil {
+		t.Errorf("%s: Expected S3 upload to be kept, got %v", instanceType, err)
+	}
+}
@@ -0,0 +1,135 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package cmd
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Buckets are created under this lock in the meta bucket, so that
+// concurrent creations cannot exceed the maximum number of buckets.
+const bucketCreationLockPath = "bucket-creation"
+
+// bucketCreationPolicy - limits on the buckets operators allow to be
+// created, to stop bucket sprawl on multi-tenant deployments.
+type bucketCreationPolicy struct {
+	MaxBuckets   int            // Maximum number of buckets, 0 disables the limit.
+	NamePattern  *regexp.Regexp // Pattern bucket names have to match, nil allows all names.
+	NamePrefix   string         // Prefix bucket names have to start with.
+	ReservedName string         // Bucket name shadowed by the browser URL prefix, if any.
+}
+
+// isEnabled - returns true if the policy restricts bucket creation.
+func (policy bucketCreationPolicy) isEnabled() bool {
+	return policy.MaxBuckets > 0 || policy.NamePattern != nil || policy.NamePrefix != "" || policy.ReservedName != ""
+}
+
+// isNameAllowed - returns true if bucket meets the naming policy.
+func (policy bucketCreationPolicy) isNameAllowed(bucket string) bool {
+	if policy.ReservedName != "" && bucket == policy.ReservedName {
+		return false
+	}
+	if !strings.HasPrefix(bucket, policy.NamePrefix) {
+		return false
+	}
+	return policy.NamePattern == nil || policy.NamePattern.MatchString(bucket)
+}
+
+// parseBucketCreationPolicy - parses the maximum number of buckets and
+// the naming policy, empty values leave bucket creation unrestricted.
+// The pattern has to match whole bucket names.
+func parseBucketCreationPolicy(maxBuckets, namePattern, namePrefix string) (policy bucketCreationPolicy, err error) {
+	if maxBuckets != "" {
+		if policy.MaxBuckets, err = strconv.Atoi(maxBuckets); err != nil || policy.MaxBuckets < 1 {
+			return policy, errInvalidArgument
+		}
+	}
+	if namePattern != "" {
+		if policy.NamePattern, err = regexp.Compile("^(?:" + namePattern + ")$"); err != nil {
+			return policy, err
+		}
+	}
+	if namePrefix != "" {
+		// The prefix has to be usable in valid bucket names.
+		if !IsValidBucketName(namePrefix + "bucket") {
+			return policy, errInvalidArgument
+		}
+		policy.NamePrefix = namePrefix
+	}
+	return policy, nil
+}
+
+// bucketCreationObjects - object layer enforcing the bucket creation
+// policy, all other operations are served by the underlying object
+// layer.
+type bucketCreationObjects struct {
+	ObjectLayer
+	policy bucketCreationPolicy
+}
+
+// newBucketCreationObjects - returns objAPI enforcing policy, objAPI
+// itself if policy does not restrict bucket creation.
+func newBucketCreationObjects(objAPI ObjectLayer, policy bucketCreationPolicy) ObjectLayer {
+	if !policy.isEnabled() {
+		return objAPI
+	}
+	return bucketCreationObjects{objAPI, policy}
+}
+
+// checkBucketName - returns BucketNameNotAllowed if bucket does not
+// meet the naming policy. Invalid names are left to the underlying
+// object layer to reject.
+func (b bucketCreationObjects) checkBucketName(bucket string) error {
+	if IsValidBucketName(bucket) && !b.policy.isNameAllowed(bucket) {
+		return traceError(BucketNameNotAllowed{Bucket: bucket})
+	}
+	return nil
+}
+
+// MakeBucket - creates a bucket meeting the naming policy, unless the
+// maximum number of buckets already exist.
+func (b bucketCreationObjects) MakeBucket(bucket string) error {
+	if err := b.checkBucketName(bucket); err != nil {
+		return err
+	}
+	if b.policy.MaxBuckets == 0 {
+		return b.ObjectLayer.MakeBucket(bucket)
+	}
+
+	opsID := getOpsID()
+	nsMutex.Lock(minioMetaBucket, bucketCreationLockPath, opsID)
+	defer nsMutex.Unlock(minioMetaBucket, bucketCreationLockPath, opsID)
+
+	buckets, err := b.ObjectLayer.ListBuckets()
+	if err != nil {
+		return err
+	}
+	if len(buckets) >= b.policy.MaxBuckets {
+		return traceError(TooManyBuckets{Bucket: bucket})
+	}
+	return b.ObjectLayer.MakeBucket(bucket)
+}
+
+// RenameBucket - renames a bucket, the new name has to meet the
+// naming policy.
+func (b bucketCreationObjects) RenameBucket(srcBucket, dstBucket string) error {
+	if err := b.checkBucketName(dstBucket); err != nil {
+		return err
+	}
+	return b.ObjectLayer.RenameBucket(srcBucket, dstBucket)
+}
@@ -0,0 +1,138 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package cmd
+
+import "testing"
+
+// Tests parsing of the bucket creation policy.
+func TestParseBucketCreationPolicy(t *testing.T) {
+	testCases := []struct {
+		maxBuckets  string
+		namePattern string
+		namePrefix  string
+		enabled     bool
+		success     bool
+	}{
+		{"", "", "", false, true},
+		{"10", "", "", true, true},
+		{"0", "", "", false, false},
+		{"-1", "", "", false, false},
+		{"ten", "", "", false, false},
+		{"", "[a-z]+", "", true, true},
+		{"", "[a-z", "", false, false},
+		{"", "", "acme-", true, true},
+		{"", "", "Acme", false, false},
+		{"", "", ".acme", false, false},
+	}
+	for i, testCase := range testCases {
+		policy, err := parseBucketCreationPolicy(testCase.maxBuckets, testCase.namePattern, testCase.namePrefix)
+		if testCase.success != (err == nil) {
+			t.Errorf("Test %d: Expected success %t, got error %v", i+1, testCase.success, err)
+			continue
+		}
+		if err == nil && policy.isEnabled() != testCase.enabled {
+			t.Errorf("Test %d: Expected enabled %t, got %t", i+1, testCase.enabled, policy.isEnabled())
+		}
+	}
+}
+
+// Tests bucket names against the naming policy.
+func TestBucketCreationPolicyIsNameAllowed(t *testing.T) {
+	policy, err := parseBucketCreationPolicy("", "[a-z]+-[0-9]+", "acme-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	testCases := []struct {
+		bucket  string
+		allowed bool
+	}{
+		{"acme-logs-1", false},
+		{"acme-1", true},
+		{"acme-123", true},
+		{"other-123", false},
+		// The pattern has to match the whole name.
+		{"acme-123-backup", false},
+	}
+	for i, testCase := range testCases {
+		if allowed := policy.isNameAllowed(testCase.bucket); allowed != testCase.allowed {
+			t.Errorf("Test %d: Expected %s allowed %t, got %t", i+1, testCase.bucket, testCase.allowed, allowed)
+		}
+	}
+
+	// Buckets shadowed by the browser URL prefix are never allowed.
+	reserved := bucketCreationPolicy{ReservedName: "console"}
+	if !reserved.isEnabled() {
+		t.Error("Expected policy with a reserved name to be enabled")
+	}
+	if reserved.isNameAllowed("console") || !reserved.isNameAllowed("console2") {
+		t.Error("Expected only the reserved name to be rejected")
+	}
+}
+
+// Wrapper for calling bucket creation policy tests for both XL and FS.
+func TestBucketCreationObjects(t *testing.T) {
+	ExecObjectLayerTest(t, testBucketCreationObjects)
+}
+
+// Tests buckets are only created within the bucket creation policy.
+func testBucketCreationObjects(obj ObjectLayer, instanceType string, t TestErrHandler) {
+	policy, err := parseBucketCreationPolicy("2", "", "acme-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	policyObj := newBucketCreationObjects(obj, policy)
+
+	testCases := []struct {
+		bucket string
+		err    error
+	}{
+		// Invalid names are still rejected as invalid.
+		{"Acme", BucketNameInvalid{Bucket: "Acme"}},
+		{"other-bucket", BucketNameNotAllowed{Bucket: "other-bucket"}},
+		{"acme-bucket1", nil},
+		{"acme-bucket2", nil},
+		{"acme-bucket3", TooManyBuckets{Bucket: "acme-bucket3"}},
+	}
+	for i, testCase := range testCases {
+		if err = errorCause(policyObj.MakeBucket(testCase.bucket)); err != testCase.err {
+			t.Errorf("%s: Test %d: Expected error %v, got %v", instanceType, i+1, testCase.err, err)
+		}
+	}
+
+	// Renamed buckets have to meet the naming policy.
+	if err = errorCause(policyObj.RenameBucket("acme-bucket1", "other-bucket")); err != (BucketNameNotAllowed{Bucket: "other-bucket"}) {
+		t.Errorf("%s: Expected BucketNameNotAllowed, got %v", instanceType, err)
+	}
+	if err = policyObj.RenameBucket("acme-bucket1", "acme-bucket3"); err != nil {
+		t.Errorf("%s: Unable to rename bucket: %v", instanceType, err)
+	}
+
+	// Buckets can be created again once others are deleted.
+	if err = policyObj.DeleteBucket("acme-bucket3"); err != nil {
+		t.Fatalf("%s: Unable to delete bucket: %v", instanceType, err)
+	}
+	if err = policyObj.MakeBucket("acme-bucket4"); err != nil {
+		t.Errorf("%s: Unable to make bucket: %v", instanceType, err)
+	}
+
+	// Policy errors are mapped to S3 errors.
+	if code := toAPIErrorCode(TooManyBuckets{Bucket: "acme-bucket5"}); code != ErrTooManyBuckets {
+		t.Errorf("Expected ErrTooManyBuckets, got %v", code)
+	}
+	if code := toAPIErrorCode(BucketNameNotAllowed{Bucket: "other-bucket"}); code != ErrBucketNameNotAllowed {
+		t.Errorf("Expected ErrBucketNameNotAllowed, got %v", code)
+	}
+}
@@ -0,0 +1,229 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package cmd
+
+import (
+	"encoding/xml"
+	"io"
+	"net/http"
+	"path"
+
+	mux "github.com/gorilla/mux"
+)
+
+// maximum supported bucket encryption configuration size.
+const maxBucketEncryptionConfigSize = 20 * 1024 // 20KiB.
+
+// sseDefault - encryption applied by default.
+type sseDefault struct {
+	SSEAlgorithm   string `xml:"SSEAlgorithm"`
+	KMSMasterKeyID string `xml:"KMSMasterKeyID,omitempty"`
+}
+
+// sseRule - rule of a bucket encryption configuration.
+type sseRule struct {
+	ApplyServerSideEncryptionByDefault sseDefault `xml:"ApplyServerSideEncryptionByDefault"`
+	// Minio extension, rejects uploads without the
+	// 'X-Amz-Server-Side-Encryption' header instead of
+	// encrypting them by default.
+	DenyUnencryptedUploads bool `xml:"DenyUnencryptedUploads,omitempty"`
+}
+
+// sseConfiguration - bucket encryption configuration.
+type sseConfiguration struct {
+	XMLName xml.Name  `xml:"ServerSideEncryptionConfiguration"`
+	Rules   []sseRule `xml:"Rule"`
+}
+
+// toBucketEncryption - returns the bucket encryption of a configuration
+// with a single rule.
+func (c sseConfiguration) toBucketEncryption() (*bucketEncryption, APIErrorCode) {
+	if len(c.Rules) != 1 {
+		return nil, ErrMalformedXML
+	}
+	rule := c.Rules[0]
+	encryption := &bucketEncryption{
+		Algorithm:       rule.ApplyServerSideEncryptionByDefault.SSEAlgorithm,
+		DenyUnencrypted: rule.DenyUnencryptedUploads,
+	}
+	if encryption.validate() != nil || rule.ApplyServerSideEncryptionByDefault.KMSMasterKeyID != "" {
+		return nil, ErrInvalidEncryptionMethod
+	}
+	return encryption, ErrNone
+}
+
+// persistBucketEncryption - sets the encryption in the settings of
+// bucket, nil removes it, leaving all the other settings as they are.
+func persistBucketEncryption(bucket string, encryption *bucketEncryption, objAPI ObjectLayer) error {
+	// Serialize read-modify-write of the bucket settings.
+	opsID := getOpsID()
+	lockPath := path.Join(bucketConfigPrefix, bucket)
+	nsMutex.Lock(minioMetaBucket, lockPath, opsID)
+	defer nsMutex.Unlock(minioMetaBucket, lockPath, opsID)
+
+	if err := isBucketExist(bucket, objAPI); err != nil {
+		return err
+	}
+	settings, err := readBucketSettings(bucket, objAPI)
+	if err != nil {
+		if _, ok := err.(BucketSettingsNotFound); !ok {
+			return err
+		}
+		settings = &bucketSettings{}
+	}
+	if encryption == nil && settings.Encryption == nil {
+		return nil
+	}
+	settings.Encryption = encryption
+	if settings.isEmpty() {
+		settings = nil
+	}
+	return persistAndNotifyBucketSettingsChange(bucket, settings, objAPI)
+}
+
+// PutBucketEncryptionHandler - PUT Bucket encryption
+// -----------------
+// This operation uses the encryption subresource to set the default
+// server side encryption of objects uploaded to a bucket.
+func (api objectAPIHandlers) PutBucketEncryptionHandler(w http.ResponseWriter, r *http.Request) {
+	objAPI := api.ObjectAPI()
+	if objAPI == nil {
+		writeErrorResponse(w, r, ErrServerNotInitialized, r.URL.Path)
+		return
+	}
+
+	// PutBucketEncryption does not support bucket policies, use checkAuth to validate signature.
+	if s3Error := checkAuth(r); s3Error != ErrNone {
+		errorIf(errSignatureMismatch, dumpRequest(r))
+		writeErrorResponse(w, r, s3Error, r.URL.Path)
+		return
+	}
+
+	vars := mux.Vars(r)
+	bucket := vars["bucket"]
+
+	// If Content-Length is unknown or zero, deny the request.
+	if !contains(r.TransferEncoding, "chunked") {
+		if r.ContentLength == -1 || r.ContentLength == 0 {
+			writeErrorResponse(w, r, ErrMissingContentLength, r.URL.Path)
+			return
+		}
+		// If Content-Length is greater than maximum allowed configuration size.
+		if r.ContentLength > maxBucketEncryptionConfigSize {
+			writeErrorResponse(w, r, ErrEntityTooLarge, r.URL.Path)
+			return
+		}
+	}
+
+	var config sseConfiguration
+	if err := xml.NewDecoder(io.LimitReader(r.Body, maxBucketEncryptionConfigSize)).Decode(&config); err != nil {
+		errorIf(err, "Unable to parse bucket encryption configuration XML.")
+		writeErrorResponse(w, r, ErrMalformedXML, r.URL.Path)
+		return
+	}
+	encryption, s3Error := config.toBucketEncryption()
+	if s3Error != ErrNone {
+		writeErrorResponse(w, r, s3Error, r.URL.Path)
+		return
+	}
+
+	if err := persistBucketEncryption(bucket, encryption, objAPI); err != nil {
+		errorIf(err, "Unable to save bucket encryption.")
+		writeErrorResponse(w, r, toAPIErrorCode(err), r.URL.Path)
+		return
+	}
+
+	// Success.
+	writeSuccessResponse(w, nil)
+}
+
+// DeleteBucketEncryptionHandler - DELETE Bucket encryption
+// -----------------
+// This operation uses the encryption subresource to remove the default
+// server side encryption of a bucket, existing objects stay encrypted.
+func (api objectAPIHandlers) DeleteBucketEncryptionHandler(w http.ResponseWriter, r *http.Request) {
+	objAPI := api.ObjectAPI()
+	if objAPI == nil {
+		writeErrorResponse(w, r, ErrServerNotInitialized, r.URL.Path)
+		return
+	}
+
+	// DeleteBucketEncryption does not support bucket policies, use checkAuth to validate signature.
+	if s3Error := checkAuth(r); s3Error != ErrNone {
+		errorIf(errSignatureMismatch, dumpRequest(r))
+		writeErrorResponse(w, r, s3Error, r.URL.Path)
+		return
+	}
+
+	vars := mux.Vars(r)
+	bucket := vars["bucket"]
+
+	if err := persistBucketEncryption(bucket, nil, objAPI); err != nil {
+		errorIf(err, "Unable to remove bucket encryption.")
+		writeErrorResponse(w, r, toAPIErrorCode(err), r.URL.Path)
+		return
+	}
+
+	// Success.
+	writeSuccessNoContent(w)
+}
+
+// GetBucketEncryptionHandler - GET Bucket encryption
+// -----------------
+// This operation uses the encryption subresource to return the default
+// server side encryption of a bucket.
+func (api objectAPIHandlers) GetBucketEncryptionHandler(w http.ResponseWriter, r *http.Request) {
+	objAPI := api.ObjectAPI()
+	if objAPI == nil {
+		writeErrorResponse(w, r, ErrServerNotInitialized, r.URL.Path)
+		return
+	}
+
+	// GetBucketEncryption does not support bucket policies, use checkAuth to validate signature.
+	if s3Error := checkAuth(r); s3Error != ErrNone {
+		errorIf(errSignatureMismatch, dumpRequest(r))
+		writeErrorResponse(w, r, s3Error, r.URL.Path)
+		return
+	}
+
+	vars := mux.Vars(r)
+	bucket := vars["bucket"]
+
+	if err := isBucketExist(bucket, objAPI); err != nil {
+		writeErrorResponse(w, r, toAPIErrorCode(err), r.URL.Path)
+		return
+	}
+	settings, err := readBucketSettings(bucket, objAPI)
+	if err != nil {
+		if _, ok := err.(BucketSettingsNotFound); !ok {
+			errorIf(err, "Unable to read bucket settings.")
+			writeErrorResponse(w, r, toAPIErrorCode(err), r.URL.Path)
+			return
+		}
+	}
+	if settings == nil || settings.Encryption == nil {
+		writeErrorResponse(w, r, ErrNoSuchEncryptionConfiguration, r.URL.Path)
+		return
+	}
+
+	config := sseConfiguration{
+		Rules: []sseRule{{
+			ApplyServerSideEncryptionByDefault: sseDefault{SSEAlgorithm: settings.Encryption.Algorithm},
+			DenyUnencryptedUploads:             settings.Encryption.DenyUnencrypted,
+		}},
+	}
+	writeSuccessResponse(w, encodeResponse(config))
+}
@@ -0,0 +1,428 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package cmd
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"hash"
+	"io"
+	"strings"
+)
+
+// Objects encrypted server side with SSE-S3 are stored with AES-256-CTR
+// under a random key per object. The object key is sealed with
+// AES-256-GCM under the master key set by MINIO_SSE_MASTER_KEY, sealed
+// key and IV are kept base64 encoded in internal metadata of the object
+// which is never returned to clients. CTR mode keeps the size of
+// objects, ranges are decrypted by seeking the key stream.
+const (
+	sseAlgorithmAES256 = "AES256"
+	sseMasterKeySize   = 32
+	sseObjectKeySize   = 32
+	sseNonceSize       = 12
+
+	// Metadata of encrypted objects, the algorithm is returned to
+	// clients as the 'X-Amz-Server-Side-Encryption' header.
+	sseMetaAlgorithm      = "x-amz-server-side-encryption"
+	sseInternalMetaPrefix = "X-Minio-Internal-Sse-"
+	sseMetaSealedKey      = sseInternalMetaPrefix + "Sealed-Key"
+	sseMetaIV             = sseInternalMetaPrefix + "Iv"
+	// ETag of the plain data, returned to clients instead of the one
+	// of the encrypted data.
+	sseMetaETag = sseInternalMetaPrefix + "Etag"
+)
+
+// errSSENotConfigured - encryption was asked for but no master key is set.
+var errSSENotConfigured = errors.New("Server side encryption requires MINIO_SSE_MASTER_KEY to be set")
+
+// errInvalidEncryptionMethod - encryption other than SSE-S3 was asked for.
+var errInvalidEncryptionMethod = errors.New("Only AES256 server side encryption is supported")
+
+// errUnencryptedUpload - upload without encryption to a bucket denying them.
+var errUnencryptedUpload = errors.New("Bucket denies uploads without server side encryption")
+
+// errSSEKeyUnsealFailed - object key cannot be unsealed with the master key.
+var errSSEKeyUnsealFailed = errors.New("Unable to unseal the object key, MINIO_SSE_MASTER_KEY is not the key objects were encrypted with")
+
+// bucketEncryption - default encryption of objects uploaded to a bucket.
+type bucketEncryption struct {
+	// Algorithm applied to uploads which ask for no encryption, only
+	// AES256 is supported.
+	Algorithm string `json:"algorithm"`
+
+	// Reject uploads which ask for no encryption, instead of
+	// encrypting them by default.
+	DenyUnencrypted bool `json:"denyUnencrypted,omitempty"`
+}
+
+// validate - validates the encryption algorithm.
+func (e bucketEncryption) validate() error {
+	if e.Algorithm != sseAlgorithmAES256 {
+		return errInvalidEncryptionMethod
+	}
+	return nil
+}
+
+// applyDefault - asks for the default encryption in the metadata of a
+// new object which asks for none, unless such uploads are denied.
+func (e bucketEncryption) applyDefault(metadata map[string]string) {
+	if !e.DenyUnencrypted && metadata[sseMetaAlgorithm] == "" {
+		metadata[sseMetaAlgorithm] = e.Algorithm
+	}
+}
+
+// applyBucketEncryption - applies only the default encryption of bucket
+// to the metadata of a new object, for copies keeping the metadata of
+// their source.
+func applyBucketEncryption(bucket string, metadata map[string]string) {
+	if settings := globalBucketSettings.GetBucketSettings(bucket); settings != nil && settings.Encryption != nil {
+		settings.Encryption.applyDefault(metadata)
+	}
+}
+
+// checkEncryptionSettingsChange - returns errSSENotConfigured if the new
+// settings of a bucket enable encryption without a master key.
+func checkEncryptionSettingsChange(settings *bucketSettings) error {
+	if settings != nil && settings.Encryption != nil && globalSSEMasterKey == nil {
+		return errSSENotConfigured
+	}
+	return nil
+}
+
+// parseSSEMasterKey - parses the hex encoded 256 bit master key.
+func parseSSEMasterKey(s string) ([]byte, error) {
+	key, err := hex.DecodeString(s)
+	if err != nil || len(key) != sseMasterKeySize {
+		return nil, errInvalidArgument
+	}
+	return key, nil
+}
+
+// checkEncryptedUpload - validates the encryption asked for in the
+// metadata of a new object of bucket.
+func checkEncryptedUpload(bucket string, metadata map[string]string) error {
+	algorithm := metadata[sseMetaAlgorithm]
+	if algorithm == "" {
+		settings := globalBucketSettings.GetBucketSettings(bucket)
+		if settings != nil && settings.Encryption != nil && settings.Encryption.DenyUnencrypted {
+			return errUnencryptedUpload
+		}
+		return nil
+	}
+	if algorithm != sseAlgorithmAES256 {
+		return errInvalidEncryptionMethod
+	}
+	if globalSSEMasterKey == nil {
+		return errSSENotConfigured
+	}
+	return nil
+}
+
+// sealObjectKey - seals an object key with the master key.
+func sealObjectKey(masterKey, objectKey []byte) (string, error) {
+	block, err := aes.NewCipher(masterKey)
+	if err != nil {
+		return "", err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, sseNonceSize)
+	if _, err = io.ReadFull(rand.Reader, nonce); err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(gcm.Seal(nonce, nonce, objectKey, nil)), nil
+}
+
+// unsealObjectKey - unseals an object key sealed with the master key.
+func unsealObjectKey(masterKey []byte, sealedKey string) ([]byte, error) {
+	sealed, err := base64.StdEncoding.DecodeString(sealedKey)
+	if err != nil || len(sealed) < sseNonceSize {
+		return nil, errSSEKeyUnsealFailed
+	}
+	block, err := aes.NewCipher(masterKey)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	objectKey, err := gcm.Open(nil, sealed[:sseNonceSize], sealed[sseNonceSize:], nil)
+	if err != nil {
+		return nil, errSSEKeyUnsealFailed
+	}
+	return objectKey, nil
+}
+
+// unsealObjectKeyAny - unseals an object key with the current master
+// key or otherwise with one of the old master keys being rotated out,
+// returns true if the key is sealed with the current master key.
+func unsealObjectKeyAny(sealedKey string) (objectKey []byte, current bool, err error) {
+	if objectKey, err = unsealObjectKey(globalSSEMasterKey, sealedKey); err == nil {
+		return objectKey, true, nil
+	}
+	for _, masterKey := range globalSSEOldMasterKeys {
+		if objectKey, err = unsealObjectKey(masterKey, sealedKey); err == nil {
+			return objectKey, false, nil
+		}
+	}
+	return nil, false, err
+}
+
+// newSSEObjectKey - returns a new random object key and IV, along with
+// the object key sealed with the master key.
+func newSSEObjectKey() (objectKey, iv []byte, sealedKey string, err error) {
+	objectKey = make([]byte, sseObjectKeySize)
+	iv = make([]byte, aes.BlockSize)
+	if _, err = io.ReadFull(rand.Reader, objectKey); err != nil {
+		return nil, nil, "", traceError(err)
+	}
+	if _, err = io.ReadFull(rand.Reader, iv); err != nil {
+		return nil, nil, "", traceError(err)
+	}
+	if sealedKey, err = sealObjectKey(globalSSEMasterKey, objectKey); err != nil {
+		return nil, nil, "", traceError(err)
+	}
+	return objectKey, iv, sealedKey, nil
+}
+
+// newSSEStream - returns the AES-256-CTR key stream of an object
+// starting at offset.
+func newSSEStream(objectKey, iv []byte, offset int64) (cipher.Stream, error) {
+	block, err := aes.NewCipher(objectKey)
+	if err != nil {
+		return nil, err
+	}
+	// Add the number of blocks before offset to the big endian counter.
+	counter := make([]byte, aes.BlockSize)
+	copy(counter, iv)
+	carry := uint64(offset / aes.BlockSize)
+	for i := len(counter) - 1; i >= 0 && carry > 0; i-- {
+		carry += uint64(counter[i])
+		counter[i] = byte(carry)
+		carry >>= 8
+	}
+	stream := cipher.NewCTR(block, counter)
+	// Skip the key stream of the block before offset.
+	skip := make([]byte, offset%aes.BlockSize)
+	stream.XORKeyStream(skip, skip)
+	return stream, nil
+}
+
+// isEncryptedObject - returns true if the object was stored encrypted.
+func isEncryptedObject(metadata map[string]string) bool {
+	return metadata[sseMetaSealedKey] != ""
+}
+
+// removeSSEMetadata - returns a copy of metadata without the internal
+// encryption metadata.
+func removeSSEMetadata(metadata map[string]string) map[string]string {
+	if metadata == nil {
+		return nil
+	}
+	m := make(map[string]string, len(metadata))
+	for k, v := range metadata {
+		if !strings.HasPrefix(k, sseInternalMetaPrefix) {
+			m[k] = v
+		}
+	}
+	return m
+}
+
+// sseVerifyReader - verifies the Content-MD5 and SHA256 of plain data
+// once all of it is read, so that the underlying object layer never
+// commits objects with mismatching content. The ETag of the plain data
+// is then set in metadata, if any, which object layers only store once
+// all the data is read.
+type sseVerifyReader struct {
+	reader    io.Reader
+	size      int64
+	read      int64
+	md5Hex    string
+	sha256sum string
+	etag      *etagWriter
+	sha256    hash.Hash
+	metadata  map[string]string
+}
+
+func newSSEVerifyReader(reader io.Reader, size int64, md5Hex, sha256sum string, metadata map[string]string) *sseVerifyReader {
+	return &sseVerifyReader{
+		reader:    reader,
+		size:      size,
+		md5Hex:    md5Hex,
+		sha256sum: sha256sum,
+		etag:      newETagWriter(md5Hex),
+		sha256:    sha256.New(),
+		metadata:  metadata,
+	}
+}
+
+func (v *sseVerifyReader) Read(p []byte) (int, error) {
+	n, err := v.reader.Read(p)
+	v.etag.Write(p[:n])
+	v.sha256.Write(p[:n])
+	v.read += int64(n)
+	// Object layers stop reading at size without waiting for io.EOF.
+	if err == io.EOF || (v.size > 0 && v.read == v.size) {
+		if vErr := v.verify(); vErr != nil {
+			return n, vErr
+		}
+	}
+	return n, err
+}
+
+func (v *sseVerifyReader) verify() error {
+	if v.md5Hex != "" {
+		if gotMD5Hex := v.etag.MD5(); gotMD5Hex != v.md5Hex {
+			return BadDigest{v.md5Hex, gotMD5Hex}
+		}
+	}
+	if v.sha256sum != "" {
+		if hex.EncodeToString(v.sha256.Sum(nil)) != v.sha256sum {
+			return SHA256Mismatch{}
+		}
+	}
+	if v.metadata != nil {
+		v.metadata[sseMetaETag] = v.etag.ETag()
+	}
+	return nil
+}
+
+// sseObjectInfo - returns the object info as seen by clients, without
+// internal encryption metadata and with the ETag of the plain data.
+func sseObjectInfo(objInfo ObjectInfo) ObjectInfo {
+	if etag := objInfo.UserDefined[sseMetaETag]; etag != "" {
+		objInfo.MD5Sum = etag
+	}
+	objInfo.UserDefined = removeSSEMetadata(objInfo.UserDefined)
+	return objInfo
+}
+
+// encryptedObjects - object layer encrypting objects which ask for
+// server side encryption in their metadata, either by the client or by
+// default of their bucket.
+type encryptedObjects struct {
+	ObjectLayer
+}
+
+// newEncryptedObjects - returns objAPI with server side encryption.
+func newEncryptedObjects(objAPI ObjectLayer) ObjectLayer {
+	return encryptedObjects{objAPI}
+}
+
+// ListObjects - lists objects, without internal encryption metadata.
+func (e encryptedObjects) ListObjects(bucket, prefix, marker, delimiter string, maxKeys int) (ListObjectsInfo, error) {
+	result, err := e.ObjectLayer.ListObjects(bucket, prefix, marker, delimiter, maxKeys)
+	if err != nil {
+		return result, err
+	}
+	for i := range result.Objects {
+		result.Objects[i] = sseObjectInfo(result.Objects[i])
+	}
+	return result, nil
+}
+
+// GetObjectInfo - returns the object info, without internal encryption
+// metadata.
+func (e encryptedObjects) GetObjectInfo(bucket, object string) (ObjectInfo, error) {
+	objInfo, err := e.ObjectLayer.GetObjectInfo(bucket, object)
+	if err != nil {
+		return objInfo, err
+	}
+	return sseObjectInfo(objInfo), nil
+}
+
+// GetObject - writes length bytes of object starting at startOffset to
+// writer, decrypting encrypted objects.
+func (e encryptedObjects) GetObject(bucket, object string, startOffset int64, length int64, writer io.Writer) error {
+	objInfo, err := e.ObjectLayer.GetObjectInfo(bucket, object)
+	if err != nil {
+		return err
+	}
+	if !isEncryptedObject(objInfo.UserDefined) {
+		return e.ObjectLayer.GetObject(bucket, object, startOffset, length, writer)
+	}
+	if globalSSEMasterKey == nil {
+		return traceError(errSSENotConfigured)
+	}
+	if startOffset < 0 {
+		return traceError(InvalidRange{startOffset, length, objInfo.Size})
+	}
+	objectKey, _, err := unsealObjectKeyAny(objInfo.UserDefined[sseMetaSealedKey])
+	if err != nil {
+		return traceError(err)
+	}
+	iv, err := base64.StdEncoding.DecodeString(objInfo.UserDefined[sseMetaIV])
+	if err != nil || len(iv) != aes.BlockSize {
+		return traceError(errSSEKeyUnsealFailed)
+	}
+	if objInfo.UserDefined[sseMetaMultipart] != "" {
+		return e.getMultipartObject(bucket, object, objInfo, objectKey, iv, startOffset, length, writer)
+	}
+	stream, err := newSSEStream(objectKey, iv, startOffset)
+	if err != nil {
+		return traceError(err)
+	}
+	return e.ObjectLayer.GetObject(bucket, object, startOffset, length, keepCacheBypassWriter(writer, cipher.StreamWriter{S: stream, W: writer}))
+}
+
+// PutObject - stores an object, encrypted if its metadata asks for it.
+func (e encryptedObjects) PutObject(bucket, object string, size int64, data io.Reader, metadata map[string]string, sha256sum string) (ObjectInfo, error) {
+	if err := checkEncryptedUpload(bucket, metadata); err != nil {
+		return ObjectInfo{}, traceError(err)
+	}
+	// Copies of encrypted objects are encrypted with a new key.
+	metadata = removeSSEMetadata(metadata)
+	if metadata[sseMetaAlgorithm] == "" {
+		return e.ObjectLayer.PutObject(bucket, object, size, data, metadata, sha256sum)
+	}
+
+	objectKey, iv, sealedKey, err := newSSEObjectKey()
+	if err != nil {
+		return ObjectInfo{}, err
+	}
+	stream, err := newSSEStream(objectKey, iv, 0)
+	if err != nil {
+		return ObjectInfo{}, traceError(err)
+	}
+	metadata[sseMetaSealedKey] = sealedKey
+	metadata[sseMetaIV] = base64.StdEncoding.EncodeToString(iv)
+
+	// Digests of the client are of the plain data, the ETag of the
+	// plain data is stored along and returned instead of the one of
+	// the encrypted data.
+	md5Hex := metadata["md5Sum"]
+	delete(metadata, "md5Sum")
+	if size > 0 {
+		data = io.LimitReader(data, size)
+	}
+	verifier := newSSEVerifyReader(data, size, md5Hex, sha256sum, metadata)
+	objInfo, err := e.ObjectLayer.PutObject(bucket, object, size, cipher.StreamReader{S: stream, R: verifier}, metadata, "")
+	if err != nil {
+		return objInfo, err
+	}
+	objInfo = sseObjectInfo(objInfo)
+	objInfo.MD5Sum = verifier.etag.ETag()
+	return objInfo, nil
+}
@@ -0,0 +1,389 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package cmd
+
+import (
+	"bytes"
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/xml"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// Tests the key stream seeked to an offset matches the one of the
+// whole object.
+func TestNewSSEStream(t *testing.T) {
+	key := bytes.Repeat([]byte{1}, sseObjectKeySize)
+	// Counter overflowing into the higher bytes.
+	iv := []byte{0, 0, 0, 0, 0, 0, 0, 0, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xfe}
+	data := make([]byte, 1000)
+	rand.New(rand.NewSource(1)).Read(data)
+
+	stream, err := newSSEStream(key, iv, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	encrypted := make([]byte, len(data))
+	stream.XORKeyStream(encrypted, data)
+
+	for _, offset := range []int64{0, 1, 15, 16, 17, 33, 999} {
+		stream, err = newSSEStream(key, iv, offset)
+		if err != nil {
+			t.Fatal(err)
+		}
+		decrypted := make([]byte, len(data)-int(offset))
+		stream.XORKeyStream(decrypted, encrypted[offset:])
+		if !bytes.Equal(decrypted, data[offset:]) {
+			t.Errorf("Offset %d: Unexpected decrypted data", offset)
+		}
+	}
+}
+
+// Tests sealed object keys are only unsealed with the same master key.
+func TestSealObjectKey(t *testing.T) {
+	masterKey := bytes.Repeat([]byte{1}, sseMasterKeySize)
+	objectKey := bytes.Repeat([]byte{2}, sseObjectKeySize)
+	sealedKey, err := sealObjectKey(masterKey, objectKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	unsealedKey, err := unsealObjectKey(masterKey, sealedKey)
+	if err != nil || !bytes.Equal(unsealedKey, objectKey) {
+		t.Errorf("Unexpected unsealed key %x (%v)", unsealedKey, err)
+	}
+	if _, err = unsealObjectKey(bytes.Repeat([]byte{3}, sseMasterKeySize), sealedKey); err != errSSEKeyUnsealFailed {
+		t.Errorf("Expected %v, got %v", errSSEKeyUnsealFailed, err)
+	}
+	if _, err = parseSSEMasterKey("abcd"); err != errInvalidArgument {
+		t.Errorf("Expected %v, got %v", errInvalidArgument, err)
+	}
+}
+
+// Wrapper for calling encryption tests for both XL and FS.
+func TestEncryptedObjects(t *testing.T) {
+	ExecObjectLayerTest(t, testEncryptedObjects)
+}
+
+// Tests objects asking for encryption are stored encrypted and read
+// back decrypted.
+func testEncryptedObjects(obj ObjectLayer, instanceType string, t TestErrHandler) {
+	bucket := "encrypted-bucket"
+	if err := obj.MakeBucket(bucket); err != nil {
+		t.Fatalf("%s: Unable to make bucket: %v", instanceType, err)
+	}
+	encObj := newEncryptedObjects(obj)
+
+	data := make([]byte, 100*1024)
+	rand.New(rand.NewSource(1)).Read(data)
+	md5Sum := md5.Sum(data)
+	md5Hex := hex.EncodeToString(md5Sum[:])
+	sseMetadata := func() map[string]string {
+		return map[string]string{sseMetaAlgorithm: sseAlgorithmAES256, "md5Sum": md5Hex}
+	}
+
+	// No master key.
+	globalSSEMasterKey = nil
+	if _, err := encObj.PutObject(bucket, "object", int64(len(data)), bytes.NewReader(data), sseMetadata(), ""); errorCause(err) != errSSENotConfigured {
+		t.Fatalf("%s: Expected %v, got %v", instanceType, errSSENotConfigured, err)
+	}
+	globalSSEMasterKey = bytes.Repeat([]byte{1}, sseMasterKeySize)
+	defer func() { globalSSEMasterKey = nil }()
+
+	// Only AES256 is supported.
+	if _, err := encObj.PutObject(bucket, "object", int64(len(data)), bytes.NewReader(data), map[string]string{sseMetaAlgorithm: "aws:kms"}, ""); errorCause(err) != errInvalidEncryptionMethod {
+		t.Fatalf("%s: Expected %v, got %v", instanceType, errInvalidEncryptionMethod, err)
+	}
+	// Digests are of the plain data.
+	badMetadata := sseMetadata()
+	badMetadata["md5Sum"] = hex.EncodeToString(make([]byte, md5.Size))
+	if _, err := encObj.PutObject(bucket, "object", int64(len(data)), bytes.NewReader(data), badMetadata, ""); err == nil {
+		t.Fatalf("%s: Expected a bad digest error", instanceType)
+	}
+	if _, err := obj.GetObjectInfo(bucket, "object"); err == nil {
+		t.Fatalf("%s: Object with a bad digest was stored", instanceType)
+	}
+
+	objInfo, err := encObj.PutObject(bucket, "object", int64(len(data)), bytes.NewReader(data), sseMetadata(), "")
+	if err != nil {
+		t.Fatalf("%s: Unable to put object: %v", instanceType, err)
+	}
+	if objInfo.Size != int64(len(data)) || objInfo.UserDefined[sseMetaAlgorithm] != sseAlgorithmAES256 || isEncryptedObject(objInfo.UserDefined) {
+		t.Errorf("%s: Unexpected object info %+v", instanceType, objInfo)
+	}
+	// The ETag is the md5sum of the plain data.
+	if objInfo.MD5Sum != md5Hex {
+		t.Errorf("%s: Expected ETag %s, got %s", instanceType, md5Hex, objInfo.MD5Sum)
+	}
+
+	// Stored data is encrypted.
+	var buffer bytes.Buffer
+	if err = obj.GetObject(bucket, "object", 0, int64(len(data)), &buffer); err != nil {
+		t.Fatalf("%s: Unable to get object: %v", instanceType, err)
+	}
+	if bytes.Equal(buffer.Bytes(), data) {
+		t.Errorf("%s: Object is stored unencrypted", instanceType)
+	}
+
+	// Internal metadata is never returned.
+	objInfo, err = encObj.GetObjectInfo(bucket, "object")
+	if err != nil || isEncryptedObject(objInfo.UserDefined) || objInfo.UserDefined[sseMetaAlgorithm] != sseAlgorithmAES256 || objInfo.MD5Sum != md5Hex {
+		t.Errorf("%s: Unexpected object info %+v (%v)", instanceType, objInfo, err)
+	}
+	if _, ok := objInfo.UserDefined[sseMetaETag]; ok {
+		t.Errorf("%s: Unexpected internal metadata %v", instanceType, objInfo.UserDefined)
+	}
+	result, err := encObj.ListObjects(bucket, "", "", "", 10)
+	if err != nil || len(result.Objects) != 1 || isEncryptedObject(result.Objects[0].UserDefined) || result.Objects[0].MD5Sum != md5Hex {
+		t.Errorf("%s: Unexpected list result %+v (%v)", instanceType, result, err)
+	}
+
+	ranges := []struct{ offset, length int64 }{
+		{0, int64(len(data))},
+		{10, 100},
+		{17, 1},
+		{int64(len(data)) - 1, 1},
+	}
+	for i, r := range ranges {
+		buffer.Reset()
+		if err = encObj.GetObject(bucket, "object", r.offset, r.length, &buffer); err != nil {
+			t.Fatalf("%s: Range %d: Unable to get object: %v", instanceType, i+1, err)
+		}
+		if !bytes.Equal(buffer.Bytes(), data[r.offset:r.offset+r.length]) {
+			t.Errorf("%s: Range %d: Unexpected data", instanceType, i+1)
+		}
+	}
+
+	// Default encryption of the bucket.
+	globalBucketSettings.SetBucketSettings(bucket, &bucketSettings{Encryption: &bucketEncryption{Algorithm: sseAlgorithmAES256}})
+	defer globalBucketSettings.SetBucketSettings(bucket, nil)
+	metadata := make(map[string]string)
+	applyBucketDefaults(bucket, "default", metadata)
+	if _, err = encObj.PutObject(bucket, "default", int64(len(data)), bytes.NewReader(data), metadata, ""); err != nil {
+		t.Fatalf("%s: Unable to put object: %v", instanceType, err)
+	}
+	if objInfo, err = obj.GetObjectInfo(bucket, "default"); err != nil || !isEncryptedObject(objInfo.UserDefined) {
+		t.Errorf("%s: Expected object to be encrypted by default (%v)", instanceType, err)
+	}
+
+	// Parts of multipart objects are encrypted as they are uploaded.
+	testEncryptedMultipart(obj, encObj, instanceType, bucket, data, t)
+	// Parts are recorded in the manifest of deduplicated objects.
+	dedupBucket := "encrypted-dedup-bucket"
+	if err = obj.MakeBucket(dedupBucket); err != nil {
+		t.Fatalf("%s: Unable to make bucket: %v", instanceType, err)
+	}
+	globalBucketSettings.SetBucketSettings(dedupBucket, &bucketSettings{Dedup: true})
+	defer globalBucketSettings.SetBucketSettings(dedupBucket, nil)
+	dedupObj := newDedupObjects(obj)
+	testEncryptedMultipart(dedupObj, newEncryptedObjects(dedupObj), instanceType, dedupBucket, data, t)
+
+	// Uploads without encryption are denied.
+	globalBucketSettings.SetBucketSettings(bucket, &bucketSettings{Encryption: &bucketEncryption{Algorithm: sseAlgorithmAES256, DenyUnencrypted: true}})
+	metadata = make(map[string]string)
+	applyBucketDefaults(bucket, "denied", metadata)
+	if _, err = encObj.PutObject(bucket, "denied", int64(len(data)), bytes.NewReader(data), metadata, ""); errorCause(err) != errUnencryptedUpload {
+		t.Errorf("%s: Expected %v, got %v", instanceType, errUnencryptedUpload, err)
+	}
+	if _, err = encObj.NewMultipartUpload(bucket, "denied", metadata); errorCause(err) != errUnencryptedUpload {
+		t.Errorf("%s: Expected %v, got %v", instanceType, errUnencryptedUpload, err)
+	}
+}
+
+// Wrapper for calling bucket encryption HTTP handler tests for both XL multiple disks and single node setup.
+func TestBucketEncryptionHandlers(t *testing.T) {
+	ExecObjectLayerAPITest(t, testBucketEncryptionHandlers, []string{"PutBucketEncryption", "GetBucketEncryption", "DeleteBucketEncryption"})
+}
+
+func testBucketEncryptionHandlers(obj ObjectLayer, instanceType, bucketName string, apiRouter http.Handler,
+	credentials credential, t *testing.T) {
+	if err := initBucketSettings(obj); err != nil {
+		t.Fatalf("%s: Unable to initialize bucket settings: %s", instanceType, err)
+	}
+	initGlobalS3Peers([]string{})
+
+	doRequest := func(method, urlStr string, body []byte) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		req, err := newTestSignedRequestV4(method, urlStr, int64(len(body)), bytes.NewReader(body),
+			credentials.AccessKeyID, credentials.SecretAccessKey)
+		if err != nil {
+			t.Fatalf("%s: Failed to create HTTP request: <ERROR> %v", instanceType, err)
+		}
+		apiRouter.ServeHTTP(rec, req)
+		return rec
+	}
+	configXML := func(algorithm string) []byte {
+		return []byte(`<ServerSideEncryptionConfiguration xmlns="http://s3.amazonaws.com/doc/2006-03-01/"><Rule><ApplyServerSideEncryptionByDefault><SSEAlgorithm>` +
+			algorithm + `</SSEAlgorithm></ApplyServerSideEncryptionByDefault><DenyUnencryptedUploads>true</DenyUnencryptedUploads></Rule></ServerSideEncryptionConfiguration>`)
+	}
+
+	// No encryption yet.
+	rec := doRequest("GET", getBucketEncryptionURL("", bucketName), nil)
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("%s: Expected %d, got %d", instanceType, http.StatusNotFound, rec.Code)
+	}
+
+	// No master key.
+	globalSSEMasterKey = nil
+	rec = doRequest("PUT", getBucketEncryptionURL("", bucketName), configXML(sseAlgorithmAES256))
+	if rec.Code != http.StatusNotImplemented {
+		t.Fatalf("%s: Expected %d, got %d", instanceType, http.StatusNotImplemented, rec.Code)
+	}
+	globalSSEMasterKey = bytes.Repeat([]byte{1}, sseMasterKeySize)
+	defer func() { globalSSEMasterKey = nil }()
+
+	// KMS is not supported.
+	rec = doRequest("PUT", getBucketEncryptionURL("", bucketName), configXML("aws:kms"))
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("%s: Expected %d, got %d", instanceType, http.StatusBadRequest, rec.Code)
+	}
+	rec = doRequest("PUT", getBucketEncryptionURL("", bucketName), []byte("<ServerSideEncryptionConfiguration>"))
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("%s: Expected %d, got %d", instanceType, http.StatusBadRequest, rec.Code)
+	}
+
+	rec = doRequest("PUT", getBucketEncryptionURL("", bucketName), configXML(sseAlgorithmAES256))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("%s: Expected %d, got %d", instanceType, http.StatusOK, rec.Code)
+	}
+	settings, err := readBucketSettings(bucketName, obj)
+	if err != nil || settings.Encryption == nil || !settings.Encryption.DenyUnencrypted {
+		t.Fatalf("%s: Unexpected bucket settings %+v (%v)", instanceType, settings, err)
+	}
+
+	rec = doRequest("GET", getBucketEncryptionURL("", bucketName), nil)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("%s: Expected %d, got %d", instanceType, http.StatusOK, rec.Code)
+	}
+	var config sseConfiguration
+	if err = xml.Unmarshal(rec.Body.Bytes(), &config); err != nil {
+		t.Fatalf("%s: Unable to parse response: %v", instanceType, err)
+	}
+	if len(config.Rules) != 1 || config.Rules[0].ApplyServerSideEncryptionByDefault.SSEAlgorithm != sseAlgorithmAES256 || !config.Rules[0].DenyUnencryptedUploads {
+		t.Errorf("%s: Unexpected configuration %+v", instanceType, config)
+	}
+
+	// Removing the encryption removes the then empty settings.
+	rec = doRequest("DELETE", getBucketEncryptionURL("", bucketName), nil)
+	if rec.Code != http.StatusNoContent {
+		t.Fatalf("%s: Expected %d, got %d", instanceType, http.StatusNoContent, rec.Code)
+	}
+	if _, err = readBucketSettings(bucketName, obj); err == nil {
+		t.Errorf("%s: Expected bucket settings to be removed", instanceType)
+	}
+	rec = doRequest("DELETE", getBucketEncryptionURL("", bucketName), nil)
+	if rec.Code != http.StatusNoContent {
+		t.Fatalf("%s: Expected %d, got %d", instanceType, http.StatusNoContent, rec.Code)
+	}
+}
+
+// Tests the parts of encrypted multipart uploads are stored encrypted,
+// while their ETags and the data read back are the ones of the plain data.
+func testEncryptedMultipart(obj, encObj ObjectLayer, instanceType, bucket string, data []byte, t TestErrHandler) {
+	firstPart := make([]byte, minPartSize)
+	rand.New(rand.NewSource(2)).Read(firstPart)
+	parts := [][]byte{firstPart, data}
+	plain := append(append([]byte{}, firstPart...), data...)
+
+	uploadID, err := encObj.NewMultipartUpload(bucket, "multipart", map[string]string{sseMetaAlgorithm: sseAlgorithmAES256})
+	if err != nil {
+		t.Fatalf("%s: Unable to initiate multipart upload: %v", instanceType, err)
+	}
+	var completeParts []completePart
+	for i, part := range parts {
+		sum := md5.Sum(part)
+		partMD5, pErr := encObj.PutObjectPart(bucket, "multipart", uploadID, i+1, int64(len(part)), bytes.NewReader(part), hex.EncodeToString(sum[:]), "")
+		if pErr != nil {
+			t.Fatalf("%s: Unable to put part: %v", instanceType, pErr)
+		}
+		if partMD5 != hex.EncodeToString(sum[:]) {
+			t.Errorf("%s: Expected part ETag %x, got %s", instanceType, sum, partMD5)
+		}
+		completeParts = append(completeParts, completePart{PartNumber: i + 1, ETag: "\"" + partMD5 + "\""})
+	}
+	listed, err := encObj.ListObjectParts(bucket, "multipart", uploadID, 0, 10)
+	if err != nil {
+		t.Fatalf("%s: Unable to list parts: %v", instanceType, err)
+	}
+	for i, part := range listed.Parts {
+		if "\""+part.ETag+"\"" != completeParts[i].ETag {
+			t.Errorf("%s: Unexpected listed part %+v", instanceType, part)
+		}
+	}
+	// Parts are identified by the ETags of their plain data.
+	wrongParts := []completePart{completeParts[0], {PartNumber: 2, ETag: hex.EncodeToString(make([]byte, md5.Size))}}
+	if _, err = encObj.CompleteMultipartUpload(bucket, "multipart", uploadID, wrongParts); err == nil {
+		t.Errorf("%s: Expected an invalid part error", instanceType)
+	}
+
+	md5Sum, err := encObj.CompleteMultipartUpload(bucket, "multipart", uploadID, completeParts)
+	if err != nil {
+		t.Fatalf("%s: Unable to complete multipart upload: %v", instanceType, err)
+	}
+	sum0, sum1 := md5.Sum(firstPart), md5.Sum(data)
+	expectedMD5Sum, err := completeMultipartMD5(completePart{1, hex.EncodeToString(sum0[:])}, completePart{2, hex.EncodeToString(sum1[:])})
+	if err != nil || md5Sum != expectedMD5Sum {
+		t.Errorf("%s: Expected ETag %s, got %s (%v)", instanceType, expectedMD5Sum, md5Sum, err)
+	}
+	objInfo, err := encObj.GetObjectInfo(bucket, "multipart")
+	if err != nil || objInfo.MD5Sum != expectedMD5Sum || objInfo.Size != int64(len(plain)) {
+		t.Errorf("%s: Unexpected object info %+v (%v)", instanceType, objInfo, err)
+	}
+
+	// Stored data is encrypted.
+	var buffer bytes.Buffer
+	if err = obj.GetObject(bucket, "multipart", 0, int64(len(plain)), &buffer); err != nil {
+		t.Fatalf("%s: Unable to get stored data: %v", instanceType, err)
+	}
+	if bytes.Equal(buffer.Bytes()[:len(firstPart)], firstPart) || bytes.Equal(buffer.Bytes()[len(firstPart):], data) {
+		t.Errorf("%s: Expected the parts to be stored encrypted", instanceType)
+	}
+	// Ranges within and across parts.
+	ranges := []struct{ offset, length int64 }{
+		{0, int64(len(plain))},
+		{1, 100},
+		{int64(len(firstPart)) - 10, 20},
+		{int64(len(firstPart)), int64(len(data))},
+		{int64(len(firstPart)) + 5, int64(len(data)) - 5},
+	}
+	for _, r := range ranges {
+		buffer.Reset()
+		if err = encObj.GetObject(bucket, "multipart", r.offset, r.length, &buffer); err != nil {
+			t.Fatalf("%s: Unable to get range %d-%d: %v", instanceType, r.offset, r.length, err)
+		}
+		if !bytes.Equal(buffer.Bytes(), plain[r.offset:r.offset+r.length]) {
+			t.Errorf("%s: Unexpected data of range %d-%d", instanceType, r.offset, r.length)
+		}
+	}
+
+	// Object keys of uploads are dropped once completed or aborted.
+	abortedID, err := encObj.NewMultipartUpload(bucket, "aborted", map[string]string{sseMetaAlgorithm: sseAlgorithmAES256})
+	if err != nil {
+		t.Fatalf("%s: Unable to initiate multipart upload: %v", instanceType, err)
+	}
+	if _, err = encObj.PutObjectPart(bucket, "aborted", abortedID, 1, int64(len(data)), bytes.NewReader(data), "", ""); err != nil {
+		t.Fatalf("%s: Unable to put part: %v", instanceType, err)
+	}
+	if err = encObj.AbortMultipartUpload(bucket, "aborted", abortedID); err != nil {
+		t.Fatalf("%s: Unable to abort multipart upload: %v", instanceType, err)
+	}
+	result, err := obj.ListObjects(minioMetaBucket, sseUploadsPrefix+slashSeparator, "", "", maxObjectList)
+	if err != nil || len(result.Objects) != 0 {
+		t.Errorf("%s: Expected no upload keys left, got %+v (%v)", instanceType, result.Objects, err)
+	}
+}
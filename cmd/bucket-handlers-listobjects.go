@@ -26,10 +26,9 @@ import (
 // Validate all the ListObjects query arguments, returns an APIErrorCode
 // if one of the args do not meet the required conditions.
 // Special conditions required by Minio server are as below
-// - delimiter if set should be equal to '/', otherwise the request is rejected.
 // - marker if set should have a common prefix with 'prefix' param, otherwise
 //   the request is rejected.
-func listObjectsValidateArgs(prefix, marker, delimiter string, maxKeys int) APIErrorCode {
+func listObjectsValidateArgs(prefix, marker string, maxKeys int) APIErrorCode {
 	// Max keys cannot be negative.
 	if maxKeys < 0 {
 		return ErrInvalidMaxKeys
@@ -37,10 +36,6 @@ func listObjectsValidateArgs(prefix, marker, delimiter string, maxKeys int) APIE
 
 	/// Minio special conditions for ListObjects.
 
-	// Verify if delimiter is anything other than '/', which we do not support.
-	if delimiter != "" && delimiter != "/" {
-		return ErrNotImplemented
-	}
 	// Marker is set validate pre-condition.
 	if marker != "" {
 		// Marker not common with prefix is not implemented.
@@ -98,16 +93,20 @@ func (api objectAPIHandlers) ListObjectsV2Handler(w http.ResponseWriter, r *http
 	// Extract all the listObjectsV2 query params to their native values.
 	prefix, token, startAfter, delimiter, fetchOwner, maxKeys, _ := getListObjectsV2Args(r.URL.Query())
 
-	// In ListObjectsV2 'continuation-token' is the marker.
-	marker := token
-	// Check if 'continuation-token' is empty.
-	if token == "" {
-		// Then we need to use 'start-after' as marker instead.
-		marker = startAfter
+	// In ListObjectsV2 'continuation-token' embeds the marker.
+	marker := startAfter
+	// Check if 'continuation-token' is set, then it takes precedence
+	// over 'start-after'.
+	if token != "" {
+		var err error
+		if marker, err = decodeContinuationToken(token); err != nil {
+			writeErrorResponse(w, r, ErrIncorrectContinuationToken, r.URL.Path)
+			return
+		}
 	}
 	// Validate the query params before beginning to serve the request.
 	// fetch-owner is not validated since it is a boolean
-	if s3Error := listObjectsValidateArgs(prefix, marker, delimiter, maxKeys); s3Error != ErrNone {
+	if s3Error := listObjectsValidateArgs(prefix, marker, maxKeys); s3Error != ErrNone {
 		writeErrorResponse(w, r, s3Error, r.URL.Path)
 		return
 	}
@@ -122,6 +121,13 @@ func (api objectAPIHandlers) ListObjectsV2Handler(w http.ResponseWriter, r *http
 	}
 
 	response := generateListObjectsV2Response(bucket, prefix, token, startAfter, delimiter, fetchOwner, maxKeys, listObjectsInfo)
+	if isListMetadataRequested(r) {
+		if err = addListObjectsMetadata(r, objectAPI, bucket, listObjectsInfo.Objects, response.Contents); err != nil {
+			errorIf(err, "Unable to read metadata of listed objects.")
+			writeErrorResponse(w, r, toAPIErrorCode(err), r.URL.Path)
+			return
+		}
+	}
 	// Write headers
 	setCommonHeaders(w)
 	// Write success response.
@@ -174,7 +180,7 @@ func (api objectAPIHandlers) ListObjectsV1Handler(w http.ResponseWriter, r *http
 	prefix, marker, delimiter, maxKeys, _ := getListObjectsV1Args(r.URL.Query())
 
 	// Validate all the query params before beginning to serve the request.
-	if s3Error := listObjectsValidateArgs(prefix, marker, delimiter, maxKeys); s3Error != ErrNone {
+	if s3Error := listObjectsValidateArgs(prefix, marker, maxKeys); s3Error != ErrNone {
 		writeErrorResponse(w, r, s3Error, r.URL.Path)
 		return
 	}
@@ -189,6 +195,13 @@ func (api objectAPIHandlers) ListObjectsV1Handler(w http.ResponseWriter, r *http
 		return
 	}
 	response := generateListObjectsV1Response(bucket, prefix, marker, delimiter, maxKeys, listObjectsInfo)
+	if isListMetadataRequested(r) {
+		if err = addListObjectsMetadata(r, objectAPI, bucket, listObjectsInfo.Objects, response.Contents); err != nil {
+			errorIf(err, "Unable to read metadata of listed objects.")
+			writeErrorResponse(w, r, toAPIErrorCode(err), r.URL.Path)
+			return
+		}
+	}
 	// Write headers
 	setCommonHeaders(w)
 	// Write success response.
@@ -396,9 +396,20 @@ func (api objectAPIHandlers) PutBucketHandler(w http.ResponseWriter, r *http.Req
 		return
 	}
 
+	// Claim the bucket name in the federation of clusters, if any.
+	claimed, err := claimFederatedBucket(bucket)
+	if err != nil {
+		errorIf(err, "Unable to claim a federated bucket.")
+		writeErrorResponse(w, r, toAPIErrorCode(err), r.URL.Path)
+		return
+	}
+
 	// Proceed to creating a bucket.
-	err := objectAPI.MakeBucket(bucket)
+	err = objectAPI.MakeBucket(bucket)
 	if err != nil {
+		if claimed {
+			releaseFederatedBucket(bucket)
+		}
 		errorIf(err, "Unable to create a bucket.")
 		writeErrorResponse(w, r, toAPIErrorCode(err), r.URL.Path)
 		return
@@ -446,6 +457,7 @@ func (api objectAPIHandlers) PostPolicyBucketHandler(w http.ResponseWriter, r *h
 
 	// Verify policy signature.
 	apiErr := doesPolicySignatureMatch(formValues)
+	recordAuthResult(r, apiErr)
 	if apiErr != ErrNone {
 		writeErrorResponse(w, r, apiErr, r.URL.Path)
 		return
@@ -455,13 +467,32 @@ func (api objectAPIHandlers) PostPolicyBucketHandler(w http.ResponseWriter, r *h
 		return
 	}
 
+	// Reject early if the upload would fill up the disks, size of
+	// the file is not known upfront so use the size of the form.
+	if err = globalDiskUsage.admit(r.ContentLength); err != nil {
+		writeErrorResponse(w, r, ErrStorageFull, r.URL.Path)
+		return
+	}
+
 	// Save metadata.
 	metadata := make(map[string]string)
-	// Nothing to store right now.
+	if algorithm, ok := formValues[http.CanonicalHeaderKey(sseMetaAlgorithm)]; ok {
+		metadata[sseMetaAlgorithm] = algorithm
+	}
+	// Apply bucket defaults.
+	applyBucketDefaults(bucket, object, metadata)
+
+	// Detect the content-type if the bucket asks for it.
+	fileReader, err := sniffContentType(bucket, object, metadata, fileBody)
+	if err != nil {
+		errorIf(err, "Unable to detect content-type of an object.")
+		writeErrorResponse(w, r, toAPIErrorCode(err), r.URL.Path)
+		return
+	}
 
 	sha256sum := ""
 
-	objInfo, err := objectAPI.PutObject(bucket, object, -1, fileBody, metadata, sha256sum)
+	objInfo, err := objectAPI.PutObject(bucket, object, -1, fileReader, metadata, sha256sum)
 	if err != nil {
 		errorIf(err, "Unable to create object.")
 		writeErrorResponse(w, r, toAPIErrorCode(err), r.URL.Path)
@@ -567,6 +598,20 @@ func (api objectAPIHandlers) DeleteBucketHandler(w http.ResponseWriter, r *http.
 	// Delete notification config, if present - ignore any errors.
 	removeNotificationConfig(bucket, objectAPI)
 
+	// Delete bucket settings, if present - ignore any errors.
+	if removeBucketSettings(bucket, objectAPI) == nil {
+		S3PeersUpdateBucketSettings(bucket, nil)
+	}
+
+	// Forget recent events of the bucket.
+	globalRecentEvents.Remove(bucket)
+
+	// Forget event statistics of the bucket.
+	globalEventStats.Remove(bucket)
+
+	// Release the bucket name in the federation of clusters, if any.
+	releaseFederatedBucket(bucket)
+
 	// Write success response.
 	writeSuccessNoContent(w)
 }
@@ -21,6 +21,9 @@ import (
 	"encoding/xml"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"sort"
 	"testing"
 )
 
@@ -607,3 +610,76 @@ func testListBucketsHandler(obj ObjectLayer, instanceType, bucketName string, ap
 	// `ExecObjectLayerAPINilTest` manages the operation.
 	ExecObjectLayerAPINilTest(t, "", "", instanceType, apiRouter, nilReq)
 }
+
+// Wrapper for calling ListObjectsV2 continuation token tests for both XL multiple disks and single node setup.
+func TestListObjectsV2ContinuationToken(t *testing.T) {
+	ExecObjectLayerAPITest(t, testListObjectsV2ContinuationToken, []string{"ListObjectsV2"})
+}
+
+// testListObjectsV2ContinuationToken - Tests paginating with opaque
+// continuation tokens over keys with characters special to URLs and XML.
+func testListObjectsV2ContinuationToken(obj ObjectLayer, instanceType, bucketName string, apiRouter http.Handler,
+	credentials credential, t *testing.T) {
+	objects := []string{"a b", "a&b=c", "a+b", "a<b>", "a%2Fb", "a?b#c"}
+	sort.Strings(objects)
+	for _, object := range objects {
+		if _, err := obj.PutObject(bucketName, object, 0, bytes.NewReader(nil), nil, ""); err != nil {
+			t.Fatalf("%s: Failed to put object %s: <ERROR> %v", instanceType, object, err)
+		}
+	}
+
+	listObjectsV2 := func(token string) (*httptest.ResponseRecorder, ListObjectsV2Response) {
+		queryValue := url.Values{}
+		queryValue.Set("list-type", "2")
+		queryValue.Set("max-keys", "1")
+		if token != "" {
+			queryValue.Set("continuation-token", token)
+		}
+		req, err := newTestSignedRequestV4("GET", makeTestTargetURL("", bucketName, "", queryValue),
+			0, nil, credentials.AccessKeyID, credentials.SecretAccessKey)
+		if err != nil {
+			t.Fatalf("%s: Failed to create HTTP request for ListObjectsV2Handler: <ERROR> %v", instanceType, err)
+		}
+		rec := httptest.NewRecorder()
+		apiRouter.ServeHTTP(rec, req)
+		var resp ListObjectsV2Response
+		if rec.Code == http.StatusOK {
+			if err = xml.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("%s: Failed to decode ListObjectsV2 response: <ERROR> %v", instanceType, err)
+			}
+		}
+		return rec, resp
+	}
+
+	var listed []string
+	var token string
+	for {
+		rec, resp := listObjectsV2(token)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("%s: Expected the response status to be `%d`, but instead found `%d`", instanceType, http.StatusOK, rec.Code)
+		}
+		if resp.ContinuationToken != token {
+			t.Errorf("%s: Expected continuation token %s to be echoed, got %s", instanceType, token, resp.ContinuationToken)
+		}
+		for _, content := range resp.Contents {
+			listed = append(listed, content.Key)
+		}
+		if !resp.IsTruncated {
+			if resp.NextContinuationToken != "" {
+				t.Errorf("%s: Expected no next continuation token, got %s", instanceType, resp.NextContinuationToken)
+			}
+			break
+		}
+		token = resp.NextContinuationToken
+	}
+	if !reflect.DeepEqual(listed, objects) {
+		t.Errorf("%s: Expected objects %v, got %v", instanceType, objects, listed)
+	}
+
+	// Tokens not generated by the server are rejected.
+	for _, token := range []string{"a b", "YWJj", "!!"} {
+		if rec, _ := listObjectsV2(token); rec.Code != http.StatusBadRequest {
+			t.Errorf("%s: Expected invalid token %s to be rejected with `%d`, got `%d`", instanceType, token, http.StatusBadRequest, rec.Code)
+		}
+	}
+}
@@ -36,6 +36,16 @@ type filterStruct struct {
 	Key keyFilter `xml:"S3Key,omitempty" json:"S3Key,omitempty"`
 }
 
+// MarshalXML - omits the filter element entirely when no filter rules
+// are set, so that configs are returned the way they were put.
+func (f filterStruct) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	if len(f.Key.FilterRules) == 0 {
+		return nil
+	}
+	type filter filterStruct // Avoid recursing into MarshalXML.
+	return e.EncodeElement(filter(f), start)
+}
+
 // ServiceConfig - Common elements of service notification.
 type ServiceConfig struct {
 	Events []string     `xml:"Event" json:"Event"`
@@ -65,8 +75,18 @@ type lambdaConfig struct {
 // notification configuration of buckets.
 type notificationConfig struct {
 	XMLName       xml.Name       `xml:"NotificationConfiguration"`
+	TopicConfigs  []topicConfig  `xml:"TopicConfiguration"`
 	QueueConfigs  []queueConfig  `xml:"QueueConfiguration"`
 	LambdaConfigs []lambdaConfig `xml:"CloudFunctionConfiguration"`
+
+	// Elements not part of the notification configuration schema,
+	// only collected to reject them.
+	Unsupported []unsupportedElement `xml:",any"`
+}
+
+// Unknown XML element in notification configuration.
+type unsupportedElement struct {
+	XMLName xml.Name
 }
 
 // listenerConfig structure represents run-time notification
@@ -79,6 +99,13 @@ type listenerConfig struct {
 // Internal error used to signal notifications not set.
 var errNoSuchNotifications = errors.New("The specified bucket does not have bucket notifications")
 
+// Errors returned on partial updates of bucket notifications.
+var (
+	errNotificationConfigModified = errors.New("Bucket notification configuration was modified, please reload and retry")
+	errNotificationRuleExists     = errors.New("A notification rule with the specified id already exists")
+	errNoSuchNotificationRule     = errors.New("The specified notification rule does not exist")
+)
+
 // EventName is AWS S3 event type:
 // http://docs.aws.amazon.com/AmazonS3/latest/dev/NotificationHowTo.html
 type EventName int
@@ -18,8 +18,10 @@ package cmd
 
 import (
 	"bytes"
+	"encoding/hex"
 	"encoding/json"
 	"encoding/xml"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -144,8 +146,16 @@ func (api objectAPIHandlers) PutBucketNotificationHandler(w http.ResponseWriter,
 		return
 	}
 
-	// Put bucket notification config.
+	// Generate ids for configs without one, like S3.
+	setNotificationIDs(&notificationCfg)
+
+	// Put bucket notification config, config changes of the bucket
+	// are serialized across the cluster.
+	opsID := getOpsID()
+	lockPath := path.Join(bucketConfigPrefix, bucket)
+	nsMutex.Lock(minioMetaBucket, lockPath, opsID)
 	err = PutBucketNotificationConfig(bucket, &notificationCfg, objectAPI)
+	nsMutex.Unlock(minioMetaBucket, lockPath, opsID)
 	if err != nil {
 		writeErrorResponse(w, r, toAPIErrorCode(err), r.URL.Path)
 		return
@@ -177,6 +187,100 @@ func PutBucketNotificationConfig(bucket string, ncfg *notificationConfig, objAPI
 	return nil
 }
 
+// Returns the ETag of a bucket notification config, used by
+// clients for optimistic concurrency on partial updates. An empty
+// ETag stands for a bucket without notification config.
+func getNotificationConfigETag(ncfg *notificationConfig) (string, error) {
+	if ncfg == nil {
+		return "", nil
+	}
+	buf, err := xml.Marshal(ncfg)
+	if err != nil {
+		return "", err
+	}
+	hasher := newETagHash()
+	hasher.Write(buf)
+	return hex.EncodeToString(hasher.Sum(nil)), nil
+}
+
+// updateBucketNotificationConfig - applies a partial update to the
+// notification config of a bucket, only if the current config still
+// matches the ETag the caller based its update on. The updated config
+// is persisted and sent to all peers, its ETag is returned.
+func updateBucketNotificationConfig(bucket, etag string, update func(*notificationConfig) error, objAPI ObjectLayer) (string, error) {
+	// Serialize read-modify-write of the config across the
+	// cluster, notification.xml itself is locked by PutObject.
+	opsID := getOpsID()
+	lockPath := path.Join(bucketConfigPrefix, bucket)
+	nsMutex.Lock(minioMetaBucket, lockPath, opsID)
+	defer nsMutex.Unlock(minioMetaBucket, lockPath, opsID)
+
+	ncfg, err := loadNotificationConfig(bucket, objAPI)
+	if err != nil && err != errNoSuchNotifications {
+		return "", err
+	}
+	curETag, err := getNotificationConfigETag(ncfg)
+	if err != nil {
+		return "", err
+	}
+	if curETag != etag {
+		return "", errNotificationConfigModified
+	}
+
+	// Work on a copy, in-memory config is only replaced by peers.
+	newCfg := &notificationConfig{}
+	if ncfg != nil {
+		newCfg.TopicConfigs = append(newCfg.TopicConfigs, ncfg.TopicConfigs...)
+		newCfg.QueueConfigs = append(newCfg.QueueConfigs, ncfg.QueueConfigs...)
+		newCfg.LambdaConfigs = append(newCfg.LambdaConfigs, ncfg.LambdaConfigs...)
+	}
+	if err = update(newCfg); err != nil {
+		return "", err
+	}
+
+	if err = PutBucketNotificationConfig(bucket, newCfg, objAPI); err != nil {
+		return "", err
+	}
+	return getNotificationConfigETag(newCfg)
+}
+
+// addNotificationRule - returns an update adding the queue config
+// to a notification config, generating a rule id if none is set.
+// Only the new rule is validated, rules already in place are kept
+// as is even if their target is unreachable at the moment.
+func addNotificationRule(qConfig queueConfig) func(*notificationConfig) error {
+	return func(ncfg *notificationConfig) error {
+		if qConfig.ID == "" {
+			qConfig.ID = newNotificationID()
+		}
+		ncfg.QueueConfigs = append(ncfg.QueueConfigs, qConfig)
+		if checkDuplicateNotificationIDs(*ncfg) != ErrNone {
+			return errNotificationRuleExists
+		}
+		if s3Error := checkDuplicateQueueConfigs(ncfg.QueueConfigs); s3Error != ErrNone {
+			return errors.New(getAPIError(s3Error).Description)
+		}
+		if s3Error := checkQueueConfig(qConfig); s3Error != ErrNone {
+			return errors.New(getAPIError(s3Error).Description)
+		}
+		return nil
+	}
+}
+
+// removeNotificationRule - returns an update removing the queue
+// config with the given rule id from a notification config.
+func removeNotificationRule(id string) func(*notificationConfig) error {
+	return func(ncfg *notificationConfig) error {
+		for i, config := range ncfg.QueueConfigs {
+			if config.ID == id {
+				ncfg.QueueConfigs = append(ncfg.QueueConfigs[:i], ncfg.QueueConfigs[i+1:]...)
+				return nil
+			}
+		}
+		return errNoSuchNotificationRule
+	}
+}
+
 // writeNotification marshals notification message before writing to client.
 func writeNotification(w http.ResponseWriter, notification map[string][]NotificationEvent) error {
 	// Invalid response writer.
@@ -232,6 +336,53 @@ func sendBucketNotification(w http.ResponseWriter, arnListenerCh <-chan []Notifi
 	}
 }
 
+// newListenerConfig - returns the listener config of a new listener
+// of events matching the filters, served by this server.
+func newListenerConfig(prefixes, suffixes, events []string) listenerConfig {
+	accountID := fmt.Sprintf("%d", time.Now().UTC().UnixNano())
+	accountARN := fmt.Sprintf(
+		"arn:minio:sqs:%s:%s:listen-%s",
+		serverConfig.GetRegion(),
+		accountID,
+		globalMinioAddr,
+	)
+	var filterRules []filterRule
+
+	for _, prefix := range prefixes {
+		filterRules = append(filterRules, filterRule{
+			Name:  "prefix",
+			Value: prefix,
+		})
+	}
+
+	for _, suffix := range suffixes {
+		filterRules = append(filterRules, filterRule{
+			Name:  "suffix",
+			Value: suffix,
+		})
+	}
+
+	// Make topic configuration corresponding to this listener.
+	topicCfg := topicConfig{
+		TopicARN: accountARN,
+		ServiceConfig: ServiceConfig{
+			Events: events,
+			Filter: struct {
+				Key keyFilter `xml:"S3Key,omitempty" json:"S3Key,omitempty"`
+			}{
+				Key: keyFilter{
+					FilterRules: filterRules,
+				},
+			},
+			ID: "sns-" + accountID,
+		},
+	}
+	return listenerConfig{
+		TopicConfig:  topicCfg,
+		TargetServer: globalMinioAddr,
+	}
+}
+
 // ListenBucketNotificationHandler - list bucket notifications.
 func (api objectAPIHandlers) ListenBucketNotificationHandler(w http.ResponseWriter, r *http.Request) {
 	// Validate if bucket exists.
@@ -277,45 +428,8 @@ func (api objectAPIHandlers) ListenBucketNotificationHandler(w http.ResponseWrit
 		return
 	}
 
-	accountID := fmt.Sprintf("%d", time.Now().UTC().UnixNano())
-	accountARN := fmt.Sprintf(
-		"arn:minio:sqs:%s:%s:listen-%s",
-		serverConfig.GetRegion(),
-		accountID,
-		globalMinioAddr,
-	)
-	var filterRules []filterRule
-
-	for _, prefix := range prefixes {
-		filterRules = append(filterRules, filterRule{
-			Name:  "prefix",
-			Value: prefix,
-		})
-	}
-
-	for _, suffix := range suffixes {
-		filterRules = append(filterRules, filterRule{
-			Name:  "suffix",
-			Value: suffix,
-		})
-	}
-
-	// Make topic configuration corresponding to this
-	// ListenBucketNotification request.
-	topicCfg := &topicConfig{
-		TopicARN: accountARN,
-		ServiceConfig: ServiceConfig{
-			Events: events,
-			Filter: struct {
-				Key keyFilter `xml:"S3Key,omitempty" json:"S3Key,omitempty"`
-			}{
-				Key: keyFilter{
-					FilterRules: filterRules,
-				},
-			},
-			ID: "sns-" + accountID,
-		},
-	}
+	lc := newListenerConfig(prefixes, suffixes, events)
+	accountARN := lc.TopicConfig.TopicARN
 
 	// Setup a listening channel that will receive notifications
 	// from the RPC handler.
@@ -334,10 +448,6 @@ func (api objectAPIHandlers) ListenBucketNotificationHandler(w http.ResponseWrit
 	// Update topic config to bucket config and persist - as soon
 	// as this call compelets, events may start appearing in
 	// nEventCh
-	lc := listenerConfig{
-		TopicConfig:  *topicCfg,
-		TargetServer: globalMinioAddr,
-	}
 	err = AddBucketListenerConfig(bucket, &lc, objAPI)
 	if err != nil {
 		writeErrorResponse(w, r, toAPIErrorCode(err), r.URL.Path)
@@ -358,13 +468,25 @@ func AddBucketListenerConfig(bucket string, lcfg *listenerConfig, objAPI ObjectL
 	if lcfg == nil {
 		return errInvalidArgument
 	}
-	listenerCfgs := globalEventNotifier.GetBucketListenerConfig(bucket)
+
+	// Serialize config changes of the bucket across the cluster.
+	opsID := getOpsID()
+	lockPath := path.Join(bucketConfigPrefix, bucket)
+	nsMutex.Lock(minioMetaBucket, lockPath, opsID)
+	defer nsMutex.Unlock(minioMetaBucket, lockPath, opsID)
+
+	// The stored listeners are the latest ones, peers may not have
+	// been updated yet.
+	listenerCfgs, err := loadListenerConfig(bucket, objAPI)
+	if err != nil && err != errNoSuchNotifications {
+		return err
+	}
 
 	// add new lid to listeners and persist to object layer.
 	listenerCfgs = append(listenerCfgs, *lcfg)
 
 	// update persistent config
-	err := persistListenerConfig(bucket, listenerCfgs, objAPI)
+	err = persistListenerConfig(bucket, listenerCfgs, objAPI)
 	if err != nil {
 		errorIf(err, "Error persisting listener config when adding a listener.")
 		return err
@@ -378,7 +500,19 @@ func AddBucketListenerConfig(bucket string, lcfg *listenerConfig, objAPI ObjectL
 
 // RemoveBucketListenerConfig - removes a given bucket notification config
 func RemoveBucketListenerConfig(bucket string, lcfg *listenerConfig, objAPI ObjectLayer) {
-	listenerCfgs := globalEventNotifier.GetBucketListenerConfig(bucket)
+	// Serialize config changes of the bucket across the cluster.
+	opsID := getOpsID()
+	lockPath := path.Join(bucketConfigPrefix, bucket)
+	nsMutex.Lock(minioMetaBucket, lockPath, opsID)
+	defer nsMutex.Unlock(minioMetaBucket, lockPath, opsID)
+
+	listenerCfgs, err := loadListenerConfig(bucket, objAPI)
+	if err != nil {
+		if err != errNoSuchNotifications {
+			errorIf(err, "Error loading listener config when removing a listener.")
+		}
+		return
+	}
 
 	// remove listener with matching ARN - if not found ignore and
 	// exit.
@@ -397,7 +531,7 @@ func RemoveBucketListenerConfig(bucket string, lcfg *listenerConfig, objAPI Obje
 	}
 
 	// update persistent config
-	err := persistListenerConfig(bucket, updatedLcfgs, objAPI)
+	err = persistListenerConfig(bucket, updatedLcfgs, objAPI)
 	if err != nil {
 		errorIf(err, "Error persisting listener config when removing a listener.")
 		return
@@ -16,7 +16,10 @@
 
 package cmd
 
-import "strings"
+import (
+	"encoding/base64"
+	"strings"
+)
 
 // List of valid event types.
 var suppportedEventTypes = map[string]struct{}{
@@ -51,14 +54,14 @@ func checkEvents(events []string) APIErrorCode {
 	return ErrNone
 }
 
-// Valid if filterName is 'prefix'.
+// Valid if filterName is 'prefix', like S3 the name is case insensitive.
 func isValidFilterNamePrefix(filterName string) bool {
-	return "prefix" == filterName
+	return strings.EqualFold("prefix", filterName)
 }
 
-// Valid if filterName is 'suffix'.
+// Valid if filterName is 'suffix', like S3 the name is case insensitive.
 func isValidFilterNameSuffix(filterName string) bool {
-	return "suffix" == filterName
+	return strings.EqualFold("suffix", filterName)
 }
 
 // Is this a valid filterName? - returns true if valid.
@@ -78,7 +81,8 @@ func checkFilterRules(filterRules []filterRule) APIErrorCode {
 
 		// Filter names should not be set twice per notification service
 		// configuration, if found return an appropriate error.
-		if _, ok := ruleSetMap[filterRule.Name]; ok {
+		ruleName := strings.ToLower(filterRule.Name)
+		if _, ok := ruleSetMap[ruleName]; ok {
 			if isValidFilterNamePrefix(filterRule.Name) {
 				return ErrFilterNamePrefix
 			} else if isValidFilterNameSuffix(filterRule.Name) {
@@ -93,7 +97,7 @@ func checkFilterRules(filterRules []filterRule) APIErrorCode {
 		}
 
 		// Set the new rule name to keep track of duplicates.
-		ruleSetMap[filterRule.Name] = filterRule.Value
+		ruleSetMap[ruleName] = filterRule.Value
 	}
 	// Success all prefixes validated.
 	return ErrNone
@@ -265,15 +269,77 @@ func checkDuplicateQueueConfigs(configs []queueConfig) APIErrorCode {
 	return ErrNone
 }
 
+// Check - validates a topic or lambda configuration. These are kept
+// for compliance only, their destination is not verified since minio
+// doesn't publish to them.
+func checkComplianceConfig(sConfig ServiceConfig, arn string) APIErrorCode {
+	if arn == "" {
+		return ErrARNNotification
+	}
+	if s3Error := checkEvents(sConfig.Events); s3Error != ErrNone {
+		return s3Error
+	}
+	return checkFilterRules(sConfig.Filter.Key.FilterRules)
+}
+
+// Check all the configs for any duplicate ids, empty ids are
+// generated later on and are not considered.
+func checkDuplicateNotificationIDs(nConfig notificationConfig) APIErrorCode {
+	var ids []string
+	for _, config := range nConfig.TopicConfigs {
+		ids = append(ids, config.ID)
+	}
+	for _, config := range nConfig.QueueConfigs {
+		ids = append(ids, config.ID)
+	}
+	for _, config := range nConfig.LambdaConfigs {
+		ids = append(ids, config.ID)
+	}
+	idSet := make(map[string]struct{})
+	for _, id := range ids {
+		if id == "" {
+			continue
+		}
+		if _, ok := idSet[id]; ok {
+			return ErrNotificationDuplicateID
+		}
+		idSet[id] = struct{}{}
+	}
+	return ErrNone
+}
+
 // Validates all the bucket notification configuration for their validity,
 // if one of the config is malformed or has invalid data it is rejected.
 // Configuration is never applied partially.
 func validateNotificationConfig(nConfig notificationConfig) APIErrorCode {
+	// Elements outside of the S3 schema are rejected instead of
+	// being silently dropped.
+	if len(nConfig.Unsupported) > 0 {
+		return ErrMalformedXML
+	}
+
 	// Validate all queue configs.
 	if s3Error := validateQueueConfigs(nConfig.QueueConfigs); s3Error != ErrNone {
 		return s3Error
 	}
 
+	// Validate compliance only topic and lambda configs.
+	for _, tConfig := range nConfig.TopicConfigs {
+		if s3Error := checkComplianceConfig(tConfig.ServiceConfig, tConfig.TopicARN); s3Error != ErrNone {
+			return s3Error
+		}
+	}
+	for _, lConfig := range nConfig.LambdaConfigs {
+		if s3Error := checkComplianceConfig(lConfig.ServiceConfig, lConfig.LambdaARN); s3Error != ErrNone {
+			return s3Error
+		}
+	}
+
+	// Check for duplicate config ids.
+	if s3Error := checkDuplicateNotificationIDs(nConfig); s3Error != ErrNone {
+		return s3Error
+	}
+
 	// Check for duplicate queue configs.
 	if len(nConfig.QueueConfigs) > 1 {
 		if s3Error := checkDuplicateQueueConfigs(nConfig.QueueConfigs); s3Error != ErrNone {
@@ -285,6 +351,31 @@ func validateNotificationConfig(nConfig notificationConfig) APIErrorCode {
 	return ErrNone
 }
 
+// Returns a new notification config id, generated the same way as
+// S3 does for configs without an id.
+func newNotificationID() string {
+	return base64.StdEncoding.EncodeToString([]byte(getUUID()))
+}
+
+// Sets generated ids on all configs which have none.
+func setNotificationIDs(nConfig *notificationConfig) {
+	for i := range nConfig.TopicConfigs {
+		if nConfig.TopicConfigs[i].ID == "" {
+			nConfig.TopicConfigs[i].ID = newNotificationID()
+		}
+	}
+	for i := range nConfig.QueueConfigs {
+		if nConfig.QueueConfigs[i].ID == "" {
+			nConfig.QueueConfigs[i].ID = newNotificationID()
+		}
+	}
+	for i := range nConfig.LambdaConfigs {
+		if nConfig.LambdaConfigs[i].ID == "" {
+			nConfig.LambdaConfigs[i].ID = newNotificationID()
+		}
+	}
+}
+
 // Unmarshals input value of AWS ARN format into minioTopic object.
 // Returned value represents minio topic type, currently supported are
 // - listen
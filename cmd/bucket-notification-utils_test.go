@@ -17,6 +17,7 @@
 package cmd
 
 import (
+	"encoding/xml"
 	"strings"
 	"testing"
 )
@@ -117,6 +118,20 @@ func TestCheckFilterRules(t *testing.T) {
 			},
 			expectedErrCode: ErrFilterNameSuffix,
 		},
+		// Filter names are case insensitive, duplicates as well.
+		{
+			rules: []filterRule{
+				{
+					Name:  "Prefix",
+					Value: "test/test1",
+				},
+				{
+					Name:  "prefix",
+					Value: "test/test2",
+				},
+			},
+			expectedErrCode: ErrFilterNamePrefix,
+		},
 		// Filter value cannot be bigger than > 1024.
 		{
 			rules: []filterRule{
@@ -153,6 +168,11 @@ func TestIsValidFilterName(t *testing.T) {
 			filterName: "suffix",
 			status:     true,
 		},
+		// Validate if 'Suffix' is correct.
+		{
+			filterName: "Suffix",
+			status:     true,
+		},
 		// Invalid filter name empty string should return false.
 		{
 			filterName: "",
@@ -173,6 +193,80 @@ func TestIsValidFilterName(t *testing.T) {
 	}
 }
 
+// Tests notification config validation of compliance configs, ids
+// and unsupported elements.
+func TestValidateNotificationConfig(t *testing.T) {
+	testCases := []struct {
+		configXML       string
+		expectedErrCode APIErrorCode
+	}{
+		// Topic and lambda configs are accepted without verifying
+		// their destination.
+		{
+			configXML:       `<NotificationConfiguration><TopicConfiguration><Id>1</Id><Topic>arn:aws:sns:us-east-1:1:topic</Topic><Event>s3:ObjectCreated:*</Event></TopicConfiguration><CloudFunctionConfiguration><Id>2</Id><CloudFunction>arn:aws:lambda:us-east-1:1:function:f</CloudFunction><Event>s3:ObjectRemoved:*</Event></CloudFunctionConfiguration></NotificationConfiguration>`,
+			expectedErrCode: ErrNone,
+		},
+		// Topic config without destination.
+		{
+			configXML:       `<NotificationConfiguration><TopicConfiguration><Event>s3:ObjectCreated:*</Event></TopicConfiguration></NotificationConfiguration>`,
+			expectedErrCode: ErrARNNotification,
+		},
+		// Lambda config with invalid filter name.
+		{
+			configXML:       `<NotificationConfiguration><CloudFunctionConfiguration><CloudFunction>arn:aws:lambda:us-east-1:1:function:f</CloudFunction><Event>s3:ObjectCreated:*</Event><Filter><S3Key><FilterRule><Name>infix</Name><Value>a</Value></FilterRule></S3Key></Filter></CloudFunctionConfiguration></NotificationConfiguration>`,
+			expectedErrCode: ErrFilterNameInvalid,
+		},
+		// Duplicate ids across configs.
+		{
+			configXML:       `<NotificationConfiguration><TopicConfiguration><Id>1</Id><Topic>arn:aws:sns:us-east-1:1:topic</Topic><Event>s3:ObjectCreated:*</Event></TopicConfiguration><CloudFunctionConfiguration><Id>1</Id><CloudFunction>arn:aws:lambda:us-east-1:1:function:f</CloudFunction><Event>s3:ObjectRemoved:*</Event></CloudFunctionConfiguration></NotificationConfiguration>`,
+			expectedErrCode: ErrNotificationDuplicateID,
+		},
+		// Unsupported element.
+		{
+			configXML:       `<NotificationConfiguration><UnknownConfiguration><Id>1</Id></UnknownConfiguration></NotificationConfiguration>`,
+			expectedErrCode: ErrMalformedXML,
+		},
+	}
+
+	for i, testCase := range testCases {
+		var nConfig notificationConfig
+		if err := xml.Unmarshal([]byte(testCase.configXML), &nConfig); err != nil {
+			t.Fatalf("Test %d: Unable to unmarshal config, %s", i+1, err)
+		}
+		errCode := validateNotificationConfig(nConfig)
+		if errCode != testCase.expectedErrCode {
+			t.Errorf("Test %d: Expected %d, got %d", i+1, testCase.expectedErrCode, errCode)
+		}
+	}
+}
+
+// Tests config round trip through XML along with id generation.
+func TestNotificationConfigRoundTrip(t *testing.T) {
+	configXML := `<NotificationConfiguration><TopicConfiguration><Event>s3:ObjectCreated:*</Event><Id>topic</Id><Topic>arn:aws:sns:us-east-1:1:topic</Topic></TopicConfiguration><CloudFunctionConfiguration><Event>s3:ObjectRemoved:*</Event><Filter><S3Key><FilterRule><Name>Prefix</Name><Value>images/</Value></FilterRule></S3Key></Filter><Id></Id><CloudFunction>arn:aws:lambda:us-east-1:1:function:f</CloudFunction></CloudFunctionConfiguration></NotificationConfiguration>`
+
+	var nConfig notificationConfig
+	if err := xml.Unmarshal([]byte(configXML), &nConfig); err != nil {
+		t.Fatalf("Unable to unmarshal config, %s", err)
+	}
+	setNotificationIDs(&nConfig)
+	if nConfig.TopicConfigs[0].ID != "topic" {
+		t.Fatalf("Expected id to be kept, got %s", nConfig.TopicConfigs[0].ID)
+	}
+	generatedID := nConfig.LambdaConfigs[0].ID
+	if generatedID == "" {
+		t.Fatal("Expected an id to be generated")
+	}
+
+	configBytes, err := xml.Marshal(nConfig)
+	if err != nil {
+		t.Fatalf("Unable to marshal config, %s", err)
+	}
+	expectedXML := strings.Replace(configXML, "<Id></Id>", "<Id>"+generatedID+"</Id>", 1)
+	if string(configBytes) != expectedXML {
+		t.Fatalf("Expected %s, got %s", expectedXML, string(configBytes))
+	}
+}
+
 // Tests list of valid and invalid events.
 func TestValidEvents(t *testing.T) {
 	testCases := []struct {
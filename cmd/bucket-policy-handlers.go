@@ -22,6 +22,7 @@ import (
 	"io"
 	"io/ioutil"
 	"net/http"
+	"path"
 
 	mux "github.com/gorilla/mux"
 	"github.com/mf-00/newgo/pkg/wildcard"
@@ -34,18 +35,26 @@ const maxAccessPolicySize = 20 * 1024 // 20KiB.
 // Verify if a given action is valid for the url path based on the
 // existing bucket access policy.
 func bucketPolicyEvalStatements(action string, resource string, conditions map[string]set.StringSet, statements []policyStatement) bool {
-	for _, statement := range statements {
+	index := bucketPolicyFindStatement(action, resource, conditions, statements)
+	if index < 0 {
+		// None match so deny.
+		return false
+	}
+	// Do not uncomment kept here for readability.
+	// else statement.Effect == "Deny"
+	return statements[index].Effect == "Allow"
+}
+
+// Returns the index of the first statement which matches action,
+// resource and conditions, it decides on the request. Returns -1
+// if none match.
+func bucketPolicyFindStatement(action string, resource string, conditions map[string]set.StringSet, statements []policyStatement) int {
+	for index, statement := range statements {
 		if bucketPolicyMatchStatement(action, resource, conditions, statement) {
-			if statement.Effect == "Allow" {
-				return true
-			}
-			// Do not uncomment kept here for readability.
-			// else statement.Effect == "Deny"
-			return false
+			return index
 		}
 	}
-	// None match so deny.
-	return false
+	return -1
 }
 
 // Verify if action, resource and conditions match input policy statement.
@@ -181,8 +190,13 @@ func (api objectAPIHandlers) PutBucketPolicyHandler(w http.ResponseWriter, r *ht
 		return
 	}
 
-	// Save bucket policy.
-	if err = persistAndNotifyBucketPolicyChange(bucket, policyChange{false, policy}, objAPI); err != nil {
+	// Save bucket policy, only if it still matches If-Match.
+	err = persistAndNotifyBucketPolicyChange(bucket, r.Header.Get("If-Match"), policyChange{BktPolicy: policy}, objAPI)
+	if err == errBucketPolicyModified {
+		writeErrorResponse(w, r, ErrPreconditionFailed, r.URL.Path)
+		return
+	}
+	if err != nil {
 		switch err.(type) {
 		case BucketNameInvalid:
 			writeErrorResponse(w, r, ErrInvalidBucketName, r.URL.Path)
@@ -193,19 +207,65 @@ func (api objectAPIHandlers) PutBucketPolicyHandler(w http.ResponseWriter, r *ht
 	}
 
 	// Success.
+	if etag, err := getBucketPolicyETag(policy); err == nil {
+		w.Header().Set("ETag", "\""+etag+"\"")
+	}
 	writeSuccessNoContent(w)
 }
 
 // persistAndNotifyBucketPolicyChange - takes a policyChange argument,
 // persists it to storage, and notify nodes in the cluster about the
 // change. In-memory state is updated in response to the notification.
-func persistAndNotifyBucketPolicyChange(bucket string, pCh policyChange, objAPI ObjectLayer) error {
+// A non empty etag is matched against the current policy, "*" matches
+// any policy, errBucketPolicyModified is returned on a mismatch.
+func persistAndNotifyBucketPolicyChange(bucket, etag string, pCh policyChange, objAPI ObjectLayer) error {
+	return updateAndNotifyBucketPolicy(bucket, objAPI, func() (policyChange, error) {
+		if etag == "" {
+			return pCh, nil
+		}
+		policy, err := readBucketPolicy(bucket, objAPI)
+		if _, ok := err.(BucketPolicyNotFound); ok {
+			return pCh, errBucketPolicyModified
+		}
+		if err != nil {
+			return pCh, err
+		}
+		curETag, err := getBucketPolicyETag(policy)
+		if err != nil {
+			return pCh, err
+		}
+		if etag != "*" && !isETagEqual(curETag, etag) {
+			return pCh, errBucketPolicyModified
+		}
+		return pCh, nil
+	})
+}
+
+// updateAndNotifyBucketPolicy - persists and notifies the policy
+// change returned by update, which is called with the policy changes
+// of the bucket serialized. Read-modify-write of a policy reads it in
+// update, so that concurrent changes are not lost.
+func updateAndNotifyBucketPolicy(bucket string, objAPI ObjectLayer, update func() (policyChange, error)) error {
 	// FIXME: Race exists between the bucket existence check and
 	// then updating the bucket policy.
 	if err := isBucketExist(bucket, objAPI); err != nil {
 		return err
 	}
 
+	// Serialize policy changes across the cluster, so that they are
+	// matched against the latest policy and versioned in order.
+	// policy.json itself is locked by PutObject.
+	opsID := getOpsID()
+	lockPath := path.Join(bucketConfigPrefix, bucket)
+	nsMutex.Lock(minioMetaBucket, lockPath, opsID)
+	defer nsMutex.Unlock(minioMetaBucket, lockPath, opsID)
+
+	pCh, err := update()
+	if err != nil {
+		return err
+	}
+	pCh.Version = globalBucketPolicies.NextBucketPolicyVersion(bucket)
+
 	if pCh.IsRemove {
 		if err := removeBucketPolicy(bucket, objAPI); err != nil {
 			return err
@@ -247,7 +307,12 @@ func (api objectAPIHandlers) DeleteBucketPolicyHandler(w http.ResponseWriter, r
 
 	// Delete bucket access policy, by passing an empty policy
 	// struct.
-	if err := persistAndNotifyBucketPolicyChange(bucket, policyChange{true, nil}, objAPI); err != nil {
+	err := persistAndNotifyBucketPolicyChange(bucket, r.Header.Get("If-Match"), policyChange{IsRemove: true}, objAPI)
+	if err == errBucketPolicyModified {
+		writeErrorResponse(w, r, ErrPreconditionFailed, r.URL.Path)
+		return
+	}
+	if err != nil {
 		switch err.(type) {
 		case BucketNameInvalid:
 			writeErrorResponse(w, r, ErrInvalidBucketName, r.URL.Path)
@@ -299,6 +364,10 @@ func (api objectAPIHandlers) GetBucketPolicyHandler(w http.ResponseWriter, r *ht
 		return
 	}
 
+	if etag, err := getBucketPolicyETag(policy); err == nil {
+		w.Header().Set("ETag", "\""+etag+"\"")
+	}
+
 	// Write to client.
 	fmt.Fprint(w, policy)
 }
@@ -23,6 +23,7 @@ import (
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
+	"sync"
 	"testing"
 
 	"github.com/minio/minio-go/pkg/set"
@@ -836,6 +837,118 @@ func testDeleteBucketPolicyHandler(obj ObjectLayer, instanceType, bucketName str
 	ExecObjectLayerAPINilTest(t, nilBucket, "", instanceType, apiRouter, nilReq)
 }
 
+// Wrapper for calling conditional Put/Delete BucketPolicy HTTP handler tests for both XL multiple disks and single node setup.
+func TestBucketPolicyIfMatch(t *testing.T) {
+	ExecObjectLayerAPITest(t, testBucketPolicyIfMatch, []string{"PutBucketPolicy", "GetBucketPolicy", "DeleteBucketPolicy"})
+}
+
+// testBucketPolicyIfMatch - Test for ETags returned by GetBucketPolicy
+// and If-Match enforced by PutBucketPolicy and DeleteBucketPolicy.
+func testBucketPolicyIfMatch(obj ObjectLayer, instanceType, bucketName string, apiRouter http.Handler,
+	credentials credential, t *testing.T) {
+	// initialize bucket policy.
+	initBucketPolicies(obj)
+
+	bucketPolicyTemplate := `{"Version":"2012-10-17","Statement":[{"Action":["s3:GetObject"],"Effect":"Allow","Principal":{"AWS":["*"]},"Resource":["arn:aws:s3:::%s/%s*"],"Sid":""}]}`
+	putPolicy := func(prefix, etag string) *httptest.ResponseRecorder {
+		policyStr := fmt.Sprintf(bucketPolicyTemplate, bucketName, prefix)
+		rec := httptest.NewRecorder()
+		req, err := newTestSignedRequestV4("PUT", getPutPolicyURL("", bucketName),
+			int64(len(policyStr)), bytes.NewReader([]byte(policyStr)), credentials.AccessKeyID, credentials.SecretAccessKey)
+		if err != nil {
+			t.Fatalf("%s: Failed to create HTTP request for PutBucketPolicyHandler: <ERROR> %v", instanceType, err)
+		}
+		if etag != "" {
+			req.Header.Set("If-Match", etag)
+		}
+		apiRouter.ServeHTTP(rec, req)
+		return rec
+	}
+
+	// Conditional writes fail while there is no policy.
+	if rec := putPolicy("a", "*"); rec.Code != http.StatusPreconditionFailed {
+		t.Fatalf("%s: Expected %d, got %d", instanceType, http.StatusPreconditionFailed, rec.Code)
+	}
+	rec := putPolicy("a", "")
+	if rec.Code != http.StatusNoContent {
+		t.Fatalf("%s: Expected %d, got %d", instanceType, http.StatusNoContent, rec.Code)
+	}
+	putETag := rec.Header().Get("ETag")
+
+	rec = httptest.NewRecorder()
+	req, err := newTestSignedRequestV4("GET", getGetPolicyURL("", bucketName), 0, nil, credentials.AccessKeyID, credentials.SecretAccessKey)
+	if err != nil {
+		t.Fatalf("%s: Failed to create HTTP request for GetBucketPolicyHandler: <ERROR> %v", instanceType, err)
+	}
+	apiRouter.ServeHTTP(rec, req)
+	etag := rec.Header().Get("ETag")
+	if etag == "" || etag != putETag {
+		t.Fatalf("%s: Expected ETag %s, got %s", instanceType, putETag, etag)
+	}
+
+	// Two writers based on the same ETag, the second one loses.
+	if rec = putPolicy("b", etag); rec.Code != http.StatusNoContent {
+		t.Fatalf("%s: Expected %d, got %d", instanceType, http.StatusNoContent, rec.Code)
+	}
+	if rec = putPolicy("c", etag); rec.Code != http.StatusPreconditionFailed {
+		t.Fatalf("%s: Expected %d, got %d", instanceType, http.StatusPreconditionFailed, rec.Code)
+	}
+	policy, err := readBucketPolicy(bucketName, obj)
+	if err != nil || !policy.Statements[0].Resources.Contains(fmt.Sprintf("arn:aws:s3:::%s/b*", bucketName)) {
+		t.Fatalf("%s: Expected the policy of the first writer, got %v", instanceType, policy)
+	}
+
+	// Stale deletes are rejected as well.
+	rec = httptest.NewRecorder()
+	req, err = newTestSignedRequestV4("DELETE", getDeletePolicyURL("", bucketName), 0, nil, credentials.AccessKeyID, credentials.SecretAccessKey)
+	if err != nil {
+		t.Fatalf("%s: Failed to create HTTP request for DeleteBucketPolicyHandler: <ERROR> %v", instanceType, err)
+	}
+	req.Header.Set("If-Match", etag)
+	apiRouter.ServeHTTP(rec, req)
+	if rec.Code != http.StatusPreconditionFailed {
+		t.Fatalf("%s: Expected %d, got %d", instanceType, http.StatusPreconditionFailed, rec.Code)
+	}
+}
+
+// Tests peers rejecting policy changes older than the applied one.
+func TestSetBucketPolicyVersion(t *testing.T) {
+	bp := &bucketPolicies{
+		rwMutex:              &sync.RWMutex{},
+		bucketPolicyConfigs:  make(map[string]*bucketPolicy),
+		bucketPolicyVersions: make(map[string]int64),
+	}
+	newPolicy := &bucketPolicy{Version: "2012-10-17"}
+	oldPolicy := &bucketPolicy{Version: "2008-10-17"}
+
+	version := bp.NextBucketPolicyVersion("bucket")
+	if err := bp.SetBucketPolicy("bucket", policyChange{BktPolicy: newPolicy, Version: version}); err != nil {
+		t.Fatal(err)
+	}
+	if next := bp.NextBucketPolicyVersion("bucket"); next <= version {
+		t.Fatalf("Expected a version newer than %d, got %d", version, next)
+	}
+	err := bp.SetBucketPolicy("bucket", policyChange{BktPolicy: oldPolicy, Version: version - 1})
+	if err != errStaleBucketPolicy {
+		t.Fatalf("Expected %s, got %v", errStaleBucketPolicy, err)
+	}
+	err = bp.SetBucketPolicy("bucket", policyChange{IsRemove: true, Version: version - 1})
+	if err != errStaleBucketPolicy {
+		t.Fatalf("Expected %s, got %v", errStaleBucketPolicy, err)
+	}
+	if bp.GetBucketPolicy("bucket") != newPolicy {
+		t.Fatal("Stale changes should not be applied")
+	}
+
+	// Versions of removed policies are kept.
+	if err = bp.SetBucketPolicy("bucket", policyChange{IsRemove: true, Version: version + 1}); err != nil {
+		t.Fatal(err)
+	}
+	if err = bp.SetBucketPolicy("bucket", policyChange{BktPolicy: oldPolicy, Version: version}); err != errStaleBucketPolicy {
+		t.Fatalf("Expected %s, got %v", errStaleBucketPolicy, err)
+	}
+}
+
 // TestBucketPolicyConditionMatch - Tests to validate whether bucket policy conditions match.
 func TestBucketPolicyConditionMatch(t *testing.T) {
 	// obtain the inner map[string]set.StringSet for policyStatement.Conditions .
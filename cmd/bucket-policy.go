@@ -18,10 +18,23 @@ package cmd
 
 import (
 	"bytes"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"io"
 	"path"
 	"sync"
+	"time"
+)
+
+var (
+	// errBucketPolicyModified means the bucket policy does not match
+	// the ETag a policy change was based on.
+	errBucketPolicyModified = errors.New("Bucket policy was modified, please reload and retry")
+
+	// errStaleBucketPolicy means a peer received a policy change older
+	// than the one it has applied.
+	errStaleBucketPolicy = errors.New("Bucket policy change is older than the applied one")
 )
 
 // Variable represents bucket policies in memory.
@@ -34,6 +47,10 @@ type bucketPolicies struct {
 
 	// Collection of 'bucket' policies.
 	bucketPolicyConfigs map[string]*bucketPolicy
+
+	// Version of the last policy change applied to each bucket,
+	// kept after removals as well.
+	bucketPolicyVersions map[string]int64
 }
 
 // Represent a policy change
@@ -44,6 +61,10 @@ type policyChange struct {
 
 	// represents the new policy for the bucket
 	BktPolicy *bucketPolicy
+
+	// Version orders changes to the policy of a bucket, changes
+	// older than the applied one are rejected by peers.
+	Version int64
 }
 
 // Fetch bucket policy for a given bucket.
@@ -53,12 +74,30 @@ func (bp bucketPolicies) GetBucketPolicy(bucket string) *bucketPolicy {
 	return bp.bucketPolicyConfigs[bucket]
 }
 
+// NextBucketPolicyVersion - returns the version for a new policy
+// change of bucket, newer than the applied one even if clocks of the
+// nodes are off.
+func (bp bucketPolicies) NextBucketPolicyVersion(bucket string) int64 {
+	bp.rwMutex.RLock()
+	defer bp.rwMutex.RUnlock()
+	version := time.Now().UTC().UnixNano()
+	if applied := bp.bucketPolicyVersions[bucket]; version <= applied {
+		version = applied + 1
+	}
+	return version
+}
+
 // Set a new bucket policy for a bucket, this operation will overwrite
-// any previous bucket policies for the bucket.
+// any previous bucket policies for the bucket. Changes older than
+// the applied one are rejected with errStaleBucketPolicy.
 func (bp *bucketPolicies) SetBucketPolicy(bucket string, pCh policyChange) error {
 	bp.rwMutex.Lock()
 	defer bp.rwMutex.Unlock()
 
+	if pCh.Version < bp.bucketPolicyVersions[bucket] {
+		return errStaleBucketPolicy
+	}
+
 	if pCh.IsRemove {
 		delete(bp.bucketPolicyConfigs, bucket)
 	} else {
@@ -67,6 +106,7 @@ func (bp *bucketPolicies) SetBucketPolicy(bucket string, pCh policyChange) error
 		}
 		bp.bucketPolicyConfigs[bucket] = pCh.BktPolicy
 	}
+	bp.bucketPolicyVersions[bucket] = pCh.Version
 	return nil
 }
 
@@ -122,8 +162,9 @@ func initBucketPolicies(objAPI ObjectLayer) error {
 
 	// Populate global bucket collection.
 	globalBucketPolicies = &bucketPolicies{
-		rwMutex:             &sync.RWMutex{},
-		bucketPolicyConfigs: policies,
+		rwMutex:              &sync.RWMutex{},
+		bucketPolicyConfigs:  policies,
+		bucketPolicyVersions: make(map[string]int64),
 	}
 
 	// Success.
@@ -190,6 +231,22 @@ func readBucketPolicy(bucket string, objAPI ObjectLayer) (*bucketPolicy, error)
 	return policy, nil
 }
 
+// getBucketPolicyETag - returns the ETag of a bucket policy, used by
+// clients for optimistic concurrency with If-Match. An empty ETag
+// stands for a bucket without policy.
+func getBucketPolicyETag(policy *bucketPolicy) (string, error) {
+	if policy == nil {
+		return "", nil
+	}
+	buf, err := json.Marshal(policy)
+	if err != nil {
+		return "", err
+	}
+	hasher := newETagHash()
+	hasher.Write(buf)
+	return hex.EncodeToString(hasher.Sum(nil)), nil
+}
+
 // removeBucketPolicy - removes any previously written bucket policy. Returns BucketPolicyNotFound
 // if no policies are found.
 func removeBucketPolicy(bucket string, objAPI ObjectLayer) error {
@@ -0,0 +1,179 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"errors"
+	"io"
+	"path"
+	"time"
+)
+
+// Objects of buckets with a retention, e.g audit logs, can be created
+// but never overwritten, nor deleted before their retention elapses.
+const (
+	retentionPrefix = "retention"
+
+	// Maximum retention, 100 years.
+	maxRetentionDays = 100 * 365
+)
+
+// errRetentionSettingsChange - retention of a bucket can only be
+// extended.
+var errRetentionSettingsChange = errors.New("Retention of a bucket can only be extended")
+
+// bucketRetention - retention of the objects of a bucket.
+type bucketRetention struct {
+	Days int `json:"days"`
+}
+
+// validate - retention must be between 1 day and maxRetentionDays.
+func (r *bucketRetention) validate() error {
+	if r.Days < 1 || r.Days > maxRetentionDays {
+		return errInvalidBucketSettings
+	}
+	return nil
+}
+
+// isRetained - returns true if an object last modified at modTime
+// cannot be deleted yet.
+func (r *bucketRetention) isRetained(modTime time.Time) bool {
+	return time.Since(modTime) < time.Duration(r.Days)*24*time.Hour
+}
+
+// getBucketRetention - returns the retention of bucket, nil if
+// objects of the bucket are mutable.
+func getBucketRetention(bucket string) *bucketRetention {
+	settings := globalBucketSettings.GetBucketSettings(bucket)
+	if settings == nil {
+		return nil
+	}
+	return settings.Retention
+}
+
+// checkRetentionSettingsChange - returns errRetentionSettingsChange if
+// the new settings of a bucket, nil when removed, remove or shorten
+// its retention.
+func checkRetentionSettingsChange(bucket string, settings *bucketSettings) error {
+	retention := getBucketRetention(bucket)
+	if retention == nil {
+		return nil
+	}
+	if settings == nil || settings.Retention == nil || settings.Retention.Days < retention.Days {
+		return errRetentionSettingsChange
+	}
+	return nil
+}
+
+// Objects are locked in the meta bucket, the object itself is locked
+// by PutObject.
+func retentionLockPath(bucket, object string) string {
+	return path.Join(retentionPrefix, bucket, object)
+}
+
+// retentionObjects - object layer enforcing the retention of buckets,
+// all other buckets are served by the underlying object layer.
+type retentionObjects struct {
+	ObjectLayer
+}
+
+// newRetentionObjects - returns objAPI enforcing bucket retentions.
+func newRetentionObjects(objAPI ObjectLayer) ObjectLayer {
+	return retentionObjects{objAPI}
+}
+
+// checkObjectAbsent - returns ObjectImmutable if object exists.
+func (r retentionObjects) checkObjectAbsent(bucket, object string) error {
+	_, err := r.ObjectLayer.GetObjectInfo(bucket, object)
+	if err == nil {
+		return traceError(ObjectImmutable{Bucket: bucket, Object: object})
+	}
+	if _, ok := errorCause(err).(ObjectNotFound); ok {
+		return nil
+	}
+	return err
+}
+
+// PutObject - creates an object, objects of buckets with a retention
+// are never overwritten.
+func (r retentionObjects) PutObject(bucket, object string, size int64, data io.Reader, metadata map[string]string, sha256sum string) (ObjectInfo, error) {
+	if getBucketRetention(bucket) == nil {
+		return r.ObjectLayer.PutObject(bucket, object, size, data, metadata, sha256sum)
+	}
+
+	opsID := getOpsID()
+	lockPath := retentionLockPath(bucket, object)
+	nsMutex.Lock(minioMetaBucket, lockPath, opsID)
+	defer nsMutex.Unlock(minioMetaBucket, lockPath, opsID)
+
+	if err := r.checkObjectAbsent(bucket, object); err != nil {
+		return ObjectInfo{}, err
+	}
+	return r.ObjectLayer.PutObject(bucket, object, size, data, metadata, sha256sum)
+}
+
+// NewMultipartUpload - initiates a multipart upload, rejected early
+// for existing objects of buckets with a retention.
+func (r retentionObjects) NewMultipartUpload(bucket, object string, metadata map[string]string) (string, error) {
+	if getBucketRetention(bucket) != nil {
+		if err := r.checkObjectAbsent(bucket, object); err != nil {
+			return "", err
+		}
+	}
+	return r.ObjectLayer.NewMultipartUpload(bucket, object, metadata)
+}
+
+// CompleteMultipartUpload - completes a multipart upload, objects of
+// buckets with a retention are never overwritten.
+func (r retentionObjects) CompleteMultipartUpload(bucket, object, uploadID string, uploadedParts []completePart) (string, error) {
+	if getBucketRetention(bucket) == nil {
+		return r.ObjectLayer.CompleteMultipartUpload(bucket, object, uploadID, uploadedParts)
+	}
+
+	opsID := getOpsID()
+	lockPath := retentionLockPath(bucket, object)
+	nsMutex.Lock(minioMetaBucket, lockPath, opsID)
+	defer nsMutex.Unlock(minioMetaBucket, lockPath, opsID)
+
+	if err := r.checkObjectAbsent(bucket, object); err != nil {
+		return "", err
+	}
+	return r.ObjectLayer.CompleteMultipartUpload(bucket, object, uploadID, uploadedParts)
+}
+
+// DeleteObject - deletes an object, objects of buckets with a
+// retention are only deleted once their retention elapsed.
+func (r retentionObjects) DeleteObject(bucket, object string) error {
+	retention := getBucketRetention(bucket)
+	if retention == nil {
+		return r.ObjectLayer.DeleteObject(bucket, object)
+	}
+
+	opsID := getOpsID()
+	lockPath := retentionLockPath(bucket, object)
+	nsMutex.Lock(minioMetaBucket, lockPath, opsID)
+	defer nsMutex.Unlock(minioMetaBucket, lockPath, opsID)
+
+	objInfo, err := r.ObjectLayer.GetObjectInfo(bucket, object)
+	if err != nil {
+		return err
+	}
+	if retention.isRetained(objInfo.ModTime) {
+		return traceError(ObjectImmutable{Bucket: bucket, Object: object})
+	}
+	return r.ObjectLayer.DeleteObject(bucket, object)
+}
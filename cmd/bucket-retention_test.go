@@ -0,0 +1,127 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+// Tests objects are retained for the number of days of the retention.
+func TestBucketRetentionIsRetained(t *testing.T) {
+	retention := &bucketRetention{Days: 2}
+	testCases := []struct {
+		modTime  time.Time
+		retained bool
+	}{
+		{time.Now().UTC(), true},
+		{time.Now().UTC().Add(-47 * time.Hour), true},
+		{time.Now().UTC().Add(-49 * time.Hour), false},
+	}
+	for i, testCase := range testCases {
+		if retained := retention.isRetained(testCase.modTime); retained != testCase.retained {
+			t.Errorf("Test %d: Expected retained %v, got %v", i+1, testCase.retained, retained)
+		}
+	}
+}
+
+// Tests the retention of a bucket can only be extended.
+func TestCheckRetentionSettingsChange(t *testing.T) {
+	globalBucketSettings.SetBucketSettings("audit-bucket", &bucketSettings{Retention: &bucketRetention{Days: 30}})
+	defer globalBucketSettings.SetBucketSettings("audit-bucket", nil)
+
+	testCases := []struct {
+		bucket   string
+		settings *bucketSettings
+		err      error
+	}{
+		{"bucket", &bucketSettings{Retention: &bucketRetention{Days: 1}}, nil},
+		{"bucket", nil, nil},
+		{"audit-bucket", &bucketSettings{Retention: &bucketRetention{Days: 30}}, nil},
+		{"audit-bucket", &bucketSettings{Retention: &bucketRetention{Days: 365}}, nil},
+		{"audit-bucket", &bucketSettings{Retention: &bucketRetention{Days: 29}}, errRetentionSettingsChange},
+		{"audit-bucket", &bucketSettings{CacheControl: "no-cache"}, errRetentionSettingsChange},
+		{"audit-bucket", nil, errRetentionSettingsChange},
+	}
+	for i, testCase := range testCases {
+		if err := checkRetentionSettingsChange(testCase.bucket, testCase.settings); err != testCase.err {
+			t.Errorf("Test %d: Expected error %v, got %v", i+1, testCase.err, err)
+		}
+	}
+}
+
+// Wrapper for calling retention tests for both XL and FS.
+func TestRetentionObjects(t *testing.T) {
+	ExecObjectLayerTest(t, testRetentionObjects)
+}
+
+// Tests objects of buckets with a retention are never overwritten nor
+// deleted before the retention elapses.
+func testRetentionObjects(obj ObjectLayer, instanceType string, t TestErrHandler) {
+	bucket := "audit-bucket"
+	if err := obj.MakeBucket(bucket); err != nil {
+		t.Fatalf("%s: Unable to make bucket: %v", instanceType, err)
+	}
+	globalBucketSettings.SetBucketSettings(bucket, &bucketSettings{Retention: &bucketRetention{Days: 1}})
+	defer globalBucketSettings.SetBucketSettings(bucket, nil)
+	retentionObj := newRetentionObjects(obj)
+
+	data := []byte("hello")
+	if _, err := retentionObj.PutObject(bucket, "object", int64(len(data)), bytes.NewReader(data), nil, ""); err != nil {
+		t.Fatalf("%s: Unable to put object: %v", instanceType, err)
+	}
+	if _, err := retentionObj.PutObject(bucket, "object", int64(len(data)), bytes.NewReader(data), nil, ""); !isObjectImmutable(err) {
+		t.Errorf("%s: Expected ObjectImmutable overwriting object, got %v", instanceType, err)
+	}
+	if _, err := retentionObj.NewMultipartUpload(bucket, "object", nil); !isObjectImmutable(err) {
+		t.Errorf("%s: Expected ObjectImmutable uploading object, got %v", instanceType, err)
+	}
+	if err := retentionObj.DeleteObject(bucket, "object"); !isObjectImmutable(err) {
+		t.Errorf("%s: Expected ObjectImmutable deleting object, got %v", instanceType, err)
+	}
+
+	// Multipart uploads initiated before the object was created are
+	// rejected on completion.
+	uploadID, err := retentionObj.NewMultipartUpload(bucket, "multipart", nil)
+	if err != nil {
+		t.Fatalf("%s: Unable to initiate multipart upload: %v", instanceType, err)
+	}
+	partInfo, err := retentionObj.PutObjectPart(bucket, "multipart", uploadID, 1, int64(len(data)), bytes.NewReader(data), "", "")
+	if err != nil {
+		t.Fatalf("%s: Unable to upload part: %v", instanceType, err)
+	}
+	if _, err = retentionObj.PutObject(bucket, "multipart", int64(len(data)), bytes.NewReader(data), nil, ""); err != nil {
+		t.Fatalf("%s: Unable to put object: %v", instanceType, err)
+	}
+	parts := []completePart{{PartNumber: 1, ETag: partInfo}}
+	if _, err = retentionObj.CompleteMultipartUpload(bucket, "multipart", uploadID, parts); !isObjectImmutable(err) {
+		t.Errorf("%s: Expected ObjectImmutable completing upload, got %v", instanceType, err)
+	}
+
+	// Without a retention, objects are deleted as usual.
+	globalBucketSettings.SetBucketSettings(bucket, nil)
+	if err = retentionObj.DeleteObject(bucket, "object"); err != nil {
+		t.Errorf("%s: Unable to delete object: %v", instanceType, err)
+	}
+}
+
+// isObjectImmutable - returns true if err is ObjectImmutable.
+func isObjectImmutable(err error) bool {
+	_, ok := errorCause(err).(ObjectImmutable)
+	return ok
+}
@@ -0,0 +1,89 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+
+	mux "github.com/gorilla/mux"
+)
+
+// SearchObjectsHandler - POST Bucket search (minio extension)
+// -----------------
+// This operation uses the search subresource to find objects of a
+// bucket by their user metadata, in the index kept for buckets with
+// search enabled in their settings.
+func (api objectAPIHandlers) SearchObjectsHandler(w http.ResponseWriter, r *http.Request) {
+	objAPI := api.ObjectAPI()
+	if objAPI == nil {
+		writeErrorResponse(w, r, ErrServerNotInitialized, r.URL.Path)
+		return
+	}
+
+	// SearchObjects does not support bucket policies, use checkAuth to validate signature.
+	if s3Error := checkAuth(r); s3Error != ErrNone {
+		errorIf(errSignatureMismatch, dumpRequest(r))
+		writeErrorResponse(w, r, s3Error, r.URL.Path)
+		return
+	}
+
+	vars := mux.Vars(r)
+	bucket := vars["bucket"]
+
+	if err := isBucketExist(bucket, objAPI); err != nil {
+		writeErrorResponse(w, r, toAPIErrorCode(err), r.URL.Path)
+		return
+	}
+
+	// If Content-Length is greater than maximum allowed query size.
+	if r.ContentLength > maxSearchQuerySize {
+		writeErrorResponse(w, r, ErrEntityTooLarge, r.URL.Path)
+		return
+	}
+	var query searchQuery
+	if err := json.NewDecoder(io.LimitReader(r.Body, maxSearchQuerySize)).Decode(&query); err != nil {
+		writeErrorResponse(w, r, ErrInvalidSearchQuery, r.URL.Path)
+		return
+	}
+	if err := query.validate(); err != nil {
+		writeErrorResponse(w, r, ErrInvalidSearchQuery, r.URL.Path)
+		return
+	}
+
+	result, err := searchObjects(objAPI, bucket, query)
+	if err != nil {
+		switch err {
+		case errSearchNotEnabled, errInvalidSearchQuery:
+			writeErrorResponse(w, r, toAPIErrorCode(err), r.URL.Path)
+		default:
+			errorIf(err, "Unable to search objects.")
+			writeErrorResponse(w, r, toAPIErrorCode(err), r.URL.Path)
+		}
+		return
+	}
+
+	resultBytes, err := json.Marshal(result)
+	if err != nil {
+		errorIf(err, "Unable to marshal search result.")
+		writeErrorResponse(w, r, ErrInternalError, r.URL.Path)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	writeSuccessResponse(w, resultBytes)
+}
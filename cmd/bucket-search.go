@@ -0,0 +1,378 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+)
+
+// Buckets with search enabled in their settings keep an index of the
+// user metadata of their objects in '.minio.sys/search/<bucket>/'.
+// 'objects/<object>' holds the indexed metadata of an object, and
+// 'meta/<key>=<value>/<object>' is an empty entry per metadata pair,
+// so that objects are found by listing '<key>=<value>'. Keys and values are query escaped, keys are lower cased without
+// their 'x-amz-meta-' prefix.
+const (
+	searchPrefix        = "search"
+	searchObjectsPrefix = "objects"
+	searchMetaPrefix    = "meta"
+	searchLocksPrefix   = "locks"
+
+	// Metadata pairs longer than this, once escaped, are not indexed.
+	maxSearchEntryLen = 255
+
+	// Maximum number of predicates of a search query.
+	maxSearchPredicates = 10
+
+	// Maximum size of a search query.
+	maxSearchQuerySize = 20 * 1024 // 20KiB.
+)
+
+// errInvalidSearchQuery - search query is malformed or has invalid values.
+var errInvalidSearchQuery = errors.New("Invalid search query")
+
+// errSearchNotEnabled - search is not enabled for the bucket.
+var errSearchNotEnabled = errors.New("Search is not enabled for the bucket")
+
+// searchPredicate - matches objects with the metadata key, and either
+// the value or a value starting with prefix. Objects with the key are
+// all matched if neither is set.
+type searchPredicate struct {
+	Key    string `json:"key"`
+	Value  string `json:"value,omitempty"`
+	Prefix string `json:"prefix,omitempty"`
+}
+
+// searchQuery - finds objects matching all the predicates, whose name
+// starts with prefix.
+type searchQuery struct {
+	Prefix     string            `json:"prefix,omitempty"`
+	Predicates []searchPredicate `json:"predicates"`
+	Marker     string            `json:"marker,omitempty"`
+	MaxKeys    int               `json:"maxKeys,omitempty"`
+}
+
+// searchResultObject - object found by a search query.
+type searchResultObject struct {
+	Key          string            `json:"key"`
+	Size         int64             `json:"size"`
+	ETag         string            `json:"etag"`
+	LastModified time.Time         `json:"lastModified"`
+	Metadata     map[string]string `json:"metadata"`
+}
+
+// searchResult - objects found by a search query, the query is
+// continued by setting its marker to nextMarker.
+type searchResult struct {
+	Objects     []searchResultObject `json:"objects"`
+	IsTruncated bool                 `json:"isTruncated"`
+	NextMarker  string               `json:"nextMarker,omitempty"`
+}
+
+// validate - validates the query, keys are lower cased.
+func (q *searchQuery) validate() error {
+	if len(q.Predicates) == 0 || len(q.Predicates) > maxSearchPredicates {
+		return errInvalidSearchQuery
+	}
+	for i, predicate := range q.Predicates {
+		if predicate.Key == "" || (predicate.Value != "" && predicate.Prefix != "") {
+			return errInvalidSearchQuery
+		}
+		q.Predicates[i].Key = strings.ToLower(predicate.Key)
+	}
+	if q.MaxKeys <= 0 || q.MaxKeys > maxObjectList {
+		q.MaxKeys = maxObjectList
+	}
+	return nil
+}
+
+// matches - returns true if the indexed metadata matches the predicate.
+func (p searchPredicate) matches(metadata map[string]string) bool {
+	value, ok := metadata[p.Key]
+	if !ok {
+		return false
+	}
+	if p.Value != "" {
+		return value == p.Value
+	}
+	return strings.HasPrefix(value, p.Prefix)
+}
+
+// listPrefix - returns the prefix of the index entries of objects
+// matching the predicate, relative to the meta prefix of the bucket.
+func (p searchPredicate) listPrefix() string {
+	if p.Value != "" {
+		return searchEntry(p.Key, p.Value) + slashSeparator
+	}
+	return searchEntry(p.Key, p.Prefix)
+}
+
+// isSearchBucket - returns true if search is enabled for bucket.
+func isSearchBucket(bucket string) bool {
+	settings := globalBucketSettings.GetBucketSettings(bucket)
+	return settings != nil && settings.Search
+}
+
+// searchEntry - returns the index entry of a metadata pair.
+func searchEntry(key, value string) string {
+	return url.QueryEscape(key) + "=" + url.QueryEscape(value)
+}
+
+func searchObjectPath(bucket, object string) string {
+	return path.Join(searchPrefix, bucket, searchObjectsPrefix, object)
+}
+
+func searchMetaPath(bucket string) string {
+	return path.Join(searchPrefix, bucket, searchMetaPrefix) + slashSeparator
+}
+
+// Objects are locked by a path other than the ones written, which are
+// locked by PutObject.
+func searchLockPath(bucket, object string) string {
+	return path.Join(searchPrefix, bucket, searchLocksPrefix, object)
+}
+
+// getIndexedMetadata - returns the user metadata of an object to index.
+func getIndexedMetadata(userDefined map[string]string) map[string]string {
+	metadata := make(map[string]string)
+	for key, value := range userDefined {
+		key = strings.ToLower(key)
+		if !strings.HasPrefix(key, "x-amz-meta-") {
+			continue
+		}
+		key = strings.TrimPrefix(key, "x-amz-meta-")
+		if len(searchEntry(key, value)) > maxSearchEntryLen {
+			continue
+		}
+		metadata[key] = value
+	}
+	return metadata
+}
+
+// readIndexedMetadata - returns the indexed metadata of an object,
+// empty if not indexed.
+func readIndexedMetadata(objAPI ObjectLayer, bucket, object string) (map[string]string, error) {
+	metadata := make(map[string]string)
+	objectPath := searchObjectPath(bucket, object)
+	objInfo, err := objAPI.GetObjectInfo(minioMetaBucket, objectPath)
+	if err != nil {
+		if _, ok := errorCause(err).(ObjectNotFound); ok {
+			return metadata, nil
+		}
+		return nil, err
+	}
+	var buffer bytes.Buffer
+	if err = objAPI.GetObject(minioMetaBucket, objectPath, 0, objInfo.Size, &buffer); err != nil {
+		return nil, err
+	}
+	if err = json.Unmarshal(buffer.Bytes(), &metadata); err != nil {
+		return nil, err
+	}
+	return metadata, nil
+}
+
+// updateObjectIndex - updates the index entries of an object to its
+// current metadata, removing them if the object no longer exists.
+func updateObjectIndex(objAPI ObjectLayer, bucket, object string) error {
+	opsID := getOpsID()
+	lockPath := searchLockPath(bucket, object)
+	nsMutex.Lock(minioMetaBucket, lockPath, opsID)
+	defer nsMutex.Unlock(minioMetaBucket, lockPath, opsID)
+
+	oldMetadata, err := readIndexedMetadata(objAPI, bucket, object)
+	if err != nil {
+		return err
+	}
+	metadata := make(map[string]string)
+	objInfo, err := objAPI.GetObjectInfo(bucket, object)
+	if err == nil {
+		metadata = getIndexedMetadata(objInfo.UserDefined)
+	} else if _, ok := errorCause(err).(ObjectNotFound); !ok {
+		return err
+	}
+
+	metaPath := searchMetaPath(bucket)
+	for key, value := range oldMetadata {
+		if newValue, ok := metadata[key]; ok && newValue == value {
+			continue
+		}
+		if err = objAPI.DeleteObject(minioMetaBucket, path.Join(metaPath, searchEntry(key, value), object)); err != nil {
+			if _, ok := errorCause(err).(ObjectNotFound); !ok {
+				return err
+			}
+		}
+	}
+	for key, value := range metadata {
+		if oldValue, ok := oldMetadata[key]; ok && oldValue == value {
+			continue
+		}
+		if _, err = objAPI.PutObject(minioMetaBucket, path.Join(metaPath, searchEntry(key, value), object), 0, bytes.NewReader(nil), nil, ""); err != nil {
+			return err
+		}
+	}
+
+	objectPath := searchObjectPath(bucket, object)
+	if len(metadata) == 0 {
+		if len(oldMetadata) == 0 {
+			return nil
+		}
+		return objAPI.DeleteObject(minioMetaBucket, objectPath)
+	}
+	data, err := json.Marshal(metadata)
+	if err != nil {
+		return err
+	}
+	_, err = objAPI.PutObject(minioMetaBucket, objectPath, int64(len(data)), bytes.NewReader(data), nil, "")
+	return err
+}
+
+// eventNotifyForBucketSearch - updates the search index of a bucket
+// on object events, if search is enabled.
+func eventNotifyForBucketSearch(bucket, object string) {
+	if !isSearchBucket(bucket) {
+		return
+	}
+	objAPI := newObjectLayerFn()
+	if objAPI == nil {
+		return
+	}
+	errorIf(updateObjectIndex(objAPI, bucket, object), "Unable to update search index of %s in bucket %s.", object, bucket)
+}
+
+// indexBucket - indexes all the objects of a bucket, when search is
+// enabled after objects were uploaded.
+func indexBucket(objAPI ObjectLayer, bucket string) error {
+	marker := ""
+	for {
+		result, err := objAPI.ListObjects(bucket, "", marker, "", maxObjectList)
+		if err != nil {
+			return err
+		}
+		for _, objInfo := range result.Objects {
+			if err = updateObjectIndex(objAPI, bucket, objInfo.Name); err != nil {
+				return err
+			}
+		}
+		if !result.IsTruncated {
+			return nil
+		}
+		marker = result.NextMarker
+	}
+}
+
+// removeBucketIndex - removes the search index of a bucket.
+func removeBucketIndex(objAPI ObjectLayer, bucket string) error {
+	prefix := path.Join(searchPrefix, bucket) + slashSeparator
+	for {
+		// Entries are deleted, so always list from the start.
+		result, err := objAPI.ListObjects(minioMetaBucket, prefix, "", "", maxObjectList)
+		if err != nil {
+			return err
+		}
+		for _, objInfo := range result.Objects {
+			if err = objAPI.DeleteObject(minioMetaBucket, objInfo.Name); err != nil {
+				return err
+			}
+		}
+		if !result.IsTruncated {
+			return nil
+		}
+	}
+}
+
+// searchObjects - returns the objects of bucket matching the query,
+// by listing the index entries of the first predicate and checking
+// the indexed metadata of these objects against the others.
+func searchObjects(objAPI ObjectLayer, bucket string, query searchQuery) (searchResult, error) {
+	result := searchResult{Objects: []searchResultObject{}}
+	if !isSearchBucket(bucket) {
+		return result, errSearchNotEnabled
+	}
+
+	metaPath := searchMetaPath(bucket)
+	listPrefix := metaPath + query.Predicates[0].listPrefix()
+	marker := ""
+	if query.Marker != "" {
+		marker = metaPath + query.Marker
+		if !strings.HasPrefix(marker, listPrefix) {
+			return result, errInvalidSearchQuery
+		}
+	}
+	for {
+		entries, err := objAPI.ListObjects(minioMetaBucket, listPrefix, marker, "", maxObjectList)
+		if err != nil {
+			return result, err
+		}
+		for _, entry := range entries.Objects {
+			if len(result.Objects) == query.MaxKeys {
+				result.IsTruncated = true
+				result.NextMarker = strings.TrimPrefix(marker, metaPath)
+				return result, nil
+			}
+			marker = entry.Name
+
+			// Entries are 'meta/<key>=<value>/<object>'.
+			entryPath := strings.TrimPrefix(entry.Name, metaPath)
+			object := entryPath[strings.Index(entryPath, slashSeparator)+1:]
+			if !strings.HasPrefix(object, query.Prefix) {
+				continue
+			}
+			metadata, err := readIndexedMetadata(objAPI, bucket, object)
+			if err != nil {
+				return result, err
+			}
+			if !matchesAllPredicates(query.Predicates, metadata) {
+				continue
+			}
+			objInfo, err := objAPI.GetObjectInfo(bucket, object)
+			if err != nil {
+				// Skip objects deleted since indexed.
+				if _, ok := errorCause(err).(ObjectNotFound); ok {
+					continue
+				}
+				return result, err
+			}
+			result.Objects = append(result.Objects, searchResultObject{
+				Key:          object,
+				Size:         objInfo.Size,
+				ETag:         objInfo.MD5Sum,
+				LastModified: objInfo.ModTime,
+				Metadata:     metadata,
+			})
+		}
+		if !entries.IsTruncated {
+			return result, nil
+		}
+	}
+}
+
+// matchesAllPredicates - returns true if the indexed metadata matches
+// all the predicates.
+func matchesAllPredicates(predicates []searchPredicate, metadata map[string]string) bool {
+	for _, predicate := range predicates {
+		if !predicate.matches(metadata) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,215 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// Tests only user metadata is indexed, with lower cased keys.
+func TestGetIndexedMetadata(t *testing.T) {
+	metadata := getIndexedMetadata(map[string]string{
+		"content-type":     "text/plain",
+		"X-Amz-Meta-Color": "red",
+		"x-amz-meta-size":  "large",
+		"X-Minio-Meta-Key": "value",
+		"X-Amz-Meta-Long":  strings.Repeat("a", maxSearchEntryLen),
+	})
+	expected := map[string]string{"color": "red", "size": "large"}
+	if !reflect.DeepEqual(metadata, expected) {
+		t.Errorf("Expected %v, got %v", expected, metadata)
+	}
+}
+
+// Tests validating search queries.
+func TestSearchQueryValidate(t *testing.T) {
+	testCases := []struct {
+		query string
+		err   error
+	}{
+		{`{"predicates":[{"key":"Color","value":"red"}]}`, nil},
+		{`{"prefix":"logs/","predicates":[{"key":"color","prefix":"r"},{"key":"size"}],"maxKeys":10}`, nil},
+		// No predicates.
+		{`{"prefix":"logs/"}`, errInvalidSearchQuery},
+		// Predicate without key.
+		{`{"predicates":[{"value":"red"}]}`, errInvalidSearchQuery},
+		// Predicate with both value and prefix.
+		{`{"predicates":[{"key":"color","value":"red","prefix":"r"}]}`, errInvalidSearchQuery},
+	}
+	for i, testCase := range testCases {
+		var query searchQuery
+		if err := json.Unmarshal([]byte(testCase.query), &query); err != nil {
+			t.Fatalf("Test %d: Unable to parse query: %v", i+1, err)
+		}
+		if err := query.validate(); err != testCase.err {
+			t.Errorf("Test %d: Expected error %v, got %v", i+1, testCase.err, err)
+		}
+	}
+}
+
+// Wrapper for calling search tests for both XL and FS.
+func TestSearchObjects(t *testing.T) {
+	ExecObjectLayerTest(t, testSearchObjects)
+}
+
+// Tests searching objects by their indexed metadata.
+func testSearchObjects(obj ObjectLayer, instanceType string, t TestErrHandler) {
+	bucket := "search-bucket"
+	if err := obj.MakeBucket(bucket); err != nil {
+		t.Fatalf("%s: Unable to make bucket: %v", instanceType, err)
+	}
+	objects := map[string]map[string]string{
+		"a/1": {"X-Amz-Meta-Color": "red", "X-Amz-Meta-Size": "large"},
+		"a/2": {"X-Amz-Meta-Color": "red", "X-Amz-Meta-Size": "small"},
+		"b/3": {"X-Amz-Meta-Color": "red/dark"},
+		"b/4": {"X-Amz-Meta-Color": "blue"},
+		"b/5": {},
+	}
+	for object, metadata := range objects {
+		if _, err := obj.PutObject(bucket, object, 5, bytes.NewReader([]byte("hello")), metadata, ""); err != nil {
+			t.Fatalf("%s: Unable to put object: %v", instanceType, err)
+		}
+	}
+
+	query := searchQuery{Predicates: []searchPredicate{{Key: "color", Value: "red"}}}
+	if _, err := searchObjects(obj, bucket, query); err != errSearchNotEnabled {
+		t.Errorf("%s: Expected error %v, got %v", instanceType, errSearchNotEnabled, err)
+	}
+	globalBucketSettings.SetBucketSettings(bucket, &bucketSettings{Search: true})
+	defer globalBucketSettings.SetBucketSettings(bucket, nil)
+	if err := indexBucket(obj, bucket); err != nil {
+		t.Fatalf("%s: Unable to index bucket: %v", instanceType, err)
+	}
+
+	testCases := []searchQuery{
+		{Predicates: []searchPredicate{{Key: "color", Value: "red"}}},
+		{Predicates: []searchPredicate{{Key: "color", Prefix: "red"}}},
+		{Predicates: []searchPredicate{{Key: "color", Value: "red"}, {Key: "size", Prefix: "sm"}}},
+		{Prefix: "b/", Predicates: []searchPredicate{{Key: "color"}}},
+		{Predicates: []searchPredicate{{Key: "color", Value: "green"}}},
+	}
+	expected := [][]string{
+		{"a/1", "a/2"},
+		{"b/3", "a/1", "a/2"},
+		{"a/2"},
+		{"b/4", "b/3"},
+		{},
+	}
+	for i, query := range testCases {
+		if err := query.validate(); err != nil {
+			t.Fatalf("%s: Test %d: Invalid query: %v", instanceType, i+1, err)
+		}
+		result, err := searchObjects(obj, bucket, query)
+		if err != nil {
+			t.Fatalf("%s: Test %d: Unable to search objects: %v", instanceType, i+1, err)
+		}
+		keys := []string{}
+		for _, object := range result.Objects {
+			keys = append(keys, object.Key)
+		}
+		if !reflect.DeepEqual(keys, expected[i]) {
+			t.Errorf("%s: Test %d: Expected %v, got %v", instanceType, i+1, expected[i], keys)
+		}
+	}
+
+	// Paginated results, sorted by their index entries.
+	query = searchQuery{Predicates: []searchPredicate{{Key: "color", Prefix: "red"}}, MaxKeys: 2}
+	result, err := searchObjects(obj, bucket, query)
+	if err != nil || len(result.Objects) != 2 || !result.IsTruncated {
+		t.Fatalf("%s: Expected a truncated result, got %+v (%v)", instanceType, result, err)
+	}
+	query.Marker = result.NextMarker
+	result, err = searchObjects(obj, bucket, query)
+	if err != nil || len(result.Objects) != 1 || result.Objects[0].Key != "a/2" || result.IsTruncated {
+		t.Fatalf("%s: Expected the last object, got %+v (%v)", instanceType, result, err)
+	}
+
+	// Deleted objects are removed from the index.
+	if err = obj.DeleteObject(bucket, "a/1"); err != nil {
+		t.Fatalf("%s: Unable to delete object: %v", instanceType, err)
+	}
+	if err = updateObjectIndex(obj, bucket, "a/1"); err != nil {
+		t.Fatalf("%s: Unable to update index: %v", instanceType, err)
+	}
+	if _, err = obj.GetObjectInfo(minioMetaBucket, searchObjectPath(bucket, "a/1")); err == nil {
+		t.Errorf("%s: Expected deleted object to be removed from the index", instanceType)
+	}
+
+	if err = removeBucketIndex(obj, bucket); err != nil {
+		t.Fatalf("%s: Unable to remove index: %v", instanceType, err)
+	}
+	entries, err := obj.ListObjects(minioMetaBucket, searchPrefix+slashSeparator, "", "", 10)
+	if err != nil || len(entries.Objects) != 0 {
+		t.Errorf("%s: Expected an empty index, got %+v (%v)", instanceType, entries.Objects, err)
+	}
+}
+
+// Wrapper for calling search HTTP handler tests for both XL multiple disks and single node setup.
+func TestSearchObjectsHandler(t *testing.T) {
+	ExecObjectLayerAPITest(t, testSearchObjectsHandler, []string{"SearchObjects"})
+}
+
+func testSearchObjectsHandler(obj ObjectLayer, instanceType, bucketName string, apiRouter http.Handler,
+	credentials credential, t *testing.T) {
+	doRequest := func(body string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		req, err := newTestSignedRequestV4("POST", getSearchObjectsURL("", bucketName), int64(len(body)),
+			strings.NewReader(body), credentials.AccessKeyID, credentials.SecretAccessKey)
+		if err != nil {
+			t.Fatalf("%s: Failed to create HTTP request: <ERROR> %v", instanceType, err)
+		}
+		apiRouter.ServeHTTP(rec, req)
+		return rec
+	}
+
+	query := `{"predicates":[{"key":"color","value":"red"}]}`
+	if rec := doRequest(query); rec.Code != http.StatusBadRequest {
+		t.Fatalf("%s: Expected %d without search enabled, got %d", instanceType, http.StatusBadRequest, rec.Code)
+	}
+
+	globalBucketSettings.SetBucketSettings(bucketName, &bucketSettings{Search: true})
+	defer globalBucketSettings.SetBucketSettings(bucketName, nil)
+	metadata := map[string]string{"X-Amz-Meta-Color": "red"}
+	if _, err := obj.PutObject(bucketName, "object", 5, bytes.NewReader([]byte("hello")), metadata, ""); err != nil {
+		t.Fatalf("%s: Unable to put object: %v", instanceType, err)
+	}
+	if err := updateObjectIndex(obj, bucketName, "object"); err != nil {
+		t.Fatalf("%s: Unable to index object: %v", instanceType, err)
+	}
+
+	if rec := doRequest(`{"predicates":[]}`); rec.Code != http.StatusBadRequest {
+		t.Fatalf("%s: Expected %d for an invalid query, got %d", instanceType, http.StatusBadRequest, rec.Code)
+	}
+	rec := doRequest(query)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("%s: Expected %d, got %d", instanceType, http.StatusOK, rec.Code)
+	}
+	var result searchResult
+	if err := json.Unmarshal(rec.Body.Bytes(), &result); err != nil {
+		t.Fatalf("%s: Unable to parse search result: %v", instanceType, err)
+	}
+	if len(result.Objects) != 1 || result.Objects[0].Key != "object" || result.Objects[0].Size != 5 ||
+		result.Objects[0].Metadata["color"] != "red" {
+		t.Errorf("%s: Unexpected search result %+v", instanceType, result)
+	}
+}
@@ -0,0 +1,162 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+
+	mux "github.com/gorilla/mux"
+)
+
+// maximum supported bucket settings size.
+const maxBucketSettingsSize = 20 * 1024 // 20KiB.
+
+// PutBucketSettingsHandler - PUT Bucket settings (minio extension)
+// -----------------
+// This operation uses the settings subresource to add to or replace
+// the default object settings of a bucket.
+func (api objectAPIHandlers) PutBucketSettingsHandler(w http.ResponseWriter, r *http.Request) {
+	objAPI := api.ObjectAPI()
+	if objAPI == nil {
+		writeErrorResponse(w, r, ErrServerNotInitialized, r.URL.Path)
+		return
+	}
+
+	// PutBucketSettings does not support bucket policies, use checkAuth to validate signature.
+	if s3Error := checkAuth(r); s3Error != ErrNone {
+		errorIf(errSignatureMismatch, dumpRequest(r))
+		writeErrorResponse(w, r, s3Error, r.URL.Path)
+		return
+	}
+
+	vars := mux.Vars(r)
+	bucket := vars["bucket"]
+
+	// If Content-Length is unknown or zero, deny the request.
+	if !contains(r.TransferEncoding, "chunked") {
+		if r.ContentLength == -1 || r.ContentLength == 0 {
+			writeErrorResponse(w, r, ErrMissingContentLength, r.URL.Path)
+			return
+		}
+		// If Content-Length is greater than maximum allowed settings size.
+		if r.ContentLength > maxBucketSettingsSize {
+			writeErrorResponse(w, r, ErrEntityTooLarge, r.URL.Path)
+			return
+		}
+	}
+
+	settings, err := parseBucketSettings(io.LimitReader(r.Body, maxBucketSettingsSize))
+	if err != nil {
+		errorIf(err, "Unable to parse bucket settings.")
+		writeErrorResponse(w, r, ErrInvalidBucketSettings, r.URL.Path)
+		return
+	}
+
+	if err = persistAndNotifyBucketSettingsChange(bucket, settings, objAPI); err != nil {
+		errorIf(err, "Unable to save bucket settings.")
+		writeErrorResponse(w, r, toAPIErrorCode(err), r.URL.Path)
+		return
+	}
+
+	// Success.
+	writeSuccessNoContent(w)
+}
+
+// DeleteBucketSettingsHandler - DELETE Bucket settings (minio extension)
+// -----------------
+// This operation uses the settings subresource to remove the default
+// object settings of a bucket.
+func (api objectAPIHandlers) DeleteBucketSettingsHandler(w http.ResponseWriter, r *http.Request) {
+	objAPI := api.ObjectAPI()
+	if objAPI == nil {
+		writeErrorResponse(w, r, ErrServerNotInitialized, r.URL.Path)
+		return
+	}
+
+	// DeleteBucketSettings does not support bucket policies, use checkAuth to validate signature.
+	if s3Error := checkAuth(r); s3Error != ErrNone {
+		errorIf(errSignatureMismatch, dumpRequest(r))
+		writeErrorResponse(w, r, s3Error, r.URL.Path)
+		return
+	}
+
+	vars := mux.Vars(r)
+	bucket := vars["bucket"]
+
+	if err := persistAndNotifyBucketSettingsChange(bucket, nil, objAPI); err != nil {
+		switch err.(type) {
+		case BucketSettingsNotFound:
+			writeErrorResponse(w, r, ErrNoSuchBucketSettings, r.URL.Path)
+		default:
+			errorIf(err, "Unable to remove bucket settings.")
+			writeErrorResponse(w, r, toAPIErrorCode(err), r.URL.Path)
+		}
+		return
+	}
+
+	// Success.
+	writeSuccessNoContent(w)
+}
+
+// GetBucketSettingsHandler - GET Bucket settings (minio extension)
+// -----------------
+// This operation uses the settings subresource to return the default
+// object settings of a bucket.
+func (api objectAPIHandlers) GetBucketSettingsHandler(w http.ResponseWriter, r *http.Request) {
+	objAPI := api.ObjectAPI()
+	if objAPI == nil {
+		writeErrorResponse(w, r, ErrServerNotInitialized, r.URL.Path)
+		return
+	}
+
+	// GetBucketSettings does not support bucket policies, use checkAuth to validate signature.
+	if s3Error := checkAuth(r); s3Error != ErrNone {
+		errorIf(errSignatureMismatch, dumpRequest(r))
+		writeErrorResponse(w, r, s3Error, r.URL.Path)
+		return
+	}
+
+	vars := mux.Vars(r)
+	bucket := vars["bucket"]
+
+	if err := isBucketExist(bucket, objAPI); err != nil {
+		writeErrorResponse(w, r, toAPIErrorCode(err), r.URL.Path)
+		return
+	}
+	settings, err := readBucketSettings(bucket, objAPI)
+	if err != nil {
+		switch err.(type) {
+		case BucketSettingsNotFound:
+			writeErrorResponse(w, r, ErrNoSuchBucketSettings, r.URL.Path)
+		default:
+			errorIf(err, "Unable to read bucket settings.")
+			writeErrorResponse(w, r, toAPIErrorCode(err), r.URL.Path)
+		}
+		return
+	}
+
+	settingsBytes, err := json.Marshal(settings)
+	if err != nil {
+		errorIf(err, "Unable to marshal bucket settings.")
+		writeErrorResponse(w, r, ErrInternalError, r.URL.Path)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	writeSuccessResponse(w, settingsBytes)
+}
@@ -0,0 +1,344 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"path"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// Bucket settings are stored as 'settings.json' in '.minio.sys/buckets/<bucket>/'.
+const bucketSettingsConfig = "settings.json"
+
+// errInvalidBucketSettings - bucket settings document has invalid values.
+var errInvalidBucketSettings = errors.New("Invalid bucket settings")
+
+// bucketSettings - per bucket defaults applied to uploaded objects
+// when the client doesn't provide them.
+type bucketSettings struct {
+	// Content-Type for objects by their file extension, e.g ".log" -> "text/plain".
+	ContentTypes map[string]string `json:"contentTypes,omitempty"`
+
+	// Cache-Control for all objects.
+	CacheControl string `json:"cacheControl,omitempty"`
+
+	// Detect Content-Type of uploaded objects which have none, from
+	// their file extension or otherwise from their leading bytes.
+	SniffContentType bool `json:"sniffContentType,omitempty"`
+
+	// Transformation webhook for GET requests of objects.
+	Transform *bucketTransform `json:"transform,omitempty"`
+
+	// Store object data as deduplicated chunks, can only be enabled
+	// on empty buckets.
+	Dedup bool `json:"dedup,omitempty"`
+
+	// Retention of objects, which are never overwritten nor deleted
+	// before it elapses.
+	Retention *bucketRetention `json:"retention,omitempty"`
+
+	// Index user metadata of objects for search queries.
+	Search bool `json:"search,omitempty"`
+
+	// Trash keeping deleted objects, which are undeleted until its
+	// retention elapses.
+	Trash *bucketTrash `json:"trash,omitempty"`
+
+	// Server side encryption of objects uploaded without asking for
+	// any, or denial of such uploads.
+	Encryption *bucketEncryption `json:"encryption,omitempty"`
+}
+
+// validate - validates all the settings, extensions are lower cased.
+func (s *bucketSettings) validate() error {
+	contentTypes := make(map[string]string, len(s.ContentTypes))
+	for ext, contentType := range s.ContentTypes {
+		if !strings.HasPrefix(ext, ".") || len(ext) < 2 || strings.Contains(ext, "/") {
+			return errInvalidBucketSettings
+		}
+		if _, _, err := mime.ParseMediaType(contentType); err != nil {
+			return errInvalidBucketSettings
+		}
+		contentTypes[strings.ToLower(ext)] = contentType
+	}
+	s.ContentTypes = contentTypes
+	if strings.ContainsAny(s.CacheControl, "\r\n") {
+		return errInvalidBucketSettings
+	}
+	if s.Transform != nil {
+		if err := s.Transform.validate(); err != nil {
+			return err
+		}
+	}
+	if s.Retention != nil {
+		if err := s.Retention.validate(); err != nil {
+			return err
+		}
+	}
+	if s.Trash != nil {
+		if err := s.Trash.validate(); err != nil {
+			return err
+		}
+	}
+	if s.Encryption != nil {
+		return s.Encryption.validate()
+	}
+	return nil
+}
+
+// isEmpty - returns true if no setting is set.
+func (s bucketSettings) isEmpty() bool {
+	if len(s.ContentTypes) != 0 {
+		return false
+	}
+	s.ContentTypes = nil
+	return reflect.DeepEqual(s, bucketSettings{})
+}
+
+// applyDefaults - applies all the defaults to the metadata of object,
+// metadata provided by the client is never overwritten.
+func (s *bucketSettings) applyDefaults(object string, metadata map[string]string) {
+	if metadata["content-type"] == "" {
+		if contentType, ok := s.ContentTypes[strings.ToLower(path.Ext(object))]; ok {
+			metadata["content-type"] = contentType
+		}
+	}
+	if metadata["cache-control"] == "" && s.CacheControl != "" {
+		metadata["cache-control"] = s.CacheControl
+	}
+	if s.Encryption != nil {
+		s.Encryption.applyDefault(metadata)
+	}
+}
+
+// Variable represents bucket settings in memory.
+var globalBucketSettings = &bucketSettingsMap{
+	rwMutex:  &sync.RWMutex{},
+	settings: make(map[string]*bucketSettings),
+}
+
+// Collection of settings of all the buckets.
+type bucketSettingsMap struct {
+	rwMutex  *sync.RWMutex
+	settings map[string]*bucketSettings
+}
+
+// Fetch bucket settings for a given bucket.
+func (bs *bucketSettingsMap) GetBucketSettings(bucket string) *bucketSettings {
+	bs.rwMutex.RLock()
+	defer bs.rwMutex.RUnlock()
+	return bs.settings[bucket]
+}
+
+// Set new bucket settings for a bucket, nil removes the settings.
+func (bs *bucketSettingsMap) SetBucketSettings(bucket string, settings *bucketSettings) {
+	bs.rwMutex.Lock()
+	defer bs.rwMutex.Unlock()
+	if settings == nil {
+		delete(bs.settings, bucket)
+		return
+	}
+	bs.settings[bucket] = settings
+}
+
+// applyBucketDefaults - applies the defaults configured for bucket
+// to the metadata of a new object.
+func applyBucketDefaults(bucket, object string, metadata map[string]string) {
+	if settings := globalBucketSettings.GetBucketSettings(bucket); settings != nil {
+		settings.applyDefaults(object, metadata)
+	}
+}
+
+// Number of leading bytes of data considered by http.DetectContentType.
+const sniffLen = 512
+
+// sniffContentType - sets the content-type of a new object of bucket
+// which has none after the bucket defaults were applied, if sniffing
+// is enabled for the bucket. The object extension is looked up first
+// and otherwise the leading bytes of data are sniffed, the returned
+// reader yields all of data.
+func sniffContentType(bucket, object string, metadata map[string]string, data io.Reader) (io.Reader, error) {
+	if metadata["content-type"] != "" {
+		return data, nil
+	}
+	settings := globalBucketSettings.GetBucketSettings(bucket)
+	if settings == nil || !settings.SniffContentType {
+		return data, nil
+	}
+	if contentType := guessContentType(object); contentType != "" {
+		metadata["content-type"] = contentType
+		return data, nil
+	}
+	head := make([]byte, sniffLen)
+	n, err := io.ReadFull(data, head)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return nil, err
+	}
+	head = head[:n]
+	// Nothing to sniff for empty objects.
+	if n > 0 {
+		metadata["content-type"] = http.DetectContentType(head)
+	}
+	return io.MultiReader(bytes.NewReader(head), data), nil
+}
+
+// readBucketSettings - reads bucket settings for an input bucket,
+// returns BucketSettingsNotFound if bucket settings are not found.
+func readBucketSettings(bucket string, objAPI ObjectLayer) (*bucketSettings, error) {
+	settingsPath := path.Join(bucketConfigPrefix, bucket, bucketSettingsConfig)
+	objInfo, err := objAPI.GetObjectInfo(minioMetaBucket, settingsPath)
+	err = errorCause(err)
+	if err != nil {
+		if _, ok := err.(ObjectNotFound); ok {
+			return nil, BucketSettingsNotFound{Bucket: bucket}
+		}
+		errorIf(err, "Unable to load settings for the bucket %s.", bucket)
+		return nil, err
+	}
+	var buffer bytes.Buffer
+	err = objAPI.GetObject(minioMetaBucket, settingsPath, 0, objInfo.Size, &buffer)
+	err = errorCause(err)
+	if err != nil {
+		if _, ok := err.(ObjectNotFound); ok {
+			return nil, BucketSettingsNotFound{Bucket: bucket}
+		}
+		errorIf(err, "Unable to load settings for the bucket %s.", bucket)
+		return nil, err
+	}
+	return parseBucketSettings(&buffer)
+}
+
+// parseBucketSettings - parses and validates bucket settings.
+func parseBucketSettings(reader io.Reader) (*bucketSettings, error) {
+	settings := &bucketSettings{}
+	if err := json.NewDecoder(reader).Decode(settings); err != nil {
+		return nil, errInvalidBucketSettings
+	}
+	if err := settings.validate(); err != nil {
+		return nil, err
+	}
+	return settings, nil
+}
+
+// writeBucketSettings - saves bucket settings that are assumed to be validated.
+func writeBucketSettings(bucket string, objAPI ObjectLayer, settings *bucketSettings) error {
+	buf, err := json.Marshal(settings)
+	if err != nil {
+		return err
+	}
+	settingsPath := path.Join(bucketConfigPrefix, bucket, bucketSettingsConfig)
+	if _, err = objAPI.PutObject(minioMetaBucket, settingsPath, int64(len(buf)), bytes.NewReader(buf), nil, ""); err != nil {
+		errorIf(err, "Unable to set settings for the bucket %s", bucket)
+		return errorCause(err)
+	}
+	return nil
+}
+
+// removeBucketSettings - removes previously written bucket settings,
+// returns BucketSettingsNotFound if no settings are found.
+func removeBucketSettings(bucket string, objAPI ObjectLayer) error {
+	settingsPath := path.Join(bucketConfigPrefix, bucket, bucketSettingsConfig)
+	if err := objAPI.DeleteObject(minioMetaBucket, settingsPath); err != nil {
+		err = errorCause(err)
+		if _, ok := err.(ObjectNotFound); ok {
+			return BucketSettingsNotFound{Bucket: bucket}
+		}
+		errorIf(err, "Unable to remove settings on bucket %s.", bucket)
+		return err
+	}
+	return nil
+}
+
+// persistAndNotifyBucketSettingsChange - persists bucket settings
+// and notifies all the nodes in the cluster about the change, nil
+// settings removes the bucket settings.
+func persistAndNotifyBucketSettingsChange(bucket string, settings *bucketSettings, objAPI ObjectLayer) error {
+	if err := isBucketExist(bucket, objAPI); err != nil {
+		return err
+	}
+	if err := checkDedupSettingsChange(bucket, settings, objAPI); err != nil {
+		return err
+	}
+	if err := checkRetentionSettingsChange(bucket, settings); err != nil {
+		return err
+	}
+	if err := checkEncryptionSettingsChange(settings); err != nil {
+		return err
+	}
+	wasSearch := isSearchBucket(bucket)
+	if settings == nil {
+		if err := removeBucketSettings(bucket, objAPI); err != nil {
+			return err
+		}
+	} else if err := writeBucketSettings(bucket, objAPI, settings); err != nil {
+		return err
+	}
+
+	// Notify all peers (including self) to update in-memory state
+	S3PeersUpdateBucketSettings(bucket, settings)
+
+	// Index objects uploaded before search was enabled, or remove
+	// the index once disabled.
+	isSearch := settings != nil && settings.Search
+	if isSearch && !wasSearch {
+		go func() {
+			errorIf(indexBucket(objAPI, bucket), "Unable to index bucket %s.", bucket)
+		}()
+	} else if wasSearch && !isSearch {
+		go func() {
+			errorIf(removeBucketIndex(objAPI, bucket), "Unable to remove search index of bucket %s.", bucket)
+		}()
+	}
+	return nil
+}
+
+// Intialize settings of all the buckets.
+func initBucketSettings(objAPI ObjectLayer) error {
+	if objAPI == nil {
+		return errInvalidArgument
+	}
+	buckets, err := objAPI.ListBuckets()
+	if err != nil {
+		return errorCause(err)
+	}
+	settingsMap := make(map[string]*bucketSettings)
+	for _, bucket := range buckets {
+		settings, sErr := readBucketSettings(bucket.Name, objAPI)
+		if sErr != nil {
+			if _, ok := sErr.(BucketSettingsNotFound); ok {
+				continue
+			}
+			return sErr
+		}
+		settingsMap[bucket.Name] = settings
+	}
+
+	// Populate global bucket settings.
+	globalBucketSettings = &bucketSettingsMap{
+		rwMutex:  &sync.RWMutex{},
+		settings: settingsMap,
+	}
+	return nil
+}
@@ -0,0 +1,264 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// Tests validation of bucket settings.
+func TestParseBucketSettings(t *testing.T) {
+	testCases := []struct {
+		settings string
+		expected *bucketSettings
+		err      error
+	}{
+		{`{"contentTypes":{".LOG":"text/plain"},"cacheControl":"max-age=3600"}`, &bucketSettings{
+			ContentTypes: map[string]string{".log": "text/plain"},
+			CacheControl: "max-age=3600",
+		}, nil},
+		{`{"cacheControl":"no-cache"}`, &bucketSettings{
+			ContentTypes: map[string]string{},
+			CacheControl: "no-cache",
+		}, nil},
+		{`{"transform":{"endpoint":"https://transform.example.com/hook","prefixes":["images/"]}}`, &bucketSettings{
+			ContentTypes: map[string]string{},
+			Transform:    &bucketTransform{Endpoint: "https://transform.example.com/hook", Prefixes: []string{"images/"}},
+		}, nil},
+		{`{"sniffContentType":true}`, &bucketSettings{
+			ContentTypes:     map[string]string{},
+			SniffContentType: true,
+		}, nil},
+		{`{"retention":{"days":365}}`, &bucketSettings{
+			ContentTypes: map[string]string{},
+			Retention:    &bucketRetention{Days: 365},
+		}, nil},
+		{`{"trash":{"days":30}}`, &bucketSettings{
+			ContentTypes: map[string]string{},
+			Trash:        &bucketTrash{Days: 30},
+		}, nil},
+		// Retention shorter than a day.
+		{`{"retention":{"days":0}}`, nil, errInvalidBucketSettings},
+		// Trash retention longer than a year.
+		{`{"trash":{"days":366}}`, nil, errInvalidBucketSettings},
+		// Transformation webhook without scheme.
+		{`{"transform":{"endpoint":"transform.example.com/hook"}}`, nil, errInvalidBucketSettings},
+		// Extension without leading dot.
+		{`{"contentTypes":{"log":"text/plain"}}`, nil, errInvalidBucketSettings},
+		// Invalid media type.
+		{`{"contentTypes":{".log":"text plain;;"}}`, nil, errInvalidBucketSettings},
+		// Header injection.
+		{`{"cacheControl":"no-cache\r\nX-Injected: 1"}`, nil, errInvalidBucketSettings},
+		// Malformed JSON.
+		{`{"cacheControl":`, nil, errInvalidBucketSettings},
+	}
+	for i, testCase := range testCases {
+		settings, err := parseBucketSettings(strings.NewReader(testCase.settings))
+		if err != testCase.err {
+			t.Errorf("Test %d: Expected error %v, got %v", i+1, testCase.err, err)
+			continue
+		}
+		if !reflect.DeepEqual(settings, testCase.expected) {
+			t.Errorf("Test %d: Expected %#v, got %#v", i+1, testCase.expected, settings)
+		}
+	}
+}
+
+// Tests applying bucket defaults never overwrites client metadata.
+func TestBucketSettingsApplyDefaults(t *testing.T) {
+	settings := &bucketSettings{
+		ContentTypes: map[string]string{".log": "text/plain"},
+		CacheControl: "max-age=3600",
+	}
+	testCases := []struct {
+		object   string
+		metadata map[string]string
+		expected map[string]string
+	}{
+		{"a/b/c.LOG", map[string]string{}, map[string]string{
+			"content-type":  "text/plain",
+			"cache-control": "max-age=3600",
+		}},
+		{"c.txt", map[string]string{}, map[string]string{
+			"cache-control": "max-age=3600",
+		}},
+		{"c.log", map[string]string{"content-type": "application/json", "cache-control": "no-cache"}, map[string]string{
+			"content-type":  "application/json",
+			"cache-control": "no-cache",
+		}},
+	}
+	for i, testCase := range testCases {
+		settings.applyDefaults(testCase.object, testCase.metadata)
+		if !reflect.DeepEqual(testCase.metadata, testCase.expected) {
+			t.Errorf("Test %d: Expected %v, got %v", i+1, testCase.expected, testCase.metadata)
+		}
+	}
+}
+
+// Tests content-type detection of uploaded objects.
+func TestSniffContentType(t *testing.T) {
+	bucket := "sniff-bucket"
+	html := "<html><body>hello</body></html>"
+	testCases := []struct {
+		sniff       bool
+		object      string
+		data        string
+		contentType string
+		expected    string
+	}{
+		// Sniffing disabled.
+		{false, "index", html, "", ""},
+		// Content-type provided by the client.
+		{true, "index", html, "application/octet-stream", "application/octet-stream"},
+		// Extension is looked up first.
+		{true, "index.json", html, "", "application/json"},
+		{true, "index", html, "", "text/html; charset=utf-8"},
+		{true, "blob", "\x00\x01\x02", "", "application/octet-stream"},
+		// Empty object.
+		{true, "empty", "", "", ""},
+		// Only the leading bytes are sniffed.
+		{true, "large", html + strings.Repeat("x", 2*sniffLen), "", "text/html; charset=utf-8"},
+	}
+	defer globalBucketSettings.SetBucketSettings(bucket, nil)
+	for i, testCase := range testCases {
+		globalBucketSettings.SetBucketSettings(bucket, &bucketSettings{SniffContentType: testCase.sniff})
+		metadata := map[string]string{}
+		if testCase.contentType != "" {
+			metadata["content-type"] = testCase.contentType
+		}
+		reader, err := sniffContentType(bucket, testCase.object, metadata, strings.NewReader(testCase.data))
+		if err != nil {
+			t.Fatalf("Test %d: Unexpected error %s", i+1, err)
+		}
+		if metadata["content-type"] != testCase.expected {
+			t.Errorf("Test %d: Expected content-type %q, got %q", i+1, testCase.expected, metadata["content-type"])
+		}
+		data, err := ioutil.ReadAll(reader)
+		if err != nil {
+			t.Fatalf("Test %d: Unexpected error %s", i+1, err)
+		}
+		if string(data) != testCase.data {
+			t.Errorf("Test %d: Expected data %q, got %q", i+1, testCase.data, string(data))
+		}
+	}
+}
+
+// Wrapper for calling bucket settings HTTP handler tests for both XL multiple disks and single node setup.
+func TestBucketSettingsHandlers(t *testing.T) {
+	ExecObjectLayerAPITest(t, testBucketSettingsHandlers, []string{"PutBucketSettings", "GetBucketSettings", "DeleteBucketSettings", "PutObject"})
+}
+
+func testBucketSettingsHandlers(obj ObjectLayer, instanceType, bucketName string, apiRouter http.Handler,
+	credentials credential, t *testing.T) {
+	if err := initBucketSettings(obj); err != nil {
+		t.Fatalf("%s: Unable to initialize bucket settings: %s", instanceType, err)
+	}
+
+	doRequest := func(method, urlStr string, body []byte) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		req, err := newTestSignedRequestV4(method, urlStr, int64(len(body)), bytes.NewReader(body),
+			credentials.AccessKeyID, credentials.SecretAccessKey)
+		if err != nil {
+			t.Fatalf("%s: Failed to create HTTP request: <ERROR> %v", instanceType, err)
+		}
+		apiRouter.ServeHTTP(rec, req)
+		return rec
+	}
+
+	// No settings yet.
+	rec := doRequest("GET", getBucketSettingsURL("", bucketName), nil)
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("%s: Expected %d, got %d", instanceType, http.StatusNotFound, rec.Code)
+	}
+
+	// Invalid settings.
+	rec = doRequest("PUT", getBucketSettingsURL("", bucketName), []byte(`{"contentTypes":{"log":"text/plain"}}`))
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("%s: Expected %d, got %d", instanceType, http.StatusBadRequest, rec.Code)
+	}
+
+	settingsJSON := []byte(`{"contentTypes":{".log":"application/x-log"},"cacheControl":"max-age=3600","sniffContentType":true}`)
+	rec = doRequest("PUT", getBucketSettingsURL("", bucketName), settingsJSON)
+	if rec.Code != http.StatusNoContent {
+		t.Fatalf("%s: Expected %d, got %d", instanceType, http.StatusNoContent, rec.Code)
+	}
+	// Peers are not initialized in tests, update in-memory state.
+	settings, err := readBucketSettings(bucketName, obj)
+	if err != nil {
+		t.Fatalf("%s: Unable to read bucket settings: %s", instanceType, err)
+	}
+	globalBucketSettings.SetBucketSettings(bucketName, settings)
+
+	rec = doRequest("GET", getBucketSettingsURL("", bucketName), nil)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("%s: Expected %d, got %d", instanceType, http.StatusOK, rec.Code)
+	}
+	gotSettings := &bucketSettings{}
+	if err = json.Unmarshal(rec.Body.Bytes(), gotSettings); err != nil {
+		t.Fatalf("%s: Unable to parse bucket settings: %s", instanceType, err)
+	}
+	if !reflect.DeepEqual(gotSettings, settings) {
+		t.Fatalf("%s: Expected %#v, got %#v", instanceType, settings, gotSettings)
+	}
+
+	// Upload an object without content-type, defaults should be applied.
+	rec = doRequest("PUT", getPutObjectURL("", bucketName, "app.log"), []byte("hello"))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("%s: Expected %d, got %d", instanceType, http.StatusOK, rec.Code)
+	}
+	objInfo, err := obj.GetObjectInfo(bucketName, "app.log")
+	if err != nil {
+		t.Fatalf("%s: Unable to get object info: %s", instanceType, err)
+	}
+	if objInfo.ContentType != "application/x-log" {
+		t.Errorf("%s: Expected content-type application/x-log, got %s", instanceType, objInfo.ContentType)
+	}
+	if objInfo.UserDefined["cache-control"] != "max-age=3600" {
+		t.Errorf("%s: Expected cache-control max-age=3600, got %s", instanceType, objInfo.UserDefined["cache-control"])
+	}
+
+	// Upload an object without content-type nor extension, its
+	// content-type should be sniffed.
+	rec = doRequest("PUT", getPutObjectURL("", bucketName, "index"), []byte("<html><body>hello</body></html>"))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("%s: Expected %d, got %d", instanceType, http.StatusOK, rec.Code)
+	}
+	objInfo, err = obj.GetObjectInfo(bucketName, "index")
+	if err != nil {
+		t.Fatalf("%s: Unable to get object info: %s", instanceType, err)
+	}
+	if objInfo.ContentType != "text/html; charset=utf-8" {
+		t.Errorf("%s: Expected content-type text/html; charset=utf-8, got %s", instanceType, objInfo.ContentType)
+	}
+
+	rec = doRequest("DELETE", getBucketSettingsURL("", bucketName), nil)
+	if rec.Code != http.StatusNoContent {
+		t.Fatalf("%s: Expected %d, got %d", instanceType, http.StatusNoContent, rec.Code)
+	}
+	rec = doRequest("DELETE", getBucketSettingsURL("", bucketName), nil)
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("%s: Expected %d, got %d", instanceType, http.StatusNotFound, rec.Code)
+	}
+	globalBucketSettings.SetBucketSettings(bucketName, nil)
+}
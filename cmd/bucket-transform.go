@@ -0,0 +1,214 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// Query parameter of the URLs given to transformation webhooks,
+	// carries a one-time token returning the untransformed object.
+	transformBypassQuery = "x-minio-transform"
+
+	// Expiry of the bypass tokens given to transformation webhooks.
+	transformBypassExpiry = 15 * time.Minute
+
+	// Time to wait for a transformation webhook to send its response
+	// headers.
+	transformTimeout = 30 * time.Second
+)
+
+// errTransformFailed - transformation webhook didn't return 200 OK.
+var errTransformFailed = errors.New("Transformation webhook failed")
+
+// Response headers of transformation webhooks passed to the client.
+var transformResponseHeaders = []string{
+	"Content-Type",
+	"Content-Length",
+	"Content-Disposition",
+	"Content-Encoding",
+	"Content-Language",
+	"Cache-Control",
+	"Expires",
+}
+
+// HTTP client for transformation webhooks.
+var transformClient = &http.Client{
+	Transport: &outboundTransport{responseHeaderTimeout: transformTimeout},
+}
+
+// bucketTransform - transformation webhook of a bucket, GET requests
+// of objects matching any of Prefixes, or all objects if there are
+// no prefixes, are answered by Endpoint.
+type bucketTransform struct {
+	Endpoint string   `json:"endpoint"`
+	Prefixes []string `json:"prefixes,omitempty"`
+}
+
+// transformRequest - JSON body POSTed to transformation webhooks, URL
+// fetches the object to be transformed, only once.
+type transformRequest struct {
+	Bucket string `json:"bucket"`
+	Object string `json:"object"`
+	URL    string `json:"url"`
+}
+
+// validate - the endpoint must be an absolute http or https URL.
+func (t *bucketTransform) validate() error {
+	u, err := url.Parse(t.Endpoint)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return errInvalidBucketSettings
+	}
+	return nil
+}
+
+// matches - returns true if object is transformed by the webhook.
+func (t *bucketTransform) matches(object string) bool {
+	if len(t.Prefixes) == 0 {
+		return true
+	}
+	for _, prefix := range t.Prefixes {
+		if strings.HasPrefix(object, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// getBucketTransform - returns the transformation webhook for a GET
+// request of object, nil if the object is served as is.
+func getBucketTransform(bucket, object string) *bucketTransform {
+	settings := globalBucketSettings.GetBucketSettings(bucket)
+	if settings == nil || settings.Transform == nil || !settings.Transform.matches(object) {
+		return nil
+	}
+	return settings.Transform
+}
+
+// transformBypass - object a bypass token was issued for.
+type transformBypass struct {
+	bucket string
+	object string
+	expiry time.Time
+}
+
+// transformBypassTokens - one-time tokens given to transformation
+// webhooks in place of credentials, each lets a single GET request
+// of the object fetch it untransformed.
+type transformBypassTokens struct {
+	mutex     sync.Mutex
+	tokens    map[string]transformBypass
+	lastPurge time.Time
+}
+
+// Bypass tokens issued by this node, webhooks are always given the
+// endpoint of the node issuing the token.
+var globalTransformBypassTokens = &transformBypassTokens{tokens: make(map[string]transformBypass)}
+
+// issue - returns a new token for object valid for expiry. Tokens
+// which expired unused are purged at most once per expiry.
+func (t *transformBypassTokens) issue(bucket, object string, expiry time.Duration) string {
+	token := getUUID()
+	now := time.Now().UTC()
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	if now.Sub(t.lastPurge) >= expiry {
+		for oldToken, bypass := range t.tokens {
+			if now.After(bypass.expiry) {
+				delete(t.tokens, oldToken)
+			}
+		}
+		t.lastPurge = now
+	}
+	t.tokens[token] = transformBypass{bucket, object, now.Add(expiry)}
+	return token
+}
+
+// redeem - returns true if token was issued for object and has not
+// expired, the token can not be redeemed again.
+func (t *transformBypassTokens) redeem(token, bucket, object string) bool {
+	if token == "" {
+		return false
+	}
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	bypass, ok := t.tokens[token]
+	if !ok || bypass.bucket != bucket || bypass.object != object {
+		return false
+	}
+	delete(t.tokens, token)
+	return time.Now().UTC().Before(bypass.expiry)
+}
+
+// isTransformBypassed - returns true if the GET request of object
+// carries a valid bypass token, i.e it is made by the transformation
+// webhook and is served the untransformed object without credentials.
+func isTransformBypassed(r *http.Request, bucket, object string) bool {
+	return globalTransformBypassTokens.redeem(r.URL.Query().Get(transformBypassQuery), bucket, object)
+}
+
+// transformObject - answers a GET request of object with the response
+// of the transformation webhook, which fetches the object with a
+// one-time bypass token from the endpoint of this node. The endpoint
+// is never taken from the request, which anonymous clients control.
+func transformObject(w http.ResponseWriter, r *http.Request, transform *bucketTransform, bucket, object string) {
+	query := url.Values{}
+	query.Set(transformBypassQuery, globalTransformBypassTokens.issue(bucket, object, transformBypassExpiry))
+
+	body, err := json.Marshal(transformRequest{
+		Bucket: bucket,
+		Object: object,
+		URL:    globalMinioEndpoint + getURLEncodedName("/"+bucket+"/"+object) + "?" + query.Encode(),
+	})
+	if err != nil {
+		errorIf(err, "Unable to marshal transformation request.")
+		writeErrorResponse(w, r, ErrInternalError, r.URL.Path)
+		return
+	}
+
+	resp, err := transformClient.Post(transform.Endpoint, "application/json", bytes.NewReader(body))
+	if err != nil {
+		errorIf(err, "Unable to reach transformation webhook %s.", transform.Endpoint)
+		writeErrorResponse(w, r, ErrTransformFailed, r.URL.Path)
+		return
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		errorIf(errTransformFailed, "Transformation webhook %s returned %s.", transform.Endpoint, resp.Status)
+		writeErrorResponse(w, r, ErrTransformFailed, r.URL.Path)
+		return
+	}
+
+	for _, header := range transformResponseHeaders {
+		if value := resp.Header.Get(header); value != "" {
+			w.Header().Set(header, value)
+		}
+	}
+	w.WriteHeader(http.StatusOK)
+	if _, err = io.Copy(w, resp.Body); err != nil {
+		errorIf(err, "Unable to write transformed object to client.")
+	}
+}
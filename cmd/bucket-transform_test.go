@@ -0,0 +1,216 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Tests matching objects with transformation webhook prefixes.
+func TestBucketTransformMatches(t *testing.T) {
+	testCases := []struct {
+		prefixes []string
+		object   string
+		matches  bool
+	}{
+		{nil, "a/b.jpg", true},
+		{[]string{"images/"}, "images/b.jpg", true},
+		{[]string{"images/", "docs/"}, "docs/c.pdf", true},
+		{[]string{"images/"}, "docs/c.pdf", false},
+	}
+	for i, testCase := range testCases {
+		transform := &bucketTransform{Endpoint: "http://localhost:8080", Prefixes: testCase.prefixes}
+		if matches := transform.matches(testCase.object); matches != testCase.matches {
+			t.Errorf("Test %d: Expected %t, got %t", i+1, testCase.matches, matches)
+		}
+	}
+}
+
+// Tests bypass tokens are valid once, for their object only.
+func TestTransformBypassTokens(t *testing.T) {
+	tokens := &transformBypassTokens{tokens: make(map[string]transformBypass)}
+	token := tokens.issue("bucket", "object", time.Minute)
+	if tokens.redeem(token, "bucket", "other-object") {
+		t.Error("Expected token not to be valid for another object")
+	}
+	if !tokens.redeem(token, "bucket", "object") {
+		t.Error("Expected token to be valid")
+	}
+	if tokens.redeem(token, "bucket", "object") {
+		t.Error("Expected token not to be valid twice")
+	}
+	if tokens.redeem("", "bucket", "object") {
+		t.Error("Expected empty token not to be valid")
+	}
+
+	// Expired tokens are not valid and purged once others are issued.
+	expired := tokens.issue("bucket", "object", -time.Minute)
+	if tokens.redeem(expired, "bucket", "object") {
+		t.Error("Expected expired token not to be valid")
+	}
+	tokens.issue("bucket", "object", -time.Minute)
+	tokens.lastPurge = time.Time{}
+	tokens.issue("bucket", "object", time.Minute)
+	if len(tokens.tokens) != 1 {
+		t.Errorf("Expected expired tokens to be purged, got %d tokens", len(tokens.tokens))
+	}
+}
+
+// Tests GET requests answered by a transformation webhook, which
+// fetches the object with the bypass token it receives.
+func TestGetObjectTransform(t *testing.T) {
+	testServer := StartTestServer(t, "FS")
+	defer testServer.Stop()
+	defer globalBucketSettings.SetBucketSettings("bucket", nil)
+
+	savedEndpoint := globalMinioEndpoint
+	globalMinioEndpoint = testServer.Server.URL
+	defer func() { globalMinioEndpoint = savedEndpoint }()
+
+	if err := testServer.Obj.MakeBucket("bucket"); err != nil {
+		t.Fatal(err)
+	}
+	for _, object := range []string{"upper/hello world.txt", "plain.txt"} {
+		if _, err := testServer.Obj.PutObject("bucket", object, 5, strings.NewReader("hello"), nil, ""); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Webhook returning the source object upper cased.
+	var lastURL string
+	webhook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req transformRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		lastURL = req.URL
+		resp, err := http.Get(req.URL)
+		if err != nil {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		defer resp.Body.Close()
+		data, _ := ioutil.ReadAll(resp.Body)
+		if resp.StatusCode != http.StatusOK {
+			w.WriteHeader(resp.StatusCode)
+			return
+		}
+		w.Header().Set("Content-Type", "text/plain")
+		w.Header().Set("X-Webhook-Internal", "1")
+		w.Write(bytes.ToUpper(data))
+	}))
+	defer webhook.Close()
+
+	globalBucketSettings.SetBucketSettings("bucket", &bucketSettings{
+		Transform: &bucketTransform{Endpoint: webhook.URL, Prefixes: []string{"upper/"}},
+	})
+
+	testCases := []struct {
+		object       string
+		expectedBody string
+	}{
+		{"upper/hello world.txt", "HELLO"},
+		// Objects not matching the prefixes are not transformed.
+		{"plain.txt", "hello"},
+	}
+	for i, testCase := range testCases {
+		req, err := newTestSignedRequestV4("GET", getGetObjectURL(testServer.Server.URL, "bucket", testCase.object), 0, nil, testServer.AccessKey, testServer.SecretKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, _ := ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("Test %d: Expected %d, got %d", i+1, http.StatusOK, resp.StatusCode)
+		}
+		if string(body) != testCase.expectedBody {
+			t.Errorf("Test %d: Expected %s, got %s", i+1, testCase.expectedBody, body)
+		}
+		if resp.Header.Get("X-Webhook-Internal") != "" {
+			t.Errorf("Test %d: Expected webhook headers not to be passed", i+1)
+		}
+	}
+
+	// Anonymous requests can't skip the transformation.
+	policy := `{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Principal":{"AWS":["*"]},"Action":["s3:GetObject"],"Resource":["arn:aws:s3:::bucket/*"]}]}`
+	bktPolicy := &bucketPolicy{}
+	if err := parseBucketPolicy(strings.NewReader(policy), bktPolicy); err != nil {
+		t.Fatal(err)
+	}
+	if err := globalBucketPolicies.SetBucketPolicy("bucket", policyChange{BktPolicy: bktPolicy}); err != nil {
+		t.Fatal(err)
+	}
+	defer globalBucketPolicies.SetBucketPolicy("bucket", policyChange{IsRemove: true})
+	anonReq, err := http.NewRequest("GET", getGetObjectURL(testServer.Server.URL, "bucket", "upper/hello world.txt")+"?"+transformBypassQuery+"=bypass", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Clients can not redirect the webhook.
+	anonReq.Host = "attacker.example.com"
+	resp, err := http.DefaultClient.Do(anonReq)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if string(body) != "HELLO" {
+		t.Errorf("Expected anonymous request to be transformed, got %s", body)
+	}
+	// Webhooks are given the endpoint of the server, never the host
+	// of the request.
+	if !strings.HasPrefix(lastURL, testServer.Server.URL+"/") {
+		t.Errorf("Expected webhook URL on %s, got %s", testServer.Server.URL, lastURL)
+	}
+
+	// Bypass tokens can not be replayed, the object is transformed.
+	resp, err = http.Get(lastURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ = ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if string(body) != "HELLO" {
+		t.Errorf("Expected replayed token to be transformed, got %s", body)
+	}
+
+	// Failing webhooks return XMinioTransformFailed.
+	webhook.Close()
+	req, err := newTestSignedRequestV4("GET", getGetObjectURL(testServer.Server.URL, "bucket", "upper/hello world.txt"), 0, nil, testServer.AccessKey, testServer.SecretKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err = http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadGateway {
+		t.Errorf("Expected %d, got %d", http.StatusBadGateway, resp.StatusCode)
+	}
+}
@@ -0,0 +1,115 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package cmd
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	mux "github.com/gorilla/mux"
+)
+
+// ListBucketTrashHandler - GET Bucket trash (minio extension)
+// -----------------
+// This operation uses the trash subresource to list the deleted
+// objects kept in the trash of a bucket, along with when they were
+// deleted and are purged.
+func (api objectAPIHandlers) ListBucketTrashHandler(w http.ResponseWriter, r *http.Request) {
+	objAPI := api.ObjectAPI()
+	if objAPI == nil {
+		writeErrorResponse(w, r, ErrServerNotInitialized, r.URL.Path)
+		return
+	}
+
+	// ListBucketTrash does not support bucket policies, use checkAuth to validate signature.
+	if s3Error := checkAuth(r); s3Error != ErrNone {
+		errorIf(errSignatureMismatch, dumpRequest(r))
+		writeErrorResponse(w, r, s3Error, r.URL.Path)
+		return
+	}
+
+	vars := mux.Vars(r)
+	bucket := vars["bucket"]
+
+	if err := isBucketExist(bucket, objAPI); err != nil {
+		writeErrorResponse(w, r, toAPIErrorCode(err), r.URL.Path)
+		return
+	}
+
+	values := r.URL.Query()
+	maxKeys := maxObjectList
+	if values.Get("max-keys") != "" {
+		var err error
+		if maxKeys, err = strconv.Atoi(values.Get("max-keys")); err != nil || maxKeys < 0 {
+			writeErrorResponse(w, r, ErrInvalidMaxKeys, r.URL.Path)
+			return
+		}
+	}
+
+	result, err := listTrash(objAPI, bucket, values.Get("prefix"), values.Get("marker"), maxKeys)
+	if err != nil {
+		errorIf(err, "Unable to list trash of bucket %s.", bucket)
+		writeErrorResponse(w, r, toAPIErrorCode(err), r.URL.Path)
+		return
+	}
+
+	resultBytes, err := json.Marshal(result)
+	if err != nil {
+		errorIf(err, "Unable to marshal trash listing.")
+		writeErrorResponse(w, r, ErrInternalError, r.URL.Path)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	writeSuccessResponse(w, resultBytes)
+}
+
+// UndeleteObjectHandler - POST Object undelete (minio extension)
+// -----------------
+// This operation uses the undelete subresource to restore the last
+// deleted version of an object from the trash of its bucket.
+func (api objectAPIHandlers) UndeleteObjectHandler(w http.ResponseWriter, r *http.Request) {
+	objAPI := api.ObjectAPI()
+	if objAPI == nil {
+		writeErrorResponse(w, r, ErrServerNotInitialized, r.URL.Path)
+		return
+	}
+
+	// UndeleteObject does not support bucket policies, use checkAuth to validate signature.
+	if s3Error := checkAuth(r); s3Error != ErrNone {
+		errorIf(errSignatureMismatch, dumpRequest(r))
+		writeErrorResponse(w, r, s3Error, r.URL.Path)
+		return
+	}
+
+	vars := mux.Vars(r)
+	bucket := vars["bucket"]
+	object := vars["object"]
+
+	if err := isBucketExist(bucket, objAPI); err != nil {
+		writeErrorResponse(w, r, toAPIErrorCode(err), r.URL.Path)
+		return
+	}
+
+	if err := undeleteObject(objAPI, bucket, object); err != nil {
+		errorIf(err, "Unable to undelete object %s.", object)
+		writeErrorResponse(w, r, toAPIErrorCode(err), r.URL.Path)
+		return
+	}
+
+	// Success.
+	writeSuccessNoContent(w)
+}
@@ -0,0 +1,298 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package cmd
+
+import (
+	"io"
+	"path"
+	"strings"
+	"time"
+)
+
+// Objects deleted from buckets with a trash in their settings are
+// moved to '.minio.sys/trash/<bucket>/objects/<object>', from where
+// they are undeleted until their trash retention elapses. Only the
+// last deletion of an object is kept.
+const (
+	trashPrefix        = "trash"
+	trashObjectsPrefix = "objects"
+	trashLocksPrefix   = "locks"
+
+	// Maximum trash retention, 1 year.
+	maxTrashDays = 365
+
+	// Interval at which expired objects are purged from the trash.
+	trashPurgeInterval = 1 * time.Hour
+)
+
+// bucketTrash - retention of the deleted objects of a bucket.
+type bucketTrash struct {
+	Days int `json:"days"`
+}
+
+// validate - trash retention must be between 1 day and maxTrashDays.
+func (t *bucketTrash) validate() error {
+	if t.Days < 1 || t.Days > maxTrashDays {
+		return errInvalidBucketSettings
+	}
+	return nil
+}
+
+// expiry - returns when an object deleted at deletedAt is purged.
+func (t *bucketTrash) expiry(deletedAt time.Time) time.Time {
+	return deletedAt.Add(time.Duration(t.Days) * 24 * time.Hour)
+}
+
+// getBucketTrash - returns the trash of bucket, nil if deleted objects
+// of the bucket are removed right away.
+func getBucketTrash(bucket string) *bucketTrash {
+	settings := globalBucketSettings.GetBucketSettings(bucket)
+	if settings == nil {
+		return nil
+	}
+	return settings.Trash
+}
+
+// Deleted objects are stored in the meta bucket.
+func trashObjectPath(bucket, object string) string {
+	return path.Join(trashPrefix, bucket, trashObjectsPrefix, object)
+}
+
+// Objects are locked by a path other than the ones written, which are
+// locked by PutObject.
+func trashLockPath(bucket, object string) string {
+	return path.Join(trashPrefix, bucket, trashLocksPrefix, object)
+}
+
+// copyObject - copies an object along with its user metadata.
+func copyObject(objAPI ObjectLayer, srcBucket, srcObject, dstBucket, dstObject string) error {
+	objInfo, err := objAPI.GetObjectInfo(srcBucket, srcObject)
+	if err != nil {
+		return err
+	}
+	metadata := make(map[string]string)
+	for k, v := range objInfo.UserDefined {
+		metadata[k] = v
+	}
+	// The MD5 is computed again by PutObject.
+	delete(metadata, "md5Sum")
+
+	pipeReader, pipeWriter := io.Pipe()
+	go func() {
+		pipeWriter.CloseWithError(objAPI.GetObject(srcBucket, srcObject, 0, objInfo.Size, pipeWriter))
+	}()
+	_, err = objAPI.PutObject(dstBucket, dstObject, objInfo.Size, pipeReader, metadata, "")
+	pipeReader.Close()
+	return err
+}
+
+// trashObjects - object layer moving deleted objects of buckets with a
+// trash to the trash, all other buckets are served by the underlying
+// object layer.
+type trashObjects struct {
+	ObjectLayer
+}
+
+// newTrashObjects - returns objAPI keeping deleted objects in the
+// trash of their bucket.
+func newTrashObjects(objAPI ObjectLayer) ObjectLayer {
+	return trashObjects{objAPI}
+}
+
+// DeleteObject - deletes an object, objects of buckets with a trash
+// are moved to the trash first. The object is kept if it cannot be
+// moved.
+func (t trashObjects) DeleteObject(bucket, object string) error {
+	if getBucketTrash(bucket) == nil {
+		return t.ObjectLayer.DeleteObject(bucket, object)
+	}
+
+	opsID := getOpsID()
+	lockPath := trashLockPath(bucket, object)
+	nsMutex.Lock(minioMetaBucket, lockPath, opsID)
+	defer nsMutex.Unlock(minioMetaBucket, lockPath, opsID)
+
+	if err := copyObject(t.ObjectLayer, bucket, object, minioMetaBucket, trashObjectPath(bucket, object)); err != nil {
+		return err
+	}
+	return t.ObjectLayer.DeleteObject(bucket, object)
+}
+
+// undeleteObject - restores the last deleted version of an object
+// from the trash, returns ObjectAlreadyExists if the object was
+// created again since.
+func undeleteObject(objAPI ObjectLayer, bucket, object string) error {
+	opsID := getOpsID()
+	lockPath := trashLockPath(bucket, object)
+	nsMutex.Lock(minioMetaBucket, lockPath, opsID)
+	defer nsMutex.Unlock(minioMetaBucket, lockPath, opsID)
+
+	_, err := objAPI.GetObjectInfo(bucket, object)
+	if err == nil {
+		return traceError(ObjectAlreadyExists{Bucket: bucket, Object: object})
+	}
+	if _, ok := errorCause(err).(ObjectNotFound); !ok {
+		return err
+	}
+
+	trashPath := trashObjectPath(bucket, object)
+	if err = copyObject(objAPI, minioMetaBucket, trashPath, bucket, object); err != nil {
+		if _, ok := errorCause(err).(ObjectNotFound); ok {
+			return traceError(ObjectNotFound{Bucket: bucket, Object: object})
+		}
+		return err
+	}
+	return objAPI.DeleteObject(minioMetaBucket, trashPath)
+}
+
+// trashListObject - object in the trash of a bucket.
+type trashListObject struct {
+	Key       string    `json:"key"`
+	Size      int64     `json:"size"`
+	ETag      string    `json:"etag"`
+	DeletedAt time.Time `json:"deletedAt"`
+	ExpiresAt time.Time `json:"expiresAt,omitempty"`
+}
+
+// trashListResult - objects in the trash of a bucket, the listing is
+// continued by passing nextMarker as marker.
+type trashListResult struct {
+	Objects     []trashListObject `json:"objects"`
+	IsTruncated bool              `json:"isTruncated"`
+	NextMarker  string            `json:"nextMarker,omitempty"`
+}
+
+// listTrash - lists the objects in the trash of bucket whose name
+// starts with prefix, after marker.
+func listTrash(objAPI ObjectLayer, bucket, prefix, marker string, maxKeys int) (trashListResult, error) {
+	if maxKeys <= 0 || maxKeys > maxObjectList {
+		maxKeys = maxObjectList
+	}
+	objectsPrefix := trashObjectPath(bucket, "") + slashSeparator
+	if marker != "" {
+		marker = objectsPrefix + marker
+	}
+	result, err := objAPI.ListObjects(minioMetaBucket, objectsPrefix+prefix, marker, "", maxKeys)
+	if err != nil {
+		return trashListResult{}, err
+	}
+
+	trash := getBucketTrash(bucket)
+	listResult := trashListResult{
+		Objects:     []trashListObject{},
+		IsTruncated: result.IsTruncated,
+		NextMarker:  strings.TrimPrefix(result.NextMarker, objectsPrefix),
+	}
+	for _, objInfo := range result.Objects {
+		object := trashListObject{
+			Key:       strings.TrimPrefix(objInfo.Name, objectsPrefix),
+			Size:      objInfo.Size,
+			ETag:      objInfo.MD5Sum,
+			DeletedAt: objInfo.ModTime,
+		}
+		if trash != nil {
+			object.ExpiresAt = trash.expiry(objInfo.ModTime)
+		}
+		listResult.Objects = append(listResult.Objects, object)
+	}
+	return listResult, nil
+}
+
+// purgeTrash - deletes objects whose trash retention elapsed from the
+// trash of all buckets, including deleted ones. Objects of buckets
+// whose trash was disabled are all deleted. Returns the number of
+// objects deleted.
+func purgeTrash(objAPI ObjectLayer) (deleted int64, err error) {
+	var buckets []string
+	marker := ""
+	for {
+		result, err := objAPI.ListObjects(minioMetaBucket, trashPrefix+slashSeparator, marker, slashSeparator, maxObjectList)
+		if err != nil {
+			return deleted, err
+		}
+		for _, prefix := range result.Prefixes {
+			buckets = append(buckets, strings.TrimSuffix(strings.TrimPrefix(prefix, trashPrefix+slashSeparator), slashSeparator))
+		}
+		if !result.IsTruncated {
+			break
+		}
+		marker = result.NextMarker
+	}
+
+	for _, bucket := range buckets {
+		trash := getBucketTrash(bucket)
+		objectsPrefix := trashObjectPath(bucket, "") + slashSeparator
+		marker = ""
+		for {
+			result, err := objAPI.ListObjects(minioMetaBucket, objectsPrefix, marker, "", maxObjectList)
+			if err != nil {
+				return deleted, err
+			}
+			for _, objInfo := range result.Objects {
+				if trash != nil && time.Now().UTC().Before(trash.expiry(objInfo.ModTime)) {
+					continue
+				}
+				ok, dErr := purgeTrashObject(objAPI, bucket, strings.TrimPrefix(objInfo.Name, objectsPrefix), objInfo.ModTime)
+				if dErr != nil {
+					return deleted, dErr
+				}
+				if ok {
+					deleted++
+				}
+			}
+			if !result.IsTruncated {
+				break
+			}
+			marker = result.NextMarker
+		}
+	}
+	return deleted, nil
+}
+
+// purgeTrashObject - deletes an object from the trash unless it was
+// replaced by a later deletion since it was listed, returns true if
+// deleted.
+func purgeTrashObject(objAPI ObjectLayer, bucket, object string, deletedAt time.Time) (bool, error) {
+	opsID := getOpsID()
+	lockPath := trashLockPath(bucket, object)
+	nsMutex.Lock(minioMetaBucket, lockPath, opsID)
+	defer nsMutex.Unlock(minioMetaBucket, lockPath, opsID)
+
+	trashPath := trashObjectPath(bucket, object)
+	objInfo, err := objAPI.GetObjectInfo(minioMetaBucket, trashPath)
+	if err != nil {
+		if _, ok := errorCause(err).(ObjectNotFound); ok {
+			return false, nil
+		}
+		return false, err
+	}
+	if !objInfo.ModTime.Equal(deletedAt) {
+		return false, nil
+	}
+	if err = objAPI.DeleteObject(minioMetaBucket, trashPath); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// startTrashPurge - periodically purges expired objects from the trash.
+func startTrashPurge(objAPI ObjectLayer, interval time.Duration) {
+	globalTaskManager.Start(trashPurgeTask, interval, func() error {
+		_, err := purgeTrash(objAPI)
+		errorIf(err, "Unable to purge expired objects from the trash.")
+		return err
+	})
+}
@@ -0,0 +1,193 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// Tests validation of the trash retention.
+func TestBucketTrashValidate(t *testing.T) {
+	testCases := []struct {
+		days int
+		err  error
+	}{
+		{0, errInvalidBucketSettings},
+		{1, nil},
+		{maxTrashDays, nil},
+		{maxTrashDays + 1, errInvalidBucketSettings},
+	}
+	for i, testCase := range testCases {
+		trash := &bucketTrash{Days: testCase.days}
+		if err := trash.validate(); err != testCase.err {
+			t.Errorf("Test %d: Expected error %v, got %v", i+1, testCase.err, err)
+		}
+	}
+	deletedAt := time.Date(2016, 10, 1, 0, 0, 0, 0, time.UTC)
+	if expiry := (&bucketTrash{Days: 2}).expiry(deletedAt); !expiry.Equal(deletedAt.AddDate(0, 0, 2)) {
+		t.Errorf("Unexpected expiry %s", expiry)
+	}
+}
+
+// Wrapper for calling trash tests for both XL and FS.
+func TestTrashObjects(t *testing.T) {
+	ExecObjectLayerTest(t, testTrashObjects)
+}
+
+// Tests deleted objects of buckets with a trash are undeleted until
+// they are purged.
+func testTrashObjects(obj ObjectLayer, instanceType string, t TestErrHandler) {
+	bucket := "trash-bucket"
+	if err := obj.MakeBucket(bucket); err != nil {
+		t.Fatalf("%s: Unable to make bucket: %v", instanceType, err)
+	}
+	globalBucketSettings.SetBucketSettings(bucket, &bucketSettings{Trash: &bucketTrash{Days: 1}})
+	defer globalBucketSettings.SetBucketSettings(bucket, nil)
+	trashObj := newTrashObjects(obj)
+
+	data := []byte("hello")
+	metadata := map[string]string{"content-type": "text/plain"}
+	if _, err := trashObj.PutObject(bucket, "dir/object", int64(len(data)), bytes.NewReader(data), metadata, ""); err != nil {
+		t.Fatalf("%s: Unable to put object: %v", instanceType, err)
+	}
+	if err := trashObj.DeleteObject(bucket, "dir/object"); err != nil {
+		t.Fatalf("%s: Unable to delete object: %v", instanceType, err)
+	}
+	if _, err := obj.GetObjectInfo(bucket, "dir/object"); !isObjectNotFound(err) {
+		t.Fatalf("%s: Expected object to be deleted, got %v", instanceType, err)
+	}
+
+	result, err := listTrash(obj, bucket, "dir/", "", 0)
+	if err != nil {
+		t.Fatalf("%s: Unable to list trash: %v", instanceType, err)
+	}
+	if len(result.Objects) != 1 || result.Objects[0].Key != "dir/object" || result.Objects[0].Size != int64(len(data)) ||
+		!result.Objects[0].ExpiresAt.Equal(result.Objects[0].DeletedAt.Add(24*time.Hour)) {
+		t.Fatalf("%s: Unexpected trash listing %+v", instanceType, result)
+	}
+
+	// Undeleted objects have their data and metadata back.
+	if err = undeleteObject(obj, bucket, "dir/object"); err != nil {
+		t.Fatalf("%s: Unable to undelete object: %v", instanceType, err)
+	}
+	objInfo, err := obj.GetObjectInfo(bucket, "dir/object")
+	if err != nil || objInfo.ContentType != "text/plain" {
+		t.Fatalf("%s: Unexpected undeleted object %+v (%v)", instanceType, objInfo, err)
+	}
+	var buffer bytes.Buffer
+	if err = obj.GetObject(bucket, "dir/object", 0, objInfo.Size, &buffer); err != nil || !bytes.Equal(buffer.Bytes(), data) {
+		t.Fatalf("%s: Unexpected undeleted data %q (%v)", instanceType, buffer.Bytes(), err)
+	}
+	if err = undeleteObject(obj, bucket, "dir/object"); !isObjectAlreadyExists(err) {
+		t.Errorf("%s: Expected ObjectAlreadyExists undeleting existing object, got %v", instanceType, err)
+	}
+	if err = undeleteObject(obj, bucket, "missing"); !isObjectNotFound(err) {
+		t.Errorf("%s: Expected ObjectNotFound undeleting missing object, got %v", instanceType, err)
+	}
+
+	// Objects are only purged once expired, or once the trash is disabled.
+	if err = trashObj.DeleteObject(bucket, "dir/object"); err != nil {
+		t.Fatalf("%s: Unable to delete object: %v", instanceType, err)
+	}
+	if deleted, pErr := purgeTrash(obj); pErr != nil || deleted != 0 {
+		t.Errorf("%s: Expected nothing purged, got %d (%v)", instanceType, deleted, pErr)
+	}
+	globalBucketSettings.SetBucketSettings(bucket, nil)
+	if deleted, pErr := purgeTrash(obj); pErr != nil || deleted != 1 {
+		t.Errorf("%s: Expected 1 object purged, got %d (%v)", instanceType, deleted, pErr)
+	}
+	if err = undeleteObject(obj, bucket, "dir/object"); !isObjectNotFound(err) {
+		t.Errorf("%s: Expected ObjectNotFound undeleting purged object, got %v", instanceType, err)
+	}
+
+	// Without a trash, objects are deleted as usual.
+	if _, err = trashObj.PutObject(bucket, "object", int64(len(data)), bytes.NewReader(data), nil, ""); err != nil {
+		t.Fatalf("%s: Unable to put object: %v", instanceType, err)
+	}
+	if err = trashObj.DeleteObject(bucket, "object"); err != nil {
+		t.Fatalf("%s: Unable to delete object: %v", instanceType, err)
+	}
+	if result, err = listTrash(obj, bucket, "", "", 0); err != nil || len(result.Objects) != 0 {
+		t.Errorf("%s: Expected an empty trash, got %+v (%v)", instanceType, result, err)
+	}
+}
+
+// Wrapper for calling trash HTTP handler tests for both XL multiple disks and single node setup.
+func TestBucketTrashHandlers(t *testing.T) {
+	ExecObjectLayerAPITest(t, testBucketTrashHandlers, []string{"ListBucketTrash", "UndeleteObject"})
+}
+
+func testBucketTrashHandlers(obj ObjectLayer, instanceType, bucketName string, apiRouter http.Handler,
+	credentials credential, t *testing.T) {
+	doRequest := func(method, url string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		req, err := newTestSignedRequestV4(method, url, 0, nil, credentials.AccessKeyID, credentials.SecretAccessKey)
+		if err != nil {
+			t.Fatalf("%s: Failed to create HTTP request: <ERROR> %v", instanceType, err)
+		}
+		apiRouter.ServeHTTP(rec, req)
+		return rec
+	}
+
+	globalBucketSettings.SetBucketSettings(bucketName, &bucketSettings{Trash: &bucketTrash{Days: 7}})
+	defer globalBucketSettings.SetBucketSettings(bucketName, nil)
+	trashObj := newTrashObjects(obj)
+	if _, err := trashObj.PutObject(bucketName, "object", 5, bytes.NewReader([]byte("hello")), nil, ""); err != nil {
+		t.Fatalf("%s: Unable to put object: %v", instanceType, err)
+	}
+	if err := trashObj.DeleteObject(bucketName, "object"); err != nil {
+		t.Fatalf("%s: Unable to delete object: %v", instanceType, err)
+	}
+
+	rec := doRequest("GET", getListBucketTrashURL("", bucketName))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("%s: Expected %d, got %d", instanceType, http.StatusOK, rec.Code)
+	}
+	var result trashListResult
+	if err := json.Unmarshal(rec.Body.Bytes(), &result); err != nil {
+		t.Fatalf("%s: Unable to parse trash listing: %v", instanceType, err)
+	}
+	if len(result.Objects) != 1 || result.Objects[0].Key != "object" {
+		t.Errorf("%s: Unexpected trash listing %+v", instanceType, result)
+	}
+
+	if rec = doRequest("POST", getUndeleteObjectURL("", bucketName, "object")); rec.Code != http.StatusNoContent {
+		t.Fatalf("%s: Expected %d, got %d", instanceType, http.StatusNoContent, rec.Code)
+	}
+	if rec = doRequest("POST", getUndeleteObjectURL("", bucketName, "object")); rec.Code != http.StatusConflict {
+		t.Errorf("%s: Expected %d undeleting existing object, got %d", instanceType, http.StatusConflict, rec.Code)
+	}
+	if rec = doRequest("POST", getUndeleteObjectURL("", bucketName, "missing")); rec.Code != http.StatusNotFound {
+		t.Errorf("%s: Expected %d undeleting missing object, got %d", instanceType, http.StatusNotFound, rec.Code)
+	}
+}
+
+// isObjectAlreadyExists - returns true if err is ObjectAlreadyExists.
+func isObjectAlreadyExists(err error) bool {
+	_, ok := errorCause(err).(ObjectAlreadyExists)
+	return ok
+}
+
+// isObjectNotFound - returns true if err is ObjectNotFound.
+func isObjectNotFound(err error) bool {
+	_, ok := errorCause(err).(ObjectNotFound)
+	return ok
+}
@@ -30,4 +30,6 @@ var (
 	CommitID = "DEVELOPMENT.GOGET"
 	// ShortCommitID - first 12 characters from CommitID.
 	ShortCommitID = CommitID[:12]
+	// ReleasePublicKey - minisign public key release binaries are signed with.
+	ReleasePublicKey = ""
 )
@@ -0,0 +1,98 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package cmd
+
+import "github.com/mf-00/newgo/pkg/objcache"
+
+// objCacheStats - usage and counters of the object cache of a node.
+type objCacheStats struct {
+	objcache.Stats
+
+	// Ratio of lookups served from the cache.
+	HitRatio float64
+}
+
+// newObjCacheStats - computes the hit ratio of stats.
+func newObjCacheStats(stats objcache.Stats) objCacheStats {
+	cacheStats := objCacheStats{Stats: stats}
+	if lookups := stats.Hits + stats.Misses; lookups > 0 {
+		cacheStats.HitRatio = float64(stats.Hits) / float64(lookups)
+	}
+	return cacheStats
+}
+
+// CacheStatsArgs - argument for CacheStats RPC handler.
+type CacheStatsArgs struct {
+	// Authentication token generated by Login.
+	GenericArgs
+}
+
+// CacheStatsReply - reply by CacheStats RPC handler.
+type CacheStatsReply struct {
+	// Object cache statistics keyed by node.
+	Stats map[string]objCacheStats
+
+	// Errors of the nodes whose statistics are missing from Stats,
+	// keyed by node.
+	Errors map[string]string
+}
+
+// CacheStatsHandler - RPC control handler for `minio control
+// cache-stats`, returns the usage, hit ratio, evictions and
+// rejections of the object cache of this node, and of all the
+// reachable nodes if args.Remote is set.
+func (c *controlAPIHandlers) CacheStatsHandler(args *CacheStatsArgs, reply *CacheStatsReply) (err error) {
+	defer encodeRPCError(&err)
+
+	if !isRPCTokenValid(args.Token, jwtAudienceAdmin) {
+		return errInvalidToken
+	}
+	objAPI := c.ObjectAPI()
+	if objAPI == nil {
+		return errServerNotInitialized
+	}
+	stats, err := objAPI.CacheStats()
+	if err != nil {
+		return err
+	}
+
+	reply.Stats = map[string]objCacheStats{
+		c.LocalNode: newObjCacheStats(stats),
+	}
+	if args.Remote {
+		// Collect the cache statistics of all the remote peers.
+		remoteControls := c.getRemoteControls()
+		replies := make([]CacheStatsReply, len(remoteControls))
+		remoteArgs := *args
+		remoteArgs.Remote = false
+		errsMap := callRemoteControls(remoteControls, "Control.CacheStatsHandler", &remoteArgs, func(index int) interface{} {
+			return &replies[index]
+		})
+		for index, client := range remoteControls {
+			if nodeErr, ok := errsMap[client.Node()]; ok {
+				if reply.Errors == nil {
+					reply.Errors = make(map[string]string)
+				}
+				reply.Errors[client.Node()] = nodeErr.Error()
+				continue
+			}
+			for node, nodeStats := range replies[index].Stats {
+				reply.Stats[node] = nodeStats
+			}
+		}
+	}
+	return nil
+}
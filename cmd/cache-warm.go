@@ -0,0 +1,214 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"errors"
+	"io/ioutil"
+	"time"
+)
+
+// Maximum number of objects warmed by one call.
+const cacheWarmMaxKeys = 100
+
+// Highest rate which still leaves a non-zero interval between objects.
+const cacheWarmMaxRate = int(time.Second)
+
+// errObjCacheDisabled - the object cache is disabled on the node.
+var errObjCacheDisabled = errors.New("Object cache is disabled, set MINIO_CACHE_SIZE to enable it")
+
+// How much of an object is read to warm it.
+const (
+	// Reads the whole object, admitted objects are then served from
+	// the object cache.
+	cacheWarmObject = "object"
+	// Reads the first block of the object, to warm the disks only.
+	cacheWarmFirstBlock = "first-block"
+	// Reads the metadata of the object only.
+	cacheWarmMetadata = "metadata"
+)
+
+// CacheWarmArgs - arguments for CacheWarm RPC.
+type CacheWarmArgs struct {
+	// Authentication token generated by Login.
+	GenericArgs
+
+	// Bucket and prefix of the objects to warm.
+	Bucket string
+	Prefix string
+	// Position to continue at, NextMarker of the previous reply.
+	Marker string
+	// One of cacheWarmObject, cacheWarmFirstBlock or
+	// cacheWarmMetadata, cacheWarmObject if empty.
+	Mode string
+	// Maximum number of objects warmed per second by each node, zero
+	// for no limit.
+	Rate int
+}
+
+// CacheWarmFailure - object which could not be warmed on a node.
+type CacheWarmFailure struct {
+	Node   string
+	Object string
+	Error  string
+}
+
+// CacheWarmReply - reply by CacheWarm RPC, counts are summed across
+// all the nodes.
+type CacheWarmReply struct {
+	Warmed int
+	Bytes  int64
+	Failed []CacheWarmFailure
+	// Errors of the nodes which could not be warmed, keyed by node.
+	Errors map[string]string
+	// Position to continue at if IsTruncated.
+	NextMarker  string
+	IsTruncated bool
+}
+
+// warmObject - reads object as asked by mode, returns the number of
+// bytes read.
+func warmObject(objAPI ObjectLayer, bucket string, objInfo ObjectInfo, mode string) (int64, error) {
+	switch mode {
+	case cacheWarmMetadata:
+		_, err := objAPI.GetObjectInfo(bucket, objInfo.Name)
+		return 0, err
+	case cacheWarmFirstBlock:
+		length := objInfo.Size
+		if length > blockSizeV1 {
+			length = blockSizeV1
+		}
+		return length, objAPI.GetObject(bucket, objInfo.Name, 0, length, ioutil.Discard)
+	}
+	return objInfo.Size, objAPI.GetObject(bucket, objInfo.Name, 0, objInfo.Size, ioutil.Discard)
+}
+
+// warmCache - warms up to maxKeys objects of bucket under prefix,
+// starting after marker, at most rate objects per second. Objects
+// which fail do not stop the others, they are reported.
+func warmCache(objAPI ObjectLayer, node, bucket, prefix, marker, mode string, rate, maxKeys int, isCancelled func() bool) (reply CacheWarmReply, err error) {
+	result, err := objAPI.ListObjects(bucket, prefix, marker, "", maxKeys)
+	if err != nil {
+		return reply, err
+	}
+	var throttle <-chan time.Time
+	if rate > 0 {
+		ticker := time.NewTicker(time.Second / time.Duration(rate))
+		defer ticker.Stop()
+		throttle = ticker.C
+	}
+	for i, objInfo := range result.Objects {
+		if isCancelled() {
+			return reply, errRPCCancelled
+		}
+		if throttle != nil && i > 0 {
+			<-throttle
+		}
+		reply.NextMarker = objInfo.Name
+		if objInfo.IsDir {
+			continue
+		}
+		n, wErr := warmObject(objAPI, bucket, objInfo, mode)
+		if wErr != nil {
+			reply.Failed = append(reply.Failed, CacheWarmFailure{
+				Node:   node,
+				Object: objInfo.Name,
+				Error:  errorCause(wErr).Error(),
+			})
+			continue
+		}
+		reply.Warmed++
+		reply.Bytes += n
+	}
+	reply.IsTruncated = result.IsTruncated
+	return reply, nil
+}
+
+// CacheWarmHandler - RPC control handler for `minio control
+// cache-warm`, reads objects of a bucket to populate the object cache
+// of this node, and of all the reachable nodes if args.Remote is set.
+// Called repeatedly with the NextMarker of the previous reply until it
+// is not truncated.
+func (c *controlAPIHandlers) CacheWarmHandler(args *CacheWarmArgs, reply *CacheWarmReply) (err error) {
+	defer encodeRPCError(&err)
+
+	if !isRPCTokenValid(args.Token, jwtAudienceAdmin) {
+		return errInvalidToken
+	}
+	objAPI := c.ObjectAPI()
+	if objAPI == nil {
+		return errServerNotInitialized
+	}
+	if !IsValidBucketName(args.Bucket) || args.Rate < 0 || args.Rate > cacheWarmMaxRate {
+		return errInvalidArgument
+	}
+	switch args.Mode {
+	case "":
+		args.Mode = cacheWarmObject
+	case cacheWarmObject, cacheWarmFirstBlock, cacheWarmMetadata:
+	default:
+		return errInvalidArgument
+	}
+	if args.Mode == cacheWarmObject {
+		stats, sErr := objAPI.CacheStats()
+		if sErr != nil {
+			return sErr
+		}
+		if stats.MaxSize == 0 {
+			return errObjCacheDisabled
+		}
+	}
+
+	// Warm the remote peers along with this node, they list the same
+	// objects.
+	var remoteReplies []CacheWarmReply
+	var errsMap map[string]error
+	remoteControls := c.getRemoteControls()
+	remoteDoneCh := make(chan struct{})
+	go func() {
+		defer close(remoteDoneCh)
+		if !args.Remote {
+			return
+		}
+		remoteReplies = make([]CacheWarmReply, len(remoteControls))
+		remoteArgs := *args
+		remoteArgs.Remote = false
+		errsMap = callRemoteControls(remoteControls, "Control.CacheWarmHandler", &remoteArgs, func(index int) interface{} {
+			return &remoteReplies[index]
+		})
+	}()
+
+	*reply, err = warmCache(objAPI, c.LocalNode, args.Bucket, args.Prefix, args.Marker, args.Mode, args.Rate, cacheWarmMaxKeys, args.isCancelled)
+	<-remoteDoneCh
+	if err != nil {
+		return err
+	}
+	for index, remoteReply := range remoteReplies {
+		node := remoteControls[index].Node()
+		if nodeErr, ok := errsMap[node]; ok {
+			if reply.Errors == nil {
+				reply.Errors = make(map[string]string)
+			}
+			reply.Errors[node] = nodeErr.Error()
+			continue
+		}
+		reply.Warmed += remoteReply.Warmed
+		reply.Bytes += remoteReply.Bytes
+		reply.Failed = append(reply.Failed, remoteReply.Failed...)
+	}
+	return nil
+}
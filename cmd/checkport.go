@@ -69,3 +69,15 @@ func isAddrInUse(err error) bool {
 	}
 	return false
 }
+
+// findFreePort - asks the kernel for a free tcp port on localhost.
+// The port is released before returning, so callers should bind it
+// as soon as possible.
+func findFreePort() (int, error) {
+	l, err := net.Listen("tcp", "localhost:0")
+	if err != nil {
+		return 0, err
+	}
+	defer l.Close()
+	return l.Addr().(*net.TCPAddr).Port, nil
+}
@@ -0,0 +1,167 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package cmd
+
+import (
+	"net"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Client context attached to notification events, to help triaging
+// anomalous access.
+
+// Duration the resolved context of a client IP is cached.
+const clientInfoExpiry = 10 * time.Minute
+
+// Maximum number of client IPs cached, the cache is emptied once full.
+const clientInfoCacheSize = 10000
+
+// Duration a reverse DNS lookup may delay a notification event.
+const reverseDNSTimeout = 500 * time.Millisecond
+
+// clientInfo - resolved context of a client IP.
+type clientInfo struct {
+	Host      string
+	Country   string
+	Continent string
+	expiry    time.Time
+}
+
+// clientEnricher - resolves the reverse DNS and the coarse location,
+// from a local MaxMind DB, of client IPs.
+type clientEnricher struct {
+	mutex      *sync.Mutex
+	reverseDNS bool
+	geoDB      *mmdbReader
+	lookupAddr func(addr string) ([]string, error)
+	cache      map[string]clientInfo
+}
+
+// Variable holding the client enricher, nil when events are not enriched.
+var globalClientEnricher *clientEnricher
+
+// newClientEnricher - returns a client enricher resolving reverse DNS
+// if asked for, and locations if a geoDB is set.
+func newClientEnricher(reverseDNS bool, geoDB *mmdbReader) *clientEnricher {
+	return &clientEnricher{
+		mutex:      &sync.Mutex{},
+		reverseDNS: reverseDNS,
+		geoDB:      geoDB,
+		lookupAddr: net.LookupAddr,
+		cache:      make(map[string]clientInfo),
+	}
+}
+
+// Lookup - returns the context of a client IP, resolved once per
+// clientInfoExpiry.
+func (e *clientEnricher) Lookup(ip string) clientInfo {
+	e.mutex.Lock()
+	info, ok := e.cache[ip]
+	e.mutex.Unlock()
+	if ok && time.Now().Before(info.expiry) {
+		return info
+	}
+
+	info = clientInfo{expiry: time.Now().Add(clientInfoExpiry)}
+	if e.reverseDNS {
+		info.Host = e.lookupHost(ip)
+	}
+	if e.geoDB != nil {
+		info.Country, info.Continent = e.lookupLocation(ip)
+	}
+
+	e.mutex.Lock()
+	if len(e.cache) >= clientInfoCacheSize {
+		e.cache = make(map[string]clientInfo)
+	}
+	e.cache[ip] = info
+	e.mutex.Unlock()
+	return info
+}
+
+// lookupHost - returns the host name of ip, empty if it is not
+// resolved within reverseDNSTimeout.
+func (e *clientEnricher) lookupHost(ip string) string {
+	hostCh := make(chan string, 1)
+	go func() {
+		names, err := e.lookupAddr(ip)
+		if err != nil || len(names) == 0 {
+			hostCh <- ""
+			return
+		}
+		hostCh <- strings.TrimSuffix(names[0], ".")
+	}()
+	select {
+	case host := <-hostCh:
+		return host
+	case <-time.After(reverseDNSTimeout):
+		return ""
+	}
+}
+
+// lookupLocation - returns the ISO country code and the continent code
+// of ip, as found in GeoLite2 and GeoIP2 country and city databases.
+func (e *clientEnricher) lookupLocation(ip string) (country, continent string) {
+	addr := net.ParseIP(ip)
+	if addr == nil {
+		return "", ""
+	}
+	record, err := e.geoDB.Lookup(addr)
+	if err != nil {
+		errorIf(err, "Unable to lookup %s in the geoip database.", ip)
+		return "", ""
+	}
+	fields, ok := record.(map[string]interface{})
+	if !ok {
+		return "", ""
+	}
+	if c, ok := fields["country"].(map[string]interface{}); ok {
+		country, _ = c["iso_code"].(string)
+	}
+	if c, ok := fields["continent"].(map[string]interface{}); ok {
+		continent, _ = c["code"].(string)
+	}
+	return country, continent
+}
+
+// Enrich - returns request parameters of an event along with the
+// context of their source IP address.
+func (e *clientEnricher) Enrich(reqParams map[string]string) map[string]string {
+	sourceIP := reqParams["sourceIPAddress"]
+	if host, _, err := net.SplitHostPort(sourceIP); err == nil {
+		sourceIP = host
+	}
+	if sourceIP == "" {
+		return reqParams
+	}
+	info := e.Lookup(sourceIP)
+	params := make(map[string]string, len(reqParams)+3)
+	for k, v := range reqParams {
+		params[k] = v
+	}
+	if info.Host != "" {
+		params["sourceHost"] = info.Host
+	}
+	if info.Country != "" {
+		params["sourceCountry"] = info.Country
+	}
+	if info.Continent != "" {
+		params["sourceContinent"] = info.Continent
+	}
+	return params
+}
@@ -0,0 +1,121 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package cmd
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// Tests request parameters of events are enriched with the context
+// of their source IP address.
+func TestClientEnricher(t *testing.T) {
+	geoDB, err := parseMMDB(newTestMMDB())
+	if err != nil {
+		t.Fatalf("Unable to parse MaxMind DB: %v", err)
+	}
+	e := newClientEnricher(true, geoDB)
+	lookups := 0
+	e.lookupAddr = func(addr string) ([]string, error) {
+		lookups++
+		if addr == "10.1.2.3" {
+			return []string{"client.example.com."}, nil
+		}
+		return nil, errors.New("no such host")
+	}
+
+	testCases := []struct {
+		reqParams map[string]string
+		expected  map[string]string
+	}{
+		{
+			map[string]string{"sourceIPAddress": "10.1.2.3:41232"},
+			map[string]string{
+				"sourceIPAddress": "10.1.2.3:41232",
+				"sourceHost":      "client.example.com",
+				"sourceCountry":   "FR",
+				"sourceContinent": "EU",
+			},
+		},
+		// Cached, with another port.
+		{
+			map[string]string{"sourceIPAddress": "10.1.2.3:50000"},
+			map[string]string{
+				"sourceIPAddress": "10.1.2.3:50000",
+				"sourceHost":      "client.example.com",
+				"sourceCountry":   "FR",
+				"sourceContinent": "EU",
+			},
+		},
+		// Unresolved addresses are left as is.
+		{
+			map[string]string{"sourceIPAddress": "192.168.1.1:1234"},
+			map[string]string{"sourceIPAddress": "192.168.1.1:1234"},
+		},
+		{
+			map[string]string{},
+			map[string]string{},
+		},
+	}
+	for i, testCase := range testCases {
+		params := e.Enrich(testCase.reqParams)
+		if !reflect.DeepEqual(params, testCase.expected) {
+			t.Errorf("Test %d: Expected %v, got %v", i+1, testCase.expected, params)
+		}
+	}
+	if lookups != 2 {
+		t.Errorf("Expected 2 reverse DNS lookups, got %d", lookups)
+	}
+
+	// Without reverse DNS, only the location is added.
+	e = newClientEnricher(false, geoDB)
+	e.lookupAddr = nil
+	params := e.Enrich(map[string]string{"sourceIPAddress": "10.1.2.3:41232"})
+	if params["sourceHost"] != "" || params["sourceCountry"] != "FR" {
+		t.Errorf("Unexpected parameters %v", params)
+	}
+}
+
+// Tests notification events carry the context of their source.
+func TestNotificationEventEnriched(t *testing.T) {
+	root, err := newTestConfig("us-east-1")
+	if err != nil {
+		t.Fatalf("Unable to initialize test config %s", err)
+	}
+	defer removeAll(root)
+
+	geoDB, err := parseMMDB(newTestMMDB())
+	if err != nil {
+		t.Fatalf("Unable to parse MaxMind DB: %v", err)
+	}
+	globalClientEnricher = newClientEnricher(false, geoDB)
+	defer func() { globalClientEnricher = nil }()
+
+	reqParams := map[string]string{"sourceIPAddress": "10.1.2.3:41232"}
+	event := newNotificationEvent(eventData{
+		Type:      ObjectCreatedPut,
+		Bucket:    "bucket",
+		ObjInfo:   ObjectInfo{Name: "object"},
+		ReqParams: reqParams,
+	})
+	if event.RequestParameters["sourceCountry"] != "FR" {
+		t.Errorf("Expected source country FR, got %v", event.RequestParameters)
+	}
+	if _, ok := reqParams["sourceCountry"]; ok {
+		t.Errorf("Expected request parameters of the handler to be left as is")
+	}
+}
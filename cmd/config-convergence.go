@@ -0,0 +1,383 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"path"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Interval between checks of peers holding the same bucket configs.
+const configCheckInterval = 5 * time.Minute
+
+// errConfigRepairFailed - stored bucket config could not be sent to
+// some of the diverging peers.
+var errConfigRepairFailed = errors.New("Unable to send bucket config to all the diverging peers")
+
+// Kinds of bucket configs every peer holds in memory.
+const (
+	policyConfigKind       = "policy"
+	notificationConfigKind = "notification"
+	listenerConfigKind     = "listener"
+)
+
+// bucketConfigHashes - hashes of the configs of a bucket, empty for
+// configs which are not set.
+type bucketConfigHashes struct {
+	Policy       string
+	Notification string
+	Listener     string
+}
+
+// get - returns the hash of the config of kind.
+func (h bucketConfigHashes) get(kind string) string {
+	switch kind {
+	case policyConfigKind:
+		return h.Policy
+	case notificationConfigKind:
+		return h.Notification
+	}
+	return h.Listener
+}
+
+// getListenerConfigHash - returns the hash of a listener config,
+// empty if there are no listeners.
+func getListenerConfigHash(lcfg []listenerConfig) (string, error) {
+	if len(lcfg) == 0 {
+		return "", nil
+	}
+	buf, err := json.Marshal(lcfg)
+	if err != nil {
+		return "", err
+	}
+	hasher := newETagHash()
+	hasher.Write(buf)
+	return hex.EncodeToString(hasher.Sum(nil)), nil
+}
+
+// getBucketConfigHashes - returns the hashes of the bucket configs.
+func getBucketConfigHashes(policy *bucketPolicy, ncfg *notificationConfig, lcfg []listenerConfig) (hashes bucketConfigHashes, err error) {
+	if hashes.Policy, err = getBucketPolicyETag(policy); err != nil {
+		return hashes, err
+	}
+	if hashes.Notification, err = getNotificationConfigETag(ncfg); err != nil {
+		return hashes, err
+	}
+	hashes.Listener, err = getListenerConfigHash(lcfg)
+	return hashes, err
+}
+
+// getLocalBucketConfigHashes - returns the hashes of the configs held
+// in memory by this server for each of the buckets.
+func getLocalBucketConfigHashes(buckets []string) (map[string]bucketConfigHashes, error) {
+	hashes := make(map[string]bucketConfigHashes)
+	for _, bucket := range buckets {
+		bucketHashes, err := getBucketConfigHashes(
+			globalBucketPolicies.GetBucketPolicy(bucket),
+			globalEventNotifier.GetBucketNotificationConfig(bucket),
+			globalEventNotifier.GetBucketListenerConfig(bucket),
+		)
+		if err != nil {
+			return nil, err
+		}
+		hashes[bucket] = bucketHashes
+	}
+	return hashes, nil
+}
+
+// storedBucketConfigs - configs of a bucket as stored in the object
+// layer, the canonical copy peers converge to.
+type storedBucketConfigs struct {
+	policy *bucketPolicy
+	ncfg   *notificationConfig
+	lcfg   []listenerConfig
+}
+
+// readStoredBucketConfigs - reads the configs of a bucket from the
+// object layer, configs which are not set are left empty.
+func readStoredBucketConfigs(objAPI ObjectLayer, bucket string) (configs storedBucketConfigs, err error) {
+	if configs.policy, err = readBucketPolicy(bucket, objAPI); err != nil {
+		if _, ok := err.(BucketPolicyNotFound); !ok {
+			return configs, err
+		}
+	}
+	if configs.ncfg, err = loadNotificationConfig(bucket, objAPI); err != nil && err != errNoSuchNotifications {
+		return configs, err
+	}
+	if configs.lcfg, err = loadListenerConfig(bucket, objAPI); err != nil && err != errNoSuchNotifications {
+		return configs, err
+	}
+	return configs, nil
+}
+
+// getBucketOwnerNode - returns the peer owning the convergence check
+// of bucket, buckets are spread evenly across the peers and every
+// peer agrees on the owner as long as they agree on the peers.
+func getBucketOwnerNode(bucket string, peers []string) string {
+	if len(peers) == 0 {
+		return ""
+	}
+	sorted := append([]string(nil), peers...)
+	sort.Strings(sorted)
+	return sorted[crc32.ChecksumIEEE([]byte(bucket))%uint32(len(sorted))]
+}
+
+// getPeersBucketConfigHashes - returns the hashes of the configs held
+// in memory by each of the peers for the buckets, along with errors
+// of the peers which could not be reached.
+func getPeersBucketConfigHashes(peers []string, buckets []string) (map[string]map[string]bucketConfigHashes, map[string]error) {
+	type callResult struct {
+		peer   string
+		hashes map[string]bucketConfigHashes
+		err    error
+	}
+	resChan := make(chan callResult)
+	for _, peer := range peers {
+		go func(peer string) {
+			reply := &BucketConfigHashesReply{}
+			var err error
+			client := globalS3Peers.GetPeerClient(peer)
+			if client == nil {
+				err = fmt.Errorf("Requested client was not initialized - %v", peer)
+			} else {
+				err = client.Call("S3.BucketConfigHashesPeer", &BucketConfigHashesArgs{Buckets: buckets}, reply)
+			}
+			resChan <- callResult{peer, reply.Hashes, err}
+		}(peer)
+	}
+
+	hashes := make(map[string]map[string]bucketConfigHashes)
+	errs := make(map[string]error)
+	for range peers {
+		res := <-resChan
+		if res.err != nil {
+			errs[res.peer] = res.err
+			continue
+		}
+		hashes[res.peer] = res.hashes
+	}
+	return hashes, errs
+}
+
+// configDivergence - peers holding a bucket config which differs
+// from the stored one.
+type configDivergence struct {
+	Bucket string   `json:"bucket"`
+	Config string   `json:"config"`
+	Peers  []string `json:"peers"`
+	// Set once the stored config was sent to the peers again.
+	Repaired bool `json:"repaired"`
+}
+
+// compareBucketConfigHashes - returns the configs of bucket for which
+// peers hold a different copy than the stored one.
+func compareBucketConfigHashes(bucket string, stored bucketConfigHashes, peerHashes map[string]map[string]bucketConfigHashes) []configDivergence {
+	var divergences []configDivergence
+	for _, kind := range []string{policyConfigKind, notificationConfigKind, listenerConfigKind} {
+		var peers []string
+		for peer, hashes := range peerHashes {
+			if hashes[bucket].get(kind) != stored.get(kind) {
+				peers = append(peers, peer)
+			}
+		}
+		if len(peers) > 0 {
+			sort.Strings(peers)
+			divergences = append(divergences, configDivergence{Bucket: bucket, Config: kind, Peers: peers})
+		}
+	}
+	return divergences
+}
+
+// repairBucketConfig - sends the stored config of the divergence to
+// its peers again.
+func repairBucketConfig(configs storedBucketConfigs, divergence configDivergence) error {
+	var errsMap map[string]error
+	switch divergence.Config {
+	case policyConfigKind:
+		pCh := policyChange{
+			IsRemove:  configs.policy == nil,
+			BktPolicy: configs.policy,
+			Version:   globalBucketPolicies.NextBucketPolicyVersion(divergence.Bucket),
+		}
+		byts, err := json.Marshal(pCh)
+		if err != nil {
+			return err
+		}
+		errsMap = globalS3Peers.SendRPC(divergence.Peers, "S3.SetBucketPolicyPeer",
+			&SetBPPArgs{Bucket: divergence.Bucket, PChBytes: byts})
+	case notificationConfigKind:
+		errsMap = globalS3Peers.SendRPC(divergence.Peers, "S3.SetBucketNotificationPeer",
+			&SetBNPArgs{Bucket: divergence.Bucket, NCfg: configs.ncfg})
+	case listenerConfigKind:
+		errsMap = globalS3Peers.SendRPC(divergence.Peers, "S3.SetBucketListenerPeer",
+			&SetBLPArgs{Bucket: divergence.Bucket, LCfg: configs.lcfg})
+	}
+	for peer, err := range errsMap {
+		errorIf(err, "Unable to repair %s config of bucket %s on %s.", divergence.Config, divergence.Bucket, peer)
+	}
+	if len(errsMap) > 0 {
+		return errConfigRepairFailed
+	}
+	return nil
+}
+
+// checkBucketConfig - compares the configs of bucket held by the
+// peers with the stored ones, and sends the stored ones again to
+// diverging peers if repair is set. Config changes of the bucket are
+// locked out meanwhile, so that changes in flight are not reported.
+func checkBucketConfig(objAPI ObjectLayer, bucket string, peers []string, repair bool) ([]configDivergence, error) {
+	opsID := getOpsID()
+	lockPath := path.Join(bucketConfigPrefix, bucket)
+	nsMutex.Lock(minioMetaBucket, lockPath, opsID)
+	defer nsMutex.Unlock(minioMetaBucket, lockPath, opsID)
+
+	configs, err := readStoredBucketConfigs(objAPI, bucket)
+	if err != nil {
+		return nil, err
+	}
+	stored, err := getBucketConfigHashes(configs.policy, configs.ncfg, configs.lcfg)
+	if err != nil {
+		return nil, err
+	}
+	peerHashes, _ := getPeersBucketConfigHashes(peers, []string{bucket})
+	divergences := compareBucketConfigHashes(bucket, stored, peerHashes)
+	if repair {
+		for i := range divergences {
+			divergences[i].Repaired = repairBucketConfig(configs, divergences[i]) == nil
+		}
+	}
+	return divergences, nil
+}
+
+// checkConfigConvergence - checks that all the peers hold the stored
+// configs of the buckets owned by this server. Hashes of all the
+// buckets are fetched from the peers at once, only buckets that look
+// diverged are checked again one by one under lock.
+func checkConfigConvergence(objAPI ObjectLayer, repair bool) error {
+	bucketsInfo, err := objAPI.ListBuckets()
+	if err != nil {
+		return errorCause(err)
+	}
+	peers := globalS3Peers.GetPeers()
+	var buckets []string
+	for _, bucketInfo := range bucketsInfo {
+		if getBucketOwnerNode(bucketInfo.Name, peers) == globalMinioAddr {
+			buckets = append(buckets, bucketInfo.Name)
+		}
+	}
+
+	peerHashes, errs := getPeersBucketConfigHashes(peers, buckets)
+	var unreachable []string
+	for peer := range errs {
+		unreachable = append(unreachable, peer)
+	}
+	sort.Strings(unreachable)
+
+	// Buckets which fail to be checked are skipped, the first error
+	// is returned once all the others are checked.
+	var divergences []configDivergence
+	var checkErr error
+	for _, bucket := range buckets {
+		configs, err := readStoredBucketConfigs(objAPI, bucket)
+		if err != nil {
+			checkErr = err
+			continue
+		}
+		stored, err := getBucketConfigHashes(configs.policy, configs.ncfg, configs.lcfg)
+		if err != nil {
+			checkErr = err
+			continue
+		}
+		if len(compareBucketConfigHashes(bucket, stored, peerHashes)) == 0 {
+			continue
+		}
+		bucketDivergences, err := checkBucketConfig(objAPI, bucket, peers, repair)
+		if err != nil {
+			checkErr = err
+			continue
+		}
+		divergences = append(divergences, bucketDivergences...)
+	}
+
+	globalConfigConvergence.Set(time.Now().UTC(), len(buckets), unreachable, divergences)
+	return checkErr
+}
+
+// startConfigCheck - periodically checks that all the peers hold the
+// same bucket configs.
+func startConfigCheck(objAPI ObjectLayer, interval time.Duration, repair bool) {
+	globalTaskManager.Start(configCheckTask, interval, func() error {
+		err := checkConfigConvergence(objAPI, repair)
+		errorIf(err, "Unable to check bucket configs of peers.")
+		return err
+	})
+}
+
+// configConvergence - result of the last convergence check of the
+// buckets owned by this server.
+type configConvergence struct {
+	mutex       *sync.Mutex
+	lastCheck   time.Time
+	buckets     int
+	unreachable []string
+	divergences []configDivergence
+}
+
+// Variable holding the result of the last bucket config check.
+var globalConfigConvergence = newConfigConvergence()
+
+func newConfigConvergence() *configConvergence {
+	return &configConvergence{mutex: &sync.Mutex{}}
+}
+
+// Set - records the result of a check.
+func (c *configConvergence) Set(lastCheck time.Time, buckets int, unreachable []string, divergences []configDivergence) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.lastCheck = lastCheck
+	c.buckets = buckets
+	c.unreachable = unreachable
+	c.divergences = divergences
+}
+
+// Divergences - returns the divergences found by the last check.
+func (c *configConvergence) Divergences() []configDivergence {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return append([]configDivergence(nil), c.divergences...)
+}
+
+// String - summary of the last check for ServerInfo.
+func (c *configConvergence) String() string {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.lastCheck.IsZero() {
+		return "Last-Check: never"
+	}
+	return fmt.Sprintf("Buckets: %d | Diverged: %d | Unreachable: %d | Last-Check: %s",
+		c.buckets,
+		len(c.divergences),
+		len(c.unreachable),
+		c.lastCheck.Format(time.RFC3339))
+}
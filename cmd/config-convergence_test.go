@@ -0,0 +1,144 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Tests spreading buckets across the peers.
+func TestGetBucketOwnerNode(t *testing.T) {
+	peers := []string{"node1:9000", "node2:9000", "node3:9000", "node4:9000"}
+	reversed := []string{"node4:9000", "node3:9000", "node2:9000", "node1:9000"}
+	owners := make(map[string]int)
+	for i := 0; i < 100; i++ {
+		bucket := "bucket" + string(rune('a'+i%26)) + strings.Repeat("x", i/26)
+		owner := getBucketOwnerNode(bucket, peers)
+		if owner != getBucketOwnerNode(bucket, reversed) {
+			t.Fatalf("Owner of %s depends on the order of the peers", bucket)
+		}
+		owners[owner]++
+	}
+	if len(owners) != len(peers) {
+		t.Fatalf("Expected buckets owned by all the %d peers, got %v", len(peers), owners)
+	}
+	if owner := getBucketOwnerNode("bucket", nil); owner != "" {
+		t.Fatalf("Expected no owner without peers, got %s", owner)
+	}
+}
+
+// Tests finding peers holding diverged configs.
+func TestCompareBucketConfigHashes(t *testing.T) {
+	stored := bucketConfigHashes{Policy: "p1", Notification: "n1"}
+	peerHashes := map[string]map[string]bucketConfigHashes{
+		"node1:9000": {"bucket": stored},
+		"node2:9000": {"bucket": {Policy: "p0", Notification: "n1"}},
+		"node3:9000": {"bucket": {Notification: "n1", Listener: "l1"}},
+		"node4:9000": {},
+	}
+	expected := []configDivergence{
+		{Bucket: "bucket", Config: policyConfigKind, Peers: []string{"node2:9000", "node3:9000", "node4:9000"}},
+		{Bucket: "bucket", Config: notificationConfigKind, Peers: []string{"node4:9000"}},
+		{Bucket: "bucket", Config: listenerConfigKind, Peers: []string{"node3:9000"}},
+	}
+	if divergences := compareBucketConfigHashes("bucket", stored, peerHashes); !reflect.DeepEqual(divergences, expected) {
+		t.Fatalf("Expected %v, got %v", expected, divergences)
+	}
+	peerHashes = map[string]map[string]bucketConfigHashes{"node1:9000": {"bucket": stored}}
+	if divergences := compareBucketConfigHashes("bucket", stored, peerHashes); len(divergences) != 0 {
+		t.Fatalf("Expected no divergences, got %v", divergences)
+	}
+}
+
+// Tests checking and repairing bucket configs of the peers.
+func TestCheckConfigConvergence(t *testing.T) {
+	testServer := StartTestServer(t, "XL")
+	defer testServer.Stop()
+
+	obj := testServer.Obj
+	if err := initEventNotifier(obj); err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	if err := initBucketPolicies(obj); err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	bucketName := getRandomBucketName()
+	if err := obj.MakeBucket(bucketName); err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+
+	// Store a policy without telling the peers.
+	policyStr := `{"Version":"2012-10-17","Statement":[{"Action":["s3:GetObject"],"Effect":"Allow","Principal":{"AWS":["*"]},"Resource":["arn:aws:s3:::` + bucketName + `/*"],"Sid":""}]}`
+	policy := &bucketPolicy{}
+	if err := parseBucketPolicy(strings.NewReader(policyStr), policy); err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	if err := writeBucketPolicy(bucketName, obj, policy); err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+
+	expected := []configDivergence{
+		{Bucket: bucketName, Config: policyConfigKind, Peers: []string{globalMinioAddr}},
+	}
+	if err := checkConfigConvergence(obj, false); err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	if divergences := globalConfigConvergence.Divergences(); !reflect.DeepEqual(divergences, expected) {
+		t.Fatalf("Expected %v, got %v", expected, divergences)
+	}
+	if globalBucketPolicies.GetBucketPolicy(bucketName) != nil {
+		t.Fatal("Policy should not be repaired")
+	}
+
+	expected[0].Repaired = true
+	if err := checkConfigConvergence(obj, true); err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	if divergences := globalConfigConvergence.Divergences(); !reflect.DeepEqual(divergences, expected) {
+		t.Fatalf("Expected %v, got %v", expected, divergences)
+	}
+	if globalBucketPolicies.GetBucketPolicy(bucketName) == nil {
+		t.Fatal("Policy should be repaired")
+	}
+
+	if err := checkConfigConvergence(obj, false); err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	if divergences := globalConfigConvergence.Divergences(); len(divergences) != 0 {
+		t.Fatalf("Expected no divergences, got %v", divergences)
+	}
+	if status := globalConfigConvergence.String(); !strings.HasPrefix(status, "Buckets: 1 | Diverged: 0 | Unreachable: 0") {
+		t.Fatalf("Unexpected status %s", status)
+	}
+}
+
+// Tests the summary of the last check.
+func TestConfigConvergenceString(t *testing.T) {
+	c := newConfigConvergence()
+	if status := c.String(); status != "Last-Check: never" {
+		t.Fatalf("Unexpected status %s", status)
+	}
+	lastCheck := time.Date(2016, 10, 1, 12, 0, 0, 0, time.UTC)
+	c.Set(lastCheck, 3, []string{"node2:9000"}, []configDivergence{{Bucket: "bucket", Config: policyConfigKind}})
+	expected := "Buckets: 3 | Diverged: 1 | Unreachable: 1 | Last-Check: 2016-10-01T12:00:00Z"
+	if status := c.String(); status != expected {
+		t.Fatalf("Expected %s, got %s", expected, status)
+	}
+}
@@ -0,0 +1,245 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"strings"
+	"sync"
+)
+
+// Secrets in config.json are stored as "minio:enc:v1:" followed by the
+// base64 encoding of salt, nonce and the AES-256-GCM sealed secret. The
+// key is derived from MINIO_CONFIG_PASSPHRASE with PBKDF2-HMAC-SHA256.
+const (
+	configSecretPrefix     = "minio:enc:v1:"
+	configSecretSaltSize   = 16
+	configSecretNonceSize  = 12
+	configSecretKeySize    = 32
+	configSecretIterations = 100000
+)
+
+// errConfigPassphraseRequired - config.json has encrypted secrets but
+// no passphrase was provided.
+var errConfigPassphraseRequired = errors.New("config.json has encrypted secrets, MINIO_CONFIG_PASSPHRASE is required")
+
+// errConfigPassphraseInvalid - an encrypted secret could not be decrypted
+// with the provided passphrase.
+var errConfigPassphraseInvalid = errors.New("Unable to decrypt config.json secrets, MINIO_CONFIG_PASSPHRASE is invalid")
+
+// pbkdf2SHA256 - PBKDF2 (RFC 2898) key derivation with HMAC-SHA256.
+func pbkdf2SHA256(password, salt []byte, iterations, keyLen int) []byte {
+	prf := hmac.New(sha256.New, password)
+	hashLen := prf.Size()
+	numBlocks := (keyLen + hashLen - 1) / hashLen
+
+	var buf [4]byte
+	key := make([]byte, 0, numBlocks*hashLen)
+	u := make([]byte, hashLen)
+	for block := 1; block <= numBlocks; block++ {
+		prf.Reset()
+		prf.Write(salt)
+		binary.BigEndian.PutUint32(buf[:], uint32(block))
+		prf.Write(buf[:])
+		u = prf.Sum(u[:0])
+		t := make([]byte, hashLen)
+		copy(t, u)
+		for i := 1; i < iterations; i++ {
+			prf.Reset()
+			prf.Write(u)
+			u = prf.Sum(u[:0])
+			for j := range t {
+				t[j] ^= u[j]
+			}
+		}
+		key = append(key, t...)
+	}
+	return key[:keyLen]
+}
+
+// configKeyID identifies a derived key.
+type configKeyID struct {
+	passphrase string
+	salt       string
+}
+
+// configSecretKeys - caches derived keys, key derivation is deliberately
+// slow and config.json has a handful of secrets all sharing one salt.
+var configSecretKeys = struct {
+	sync.Mutex
+	keys map[configKeyID][]byte
+	salt []byte
+}{keys: make(map[configKeyID][]byte)}
+
+// getConfigSecretKey - returns the key derived from passphrase and salt.
+func getConfigSecretKey(passphrase string, salt []byte) []byte {
+	configSecretKeys.Lock()
+	defer configSecretKeys.Unlock()
+
+	id := configKeyID{passphrase, string(salt)}
+	key, ok := configSecretKeys.keys[id]
+	if !ok {
+		key = pbkdf2SHA256([]byte(passphrase), salt, configSecretIterations, configSecretKeySize)
+		configSecretKeys.keys[id] = key
+	}
+	return key
+}
+
+// getConfigSecretSalt - returns the salt used for encrypting secrets,
+// generated once per process.
+func getConfigSecretSalt() ([]byte, error) {
+	configSecretKeys.Lock()
+	defer configSecretKeys.Unlock()
+
+	if configSecretKeys.salt == nil {
+		salt := make([]byte, configSecretSaltSize)
+		if _, err := rand.Read(salt); err != nil {
+			return nil, err
+		}
+		configSecretKeys.salt = salt
+	}
+	return configSecretKeys.salt, nil
+}
+
+// isEncryptedConfigSecret - returns true if value is an encrypted secret.
+func isEncryptedConfigSecret(value string) bool {
+	return strings.HasPrefix(value, configSecretPrefix)
+}
+
+// encryptConfigSecret - encrypts a config.json secret with passphrase.
+// Empty and already encrypted values are returned as is.
+func encryptConfigSecret(passphrase, value string) (string, error) {
+	if value == "" || isEncryptedConfigSecret(value) {
+		return value, nil
+	}
+	salt, err := getConfigSecretSalt()
+	if err != nil {
+		return "", err
+	}
+	block, err := aes.NewCipher(getConfigSecretKey(passphrase, salt))
+	if err != nil {
+		return "", err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, configSecretNonceSize)
+	if _, err = rand.Read(nonce); err != nil {
+		return "", err
+	}
+	sealed := append(append([]byte{}, salt...), nonce...)
+	sealed = aead.Seal(sealed, nonce, []byte(value), nil)
+	return configSecretPrefix + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// decryptConfigSecret - decrypts a config.json secret with passphrase.
+// Plaintext values are returned as is.
+func decryptConfigSecret(passphrase, value string) (string, error) {
+	if !isEncryptedConfigSecret(value) {
+		return value, nil
+	}
+	if passphrase == "" {
+		return "", errConfigPassphraseRequired
+	}
+	sealed, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(value, configSecretPrefix))
+	if err != nil || len(sealed) < configSecretSaltSize+configSecretNonceSize {
+		return "", errConfigPassphraseInvalid
+	}
+	salt := sealed[:configSecretSaltSize]
+	nonce := sealed[configSecretSaltSize : configSecretSaltSize+configSecretNonceSize]
+	block, err := aes.NewCipher(getConfigSecretKey(passphrase, salt))
+	if err != nil {
+		return "", err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return "", err
+	}
+	plaintext, err := aead.Open(nil, nonce, sealed[configSecretSaltSize+configSecretNonceSize:], nil)
+	if err != nil {
+		return "", errConfigPassphraseInvalid
+	}
+	return string(plaintext), nil
+}
+
+// transformSecrets - replaces every secret in the config with the result
+// of fn. Notification maps are copied, so that a copy of the config can
+// be transformed without touching the original.
+func (s *serverConfigV10) transformSecrets(fn func(string) (string, error)) (err error) {
+	transform := func(value *string) {
+		if err == nil {
+			*value, err = fn(*value)
+		}
+	}
+
+	transform(&s.Credential.SecretAccessKey)
+
+	amqp := make(map[string]amqpNotify, len(s.Notify.AMQP))
+	for k, v := range s.Notify.AMQP {
+		// AMQP credentials are part of the URL.
+		transform(&v.URL)
+		amqp[k] = v
+	}
+	s.Notify.AMQP = amqp
+
+	nats := make(map[string]natsNotify, len(s.Notify.NATS))
+	for k, v := range s.Notify.NATS {
+		transform(&v.Password)
+		transform(&v.Token)
+		nats[k] = v
+	}
+	s.Notify.NATS = nats
+
+	redis := make(map[string]redisNotify, len(s.Notify.Redis))
+	for k, v := range s.Notify.Redis {
+		transform(&v.Password)
+		redis[k] = v
+	}
+	s.Notify.Redis = redis
+
+	postgreSQL := make(map[string]postgreSQLNotify, len(s.Notify.PostgreSQL))
+	for k, v := range s.Notify.PostgreSQL {
+		transform(&v.ConnectionString)
+		transform(&v.Password)
+		postgreSQL[k] = v
+	}
+	s.Notify.PostgreSQL = postgreSQL
+
+	return err
+}
+
+// encryptSecrets - encrypts every secret in the config with passphrase.
+func (s *serverConfigV10) encryptSecrets(passphrase string) error {
+	return s.transformSecrets(func(value string) (string, error) {
+		return encryptConfigSecret(passphrase, value)
+	})
+}
+
+// decryptSecrets - decrypts every secret in the config with passphrase.
+func (s *serverConfigV10) decryptSecrets(passphrase string) error {
+	return s.transformSecrets(func(value string) (string, error) {
+		return decryptConfigSecret(passphrase, value)
+	})
+}
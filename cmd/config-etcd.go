@@ -0,0 +1,109 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+)
+
+// Key of config.json in etcd, shared by all the servers.
+const etcdConfigKey = "config/config.json"
+
+// etcdValue - returns the config as stored in etcd, secrets encrypted
+// if MINIO_CONFIG_PASSPHRASE is set. s is a copy so the secrets in
+// memory stay in plaintext.
+func (s serverConfigV10) etcdValue() (string, error) {
+	if globalConfigPassphrase != "" {
+		if err := s.encryptSecrets(globalConfigPassphrase); err != nil {
+			return "", err
+		}
+	}
+	data, err := json.MarshalIndent(&s, "", "\t")
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// loadEtcdConfig - loads the config stored in etcd, fails with
+// errEtcdKeyNotFound if none was stored yet.
+func loadEtcdConfig() (*serverConfigV10, error) {
+	value, err := globalEtcd.get(etcdConfigKey)
+	if err != nil {
+		return nil, err
+	}
+	srvCfg := &serverConfigV10{}
+	if err = json.Unmarshal([]byte(value), srvCfg); err != nil {
+		return nil, err
+	}
+	if srvCfg.Version != globalMinioConfigVersion {
+		return nil, fmt.Errorf("Unsupported config version '%s' in etcd, expected '%s'", srvCfg.Version, globalMinioConfigVersion)
+	}
+	// Decrypt secrets encrypted with MINIO_CONFIG_PASSPHRASE.
+	if err = srvCfg.decryptSecrets(globalConfigPassphrase); err != nil {
+		return nil, err
+	}
+	srvCfg.rwMutex = &sync.RWMutex{}
+	return srvCfg, nil
+}
+
+// storeEtcdConfig - stores the config loaded from config.json in etcd
+// the first time. If another server stored its config first, that
+// config is loaded instead.
+func storeEtcdConfig() error {
+	serverConfig.rwMutex.RLock()
+	value, err := serverConfig.etcdValue()
+	serverConfig.rwMutex.RUnlock()
+	if err != nil {
+		return err
+	}
+	if err = globalEtcd.create(etcdConfigKey, value); err != errEtcdKeyModified {
+		return err
+	}
+	srvCfg, err := loadEtcdConfig()
+	if err != nil {
+		return err
+	}
+	serverConfig = srvCfg
+	return nil
+}
+
+// reloadEtcdConfig - applies the credential and region changed in
+// etcd by another server. Credentials set by MINIO_ACCESS_KEY and
+// MINIO_SECRET_KEY are kept, changes of loggers and notification
+// targets take effect on restart.
+func reloadEtcdConfig() error {
+	srvCfg, err := loadEtcdConfig()
+	if err != nil {
+		return err
+	}
+	if os.Getenv("MINIO_ACCESS_KEY") == "" || os.Getenv("MINIO_SECRET_KEY") == "" {
+		serverConfig.SetCredential(srvCfg.GetCredential())
+	}
+	serverConfig.SetRegion(srvCfg.GetRegion())
+	return nil
+}
+
+// startEtcdConfigWatch - reloads the config whenever it changes in
+// etcd, until doneCh is closed.
+func startEtcdConfigWatch(doneCh <-chan struct{}) {
+	globalEtcd.watch(etcdConfigKey, doneCh, func(string) {
+		errorIf(reloadEtcdConfig(), "Unable to reload config from etcd.")
+	})
+}
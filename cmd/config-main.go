@@ -0,0 +1,153 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"os"
+
+	"github.com/minio/cli"
+	"github.com/minio/mc/pkg/console"
+)
+
+// "minio config" command.
+var configCmd = cli.Command{
+	Name:   "config",
+	Usage:  "Manage the server config.",
+	Flags:  globalFlags,
+	Action: mainConfig,
+	Subcommands: []cli.Command{
+		configEncryptCmd,
+		configDecryptCmd,
+	},
+	CustomHelpTemplate: `NAME:
+   {{.Name}} - {{.Usage}}
+
+USAGE:
+   {{.Name}} [FLAGS] COMMAND
+
+FLAGS:
+  {{range .Flags}}{{.}}
+  {{end}}
+COMMANDS:
+   {{range .Commands}}{{join .Names ", "}}{{ "\t" }}{{.Usage}}
+   {{end}}
+`,
+}
+
+// "minio config encrypt" command.
+var configEncryptCmd = cli.Command{
+	Name:   "encrypt",
+	Usage:  "Encrypt the secrets in config.json.",
+	Flags:  globalFlags,
+	Action: mainConfigEncrypt,
+	CustomHelpTemplate: `NAME:
+   minio config {{.Name}} - {{.Usage}}
+
+USAGE:
+   minio config {{.Name}}
+
+FLAGS:
+  {{range .Flags}}{{.}}
+  {{end}}
+DESCRIPTION:
+   Rewrites config.json with the secret key and notification target
+   passwords, tokens, connection strings and AMQP URLs encrypted with
+   MINIO_CONFIG_PASSPHRASE. The plaintext config.json.old backup is removed.
+   The server must then always be started with the same passphrase.
+
+ENVIRONMENT VARIABLES:
+  SECURITY:
+     MINIO_CONFIG_PASSPHRASE: Passphrase encrypting the secrets in config.json.
+
+EXAMPLES:
+   1. Encrypt the secrets of an existing plaintext config.
+      $ export MINIO_CONFIG_PASSPHRASE
+      $ read -s MINIO_CONFIG_PASSPHRASE
+      $ minio config {{.Name}}
+`,
+}
+
+// "minio config decrypt" command.
+var configDecryptCmd = cli.Command{
+	Name:   "decrypt",
+	Usage:  "Decrypt the secrets in config.json.",
+	Flags:  globalFlags,
+	Action: mainConfigDecrypt,
+	CustomHelpTemplate: `NAME:
+   minio config {{.Name}} - {{.Usage}}
+
+USAGE:
+   minio config {{.Name}}
+
+FLAGS:
+  {{range .Flags}}{{.}}
+  {{end}}
+DESCRIPTION:
+   Rewrites config.json with all secrets in plaintext. Together with
+   'minio config encrypt' this changes the passphrase.
+
+ENVIRONMENT VARIABLES:
+  SECURITY:
+     MINIO_CONFIG_PASSPHRASE: Passphrase encrypting the secrets in config.json.
+
+EXAMPLES:
+   1. Change the passphrase of an encrypted config.
+      $ MINIO_CONFIG_PASSPHRASE=old-passphrase minio config {{.Name}}
+      $ MINIO_CONFIG_PASSPHRASE=new-passphrase minio config encrypt
+`,
+}
+
+func mainConfig(ctx *cli.Context) {
+	cli.ShowAppHelp(ctx)
+}
+
+func mainConfigEncrypt(ctx *cli.Context) {
+	if len(ctx.Args()) != 0 {
+		cli.ShowCommandHelpAndExit(ctx, "encrypt", 1)
+	}
+	if globalConfigPassphrase == "" {
+		console.Fatalln("MINIO_CONFIG_PASSPHRASE is not set.")
+	}
+
+	err := serverConfig.Save()
+	fatalIf(err, "Unable to save config.json with encrypted secrets.")
+
+	// The backup of the previous config has the secrets in plaintext.
+	configFile, err := getConfigFile()
+	fatalIf(err, "Unable to get config file.")
+	if err = os.Remove(configFile + ".old"); err != nil && !os.IsNotExist(err) {
+		fatalIf(err, "Unable to remove %s.old.", configFile)
+	}
+
+	console.Println("Secrets in " + configFile + " are now encrypted.")
+}
+
+func mainConfigDecrypt(ctx *cli.Context) {
+	if len(ctx.Args()) != 0 {
+		cli.ShowCommandHelpAndExit(ctx, "decrypt", 1)
+	}
+
+	// Secrets are already decrypted in memory, saving without a
+	// passphrase writes them in plaintext.
+	globalConfigPassphrase = ""
+	err := serverConfig.Save()
+	fatalIf(err, "Unable to save config.json with plaintext secrets.")
+
+	configFile, err := getConfigFile()
+	fatalIf(err, "Unable to get config file.")
+	console.Println("Secrets in " + configFile + " are now in plaintext.")
+}
@@ -23,7 +23,7 @@ import (
 	"testing"
 )
 
-const lastConfigVersion = 9
+const lastConfigVersion = 10
 
 // Test if config v1 is purged
 func TestServerConfigMigrateV1(t *testing.T) {
@@ -97,10 +97,13 @@ func TestServerConfigMigrateInexistentConfig(t *testing.T) {
 	if err := migrateV8ToV9(); err != nil {
 		t.Fatal("migrate v8 to v9 should succeed when no config file is found")
 	}
+	if err := migrateV9ToV10(); err != nil {
+		t.Fatal("migrate v9 to v10 should succeed when no config file is found")
+	}
 }
 
-// Test if a config migration from v2 to v9 is successfully done
-func TestServerConfigMigrateV2toV9(t *testing.T) {
+// Test if a config migration from v2 to v10 is successfully done
+func TestServerConfigMigrateV2toV10(t *testing.T) {
 	rootPath, err := newTestConfig("us-east-1")
 	if err != nil {
 		t.Fatalf("Init Test config failed")
@@ -158,6 +161,53 @@ func TestServerConfigMigrateV2toV9(t *testing.T) {
 	}
 }
 
+// Test if a config migration from v9 to v10 keeps the HTTP logger and
+// the TLS insecure flags of the notification targets.
+func TestServerConfigMigrateV9toV10(t *testing.T) {
+	rootPath, err := newTestConfig("us-east-1")
+	if err != nil {
+		t.Fatalf("Init Test config failed")
+	}
+	// remove the root folder after the test ends.
+	defer removeAll(rootPath)
+
+	setGlobalConfigPath(rootPath)
+	configPath := rootPath + "/" + globalMinioConfigFile
+
+	// Create a V9 config json file and store it
+	configJSON := `{"version":"9", "credential":{"accessKey":"accessfoo", "secretKey":"secretfoo"}, "region":"us-east-1",
+	"logger":{"console":{"enable":true, "level":"fatal"}, "http":{"enable":true, "endpoint":"https://localhost:9090/log", "level":"error", "insecure":true}},
+	"notify":{"amqp":{"1":{"enable":true, "url":"amqps://localhost:5671", "insecure":true}},
+	"nats":{"1":{"enable":true, "address":"localhost:4222", "insecure":true}},
+	"elasticsearch":{"1":{"enable":true, "url":"https://localhost:9200", "index":"minio", "insecure":true}}}}`
+	if err := ioutil.WriteFile(configPath, []byte(configJSON), 0644); err != nil {
+		t.Fatal("Unexpected error: ", err)
+	}
+	if err := migrateConfig(); err != nil {
+		t.Fatal("Unexpected error: ", err)
+	}
+	if err := initConfig(); err != nil {
+		t.Fatalf("Unable to initialize from updated config file %s", err)
+	}
+
+	if serverConfig.Version != "10" {
+		t.Fatalf("Expect version 10, found: %v", serverConfig.Version)
+	}
+	httpL := serverConfig.Logger.HTTP
+	if !httpL.Enable || httpL.Endpoint != "https://localhost:9090/log" || !httpL.Insecure {
+		t.Fatalf("HTTP logger lost during migration, found: %#v", httpL)
+	}
+	if !serverConfig.Notify.AMQP["1"].Insecure {
+		t.Fatal("AMQP insecure flag lost during migration")
+	}
+	if !serverConfig.Notify.NATS["1"].Insecure {
+		t.Fatal("NATS insecure flag lost during migration")
+	}
+	if !serverConfig.Notify.ElasticSearch["1"].Insecure {
+		t.Fatal("Elasticsearch insecure flag lost during migration")
+	}
+}
+
 // Test if all migrate code returns error with corrupted config files
 func TestServerConfigMigrateFaultyConfig(t *testing.T) {
 	rootPath, err := newTestConfig("us-east-1")
@@ -197,4 +247,7 @@ func TestServerConfigMigrateFaultyConfig(t *testing.T) {
 	if err := migrateV8ToV9(); err == nil {
 		t.Fatal("migrateConfigV8ToV9() should fail with a corrupted json")
 	}
+	if err := migrateV9ToV10(); err == nil {
+		t.Fatal("migrateConfigV9ToV10() should fail with a corrupted json")
+	}
 }
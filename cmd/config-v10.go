@@ -23,9 +23,10 @@ import (
 	"github.com/mf-00/newgo/pkg/quick"
 )
 
-// serverConfigV9 server configuration version '9'. Adds PostgreSQL
-// notifier configuration.
-type serverConfigV9 struct {
+// serverConfigV10 server configuration version '10'. Adds HTTP logger
+// configuration and the TLS 'insecure' flag for the HTTP logger and the
+// AMQP, NATS and Elasticsearch notification targets.
+type serverConfigV10 struct {
 	Version string `json:"version"`
 
 	// S3 API configuration.
@@ -44,9 +45,20 @@ type serverConfigV9 struct {
 
 // initConfig - initialize server config. config version (called only once).
 func initConfig() error {
+	// Config stored in etcd takes precedence over config.json.
+	if globalEtcd != nil {
+		srvCfg, err := loadEtcdConfig()
+		if err == nil {
+			serverConfig = srvCfg
+			return nil
+		}
+		if err != errEtcdKeyNotFound {
+			return err
+		}
+	}
 	if !isConfigFileExists() {
 		// Initialize server config.
-		srvCfg := &serverConfigV9{}
+		srvCfg := &serverConfigV10{}
 		srvCfg.Version = globalMinioConfigVersion
 		srvCfg.Region = defaultRegion
 		srvCfg.Credential = mustGenAccessKeys()
@@ -79,6 +91,11 @@ func initConfig() error {
 		// Save the new config globally.
 		serverConfig = srvCfg
 
+		// Share the new config through etcd.
+		if globalEtcd != nil {
+			return storeEtcdConfig()
+		}
+
 		// Save config into file.
 		return serverConfig.Save()
 	}
@@ -89,7 +106,7 @@ func initConfig() error {
 	if _, err = os.Stat(configFile); err != nil {
 		return err
 	}
-	srvCfg := &serverConfigV9{}
+	srvCfg := &serverConfigV10{}
 	srvCfg.Version = globalMinioConfigVersion
 	srvCfg.rwMutex = &sync.RWMutex{}
 	qc, err := quick.New(srvCfg)
@@ -99,19 +116,27 @@ func initConfig() error {
 	if err := qc.Load(configFile); err != nil {
 		return err
 	}
+	// Decrypt secrets encrypted with MINIO_CONFIG_PASSPHRASE.
+	if err := srvCfg.decryptSecrets(globalConfigPassphrase); err != nil {
+		return err
+	}
 	// Save the loaded config globally.
 	serverConfig = srvCfg
 	// Set the version properly after the unmarshalled json is loaded.
 	serverConfig.Version = globalMinioConfigVersion
 
+	// Share config.json through etcd from now on.
+	if globalEtcd != nil {
+		return storeEtcdConfig()
+	}
 	return nil
 }
 
 // serverConfig server config.
-var serverConfig *serverConfigV9
+var serverConfig *serverConfigV10
 
 // GetVersion get current config version.
-func (s serverConfigV9) GetVersion() string {
+func (s serverConfigV10) GetVersion() string {
 	s.rwMutex.RLock()
 	defer s.rwMutex.RUnlock()
 	return s.Version
@@ -119,182 +144,213 @@ func (s serverConfigV9) GetVersion() string {
 
 /// Logger related.
 
-func (s *serverConfigV9) SetAMQPNotifyByID(accountID string, amqpn amqpNotify) {
+func (s *serverConfigV10) SetAMQPNotifyByID(accountID string, amqpn amqpNotify) {
 	s.rwMutex.Lock()
 	defer s.rwMutex.Unlock()
 	s.Notify.AMQP[accountID] = amqpn
 }
 
-func (s serverConfigV9) GetAMQP() map[string]amqpNotify {
+func (s serverConfigV10) GetAMQP() map[string]amqpNotify {
 	s.rwMutex.RLock()
 	defer s.rwMutex.RUnlock()
 	return s.Notify.AMQP
 }
 
 // GetAMQPNotify get current AMQP logger.
-func (s serverConfigV9) GetAMQPNotifyByID(accountID string) amqpNotify {
+func (s serverConfigV10) GetAMQPNotifyByID(accountID string) amqpNotify {
 	s.rwMutex.RLock()
 	defer s.rwMutex.RUnlock()
 	return s.Notify.AMQP[accountID]
 }
 
 //
-func (s *serverConfigV9) SetNATSNotifyByID(accountID string, natsn natsNotify) {
+func (s *serverConfigV10) SetNATSNotifyByID(accountID string, natsn natsNotify) {
 	s.rwMutex.Lock()
 	defer s.rwMutex.Unlock()
 	s.Notify.NATS[accountID] = natsn
 }
 
-func (s serverConfigV9) GetNATS() map[string]natsNotify {
+func (s serverConfigV10) GetNATS() map[string]natsNotify {
 	s.rwMutex.RLock()
 	defer s.rwMutex.RUnlock()
 	return s.Notify.NATS
 }
 
 // GetNATSNotify get current NATS logger.
-func (s serverConfigV9) GetNATSNotifyByID(accountID string) natsNotify {
+func (s serverConfigV10) GetNATSNotifyByID(accountID string) natsNotify {
 	s.rwMutex.RLock()
 	defer s.rwMutex.RUnlock()
 	return s.Notify.NATS[accountID]
 }
 
-func (s *serverConfigV9) SetElasticSearchNotifyByID(accountID string, esNotify elasticSearchNotify) {
+func (s *serverConfigV10) SetElasticSearchNotifyByID(accountID string, esNotify elasticSearchNotify) {
 	s.rwMutex.Lock()
 	defer s.rwMutex.Unlock()
 	s.Notify.ElasticSearch[accountID] = esNotify
 }
 
-func (s serverConfigV9) GetElasticSearch() map[string]elasticSearchNotify {
+func (s serverConfigV10) GetElasticSearch() map[string]elasticSearchNotify {
 	s.rwMutex.RLock()
 	defer s.rwMutex.RUnlock()
 	return s.Notify.ElasticSearch
 }
 
 // GetElasticSearchNotify get current ElasicSearch logger.
-func (s serverConfigV9) GetElasticSearchNotifyByID(accountID string) elasticSearchNotify {
+func (s serverConfigV10) GetElasticSearchNotifyByID(accountID string) elasticSearchNotify {
 	s.rwMutex.RLock()
 	defer s.rwMutex.RUnlock()
 	return s.Notify.ElasticSearch[accountID]
 }
 
-func (s *serverConfigV9) SetRedisNotifyByID(accountID string, rNotify redisNotify) {
+func (s *serverConfigV10) SetRedisNotifyByID(accountID string, rNotify redisNotify) {
 	s.rwMutex.Lock()
 	defer s.rwMutex.Unlock()
 	s.Notify.Redis[accountID] = rNotify
 }
 
-func (s serverConfigV9) GetRedis() map[string]redisNotify {
+func (s serverConfigV10) GetRedis() map[string]redisNotify {
 	s.rwMutex.RLock()
 	defer s.rwMutex.RUnlock()
 	return s.Notify.Redis
 }
 
 // GetRedisNotify get current Redis logger.
-func (s serverConfigV9) GetRedisNotifyByID(accountID string) redisNotify {
+func (s serverConfigV10) GetRedisNotifyByID(accountID string) redisNotify {
 	s.rwMutex.RLock()
 	defer s.rwMutex.RUnlock()
 	return s.Notify.Redis[accountID]
 }
 
-func (s *serverConfigV9) SetPostgreSQLNotifyByID(accountID string, pgn postgreSQLNotify) {
+func (s *serverConfigV10) SetPostgreSQLNotifyByID(accountID string, pgn postgreSQLNotify) {
 	s.rwMutex.Lock()
 	defer s.rwMutex.Unlock()
 	s.Notify.PostgreSQL[accountID] = pgn
 }
 
-func (s serverConfigV9) GetPostgreSQL() map[string]postgreSQLNotify {
+func (s serverConfigV10) GetPostgreSQL() map[string]postgreSQLNotify {
 	s.rwMutex.RLock()
 	defer s.rwMutex.RUnlock()
 	return s.Notify.PostgreSQL
 }
 
-func (s serverConfigV9) GetPostgreSQLNotifyByID(accountID string) postgreSQLNotify {
+func (s serverConfigV10) GetPostgreSQLNotifyByID(accountID string) postgreSQLNotify {
 	s.rwMutex.RLock()
 	defer s.rwMutex.RUnlock()
 	return s.Notify.PostgreSQL[accountID]
 }
 
 // SetFileLogger set new file logger.
-func (s *serverConfigV9) SetFileLogger(flogger fileLogger) {
+func (s *serverConfigV10) SetFileLogger(flogger fileLogger) {
 	s.rwMutex.Lock()
 	defer s.rwMutex.Unlock()
 	s.Logger.File = flogger
 }
 
 // GetFileLogger get current file logger.
-func (s serverConfigV9) GetFileLogger() fileLogger {
+func (s serverConfigV10) GetFileLogger() fileLogger {
 	s.rwMutex.RLock()
 	defer s.rwMutex.RUnlock()
 	return s.Logger.File
 }
 
 // SetConsoleLogger set new console logger.
-func (s *serverConfigV9) SetConsoleLogger(clogger consoleLogger) {
+func (s *serverConfigV10) SetConsoleLogger(clogger consoleLogger) {
 	s.rwMutex.Lock()
 	defer s.rwMutex.Unlock()
 	s.Logger.Console = clogger
 }
 
 // GetConsoleLogger get current console logger.
-func (s serverConfigV9) GetConsoleLogger() consoleLogger {
+func (s serverConfigV10) GetConsoleLogger() consoleLogger {
 	s.rwMutex.RLock()
 	defer s.rwMutex.RUnlock()
 	return s.Logger.Console
 }
 
 // SetSyslogLogger set new syslog logger.
-func (s *serverConfigV9) SetSyslogLogger(slogger syslogLogger) {
+func (s *serverConfigV10) SetSyslogLogger(slogger syslogLogger) {
 	s.rwMutex.Lock()
 	defer s.rwMutex.Unlock()
 	s.Logger.Syslog = slogger
 }
 
 // GetSyslogLogger get current syslog logger.
-func (s *serverConfigV9) GetSyslogLogger() syslogLogger {
+func (s *serverConfigV10) GetSyslogLogger() syslogLogger {
 	s.rwMutex.RLock()
 	defer s.rwMutex.RUnlock()
 	return s.Logger.Syslog
 }
 
+// SetHTTPLogger set new http logger.
+func (s *serverConfigV10) SetHTTPLogger(hlogger httpLogger) {
+	s.rwMutex.Lock()
+	defer s.rwMutex.Unlock()
+	s.Logger.HTTP = hlogger
+}
+
+// GetHTTPLogger get current http logger.
+func (s serverConfigV10) GetHTTPLogger() httpLogger {
+	s.rwMutex.RLock()
+	defer s.rwMutex.RUnlock()
+	return s.Logger.HTTP
+}
+
 // SetRegion set new region.
-func (s *serverConfigV9) SetRegion(region string) {
+func (s *serverConfigV10) SetRegion(region string) {
 	s.rwMutex.Lock()
 	defer s.rwMutex.Unlock()
 	s.Region = region
 }
 
 // GetRegion get current region.
-func (s serverConfigV9) GetRegion() string {
+func (s serverConfigV10) GetRegion() string {
 	s.rwMutex.RLock()
 	defer s.rwMutex.RUnlock()
 	return s.Region
 }
 
 // SetCredentials set new credentials.
-func (s *serverConfigV9) SetCredential(creds credential) {
+func (s *serverConfigV10) SetCredential(creds credential) {
 	s.rwMutex.Lock()
 	defer s.rwMutex.Unlock()
 	s.Credential = creds
 }
 
 // GetCredentials get current credentials.
-func (s serverConfigV9) GetCredential() credential {
+func (s serverConfigV10) GetCredential() credential {
 	s.rwMutex.RLock()
 	defer s.rwMutex.RUnlock()
 	return s.Credential
 }
 
 // Save config.
-func (s serverConfigV9) Save() error {
+func (s serverConfigV10) Save() error {
 	s.rwMutex.RLock()
 	defer s.rwMutex.RUnlock()
 
+	// Config is shared by all the servers through etcd.
+	if globalEtcd != nil {
+		value, err := s.etcdValue()
+		if err != nil {
+			return err
+		}
+		return globalEtcd.put(etcdConfigKey, value)
+	}
+
 	// get config file.
 	configFile, err := getConfigFile()
 	if err != nil {
 		return err
 	}
 
+	// Encrypt secrets if MINIO_CONFIG_PASSPHRASE is set, s is a copy
+	// so the secrets in memory stay in plaintext.
+	if globalConfigPassphrase != "" {
+		if err = s.encryptSecrets(globalConfigPassphrase); err != nil {
+			return err
+		}
+	}
+
 	// initialize quick.
 	qc, err := quick.New(&s)
 	if err != nil {
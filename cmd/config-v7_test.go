@@ -87,6 +87,15 @@ func TestServerConfig(t *testing.T) {
 		t.Errorf("Expecting syslog logger config %#v found %#v", syslogLogger{Enable: true}, sysLogCfg)
 	}
 
+	// Set new http logger.
+	serverConfig.SetHTTPLogger(httpLogger{
+		Enable: true,
+	})
+	httpLogCfg := serverConfig.GetHTTPLogger()
+	if !reflect.DeepEqual(httpLogCfg, httpLogger{Enable: true}) {
+		t.Errorf("Expecting http logger config %#v found %#v", httpLogger{Enable: true}, httpLogCfg)
+	}
+
 	// Match version.
 	if serverConfig.GetVersion() != globalMinioConfigVersion {
 		t.Errorf("Expecting version %s found %s", serverConfig.GetVersion(), globalMinioConfigVersion)
@@ -0,0 +1,76 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package cmd
+
+import (
+	"io/ioutil"
+	"os"
+	"strings"
+	"sync"
+)
+
+// Container runtimes minio may be running in.
+const (
+	containerRuntimeNone       = ""
+	containerRuntimeKubernetes = "kubernetes"
+	containerRuntimeDocker     = "docker"
+	containerRuntimePodman     = "podman"
+	containerRuntimeContainerd = "containerd"
+	containerRuntimeLXC        = "lxc"
+)
+
+var (
+	containerRuntimeOnce sync.Once
+	containerRuntime     string
+)
+
+// detectContainerRuntime - returns the container runtime given the
+// cgroups of the process, its environment and the marker files left
+// by the runtimes. Kubernetes is reported over the runtime it uses.
+func detectContainerRuntime(cgroup string, getenv func(string) string, isFile func(string) bool) string {
+	switch {
+	case getenv("KUBERNETES_SERVICE_HOST") != "" || strings.Contains(cgroup, "kubepods"):
+		return containerRuntimeKubernetes
+	case getenv("container") == "podman" || isFile("/run/.containerenv") || strings.Contains(cgroup, "libpod"):
+		return containerRuntimePodman
+	case isFile("/.dockerenv") || strings.Contains(cgroup, "docker"):
+		return containerRuntimeDocker
+	case strings.Contains(cgroup, "containerd"):
+		return containerRuntimeContainerd
+	case getenv("container") == "lxc" || strings.Contains(cgroup, "/lxc/") || strings.Contains(cgroup, "lxc.payload"):
+		return containerRuntimeLXC
+	}
+	return containerRuntimeNone
+}
+
+// getContainerRuntime - returns the container runtime minio is
+// running in, empty if none was detected.
+func getContainerRuntime() string {
+	containerRuntimeOnce.Do(func() {
+		// Missing on other operating systems than linux.
+		cgroup, _ := ioutil.ReadFile("/proc/self/cgroup")
+		containerRuntime = detectContainerRuntime(string(cgroup), os.Getenv, func(name string) bool {
+			_, err := os.Stat(name)
+			return err == nil
+		})
+	})
+	return containerRuntime
+}
+
+// isContainer - returns if minio is running in a container.
+func isContainer() bool {
+	return getContainerRuntime() != containerRuntimeNone
+}
@@ -0,0 +1,65 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package cmd
+
+import "testing"
+
+// Tests detection of the container runtimes.
+func TestDetectContainerRuntime(t *testing.T) {
+	testCases := []struct {
+		cgroup  string
+		env     map[string]string
+		files   []string
+		runtime string
+	}{
+		// Test case 1: not in a container.
+		{"12:cpu,cpuacct:/user.slice\n", nil, nil, containerRuntimeNone},
+		// Test case 2: docker, cgroup v1.
+		{"12:cpu,cpuacct:/docker/3f2b5c6a\n", nil, nil, containerRuntimeDocker},
+		// Test case 3: docker, cgroup v2.
+		{"0::/\n", nil, []string{"/.dockerenv"}, containerRuntimeDocker},
+		// Test case 4: kubernetes pod run by docker.
+		{"12:cpu,cpuacct:/kubepods/burstable/pod1/3f2b5c6a\n", nil, []string{"/.dockerenv"}, containerRuntimeKubernetes},
+		// Test case 5: kubernetes, from the service environment.
+		{"0::/\n", map[string]string{"KUBERNETES_SERVICE_HOST": "10.0.0.1"}, nil, containerRuntimeKubernetes},
+		// Test case 6: podman.
+		{"0::/\n", map[string]string{"container": "podman"}, []string{"/run/.containerenv"}, containerRuntimePodman},
+		// Test case 7: podman, from the cgroup.
+		{"1:name=systemd:/machine.slice/libpod-3f2b5c6a.scope\n", nil, nil, containerRuntimePodman},
+		// Test case 8: containerd.
+		{"12:pids:/system.slice/containerd.service/default/minio\n", nil, nil, containerRuntimeContainerd},
+		// Test case 9: lxc.
+		{"12:cpu,cpuacct:/lxc/minio\n", nil, nil, containerRuntimeLXC},
+		// Test case 10: lxc, from the environment.
+		{"0::/\n", map[string]string{"container": "lxc"}, nil, containerRuntimeLXC},
+	}
+	for i, testCase := range testCases {
+		getenv := func(key string) string {
+			return testCase.env[key]
+		}
+		isFile := func(name string) bool {
+			for _, file := range testCase.files {
+				if file == name {
+					return true
+				}
+			}
+			return false
+		}
+		if runtime := detectContainerRuntime(testCase.cgroup, getenv, isFile); runtime != testCase.runtime {
+			t.Errorf("Test %d: Expected %q, got %q", i+1, testCase.runtime, runtime)
+		}
+	}
+}
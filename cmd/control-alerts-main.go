@@ -0,0 +1,157 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package cmd
+
+import (
+	"fmt"
+	"net/url"
+	"path"
+	"sort"
+
+	"github.com/minio/cli"
+	"github.com/minio/mc/pkg/console"
+)
+
+var alertsFlags = []cli.Flag{
+	cli.StringFlag{
+		Name:  "set",
+		Usage: "Name of the rule to add or replace.",
+	},
+	cli.StringFlag{
+		Name:  "remove",
+		Usage: "Name of the rule to remove.",
+	},
+	cli.StringFlag{
+		Name:  "metric",
+		Usage: "Metric of the rule, one of 5xx-rate, auth-failures and egress.",
+	},
+	cli.Float64Flag{
+		Name:  "threshold",
+		Usage: "Value of the metric beyond which the rule fires.",
+	},
+	cli.StringFlag{
+		Name:  "bucket",
+		Usage: "Bucket of egress rules, all the buckets if not set.",
+	},
+	cli.StringFlag{
+		Name:  "target",
+		Usage: "Notification target ARN or webhook URL alerts are sent to.",
+	},
+}
+
+var alertsCmd = cli.Command{
+	Name:   "alerts",
+	Usage:  "List, add or remove alert rules on request metrics.",
+	Action: alertsControl,
+	Flags:  append(alertsFlags, globalFlags...),
+	CustomHelpTemplate: `NAME:
+  minio control {{.Name}} - {{.Usage}}
+
+USAGE:
+  minio control {{.Name}} [FLAGS] URL
+
+FLAGS:
+  {{range .Flags}}{{.}}
+  {{end}}
+DESCRIPTION:
+  Every node evaluates the alert rules on the requests it served in the
+  last minute. An alert is sent when a metric goes beyond the threshold
+  of a rule, and again once it is back under the threshold. Metrics are:
+     5xx-rate: Percentage of the requests answered with a 5xx status.
+     auth-failures: Number of failed authentications per minute.
+     egress: Number of bytes sent per minute for a bucket.
+
+  Alerts are sent to a notification target configured in config.json,
+  or POSTed as JSON to a webhook URL.
+
+EXAMPLES:
+  1. List alert rules.
+    $ minio control {{.Name}} http://localhost:9000/
+
+  2. Alert a webhook when more than 5% of the requests fail.
+    $ minio control {{.Name}} --set errors --metric 5xx-rate --threshold 5 --target https://alerts.example.com/minio http://localhost:9000/
+
+  3. Send an alert to an AMQP target when a bucket sends more than 1GiB per minute.
+    $ minio control {{.Name}} --set egress --metric egress --threshold 1073741824 --target arn:minio:sqs:us-east-1:1:amqp http://localhost:9000/
+
+  4. Remove an alert rule.
+    $ minio control {{.Name}} --remove errors http://localhost:9000/
+`,
+}
+
+// Returns printable alert rule.
+func getAlertRuleMsg(rule alertRule) string {
+	metric := rule.Metric
+	if rule.Bucket != "" {
+		metric += " of " + rule.Bucket
+	}
+	return fmt.Sprintf("%s: %s > %g to %s", rule.Name, metric, rule.Threshold, rule.Target)
+}
+
+// "minio control alerts" entry point.
+func alertsControl(c *cli.Context) {
+	if len(c.Args()) != 1 || (c.String("set") != "" && c.String("remove") != "") {
+		cli.ShowCommandHelpAndExit(c, "alerts", 1)
+	}
+
+	parsedURL, err := url.Parse(c.Args().Get(0))
+	fatalIf(err, "Unable to parse URL %s", c.Args().Get(0))
+
+	authCfg := &authConfig{
+		accessKey:   serverConfig.GetCredential().AccessKeyID,
+		secretKey:   serverConfig.GetCredential().SecretAccessKey,
+		secureConn:  parsedURL.Scheme == "https",
+		address:     parsedURL.Host,
+		path:        path.Join(reservedBucket, controlPath),
+		loginMethod: "Control.LoginHandler",
+	}
+	client := newAuthClient(authCfg)
+
+	args := &AlertRulesArgs{
+		GenericArgs: GenericArgs{Remote: true, IdempotencyKey: getUUID()},
+		Remove:      c.String("remove"),
+	}
+	if name := c.String("set"); name != "" {
+		args.Set = &alertRule{
+			Name:      name,
+			Metric:    c.String("metric"),
+			Bucket:    c.String("bucket"),
+			Threshold: c.Float64("threshold"),
+			Target:    c.String("target"),
+		}
+	}
+	reply := &AlertRulesReply{}
+	err = client.Call("Control.AlertRulesHandler", args, reply)
+	fatalIf(err, "Unable to update alert rules.")
+
+	// Unreachable nodes keep their previous rules until restarted.
+	var nodes []string
+	for node := range reply.Errors {
+		nodes = append(nodes, node)
+	}
+	sort.Strings(nodes)
+	for _, node := range nodes {
+		console.Println("Unable to load alert rules on " + node + ": " + reply.Errors[node])
+	}
+
+	if len(reply.Rules) == 0 {
+		console.Println("No alert rules.")
+		return
+	}
+	for _, rule := range reply.Rules {
+		console.Println(getAlertRuleMsg(rule))
+	}
+}
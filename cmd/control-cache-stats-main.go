@@ -0,0 +1,105 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package cmd
+
+import (
+	"fmt"
+	"net/url"
+	"path"
+	"sort"
+
+	humanize "github.com/dustin/go-humanize"
+	"github.com/minio/cli"
+	"github.com/minio/mc/pkg/console"
+)
+
+var cacheStatsCmd = cli.Command{
+	Name:   "cache-stats",
+	Usage:  "Show object cache statistics of all the nodes.",
+	Action: cacheStatsControl,
+	Flags:  globalFlags,
+	CustomHelpTemplate: `NAME:
+  minio control {{.Name}} - {{.Usage}}
+
+USAGE:
+  minio control {{.Name}} URL
+
+FLAGS:
+  {{range .Flags}}{{.}}
+  {{end}}
+DESCRIPTION:
+  Shows the size used, the number of entries, the hit ratio and the
+  number of evictions and rejections of the object cache of every node
+  since it was started.
+
+EXAMPLES:
+  1. Show object cache statistics of the cluster.
+    $ minio control {{.Name}} http://localhost:9000/
+`,
+}
+
+// "minio control cache-stats" entry point.
+func cacheStatsControl(c *cli.Context) {
+	if len(c.Args()) != 1 {
+		cli.ShowCommandHelpAndExit(c, "cache-stats", 1)
+	}
+
+	parsedURL, err := url.Parse(c.Args().Get(0))
+	fatalIf(err, "Unable to parse URL %s", c.Args().Get(0))
+
+	authCfg := &authConfig{
+		accessKey:   serverConfig.GetCredential().AccessKeyID,
+		secretKey:   serverConfig.GetCredential().SecretAccessKey,
+		secureConn:  parsedURL.Scheme == "https",
+		address:     parsedURL.Host,
+		path:        path.Join(reservedBucket, controlPath),
+		loginMethod: "Control.LoginHandler",
+	}
+	client := newAuthClient(authCfg)
+
+	args := &CacheStatsArgs{
+		GenericArgs: GenericArgs{Remote: true},
+	}
+	reply := &CacheStatsReply{}
+	err = client.Call("Control.CacheStatsHandler", args, reply)
+	fatalIf(err, "Unable to get object cache statistics.")
+
+	// Statistics of the unreachable nodes are left out.
+	var nodes []string
+	for node := range reply.Errors {
+		nodes = append(nodes, node)
+	}
+	sort.Strings(nodes)
+	for _, node := range nodes {
+		console.Println("Unable to get object cache statistics of " + node + ": " + reply.Errors[node])
+	}
+
+	nodes = nil
+	for node := range reply.Stats {
+		nodes = append(nodes, node)
+	}
+	sort.Strings(nodes)
+	for _, node := range nodes {
+		stats := reply.Stats[node]
+		if stats.MaxSize == 0 {
+			console.Println(node + ": object cache disabled")
+			continue
+		}
+		console.Println(fmt.Sprintf("%s: used %s of %s, entries %d, hit ratio %.2f%%, evictions %d, rejections %d",
+			node, humanize.IBytes(stats.CurrentSize), humanize.IBytes(stats.MaxSize), stats.Entries,
+			stats.HitRatio*100, stats.Evictions, stats.Rejections))
+	}
+}
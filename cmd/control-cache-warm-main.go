@@ -0,0 +1,159 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"fmt"
+	"net/url"
+	"path"
+
+	humanize "github.com/dustin/go-humanize"
+	"github.com/minio/cli"
+	"github.com/minio/mc/pkg/console"
+)
+
+var cacheWarmFlags = []cli.Flag{
+	cli.IntFlag{
+		Name:  "rate",
+		Value: 10,
+		Usage: "Maximum number of objects read per second by each node, 0 for no limit.",
+	},
+	cli.BoolFlag{
+		Name:  "first-block",
+		Usage: "Only read the first block of every object, to warm the disks.",
+	},
+	cli.BoolFlag{
+		Name:  "metadata",
+		Usage: "Only read the metadata of every object, to warm the disks.",
+	},
+}
+
+var cacheWarmCmd = cli.Command{
+	Name:   "cache-warm",
+	Usage:  "Populate the object cache of all the nodes with the objects of a bucket.",
+	Action: cacheWarmControl,
+	Flags:  append(cacheWarmFlags, globalFlags...),
+	CustomHelpTemplate: `NAME:
+  minio control {{.Name}} - {{.Usage}}
+
+USAGE:
+  minio control {{.Name}} [FLAGS] URL
+
+FLAGS:
+  {{range .Flags}}{{.}}
+  {{end}}
+DESCRIPTION:
+  Reads the objects of a bucket with a prefix on every node, so that
+  they are served from the object cache afterwards, e.g. before moving
+  traffic to a standby cluster. Objects are only cached if admitted by
+  MINIO_CACHE_MAX_OBJECT_SIZE and MINIO_CACHE_BUCKETS, and as long as
+  they fit in MINIO_CACHE_SIZE. Objects are read at a limited rate to
+  spare the disks serving requests.
+
+EXAMPLES:
+  1. Warm the object cache with all the objects of a bucket.
+    $ minio control {{.Name}} http://localhost:9000/songs
+
+  2. Warm the object cache with the objects of a prefix, 100 objects per second.
+    $ minio control {{.Name}} --rate 100 http://localhost:9000/songs/classical/
+
+  3. Only read the first block of the objects of a bucket.
+    $ minio control {{.Name}} --first-block http://localhost:9000/songs
+`,
+}
+
+// Returns printable progress of a cache warm up.
+func getCacheWarmMsg(reply CacheWarmReply) string {
+	return fmt.Sprintf("Warmed: %d | Read: %s | Failed: %d", reply.Warmed, humanize.IBytes(uint64(reply.Bytes)), len(reply.Failed))
+}
+
+// "minio control cache-warm" entry point.
+func cacheWarmControl(c *cli.Context) {
+	if len(c.Args()) != 1 {
+		cli.ShowCommandHelpAndExit(c, "cache-warm", 1)
+	}
+
+	parsedURL, err := url.Parse(c.Args().Get(0))
+	fatalIf(err, "Unable to parse URL %s", c.Args().Get(0))
+	bucketName, prefixName := urlPathSplit(parsedURL.Path)
+	if bucketName == "" {
+		cli.ShowCommandHelpAndExit(c, "cache-warm", 1)
+	}
+
+	mode := cacheWarmObject
+	switch {
+	case c.Bool("first-block") && c.Bool("metadata"):
+		fatalIf(errInvalidArgument, "--first-block cannot be used with --metadata.")
+	case c.Bool("first-block"):
+		mode = cacheWarmFirstBlock
+	case c.Bool("metadata"):
+		mode = cacheWarmMetadata
+	}
+
+	authCfg := &authConfig{
+		accessKey:   serverConfig.GetCredential().AccessKeyID,
+		secretKey:   serverConfig.GetCredential().SecretAccessKey,
+		secureConn:  parsedURL.Scheme == "https",
+		address:     parsedURL.Host,
+		path:        path.Join(reservedBucket, controlPath),
+		loginMethod: "Control.LoginHandler",
+	}
+	client := newAuthClient(authCfg)
+
+	// Warming stops on the servers when interrupted.
+	callID := getUUID()
+	stop := cancelOnInterrupt(client, callID)
+	defer stop()
+
+	total := CacheWarmReply{}
+	nodeErrs := make(map[string]string)
+	var marker string
+	for {
+		args := &CacheWarmArgs{
+			GenericArgs: GenericArgs{CallID: callID, Remote: true},
+			Bucket:      bucketName,
+			Prefix:      prefixName,
+			Marker:      marker,
+			Mode:        mode,
+			Rate:        c.Int("rate"),
+		}
+		reply := CacheWarmReply{}
+		err = client.Call("Control.CacheWarmHandler", args, &reply)
+		fatalIf(err, "Unable to warm the object cache.")
+
+		for _, failure := range reply.Failed {
+			console.Println(fmt.Sprintf("%s  %s  %s  %s", colorRed("FAILED"), failure.Node, path.Join(bucketName, failure.Object), failure.Error))
+		}
+		for node, nodeErr := range reply.Errors {
+			if _, ok := nodeErrs[node]; !ok {
+				console.Println(fmt.Sprintf("%s  %s  %s", colorRed("FAILED"), node, nodeErr))
+			}
+			nodeErrs[node] = nodeErr
+		}
+		total.Warmed += reply.Warmed
+		total.Bytes += reply.Bytes
+		total.Failed = append(total.Failed, reply.Failed...)
+		scanBar(getCacheWarmMsg(total))
+
+		if !reply.IsTruncated {
+			break
+		}
+		marker = reply.NextMarker
+	}
+	console.Println()
+	console.Println(getCacheWarmMsg(total))
+}
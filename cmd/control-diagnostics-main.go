@@ -0,0 +1,227 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/minio/cli"
+	"github.com/minio/mc/pkg/console"
+)
+
+var diagnosticsFlags = []cli.Flag{
+	cli.StringFlag{
+		Name:  "output",
+		Usage: "Path of the diagnostics archive, defaults to minio-diagnostics-<timestamp>.tar.gz.",
+	},
+	cli.StringFlag{
+		Name:  "upload",
+		Usage: "Opt-in to upload the diagnostics archive to the given URL.",
+	},
+}
+
+var diagnosticsCmd = cli.Command{
+	Name:   "diagnostics",
+	Usage:  "Collect sanitized diagnostics of the cluster for support.",
+	Action: diagnosticsControl,
+	Flags:  append(diagnosticsFlags, globalFlags...),
+	CustomHelpTemplate: `NAME:
+  minio control {{.Name}} - {{.Usage}}
+
+USAGE:
+  minio control {{.Name}} [FLAGS] URL
+
+FLAGS:
+  {{range .Flags}}{{.}}
+  {{end}}
+EXAMPLES:
+  1. Collect diagnostics from all the nodes into a local archive.
+    $ minio control {{.Name}} http://localhost:9000/
+
+  2. Collect diagnostics into a specific archive.
+    $ minio control {{.Name}} --output /tmp/diag.tar.gz http://localhost:9000/
+
+  3. Collect diagnostics and upload the archive, nothing is uploaded without this flag.
+    $ minio control {{.Name}} --upload https://support.example.com/upload http://localhost:9000/
+`,
+}
+
+// Returns a node name usable as a directory name inside an archive.
+func nodeArchiveDir(node string) string {
+	node = strings.TrimPrefix(node, ":")
+	if node == "" {
+		node = "localhost"
+	}
+	return strings.Replace(node, ":", "_", -1)
+}
+
+// Writes a single file entry to the tar archive.
+func writeTarEntry(tw *tar.Writer, name string, data []byte) error {
+	hdr := &tar.Header{
+		Name:    name,
+		Mode:    0600,
+		Size:    int64(len(data)),
+		ModTime: time.Now().UTC(),
+	}
+	if err := tw.WriteHeader(hdr); err != nil {
+		return err
+	}
+	_, err := tw.Write(data)
+	return err
+}
+
+// writeDiagnosticsArchive - writes all the collected diagnostics as a
+// gzipped tar archive, one directory per node.
+func writeDiagnosticsArchive(w io.Writer, diags map[string]DiagnosticsReply, lkStateRep map[string]SystemLockState) error {
+	gw := gzip.NewWriter(w)
+	tw := tar.NewWriter(gw)
+	for node, diag := range diags {
+		dir := nodeArchiveDir(node)
+		// Only the error is known of unreachable nodes.
+		if diag.Error != "" {
+			if err := writeTarEntry(tw, path.Join(dir, "error.txt"), []byte(diag.Error)); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := writeTarEntry(tw, path.Join(dir, "config.json"), diag.Config); err != nil {
+			return err
+		}
+		errLog := strings.Join(diag.RecentErrors, "\n")
+		if err := writeTarEntry(tw, path.Join(dir, "errors.log"), []byte(errLog)); err != nil {
+			return err
+		}
+		// Config and errors are already saved separately.
+		diag.Config = nil
+		diag.RecentErrors = nil
+		infoBytes, err := json.MarshalIndent(diag, "", "\t")
+		if err != nil {
+			return err
+		}
+		if err = writeTarEntry(tw, path.Join(dir, "info.json"), infoBytes); err != nil {
+			return err
+		}
+	}
+	lockBytes, err := json.MarshalIndent(lkStateRep, "", "\t")
+	if err != nil {
+		return err
+	}
+	if err = writeTarEntry(tw, "locks.json", lockBytes); err != nil {
+		return err
+	}
+	if err = tw.Close(); err != nil {
+		return err
+	}
+	return gw.Close()
+}
+
+// uploadDiagnosticsArchive - uploads the archive to the given URL.
+func uploadDiagnosticsArchive(uploadURL, archivePath string) error {
+	file, err := os.Open(archivePath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	st, err := file.Stat()
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest("PUT", uploadURL, file)
+	if err != nil {
+		return err
+	}
+	req.ContentLength = st.Size()
+	req.Header.Set("Content-Type", "application/gzip")
+	req.Header.Set("User-Agent", "Minio ("+Version+")")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("Upload failed with %s", resp.Status)
+	}
+	return nil
+}
+
+// "minio control diagnostics" entry point.
+func diagnosticsControl(c *cli.Context) {
+	if len(c.Args()) != 1 {
+		cli.ShowCommandHelpAndExit(c, "diagnostics", 1)
+	}
+
+	parsedURL, err := url.Parse(c.Args().Get(0))
+	fatalIf(err, "Unable to parse URL %s", c.Args().Get(0))
+
+	authCfg := &authConfig{
+		accessKey:   serverConfig.GetCredential().AccessKeyID,
+		secretKey:   serverConfig.GetCredential().SecretAccessKey,
+		secureConn:  parsedURL.Scheme == "https",
+		address:     parsedURL.Host,
+		path:        path.Join(reservedBucket, controlPath),
+		loginMethod: "Control.LoginHandler",
+	}
+	client := newAuthClient(authCfg)
+
+	args := &GenericArgs{
+		// This is necessary so that the remotes,
+		// don't end up sending requests back and forth.
+		Remote: true,
+	}
+	diags := make(map[string]DiagnosticsReply)
+	err = client.Call("Control.Diagnostics", args, &diags)
+	fatalIf(err, "Unable to collect diagnostics from %s", parsedURL.Host)
+	for node, diag := range diags {
+		if diag.Error != "" {
+			console.Println("Unable to collect diagnostics of " + node + ": " + diag.Error)
+		}
+	}
+
+	args = &GenericArgs{Remote: true}
+	lkStateRep := make(map[string]SystemLockState)
+	err = client.Call("Control.LockInfo", args, &lkStateRep)
+	fatalIf(err, "Unable to fetch system lockInfo.")
+
+	archivePath := c.String("output")
+	if archivePath == "" {
+		archivePath = fmt.Sprintf("minio-diagnostics-%s.tar.gz", time.Now().UTC().Format("20060102T150405Z"))
+	}
+	file, err := os.OpenFile(archivePath, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0600)
+	fatalIf(err, "Unable to create diagnostics archive %s", archivePath)
+	err = writeDiagnosticsArchive(file, diags, lkStateRep)
+	file.Close()
+	fatalIf(err, "Unable to write diagnostics archive %s", archivePath)
+	console.Println("Diagnostics saved to " + archivePath)
+
+	// Upload only when explicitly asked for.
+	if uploadURL := c.String("upload"); uploadURL != "" {
+		err = uploadDiagnosticsArchive(uploadURL, archivePath)
+		fatalIf(err, "Unable to upload diagnostics archive to %s", uploadURL)
+		console.Println("Diagnostics uploaded to " + uploadURL)
+	}
+}
@@ -0,0 +1,109 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"fmt"
+	"net/url"
+	"path"
+	"sort"
+
+	"github.com/minio/cli"
+	"github.com/minio/mc/pkg/console"
+)
+
+var eventStatsCmd = cli.Command{
+	Name:   "event-stats",
+	Usage:  "Show event notification statistics of a bucket.",
+	Action: eventStatsControl,
+	Flags:  globalFlags,
+	CustomHelpTemplate: `NAME:
+  minio control {{.Name}} - {{.Usage}}
+
+USAGE:
+  minio control {{.Name}} URL/BUCKET
+
+FLAGS:
+  {{range .Flags}}{{.}}
+  {{end}}
+DESCRIPTION:
+  Shows the number of events generated, delivered, failed and pending
+  for every target ARN of the bucket, summed up over all the nodes since
+  they were started.
+
+EXAMPLES:
+  1. Show event statistics of bucket 'photos'.
+    $ minio control {{.Name}} http://localhost:9000/photos
+`,
+}
+
+// "minio control event-stats" entry point.
+func eventStatsControl(c *cli.Context) {
+	if len(c.Args()) != 1 {
+		cli.ShowCommandHelpAndExit(c, "event-stats", 1)
+	}
+
+	parsedURL, err := url.Parse(c.Args().Get(0))
+	fatalIf(err, "Unable to parse URL %s", c.Args().Get(0))
+	bucketName, objectName := urlPathSplit(parsedURL.Path)
+	if bucketName == "" || objectName != "" {
+		cli.ShowCommandHelpAndExit(c, "event-stats", 1)
+	}
+
+	authCfg := &authConfig{
+		accessKey:   serverConfig.GetCredential().AccessKeyID,
+		secretKey:   serverConfig.GetCredential().SecretAccessKey,
+		secureConn:  parsedURL.Scheme == "https",
+		address:     parsedURL.Host,
+		path:        path.Join(reservedBucket, controlPath),
+		loginMethod: "Control.LoginHandler",
+	}
+	client := newAuthClient(authCfg)
+
+	args := &EventStatsArgs{
+		GenericArgs: GenericArgs{Remote: true},
+		Bucket:      bucketName,
+	}
+	reply := &EventStatsReply{}
+	err = client.Call("Control.EventStatsHandler", args, reply)
+	fatalIf(err, "Unable to get event statistics of bucket %s.", bucketName)
+
+	// Statistics of the unreachable nodes are left out.
+	var nodes []string
+	for node := range reply.Errors {
+		nodes = append(nodes, node)
+	}
+	sort.Strings(nodes)
+	for _, node := range nodes {
+		console.Println("Unable to get event statistics of " + node + ": " + reply.Errors[node])
+	}
+
+	if len(reply.Stats) == 0 {
+		console.Println("No events sent for bucket " + bucketName + ".")
+		return
+	}
+	var arns []string
+	for arn := range reply.Stats {
+		arns = append(arns, arn)
+	}
+	sort.Strings(arns)
+	for _, arn := range arns {
+		stats := reply.Stats[arn]
+		console.Println(fmt.Sprintf("%s: generated %d, delivered %d, failed %d, pending %d",
+			arn, stats.Generated, stats.Delivered, stats.Failed, stats.Pending))
+	}
+}
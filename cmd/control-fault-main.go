@@ -0,0 +1,114 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"fmt"
+	"net/url"
+	"path"
+
+	"github.com/minio/cli"
+	"github.com/minio/mc/pkg/console"
+)
+
+var faultFlags = []cli.Flag{
+	cli.IntFlag{
+		Name:  "disk-write-drop",
+		Usage: "Percentage of disk writes to fail.",
+	},
+	cli.IntFlag{
+		Name:  "disk-read-corrupt",
+		Usage: "Percentage of disk reads to return corrupted data.",
+	},
+	cli.DurationFlag{
+		Name:  "rpc-delay",
+		Usage: "Delay added to every outgoing RPC call, upto 1m.",
+	},
+}
+
+var faultCmd = cli.Command{
+	Name:   "fault",
+	Usage:  "Inject storage and RPC faults on a server for chaos testing.",
+	Action: faultControl,
+	Flags:  append(faultFlags, globalFlags...),
+	CustomHelpTemplate: `NAME:
+  minio control {{.Name}} - {{.Usage}}
+
+USAGE:
+  minio control {{.Name}} [FLAGS] URL
+
+FLAGS:
+  {{range .Flags}}{{.}}
+  {{end}}
+DESCRIPTION:
+  Faults replace any previously injected faults and apply only to the
+  server at URL, which must be started with MINIO_FAULT_INJECTION=on.
+
+EXAMPLES:
+  1. Fail 10% of the disk writes and delay RPC calls by 200ms.
+    $ minio control {{.Name}} --disk-write-drop 10 --rpc-delay 200ms http://localhost:9000/
+
+  2. Return corrupted data for 5% of the disk reads.
+    $ minio control {{.Name}} --disk-read-corrupt 5 http://localhost:9000/
+
+  3. Clear all injected faults.
+    $ minio control {{.Name}} http://localhost:9000/
+`,
+}
+
+// Returns printable fault injection message.
+func getFaultMsg(faults faultConfig) string {
+	msg := fmt.Sprintf("Disk write drop: %d%%\n", faults.DiskWriteDrop)
+	msg += fmt.Sprintf("Disk read corrupt: %d%%\n", faults.DiskReadCorrupt)
+	msg += fmt.Sprintf("RPC delay: %s", faults.RPCDelay)
+	return msg
+}
+
+// "minio control fault" entry point.
+func faultControl(c *cli.Context) {
+	if len(c.Args()) != 1 {
+		cli.ShowCommandHelpAndExit(c, "fault", 1)
+	}
+
+	parsedURL, err := url.Parse(c.Args().Get(0))
+	fatalIf(err, "Unable to parse URL %s", c.Args().Get(0))
+
+	args := &FaultInjectionArgs{
+		GenericArgs: GenericArgs{IdempotencyKey: getUUID()},
+		Faults: faultConfig{
+			DiskWriteDrop:   c.Int("disk-write-drop"),
+			DiskReadCorrupt: c.Int("disk-read-corrupt"),
+			RPCDelay:        c.Duration("rpc-delay"),
+		},
+	}
+	fatalIf(args.Faults.validate(), "Invalid faults, rates must be between 0 and 100 and RPC delay cannot exceed %s.", maxFaultRPCDelay)
+
+	authCfg := &authConfig{
+		accessKey:   serverConfig.GetCredential().AccessKeyID,
+		secretKey:   serverConfig.GetCredential().SecretAccessKey,
+		secureConn:  parsedURL.Scheme == "https",
+		address:     parsedURL.Host,
+		path:        path.Join(reservedBucket, controlPath),
+		loginMethod: "Control.LoginHandler",
+	}
+	client := newAuthClient(authCfg)
+
+	reply := FaultInjectionReply{}
+	err = client.Call("Control.FaultInjectionHandler", args, &reply)
+	fatalIf(err, "Unable to inject faults on %s", parsedURL.Host)
+	console.Println(getFaultMsg(reply.Faults))
+}
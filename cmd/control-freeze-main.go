@@ -0,0 +1,147 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"fmt"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/minio/cli"
+	"github.com/minio/mc/pkg/console"
+)
+
+var freezeFlags = []cli.Flag{
+	cli.DurationFlag{
+		Name:  "duration",
+		Value: defaultFreezeDuration,
+		Usage: "Duration after which writes are thawed automatically, upto 5m.",
+	},
+}
+
+var freezeCmd = cli.Command{
+	Name:   "freeze",
+	Usage:  "Freeze all writes in the cluster to take consistent snapshots of the drives.",
+	Action: freezeControl,
+	Flags:  append(freezeFlags, globalFlags...),
+	CustomHelpTemplate: `NAME:
+  minio control {{.Name}} - {{.Usage}}
+
+USAGE:
+  minio control {{.Name}} [FLAGS] URL
+
+FLAGS:
+  {{range .Flags}}{{.}}
+  {{end}}
+EXAMPLES:
+  1. Freeze all writes in the cluster for upto 30 seconds.
+    $ minio control {{.Name}} http://localhost:9000/
+
+  2. Freeze all writes in the cluster for upto 2 minutes.
+    $ minio control {{.Name}} --duration 2m http://localhost:9000/
+`,
+}
+
+var thawCmd = cli.Command{
+	Name:   "thaw",
+	Usage:  "Thaw all writes frozen by 'minio control freeze'.",
+	Action: thawControl,
+	Flags:  globalFlags,
+	CustomHelpTemplate: `NAME:
+  minio control {{.Name}} - {{.Usage}}
+
+USAGE:
+  minio control {{.Name}} FREEZE-ID URL
+
+FLAGS:
+  {{range .Flags}}{{.}}
+  {{end}}
+EXAMPLES:
+  1. Thaw all writes frozen with freeze ID 'f2b4c3c9-8f5a-4ae5-a0a5-1d8c3e4f7f2d'.
+    $ minio control {{.Name}} f2b4c3c9-8f5a-4ae5-a0a5-1d8c3e4f7f2d http://localhost:9000/
+`,
+}
+
+// Returns a new control client for URL.
+func newFreezeControlClient(urlStr string) *AuthRPCClient {
+	parsedURL, err := url.Parse(urlStr)
+	fatalIf(err, "Unable to parse URL %s", urlStr)
+
+	authCfg := &authConfig{
+		accessKey:   serverConfig.GetCredential().AccessKeyID,
+		secretKey:   serverConfig.GetCredential().SecretAccessKey,
+		secureConn:  parsedURL.Scheme == "https",
+		address:     parsedURL.Host,
+		path:        path.Join(reservedBucket, controlPath),
+		loginMethod: "Control.LoginHandler",
+	}
+	return newAuthClient(authCfg)
+}
+
+// Returns printable freeze message.
+func getFreezeMsg(reply FreezeReply) string {
+	msg := fmt.Sprintf("Freeze ID: %s\n", reply.FreezeID)
+	msg += fmt.Sprintf("Frozen at: %s\n", reply.FrozenAt.Format(time.RFC3339Nano))
+	msg += fmt.Sprintf("Expires at: %s\n", reply.ExpiresAt.Format(time.RFC3339Nano))
+	msg += fmt.Sprintf("Nodes: %s\n", strings.Join(reply.Nodes, ", "))
+	msg += fmt.Sprintf("Buckets: %s", strings.Join(reply.Buckets, ", "))
+	return msg
+}
+
+// "minio control freeze" entry point.
+func freezeControl(c *cli.Context) {
+	if len(c.Args()) != 1 {
+		cli.ShowCommandHelpAndExit(c, "freeze", 1)
+	}
+	if c.Duration("duration") > maxFreezeDuration {
+		fatalIf(errInvalidArgument, "Freeze duration cannot exceed %s", maxFreezeDuration)
+	}
+
+	client := newFreezeControlClient(c.Args().Get(0))
+	args := &FreezeArgs{
+		GenericArgs: GenericArgs{IdempotencyKey: getUUID()},
+		Duration:    c.Duration("duration"),
+	}
+	// This is necessary so that the remotes,
+	// don't end up sending requests back and forth.
+	args.Remote = true
+	reply := FreezeReply{}
+	err := client.Call("Control.FreezeHandler", args, &reply)
+	fatalIf(err, "Unable to freeze writes.")
+	console.Println(getFreezeMsg(reply))
+}
+
+// "minio control thaw" entry point.
+func thawControl(c *cli.Context) {
+	if len(c.Args()) != 2 {
+		cli.ShowCommandHelpAndExit(c, "thaw", 1)
+	}
+
+	client := newFreezeControlClient(c.Args().Get(1))
+	args := &FreezeArgs{
+		GenericArgs: GenericArgs{IdempotencyKey: getUUID()},
+		FreezeID:    c.Args().Get(0),
+	}
+	// This is necessary so that the remotes,
+	// don't end up sending requests back and forth.
+	args.Remote = true
+	err := client.Call("Control.ThawHandler", args, &FreezeReply{})
+	fatalIf(err, "Unable to thaw writes for freeze ID %s.", args.FreezeID)
+	console.Println("Writes thawed for freeze ID " + args.FreezeID)
+}
@@ -18,7 +18,6 @@ package cmd
 
 import (
 	"errors"
-	"sync"
 	"time"
 )
 
@@ -34,7 +33,9 @@ var errServerTimeMismatch = errors.New("Server times are too far apart.")
 /// Auth operations
 
 // Login - login handler.
-func (c *controlAPIHandlers) LoginHandler(args *RPCLoginArgs, reply *RPCLoginReply) error {
+func (c *controlAPIHandlers) LoginHandler(args *RPCLoginArgs, reply *RPCLoginReply) (err error) {
+	defer encodeRPCError(&err)
+
 	jwt, err := newJWT(defaultInterNodeJWTExpiry)
 	if err != nil {
 		return err
@@ -42,7 +43,7 @@ func (c *controlAPIHandlers) LoginHandler(args *RPCLoginArgs, reply *RPCLoginRep
 	if err = jwt.Authenticate(args.Username, args.Password); err != nil {
 		return err
 	}
-	token, err := jwt.GenerateToken(args.Username)
+	token, err := jwt.GenerateToken(args.Username, jwtAudienceAdmin)
 	if err != nil {
 		return err
 	}
@@ -72,17 +73,22 @@ type HealListReply struct {
 }
 
 // ListObjects - list all objects that needs healing.
-func (c *controlAPIHandlers) ListObjectsHealHandler(args *HealListArgs, reply *HealListReply) error {
+func (c *controlAPIHandlers) ListObjectsHealHandler(args *HealListArgs, reply *HealListReply) (err error) {
+	defer encodeRPCError(&err)
+
 	objAPI := c.ObjectAPI()
 	if objAPI == nil {
 		return errServerNotInitialized
 	}
-	if !isRPCTokenValid(args.Token) {
+	if !isHealTokenValid(args.Token) {
 		return errInvalidToken
 	}
 	if !c.IsXL {
 		return nil
 	}
+	if args.isCancelled() {
+		return errRPCCancelled
+	}
 	info, err := objAPI.ListObjectsHeal(args.Bucket, args.Prefix, args.Marker, args.Delimiter, args.MaxKeys)
 	if err != nil {
 		return err
@@ -103,14 +109,22 @@ type HealBucketArgs struct {
 }
 
 // Heals missing buckets across disks, if we have enough quorum.
-func (c *controlAPIHandlers) HealBucketHandler(args *HealBucketArgs, reply *GenericReply) error {
+func (c *controlAPIHandlers) HealBucketHandler(args *HealBucketArgs, reply *GenericReply) (err error) {
+	defer encodeRPCError(&err)
+
 	objAPI := c.ObjectAPI()
 	if objAPI == nil {
 		return errServerNotInitialized
 	}
-	if !isRPCTokenValid(args.Token) {
+	if !isHealTokenValid(args.Token) {
 		return errInvalidToken
 	}
+	// Retries get the result of the first call.
+	if replayed, replayErr := globalIdempotentCalls.Begin("Control.HealBucketHandler", args.IdempotencyKey, reply); replayed {
+		return replayErr
+	}
+	defer globalIdempotentCalls.End("Control.HealBucketHandler", args.IdempotencyKey, reply, &err)
+
 	if !c.IsXL {
 		return nil
 	}
@@ -137,21 +151,34 @@ type HealObjectReply struct {
 }
 
 // HealObject heals 1000 objects at a time for missing chunks, missing metadata on a given bucket.
-func (c *controlAPIHandlers) HealObjectsHandler(args *HealObjectArgs, reply *HealObjectReply) error {
+func (c *controlAPIHandlers) HealObjectsHandler(args *HealObjectArgs, reply *HealObjectReply) (err error) {
+	defer encodeRPCError(&err)
+
 	objAPI := c.ObjectAPI()
 	if objAPI == nil {
 		return errServerNotInitialized
 	}
-	if !isRPCTokenValid(args.Token) {
+	if !isHealTokenValid(args.Token) {
 		return errInvalidToken
 	}
+	// Retries get the result of the first call.
+	if replayed, replayErr := globalIdempotentCalls.Begin("Control.HealObjectsHandler", args.IdempotencyKey, reply); replayed {
+		return replayErr
+	}
+	defer globalIdempotentCalls.End("Control.HealObjectsHandler", args.IdempotencyKey, reply, &err)
+
 	if !c.IsXL {
 		return nil
 	}
 
-	// Heal all objects that need healing.
+	// Heal all objects that need healing, the objects left once the
+	// call is cancelled are reported as such.
 	var errs = make([]error, len(args.Objects))
 	for idx, objInfo := range args.Objects {
+		if args.isCancelled() {
+			errs[idx] = errRPCCancelled
+			continue
+		}
 		errs[idx] = objAPI.HealObject(args.Bucket, objInfo.Name)
 	}
 
@@ -169,14 +196,22 @@ func (c *controlAPIHandlers) HealObjectsHandler(args *HealObjectArgs, reply *Hea
 }
 
 // Heals backend storage format.
-func (c *controlAPIHandlers) HealFormatHandler(args *GenericArgs, reply *GenericReply) error {
-	if !isRPCTokenValid(args.Token) {
+func (c *controlAPIHandlers) HealFormatHandler(args *GenericArgs, reply *GenericReply) (err error) {
+	defer encodeRPCError(&err)
+
+	if !isHealTokenValid(args.Token) {
 		return errInvalidToken
 	}
+	// Retries get the result of the first call.
+	if replayed, replayErr := globalIdempotentCalls.Begin("Control.HealFormatHandler", args.IdempotencyKey, reply); replayed {
+		return replayErr
+	}
+	defer globalIdempotentCalls.End("Control.HealFormatHandler", args.IdempotencyKey, reply, &err)
+
 	if !c.IsXL {
 		return nil
 	}
-	err := healFormatXL(c.StorageDisks)
+	err = healFormatXL(c.StorageDisks)
 	if err != nil {
 		return err
 	}
@@ -202,33 +237,19 @@ type ServiceReply struct {
 	StorageInfo StorageInfo
 }
 
-// Remote procedure call, calls serviceMethod with given input args.
-func (c *controlAPIHandlers) remoteServiceCall(args *ServiceArgs, replies []*ServiceReply) error {
-	var wg sync.WaitGroup
-	var errs = make([]error, len(c.RemoteControls))
-	// Send remote call to all neighboring peers to restart minio servers.
-	for index, clnt := range c.RemoteControls {
-		wg.Add(1)
-		go func(index int, client *AuthRPCClient) {
-			defer wg.Done()
-			errs[index] = client.Call("Control.ServiceHandler", args, replies[index])
-			errorIf(errs[index], "Unable to initiate control service request to remote node %s", client.Node())
-		}(index, clnt)
-	}
-	wg.Wait()
-	for _, err := range errs {
-		if err != nil {
-			return err
-		}
-	}
-	return nil
-}
-
 // Service - handler for sending service signals across many servers.
-func (c *controlAPIHandlers) ServiceHandler(args *ServiceArgs, reply *ServiceReply) error {
-	if !isRPCTokenValid(args.Token) {
+func (c *controlAPIHandlers) ServiceHandler(args *ServiceArgs, reply *ServiceReply) (err error) {
+	defer encodeRPCError(&err)
+
+	if !isRPCTokenValid(args.Token, jwtAudienceAdmin) {
 		return errInvalidToken
 	}
+	// Retries get the result of the first call.
+	if replayed, replayErr := globalIdempotentCalls.Begin("Control.ServiceHandler", args.IdempotencyKey, reply); replayed {
+		return replayErr
+	}
+	defer globalIdempotentCalls.End("Control.ServiceHandler", args.IdempotencyKey, reply, &err)
+
 	objAPI := c.ObjectAPI()
 	if objAPI == nil {
 		return errServerNotInitialized
@@ -237,13 +258,18 @@ func (c *controlAPIHandlers) ServiceHandler(args *ServiceArgs, reply *ServiceRep
 		reply.StorageInfo = objAPI.StorageInfo()
 		return nil
 	}
-	var replies = make([]*ServiceReply, len(c.RemoteControls))
+	remoteControls := c.getRemoteControls()
+	var replies = make([]ServiceReply, len(remoteControls))
+	serviceReply := func(index int) interface{} {
+		return &replies[index]
+	}
 	switch args.Signal {
 	case serviceRestart:
 		if args.Remote {
 			// Set remote as false for remote calls.
 			args.Remote = false
-			if err := c.remoteServiceCall(args, replies); err != nil {
+			errsMap := callRemoteControls(remoteControls, "Control.ServiceHandler", args, serviceReply)
+			if err := nodeErrorsToError(errsMap); err != nil {
 				return err
 			}
 		}
@@ -252,7 +278,8 @@ func (c *controlAPIHandlers) ServiceHandler(args *ServiceArgs, reply *ServiceRep
 		if args.Remote {
 			// Set remote as false for remote calls.
 			args.Remote = false
-			if err := c.remoteServiceCall(args, replies); err != nil {
+			errsMap := callRemoteControls(remoteControls, "Control.ServiceHandler", args, serviceReply)
+			if err := nodeErrorsToError(errsMap); err != nil {
 				return err
 			}
 		}
@@ -262,8 +289,10 @@ func (c *controlAPIHandlers) ServiceHandler(args *ServiceArgs, reply *ServiceRep
 }
 
 // LockInfo - RPC control handler for `minio control lock`. Returns the info of the locks held in the system.
-func (c *controlAPIHandlers) TryInitHandler(args *GenericArgs, reply *GenericReply) error {
-	if !isRPCTokenValid(args.Token) {
+func (c *controlAPIHandlers) TryInitHandler(args *GenericArgs, reply *GenericReply) (err error) {
+	defer encodeRPCError(&err)
+
+	if !isRPCTokenValid(args.Token, jwtAudienceAdmin) {
 		return errInvalidToken
 	}
 	if !c.IsXL {
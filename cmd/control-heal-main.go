@@ -20,6 +20,7 @@ import (
 	"errors"
 	"fmt"
 	"net/url"
+	"os"
 	"path"
 
 	"github.com/minio/cli"
@@ -40,6 +41,8 @@ USAGE:
 FLAGS:
   {{range .Flags}}{{.}}
   {{end}}
+ENVIRONMENT VARIABLES:
+  MINIO_HEAL_TOKEN: Heal token printed by a server waiting for heal, used instead of the credentials.
 
 EXAMPLES:
   1. Heal missing on-disk format across all inconsistent nodes.
@@ -60,7 +63,7 @@ EXAMPLES:
 // fresh or corrupted disks.  This call does deep inspection of backend layout
 // and applies appropriate `format.json` to the disk.
 func healStorageFormat(authClnt *AuthRPCClient) error {
-	args := &GenericArgs{}
+	args := &GenericArgs{IdempotencyKey: getUUID()}
 	reply := &GenericReply{}
 	return authClnt.Call("Control.HealFormatHandler", args, reply)
 }
@@ -68,13 +71,14 @@ func healStorageFormat(authClnt *AuthRPCClient) error {
 // lists all objects which needs to be healed, this is a precursor helper function called before
 // calling actual healing operation. Returns a maximum of 1000 objects that needs healing at a time.
 // Marker indicates the next entry point where the listing will start.
-func listObjectsHeal(authClnt *AuthRPCClient, bucketName, prefixName, markerName string) (*HealListReply, error) {
+func listObjectsHeal(authClnt *AuthRPCClient, callID, bucketName, prefixName, markerName string) (*HealListReply, error) {
 	args := &HealListArgs{
-		Bucket:    bucketName,
-		Prefix:    prefixName,
-		Marker:    markerName,
-		Delimiter: "",
-		MaxKeys:   1000,
+		GenericArgs: GenericArgs{CallID: callID},
+		Bucket:      bucketName,
+		Prefix:      prefixName,
+		Marker:      markerName,
+		Delimiter:   "",
+		MaxKeys:     1000,
 	}
 	reply := &HealListReply{}
 	err := authClnt.Call("Control.ListObjectsHealHandler", args, reply)
@@ -101,10 +105,9 @@ func prettyHealResults(healedObjects []ObjectInfo, healReply *HealObjectReply) <
 		// Go through all the results and validate if we have success or failure.
 		for i, healStr := range healReply.Results {
 			objPath := path.Join(healedObjects[i].Bucket, healedObjects[i].Name)
-			// TODO: We need to still print heal error cause.
 			if healStr != "" {
 				msgCh <- healMsg{
-					Msg: fmt.Sprintf("%s  %s", colorRed("FAILED"), objPath),
+					Msg: fmt.Sprintf("%s  %s  %s", colorRed("FAILED"), objPath, healStr),
 					Err: errors.New(healStr),
 				}
 				continue
@@ -122,36 +125,43 @@ func prettyHealResults(healedObjects []ObjectInfo, healReply *HealObjectReply) <
 var scanBar = scanBarFactory()
 
 // Heals all the objects under a given bucket, optionally you can specify an
-// object prefix to heal objects under this prefix.
-func healObjects(authClnt *AuthRPCClient, bucketName, prefixName string) error {
+// object prefix to heal objects under this prefix. Objects which fail to
+// heal do not stop healing the others, their count is returned.
+func healObjects(authClnt *AuthRPCClient, bucketName, prefixName string) (failed int, err error) {
 	if authClnt == nil || bucketName == "" {
-		return errInvalidArgument
+		return 0, errInvalidArgument
 	}
+	// Healing stops on the server when interrupted.
+	callID := getUUID()
+	stop := cancelOnInterrupt(authClnt, callID)
+	defer stop()
+
 	// Save marker for the next request.
 	var markerName string
 	for {
-		healListReply, err := listObjectsHeal(authClnt, bucketName, prefixName, markerName)
+		healListReply, err := listObjectsHeal(authClnt, callID, bucketName, prefixName, markerName)
 		if err != nil {
-			return err
+			return failed, err
 		}
 
 		// Attempt to heal only if there are any objects to heal.
 		if len(healListReply.Objects) > 0 {
 			healArgs := &HealObjectArgs{
-				Bucket:  bucketName,
-				Objects: healListReply.Objects,
+				GenericArgs: GenericArgs{CallID: callID, IdempotencyKey: getUUID()},
+				Bucket:      bucketName,
+				Objects:     healListReply.Objects,
 			}
 
 			healReply := &HealObjectReply{}
 			err = authClnt.Call("Control.HealObjectsHandler", healArgs, healReply)
 			if err != nil {
-				return err
+				return failed, err
 			}
 
 			// Pretty print all the heal results.
 			for msg := range prettyHealResults(healArgs.Objects, healReply) {
 				if msg.Err != nil {
-					// TODO we need to print the error cause as well.
+					failed++
 					scanBar(msg.Msg)
 					continue
 				}
@@ -169,7 +179,7 @@ func healObjects(authClnt *AuthRPCClient, bucketName, prefixName string) error {
 		markerName = healListReply.NextMarker
 
 	}
-	return nil
+	return failed, nil
 }
 
 // Heals your bucket for any missing entries.
@@ -178,7 +188,8 @@ func healBucket(authClnt *AuthRPCClient, bucketName string) error {
 		return errInvalidArgument
 	}
 	return authClnt.Call("Control.HealBucketHandler", &HealBucketArgs{
-		Bucket: bucketName,
+		GenericArgs: GenericArgs{IdempotencyKey: getUUID()},
+		Bucket:      bucketName,
 	}, &GenericReply{})
 }
 
@@ -198,6 +209,8 @@ func healControl(ctx *cli.Context) {
 		address:     parsedURL.Host,
 		path:        path.Join(reservedBucket, controlPath),
 		loginMethod: "Control.LoginHandler",
+		compress:    true,
+		token:       os.Getenv("MINIO_HEAL_TOKEN"),
 	}
 
 	client := newAuthClient(authCfg)
@@ -207,11 +220,16 @@ func healControl(ctx *cli.Context) {
 		return
 	}
 	bucketName, prefixName := urlPathSplit(parsedURL.Path)
-	// Heal the bucket.
-	err = healBucket(client, bucketName)
-	fatalIf(err, "Unable to heal bucket %s", bucketName)
+	// Heal the bucket, objects are still healed if the bucket could
+	// not be healed on all the disks.
+	if err = healBucket(client, bucketName); err != nil {
+		console.Println("Unable to heal bucket " + bucketName + ": " + err.Error())
+	}
 	// Heal all the objects.
-	err = healObjects(client, bucketName, prefixName)
+	failed, err := healObjects(client, bucketName, prefixName)
 	fatalIf(err, "Unable to heal objects on bucket %s at prefix %s", bucketName, prefixName)
 	console.Println()
+	if failed > 0 {
+		console.Println(fmt.Sprintf("%d object(s) could not be healed.", failed))
+	}
 }
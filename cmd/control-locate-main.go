@@ -0,0 +1,113 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package cmd
+
+import (
+	"fmt"
+	"net/url"
+	"path"
+	"strings"
+
+	"github.com/minio/cli"
+	"github.com/minio/mc/pkg/console"
+)
+
+var locateCmd = cli.Command{
+	Name:   "locate",
+	Usage:  "Report the disks which host an object and which hold its parts.",
+	Action: locateControl,
+	Flags:  globalFlags,
+	CustomHelpTemplate: `NAME:
+  minio control {{.Name}} - {{.Usage}}
+
+USAGE:
+  minio control {{.Name}} URL
+
+FLAGS:
+  {{range .Flags}}{{.}}
+  {{end}}
+EXAMPLES:
+  1. Locate a specific object.
+    $ minio control {{.Name}} http://localhost:9000/songs/classical/western/piano.mp3
+`,
+}
+
+// Returns printable location of a single disk.
+func getDiskLocationMsg(location ObjectDiskLocation) string {
+	disk := location.Disk
+	if disk == "" {
+		disk = "<offline>"
+	}
+	msg := fmt.Sprintf("  Block %d: %s", location.BlockIndex, disk)
+	switch {
+	case location.Err != "":
+		msg += fmt.Sprintf(", error: %s", location.Err)
+	case location.StoredIndex == 0:
+		msg += ", object missing"
+	default:
+		if location.StoredIndex != location.BlockIndex {
+			msg += fmt.Sprintf(", stored as block %d", location.StoredIndex)
+		}
+		msg += fmt.Sprintf(", parts: %s", strings.Join(location.Parts, " "))
+		if len(location.MissingParts) > 0 {
+			msg += fmt.Sprintf(", missing parts: %s", strings.Join(location.MissingParts, " "))
+		}
+	}
+	return msg
+}
+
+// Returns printable object location message.
+func getLocateMsg(reply LocateObjectReply) string {
+	msg := fmt.Sprintf("Erasure set: %d", reply.Set)
+	for _, location := range reply.Disks {
+		msg += "\n" + getDiskLocationMsg(location)
+	}
+	return msg
+}
+
+// "minio control locate" entry point.
+func locateControl(c *cli.Context) {
+	if len(c.Args()) != 1 {
+		cli.ShowCommandHelpAndExit(c, "locate", 1)
+	}
+
+	parsedURL, err := url.Parse(c.Args().Get(0))
+	fatalIf(err, "Unable to parse URL %s", c.Args().Get(0))
+
+	bucketName, objectName := urlPathSplit(parsedURL.Path)
+	if bucketName == "" || objectName == "" {
+		cli.ShowCommandHelpAndExit(c, "locate", 1)
+	}
+
+	authCfg := &authConfig{
+		accessKey:   serverConfig.GetCredential().AccessKeyID,
+		secretKey:   serverConfig.GetCredential().SecretAccessKey,
+		secureConn:  parsedURL.Scheme == "https",
+		address:     parsedURL.Host,
+		path:        path.Join(reservedBucket, controlPath),
+		loginMethod: "Control.LoginHandler",
+	}
+	client := newAuthClient(authCfg)
+
+	args := &LocateObjectArgs{
+		Bucket: bucketName,
+		Object: objectName,
+	}
+	reply := LocateObjectReply{}
+	err = client.Call("Control.LocateObjectHandler", args, &reply)
+	fatalIf(err, "Unable to locate object %s on bucket %s.", objectName, bucketName)
+	console.Println(getLocateMsg(reply))
+}
@@ -17,6 +17,7 @@
 package cmd
 
 import (
+	"encoding/json"
 	"net/url"
 	"path"
 	"time"
@@ -60,9 +61,20 @@ EAMPLES:
 `,
 }
 
+// Headers of the lock state listings.
+const (
+	lockStateHeader        = "Duration     Server     LockType     Resource"
+	lockStateVerboseHeader = "Duration     Server     LockType     LockAcquired     Status     LockOrigin     Resource"
+)
+
 // printLockStateVerbose - pretty prints systemLockState, additionally this filters out based on a given duration.
 func printLockStateVerbose(lkStateRep map[string]SystemLockState, olderThan time.Duration) {
-	console.Println("Duration     Server     LockType     LockAcquired     Status     LockOrigin     Resource")
+	console.Println(lockStateVerboseHeader)
+	printLockStateVerboseRows(lkStateRep, olderThan)
+}
+
+// printLockStateVerboseRows - pretty prints the locks of systemLockState without header.
+func printLockStateVerboseRows(lkStateRep map[string]SystemLockState, olderThan time.Duration) {
 	for server, lockState := range lkStateRep {
 		for _, lockInfo := range lockState.LocksInfoPerObject {
 			lockedResource := path.Join(lockInfo.Bucket, lockInfo.Object)
@@ -81,7 +93,12 @@ func printLockStateVerbose(lkStateRep map[string]SystemLockState, olderThan time
 
 // printLockState - pretty prints systemLockState, additionally this filters out based on a given duration.
 func printLockState(lkStateRep map[string]SystemLockState, olderThan time.Duration) {
-	console.Println("Duration     Server     LockType     Resource")
+	console.Println(lockStateHeader)
+	printLockStateRows(lkStateRep, olderThan)
+}
+
+// printLockStateRows - pretty prints the locks of systemLockState without header.
+func printLockStateRows(lkStateRep map[string]SystemLockState, olderThan time.Duration) {
 	for server, lockState := range lkStateRep {
 		for _, lockInfo := range lockState.LocksInfoPerObject {
 			lockedResource := path.Join(lockInfo.Bucket, lockInfo.Object)
@@ -120,6 +137,7 @@ func lockControl(c *cli.Context) {
 		address:     parsedURL.Host,
 		path:        path.Join(reservedBucket, controlPath),
 		loginMethod: "Control.LoginHandler",
+		compress:    true,
 	}
 	client := newAuthClient(authCfg)
 
@@ -132,14 +150,37 @@ func lockControl(c *cli.Context) {
 	subCommand := c.Args().Get(0)
 	switch subCommand {
 	case "list":
-		lkStateRep := make(map[string]SystemLockState)
-		// Request lock info, fetches from all the nodes in the cluster.
-		err = client.Call("Control.LockInfo", args, &lkStateRep)
+		// Request lock info, fetches from all the nodes in the cluster
+		// and prints the locks of every node as soon as it replies.
+		startReply := &ReplyStreamStartReply{}
+		err = client.Call("Control.LockInfoStream", args, startReply)
 		fatalIf(err, "Unable to fetch system lockInfo.")
 		if !verbose {
-			printLockState(lkStateRep, olderThan)
+			console.Println(lockStateHeader)
 		} else {
-			printLockStateVerbose(lkStateRep, olderThan)
+			console.Println(lockStateVerboseHeader)
+		}
+		nextArgs := &ReplyStreamNextArgs{StreamID: startReply.StreamID}
+		for done := false; !done; {
+			nextReply := &ReplyStreamNextReply{}
+			err = client.Call("Control.NextReplyChunks", nextArgs, nextReply)
+			fatalIf(err, "Unable to fetch system lockInfo.")
+			for _, chunk := range nextReply.Chunks {
+				if chunk.Error != "" {
+					console.Println("Unable to fetch lockInfo of " + chunk.Node + ": " + chunk.Error)
+					continue
+				}
+				var lockState SystemLockState
+				err = json.Unmarshal(chunk.Reply, &lockState)
+				fatalIf(err, "Unable to decode lockInfo of %s.", chunk.Node)
+				lkStateRep := map[string]SystemLockState{chunk.Node: lockState}
+				if !verbose {
+					printLockStateRows(lkStateRep, olderThan)
+				} else {
+					printLockStateVerboseRows(lkStateRep, olderThan)
+				}
+			}
+			done = nextReply.Done
 		}
 	case "clear":
 		// TODO. Defaults to clearing all locks.
@@ -28,6 +28,21 @@ var controlCmd = cli.Command{
 		lockCmd,
 		healCmd,
 		serviceCmd,
+		diagnosticsCmd,
+		freezeCmd,
+		thawCmd,
+		faultCmd,
+		peersCmd,
+		locateCmd,
+		renameBucketCmd,
+		eventStatsCmd,
+		cacheStatsCmd,
+		cacheWarmCmd,
+		scheduleCmd,
+		tasksCmd,
+		simulatePolicyCmd,
+		rotateKeysCmd,
+		alertsCmd,
 	},
 	CustomHelpTemplate: `NAME:
    {{.Name}} - {{.Usage}}
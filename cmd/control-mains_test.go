@@ -247,3 +247,57 @@ func TestControlMain(t *testing.T) {
 		t.Errorf("Control-Main test failed with - %s", err)
 	}
 }
+
+// Test to call peersControl(list) in control-peers-main.go
+func TestControlPeersMain(t *testing.T) {
+	// create cli app for testing
+	app := cli.NewApp()
+	app.Commands = []cli.Command{controlCmd}
+
+	// start test server
+	testServer := StartTestServer(t, "XL")
+
+	// schedule cleanup at the end
+	defer testServer.Stop()
+
+	// fetch http server endpoint
+	url := testServer.Server.URL
+
+	// create args to call
+	args := []string{"./minio", "control", "peers", "list", url}
+
+	// run app
+	err := app.Run(args)
+	if err != nil {
+		t.Errorf("Control-Peers-Main test failed with - %s", err)
+	}
+}
+
+// Test to call locateControl in control-locate-main.go
+func TestControlLocateMain(t *testing.T) {
+	// create cli app for testing
+	app := cli.NewApp()
+	app.Commands = []cli.Command{controlCmd}
+
+	// start test server
+	testServer := StartTestServer(t, "XL")
+
+	// schedule cleanup at the end
+	defer testServer.Stop()
+
+	// fetch http server endpoint
+	url := testServer.Server.URL
+
+	if err := testServer.Obj.MakeBucket("testbucket"); err != nil {
+		t.Fatalf("Create bucket failed with <ERROR> %s", err)
+	}
+
+	// create args to call
+	args := []string{"./minio", "control", "locate", url + "/testbucket/testobject"}
+
+	// run app
+	err := app.Run(args)
+	if err != nil {
+		t.Errorf("Control-Locate-Main test failed with - %s", err)
+	}
+}
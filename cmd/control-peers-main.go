@@ -0,0 +1,107 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package cmd
+
+import (
+	"fmt"
+	"net/url"
+	"path"
+	"strings"
+
+	"github.com/minio/cli"
+	"github.com/minio/mc/pkg/console"
+)
+
+var peersCmd = cli.Command{
+	Name:   "peers",
+	Usage:  "List, add or remove peers of the cluster.",
+	Action: peersControl,
+	Flags:  globalFlags,
+	CustomHelpTemplate: `NAME:
+  minio control {{.Name}} - {{.Usage}}
+
+USAGE:
+  minio control {{.Name}} list URL
+  minio control {{.Name}} [add|remove] PEER URL
+
+FLAGS:
+  {{range .Flags}}{{.}}
+  {{end}}
+EXAMPLES:
+  1. List all the peers of the cluster.
+    $ minio control {{.Name}} list http://localhost:9000/
+
+  2. Add a new node to the peers of the cluster.
+    $ minio control {{.Name}} add 10.1.10.5:9000 http://localhost:9000/
+
+  3. Remove a node, which no longer serves any disks, from the peers of the cluster.
+    $ minio control {{.Name}} remove 10.1.10.5:9000 http://localhost:9000/
+`,
+}
+
+// Returns printable peers message.
+func getPeersMsg(reply PeersReply) string {
+	msg := fmt.Sprintf("Local node: %s\n", reply.LocalNode)
+	msg += fmt.Sprintf("S3 peers: %s\n", strings.Join(reply.S3Peers, ", "))
+	msg += fmt.Sprintf("Control peers: %s", strings.Join(reply.ControlPeers, ", "))
+	return msg
+}
+
+// "minio control peers" entry point.
+func peersControl(c *cli.Context) {
+	var method, urlStr string
+	args := &PeerArgs{
+		GenericArgs: GenericArgs{IdempotencyKey: getUUID()},
+	}
+	switch {
+	case len(c.Args()) == 2 && c.Args().Get(0) == "list":
+		method = "Control.ListPeersHandler"
+		urlStr = c.Args().Get(1)
+	case len(c.Args()) == 3 && c.Args().Get(0) == "add":
+		method = "Control.AddPeerHandler"
+		args.Peer, urlStr = c.Args().Get(1), c.Args().Get(2)
+	case len(c.Args()) == 3 && c.Args().Get(0) == "remove":
+		method = "Control.RemovePeerHandler"
+		args.Peer, urlStr = c.Args().Get(1), c.Args().Get(2)
+	default:
+		cli.ShowCommandHelpAndExit(c, "peers", 1)
+	}
+
+	parsedURL, err := url.Parse(urlStr)
+	fatalIf(err, "Unable to parse URL %s", urlStr)
+
+	authCfg := &authConfig{
+		accessKey:   serverConfig.GetCredential().AccessKeyID,
+		secretKey:   serverConfig.GetCredential().SecretAccessKey,
+		secureConn:  parsedURL.Scheme == "https",
+		address:     parsedURL.Host,
+		path:        path.Join(reservedBucket, controlPath),
+		loginMethod: "Control.LoginHandler",
+	}
+	client := newAuthClient(authCfg)
+
+	reply := PeersReply{}
+	if method == "Control.ListPeersHandler" {
+		err = client.Call(method, &GenericArgs{}, &reply)
+	} else {
+		// This is necessary so that the remotes,
+		// don't end up sending requests back and forth.
+		args.Remote = true
+		err = client.Call(method, args, &reply)
+	}
+	fatalIf(err, "Peers command %s failed for %s", c.Args().Get(0), parsedURL.Host)
+	console.Println(getPeersMsg(reply))
+}
@@ -0,0 +1,84 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package cmd
+
+import (
+	"net/url"
+	"path"
+
+	"github.com/minio/cli"
+	"github.com/minio/mc/pkg/console"
+)
+
+var renameBucketCmd = cli.Command{
+	Name:   "rename-bucket",
+	Usage:  "Rename a bucket without copying its objects.",
+	Action: renameBucketControl,
+	Flags:  globalFlags,
+	CustomHelpTemplate: `NAME:
+  minio control {{.Name}} - {{.Usage}}
+
+USAGE:
+  minio control {{.Name}} URL/BUCKET NEW-BUCKET
+
+FLAGS:
+  {{range .Flags}}{{.}}
+  {{end}}
+DESCRIPTION:
+  The bucket is renamed on all disks, along with its policy, notification
+  and listener configs, settings, incomplete uploads, search index and
+  trash. Configured notifications receive s3:ObjectRemoved:Delete for all
+  objects of the old name and s3:ObjectCreated:Copy for the new name.
+  Clients still using the old name fail with NoSuchBucket.
+
+EXAMPLES:
+  1. Rename bucket 'photos' to 'photos-2016'.
+    $ minio control {{.Name}} http://localhost:9000/photos photos-2016
+`,
+}
+
+// "minio control rename-bucket" entry point.
+func renameBucketControl(c *cli.Context) {
+	if len(c.Args()) != 2 {
+		cli.ShowCommandHelpAndExit(c, "rename-bucket", 1)
+	}
+
+	parsedURL, err := url.Parse(c.Args().Get(0))
+	fatalIf(err, "Unable to parse URL %s", c.Args().Get(0))
+	srcBucket, objectName := urlPathSplit(parsedURL.Path)
+	if srcBucket == "" || objectName != "" {
+		cli.ShowCommandHelpAndExit(c, "rename-bucket", 1)
+	}
+
+	authCfg := &authConfig{
+		accessKey:   serverConfig.GetCredential().AccessKeyID,
+		secretKey:   serverConfig.GetCredential().SecretAccessKey,
+		secureConn:  parsedURL.Scheme == "https",
+		address:     parsedURL.Host,
+		path:        path.Join(reservedBucket, controlPath),
+		loginMethod: "Control.LoginHandler",
+	}
+	client := newAuthClient(authCfg)
+
+	args := &RenameBucketArgs{
+		GenericArgs: GenericArgs{IdempotencyKey: getUUID()},
+		SrcBucket:   srcBucket,
+		DstBucket:   c.Args().Get(1),
+	}
+	err = client.Call("Control.RenameBucketHandler", args, &GenericReply{})
+	fatalIf(err, "Unable to rename bucket %s.", srcBucket)
+	console.Println("Bucket " + srcBucket + " renamed to " + args.DstBucket + ".")
+}
@@ -0,0 +1,195 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	// Maximum time NextReplyChunks waits for a node to reply.
+	replyStreamWait = 5 * time.Second
+
+	// Streams not read until the end are forgotten after this duration.
+	replyStreamExpiry = 10 * time.Minute
+)
+
+// errInvalidReplyStream - reply stream does not exist or has expired.
+var errInvalidReplyStream = errors.New("Invalid reply stream")
+
+// NodeReplyChunk - reply of a single node of a reply stream.
+type NodeReplyChunk struct {
+	// Node which sent the reply.
+	Node string
+
+	// Error of the node, set instead of Reply on partial failure.
+	Error string
+
+	// JSON encoded reply of the node.
+	Reply []byte
+}
+
+// ReplyStreamStartReply - reply by the RPC handlers starting a reply
+// stream.
+type ReplyStreamStartReply struct {
+	// Stream to read the node replies from with NextReplyChunks.
+	StreamID string
+
+	// All the nodes a reply chunk is expected from.
+	Nodes []string
+}
+
+// ReplyStreamNextArgs - argument for NextReplyChunks RPC handler.
+type ReplyStreamNextArgs struct {
+	// Authentication token generated by Login.
+	GenericArgs
+
+	// Stream to read the node replies from.
+	StreamID string
+}
+
+// ReplyStreamNextReply - reply by NextReplyChunks RPC handler.
+type ReplyStreamNextReply struct {
+	// Node replies received since the previous call.
+	Chunks []NodeReplyChunk
+
+	// Set once the replies of all the nodes are read.
+	Done bool
+}
+
+// replyStream - node replies being received for a single request.
+type replyStream struct {
+	chunks  chan NodeReplyChunk
+	pending int
+	created time.Time
+}
+
+// replyStreams - all the reply streams being read on this server.
+type replyStreams struct {
+	mutex   *sync.Mutex
+	streams map[string]*replyStream
+}
+
+// Variable holding all the reply streams.
+var globalReplyStreams = newReplyStreams()
+
+// newReplyStreams - returns empty reply streams.
+func newReplyStreams() *replyStreams {
+	return &replyStreams{
+		mutex:   &sync.Mutex{},
+		streams: make(map[string]*replyStream),
+	}
+}
+
+// New calls all the node calls concurrently and returns the stream id
+// to read their replies from as they arrive, along with the nodes.
+func (s *replyStreams) New(calls map[string]func() (interface{}, error)) (string, []string) {
+	stream := &replyStream{
+		chunks:  make(chan NodeReplyChunk, len(calls)),
+		pending: len(calls),
+		created: time.Now().UTC(),
+	}
+	var nodes []string
+	for node, call := range calls {
+		nodes = append(nodes, node)
+		go func(node string, call func() (interface{}, error)) {
+			chunk := NodeReplyChunk{Node: node}
+			reply, err := call()
+			if err == nil {
+				chunk.Reply, err = json.Marshal(reply)
+			}
+			if err != nil {
+				errorIf(err, "Unable to fetch reply of node %s", node)
+				chunk.Error = err.Error()
+				chunk.Reply = nil
+			}
+			stream.chunks <- chunk
+		}(node, call)
+	}
+	sort.Strings(nodes)
+
+	id := getUUID()
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	// Forget streams abandoned by their readers.
+	for streamID, oldStream := range s.streams {
+		if time.Since(oldStream.created) > replyStreamExpiry {
+			delete(s.streams, streamID)
+		}
+	}
+	s.streams[id] = stream
+	return id, nodes
+}
+
+// Next returns the node replies of the stream received so far, waiting
+// at most wait for the first one. done is set once all the replies are
+// read, the stream is forgotten then.
+func (s *replyStreams) Next(id string, wait time.Duration) (chunks []NodeReplyChunk, done bool, err error) {
+	s.mutex.Lock()
+	stream, ok := s.streams[id]
+	s.mutex.Unlock()
+	if !ok {
+		return nil, false, errInvalidReplyStream
+	}
+
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case chunk := <-stream.chunks:
+		chunks = append(chunks, chunk)
+	case <-timer.C:
+	}
+	// Return all the other replies already received.
+	for received := true; received; {
+		select {
+		case chunk := <-stream.chunks:
+			chunks = append(chunks, chunk)
+		default:
+			received = false
+		}
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	stream.pending -= len(chunks)
+	done = stream.pending == 0
+	if done {
+		delete(s.streams, id)
+	}
+	return chunks, done, nil
+}
+
+// NextReplyChunks - RPC control handler returning the node replies of
+// a reply stream as they arrive, a slow node only delays its own reply.
+func (c *controlAPIHandlers) NextReplyChunks(args *ReplyStreamNextArgs, reply *ReplyStreamNextReply) (err error) {
+	defer encodeRPCError(&err)
+
+	if !isRPCTokenValid(args.Token, jwtAudienceAdmin) {
+		return errInvalidToken
+	}
+	chunks, done, err := globalReplyStreams.Next(args.StreamID, replyStreamWait)
+	if err != nil {
+		return err
+	}
+	reply.Chunks = chunks
+	reply.Done = done
+	return nil
+}
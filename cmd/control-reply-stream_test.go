@@ -0,0 +1,88 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+// Tests node replies are returned as they arrive.
+func TestReplyStreams(t *testing.T) {
+	streams := newReplyStreams()
+	slow := make(chan struct{})
+	id, nodes := streams.New(map[string]func() (interface{}, error){
+		"fast": func() (interface{}, error) {
+			return "reply", nil
+		},
+		"failed": func() (interface{}, error) {
+			return nil, errors.New("unreachable")
+		},
+		"slow": func() (interface{}, error) {
+			<-slow
+			return "late reply", nil
+		},
+	})
+	if len(nodes) != 3 || nodes[0] != "failed" {
+		t.Fatalf("Expected sorted nodes, got %v", nodes)
+	}
+
+	// The slow node does not delay the other replies.
+	var chunks []NodeReplyChunk
+	for len(chunks) < 2 {
+		next, done, err := streams.Next(id, time.Second)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if done {
+			t.Fatal("Expected stream not to be done before the slow node replies")
+		}
+		chunks = append(chunks, next...)
+	}
+	for _, chunk := range chunks {
+		switch chunk.Node {
+		case "fast":
+			if chunk.Error != "" || string(chunk.Reply) != `"reply"` {
+				t.Errorf("Unexpected reply chunk %v", chunk)
+			}
+		case "failed":
+			if chunk.Error != "unreachable" || chunk.Reply != nil {
+				t.Errorf("Expected partial failure marker, got %v", chunk)
+			}
+		default:
+			t.Errorf("Unexpected reply of node %s", chunk.Node)
+		}
+	}
+
+	// Waiting for the slow node times out without replies.
+	next, done, err := streams.Next(id, time.Millisecond)
+	if err != nil || done || len(next) != 0 {
+		t.Fatalf("Expected no replies, got %v %v %v", next, done, err)
+	}
+
+	close(slow)
+	next, done, err = streams.Next(id, time.Second)
+	if err != nil || !done || len(next) != 1 || next[0].Node != "slow" {
+		t.Fatalf("Expected the slow reply to end the stream, got %v %v %v", next, done, err)
+	}
+
+	// Streams read until the end are forgotten.
+	if _, _, err = streams.Next(id, time.Millisecond); err != errInvalidReplyStream {
+		t.Fatalf("Expected %v, got %v", errInvalidReplyStream, err)
+	}
+}
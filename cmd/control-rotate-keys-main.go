@@ -0,0 +1,138 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package cmd
+
+import (
+	"fmt"
+	"net/url"
+	"path"
+
+	"github.com/minio/cli"
+	"github.com/minio/mc/pkg/console"
+)
+
+var rotateKeysFlags = []cli.Flag{
+	cli.BoolFlag{
+		Name:  "verify",
+		Usage: "Only verify the keys of all the encrypted objects are sealed with the current master key.",
+	},
+}
+
+var rotateKeysCmd = cli.Command{
+	Name:   "rotate-keys",
+	Usage:  "Re-seal the keys of encrypted objects with the current master key.",
+	Action: rotateKeysControl,
+	Flags:  append(rotateKeysFlags, globalFlags...),
+	CustomHelpTemplate: `NAME:
+  minio control {{.Name}} - {{.Usage}}
+
+USAGE:
+  minio control {{.Name}} [FLAGS] URL
+
+FLAGS:
+  {{range .Flags}}{{.}}
+  {{end}}
+DESCRIPTION:
+  To rotate the master key, restart all the servers with the new key in
+  MINIO_SSE_MASTER_KEY and the previous one in MINIO_SSE_OLD_MASTER_KEYS,
+  then run this command. The keys of objects are re-sealed with the new
+  master key, their data is not encrypted again. Objects already sealed
+  with the new master key are skipped, so an interrupted rotation can be
+  run again. Run with --verify afterwards, the old master key can be
+  removed once no object fails.
+
+  Without a bucket all the buckets are rotated, including the trash.
+
+EXAMPLES:
+  1. Rotate the keys of all the encrypted objects.
+    $ minio control {{.Name}} http://localhost:9000
+
+  2. Rotate the keys of the objects of a bucket with a prefix.
+    $ minio control {{.Name}} http://localhost:9000/songs/classical/
+
+  3. Verify all the keys are sealed with the current master key.
+    $ minio control {{.Name}} --verify http://localhost:9000
+`,
+}
+
+// Returns printable progress of a key rotation.
+func getRotateKeysMsg(reply RotateKeysReply, verify bool) string {
+	if verify {
+		return fmt.Sprintf("Scanned: %d | Encrypted: %d | Not rotated: %d", reply.Scanned, reply.Encrypted, len(reply.Failed))
+	}
+	return fmt.Sprintf("Scanned: %d | Encrypted: %d | Rotated: %d | Failed: %d", reply.Scanned, reply.Encrypted, reply.Rotated, len(reply.Failed))
+}
+
+// "minio control rotate-keys" entry point.
+func rotateKeysControl(c *cli.Context) {
+	if len(c.Args()) != 1 {
+		cli.ShowCommandHelpAndExit(c, "rotate-keys", 1)
+	}
+
+	parsedURL, err := url.Parse(c.Args().Get(0))
+	fatalIf(err, "Unable to parse URL %s", c.Args().Get(0))
+
+	authCfg := &authConfig{
+		accessKey:   serverConfig.GetCredential().AccessKeyID,
+		secretKey:   serverConfig.GetCredential().SecretAccessKey,
+		secureConn:  parsedURL.Scheme == "https",
+		address:     parsedURL.Host,
+		path:        path.Join(reservedBucket, controlPath),
+		loginMethod: "Control.LoginHandler",
+	}
+	client := newAuthClient(authCfg)
+
+	// Rotation stops on the server when interrupted.
+	callID := getUUID()
+	stop := cancelOnInterrupt(client, callID)
+	defer stop()
+
+	verify := c.Bool("verify")
+	bucketName, prefixName := urlPathSplit(parsedURL.Path)
+	total := RotateKeysReply{}
+	var marker string
+	for {
+		args := &RotateKeysArgs{
+			GenericArgs: GenericArgs{CallID: callID, IdempotencyKey: getUUID()},
+			Bucket:      bucketName,
+			Prefix:      prefixName,
+			Marker:      marker,
+			Verify:      verify,
+		}
+		reply := RotateKeysReply{}
+		err = client.Call("Control.RotateKeysHandler", args, &reply)
+		fatalIf(err, "Unable to rotate the keys of encrypted objects.")
+
+		for _, failure := range reply.Failed {
+			console.Println(fmt.Sprintf("%s  %s  %s", colorRed("FAILED"), path.Join(failure.Bucket, failure.Object), failure.Error))
+		}
+		total.Scanned += reply.Scanned
+		total.Encrypted += reply.Encrypted
+		total.Rotated += reply.Rotated
+		total.Failed = append(total.Failed, reply.Failed...)
+		scanBar(getRotateKeysMsg(total, verify))
+
+		if !reply.IsTruncated {
+			break
+		}
+		marker = reply.NextMarker
+	}
+	console.Println()
+	console.Println(getRotateKeysMsg(total, verify))
+	if len(total.Failed) > 0 {
+		fatalIf(errSSEKeyNotRotated, "%d object(s) are not sealed with the current master key.", len(total.Failed))
+	}
+}
@@ -17,10 +17,14 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
 	"net/rpc"
 	"path"
+	"sort"
 	"strings"
+	"sync"
+	"time"
 
 	router "github.com/gorilla/mux"
 	"github.com/minio/minio-go/pkg/set"
@@ -57,18 +61,26 @@ func initRemoteControlClients(srvCmdConfig serverCmdConfig) []*AuthRPCClient {
 		remoteHosts.Add(fmt.Sprintf("%s:%d", host, globalMinioPort))
 	}
 	for host := range remoteHosts {
-		remoteControlClnts = append(remoteControlClnts, newAuthClient(&authConfig{
-			accessKey:   serverConfig.GetCredential().AccessKeyID,
-			secretKey:   serverConfig.GetCredential().SecretAccessKey,
-			secureConn:  isSSL(),
-			address:     host,
-			path:        path.Join(reservedBucket, controlPath),
-			loginMethod: "Control.LoginHandler",
-		}))
+		remoteControlClnts = append(remoteControlClnts, newRemoteControlClient(host))
 	}
 	return remoteControlClnts
 }
 
+// Returns a new control client for the remote node at host (in
+// `host:port` format).
+func newRemoteControlClient(host string) *AuthRPCClient {
+	return newAuthClient(&authConfig{
+		accessKey:   serverConfig.GetCredential().AccessKeyID,
+		secretKey:   serverConfig.GetCredential().SecretAccessKey,
+		secureConn:  isSSL(),
+		address:     host,
+		path:        path.Join(reservedBucket, controlPath),
+		loginMethod: "Control.LoginHandler",
+		compress:    true,
+		timeout:     controlRPCTimeout,
+	})
+}
+
 // Represents control object which provides handlers for control
 // operations on server.
 type controlAPIHandlers struct {
@@ -77,6 +89,63 @@ type controlAPIHandlers struct {
 	RemoteControls []*AuthRPCClient
 	LocalNode      string
 	StorageDisks   []StorageAPI
+
+	// Guards RemoteControls, which can be changed at runtime
+	// by AddPeerHandler and RemovePeerHandler.
+	peersMutex sync.RWMutex
+}
+
+// Returns a snapshot of the remote control clients.
+func (c *controlAPIHandlers) getRemoteControls() []*AuthRPCClient {
+	c.peersMutex.RLock()
+	defer c.peersMutex.RUnlock()
+	return append([]*AuthRPCClient(nil), c.RemoteControls...)
+}
+
+// Make RPC calls with the given method and arguments to all the given
+// remote controls (in parallel). The reply of remoteControls[i] is read
+// into reply(i). Returns a map of node to error response of the nodes
+// which failed, the replies of all the other nodes are valid.
+func callRemoteControls(remoteControls []*AuthRPCClient, method string, args interface {
+	SetToken(token string)
+	SetTimestamp(tstamp time.Time)
+}, reply func(index int) interface{}) map[string]error {
+	var wg sync.WaitGroup
+	var errs = make([]error, len(remoteControls))
+	for index, clnt := range remoteControls {
+		wg.Add(1)
+		go func(index int, client *AuthRPCClient) {
+			defer wg.Done()
+			errs[index] = client.Call(method, args, reply(index))
+			errorIf(errs[index], "Unable to call %s on remote node %s", method, client.Node())
+		}(index, clnt)
+	}
+	wg.Wait()
+	errsMap := make(map[string]error)
+	for index, err := range errs {
+		if err != nil {
+			errsMap[remoteControls[index].Node()] = err
+		}
+	}
+	return errsMap
+}
+
+// Returns a single error describing the errors of all the failed
+// nodes, nil if no node failed.
+func nodeErrorsToError(errsMap map[string]error) error {
+	if len(errsMap) == 0 {
+		return nil
+	}
+	var nodes []string
+	for node := range errsMap {
+		nodes = append(nodes, node)
+	}
+	sort.Strings(nodes)
+	var msgs []string
+	for _, node := range nodes {
+		msgs = append(msgs, node+": "+errsMap[node].Error())
+	}
+	return errors.New(strings.Join(msgs, "; "))
 }
 
 // Register control RPC handlers.
@@ -97,6 +166,6 @@ func registerControlRPCRouter(mux *router.Router, srvCmdConfig serverCmdConfig)
 	}
 
 	ctrlRouter := mux.NewRoute().PathPrefix(reservedBucket).Subrouter()
-	ctrlRouter.Path(controlPath).Handler(ctrlRPCServer)
+	ctrlRouter.Path(controlPath).Handler(newRPCHandler(ctrlRPCServer))
 	return nil
 }
@@ -0,0 +1,119 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package cmd
+
+import (
+	"net/url"
+	"path"
+	"sort"
+	"time"
+
+	"github.com/minio/cli"
+	"github.com/minio/mc/pkg/console"
+)
+
+var scheduleFlags = []cli.Flag{
+	cli.DurationFlag{
+		Name:  "run-for",
+		Usage: "Run background tasks regardless of MINIO_BACKGROUND_WINDOW for this long.",
+	},
+	cli.BoolFlag{
+		Name:  "reset",
+		Usage: "Cancel a previous --run-for, background tasks run only in MINIO_BACKGROUND_WINDOW again.",
+	},
+}
+
+var scheduleCmd = cli.Command{
+	Name:   "schedule",
+	Usage:  "Show or override the window background tasks run in.",
+	Action: scheduleControl,
+	Flags:  append(scheduleFlags, globalFlags...),
+	CustomHelpTemplate: `NAME:
+  minio control {{.Name}} - {{.Usage}}
+
+USAGE:
+  minio control {{.Name}} [FLAGS] URL
+
+FLAGS:
+  {{range .Flags}}{{.}}
+  {{end}}
+DESCRIPTION:
+  Background tasks like tmp cleanup, deduplication GC and purging the
+  trash, resumable and browser uploads run only in the local time window
+  set by MINIO_BACKGROUND_WINDOW. The window can be overridden on all the
+  nodes, for example to catch up after a long outage.
+
+EXAMPLES:
+  1. Show the window background tasks run in.
+    $ minio control {{.Name}} http://localhost:9000/
+
+  2. Run background tasks for the next 2 hours regardless of the window.
+    $ minio control {{.Name}} --run-for 2h http://localhost:9000/
+
+  3. Run background tasks only in the window again.
+    $ minio control {{.Name}} --reset http://localhost:9000/
+`,
+}
+
+// "minio control schedule" entry point.
+func scheduleControl(c *cli.Context) {
+	if len(c.Args()) != 1 {
+		cli.ShowCommandHelpAndExit(c, "schedule", 1)
+	}
+	if c.Duration("run-for") < 0 || (c.Duration("run-for") > 0 && c.Bool("reset")) {
+		fatalIf(errInvalidArgument, "--run-for has to be positive and cannot be combined with --reset.")
+	}
+
+	parsedURL, err := url.Parse(c.Args().Get(0))
+	fatalIf(err, "Unable to parse URL %s", c.Args().Get(0))
+
+	authCfg := &authConfig{
+		accessKey:   serverConfig.GetCredential().AccessKeyID,
+		secretKey:   serverConfig.GetCredential().SecretAccessKey,
+		secureConn:  parsedURL.Scheme == "https",
+		address:     parsedURL.Host,
+		path:        path.Join(reservedBucket, controlPath),
+		loginMethod: "Control.LoginHandler",
+	}
+	client := newAuthClient(authCfg)
+
+	args := &ScheduleArgs{
+		GenericArgs: GenericArgs{Remote: true, IdempotencyKey: getUUID()},
+		RunFor:      c.Duration("run-for"),
+		Reset:       c.Bool("reset"),
+	}
+	reply := &ScheduleReply{}
+	err = client.Call("Control.ScheduleHandler", args, reply)
+	fatalIf(err, "Unable to schedule background tasks.")
+
+	var nodes []string
+	for node := range reply.Errors {
+		nodes = append(nodes, node)
+	}
+	sort.Strings(nodes)
+	for _, node := range nodes {
+		console.Println("Unable to schedule background tasks of " + node + ": " + reply.Errors[node])
+	}
+
+	if reply.Window == "" {
+		console.Println("Background tasks run always.")
+		return
+	}
+	console.Println("Background tasks run between " + reply.Window + " local time.")
+	if !reply.OverrideUntil.IsZero() {
+		console.Println("Overridden until " + reply.OverrideUntil.Format(time.RFC3339) + ".")
+	}
+}
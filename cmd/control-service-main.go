@@ -82,7 +82,8 @@ func serviceControl(c *cli.Context) {
 	client := newAuthClient(authCfg)
 
 	args := &ServiceArgs{
-		Signal: signal,
+		GenericArgs: GenericArgs{IdempotencyKey: getUUID()},
+		Signal:      signal,
 	}
 	// This is necessary so that the remotes,
 	// don't end up sending requests back and forth.
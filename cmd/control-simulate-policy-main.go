@@ -0,0 +1,131 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"fmt"
+	"net/url"
+	"path"
+	"strings"
+
+	"github.com/minio/cli"
+	"github.com/minio/mc/pkg/console"
+)
+
+var simulatePolicyFlags = []cli.Flag{
+	cli.StringFlag{
+		Name:  "access-key",
+		Usage: "Access key the request is signed with, anonymous if not set.",
+	},
+	cli.StringFlag{
+		Name:  "action",
+		Usage: "Action requested, such as s3:GetObject.",
+	},
+	cli.StringSliceFlag{
+		Name:  "condition",
+		Usage: "Condition context as KEY=VALUE, such as prefix=photos/ for s3:ListBucket.",
+	},
+}
+
+var simulatePolicyCmd = cli.Command{
+	Name:   "simulate-policy",
+	Usage:  "Report whether a request would be allowed and which policy statement decides.",
+	Action: simulatePolicyControl,
+	Flags:  append(simulatePolicyFlags, globalFlags...),
+	CustomHelpTemplate: `NAME:
+  minio control {{.Name}} - {{.Usage}}
+
+USAGE:
+  minio control {{.Name}} --action ACTION [FLAGS] URL
+
+FLAGS:
+  {{range .Flags}}{{.}}
+  {{end}}
+DESCRIPTION:
+  Requests signed with the access key of the server are always allowed.
+  Anonymous requests are decided by the first statement of the bucket
+  policy which matches the action, resource and conditions, Deny
+  statements are evaluated before Allow statements. Requests are denied
+  if no statement matches. No request is made.
+
+EXAMPLES:
+  1. Check if anyone can download an object.
+    $ minio control {{.Name}} --action s3:GetObject http://localhost:9000/songs/piano.mp3
+
+  2. Check if anyone can list a prefix of a bucket.
+    $ minio control {{.Name}} --action s3:ListBucket --condition prefix=classical/ http://localhost:9000/songs
+`,
+}
+
+// Returns printable result of a policy simulation.
+func getSimulatePolicyMsg(reply PolicySimulateReply) string {
+	decision := "Denied"
+	if reply.Allowed {
+		decision = "Allowed"
+	}
+	msg := fmt.Sprintf("%s by %s: %s", decision, reply.Source, reply.Reason)
+	if reply.StatementIndex > 0 {
+		msg += fmt.Sprintf("\n  Statement %d: %s", reply.StatementIndex, reply.Statement)
+	}
+	return msg
+}
+
+// "minio control simulate-policy" entry point.
+func simulatePolicyControl(c *cli.Context) {
+	if len(c.Args()) != 1 || c.String("action") == "" {
+		cli.ShowCommandHelpAndExit(c, "simulate-policy", 1)
+	}
+
+	parsedURL, err := url.Parse(c.Args().Get(0))
+	fatalIf(err, "Unable to parse URL %s", c.Args().Get(0))
+
+	bucketName, objectName := urlPathSplit(parsedURL.Path)
+	if bucketName == "" {
+		cli.ShowCommandHelpAndExit(c, "simulate-policy", 1)
+	}
+
+	conditions := make(map[string]string)
+	for _, condition := range c.StringSlice("condition") {
+		keyValue := strings.SplitN(condition, "=", 2)
+		if len(keyValue) != 2 || keyValue[0] == "" {
+			fatalIf(errInvalidArgument, "Invalid condition %s, expected KEY=VALUE.", condition)
+		}
+		conditions[keyValue[0]] = keyValue[1]
+	}
+
+	authCfg := &authConfig{
+		accessKey:   serverConfig.GetCredential().AccessKeyID,
+		secretKey:   serverConfig.GetCredential().SecretAccessKey,
+		secureConn:  parsedURL.Scheme == "https",
+		address:     parsedURL.Host,
+		path:        path.Join(reservedBucket, controlPath),
+		loginMethod: "Control.LoginHandler",
+	}
+	client := newAuthClient(authCfg)
+
+	args := &PolicySimulateArgs{
+		AccessKey:  c.String("access-key"),
+		Action:     c.String("action"),
+		Bucket:     bucketName,
+		Object:     objectName,
+		Conditions: conditions,
+	}
+	reply := PolicySimulateReply{}
+	err = client.Call("Control.PolicySimulateHandler", args, &reply)
+	fatalIf(err, "Unable to simulate %s on %s.", args.Action, parsedURL.Path)
+	console.Println(getSimulatePolicyMsg(reply))
+}
@@ -0,0 +1,127 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package cmd
+
+import (
+	"fmt"
+	"net/url"
+	"path"
+	"time"
+
+	"github.com/minio/cli"
+	"github.com/minio/mc/pkg/console"
+)
+
+var tasksCmd = cli.Command{
+	Name:   "tasks",
+	Usage:  "List, pause and resume background tasks.",
+	Action: tasksControl,
+	Flags:  globalFlags,
+	CustomHelpTemplate: `NAME:
+  minio control {{.Name}} - {{.Usage}}
+
+USAGE:
+  minio control {{.Name}} list URL
+  minio control {{.Name}} [pause|resume] TASK URL
+
+FLAGS:
+  {{range .Flags}}{{.}}
+  {{end}}
+DESCRIPTION:
+  Background tasks are tmp-cleanup, dedup-gc, trash-purge,
+  resumable-purge, browser-upload-purge and config-check. Paused tasks stay paused
+  until resumed or the server is restarted.
+
+EXAMPLES:
+  1. List the background tasks of all the nodes.
+    $ minio control {{.Name}} list http://localhost:9000/
+
+  2. Pause purging the trash on all the nodes.
+    $ minio control {{.Name}} pause trash-purge http://localhost:9000/
+
+  3. Resume purging the trash on all the nodes.
+    $ minio control {{.Name}} resume trash-purge http://localhost:9000/
+`,
+}
+
+// Returns printable status of a background task.
+func getTaskStatusMsg(node string, status TaskStatus) string {
+	lastRun := "never"
+	if !status.LastRun.IsZero() {
+		lastRun = status.LastRun.Format(time.RFC3339) + " (" + status.LastDuration.String() + ")"
+	}
+	msg := fmt.Sprintf("%s %s: %s, last run %s, next run %s, %d runs, %d failures",
+		node, status.Name, status.State, lastRun, status.NextRun.Format(time.RFC3339),
+		status.Runs, status.Failures)
+	if status.LastError != "" {
+		msg += ", last error: " + status.LastError
+	}
+	return msg
+}
+
+// "minio control tasks" entry point.
+func tasksControl(c *cli.Context) {
+	args := c.Args()
+	switch {
+	case len(args) == 2 && args.Get(0) == "list":
+	case len(args) == 3 && (args.Get(0) == "pause" || args.Get(0) == "resume"):
+	default:
+		cli.ShowCommandHelpAndExit(c, "tasks", 1)
+	}
+
+	urlStr := args.Get(len(args) - 1)
+	parsedURL, err := url.Parse(urlStr)
+	fatalIf(err, "Unable to parse URL %s", urlStr)
+
+	authCfg := &authConfig{
+		accessKey:   serverConfig.GetCredential().AccessKeyID,
+		secretKey:   serverConfig.GetCredential().SecretAccessKey,
+		secureConn:  parsedURL.Scheme == "https",
+		address:     parsedURL.Host,
+		path:        path.Join(reservedBucket, controlPath),
+		loginMethod: "Control.LoginHandler",
+	}
+	client := newAuthClient(authCfg)
+
+	if args.Get(0) == "list" {
+		reply := &TaskStatusReply{}
+		err = client.Call("Control.TaskStatusHandler", &TaskStatusArgs{GenericArgs{Remote: true}}, reply)
+		fatalIf(err, "Unable to list background tasks.")
+		for _, nodeStatus := range reply.Nodes {
+			if nodeStatus.Error != "" {
+				console.Println("Unable to list background tasks of " + nodeStatus.Node + ": " + nodeStatus.Error)
+				continue
+			}
+			for _, status := range nodeStatus.Tasks {
+				console.Println(getTaskStatusMsg(nodeStatus.Node, status))
+			}
+		}
+		return
+	}
+
+	taskArgs := &TaskControlArgs{
+		GenericArgs: GenericArgs{Remote: true, IdempotencyKey: getUUID()},
+		Task:        args.Get(1),
+		Pause:       args.Get(0) == "pause",
+	}
+	err = client.Call("Control.TaskControlHandler", taskArgs, &GenericReply{})
+	fatalIf(err, "Unable to %s background task %s.", args.Get(0), taskArgs.Task)
+	if taskArgs.Pause {
+		console.Println("Background task " + taskArgs.Task + " paused.")
+	} else {
+		console.Println("Background task " + taskArgs.Task + " resumed.")
+	}
+}
@@ -17,7 +17,14 @@
 package cmd
 
 import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
 	"path"
+	"path/filepath"
+	"reflect"
 	"strconv"
 	"strings"
 	"sync"
@@ -386,3 +393,1049 @@ func (s *TestRPCControlSuite) testControlListObjectsHealH(t *testing.T) {
 		t.Errorf("Test failed - %s", err)
 	}
 }
+
+func TestControlFreezeThawH(t *testing.T) {
+	// Setup code
+	s := &TestRPCControlSuite{serverType: "XL"}
+	s.SetUpSuite(t)
+
+	// Run test
+	s.testControlFreezeThawH(t)
+
+	// Teardown code
+	s.TearDownSuite(t)
+}
+
+// Tests freezing and thawing writes via `FreezeHandler` and `ThawHandler`.
+func (s *TestRPCControlSuite) testControlFreezeThawH(t *testing.T) {
+	client := newAuthClient(s.testAuthConf)
+	defer client.Close()
+
+	objAPI := newObjectLayerFn()
+	if err := objAPI.MakeBucket("freezebucket"); err != nil {
+		t.Fatalf("Create bucket failed with <ERROR> %s", err)
+	}
+
+	args := &FreezeArgs{Duration: time.Minute}
+	args.Remote = true
+	reply := FreezeReply{}
+	if err := client.Call("Control.FreezeHandler", args, &reply); err != nil {
+		t.Fatalf("Freeze failed with <ERROR> %s", err)
+	}
+	if reply.FreezeID == "" {
+		t.Fatal("Expected a freeze ID")
+	}
+	if len(reply.Buckets) != 1 || reply.Buckets[0] != "freezebucket" {
+		t.Errorf("Expected frozen buckets [freezebucket], got %v", reply.Buckets)
+	}
+	if freezeID, frozen := globalWriteFreeze.isFrozen(); !frozen || freezeID != reply.FreezeID {
+		t.Errorf("Expected writes to be frozen with %s, got %s", reply.FreezeID, freezeID)
+	}
+
+	// Freezing again should fail.
+	args = &FreezeArgs{Duration: time.Minute}
+	args.Remote = true
+	if err := client.Call("Control.FreezeHandler", args, &FreezeReply{}); err == nil {
+		t.Error("Expected freezing frozen writes to fail")
+	}
+
+	// Thawing with a wrong freeze ID should fail.
+	args = &FreezeArgs{FreezeID: "unknown"}
+	args.Remote = true
+	if err := client.Call("Control.ThawHandler", args, &FreezeReply{}); err == nil {
+		t.Error("Expected thawing with unknown freeze ID to fail")
+	}
+
+	args = &FreezeArgs{FreezeID: reply.FreezeID}
+	args.Remote = true
+	if err := client.Call("Control.ThawHandler", args, &FreezeReply{}); err != nil {
+		t.Fatalf("Thaw failed with <ERROR> %s", err)
+	}
+	if _, frozen := globalWriteFreeze.isFrozen(); frozen {
+		t.Error("Expected writes to be thawed")
+	}
+
+	// Bucket locks should be released once thawed.
+	if err := objAPI.DeleteBucket("freezebucket"); err != nil {
+		t.Errorf("Delete bucket failed with <ERROR> %s", err)
+	}
+}
+
+func TestControlFaultInjectionH(t *testing.T) {
+	// Setup code
+	s := &TestRPCControlSuite{serverType: "XL"}
+	s.SetUpSuite(t)
+
+	// Run test
+	s.testControlFaultInjectionH(t)
+
+	// Teardown code
+	s.TearDownSuite(t)
+}
+
+// Tests injecting faults via `FaultInjectionHandler`.
+func (s *TestRPCControlSuite) testControlFaultInjectionH(t *testing.T) {
+	client := newAuthClient(s.testAuthConf)
+	defer client.Close()
+
+	faults := faultConfig{DiskWriteDrop: 10, RPCDelay: time.Millisecond}
+
+	// Fault injection is disabled by default.
+	globalFaultInjection = false
+	err := client.Call("Control.FaultInjectionHandler", &FaultInjectionArgs{Faults: faults}, &FaultInjectionReply{})
+	if err != errFaultInjectionDisabled {
+		t.Fatalf("Expected %s, got %v", errFaultInjectionDisabled, err)
+	}
+
+	globalFaultInjection = true
+	defer func() {
+		globalFaultInjection = false
+		globalFaultInjector.setConfig(faultConfig{})
+	}()
+
+	// Invalid rates should be rejected.
+	args := &FaultInjectionArgs{Faults: faultConfig{DiskReadCorrupt: 101}}
+	if err = client.Call("Control.FaultInjectionHandler", args, &FaultInjectionReply{}); err == nil {
+		t.Error("Expected invalid fault rate to be rejected")
+	}
+
+	reply := FaultInjectionReply{}
+	if err = client.Call("Control.FaultInjectionHandler", &FaultInjectionArgs{Faults: faults}, &reply); err != nil {
+		t.Fatalf("Fault injection failed with <ERROR> %s", err)
+	}
+	if reply.Faults != faults {
+		t.Errorf("Expected injected faults %v, got %v", faults, reply.Faults)
+	}
+	if config := globalFaultInjector.getConfig(); config != faults {
+		t.Errorf("Expected injected faults %v, got %v", faults, config)
+	}
+
+	// Empty faults clear the injected faults.
+	reply = FaultInjectionReply{}
+	if err = client.Call("Control.FaultInjectionHandler", &FaultInjectionArgs{}, &reply); err != nil {
+		t.Fatalf("Clearing faults failed with <ERROR> %s", err)
+	}
+	if reply.Faults != (faultConfig{}) {
+		t.Errorf("Expected faults to be cleared, got %v", reply.Faults)
+	}
+}
+
+func TestControlPeersH(t *testing.T) {
+	// Setup code
+	s := &TestRPCControlSuite{serverType: "XL"}
+	s.SetUpSuite(t)
+
+	// Run test
+	s.testControlPeersH(t)
+
+	// Teardown code
+	s.TearDownSuite(t)
+}
+
+// Tests peer membership changes via `ListPeersHandler`, `AddPeerHandler` and `RemovePeerHandler`.
+func (s *TestRPCControlSuite) testControlPeersH(t *testing.T) {
+	globalMinioAddr = fmt.Sprintf(":%d", globalMinioPort)
+	initGlobalS3Peers([]string{})
+	defer globalS3Peers.Close()
+
+	client := newAuthClient(s.testAuthConf)
+	defer client.Close()
+
+	reply := PeersReply{}
+	if err := client.Call("Control.ListPeersHandler", &GenericArgs{}, &reply); err != nil {
+		t.Fatalf("List peers failed with <ERROR> %s", err)
+	}
+	if len(reply.S3Peers) != 1 || len(reply.ControlPeers) != 0 {
+		t.Fatalf("Expected only the local node as peer, got %v", reply)
+	}
+
+	// The test server itself is reachable under its listener address.
+	peer := s.testServer.Server.Listener.Addr().String()
+
+	testCases := []struct {
+		method string
+		peer   string
+		err    error
+	}{
+		// Test case - 1, invalid peer address.
+		{"Control.AddPeerHandler", "localhost", errInvalidArgument},
+		// Test case - 2, unreachable peer.
+		{"Control.AddPeerHandler", "127.0.0.1:1", nil},
+		// Test case - 3, removing a node which is not a peer.
+		{"Control.RemovePeerHandler", peer, errPeerNotFound},
+		// Test case - 4, removing the local node.
+		{"Control.RemovePeerHandler", reply.LocalNode, errPeerIsLocal},
+		// Test case - 5, adding a reachable peer.
+		{"Control.AddPeerHandler", peer, nil},
+		// Test case - 6, adding an existing peer.
+		{"Control.AddPeerHandler", peer, errPeerExists},
+		// Test case - 7, removing a peer.
+		{"Control.RemovePeerHandler", peer, nil},
+	}
+	for i, testCase := range testCases {
+		args := &PeerArgs{Peer: testCase.peer}
+		args.Remote = true
+		err := client.Call(testCase.method, args, &PeersReply{})
+		if i == 1 {
+			// Connection errors are not sentinels.
+			if err == nil {
+				t.Errorf("Test %d: Expected adding an unreachable peer to fail", i+1)
+			}
+			continue
+		}
+		if err != testCase.err {
+			t.Errorf("Test %d: Expected %v, got %v", i+1, testCase.err, err)
+		}
+	}
+
+	args := &PeerArgs{Peer: peer}
+	args.Remote = true
+	reply = PeersReply{}
+	if err := client.Call("Control.AddPeerHandler", args, &reply); err != nil {
+		t.Fatalf("Add peer failed with <ERROR> %s", err)
+	}
+	if len(reply.S3Peers) != 2 || len(reply.ControlPeers) != 1 || reply.ControlPeers[0] != peer {
+		t.Errorf("Expected %s to be a peer, got %v", peer, reply)
+	}
+	reply = PeersReply{}
+	if err := client.Call("Control.RemovePeerHandler", args, &reply); err != nil {
+		t.Fatalf("Remove peer failed with <ERROR> %s", err)
+	}
+	if len(reply.S3Peers) != 1 || len(reply.ControlPeers) != 0 {
+		t.Errorf("Expected %s to be removed, got %v", peer, reply)
+	}
+}
+
+// Tests that membership changes need to be acknowledged by a quorum of nodes.
+func TestBroadcastPeerChange(t *testing.T) {
+	rootPath, err := newTestConfig("us-east-1")
+	if err != nil {
+		t.Fatal("Unable to initialize config", err)
+	}
+	defer removeAll(rootPath)
+
+	testCases := []struct {
+		nodes  int
+		quorum int
+	}{
+		{1, 1},
+		{2, 2},
+		{3, 2},
+		{4, 3},
+		{16, 9},
+	}
+	for i, testCase := range testCases {
+		if quorum := peerQuorum(testCase.nodes); quorum != testCase.quorum {
+			t.Errorf("Test %d: Expected quorum %d, got %d", i+1, testCase.quorum, quorum)
+		}
+	}
+
+	// No remote nodes, the local node is a quorum by itself.
+	args := &PeerArgs{Peer: "10.1.10.5:9000"}
+	if err = broadcastPeerChange(nil, "Control.AddPeerHandler", "Control.RemovePeerHandler", args); err != nil {
+		t.Errorf("Expected no error, got %s", err)
+	}
+
+	// Unreachable remote node, the local node alone is not a quorum.
+	remoteControls := []*AuthRPCClient{newRemoteControlClient("127.0.0.1:1")}
+	defer remoteControls[0].Close()
+	err = broadcastPeerChange(remoteControls, "Control.AddPeerHandler", "Control.RemovePeerHandler", args)
+	if err != errPeerQuorum {
+		t.Errorf("Expected %s, got %v", errPeerQuorum, err)
+	}
+}
+
+func TestControlLocateObjectH(t *testing.T) {
+	// Setup code
+	s := &TestRPCControlSuite{serverType: "XL"}
+	s.SetUpSuite(t)
+
+	// Run test
+	s.testControlLocateObjectH(t)
+
+	// Teardown code
+	s.TearDownSuite(t)
+}
+
+// Tests locating an object on the disks via `LocateObjectHandler`.
+func (s *TestRPCControlSuite) testControlLocateObjectH(t *testing.T) {
+	client := newAuthClient(s.testAuthConf)
+	defer client.Close()
+
+	objAPI := newObjectLayerFn()
+	if err := objAPI.MakeBucket("testbucket"); err != nil {
+		t.Fatalf("Create bucket failed with <ERROR> %s", err)
+	}
+	data := []byte("hello")
+	if _, err := objAPI.PutObject("testbucket", "testobject", int64(len(data)), bytes.NewReader(data), nil, ""); err != nil {
+		t.Fatalf("Put object failed with <ERROR> %s", err)
+	}
+
+	// Invalid bucket names are rejected.
+	args := &LocateObjectArgs{Bucket: "a", Object: "testobject"}
+	if err := client.Call("Control.LocateObjectHandler", args, &LocateObjectReply{}); err != errInvalidArgument {
+		t.Errorf("Expected %s, got %v", errInvalidArgument, err)
+	}
+
+	// Objects which do not exist are missing on all the disks.
+	args = &LocateObjectArgs{Bucket: "testbucket", Object: "missing"}
+	reply := LocateObjectReply{}
+	if err := client.Call("Control.LocateObjectHandler", args, &reply); err != nil {
+		t.Fatalf("Locate object failed with <ERROR> %s", err)
+	}
+	for i, location := range reply.Disks {
+		if location.StoredIndex != 0 || location.Err != "" {
+			t.Errorf("Disk %d: Expected object to be missing, got %v", i+1, location)
+		}
+	}
+
+	// Remove a part from a single disk.
+	if err := os.Remove(filepath.Join(s.testServer.Disks[0], "testbucket", "testobject", "part.1")); err != nil {
+		t.Fatal(err)
+	}
+
+	args = &LocateObjectArgs{Bucket: "testbucket", Object: "testobject"}
+	reply = LocateObjectReply{}
+	if err := client.Call("Control.LocateObjectHandler", args, &reply); err != nil {
+		t.Fatalf("Locate object failed with <ERROR> %s", err)
+	}
+	if len(reply.Disks) != len(s.testServer.Disks) {
+		t.Fatalf("Expected %d disks, got %d", len(s.testServer.Disks), len(reply.Disks))
+	}
+	expectedOrder := hashOrder("testobject", len(s.testServer.Disks))
+	for i, location := range reply.Disks {
+		if location.BlockIndex != expectedOrder[i] || location.StoredIndex != location.BlockIndex {
+			t.Errorf("Disk %d: Expected block index %d, got %d stored as %d", i+1, expectedOrder[i], location.BlockIndex, location.StoredIndex)
+		}
+		if location.Disk == s.testServer.Disks[0] {
+			if len(location.Parts) != 0 || len(location.MissingParts) != 1 || location.MissingParts[0] != "part.1" {
+				t.Errorf("Disk %d: Expected part.1 to be missing, got %v", i+1, location)
+			}
+			continue
+		}
+		if len(location.Parts) != 1 || location.Parts[0] != "part.1" || len(location.MissingParts) != 0 {
+			t.Errorf("Disk %d: Expected part.1 to be present, got %v", i+1, location)
+		}
+	}
+}
+
+func TestControlRenameBucketH(t *testing.T) {
+	// Setup code
+	s := &TestRPCControlSuite{serverType: "XL"}
+	s.SetUpSuite(t)
+
+	// Run test
+	s.testControlRenameBucketH(t)
+
+	// Teardown code
+	s.TearDownSuite(t)
+}
+
+// Tests renaming a bucket via `RenameBucketHandler`.
+func (s *TestRPCControlSuite) testControlRenameBucketH(t *testing.T) {
+	client := newAuthClient(s.testAuthConf)
+	defer client.Close()
+
+	objAPI := newObjectLayerFn()
+	if err := objAPI.MakeBucket("testbucket"); err != nil {
+		t.Fatalf("Create bucket failed with <ERROR> %s", err)
+	}
+	data := []byte("hello")
+	if _, err := objAPI.PutObject("testbucket", "testobject", int64(len(data)), bytes.NewReader(data), nil, ""); err != nil {
+		t.Fatalf("Put object failed with <ERROR> %s", err)
+	}
+	settings := &bucketSettings{CacheControl: "no-cache"}
+	if err := writeBucketSettings("testbucket", objAPI, settings); err != nil {
+		t.Fatalf("Write bucket settings failed with <ERROR> %s", err)
+	}
+
+	// Buckets which do not exist can not be renamed.
+	args := &RenameBucketArgs{SrcBucket: "missing", DstBucket: "newbucket"}
+	if err := client.Call("Control.RenameBucketHandler", args, &GenericReply{}); err == nil {
+		t.Fatal("Expected rename of a missing bucket to fail")
+	}
+
+	args = &RenameBucketArgs{SrcBucket: "testbucket", DstBucket: "newbucket"}
+	if err := client.Call("Control.RenameBucketHandler", args, &GenericReply{}); err != nil {
+		t.Fatalf("Rename bucket failed with <ERROR> %s", err)
+	}
+	if _, err := objAPI.GetObjectInfo("newbucket", "testobject"); err != nil {
+		t.Errorf("Expected object to be renamed, got %v", err)
+	}
+
+	// Settings are renamed along with the bucket.
+	renamed, err := readBucketSettings("newbucket", objAPI)
+	if err != nil {
+		t.Fatalf("Read bucket settings failed with <ERROR> %s", err)
+	}
+	if renamed.CacheControl != "no-cache" {
+		t.Errorf("Expected settings to be renamed, got %v", renamed)
+	}
+}
+
+func TestControlEventStatsH(t *testing.T) {
+	// Setup code
+	s := &TestRPCControlSuite{serverType: "XL"}
+	s.SetUpSuite(t)
+
+	// Run test
+	s.testControlEventStatsH(t)
+
+	// Teardown code
+	s.TearDownSuite(t)
+}
+
+// Tests event statistics via `EventStatsHandler`.
+func (s *TestRPCControlSuite) testControlEventStatsH(t *testing.T) {
+	client := newAuthClient(s.testAuthConf)
+	defer client.Close()
+
+	objAPI := newObjectLayerFn()
+	if err := objAPI.MakeBucket("statsbucket"); err != nil {
+		t.Fatalf("Create bucket failed with <ERROR> %s", err)
+	}
+	arn := "arn:minio:sqs:us-east-1:1:redis"
+	globalEventStats.Generated("statsbucket", arn)
+	globalEventStats.Sent("statsbucket", arn, nil)
+	defer globalEventStats.Remove("statsbucket")
+
+	// Buckets which do not exist have no statistics.
+	args := &EventStatsArgs{Bucket: "missing"}
+	if err := client.Call("Control.EventStatsHandler", args, &EventStatsReply{}); err == nil {
+		t.Fatal("Expected event stats of a missing bucket to fail")
+	}
+
+	args = &EventStatsArgs{Bucket: "statsbucket"}
+	reply := &EventStatsReply{}
+	if err := client.Call("Control.EventStatsHandler", args, reply); err != nil {
+		t.Fatalf("Event stats failed with <ERROR> %s", err)
+	}
+	expected := eventTargetStats{Generated: 1, Delivered: 1}
+	if reply.Stats[arn] != expected {
+		t.Errorf("Expected %v, got %v", expected, reply.Stats)
+	}
+}
+
+func TestControlCacheStatsH(t *testing.T) {
+	// Setup code
+	s := &TestRPCControlSuite{serverType: "XL"}
+	s.SetUpSuite(t)
+
+	// Run test
+	s.testControlCacheStatsH(t)
+
+	// Teardown code
+	s.TearDownSuite(t)
+}
+
+// Tests object cache statistics via `CacheStatsHandler`.
+func (s *TestRPCControlSuite) testControlCacheStatsH(t *testing.T) {
+	client := newAuthClient(s.testAuthConf)
+	defer client.Close()
+
+	objAPI := newObjectLayerFn()
+	if err := objAPI.MakeBucket("cachebucket"); err != nil {
+		t.Fatalf("Create bucket failed with <ERROR> %s", err)
+	}
+	data := []byte("hello")
+	if _, err := objAPI.PutObject("cachebucket", "object", int64(len(data)), bytes.NewReader(data), nil, ""); err != nil {
+		t.Fatalf("Put object failed with <ERROR> %s", err)
+	}
+	if err := objAPI.GetObject("cachebucket", "object", 0, int64(len(data)), ioutil.Discard); err != nil {
+		t.Fatalf("Get object failed with <ERROR> %s", err)
+	}
+
+	reply := &CacheStatsReply{}
+	if err := client.Call("Control.CacheStatsHandler", &CacheStatsArgs{}, reply); err != nil {
+		t.Fatalf("Cache stats failed with <ERROR> %s", err)
+	}
+	if len(reply.Stats) != 1 {
+		t.Fatalf("Expected stats of a single node, got %v", reply.Stats)
+	}
+	for node, stats := range reply.Stats {
+		if stats.MaxSize > 0 && (stats.Hits != 1 || stats.HitRatio != 1) {
+			t.Errorf("%s: expected a single cache hit, got %+v", node, stats)
+		}
+	}
+}
+
+func TestControlCacheWarmH(t *testing.T) {
+	// Setup code
+	s := &TestRPCControlSuite{serverType: "XL"}
+	s.SetUpSuite(t)
+
+	// Run test
+	s.testControlCacheWarmH(t)
+
+	// Teardown code
+	s.TearDownSuite(t)
+}
+
+// Tests warming the object cache via `CacheWarmHandler`.
+func (s *TestRPCControlSuite) testControlCacheWarmH(t *testing.T) {
+	client := newAuthClient(s.testAuthConf)
+	defer client.Close()
+
+	objAPI := newObjectLayerFn()
+	if err := objAPI.MakeBucket("warmbucket"); err != nil {
+		t.Fatalf("Create bucket failed with <ERROR> %s", err)
+	}
+	data := []byte("hello")
+	for _, object := range []string{"a/1", "a/2", "a/3", "b/1"} {
+		if _, err := objAPI.PutObject("warmbucket", object, int64(len(data)), bytes.NewReader(data), nil, ""); err != nil {
+			t.Fatalf("Put object failed with <ERROR> %s", err)
+		}
+	}
+
+	testCases := []struct {
+		args       CacheWarmArgs
+		warmed     int
+		bytes      int64
+		shouldPass bool
+	}{
+		// Test 1: all the objects of a bucket.
+		{CacheWarmArgs{Bucket: "warmbucket"}, 4, 20, true},
+		// Test 2: objects of a prefix, throttled.
+		{CacheWarmArgs{Bucket: "warmbucket", Prefix: "a/", Rate: 100}, 3, 15, true},
+		// Test 3: first block only.
+		{CacheWarmArgs{Bucket: "warmbucket", Prefix: "b/", Mode: cacheWarmFirstBlock}, 1, 5, true},
+		// Test 4: metadata only.
+		{CacheWarmArgs{Bucket: "warmbucket", Mode: cacheWarmMetadata}, 4, 0, true},
+		// Test 5: unknown mode.
+		{CacheWarmArgs{Bucket: "warmbucket", Mode: "unknown"}, 0, 0, false},
+		// Test 6: invalid bucket.
+		{CacheWarmArgs{Bucket: ""}, 0, 0, false},
+		// Test 7: negative rate.
+		{CacheWarmArgs{Bucket: "warmbucket", Rate: -1}, 0, 0, false},
+		// Test 8: rate too high to throttle.
+		{CacheWarmArgs{Bucket: "warmbucket", Rate: cacheWarmMaxRate + 1}, 0, 0, false},
+	}
+	for i, testCase := range testCases {
+		args := testCase.args
+		reply := &CacheWarmReply{}
+		err := client.Call("Control.CacheWarmHandler", &args, reply)
+		if testCase.shouldPass && err != nil {
+			t.Fatalf("Test %d: Cache warm failed with <ERROR> %s", i+1, err)
+		}
+		if !testCase.shouldPass && err == nil {
+			t.Fatalf("Test %d: Expected cache warm to fail", i+1)
+		}
+		if reply.Warmed != testCase.warmed || reply.Bytes != testCase.bytes || len(reply.Failed) != 0 || reply.IsTruncated {
+			t.Errorf("Test %d: Unexpected reply %+v", i+1, reply)
+		}
+	}
+
+	stats, err := objAPI.CacheStats()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.MaxSize > 0 && stats.Entries != 4 {
+		t.Errorf("Expected 4 cached objects, got %+v", stats)
+	}
+
+	// Warming continues after the marker of the previous call.
+	neverCancelled := func() bool { return false }
+	reply, err := warmCache(objAPI, "node", "warmbucket", "", "", cacheWarmMetadata, 0, 3, neverCancelled)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reply.Warmed != 3 || !reply.IsTruncated || reply.NextMarker != "a/3" {
+		t.Fatalf("Unexpected reply %+v", reply)
+	}
+	reply, err = warmCache(objAPI, "node", "warmbucket", "", reply.NextMarker, cacheWarmMetadata, 0, 3, neverCancelled)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reply.Warmed != 1 || reply.IsTruncated {
+		t.Fatalf("Unexpected reply %+v", reply)
+	}
+	cancelled := func() bool { return true }
+	if _, err = warmCache(objAPI, "node", "warmbucket", "", "", cacheWarmMetadata, 0, 3, cancelled); err != errRPCCancelled {
+		t.Fatalf("Expected %s, got %s", errRPCCancelled, err)
+	}
+}
+
+func TestControlLockInfoStreamH(t *testing.T) {
+	// Setup code
+	s := &TestRPCControlSuite{serverType: "XL"}
+	s.SetUpSuite(t)
+
+	// Run test
+	s.testControlLockInfoStreamH(t)
+
+	// Teardown code
+	s.TearDownSuite(t)
+}
+
+// Tests reading lock info per node via `LockInfoStream`.
+func (s *TestRPCControlSuite) testControlLockInfoStreamH(t *testing.T) {
+	client := newAuthClient(s.testAuthConf)
+	defer client.Close()
+
+	nsMutex.RLock("stream-bucket", "stream-object", "stream-1")
+	defer nsMutex.RUnlock("stream-bucket", "stream-object", "stream-1")
+
+	startReply := &ReplyStreamStartReply{}
+	if err := client.Call("Control.LockInfoStream", &GenericArgs{}, startReply); err != nil {
+		t.Fatalf("Lock info stream failed with <ERROR> %s", err)
+	}
+	if len(startReply.Nodes) != 1 {
+		t.Fatalf("Expected a single node, got %v", startReply.Nodes)
+	}
+
+	var chunks []NodeReplyChunk
+	nextArgs := &ReplyStreamNextArgs{StreamID: startReply.StreamID}
+	for done := false; !done; {
+		nextReply := &ReplyStreamNextReply{}
+		if err := client.Call("Control.NextReplyChunks", nextArgs, nextReply); err != nil {
+			t.Fatalf("Next reply chunks failed with <ERROR> %s", err)
+		}
+		chunks = append(chunks, nextReply.Chunks...)
+		done = nextReply.Done
+	}
+	if len(chunks) != 1 || chunks[0].Node != startReply.Nodes[0] || chunks[0].Error != "" {
+		t.Fatalf("Expected the reply of node %v, got %v", startReply.Nodes, chunks)
+	}
+	var lockState SystemLockState
+	if err := json.Unmarshal(chunks[0].Reply, &lockState); err != nil {
+		t.Fatalf("Unable to decode lock info: <ERROR> %s", err)
+	}
+	found := false
+	for _, lockInfo := range lockState.LocksInfoPerObject {
+		if lockInfo.Bucket == "stream-bucket" && lockInfo.Object == "stream-object" {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("Expected lock on stream-bucket/stream-object, got %v", lockState)
+	}
+
+	// Streams can not be read after the end.
+	if err := client.Call("Control.NextReplyChunks", nextArgs, &ReplyStreamNextReply{}); err != errInvalidReplyStream {
+		t.Errorf("Expected %s reading a finished stream, got %v", errInvalidReplyStream, err)
+	}
+}
+
+func TestControlRemoteCalls(t *testing.T) {
+	// Setup code
+	s := &TestRPCControlSuite{serverType: "XL"}
+	s.SetUpSuite(t)
+
+	// Run test
+	s.testControlRemoteCalls(t)
+
+	// Teardown code
+	s.TearDownSuite(t)
+}
+
+// Tests a failing node does not hide the replies of the other nodes.
+func (s *TestRPCControlSuite) testControlRemoteCalls(t *testing.T) {
+	client := newAuthClient(s.testAuthConf)
+	defer client.Close()
+	unreachableConf := *s.testAuthConf
+	unreachableConf.address = "127.0.0.1:1"
+	unreachable := newAuthClient(&unreachableConf)
+	defer unreachable.Close()
+
+	remoteControls := []*AuthRPCClient{client, unreachable}
+	replies := make([]SystemLockState, len(remoteControls))
+	errsMap := callRemoteControls(remoteControls, "Control.RemoteLockInfo", &GenericArgs{}, func(index int) interface{} {
+		return &replies[index]
+	})
+	if len(errsMap) != 1 || errsMap[unreachable.Node()] == nil {
+		t.Fatalf("Expected only %s to fail, got %v", unreachable.Node(), errsMap)
+	}
+	if err := nodeErrorsToError(errsMap); err == nil || !strings.HasPrefix(err.Error(), unreachable.Node()+": ") {
+		t.Errorf("Expected error of %s, got %v", unreachable.Node(), err)
+	}
+	if err := nodeErrorsToError(map[string]error{}); err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+}
+
+func TestControlCancelH(t *testing.T) {
+	// Setup code
+	s := &TestRPCControlSuite{serverType: "XL"}
+	s.SetUpSuite(t)
+
+	// Run test
+	s.testControlCancelH(t)
+
+	// Teardown code
+	s.TearDownSuite(t)
+}
+
+// Tests healing stops once cancelled via `CancelHandler`.
+func (s *TestRPCControlSuite) testControlCancelH(t *testing.T) {
+	client := newAuthClient(s.testAuthConf)
+	defer client.Close()
+
+	objAPI := newObjectLayerFn()
+	if err := objAPI.MakeBucket("cancelbucket"); err != nil {
+		t.Fatalf("Create bucket failed with <ERROR> %s", err)
+	}
+
+	if err := client.Call("Control.CancelHandler", &CancelArgs{}, &GenericReply{}); err != errInvalidArgument {
+		t.Fatalf("Expected %v, got %v", errInvalidArgument, err)
+	}
+	if err := client.Call("Control.CancelHandler", &CancelArgs{TargetCallID: "heal-1"}, &GenericReply{}); err != nil {
+		t.Fatalf("Cancel failed with <ERROR> %s", err)
+	}
+
+	args := &HealObjectArgs{
+		GenericArgs: GenericArgs{CallID: "heal-1"},
+		Bucket:      "cancelbucket",
+		Objects:     []ObjectInfo{{Name: "object1"}, {Name: "object2"}},
+	}
+	reply := &HealObjectReply{}
+	if err := client.Call("Control.HealObjectsHandler", args, reply); err != nil {
+		t.Fatalf("Heal objects failed with <ERROR> %s", err)
+	}
+	for _, result := range reply.Results {
+		if result != errRPCCancelled.Error() {
+			t.Errorf("Expected cancelled objects, got %v", reply.Results)
+		}
+	}
+}
+
+func TestControlIdempotentRenameBucketH(t *testing.T) {
+	// Setup code
+	s := &TestRPCControlSuite{serverType: "XL"}
+	s.SetUpSuite(t)
+
+	// Run test
+	s.testControlIdempotentRenameBucketH(t)
+
+	// Teardown code
+	s.TearDownSuite(t)
+}
+
+// Tests a retried `RenameBucketHandler` call is not applied twice.
+func (s *TestRPCControlSuite) testControlIdempotentRenameBucketH(t *testing.T) {
+	client := newAuthClient(s.testAuthConf)
+	defer client.Close()
+
+	objAPI := newObjectLayerFn()
+	if err := objAPI.MakeBucket("retrybucket"); err != nil {
+		t.Fatalf("Create bucket failed with <ERROR> %s", err)
+	}
+
+	args := &RenameBucketArgs{
+		GenericArgs: GenericArgs{IdempotencyKey: "rename-1"},
+		SrcBucket:   "retrybucket",
+		DstBucket:   "retriedbucket",
+	}
+	for i := 0; i < 2; i++ {
+		if err := client.Call("Control.RenameBucketHandler", args, &GenericReply{}); err != nil {
+			t.Fatalf("Attempt %d: rename bucket failed with <ERROR> %s", i+1, err)
+		}
+	}
+
+	// Without the key the retry fails.
+	args.IdempotencyKey = ""
+	if err := client.Call("Control.RenameBucketHandler", args, &GenericReply{}); err == nil {
+		t.Fatal("Expected rename of a renamed bucket to fail")
+	}
+}
+
+func TestControlScheduleH(t *testing.T) {
+	// Setup code
+	s := &TestRPCControlSuite{serverType: "XL"}
+	s.SetUpSuite(t)
+
+	// Run test
+	s.testControlScheduleH(t)
+
+	// Teardown code
+	s.TearDownSuite(t)
+}
+
+// Tests overriding the background window via `ScheduleHandler`.
+func (s *TestRPCControlSuite) testControlScheduleH(t *testing.T) {
+	client := newAuthClient(s.testAuthConf)
+	defer client.Close()
+
+	window, err := parseBackgroundWindow("01:00-05:00")
+	if err != nil {
+		t.Fatal(err)
+	}
+	globalBackgroundSchedule.SetWindow(window)
+	defer func() {
+		globalBackgroundSchedule = newBackgroundSchedule()
+	}()
+
+	args := &ScheduleArgs{RunFor: time.Hour, Reset: true}
+	if err = client.Call("Control.ScheduleHandler", args, &ScheduleReply{}); err == nil {
+		t.Fatal("Expected schedule with both run-for and reset to fail")
+	}
+
+	args = &ScheduleArgs{RunFor: time.Hour}
+	reply := &ScheduleReply{}
+	if err = client.Call("Control.ScheduleHandler", args, reply); err != nil {
+		t.Fatalf("Schedule failed with <ERROR> %s", err)
+	}
+	if reply.Window != "01:00-05:00" || reply.OverrideUntil.IsZero() {
+		t.Fatalf("Unexpected schedule reply %#v", reply)
+	}
+	if !globalBackgroundSchedule.IsOpen(time.Now()) {
+		t.Fatal("Expected background tasks to run while overridden")
+	}
+
+	args = &ScheduleArgs{Reset: true}
+	reply = &ScheduleReply{}
+	if err = client.Call("Control.ScheduleHandler", args, reply); err != nil {
+		t.Fatalf("Schedule failed with <ERROR> %s", err)
+	}
+	if !reply.OverrideUntil.IsZero() {
+		t.Fatalf("Expected override to be reset, got %s", reply.OverrideUntil)
+	}
+}
+
+func TestControlTasksH(t *testing.T) {
+	// Setup code
+	s := &TestRPCControlSuite{serverType: "XL"}
+	s.SetUpSuite(t)
+
+	// Run test
+	s.testControlTasksH(t)
+
+	// Teardown code
+	s.TearDownSuite(t)
+}
+
+// Tests background task status and controls via `TaskStatusHandler`
+// and `TaskControlHandler`.
+func (s *TestRPCControlSuite) testControlTasksH(t *testing.T) {
+	client := newAuthClient(s.testAuthConf)
+	defer client.Close()
+
+	globalTaskManager.Add("test-task", time.Hour, func() error { return nil })
+	defer func() {
+		globalTaskManager = newTaskManager()
+	}()
+
+	err := client.Call("Control.TaskControlHandler", &TaskControlArgs{Task: "missing-task", Pause: true}, &GenericReply{})
+	if err != errTaskNotFound {
+		t.Fatalf("Expected %s, got %v", errTaskNotFound, err)
+	}
+	if err = client.Call("Control.TaskControlHandler", &TaskControlArgs{Task: "test-task", Pause: true}, &GenericReply{}); err != nil {
+		t.Fatalf("Pausing task failed with <ERROR> %s", err)
+	}
+
+	reply := &TaskStatusReply{}
+	if err = client.Call("Control.TaskStatusHandler", &TaskStatusArgs{}, reply); err != nil {
+		t.Fatalf("Task status failed with <ERROR> %s", err)
+	}
+	if len(reply.Nodes) != 1 || len(reply.Nodes[0].Tasks) != 1 {
+		t.Fatalf("Unexpected task status reply %#v", reply)
+	}
+	if status := reply.Nodes[0].Tasks[0]; status.Name != "test-task" || status.State != taskPaused {
+		t.Fatalf("Unexpected task status %#v", status)
+	}
+}
+
+func TestControlPolicySimulateH(t *testing.T) {
+	// Setup code
+	s := &TestRPCControlSuite{serverType: "XL"}
+	s.SetUpSuite(t)
+
+	// Run test
+	s.testControlPolicySimulateH(t)
+
+	// Teardown code
+	s.TearDownSuite(t)
+}
+
+// Tests simulating requests via `PolicySimulateHandler`.
+func (s *TestRPCControlSuite) testControlPolicySimulateH(t *testing.T) {
+	client := newAuthClient(s.testAuthConf)
+	defer client.Close()
+
+	obj := newObjectLayerFn()
+	if err := initBucketPolicies(obj); err != nil {
+		t.Fatal(err)
+	}
+	if err := obj.MakeBucket("songs"); err != nil {
+		t.Fatal(err)
+	}
+
+	// Without a policy anonymous requests are denied.
+	args := &PolicySimulateArgs{Action: "s3:GetObject", Bucket: "songs", Object: "piano.mp3"}
+	reply := PolicySimulateReply{}
+	if err := client.Call("Control.PolicySimulateHandler", args, &reply); err != nil {
+		t.Fatalf("Simulation failed with <ERROR> %s", err)
+	}
+	if reply.Allowed || reply.Source != policySourceBucketPolicy || reply.StatementIndex != 0 {
+		t.Fatalf("Unexpected simulation reply %#v", reply)
+	}
+
+	policyStr := `{"Version":"2012-10-17","Statement":[` +
+		`{"Action":["s3:GetObject"],"Effect":"Deny","Principal":{"AWS":["*"]},"Resource":["arn:aws:s3:::songs/private/*"],"Sid":"private"},` +
+		`{"Action":["s3:GetObject"],"Effect":"Allow","Principal":{"AWS":["*"]},"Resource":["arn:aws:s3:::songs/*"],"Sid":"public"},` +
+		`{"Action":["s3:ListBucket"],"Condition":{"StringEquals":{"s3:prefix":["public/"]}},"Effect":"Allow","Principal":{"AWS":["*"]},"Resource":["arn:aws:s3:::songs"],"Sid":"list"}]}`
+	policy := &bucketPolicy{}
+	if err := parseBucketPolicy(strings.NewReader(policyStr), policy); err != nil {
+		t.Fatal(err)
+	}
+	globalBucketPolicies.SetBucketPolicy("songs", policyChange{BktPolicy: policy})
+
+	testCases := []struct {
+		args           PolicySimulateArgs
+		allowed        bool
+		source         string
+		statementIndex int
+	}{
+		{PolicySimulateArgs{Action: "s3:GetObject", Bucket: "songs", Object: "piano.mp3"}, true, policySourceBucketPolicy, 2},
+		{PolicySimulateArgs{Action: "s3:GetObject", Bucket: "songs", Object: "private/piano.mp3"}, false, policySourceBucketPolicy, 1},
+		{PolicySimulateArgs{Action: "s3:PutObject", Bucket: "songs", Object: "piano.mp3"}, false, policySourceBucketPolicy, 0},
+		{PolicySimulateArgs{Action: "s3:ListBucket", Bucket: "songs", Conditions: map[string]string{"prefix": "public/"}}, true, policySourceBucketPolicy, 3},
+		{PolicySimulateArgs{Action: "s3:ListBucket", Bucket: "songs", Conditions: map[string]string{"prefix": "private/"}}, false, policySourceBucketPolicy, 0},
+		{PolicySimulateArgs{AccessKey: s.testServer.AccessKey, Action: "s3:PutObject", Bucket: "songs", Object: "private/piano.mp3"}, true, policySourceCredential, 0},
+		{PolicySimulateArgs{AccessKey: "UNKNOWNACCESSKEY", Action: "s3:GetObject", Bucket: "songs", Object: "piano.mp3"}, false, policySourceCredential, 0},
+	}
+	for i, testCase := range testCases {
+		reply = PolicySimulateReply{}
+		if err := client.Call("Control.PolicySimulateHandler", &testCase.args, &reply); err != nil {
+			t.Fatalf("Test %d: Simulation failed with <ERROR> %s", i+1, err)
+		}
+		if reply.Allowed != testCase.allowed || reply.Source != testCase.source || reply.StatementIndex != testCase.statementIndex {
+			t.Errorf("Test %d: Unexpected simulation reply %#v", i+1, reply)
+		}
+	}
+
+	// Buckets have to exist.
+	args = &PolicySimulateArgs{Action: "s3:GetObject", Bucket: "missing", Object: "piano.mp3"}
+	if err := client.Call("Control.PolicySimulateHandler", args, &PolicySimulateReply{}); err == nil {
+		t.Fatal("Expected simulation on a missing bucket to fail")
+	}
+}
+
+func TestControlRotateKeysH(t *testing.T) {
+	// Setup code
+	s := &TestRPCControlSuite{serverType: "XL"}
+	s.SetUpSuite(t)
+
+	// Run test
+	s.testControlRotateKeysH(t)
+
+	// Teardown code
+	s.TearDownSuite(t)
+}
+
+// Tests rotating keys via `RotateKeysHandler`.
+func (s *TestRPCControlSuite) testControlRotateKeysH(t *testing.T) {
+	client := newAuthClient(s.testAuthConf)
+	defer client.Close()
+
+	obj := newObjectLayerFn()
+	if err := obj.MakeBucket("songs"); err != nil {
+		t.Fatal(err)
+	}
+
+	// Encryption has to be enabled.
+	args := &RotateKeysArgs{Bucket: "songs"}
+	if err := client.Call("Control.RotateKeysHandler", args, &RotateKeysReply{}); err == nil {
+		t.Fatal("Expected rotation without a master key to fail")
+	}
+
+	globalSSEMasterKey = bytes.Repeat([]byte{1}, sseMasterKeySize)
+	defer func() { globalSSEMasterKey = nil }()
+	data := []byte("piano")
+	metadata := map[string]string{sseMetaAlgorithm: sseAlgorithmAES256}
+	if _, err := newEncryptedObjects(obj).PutObject("songs", "piano.mp3", int64(len(data)), bytes.NewReader(data), metadata, ""); err != nil {
+		t.Fatal(err)
+	}
+	reply := RotateKeysReply{}
+	if err := client.Call("Control.RotateKeysHandler", args, &reply); err != nil {
+		t.Fatalf("Rotation failed with <ERROR> %s", err)
+	}
+	if reply.Scanned != 1 || reply.Encrypted != 1 || reply.Rotated != 0 || reply.IsTruncated {
+		t.Fatalf("Unexpected rotation reply %#v", reply)
+	}
+}
+
+func TestControlAlertRulesH(t *testing.T) {
+	// Setup code
+	s := &TestRPCControlSuite{serverType: "XL"}
+	s.SetUpSuite(t)
+
+	// Run test
+	s.testControlAlertRulesH(t)
+
+	// Teardown code
+	s.TearDownSuite(t)
+}
+
+// Tests adding and removing alert rules via `AlertRulesHandler`.
+func (s *TestRPCControlSuite) testControlAlertRulesH(t *testing.T) {
+	client := newAuthClient(s.testAuthConf)
+	defer client.Close()
+	defer globalAlertEngine.SetRules(nil)
+
+	rule := alertRule{Name: "errors", Metric: alertMetric5xxRate, Threshold: 5, Target: "http://localhost/"}
+	testCases := []struct {
+		args  *AlertRulesArgs
+		rules []alertRule
+		err   error
+	}{
+		{&AlertRulesArgs{Set: &rule}, []alertRule{rule}, nil},
+		{&AlertRulesArgs{}, []alertRule{rule}, nil},
+		{&AlertRulesArgs{Set: &alertRule{Name: "invalid"}}, nil, errInvalidAlertRule},
+		{&AlertRulesArgs{Remove: "missing"}, nil, errAlertRuleNotFound},
+		{&AlertRulesArgs{Remove: "errors"}, nil, nil},
+	}
+	for i, testCase := range testCases {
+		reply := &AlertRulesReply{}
+		err := client.Call("Control.AlertRulesHandler", testCase.args, reply)
+		if err != testCase.err {
+			t.Fatalf("Test %d: Expected %v, got %v", i+1, testCase.err, err)
+		}
+		if err == nil && !reflect.DeepEqual(reply.Rules, testCase.rules) {
+			t.Errorf("Test %d: Expected %v, got %v", i+1, testCase.rules, reply.Rules)
+		}
+		if err == nil && !reflect.DeepEqual(globalAlertEngine.Rules(), testCase.rules) {
+			t.Errorf("Test %d: Expected %v loaded, got %v", i+1, testCase.rules, globalAlertEngine.Rules())
+		}
+	}
+}
+
+func TestControlConcurrentLoginH(t *testing.T) {
+	// Setup code
+	s := &TestRPCControlSuite{serverType: "XL"}
+	s.SetUpSuite(t)
+
+	// Run test
+	s.testControlConcurrentLoginH(t)
+
+	// Teardown code
+	s.TearDownSuite(t)
+}
+
+// Tests concurrent calls of a shared client while its token is renewed.
+func (s *TestRPCControlSuite) testControlConcurrentLoginH(t *testing.T) {
+	client := newAuthClient(s.testAuthConf)
+	defer client.Close()
+
+	var wg sync.WaitGroup
+	errs := make([]error, 8)
+	for i := range errs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 5 && errs[i] == nil; j++ {
+				// Expire the token, the next call logs in again.
+				client.mu.Lock()
+				client.tokenExpiry = time.Time{}
+				client.mu.Unlock()
+				reply := make(map[string]*SystemLockState)
+				errs[i] = client.Call("Control.LockInfo", &GenericArgs{}, &reply)
+			}
+		}(i)
+	}
+	wg.Wait()
+	for i, err := range errs {
+		if err != nil {
+			t.Errorf("Caller %d: Unexpected error %v", i+1, err)
+		}
+	}
+}
@@ -0,0 +1,95 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/minio/cli"
+)
+
+// "minio credentials" command.
+var credentialsCmd = cli.Command{
+	Name:   "credentials",
+	Usage:  "Manage access and secret keys.",
+	Flags:  globalFlags,
+	Action: mainCredentials,
+	Subcommands: []cli.Command{
+		credentialsGenerateCmd,
+	},
+	CustomHelpTemplate: `NAME:
+   {{.Name}} - {{.Usage}}
+
+USAGE:
+   {{.Name}} [FLAGS] COMMAND
+
+FLAGS:
+  {{range .Flags}}{{.}}
+  {{end}}
+COMMANDS:
+   {{range .Commands}}{{join .Names ", "}}{{ "\t" }}{{.Usage}}
+   {{end}}
+`,
+}
+
+// "minio credentials generate" command.
+var credentialsGenerateCmd = cli.Command{
+	Name:   "generate",
+	Usage:  "Generate a random access and secret key pair.",
+	Flags:  globalFlags,
+	Action: mainCredentialsGenerate,
+	CustomHelpTemplate: `NAME:
+   minio credentials {{.Name}} - {{.Usage}}
+
+USAGE:
+   minio credentials {{.Name}}
+
+FLAGS:
+  {{range .Flags}}{{.}}
+  {{end}}
+DESCRIPTION:
+   The generated secret key meets the secret key policy. The pair is printed
+   to stdout as shell variable assignments and is not saved in the config.
+
+ENVIRONMENT VARIABLES:
+  SECURITY:
+     MINIO_SECRET_KEY_MIN_LENGTH: Minimum secret key length, between 8 and 40 characters. Defaults to 8.
+     MINIO_SECRET_KEY_MIN_ENTROPY: Minimum estimated secret key entropy in bits, up to 128. Defaults to 0.
+
+EXAMPLES:
+   1. Generate credentials and start the server with them.
+      $ eval $(minio credentials {{.Name}})
+      $ export MINIO_ACCESS_KEY MINIO_SECRET_KEY
+      $ minio server /home/shared
+`,
+}
+
+func mainCredentials(ctx *cli.Context) {
+	cli.ShowAppHelp(ctx)
+}
+
+func mainCredentialsGenerate(ctx *cli.Context) {
+	if len(ctx.Args()) != 0 {
+		cli.ShowCommandHelpAndExit(ctx, "generate", 1)
+	}
+
+	cred, err := genAccessKeys()
+	fatalIf(err, "Unable to generate credentials meeting the secret key policy.")
+
+	fmt.Printf("MINIO_ACCESS_KEY=%s\n", cred.AccessKeyID)
+	fmt.Printf("MINIO_SECRET_KEY=%s\n", cred.SecretAccessKey)
+}
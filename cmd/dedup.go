@@ -0,0 +1,682 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"hash"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Objects of buckets with deduplication enabled are stored as JSON
+// manifests listing the chunks of their data. Chunks are named by the
+// SHA-256 of their content and stored once per bucket in
+// '.minio.sys/dedup/<bucket>/chunks/<sha256>', along with the number of
+// manifests referencing them in '.minio.sys/dedup/<bucket>/refs/<sha256>'.
+const (
+	dedupPrefix          = "dedup"
+	dedupChunksPrefix    = "chunks"
+	dedupRefsPrefix      = "refs"
+	dedupManifestVersion = "1"
+
+	// Size of chunks, objects are split at fixed offsets.
+	dedupChunkSize = 4 * 1024 * 1024 // 4MiB.
+
+	// Interval at which unreferenced chunks are deleted.
+	dedupGCInterval = 1 * time.Hour
+	// Chunks are only deleted once unreferenced for this long, so that
+	// reads of overwritten or deleted objects still in flight complete.
+	dedupGCExpiry = 1 * time.Hour
+)
+
+// errDedupSettingsChange - deduplication can only be enabled on empty
+// buckets and never disabled.
+var errDedupSettingsChange = errors.New("Deduplication can only be enabled on empty buckets and cannot be disabled")
+
+// errDedupInvalidManifest - object of a deduplicated bucket is not a
+// valid manifest.
+var errDedupInvalidManifest = errors.New("Invalid deduplication manifest")
+
+// dedupChunk - chunk of an object.
+type dedupChunk struct {
+	Hash string `json:"hash"`
+	Size int64  `json:"size"`
+}
+
+// dedupManifest - object of a deduplicated bucket.
+type dedupManifest struct {
+	Version     string            `json:"version"`
+	Size        int64             `json:"size"`
+	MD5Sum      string            `json:"md5Sum"`
+	ModTime     time.Time         `json:"modTime"`
+	UserDefined map[string]string `json:"meta,omitempty"`
+	Chunks      []dedupChunk      `json:"chunks"`
+	// Parts of multipart objects, as recorded by the object layer.
+	Parts []objectPartInfo `json:"parts,omitempty"`
+}
+
+// toObjectInfo - returns the object info of the manifest object.
+func (m dedupManifest) toObjectInfo(bucket, object string) ObjectInfo {
+	return ObjectInfo{
+		Bucket:          bucket,
+		Name:            object,
+		ModTime:         m.ModTime,
+		Size:            m.Size,
+		MD5Sum:          m.MD5Sum,
+		ContentType:     m.UserDefined["content-type"],
+		ContentEncoding: m.UserDefined["content-encoding"],
+		UserDefined:     m.UserDefined,
+	}
+}
+
+// isDedupBucket - returns true if deduplication is enabled for bucket.
+func isDedupBucket(bucket string) bool {
+	settings := globalBucketSettings.GetBucketSettings(bucket)
+	return settings != nil && settings.Dedup
+}
+
+// checkDedupSettingsChange - returns errDedupSettingsChange if the new
+// settings of a bucket, nil when removed, change deduplication in a way
+// that is not allowed.
+func checkDedupSettingsChange(bucket string, settings *bucketSettings, objAPI ObjectLayer) error {
+	wasDedup := isDedupBucket(bucket)
+	isDedup := settings != nil && settings.Dedup
+	if wasDedup == isDedup {
+		return nil
+	}
+	if wasDedup {
+		return errDedupSettingsChange
+	}
+	result, err := objAPI.ListObjects(bucket, "", "", "", 1)
+	if err != nil {
+		return err
+	}
+	if len(result.Objects) != 0 || len(result.Prefixes) != 0 {
+		return errDedupSettingsChange
+	}
+	return nil
+}
+
+func dedupChunkPath(bucket, hash string) string {
+	return path.Join(dedupPrefix, bucket, dedupChunksPrefix, hash)
+}
+
+func dedupRefsPath(bucket, hash string) string {
+	return path.Join(dedupPrefix, bucket, dedupRefsPrefix, hash)
+}
+
+// Chunks are locked by a path other than the ones written, which are
+// locked by PutObject.
+func dedupChunkLockPath(bucket, hash string) string {
+	return path.Join(dedupPrefix, bucket, hash)
+}
+
+// Manifests are locked in the meta bucket, the object itself is
+// locked by PutObject.
+func dedupManifestLockPath(bucket, object string) string {
+	return path.Join(dedupPrefix, bucket, "objects", object)
+}
+
+// readDedupObject - reads a whole small object.
+func readDedupObject(objAPI ObjectLayer, bucket, object string) ([]byte, error) {
+	objInfo, err := objAPI.GetObjectInfo(bucket, object)
+	if err != nil {
+		return nil, err
+	}
+	var buffer bytes.Buffer
+	if err = objAPI.GetObject(bucket, object, 0, objInfo.Size, &buffer); err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
+
+// readChunkRefs - returns the number of manifests referencing a chunk.
+func readChunkRefs(objAPI ObjectLayer, bucket, hash string) (int64, error) {
+	data, err := readDedupObject(objAPI, minioMetaBucket, dedupRefsPath(bucket, hash))
+	if err != nil {
+		if _, ok := errorCause(err).(ObjectNotFound); ok {
+			return 0, nil
+		}
+		return 0, err
+	}
+	return strconv.ParseInt(string(data), 10, 64)
+}
+
+// writeChunkRefs - saves the number of manifests referencing a chunk.
+func writeChunkRefs(objAPI ObjectLayer, bucket, hash string, refs int64) error {
+	data := []byte(strconv.FormatInt(refs, 10))
+	_, err := objAPI.PutObject(minioMetaBucket, dedupRefsPath(bucket, hash), int64(len(data)), bytes.NewReader(data), nil, "")
+	return err
+}
+
+// addChunk - stores a chunk unless already stored, and adds a
+// reference to it.
+func addChunk(objAPI ObjectLayer, bucket, hash string, data []byte, bypassCache bool) error {
+	opsID := getOpsID()
+	lockPath := dedupChunkLockPath(bucket, hash)
+	nsMutex.Lock(minioMetaBucket, lockPath, opsID)
+	defer nsMutex.Unlock(minioMetaBucket, lockPath, opsID)
+
+	refs, err := readChunkRefs(objAPI, bucket, hash)
+	if err != nil {
+		return err
+	}
+	chunkPath := dedupChunkPath(bucket, hash)
+	if _, err = objAPI.GetObjectInfo(minioMetaBucket, chunkPath); err != nil {
+		if _, ok := errorCause(err).(ObjectNotFound); !ok {
+			return err
+		}
+		if _, err = objAPI.PutObject(minioMetaBucket, chunkPath, int64(len(data)), bytes.NewReader(data), cacheBypassMetadata(bypassCache), ""); err != nil {
+			return err
+		}
+	}
+	return writeChunkRefs(objAPI, bucket, hash, refs+1)
+}
+
+// releaseChunks - removes a reference to each chunk, chunks left
+// without references are deleted later by deleteUnreferencedChunks.
+func releaseChunks(objAPI ObjectLayer, bucket string, chunks []dedupChunk) {
+	for _, chunk := range chunks {
+		opsID := getOpsID()
+		lockPath := dedupChunkLockPath(bucket, chunk.Hash)
+		nsMutex.Lock(minioMetaBucket, lockPath, opsID)
+		refs, err := readChunkRefs(objAPI, bucket, chunk.Hash)
+		if err == nil && refs > 0 {
+			err = writeChunkRefs(objAPI, bucket, chunk.Hash, refs-1)
+		}
+		nsMutex.Unlock(minioMetaBucket, lockPath, opsID)
+		errorIf(err, "Unable to release deduplicated chunk %s of bucket %s.", chunk.Hash, bucket)
+	}
+}
+
+// storeChunks - splits data into chunks and stores them, returns the
+// chunks and the number of bytes read. New chunks bypass the object
+// cache if bypassCache is set.
+func storeChunks(objAPI ObjectLayer, bucket string, data io.Reader, bypassCache bool) ([]dedupChunk, int64, error) {
+	var chunks []dedupChunk
+	var size int64
+	buf := make([]byte, dedupChunkSize)
+	for {
+		n, err := io.ReadFull(data, buf)
+		if n > 0 {
+			sum := sha256.Sum256(buf[:n])
+			chunk := dedupChunk{Hash: hex.EncodeToString(sum[:]), Size: int64(n)}
+			if aErr := addChunk(objAPI, bucket, chunk.Hash, buf[:n], bypassCache); aErr != nil {
+				releaseChunks(objAPI, bucket, chunks)
+				return nil, 0, aErr
+			}
+			chunks = append(chunks, chunk)
+			size += int64(n)
+		}
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return chunks, size, nil
+		}
+		if err != nil {
+			releaseChunks(objAPI, bucket, chunks)
+			return nil, 0, traceError(err)
+		}
+	}
+}
+
+// readManifest - reads the manifest of an object of a deduplicated bucket.
+func readManifest(objAPI ObjectLayer, bucket, object string) (dedupManifest, error) {
+	var manifest dedupManifest
+	data, err := readDedupObject(objAPI, bucket, object)
+	if err != nil {
+		return manifest, err
+	}
+	if err = json.Unmarshal(data, &manifest); err != nil || manifest.Version != dedupManifestVersion {
+		return manifest, traceError(errDedupInvalidManifest)
+	}
+	return manifest, nil
+}
+
+// writeManifest - saves the manifest of an object, the chunks of
+// oldManifest, the manifest known to be replaced if any, are released.
+// The object stored is never read back as the old manifest, it may be
+// data uploaded by a client, see CompleteMultipartUpload. The manifest
+// lock must be held by the caller.
+func writeManifest(objAPI ObjectLayer, bucket, object string, manifest dedupManifest, oldManifest *dedupManifest) error {
+	data, err := json.Marshal(manifest)
+	if err != nil {
+		return err
+	}
+	if _, err = objAPI.PutObject(bucket, object, int64(len(data)), bytes.NewReader(data), nil, ""); err != nil {
+		return err
+	}
+	if oldManifest != nil {
+		releaseChunks(objAPI, bucket, oldManifest.Chunks)
+	}
+	return nil
+}
+
+// dedupObjects - object layer deduplicating the data of buckets with
+// deduplication enabled in their settings, all other buckets are
+// served by the underlying object layer.
+type dedupObjects struct {
+	ObjectLayer
+}
+
+// newDedupObjects - returns objAPI with deduplication.
+func newDedupObjects(objAPI ObjectLayer) ObjectLayer {
+	return dedupObjects{objAPI}
+}
+
+// ListObjects - lists objects, sizes and ETags of deduplicated objects
+// are read from their manifests.
+func (d dedupObjects) ListObjects(bucket, prefix, marker, delimiter string, maxKeys int) (ListObjectsInfo, error) {
+	result, err := d.ObjectLayer.ListObjects(bucket, prefix, marker, delimiter, maxKeys)
+	if err != nil || !isDedupBucket(bucket) {
+		return result, err
+	}
+	for i, objInfo := range result.Objects {
+		if objInfo.IsDir {
+			continue
+		}
+		manifest, mErr := readManifest(d.ObjectLayer, bucket, objInfo.Name)
+		if mErr != nil {
+			return ListObjectsInfo{}, mErr
+		}
+		result.Objects[i] = manifest.toObjectInfo(bucket, objInfo.Name)
+	}
+	return result, nil
+}
+
+// GetObject - writes length bytes of object starting at startOffset
+// to writer, reading them from the chunks of deduplicated objects.
+func (d dedupObjects) GetObject(bucket, object string, startOffset int64, length int64, writer io.Writer) error {
+	if !isDedupBucket(bucket) {
+		return d.ObjectLayer.GetObject(bucket, object, startOffset, length, writer)
+	}
+	manifest, err := readManifest(d.ObjectLayer, bucket, object)
+	if err != nil {
+		return err
+	}
+	if startOffset < 0 || length < 0 || startOffset+length > manifest.Size {
+		return traceError(InvalidRange{startOffset, length, manifest.Size})
+	}
+
+	var chunkOffset int64
+	for _, chunk := range manifest.Chunks {
+		if length == 0 {
+			break
+		}
+		if startOffset >= chunkOffset+chunk.Size {
+			chunkOffset += chunk.Size
+			continue
+		}
+		offset := startOffset - chunkOffset
+		n := chunk.Size - offset
+		if n > length {
+			n = length
+		}
+		if err = d.ObjectLayer.GetObject(minioMetaBucket, dedupChunkPath(bucket, chunk.Hash), offset, n, writer); err != nil {
+			return err
+		}
+		startOffset += n
+		length -= n
+		chunkOffset += chunk.Size
+	}
+	return nil
+}
+
+// GetObjectInfo - returns the object info, from the manifest of
+// deduplicated objects.
+func (d dedupObjects) GetObjectInfo(bucket, object string) (ObjectInfo, error) {
+	if !isDedupBucket(bucket) {
+		return d.ObjectLayer.GetObjectInfo(bucket, object)
+	}
+	manifest, err := readManifest(d.ObjectLayer, bucket, object)
+	if err != nil {
+		return ObjectInfo{}, err
+	}
+	return manifest.toObjectInfo(bucket, object), nil
+}
+
+// GetObjectParts - returns the parts of deduplicated multipart objects
+// recorded in their manifest.
+func (d dedupObjects) GetObjectParts(bucket, object string) ([]objectPartInfo, error) {
+	if !isDedupBucket(bucket) {
+		return d.ObjectLayer.GetObjectParts(bucket, object)
+	}
+	manifest, err := readManifest(d.ObjectLayer, bucket, object)
+	if err != nil {
+		return nil, err
+	}
+	return manifest.Parts, nil
+}
+
+// PutObject - stores an object, as chunks and a manifest in
+// deduplicated buckets.
+func (d dedupObjects) PutObject(bucket, object string, size int64, data io.Reader, metadata map[string]string, sha256sum string) (ObjectInfo, error) {
+	if !isDedupBucket(bucket) {
+		return d.ObjectLayer.PutObject(bucket, object, size, data, metadata, sha256sum)
+	}
+	if !IsValidObjectName(object) {
+		return ObjectInfo{}, traceError(ObjectNameInvalid{Bucket: bucket, Object: object})
+	}
+	if metadata == nil {
+		metadata = make(map[string]string)
+	}
+	bypassCache := takeCacheBypass(metadata)
+
+	// Initialize md5 writer, computing the ETag and the md5sum of
+	// Content-MD5.
+	md5Writer := newETagWriter(metadata["md5Sum"])
+	hashWriters := []io.Writer{md5Writer}
+	var sha256Writer hash.Hash
+	if sha256sum != "" {
+		sha256Writer = sha256.New()
+		hashWriters = append(hashWriters, sha256Writer)
+	}
+
+	// Limit the reader to its provided size if specified.
+	limitDataReader := data
+	if size > 0 {
+		limitDataReader = io.LimitReader(data, size)
+	}
+	chunks, written, err := storeChunks(d.ObjectLayer, bucket, io.TeeReader(limitDataReader, io.MultiWriter(hashWriters...)), bypassCache)
+	if err != nil {
+		return ObjectInfo{}, err
+	}
+	if written < size {
+		releaseChunks(d.ObjectLayer, bucket, chunks)
+		return ObjectInfo{}, traceError(IncompleteBody{})
+	}
+	if md5Hex := metadata["md5Sum"]; md5Hex != "" {
+		if gotMD5Hex := md5Writer.MD5(); gotMD5Hex != md5Hex {
+			releaseChunks(d.ObjectLayer, bucket, chunks)
+			return ObjectInfo{}, traceError(BadDigest{md5Hex, gotMD5Hex})
+		}
+	}
+	if sha256sum != "" {
+		if newSHA256sum := hex.EncodeToString(sha256Writer.Sum(nil)); newSHA256sum != sha256sum {
+			releaseChunks(d.ObjectLayer, bucket, chunks)
+			return ObjectInfo{}, traceError(SHA256Mismatch{})
+		}
+	}
+
+	manifest := dedupManifest{
+		Version:     dedupManifestVersion,
+		Size:        written,
+		MD5Sum:      md5Writer.ETag(),
+		ModTime:     time.Now().UTC(),
+		UserDefined: make(map[string]string),
+		Chunks:      chunks,
+	}
+	for k, v := range metadata {
+		if k != "md5Sum" {
+			manifest.UserDefined[k] = v
+		}
+	}
+
+	opsID := getOpsID()
+	lockPath := dedupManifestLockPath(bucket, object)
+	nsMutex.Lock(minioMetaBucket, lockPath, opsID)
+	defer nsMutex.Unlock(minioMetaBucket, lockPath, opsID)
+
+	var oldManifest *dedupManifest
+	if m, mErr := readManifest(d.ObjectLayer, bucket, object); mErr == nil {
+		oldManifest = &m
+	}
+	if err = writeManifest(d.ObjectLayer, bucket, object, manifest, oldManifest); err != nil {
+		releaseChunks(d.ObjectLayer, bucket, chunks)
+		return ObjectInfo{}, err
+	}
+	return manifest.toObjectInfo(bucket, object), nil
+}
+
+// VerifyObject - verifies the manifest and all the chunks of
+// deduplicated objects.
+func (d dedupObjects) VerifyObject(bucket, object string) error {
+	if !isDedupBucket(bucket) {
+		return d.ObjectLayer.VerifyObject(bucket, object)
+	}
+	if err := d.ObjectLayer.VerifyObject(bucket, object); err != nil {
+		return err
+	}
+	manifest, err := readManifest(d.ObjectLayer, bucket, object)
+	if err != nil {
+		return err
+	}
+	verified := make(map[string]bool)
+	for _, chunk := range manifest.Chunks {
+		if verified[chunk.Hash] {
+			continue
+		}
+		if err = d.ObjectLayer.VerifyObject(minioMetaBucket, dedupChunkPath(bucket, chunk.Hash)); err != nil {
+			if _, ok := errorCause(err).(ObjectCorrupted); ok {
+				return traceError(ObjectCorrupted{Bucket: bucket, Object: object})
+			}
+			return err
+		}
+		verified[chunk.Hash] = true
+	}
+	return nil
+}
+
+// DeleteObject - deletes an object, releasing the chunks of
+// deduplicated objects.
+func (d dedupObjects) DeleteObject(bucket, object string) error {
+	if !isDedupBucket(bucket) {
+		return d.ObjectLayer.DeleteObject(bucket, object)
+	}
+
+	opsID := getOpsID()
+	lockPath := dedupManifestLockPath(bucket, object)
+	nsMutex.Lock(minioMetaBucket, lockPath, opsID)
+	defer nsMutex.Unlock(minioMetaBucket, lockPath, opsID)
+
+	manifest, mErr := readManifest(d.ObjectLayer, bucket, object)
+	if err := d.ObjectLayer.DeleteObject(bucket, object); err != nil {
+		return err
+	}
+	if mErr == nil {
+		releaseChunks(d.ObjectLayer, bucket, manifest.Chunks)
+	}
+	return nil
+}
+
+// UpdateObjectMetadata - updates the metadata of an object, kept in
+// the manifest of deduplicated objects.
+func (d dedupObjects) UpdateObjectMetadata(bucket, object string, update func(metadata map[string]string) (bool, error)) error {
+	if !isDedupBucket(bucket) {
+		return d.ObjectLayer.UpdateObjectMetadata(bucket, object, update)
+	}
+
+	opsID := getOpsID()
+	lockPath := dedupManifestLockPath(bucket, object)
+	nsMutex.Lock(minioMetaBucket, lockPath, opsID)
+	defer nsMutex.Unlock(minioMetaBucket, lockPath, opsID)
+
+	manifest, err := readManifest(d.ObjectLayer, bucket, object)
+	if err != nil {
+		return err
+	}
+	if manifest.UserDefined == nil {
+		manifest.UserDefined = make(map[string]string)
+	}
+	changed, err := update(manifest.UserDefined)
+	if err != nil || !changed {
+		return err
+	}
+	// The chunks stay referenced by the rewritten manifest.
+	return writeManifest(d.ObjectLayer, bucket, object, manifest, nil)
+}
+
+// CompleteMultipartUpload - completes a multipart upload, the object
+// of deduplicated buckets is then split into chunks.
+func (d dedupObjects) CompleteMultipartUpload(bucket, object, uploadID string, uploadedParts []completePart) (string, error) {
+	if !isDedupBucket(bucket) {
+		return d.ObjectLayer.CompleteMultipartUpload(bucket, object, uploadID, uploadedParts)
+	}
+
+	opsID := getOpsID()
+	lockPath := dedupManifestLockPath(bucket, object)
+	nsMutex.Lock(minioMetaBucket, lockPath, opsID)
+	defer nsMutex.Unlock(minioMetaBucket, lockPath, opsID)
+
+	// Chunks of the previous object are released once replaced. Its
+	// manifest is read before the uploaded data replaces it, which is
+	// never read as a manifest.
+	var oldManifest *dedupManifest
+	if m, mErr := readManifest(d.ObjectLayer, bucket, object); mErr == nil {
+		oldManifest = &m
+	}
+
+	md5Sum, err := d.ObjectLayer.CompleteMultipartUpload(bucket, object, uploadID, uploadedParts)
+	if err != nil {
+		return "", err
+	}
+	// The uploaded data must not stay in place of a manifest, the
+	// previous object is gone already.
+	removeUploaded := func() {
+		errorIf(d.ObjectLayer.DeleteObject(bucket, object), "Unable to delete uploaded data of %s/%s.", bucket, object)
+		if oldManifest != nil {
+			releaseChunks(d.ObjectLayer, bucket, oldManifest.Chunks)
+		}
+	}
+	objInfo, err := d.ObjectLayer.GetObjectInfo(bucket, object)
+	if err != nil {
+		removeUploaded()
+		return "", err
+	}
+	parts, err := d.ObjectLayer.GetObjectParts(bucket, object)
+	if err != nil {
+		removeUploaded()
+		return "", err
+	}
+
+	pipeReader, pipeWriter := io.Pipe()
+	go func() {
+		pipeWriter.CloseWithError(d.ObjectLayer.GetObject(bucket, object, 0, objInfo.Size, pipeWriter))
+	}()
+	chunks, written, err := storeChunks(d.ObjectLayer, bucket, pipeReader, false)
+	pipeReader.Close()
+	if err != nil {
+		removeUploaded()
+		return "", err
+	}
+
+	manifest := dedupManifest{
+		Version:     dedupManifestVersion,
+		Size:        written,
+		MD5Sum:      md5Sum,
+		ModTime:     objInfo.ModTime,
+		UserDefined: make(map[string]string),
+		Chunks:      chunks,
+		Parts:       parts,
+	}
+	for k, v := range objInfo.UserDefined {
+		manifest.UserDefined[k] = v
+	}
+	if err = writeManifest(d.ObjectLayer, bucket, object, manifest, oldManifest); err != nil {
+		releaseChunks(d.ObjectLayer, bucket, chunks)
+		removeUploaded()
+		return "", err
+	}
+	return md5Sum, nil
+}
+
+// deleteUnreferencedChunks - deletes chunks of all buckets, including
+// deleted ones, left without references for longer than expiry.
+// Returns the number of chunks deleted.
+func deleteUnreferencedChunks(objAPI ObjectLayer, expiry time.Duration) (deleted int64, err error) {
+	var buckets []string
+	marker := ""
+	for {
+		result, err := objAPI.ListObjects(minioMetaBucket, dedupPrefix+slashSeparator, marker, slashSeparator, maxObjectList)
+		if err != nil {
+			return deleted, err
+		}
+		for _, prefix := range result.Prefixes {
+			buckets = append(buckets, strings.TrimSuffix(strings.TrimPrefix(prefix, dedupPrefix+slashSeparator), slashSeparator))
+		}
+		if !result.IsTruncated {
+			break
+		}
+		marker = result.NextMarker
+	}
+
+	for _, bucket := range buckets {
+		refsPrefix := path.Join(dedupPrefix, bucket, dedupRefsPrefix) + slashSeparator
+		marker = ""
+		for {
+			result, err := objAPI.ListObjects(minioMetaBucket, refsPrefix, marker, "", maxObjectList)
+			if err != nil {
+				return deleted, err
+			}
+			for _, objInfo := range result.Objects {
+				if time.Since(objInfo.ModTime) < expiry {
+					continue
+				}
+				ok, dErr := deleteChunkIfUnreferenced(objAPI, bucket, path.Base(objInfo.Name))
+				if dErr != nil {
+					return deleted, dErr
+				}
+				if ok {
+					deleted++
+				}
+			}
+			if !result.IsTruncated {
+				break
+			}
+			marker = result.NextMarker
+		}
+	}
+	return deleted, nil
+}
+
+// deleteChunkIfUnreferenced - deletes a chunk and its references if
+// there are no references left, returns true if deleted.
+func deleteChunkIfUnreferenced(objAPI ObjectLayer, bucket, hash string) (bool, error) {
+	opsID := getOpsID()
+	lockPath := dedupChunkLockPath(bucket, hash)
+	nsMutex.Lock(minioMetaBucket, lockPath, opsID)
+	defer nsMutex.Unlock(minioMetaBucket, lockPath, opsID)
+
+	refs, err := readChunkRefs(objAPI, bucket, hash)
+	if err != nil || refs > 0 {
+		return false, err
+	}
+	if err = objAPI.DeleteObject(minioMetaBucket, dedupChunkPath(bucket, hash)); err != nil {
+		if _, ok := errorCause(err).(ObjectNotFound); !ok {
+			return false, err
+		}
+	}
+	if err = objAPI.DeleteObject(minioMetaBucket, dedupRefsPath(bucket, hash)); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// startDedupGC - periodically deletes unreferenced chunks.
+func startDedupGC(objAPI ObjectLayer, interval, expiry time.Duration) {
+	globalTaskManager.Start(dedupGCTask, interval, func() error {
+		_, err := deleteUnreferencedChunks(objAPI, expiry)
+		errorIf(err, "Unable to delete unreferenced deduplicated chunks.")
+		return err
+	})
+}
@@ -0,0 +1,240 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"bytes"
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/json"
+	"math/rand"
+	"path"
+	"testing"
+)
+
+// Wrapper for calling deduplication tests for both XL and FS.
+func TestDedupObjects(t *testing.T) {
+	ExecObjectLayerTest(t, testDedupObjects)
+}
+
+// Tests objects of deduplicated buckets share chunks of identical data.
+func testDedupObjects(obj ObjectLayer, instanceType string, t TestErrHandler) {
+	bucket := "dedup-bucket"
+	if err := obj.MakeBucket(bucket); err != nil {
+		t.Fatalf("%s: Unable to make bucket: %v", instanceType, err)
+	}
+	globalBucketSettings.SetBucketSettings(bucket, &bucketSettings{Dedup: true})
+	defer globalBucketSettings.SetBucketSettings(bucket, nil)
+	dedupObj := newDedupObjects(obj)
+
+	// Two and a half chunks of data.
+	data := make([]byte, 2*dedupChunkSize+dedupChunkSize/2)
+	rand.New(rand.NewSource(1)).Read(data)
+	md5Sum := md5.Sum(data)
+	md5Hex := hex.EncodeToString(md5Sum[:])
+
+	for _, object := range []string{"object1", "dir/object2"} {
+		objInfo, err := dedupObj.PutObject(bucket, object, int64(len(data)), bytes.NewReader(data), map[string]string{"md5Sum": md5Hex}, "")
+		if err != nil {
+			t.Fatalf("%s: Unable to put %s: %v", instanceType, object, err)
+		}
+		if objInfo.MD5Sum != md5Hex || objInfo.Size != int64(len(data)) {
+			t.Errorf("%s: Unexpected object info %+v", instanceType, objInfo)
+		}
+	}
+
+	// Chunks are stored once, referenced by both objects.
+	manifest, err := readManifest(obj, bucket, "object1")
+	if err != nil {
+		t.Fatalf("%s: Unable to read manifest: %v", instanceType, err)
+	}
+	if len(manifest.Chunks) != 3 {
+		t.Fatalf("%s: Expected 3 chunks, got %d", instanceType, len(manifest.Chunks))
+	}
+	for _, chunk := range manifest.Chunks {
+		refs, rErr := readChunkRefs(obj, bucket, chunk.Hash)
+		if rErr != nil || refs != 2 {
+			t.Errorf("%s: Expected 2 references to chunk %s, got %d (%v)", instanceType, chunk.Hash, refs, rErr)
+		}
+	}
+
+	objInfo, err := dedupObj.GetObjectInfo(bucket, "dir/object2")
+	if err != nil || objInfo.MD5Sum != md5Hex || objInfo.Size != int64(len(data)) {
+		t.Errorf("%s: Unexpected object info %+v (%v)", instanceType, objInfo, err)
+	}
+	result, err := dedupObj.ListObjects(bucket, "", "", "", 10)
+	if err != nil || len(result.Objects) != 2 || result.Objects[0].MD5Sum != md5Hex || result.Objects[0].Size != int64(len(data)) {
+		t.Errorf("%s: Unexpected list result %+v (%v)", instanceType, result, err)
+	}
+
+	// Ranges within and across chunks.
+	ranges := []struct{ offset, length int64 }{
+		{0, int64(len(data))},
+		{10, 100},
+		{dedupChunkSize - 10, dedupChunkSize + 20},
+		{int64(len(data)) - 1, 1},
+		{0, 0},
+	}
+	for i, r := range ranges {
+		var buffer bytes.Buffer
+		if err = dedupObj.GetObject(bucket, "object1", r.offset, r.length, &buffer); err != nil {
+			t.Fatalf("%s: Range %d: Unable to get object: %v", instanceType, i+1, err)
+		}
+		if !bytes.Equal(buffer.Bytes(), data[r.offset:r.offset+r.length]) {
+			t.Errorf("%s: Range %d: Unexpected data", instanceType, i+1)
+		}
+	}
+	if err = dedupObj.GetObject(bucket, "object1", 1, int64(len(data)), &bytes.Buffer{}); err == nil {
+		t.Errorf("%s: Expected an invalid range error", instanceType)
+	}
+
+	// Overwriting and deleting release the chunks.
+	if _, err = dedupObj.PutObject(bucket, "object1", 5, bytes.NewReader([]byte("hello")), nil, ""); err != nil {
+		t.Fatalf("%s: Unable to overwrite object: %v", instanceType, err)
+	}
+	if err = dedupObj.DeleteObject(bucket, "dir/object2"); err != nil {
+		t.Fatalf("%s: Unable to delete object: %v", instanceType, err)
+	}
+	for _, chunk := range manifest.Chunks {
+		refs, rErr := readChunkRefs(obj, bucket, chunk.Hash)
+		if rErr != nil || refs != 0 {
+			t.Errorf("%s: Expected no references to chunk %s, got %d (%v)", instanceType, chunk.Hash, refs, rErr)
+		}
+	}
+
+	// Unreferenced chunks are only deleted once expired.
+	deleted, err := deleteUnreferencedChunks(obj, dedupGCExpiry)
+	if err != nil || deleted != 0 {
+		t.Errorf("%s: Expected no chunks deleted, got %d (%v)", instanceType, deleted, err)
+	}
+	deleted, err = deleteUnreferencedChunks(obj, 0)
+	if err != nil || deleted != 3 {
+		t.Errorf("%s: Expected 3 chunks deleted, got %d (%v)", instanceType, deleted, err)
+	}
+	for _, chunk := range manifest.Chunks {
+		if _, err = obj.GetObjectInfo(minioMetaBucket, dedupChunkPath(bucket, chunk.Hash)); err == nil {
+			t.Errorf("%s: Expected chunk %s to be deleted", instanceType, chunk.Hash)
+		}
+	}
+
+	var buffer bytes.Buffer
+	if err = dedupObj.GetObject(bucket, "object1", 0, 5, &buffer); err != nil || buffer.String() != "hello" {
+		t.Errorf("%s: Expected overwritten object, got %q (%v)", instanceType, buffer.String(), err)
+	}
+}
+
+// Wrapper for calling deduplication multipart tests for both XL and FS.
+func TestDedupMultipartForgedManifest(t *testing.T) {
+	ExecObjectLayerTest(t, testDedupMultipartForgedManifest)
+}
+
+// Tests uploaded data looking like a manifest never releases the
+// chunks it names.
+func testDedupMultipartForgedManifest(obj ObjectLayer, instanceType string, t TestErrHandler) {
+	bucket := "dedup-bucket"
+	if err := obj.MakeBucket(bucket); err != nil {
+		t.Fatalf("%s: Unable to make bucket: %v", instanceType, err)
+	}
+	globalBucketSettings.SetBucketSettings(bucket, &bucketSettings{Dedup: true})
+	defer globalBucketSettings.SetBucketSettings(bucket, nil)
+	dedupObj := newDedupObjects(obj)
+
+	data := make([]byte, dedupChunkSize+dedupChunkSize/2)
+	rand.New(rand.NewSource(1)).Read(data)
+	if _, err := dedupObj.PutObject(bucket, "victim", int64(len(data)), bytes.NewReader(data), nil, ""); err != nil {
+		t.Fatalf("%s: Unable to put object: %v", instanceType, err)
+	}
+	manifest, err := readManifest(obj, bucket, "victim")
+	if err != nil {
+		t.Fatalf("%s: Unable to read manifest: %v", instanceType, err)
+	}
+	forged, err := json.Marshal(manifest)
+	if err != nil {
+		t.Fatalf("%s: Unable to marshal manifest: %v", instanceType, err)
+	}
+
+	// Upload the manifest of the victim as the content of another object.
+	uploadID, err := dedupObj.NewMultipartUpload(bucket, "forged", nil)
+	if err != nil {
+		t.Fatalf("%s: Unable to start upload: %v", instanceType, err)
+	}
+	md5Sum := md5.Sum(forged)
+	md5Hex := hex.EncodeToString(md5Sum[:])
+	if _, err = dedupObj.PutObjectPart(bucket, "forged", uploadID, 1, int64(len(forged)), bytes.NewReader(forged), md5Hex, ""); err != nil {
+		t.Fatalf("%s: Unable to put part: %v", instanceType, err)
+	}
+	if _, err = dedupObj.CompleteMultipartUpload(bucket, "forged", uploadID, []completePart{{PartNumber: 1, ETag: md5Hex}}); err != nil {
+		t.Fatalf("%s: Unable to complete upload: %v", instanceType, err)
+	}
+
+	for _, chunk := range manifest.Chunks {
+		refs, rErr := readChunkRefs(obj, bucket, chunk.Hash)
+		if rErr != nil || refs != 1 {
+			t.Errorf("%s: Expected 1 reference to chunk %s, got %d (%v)", instanceType, chunk.Hash, refs, rErr)
+		}
+	}
+	if _, err = deleteUnreferencedChunks(obj, 0); err != nil {
+		t.Fatalf("%s: Unable to delete unreferenced chunks: %v", instanceType, err)
+	}
+	var buffer bytes.Buffer
+	if err = dedupObj.GetObject(bucket, "victim", 0, int64(len(data)), &buffer); err != nil || !bytes.Equal(buffer.Bytes(), data) {
+		t.Errorf("%s: Unable to read back victim object (%v)", instanceType, err)
+	}
+	buffer.Reset()
+	if err = dedupObj.GetObject(bucket, "forged", 0, int64(len(forged)), &buffer); err != nil || !bytes.Equal(buffer.Bytes(), forged) {
+		t.Errorf("%s: Expected uploaded data, got %q (%v)", instanceType, buffer.String(), err)
+	}
+}
+
+// Wrapper for calling deduplication settings tests for both XL and FS.
+func TestCheckDedupSettingsChange(t *testing.T) {
+	ExecObjectLayerTest(t, testCheckDedupSettingsChange)
+}
+
+// Tests deduplication can only be enabled on empty buckets and never disabled.
+func testCheckDedupSettingsChange(obj ObjectLayer, instanceType string, t TestErrHandler) {
+	for _, bucket := range []string{"empty-bucket", "bucket"} {
+		if err := obj.MakeBucket(bucket); err != nil {
+			t.Fatalf("%s: Unable to make bucket: %v", instanceType, err)
+		}
+	}
+	if _, err := obj.PutObject("bucket", path.Join("dir", "object"), 5, bytes.NewReader([]byte("hello")), nil, ""); err != nil {
+		t.Fatalf("%s: Unable to put object: %v", instanceType, err)
+	}
+	globalBucketSettings.SetBucketSettings("dedup-bucket", &bucketSettings{Dedup: true})
+	defer globalBucketSettings.SetBucketSettings("dedup-bucket", nil)
+
+	testCases := []struct {
+		bucket   string
+		settings *bucketSettings
+		err      error
+	}{
+		{"empty-bucket", &bucketSettings{Dedup: true}, nil},
+		{"empty-bucket", &bucketSettings{CacheControl: "no-cache"}, nil},
+		{"empty-bucket", nil, nil},
+		{"bucket", &bucketSettings{Dedup: true}, errDedupSettingsChange},
+		{"bucket", &bucketSettings{CacheControl: "no-cache"}, nil},
+		{"dedup-bucket", &bucketSettings{Dedup: true, CacheControl: "no-cache"}, nil},
+		{"dedup-bucket", &bucketSettings{CacheControl: "no-cache"}, errDedupSettingsChange},
+		{"dedup-bucket", nil, errDedupSettingsChange},
+	}
+	for i, testCase := range testCases {
+		if err := checkDedupSettingsChange(testCase.bucket, testCase.settings, obj); err != testCase.err {
+			t.Errorf("%s: Test %d: Expected error %v, got %v", instanceType, i+1, testCase.err, err)
+		}
+	}
+}
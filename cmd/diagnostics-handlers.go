@@ -0,0 +1,216 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"encoding/json"
+	"os"
+	"runtime"
+
+	"github.com/mf-00/newgo/pkg/disk"
+)
+
+// DiskDiagnostics - represents the state of a single disk.
+type DiskDiagnostics struct {
+	Endpoint string
+	Online   bool
+	Info     disk.Info
+	Err      string
+}
+
+// DiagnosticsReply - reply by Diagnostics RPC, represents the
+// sanitized diagnostics information of a single node.
+type DiagnosticsReply struct {
+	Node          string
+	ServerVersion string
+	ReleaseTag    string
+	CommitID      string
+	GoVersion     string
+	OS            string
+	Arch          string
+	NumCPU        int
+	Hostname      string
+	StorageInfo   StorageInfo
+	Disks         []DiskDiagnostics
+
+	// Container runtime the node runs in, empty if none.
+	ContainerRuntime string
+
+	// Config with all the secrets redacted.
+	Config []byte
+
+	// Most recent errors logged on this node.
+	RecentErrors []string
+
+	// Download URL of a newer release found by the background update
+	// checks, empty if none was found.
+	AvailableUpdate string
+
+	// Set instead of the diagnostics when the node could not be
+	// reached.
+	Error string
+}
+
+// getRedactedConfig - returns current server config as json with all
+// the secrets redacted.
+func getRedactedConfig(config *serverConfigV10) ([]byte, error) {
+	config.rwMutex.RLock()
+	configBytes, err := json.Marshal(config)
+	config.rwMutex.RUnlock()
+	if err != nil {
+		return nil, err
+	}
+	var configMap map[string]interface{}
+	if err = json.Unmarshal(configBytes, &configMap); err != nil {
+		return nil, err
+	}
+	return json.MarshalIndent(redactConfigValue(configMap), "", "\t")
+}
+
+// getDiskDiagnostics - returns the state of all the disks.
+func getDiskDiagnostics(storageDisks []StorageAPI) []DiskDiagnostics {
+	disksDiag := make([]DiskDiagnostics, len(storageDisks))
+	for index, storageDisk := range storageDisks {
+		if storageDisk == nil {
+			disksDiag[index] = DiskDiagnostics{Err: errDiskNotFound.Error()}
+			continue
+		}
+		disksDiag[index].Endpoint = storageDisk.String()
+		info, err := storageDisk.DiskInfo()
+		if err != nil {
+			disksDiag[index].Err = err.Error()
+			continue
+		}
+		disksDiag[index].Online = true
+		disksDiag[index].Info = info
+	}
+	return disksDiag
+}
+
+// getLocalDiagnostics - collects the diagnostics information of the local node.
+func (c *controlAPIHandlers) getLocalDiagnostics() (DiagnosticsReply, error) {
+	configBytes, err := getRedactedConfig(serverConfig)
+	if err != nil {
+		return DiagnosticsReply{}, err
+	}
+	hostname, _ := os.Hostname()
+	diag := DiagnosticsReply{
+		Node:          c.LocalNode,
+		ServerVersion: Version,
+		ReleaseTag:    ReleaseTag,
+		CommitID:      CommitID,
+		GoVersion:     runtime.Version(),
+		OS:            runtime.GOOS,
+		Arch:          runtime.GOARCH,
+		NumCPU:        runtime.NumCPU(),
+		Hostname:      hostname,
+		Disks:         getDiskDiagnostics(c.StorageDisks),
+		Config:        configBytes,
+		RecentErrors:  globalRecentErrors.list(),
+	}
+	diag.AvailableUpdate = globalUpdateNotifier.Available()
+	diag.ContainerRuntime = getContainerRuntime()
+	if objAPI := c.ObjectAPI(); objAPI != nil {
+		diag.StorageInfo = objAPI.StorageInfo()
+	}
+	return diag, nil
+}
+
+// RemoteDiagnostics - RPC control handler for `minio control diagnostics`, used
+// internally by Diagnostics to make calls to neighboring peers.
+func (c *controlAPIHandlers) RemoteDiagnostics(args *GenericArgs, reply *DiagnosticsReply) (err error) {
+	defer encodeRPCError(&err)
+
+	if !isRPCTokenValid(args.Token, jwtAudienceAdmin) {
+		return errInvalidToken
+	}
+	diag, err := c.getLocalDiagnostics()
+	if err != nil {
+		return err
+	}
+	*reply = diag
+	return nil
+}
+
+// Diagnostics - RPC control handler for `minio control diagnostics`. Returns
+// the sanitized diagnostics information of all the nodes in the cluster,
+// nodes which could not be reached have their error set instead.
+func (c *controlAPIHandlers) Diagnostics(args *GenericArgs, reply *map[string]DiagnosticsReply) (err error) {
+	defer encodeRPCError(&err)
+
+	if !isRPCTokenValid(args.Token, jwtAudienceAdmin) {
+		return errInvalidToken
+	}
+	remoteControls := c.getRemoteControls()
+	var replies = make([]DiagnosticsReply, len(remoteControls))
+	errsMap := make(map[string]error)
+	if args.Remote {
+		// Fetch diagnostics from all the remote peers, the nodes
+		// which fail are reported along with the others.
+		args.Remote = false
+		errsMap = callRemoteControls(remoteControls, "Control.RemoteDiagnostics", args, func(index int) interface{} {
+			return &replies[index]
+		})
+	}
+	rep := make(map[string]DiagnosticsReply)
+	for index, client := range remoteControls {
+		if err, ok := errsMap[client.Node()]; ok {
+			rep[client.Node()] = DiagnosticsReply{Node: client.Node(), Error: err.Error()}
+			continue
+		}
+		rep[client.Node()] = replies[index]
+	}
+	diag, err := c.getLocalDiagnostics()
+	if err != nil {
+		return err
+	}
+	rep[c.LocalNode] = diag
+
+	// Set the reply.
+	*reply = rep
+
+	// Success.
+	return nil
+}
+
+// DiagnosticsStream - RPC control handler starting to collect the
+// diagnostics of all the nodes, to be read per node with
+// NextReplyChunks as the nodes reply.
+func (c *controlAPIHandlers) DiagnosticsStream(args *GenericArgs, reply *ReplyStreamStartReply) (err error) {
+	defer encodeRPCError(&err)
+
+	if !isRPCTokenValid(args.Token, jwtAudienceAdmin) {
+		return errInvalidToken
+	}
+	calls := map[string]func() (interface{}, error){
+		c.LocalNode: func() (interface{}, error) {
+			return c.getLocalDiagnostics()
+		},
+	}
+	if args.Remote {
+		for _, clnt := range c.getRemoteControls() {
+			client := clnt
+			calls[client.Node()] = func() (interface{}, error) {
+				var diag DiagnosticsReply
+				err := client.Call("Control.RemoteDiagnostics", &GenericArgs{}, &diag)
+				return diag, err
+			}
+		}
+	}
+	reply.StreamID, reply.Nodes = globalReplyStreams.New(calls)
+	return nil
+}
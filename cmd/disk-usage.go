@@ -0,0 +1,129 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"sync"
+	"time"
+
+	"github.com/mf-00/newgo/pkg/disk"
+)
+
+// Interval at which free space and inodes are refreshed.
+const diskUsageRefreshInterval = 5 * time.Second
+
+// diskUsageMonitor - tracks free space and inodes of all the disks,
+// so that writes which would fill them up are rejected before any
+// data is written, instead of failing half way through.
+type diskUsageMonitor struct {
+	mu         sync.RWMutex
+	disks      []StorageAPI
+	dataBlocks int64 // Disks an object is split across.
+	disksInfo  []disk.Info
+}
+
+// Disk usage of the disks served by this node, admits all writes
+// until disks are set.
+var globalDiskUsage = &diskUsageMonitor{}
+
+// setDisks - starts tracking disks, a single disk is FS and
+// multiple disks are XL with half of them holding data blocks.
+func (m *diskUsageMonitor) setDisks(disks []StorageAPI) {
+	m.mu.Lock()
+	m.disks = disks
+	m.dataBlocks = 1
+	if len(disks) > 1 {
+		m.dataBlocks = int64(len(disks) / 2)
+	}
+	m.mu.Unlock()
+	m.refresh()
+}
+
+// refresh - fetches the latest disks info.
+func (m *diskUsageMonitor) refresh() {
+	m.mu.RLock()
+	disks := m.disks
+	m.mu.RUnlock()
+
+	disksInfo, _, _ := getDisksInfo(disks)
+
+	m.mu.Lock()
+	m.disksInfo = disksInfo
+	m.mu.Unlock()
+}
+
+// monitor - refreshes disks info every interval, never returns.
+func (m *diskUsageMonitor) monitor(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for range ticker.C {
+		m.refresh()
+	}
+}
+
+// admit - returns errDiskFull if writing size bytes would take any of
+// the disks past the free space and inodes thresholds. Unknown sizes
+// (-1) are admitted as long as the disks are not already full.
+func (m *diskUsageMonitor) admit(size int64) error {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	// No disks tracked yet.
+	if len(m.disksInfo) == 0 {
+		return nil
+	}
+
+	// Size of the data written to each disk.
+	var diskSize int64
+	if size > 0 {
+		diskSize = (size + m.dataBlocks - 1) / m.dataBlocks
+	}
+	for _, di := range m.disksInfo {
+		// Disk is offline or its info is not available.
+		if di.Total == 0 {
+			continue
+		}
+		if isDiskUsageFull(di, diskSize) {
+			return errDiskFull
+		}
+	}
+	return nil
+}
+
+// isDiskUsageFull - returns true if writing size bytes to the disk would
+// leave less than the minimum free space and inodes enforced by posix,
+// or take its usage past MINIO_DISK_HIGH_WATERMARK.
+func isDiskUsageFull(di disk.Info, size int64) bool {
+	free := di.Free - size
+
+	// Remove 5% from free space for journalling, inodes etc, same as posix.
+	if int64(float64(free)*0.95) <= fsMinFreeSpace {
+		return true
+	}
+	// Inodes are validated only if the filesystem reports them.
+	if di.Files != 0 && int64(100*float64(di.Ffree)/float64(di.Files)) <= fsMinFreeInodesPercent {
+		return true
+	}
+
+	if globalDiskHighWatermark == 0 {
+		return false
+	}
+	if 100*float64(di.Total-free)/float64(di.Total) > float64(globalDiskHighWatermark) {
+		return true
+	}
+	return di.Files != 0 && 100*float64(di.Files-di.Ffree)/float64(di.Files) > float64(globalDiskHighWatermark)
+}
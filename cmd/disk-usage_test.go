@@ -0,0 +1,94 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"testing"
+
+	"github.com/mf-00/newgo/pkg/disk"
+)
+
+// Tests admission of writes against tracked disk usage.
+func TestDiskUsageAdmit(t *testing.T) {
+	const gib = 1024 * 1024 * 1024
+	defer func() { globalDiskHighWatermark = 0 }()
+
+	disksInfo := []disk.Info{
+		{Total: 100 * gib, Free: 50 * gib, Files: 1000, Ffree: 500},
+		{Total: 100 * gib, Free: 20 * gib, Files: 1000, Ffree: 500},
+		// Offline disk.
+		{},
+		{Total: 100 * gib, Free: 40 * gib},
+	}
+	m := &diskUsageMonitor{dataBlocks: 2, disksInfo: disksInfo}
+
+	testCases := []struct {
+		highWatermark int
+		size          int64
+		err           error
+	}{
+		// Only minimum free space is enforced.
+		{0, -1, nil},
+		{0, 30 * gib, nil},
+		// 19GiB lands on each disk, leaving less than 1GiB free.
+		{0, 38 * gib, errDiskFull},
+		// Above high-water mark once 2GiB lands on each disk.
+		{80, 0, nil},
+		{80, 2 * gib, errDiskFull},
+		// Usage is above high-water mark already.
+		{40, 0, errDiskFull},
+	}
+	for i, testCase := range testCases {
+		globalDiskHighWatermark = testCase.highWatermark
+		if err := m.admit(testCase.size); err != testCase.err {
+			t.Errorf("Test %d: expected %v, got %v", i+1, testCase.err, err)
+		}
+	}
+
+	// Running out of inodes is rejected regardless of high-water mark.
+	globalDiskHighWatermark = 0
+	m.disksInfo = []disk.Info{{Total: 100 * gib, Free: 50 * gib, Files: 1000, Ffree: 50}}
+	if err := m.admit(0); err != errDiskFull {
+		t.Errorf("Expected %v, got %v", errDiskFull, err)
+	}
+}
+
+// Tests that disk usage is refreshed from the disks.
+func TestDiskUsageSetDisks(t *testing.T) {
+	posixDisk, diskPath, err := newPosixTestSetup()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer removeAll(diskPath)
+
+	m := &diskUsageMonitor{}
+	// No disks, all writes are admitted.
+	if err = m.admit(1 << 62); err != nil {
+		t.Fatalf("Expected writes to be admitted without disks, got %v", err)
+	}
+
+	m.setDisks([]StorageAPI{posixDisk})
+	if m.dataBlocks != 1 {
+		t.Errorf("Expected 1 data block, got %d", m.dataBlocks)
+	}
+	if len(m.disksInfo) != 1 || m.disksInfo[0].Total == 0 {
+		t.Fatalf("Expected disk info to be refreshed, got %v", m.disksInfo)
+	}
+	if err = m.admit(m.disksInfo[0].Free); err != errDiskFull {
+		t.Errorf("Expected %v, got %v", errDiskFull, err)
+	}
+}
@@ -0,0 +1,162 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Durability modes of metadata writes, set by MINIO_DURABILITY.
+const (
+	// Metadata is committed to the disks whenever the OS flushes it.
+	durabilityOff = "off"
+
+	// Every xl.json write and rename out of the tmp directory waits
+	// for its data to be committed to the disks, concurrent writes
+	// share one commit.
+	durabilitySync = "sync"
+
+	// Like sync, commits are delayed by durabilityBatchDelay to be
+	// shared by more writes.
+	durabilityBatch = "batch"
+)
+
+// Maximum delay of a commit in batch mode.
+const durabilityBatchDelay = 5 * time.Millisecond
+
+// errDurabilityUnsupported - durable modes need flushDisks.
+var errDurabilityUnsupported = errors.New("Durable metadata writes are not supported on windows")
+
+// parseDurabilityMode - parses the durability mode of metadata writes.
+func parseDurabilityMode(mode string) (string, error) {
+	mode = strings.ToLower(mode)
+	switch mode {
+	case durabilityOff:
+		return mode, nil
+	case durabilitySync, durabilityBatch:
+		if runtime.GOOS == "windows" {
+			return "", errDurabilityUnsupported
+		}
+		return mode, nil
+	}
+	return "", errInvalidArgument
+}
+
+// durabilityStats - commits of metadata writes since server start,
+// reported by ServerInfo.
+type durabilityStats struct {
+	mu      sync.Mutex
+	commits int64
+	writes  int64
+}
+
+// Durability statistics of this node.
+var globalDurabilityStats = &durabilityStats{}
+
+// update - adds a commit shared by writes.
+func (s *durabilityStats) update(writes int64) {
+	s.mu.Lock()
+	s.commits++
+	s.writes += writes
+	s.mu.Unlock()
+}
+
+func (s *durabilityStats) String() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	perCommit := 0.0
+	if s.commits > 0 {
+		perCommit = float64(s.writes) / float64(s.commits)
+	}
+	return fmt.Sprintf("Mode: %s | Commits: %d | Writes: %d (%.1f per commit)",
+		globalDurabilityMode, s.commits, s.writes, perCommit)
+}
+
+// Commits metadata writes of all the local disks, nil unless
+// MINIO_DURABILITY is sync or batch.
+var globalFsyncBatcher *fsyncBatcher
+
+// fsyncBatch - writes waiting for the same commit.
+type fsyncBatch struct {
+	writes int64
+	doneCh chan struct{}
+	err    error
+}
+
+// fsyncBatcher - commits writes to the disks, writes waiting at the
+// same time are committed together.
+type fsyncBatcher struct {
+	delay time.Duration
+	flush func() error
+
+	mutex   sync.Mutex
+	pending *fsyncBatch
+}
+
+// newFsyncBatcher - returns the batcher for a durability mode, nil
+// if writes aren't committed.
+func newFsyncBatcher(mode string) *fsyncBatcher {
+	switch mode {
+	case durabilitySync:
+		return &fsyncBatcher{flush: flushDisks}
+	case durabilityBatch:
+		return &fsyncBatcher{delay: durabilityBatchDelay, flush: flushDisks}
+	}
+	return nil
+}
+
+// sync - waits until everything written before is committed to the
+// disks.
+func (b *fsyncBatcher) sync() error {
+	b.mutex.Lock()
+	batch := b.pending
+	if batch == nil {
+		batch = &fsyncBatch{doneCh: make(chan struct{})}
+		b.pending = batch
+		go b.commit(batch)
+	}
+	batch.writes++
+	b.mutex.Unlock()
+
+	<-batch.doneCh
+	return batch.err
+}
+
+// commit - commits the writes of batch once the delay elapsed. Writes
+// waiting after the commit started wait for the next one.
+func (b *fsyncBatcher) commit(batch *fsyncBatch) {
+	if b.delay > 0 {
+		time.Sleep(b.delay)
+	}
+	b.mutex.Lock()
+	b.pending = nil
+	b.mutex.Unlock()
+
+	batch.err = b.flush()
+	globalDurabilityStats.update(batch.writes)
+	close(batch.doneCh)
+}
+
+// isTmpPath - returns true for paths in the tmp directory of the meta
+// bucket, renames within it are not committed.
+func isTmpPath(volume, path string) bool {
+	return volume == minioMetaBucket && (path == tmpMetaPrefix || strings.HasPrefix(path, tmpMetaPrefix+slashSeparator))
+}
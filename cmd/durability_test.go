@@ -0,0 +1,148 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package cmd
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// Tests parsing durability modes.
+func TestParseDurabilityMode(t *testing.T) {
+	testCases := []struct {
+		mode     string
+		expected string
+		err      error
+	}{
+		{"off", durabilityOff, nil},
+		{"Sync", durabilitySync, nil},
+		{"BATCH", durabilityBatch, nil},
+		{"always", "", errInvalidArgument},
+		{"", "", errInvalidArgument},
+	}
+	for i, testCase := range testCases {
+		mode, err := parseDurabilityMode(testCase.mode)
+		if err != testCase.err {
+			t.Errorf("Test %d: Expected error %v, got %v", i+1, testCase.err, err)
+		}
+		if mode != testCase.expected {
+			t.Errorf("Test %d: Expected mode %s, got %s", i+1, testCase.expected, mode)
+		}
+	}
+}
+
+// Tests concurrent writes share one commit.
+func TestFsyncBatcher(t *testing.T) {
+	var flushes int32
+	releaseCh := make(chan struct{})
+	batcher := &fsyncBatcher{
+		delay: 50 * time.Millisecond,
+		flush: func() error {
+			<-releaseCh
+			atomic.AddInt32(&flushes, 1)
+			return nil
+		},
+	}
+
+	// Writes waiting for the same commit.
+	var wg sync.WaitGroup
+	doneCh := make(chan struct{})
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := batcher.sync(); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	go func() {
+		wg.Wait()
+		close(doneCh)
+	}()
+
+	// Writes wait until the commit is done.
+	select {
+	case <-doneCh:
+		t.Fatal("Expected writes to wait for the commit")
+	case <-time.After(100 * time.Millisecond):
+	}
+	close(releaseCh)
+	<-doneCh
+	if flushes != 1 {
+		t.Fatalf("Expected 1 commit, got %d", flushes)
+	}
+
+	// Later writes wait for the next commit.
+	if err := batcher.sync(); err != nil {
+		t.Fatal(err)
+	}
+	if flushes != 2 {
+		t.Fatalf("Expected 2 commits, got %d", flushes)
+	}
+
+	// Errors of the commit are returned to all its writes.
+	errFlush := errors.New("flush failed")
+	batcher.flush = func() error { return errFlush }
+	if err := batcher.sync(); err != errFlush {
+		t.Fatalf("Expected %v, got %v", errFlush, err)
+	}
+}
+
+// Tests xl.json writes and renames out of the tmp directory are
+// committed.
+func TestPosixDurability(t *testing.T) {
+	disk, diskPath, err := newPosixTestSetup()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer removeAll(diskPath)
+
+	var flushes int32
+	globalFsyncBatcher = &fsyncBatcher{flush: func() error {
+		atomic.AddInt32(&flushes, 1)
+		return nil
+	}}
+	defer func() { globalFsyncBatcher = nil }()
+
+	for _, volume := range []string{minioMetaBucket, "bucket"} {
+		if err = disk.MakeVol(volume); err != nil {
+			t.Fatal(err)
+		}
+	}
+	testCases := []struct {
+		fn       func() error
+		expected int32
+	}{
+		{func() error { return disk.AppendFile(minioMetaBucket, "tmp/uuid/part.1", []byte("data")) }, 0},
+		{func() error { return disk.AppendFile(minioMetaBucket, "tmp/uuid/xl.json", []byte("{}")) }, 1},
+		{func() error {
+			return disk.RenameFile(minioMetaBucket, "tmp/uuid/part.1", minioMetaBucket, "tmp/uuid2/part.1")
+		}, 1},
+		{func() error { return disk.RenameFile(minioMetaBucket, "tmp/uuid/", "bucket", "object/") }, 2},
+	}
+	for i, testCase := range testCases {
+		if err = testCase.fn(); err != nil {
+			t.Fatalf("Test %d: %v", i+1, err)
+		}
+		if flushes != testCase.expected {
+			t.Errorf("Test %d: Expected %d commits, got %d", i+1, testCase.expected, flushes)
+		}
+	}
+}
@@ -169,6 +169,11 @@ func erasureReadFile(writer io.Writer, disks []StorageAPI, volume string, path s
 		return 0, traceError(errUnexpected)
 	}
 
+	// Nothing to read.
+	if length == 0 {
+		return 0, nil
+	}
+
 	// chunkSize is the amount of data that needs to be read from each disk at a time.
 	chunkSize := getChunkSize(blockSize, dataBlocks)
 
@@ -203,9 +208,9 @@ func erasureReadFile(writer io.Writer, disks []StorageAPI, volume string, path s
 	curChunkSize := chunkSize
 	curBlockSize := blockSize
 
-	// For each block, read chunk from each disk. If we are able to read all the data disks then we don't
-	// need to read parity disks. If one of the data disk is missing we need to read DataBlocks+1 number
-	// of disks. Once read, we Reconstruct() missing data if needed and write it to the given writer.
+	// For each block, read the chunks holding the requested range from their data disks. If one of them
+	// can't be read, read all the data disks, or DataBlocks number of data and parity disks if data disks
+	// are missing. Once read, we Reconstruct() missing data if needed and write it to the given writer.
 	for block := startBlock; block <= endBlock; block++ {
 		// Mark all buffers as unused at the start of the loop so that the buffers
 		// can be reused.
@@ -227,41 +232,6 @@ func erasureReadFile(writer io.Writer, disks []StorageAPI, volume string, path s
 		// then it can result in wrong offset for the last block.
 		blockOffset := block * chunkSize
 
-		// nextIndex - index from which next set of parallel reads
-		// should happen.
-		nextIndex := 0
-
-		for {
-			// readDisks - disks from which we need to read in parallel.
-			var readDisks []StorageAPI
-			var err error
-			// get readable disks slice from which we can read parallelly.
-			readDisks, nextIndex, err = getReadDisks(disks, nextIndex, dataBlocks)
-			if err != nil {
-				return bytesWritten, err
-			}
-			// Issue a parallel read across the disks specified in readDisks.
-			parallelRead(volume, path, readDisks, disks, enBlocks, blockOffset, curChunkSize, bitRotVerify, pool)
-			if isSuccessDecodeBlocks(enBlocks, dataBlocks) {
-				// If enough blocks are available to do rs.Reconstruct()
-				break
-			}
-			if nextIndex == len(disks) {
-				// No more disks to read from.
-				return bytesWritten, traceError(errXLReadQuorum)
-			}
-			// We do not have enough enough data blocks to reconstruct the data
-			// hence continue the for-loop till we have enough data blocks.
-		}
-
-		// If we have all the data blocks no need to decode, continue to write.
-		if !isSuccessDataBlocks(enBlocks, dataBlocks) {
-			// Reconstruct the missing data blocks.
-			if err := decodeData(enBlocks, dataBlocks, parityBlocks); err != nil {
-				return bytesWritten, err
-			}
-		}
-
 		// Offset in enBlocks from where data should be read from.
 		enBlocksOffset := int64(0)
 
@@ -280,8 +250,23 @@ func erasureReadFile(writer io.Writer, disks []StorageAPI, volume string, path s
 			enBlocksLength = remaining
 		}
 
-		// Write data blocks.
-		n, err := writeDataBlocks(writer, enBlocks, dataBlocks, enBlocksOffset, enBlocksLength)
+		// Read only the data chunks holding the requested range, no
+		// other disk is read nor verified for bit rot unless one of
+		// them fails.
+		firstChunk, lastChunk := getDataChunkRange(enBlocksOffset, enBlocksLength, curChunkSize)
+		if !readDataChunks(volume, path, disks, enBlocks, firstChunk, lastChunk, blockOffset, curChunkSize, bitRotVerify, pool) {
+			// Start over reading all the data blocks, failed disks
+			// were already removed from disks.
+			pool.Reset()
+			enBlocks = make([][]byte, len(disks))
+			if err := readDecodeBlocks(volume, path, disks, enBlocks, dataBlocks, parityBlocks, blockOffset, curChunkSize, bitRotVerify, pool); err != nil {
+				return bytesWritten, err
+			}
+		}
+
+		// Write data blocks, from the first chunk read on.
+		chunkOffset := int64(firstChunk) * curChunkSize
+		n, err := writeDataBlocks(writer, enBlocks[firstChunk:], dataBlocks-firstChunk, enBlocksOffset-chunkOffset, enBlocksLength)
 		if err != nil {
 			return bytesWritten, err
 		}
@@ -299,6 +284,74 @@ func erasureReadFile(writer io.Writer, disks []StorageAPI, volume string, path s
 	return bytesWritten, nil
 }
 
+// readDecodeBlocks - reads the chunks of a block from as many disks as
+// needed and reconstructs the missing data blocks.
+func readDecodeBlocks(volume, path string, disks []StorageAPI, enBlocks [][]byte, dataBlocks, parityBlocks int, blockOffset, curChunkSize int64, bitRotVerify func(diskIndex int) bool, pool *bpool.BytePool) error {
+	// nextIndex - index from which next set of parallel reads
+	// should happen.
+	nextIndex := 0
+
+	for {
+		// readDisks - disks from which we need to read in parallel.
+		var readDisks []StorageAPI
+		var err error
+		// get readable disks slice from which we can read parallelly.
+		readDisks, nextIndex, err = getReadDisks(disks, nextIndex, dataBlocks)
+		if err != nil {
+			return err
+		}
+		// Issue a parallel read across the disks specified in readDisks.
+		parallelRead(volume, path, readDisks, disks, enBlocks, blockOffset, curChunkSize, bitRotVerify, pool)
+		if isSuccessDecodeBlocks(enBlocks, dataBlocks) {
+			// If enough blocks are available to do rs.Reconstruct()
+			break
+		}
+		if nextIndex == len(disks) {
+			// No more disks to read from.
+			return traceError(errXLReadQuorum)
+		}
+		// We do not have enough enough data blocks to reconstruct the data
+		// hence continue the for-loop till we have enough data blocks.
+	}
+
+	// If we have all the data blocks no need to decode.
+	if isSuccessDataBlocks(enBlocks, dataBlocks) {
+		return nil
+	}
+	// Reconstruct the missing data blocks.
+	return decodeData(enBlocks, dataBlocks, parityBlocks)
+}
+
+// getDataChunkRange - returns the indices of the first and the last
+// data chunks holding length bytes from offset in a block.
+func getDataChunkRange(offset, length, chunkSize int64) (firstChunk, lastChunk int) {
+	firstChunk = int(offset / chunkSize)
+	lastChunk = int((offset + length - 1) / chunkSize)
+	if lastChunk < firstChunk {
+		lastChunk = firstChunk
+	}
+	return firstChunk, lastChunk
+}
+
+// readDataChunks - reads the data chunks firstChunk to lastChunk of a
+// block in parallel, returns false if any of them couldn't be read.
+func readDataChunks(volume, path string, disks []StorageAPI, enBlocks [][]byte, firstChunk, lastChunk int, blockOffset, curChunkSize int64, bitRotVerify func(diskIndex int) bool, pool *bpool.BytePool) bool {
+	readDisks := make([]StorageAPI, len(disks))
+	for index := firstChunk; index <= lastChunk; index++ {
+		if disks[index] == nil {
+			return false
+		}
+		readDisks[index] = disks[index]
+	}
+	parallelRead(volume, path, readDisks, disks, enBlocks, blockOffset, curChunkSize, bitRotVerify, pool)
+	for index := firstChunk; index <= lastChunk; index++ {
+		if enBlocks[index] == nil {
+			return false
+		}
+	}
+	return true
+}
+
 // isValidBlock - calculates the checksum hash for the block and
 // validates if its correct returns true for valid cases, false otherwise.
 func isValidBlock(disk StorageAPI, volume, path, checkSum, checkSumAlgo string) (ok bool) {
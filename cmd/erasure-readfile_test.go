@@ -19,6 +19,7 @@ package cmd
 import (
 	"bytes"
 	"math/rand"
+	"sync"
 	"testing"
 	"time"
 
@@ -396,6 +397,103 @@ func TestErasureReadFileOffsetLength(t *testing.T) {
 	}
 }
 
+// Records the disks read from by ReadFile(), bit rot verification
+// included.
+type ReadDiskRecord struct {
+	*posix
+	index int
+	read  *[]bool
+	mutex *sync.Mutex
+}
+
+func (r ReadDiskRecord) ReadFile(volume string, path string, offset int64, buf []byte) (n int64, err error) {
+	r.mutex.Lock()
+	(*r.read)[r.index] = true
+	r.mutex.Unlock()
+	return r.posix.ReadFile(volume, path, offset, buf)
+}
+
+// Tests erasureReadFile only reads the disks holding the requested range.
+func TestErasureReadFileNeededDisks(t *testing.T) {
+	// Initialize environment needed for the test.
+	dataBlocks := 7
+	parityBlocks := 7
+	blockSize := int64(1 * 1024 * 1024)
+	setup, err := newErasureTestSetup(dataBlocks, parityBlocks, blockSize)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer setup.Remove()
+
+	// Prepare a slice of 5.1MB with random data, the last block is
+	// shorter.
+	data := make([]byte, 5*1024*1024+100*1024)
+	length := int64(len(data))
+	if _, err = rand.Read(data); err != nil {
+		t.Fatal(err)
+	}
+	_, checkSums, err := erasureCreateFile(setup.disks, "testbucket", "testobject", bytes.NewReader(data), blockSize, dataBlocks, parityBlocks, bitRotAlgo, dataBlocks+1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	chunkSize := getChunkSize(blockSize, dataBlocks)
+	lastBlockOffset := length - length%blockSize
+	lastChunkSize := getChunkSize(length%blockSize, dataBlocks)
+	pool := bpool.NewBytePool(chunkSize, len(setup.disks))
+
+	testCases := []struct {
+		offset, length int64
+		downDisks      []int
+		readDisks      []int
+	}{
+		// Within the first chunk of a block.
+		{blockSize + 10, 100, nil, []int{0}},
+		// Across two chunks.
+		{chunkSize - 1, 2, nil, []int{0, 1}},
+		// Across two blocks.
+		{2*blockSize - 1, 2, nil, []int{0, 6}},
+		// Within the last chunk of the last, shorter, block.
+		{length - 1, 1, nil, []int{6}},
+		{lastBlockOffset + 6*lastChunkSize - 1, 2, nil, []int{5, 6}},
+		// Whole block.
+		{blockSize, blockSize, nil, []int{0, 1, 2, 3, 4, 5, 6}},
+		// Needed disk down, the other data disks and a parity disk
+		// are read.
+		{10, 100, []int{0}, []int{1, 2, 3, 4, 5, 6, 7}},
+		// Disk down which isn't needed.
+		{10, 100, []int{1}, []int{0}},
+	}
+	for i, testCase := range testCases {
+		read := make([]bool, len(setup.disks))
+		mutex := &sync.Mutex{}
+		disks := make([]StorageAPI, len(setup.disks))
+		for index, disk := range setup.disks {
+			disks[index] = ReadDiskRecord{disk.(*posix), index, &read, mutex}
+		}
+		for _, index := range testCase.downDisks {
+			disks[index] = ReadDiskDown{setup.disks[index].(*posix)}
+		}
+
+		buf := &bytes.Buffer{}
+		_, err = erasureReadFile(buf, disks, "testbucket", "testobject", testCase.offset, testCase.length, length, blockSize, dataBlocks, parityBlocks, checkSums, bitRotAlgo, pool)
+		if err != nil {
+			t.Fatalf("Test %d: %v", i+1, err)
+		}
+		if !bytes.Equal(buf.Bytes(), data[testCase.offset:testCase.offset+testCase.length]) {
+			t.Errorf("Test %d: read data is different from what was expected", i+1)
+		}
+		var readDisks []int
+		for index := range read {
+			if read[index] {
+				readDisks = append(readDisks, index)
+			}
+		}
+		if !reflect.DeepEqual(readDisks, testCase.readDisks) {
+			t.Errorf("Test %d: Expected disks %v to be read, got %v", i+1, testCase.readDisks, readDisks)
+		}
+	}
+}
+
 // Test erasureReadFile with random offset and lengths.
 // This test is t.Skip()ed as it a long time to run, hence should be run
 // explicitly after commenting out t.Skip()
@@ -0,0 +1,89 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+)
+
+// errorCatalogEntry - a single error code of the error catalog.
+type errorCatalogEntry struct {
+	Code           string `json:"code"`
+	Description    string `json:"description"`
+	HTTPStatusCode int    `json:"httpStatusCode"`
+	// Set for errors specific to Minio, not sent by Amazon S3.
+	Extended bool `json:"extended"`
+}
+
+// errorCatalog - all the error responses this server may send.
+type errorCatalog struct {
+	Errors []errorCatalogEntry `json:"errors"`
+}
+
+// getErrorCatalog - returns the error catalog in the order of the API
+// error codes, error codes sharing the same code, description and
+// status are listed once.
+func getErrorCatalog() errorCatalog {
+	var apiErrCodes []int
+	for apiErrCode := range errorCodeResponse {
+		apiErrCodes = append(apiErrCodes, int(apiErrCode))
+	}
+	sort.Ints(apiErrCodes)
+
+	catalog := errorCatalog{Errors: []errorCatalogEntry{}}
+	seen := make(map[APIError]bool)
+	for _, apiErrCode := range apiErrCodes {
+		apiErr := errorCodeResponse[APIErrorCode(apiErrCode)]
+		if seen[apiErr] {
+			continue
+		}
+		seen[apiErr] = true
+		catalog.Errors = append(catalog.Errors, errorCatalogEntry{
+			Code:           apiErr.Code,
+			Description:    apiErr.Description,
+			HTTPStatusCode: apiErr.HTTPStatusCode,
+			// Minio extended errors are declared last.
+			Extended: APIErrorCode(apiErrCode) >= ErrReadQuorum,
+		})
+	}
+	return catalog
+}
+
+// ListErrorCodesHandler - GET Service errors (minio extension)
+// -----------------
+// This operation uses the errors subresource to return the catalog of
+// all the error codes this server may respond with, along with their
+// HTTP status code and description.
+func (api objectAPIHandlers) ListErrorCodesHandler(w http.ResponseWriter, r *http.Request) {
+	// ListErrorCodes does not support bucket policies, proceed to validate signature.
+	if s3Error := checkAuthWithRegion(r, ""); s3Error != ErrNone {
+		errorIf(errSignatureMismatch, dumpRequest(r))
+		writeErrorResponse(w, r, s3Error, r.URL.Path)
+		return
+	}
+
+	catalogBytes, err := json.Marshal(getErrorCatalog())
+	if err != nil {
+		errorIf(err, "Unable to marshal error catalog.")
+		writeErrorResponse(w, r, ErrInternalError, r.URL.Path)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	writeSuccessResponse(w, catalogBytes)
+}
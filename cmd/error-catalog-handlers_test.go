@@ -0,0 +1,104 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// Tests the error catalog contains all the error codes exactly once.
+func TestGetErrorCatalog(t *testing.T) {
+	catalog := getErrorCatalog()
+	seen := make(map[errorCatalogEntry]bool)
+	for _, entry := range catalog.Errors {
+		if seen[entry] {
+			t.Errorf("Duplicate catalog entry %v", entry)
+		}
+		seen[entry] = true
+	}
+	for apiErrCode, apiErr := range errorCodeResponse {
+		entry := errorCatalogEntry{
+			Code:           apiErr.Code,
+			Description:    apiErr.Description,
+			HTTPStatusCode: apiErr.HTTPStatusCode,
+			Extended:       apiErrCode >= ErrReadQuorum,
+		}
+		if !seen[entry] {
+			t.Errorf("Missing catalog entry %v", entry)
+		}
+	}
+}
+
+// Wrapper for calling ListErrorCodes HTTP handler tests for both XL multiple disks and single node setup.
+func TestListErrorCodesHandler(t *testing.T) {
+	ExecObjectLayerAPITest(t, testListErrorCodesHandler, []string{"ListErrorCodes"})
+}
+
+func testListErrorCodesHandler(obj ObjectLayer, instanceType, bucketName string, apiRouter http.Handler,
+	credentials credential, t *testing.T) {
+
+	testCases := []struct {
+		accessKey          string
+		secretKey          string
+		expectedRespStatus int
+	}{
+		{credentials.AccessKeyID, credentials.SecretAccessKey, http.StatusOK},
+		// Invalid credentials are rejected.
+		{"abcd", "abcd", http.StatusForbidden},
+	}
+	for i, testCase := range testCases {
+		rec := httptest.NewRecorder()
+		req, err := newTestSignedRequestV4("GET", getListErrorCodesURL(""), 0, nil, testCase.accessKey, testCase.secretKey)
+		if err != nil {
+			t.Fatalf("Test %d: %s: Failed to create HTTP request for ListErrorCodesHandler: <ERROR> %v", i+1, instanceType, err)
+		}
+		apiRouter.ServeHTTP(rec, req)
+		if rec.Code != testCase.expectedRespStatus {
+			t.Fatalf("Test %d: %s: Expected the response status to be `%d`, but instead found `%d`", i+1, instanceType, testCase.expectedRespStatus, rec.Code)
+		}
+		if rec.Code != http.StatusOK {
+			continue
+		}
+		var catalog errorCatalog
+		if err = json.Unmarshal(rec.Body.Bytes(), &catalog); err != nil {
+			t.Fatalf("Test %d: %s: Unable to decode error catalog: <ERROR> %v", i+1, instanceType, err)
+		}
+		found := false
+		for _, entry := range catalog.Errors {
+			if entry.Code == "NoSuchBucket" && entry.HTTPStatusCode == http.StatusNotFound && !entry.Extended {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("Test %d: %s: Expected NoSuchBucket in the error catalog", i+1, instanceType)
+		}
+	}
+
+	// Anonymous requests are rejected.
+	rec := httptest.NewRecorder()
+	anonReq, err := newTestRequest("GET", getListErrorCodesURL(""), 0, nil)
+	if err != nil {
+		t.Fatalf("%s: Failed to create an anonymous request: <ERROR> %v", instanceType, err)
+	}
+	apiRouter.ServeHTTP(rec, anonReq)
+	if rec.Code != http.StatusForbidden {
+		t.Errorf("%s: Expected anonymous request to be rejected, got `%d`", instanceType, rec.Code)
+	}
+}
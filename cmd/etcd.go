@@ -0,0 +1,270 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// Keys are stored under this prefix unless MINIO_ETCD_PREFIX is set.
+	etcdDefaultPrefix = "/minio"
+
+	// Timeout of requests to etcd, watches excluded.
+	etcdRequestTimeout = 5 * time.Second
+
+	// Time waited before watching again after a failed watch.
+	etcdWatchRetryDelay = 1 * time.Second
+)
+
+// Error codes returned by the etcd v2 keys API.
+const (
+	etcdErrorKeyNotFound   = 100
+	etcdErrorCompareFailed = 101
+	etcdErrorNodeExist     = 105
+	etcdErrorEventIndex    = 401
+)
+
+var (
+	// errEtcdKeyNotFound - key does not exist in etcd.
+	errEtcdKeyNotFound = errors.New("Key not found in etcd")
+
+	// errEtcdKeyModified - key exists or does not hold the expected
+	// value in etcd.
+	errEtcdKeyModified = errors.New("Key was modified in etcd")
+
+	// errEtcdEventCleared - events watched for were cleared from the
+	// etcd history, keys must be read again.
+	errEtcdEventCleared = errors.New("Watched events were cleared in etcd")
+)
+
+// etcdNode - key returned by the etcd v2 keys API.
+type etcdNode struct {
+	Key           string `json:"key"`
+	Value         string `json:"value,omitempty"`
+	Dir           bool   `json:"dir,omitempty"`
+	ModifiedIndex uint64 `json:"modifiedIndex"`
+}
+
+// etcdResponse - response of the etcd v2 keys API.
+type etcdResponse struct {
+	Action string    `json:"action"`
+	Node   *etcdNode `json:"node"`
+
+	// Index of etcd when the response was sent, from the
+	// X-Etcd-Index header.
+	index uint64
+}
+
+// etcdError - error of the etcd v2 keys API.
+type etcdError struct {
+	ErrorCode int    `json:"errorCode"`
+	Message   string `json:"message"`
+	Cause     string `json:"cause"`
+}
+
+func (e etcdError) Error() string {
+	return fmt.Sprintf("etcd: %s (%s)", e.Message, e.Cause)
+}
+
+// etcdClient - client of the etcd v2 keys API, endpoints are tried in
+// order until one of them answers.
+type etcdClient struct {
+	endpoints []string
+	prefix    string
+
+	client      *http.Client
+	watchClient *http.Client
+}
+
+// parseEtcdEndpoints - parses a comma separated list of etcd endpoint
+// URLs.
+func parseEtcdEndpoints(value string) ([]string, error) {
+	var endpoints []string
+	for _, endpoint := range strings.Split(value, ",") {
+		u, err := parseFederationURL(strings.TrimSpace(endpoint))
+		if err != nil {
+			return nil, err
+		}
+		endpoints = append(endpoints, u.String())
+	}
+	return endpoints, nil
+}
+
+// newEtcdClient - returns a client storing keys under prefix on the
+// etcd cluster at endpoints.
+func newEtcdClient(endpoints []string, prefix string) *etcdClient {
+	return &etcdClient{
+		endpoints:   endpoints,
+		prefix:      path.Join(slashSeparator, prefix),
+		client:      &http.Client{Timeout: etcdRequestTimeout, Transport: newOutboundTransport(false)},
+		watchClient: &http.Client{Transport: newOutboundTransport(false)},
+	}
+}
+
+// relKey - returns key relative to the prefix of the client.
+func (c *etcdClient) relKey(key string) string {
+	return strings.TrimPrefix(strings.TrimPrefix(key, c.prefix), slashSeparator)
+}
+
+// do - sends a request for key to the first etcd endpoint answering,
+// watches are cancelled by closing cancelCh.
+func (c *etcdClient) do(method, key string, query, form url.Values, cancelCh <-chan struct{}) (*etcdResponse, error) {
+	var err error
+	for _, endpoint := range c.endpoints {
+		var req *http.Request
+		reqURL := endpoint + "/v2/keys" + path.Join(c.prefix, key)
+		if len(query) > 0 {
+			reqURL += "?" + query.Encode()
+		}
+		if req, err = http.NewRequest(method, reqURL, strings.NewReader(form.Encode())); err != nil {
+			return nil, err
+		}
+		if len(form) > 0 {
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		}
+		client := c.client
+		if query.Get("wait") == "true" {
+			// Watches long poll until key changes.
+			client = c.watchClient
+			req.Cancel = cancelCh
+		}
+		var resp *http.Response
+		if resp, err = client.Do(req); err != nil {
+			// Try the next endpoint.
+			continue
+		}
+		defer resp.Body.Close()
+		return decodeEtcdResponse(resp)
+	}
+	return nil, err
+}
+
+// decodeEtcdResponse - decodes a response of the etcd v2 keys API,
+// errors are mapped to errEtcd* where possible. The index of etcd is
+// returned along with errors too.
+func decodeEtcdResponse(resp *http.Response) (*etcdResponse, error) {
+	etcdResp := &etcdResponse{}
+	etcdResp.index, _ = strconv.ParseUint(resp.Header.Get("X-Etcd-Index"), 10, 64)
+	decoder := json.NewDecoder(resp.Body)
+	if resp.StatusCode/100 != 2 {
+		etcdErr := etcdError{}
+		if err := decoder.Decode(&etcdErr); err != nil {
+			return etcdResp, fmt.Errorf("etcd: unexpected response %s", resp.Status)
+		}
+		switch etcdErr.ErrorCode {
+		case etcdErrorKeyNotFound:
+			return etcdResp, errEtcdKeyNotFound
+		case etcdErrorCompareFailed, etcdErrorNodeExist:
+			return etcdResp, errEtcdKeyModified
+		case etcdErrorEventIndex:
+			return etcdResp, errEtcdEventCleared
+		}
+		return etcdResp, etcdErr
+	}
+	if err := decoder.Decode(etcdResp); err != nil {
+		return etcdResp, err
+	}
+	if etcdResp.Node == nil {
+		return etcdResp, fmt.Errorf("etcd: response without node")
+	}
+	return etcdResp, nil
+}
+
+// get - returns the value of key.
+func (c *etcdClient) get(key string) (string, error) {
+	resp, err := c.do("GET", key, nil, nil, nil)
+	if err != nil {
+		return "", err
+	}
+	return resp.Node.Value, nil
+}
+
+// put - sets the value of key.
+func (c *etcdClient) put(key, value string) error {
+	_, err := c.do("PUT", key, nil, url.Values{"value": {value}}, nil)
+	return err
+}
+
+// create - sets the value of key, fails with errEtcdKeyModified if
+// key already exists.
+func (c *etcdClient) create(key, value string) error {
+	_, err := c.do("PUT", key, nil, url.Values{"value": {value}, "prevExist": {"false"}}, nil)
+	return err
+}
+
+// compareAndDelete - deletes key, fails with errEtcdKeyModified if
+// it does not hold prevValue.
+func (c *etcdClient) compareAndDelete(key, prevValue string) error {
+	_, err := c.do("DELETE", key, url.Values{"prevValue": {prevValue}}, nil, nil)
+	return err
+}
+
+// currentIndex - returns the current index of etcd, changes of key
+// after it are watched.
+func (c *etcdClient) currentIndex(key string) (uint64, error) {
+	resp, err := c.do("GET", key, nil, nil, nil)
+	if err != nil && err != errEtcdKeyNotFound {
+		return 0, err
+	}
+	return resp.index, nil
+}
+
+// watch - calls fn with the key, relative to the prefix, of every
+// change of key and the keys below it, until doneCh is closed. fn is
+// called with an empty key when changes may have been missed.
+func (c *etcdClient) watch(key string, doneCh <-chan struct{}, fn func(key string)) {
+	index, err := c.currentIndex(key)
+	for {
+		if err == nil {
+			var resp *etcdResponse
+			resp, err = c.do("GET", key, url.Values{
+				"wait":      {"true"},
+				"recursive": {"true"},
+				"waitIndex": {strconv.FormatUint(index+1, 10)},
+			}, nil, doneCh)
+			if err == nil {
+				index = resp.Node.ModifiedIndex
+				fn(c.relKey(resp.Node.Key))
+				continue
+			}
+		}
+		select {
+		case <-doneCh:
+			return
+		default:
+		}
+		if err != errEtcdEventCleared {
+			errorIf(err, "Unable to watch %s in etcd.", key)
+			select {
+			case <-doneCh:
+				return
+			case <-time.After(etcdWatchRetryDelay):
+			}
+		}
+		// Changes may have been missed, start over from the current index.
+		index, err = c.currentIndex(key)
+		fn("")
+	}
+}
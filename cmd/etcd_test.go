@@ -0,0 +1,333 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package cmd
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeEtcd - in memory etcd v2 keys API, enough for etcdClient.
+type fakeEtcd struct {
+	mutex  sync.Mutex
+	index  uint64
+	keys   map[string]etcdNode
+	events []etcdNode
+}
+
+func newFakeEtcd() *httptest.Server {
+	return httptest.NewServer(&fakeEtcd{keys: make(map[string]etcdNode)})
+}
+
+func (e *fakeEtcd) writeError(w http.ResponseWriter, status, code int, key string) {
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(etcdError{ErrorCode: code, Message: http.StatusText(status), Cause: key})
+}
+
+func (e *fakeEtcd) writeNode(w http.ResponseWriter, action string, node etcdNode) {
+	json.NewEncoder(w).Encode(etcdResponse{Action: action, Node: &node})
+}
+
+// nextEvent - returns the first change of key or the keys below it
+// from index on.
+func (e *fakeEtcd) nextEvent(key string, index uint64) (etcdNode, bool) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	for _, event := range e.events {
+		if event.ModifiedIndex >= index && (event.Key == key || strings.HasPrefix(event.Key, key+"/")) {
+			return event, true
+		}
+	}
+	return etcdNode{}, false
+}
+
+func (e *fakeEtcd) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	key := strings.TrimPrefix(r.URL.Path, "/v2/keys")
+	if r.URL.Query().Get("wait") == "true" {
+		index, _ := strconv.ParseUint(r.URL.Query().Get("waitIndex"), 10, 64)
+		for {
+			if event, ok := e.nextEvent(key, index); ok {
+				e.writeNode(w, "set", event)
+				return
+			}
+			select {
+			case <-r.Context().Done():
+				return
+			case <-time.After(10 * time.Millisecond):
+			}
+		}
+	}
+
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	w.Header().Set("X-Etcd-Index", strconv.FormatUint(e.index, 10))
+	node, ok := e.keys[key]
+	switch r.Method {
+	case "GET":
+		if !ok {
+			e.writeError(w, http.StatusNotFound, etcdErrorKeyNotFound, key)
+			return
+		}
+		e.writeNode(w, "get", node)
+	case "PUT":
+		if ok && r.FormValue("prevExist") == "false" {
+			e.writeError(w, http.StatusPreconditionFailed, etcdErrorNodeExist, key)
+			return
+		}
+		e.index++
+		node = etcdNode{Key: key, Value: r.FormValue("value"), ModifiedIndex: e.index}
+		e.keys[key] = node
+		e.events = append(e.events, node)
+		e.writeNode(w, "set", node)
+	case "DELETE":
+		if !ok {
+			e.writeError(w, http.StatusNotFound, etcdErrorKeyNotFound, key)
+			return
+		}
+		if prevValue := r.URL.Query().Get("prevValue"); prevValue != "" && prevValue != node.Value {
+			e.writeError(w, http.StatusPreconditionFailed, etcdErrorCompareFailed, key)
+			return
+		}
+		e.index++
+		delete(e.keys, key)
+		node = etcdNode{Key: key, ModifiedIndex: e.index}
+		e.events = append(e.events, node)
+		e.writeNode(w, "delete", node)
+	}
+}
+
+// Tests parsing etcd endpoints.
+func TestParseEtcdEndpoints(t *testing.T) {
+	testCases := []struct {
+		value    string
+		expected []string
+		err      bool
+	}{
+		{"http://127.0.0.1:2379", []string{"http://127.0.0.1:2379"}, false},
+		{"https://etcd1:2379, https://etcd2:2379/", []string{"https://etcd1:2379", "https://etcd2:2379"}, false},
+		{"etcd1:2379", nil, true},
+		{"http://etcd1:2379,", nil, true},
+		{"http://etcd1:2379/v2/keys", nil, true},
+	}
+	for i, testCase := range testCases {
+		endpoints, err := parseEtcdEndpoints(testCase.value)
+		if (err != nil) != testCase.err {
+			t.Errorf("Test %d: Expected error %v, got %v", i+1, testCase.err, err)
+			continue
+		}
+		if strings.Join(endpoints, ",") != strings.Join(testCase.expected, ",") {
+			t.Errorf("Test %d: Expected %v, got %v", i+1, testCase.expected, endpoints)
+		}
+	}
+}
+
+// Tests reading and writing keys, the first endpoint is unreachable.
+func TestEtcdClient(t *testing.T) {
+	server := newFakeEtcd()
+	defer server.Close()
+	etcd := newEtcdClient([]string{"http://127.0.0.1:1", server.URL}, etcdDefaultPrefix)
+
+	if _, err := etcd.get("key"); err != errEtcdKeyNotFound {
+		t.Fatalf("Expected %v, got %v", errEtcdKeyNotFound, err)
+	}
+	if err := etcd.put("key", "value1"); err != nil {
+		t.Fatal(err)
+	}
+	if value, err := etcd.get("key"); err != nil || value != "value1" {
+		t.Fatalf("Expected value1, got %s, %v", value, err)
+	}
+	if err := etcd.create("key", "value2"); err != errEtcdKeyModified {
+		t.Fatalf("Expected %v, got %v", errEtcdKeyModified, err)
+	}
+	if err := etcd.compareAndDelete("key", "value2"); err != errEtcdKeyModified {
+		t.Fatalf("Expected %v, got %v", errEtcdKeyModified, err)
+	}
+	if err := etcd.compareAndDelete("key", "value1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := etcd.create("key", "value2"); err != nil {
+		t.Fatal(err)
+	}
+	if value, err := etcd.get("key"); err != nil || value != "value2" {
+		t.Fatalf("Expected value2, got %s, %v", value, err)
+	}
+
+	// Keys are stored under the prefix.
+	if _, ok := server.Config.Handler.(*fakeEtcd).keys["/minio/key"]; !ok {
+		t.Fatal("Expected key to be stored under /minio")
+	}
+}
+
+// Tests watching keys until the watch is stopped.
+func TestEtcdWatch(t *testing.T) {
+	server := newFakeEtcd()
+	defer server.Close()
+	etcd := newEtcdClient([]string{server.URL}, etcdDefaultPrefix)
+	if err := etcd.put("dir/old", "value"); err != nil {
+		t.Fatal(err)
+	}
+
+	keyCh := make(chan string, 10)
+	doneCh := make(chan struct{})
+	stoppedCh := make(chan struct{})
+	go func() {
+		etcd.watch("dir", doneCh, func(key string) { keyCh <- key })
+		close(stoppedCh)
+	}()
+
+	// Changes before the watch started are not reported.
+	time.Sleep(50 * time.Millisecond)
+	for _, key := range []string{"other", "dir/a", "dir/b/c"} {
+		if err := etcd.put(key, "value"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, expected := range []string{"dir/a", "dir/b/c"} {
+		select {
+		case key := <-keyCh:
+			if key != expected {
+				t.Fatalf("Expected %s, got %s", expected, key)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("Timed out waiting for %s", expected)
+		}
+	}
+
+	close(doneCh)
+	select {
+	case <-stoppedCh:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for the watch to stop")
+	}
+}
+
+// Tests sharing config.json through etcd.
+func TestEtcdConfig(t *testing.T) {
+	server := newFakeEtcd()
+	defer server.Close()
+	defer func() { globalEtcd = nil }()
+	globalEtcd = newEtcdClient([]string{server.URL}, etcdDefaultPrefix)
+
+	// The first server stores its config in etcd.
+	root, err := newTestConfig("us-west-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer removeAll(root)
+	creds := serverConfig.GetCredential()
+	if _, err = globalEtcd.get(etcdConfigKey); err != nil {
+		t.Fatal(err)
+	}
+
+	// Other servers load it.
+	serverConfig = nil
+	if err = initConfig(); err != nil {
+		t.Fatal(err)
+	}
+	if serverConfig.GetCredential() != creds || serverConfig.GetRegion() != "us-west-1" {
+		t.Fatalf("Expected config loaded from etcd, got %v", serverConfig)
+	}
+
+	// Changes by other servers are reloaded, secrets are encrypted.
+	globalConfigPassphrase = "passphrase"
+	defer func() { globalConfigPassphrase = "" }()
+	newCreds := mustGenAccessKeys()
+	srvCfg := *serverConfig
+	srvCfg.Credential = newCreds
+	srvCfg.Region = "eu-west-1"
+	if err = srvCfg.Save(); err != nil {
+		t.Fatal(err)
+	}
+	value, err := globalEtcd.get(etcdConfigKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(value, newCreds.SecretAccessKey) {
+		t.Fatal("Expected secret key to be encrypted in etcd")
+	}
+	if err = reloadEtcdConfig(); err != nil {
+		t.Fatal(err)
+	}
+	if serverConfig.GetCredential() != newCreds || serverConfig.GetRegion() != "eu-west-1" {
+		t.Fatalf("Expected config reloaded from etcd, got %v", serverConfig)
+	}
+}
+
+// Tests federating buckets through etcd.
+func TestEtcdFederation(t *testing.T) {
+	server := newFakeEtcd()
+	defer server.Close()
+	etcd := newEtcdClient([]string{server.URL}, etcdDefaultPrefix)
+
+	cluster1 := newEtcdFederation(&url.URL{Scheme: "https", Host: "cluster1.example.com"}, etcd, federationRedirect)
+	cluster2 := newEtcdFederation(&url.URL{Scheme: "https", Host: "cluster2.example.com"}, etcd, federationRedirect)
+	doneCh := make(chan struct{})
+	defer close(doneCh)
+	go cluster1.startEtcdWatch(doneCh)
+
+	// Cache the bucket as unclaimed on cluster1.
+	if owner, err := cluster1.lookup("bucket"); err != nil || owner != "" {
+		t.Fatalf("Expected no owner, got %s, %v", owner, err)
+	}
+	time.Sleep(50 * time.Millisecond)
+
+	if claimed, err := cluster2.claim("bucket"); err != nil || !claimed {
+		t.Fatalf("Expected bucket to be claimed, got %v, %v", claimed, err)
+	}
+	if claimed, err := cluster2.claim("bucket"); err != nil || claimed {
+		t.Fatalf("Expected bucket to be already claimed, got %v, %v", claimed, err)
+	}
+	if _, err := cluster1.claim("bucket"); !isSameType(err, BucketAlreadyExists{}) {
+		t.Fatalf("Expected BucketAlreadyExists, got %v", err)
+	}
+
+	// The cached owner is forgotten once the claim is watched.
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		owner, err := cluster1.lookup("bucket")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if owner == cluster2.endpoint {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected owner %s, got %s", cluster2.endpoint, owner)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	// Only the owner releases a bucket.
+	if err := cluster1.release("bucket"); err != nil {
+		t.Fatal(err)
+	}
+	if owner, err := lookupEtcdBucket(etcd, "bucket"); err != nil || owner != cluster2.endpoint {
+		t.Fatalf("Expected owner %s, got %s, %v", cluster2.endpoint, owner, err)
+	}
+	if err := cluster2.release("bucket"); err != nil {
+		t.Fatal(err)
+	}
+	if owner, err := lookupEtcdBucket(etcd, "bucket"); err != nil || owner != "" {
+		t.Fatalf("Expected no owner, got %s, %v", owner, err)
+	}
+}
@@ -92,6 +92,10 @@ func newNotificationEvent(event eventData) NotificationEvent {
 	region := serverConfig.GetRegion()
 	tnow := time.Now().UTC()
 	sequencer := fmt.Sprintf("%X", tnow.UnixNano())
+	reqParams := event.ReqParams
+	if globalClientEnricher != nil {
+		reqParams = globalClientEnricher.Enrich(reqParams)
+	}
 	// Following blocks fills in all the necessary details of s3
 	// event message structure.
 	// http://docs.aws.amazon.com/AmazonS3/latest/dev/notification-content-structure.html
@@ -102,7 +106,7 @@ func newNotificationEvent(event eventData) NotificationEvent {
 		EventTime:         tnow.Format(timeFormatAMZ),
 		EventName:         event.Type.String(),
 		UserIdentity:      defaultIdentity(),
-		RequestParameters: event.ReqParams,
+		RequestParameters: reqParams,
 		ResponseElements:  map[string]string{},
 		S3: eventMeta{
 			SchemaVersion:   "1.0",
@@ -235,6 +239,7 @@ func eventNotifyForBucketNotifications(eventType, objectName, bucketName string,
 		if eventMatch && ruleMatch {
 			targetLog := globalEventNotifier.GetExternalTarget(qConfig.QueueARN)
 			if targetLog != nil {
+				globalEventStats.Generated(bucketName, qConfig.QueueARN)
 				targetLog.WithFields(logrus.Fields{
 					"Key":       path.Join(bucketName, objectName),
 					"EventType": eventType,
@@ -259,6 +264,7 @@ func eventNotifyForBucketListeners(eventType, objectName, bucketName string,
 			targetLog := globalEventNotifier.GetInternalTarget(
 				lcfg.TopicConfig.TopicARN)
 			if targetLog != nil && targetLog.log != nil {
+				globalEventStats.Generated(bucketName, lcfg.TopicConfig.TopicARN)
 				targetLog.log.WithFields(logrus.Fields{
 					"Key":       path.Join(bucketName, objectName),
 					"EventType": eventType,
@@ -297,6 +303,12 @@ func eventNotify(event eventData) {
 	// Notify internal targets.
 	eventNotifyForBucketListeners(eventType, objectName, event.Bucket,
 		notificationEvent)
+
+	// Keep the event for replay to listeners.
+	globalRecentEvents.Add(event.Bucket, eventType, objectName, notificationEvent[0])
+
+	// Update the search index.
+	eventNotifyForBucketSearch(event.Bucket, objectName)
 }
 
 // loads notification config if any for a given bucket, returns
@@ -618,6 +630,11 @@ func loadAllQueueTargets() (map[string]*logrus.Logger, error) {
 		queueTargets[queueARN] = pgLog
 	}
 
+	// Record event statistics of all the queue targets.
+	for queueARN, queueLog := range queueTargets {
+		addEventStatsHooks(queueARN, queueLog)
+	}
+
 	// Successfully initialized queue targets.
 	return queueTargets, nil
 }
@@ -21,6 +21,7 @@ import (
 	"net"
 	"reflect"
 	"strconv"
+	"sync"
 	"testing"
 	"time"
 )
@@ -492,4 +493,23 @@ func TestAddRemoveBucketListenerConfig(t *testing.T) {
 		t.Errorf("Remove Listener Config Test: did not remove listener config - %v",
 			lcSlice)
 	}
+
+	// Listeners added concurrently are all kept.
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			lCfg := *sampleListenerCfg
+			lCfg.TopicConfig.TopicARN = fmt.Sprintf("%s-%d", accountARN, i)
+			if err := AddBucketListenerConfig(randBucket, &lCfg, obj); err != nil {
+				t.Errorf("Listener %d: Failed with error %v", i, err)
+			}
+		}(i)
+	}
+	wg.Wait()
+	lcSlice, err := loadListenerConfig(randBucket, obj)
+	if err != nil || len(lcSlice) != 5 {
+		t.Errorf("Concurrent Add Listener Config Test: expected 5 listeners, got %v (%v)", lcSlice, err)
+	}
 }
@@ -0,0 +1,106 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import "sync"
+
+// Number of recent events kept per bucket for replay.
+const recentEventsPerBucket = 100
+
+// recentEvent - event along with its type and object name, to be
+// matched against listener configs on replay.
+type recentEvent struct {
+	eventType  string
+	objectName string
+	event      NotificationEvent
+}
+
+// eventRing - fixed size ring buffer of the recent events of a bucket.
+type eventRing struct {
+	events []recentEvent
+	next   int
+	full   bool
+}
+
+// recentEvents - recent events of all the buckets, as notified on
+// this server.
+type recentEvents struct {
+	mutex *sync.Mutex
+	size  int
+	rings map[string]*eventRing
+}
+
+// Variable holding the recent events of all the buckets.
+var globalRecentEvents = newRecentEvents(recentEventsPerBucket)
+
+// newRecentEvents - returns recent events keeping size events per bucket.
+func newRecentEvents(size int) *recentEvents {
+	return &recentEvents{
+		mutex: &sync.Mutex{},
+		size:  size,
+		rings: make(map[string]*eventRing),
+	}
+}
+
+// Add an event of a bucket, replacing its oldest event once full.
+func (r *recentEvents) Add(bucket, eventType, objectName string, event NotificationEvent) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	ring, ok := r.rings[bucket]
+	if !ok {
+		ring = &eventRing{events: make([]recentEvent, r.size)}
+		r.rings[bucket] = ring
+	}
+	ring.events[ring.next] = recentEvent{eventType, objectName, event}
+	ring.next = (ring.next + 1) % r.size
+	if ring.next == 0 {
+		ring.full = true
+	}
+}
+
+// Get the recent events of a bucket matching the listener config,
+// oldest first, at most limit events.
+func (r *recentEvents) Get(bucket string, lcfg listenerConfig, limit int) []NotificationEvent {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	ring, ok := r.rings[bucket]
+	if !ok {
+		return nil
+	}
+	events := ring.events[:ring.next]
+	if ring.full {
+		events = append(append([]recentEvent{}, ring.events[ring.next:]...), events...)
+	}
+	var matched []NotificationEvent
+	for _, event := range events {
+		if eventMatch(event.eventType, lcfg.TopicConfig.Events) &&
+			filterRuleMatch(event.objectName, lcfg.TopicConfig.Filter.Key.FilterRules) {
+			matched = append(matched, event.event)
+		}
+	}
+	if len(matched) > limit {
+		matched = matched[len(matched)-limit:]
+	}
+	return matched
+}
+
+// Remove the recent events of a deleted bucket.
+func (r *recentEvents) Remove(bucket string) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	delete(r.rings, bucket)
+}
@@ -0,0 +1,81 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"reflect"
+	"strconv"
+	"testing"
+)
+
+// Tests recent events are replayed oldest first, filtered and limited.
+func TestRecentEvents(t *testing.T) {
+	rootPath, err := newTestConfig("us-east-1")
+	if err != nil {
+		t.Fatalf("Init Test config failed")
+	}
+	defer removeAll(rootPath)
+
+	recent := newRecentEvents(3)
+	for i := 1; i <= 5; i++ {
+		eventType := ObjectCreatedPut.String()
+		if i == 4 {
+			eventType = ObjectRemovedDelete.String()
+		}
+		object := "photos/" + strconv.Itoa(i) + ".jpg"
+		recent.Add("bucket", eventType, object, NotificationEvent{EventName: eventType, S3: eventMeta{Object: objectMeta{Key: object}}})
+	}
+
+	getKeys := func(events []NotificationEvent) []string {
+		keys := []string{}
+		for _, event := range events {
+			keys = append(keys, event.S3.Object.Key)
+		}
+		return keys
+	}
+	testCases := []struct {
+		bucket   string
+		lcfg     listenerConfig
+		limit    int
+		expected []string
+	}{
+		// Only the last 3 events are kept.
+		{"bucket", newListenerConfig(nil, nil, []string{"s3:ObjectCreated:*", "s3:ObjectRemoved:*"}), 10,
+			[]string{"photos/3.jpg", "photos/4.jpg", "photos/5.jpg"}},
+		{"bucket", newListenerConfig(nil, nil, []string{"s3:ObjectCreated:*"}), 10,
+			[]string{"photos/3.jpg", "photos/5.jpg"}},
+		{"bucket", newListenerConfig(nil, []string{"4.jpg"}, []string{"s3:ObjectRemoved:*"}), 10,
+			[]string{"photos/4.jpg"}},
+		{"bucket", newListenerConfig([]string{"videos/"}, nil, []string{"s3:ObjectCreated:*"}), 10,
+			[]string{}},
+		{"bucket", newListenerConfig(nil, nil, []string{"s3:ObjectCreated:*", "s3:ObjectRemoved:*"}), 1,
+			[]string{"photos/5.jpg"}},
+		{"other-bucket", newListenerConfig(nil, nil, []string{"s3:ObjectCreated:*"}), 10,
+			[]string{}},
+	}
+	for i, testCase := range testCases {
+		keys := getKeys(recent.Get(testCase.bucket, testCase.lcfg, testCase.limit))
+		if !reflect.DeepEqual(keys, testCase.expected) {
+			t.Errorf("Test %d: Expected %v, got %v", i+1, testCase.expected, keys)
+		}
+	}
+
+	recent.Remove("bucket")
+	if events := recent.Get("bucket", testCases[0].lcfg, 10); len(events) != 0 {
+		t.Errorf("Expected no events once removed, got %v", events)
+	}
+}
@@ -0,0 +1,79 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+// EventStatsArgs - argument for EventStats RPC handler.
+type EventStatsArgs struct {
+	// Authentication token generated by Login.
+	GenericArgs
+
+	// Bucket to return the event statistics of.
+	Bucket string
+}
+
+// EventStatsReply - reply by EventStats RPC handler.
+type EventStatsReply struct {
+	// Event statistics keyed by target ARN.
+	Stats map[string]eventTargetStats
+
+	// Errors of the nodes whose statistics are missing from Stats,
+	// keyed by node.
+	Errors map[string]string
+}
+
+// EventStatsHandler - RPC control handler for `minio control
+// event-stats`, returns the counts of events generated, delivered,
+// failed and pending per target ARN of a bucket, summed up over all
+// the reachable nodes if args.Remote is set.
+func (c *controlAPIHandlers) EventStatsHandler(args *EventStatsArgs, reply *EventStatsReply) (err error) {
+	defer encodeRPCError(&err)
+
+	if !isRPCTokenValid(args.Token, jwtAudienceAdmin) {
+		return errInvalidToken
+	}
+	objAPI := c.ObjectAPI()
+	if objAPI == nil {
+		return errServerNotInitialized
+	}
+	if _, err = objAPI.GetBucketInfo(args.Bucket); err != nil {
+		return err
+	}
+
+	stats := globalEventStats.Get(args.Bucket)
+	if args.Remote {
+		// Sum up the event statistics of all the remote peers.
+		remoteControls := c.getRemoteControls()
+		replies := make([]EventStatsReply, len(remoteControls))
+		remoteArgs := *args
+		remoteArgs.Remote = false
+		errsMap := callRemoteControls(remoteControls, "Control.EventStatsHandler", &remoteArgs, func(index int) interface{} {
+			return &replies[index]
+		})
+		for index, client := range remoteControls {
+			if nodeErr, ok := errsMap[client.Node()]; ok {
+				if reply.Errors == nil {
+					reply.Errors = make(map[string]string)
+				}
+				reply.Errors[client.Node()] = nodeErr.Error()
+				continue
+			}
+			mergeEventStats(stats, replies[index].Stats)
+		}
+	}
+	reply.Stats = stats
+	return nil
+}
@@ -0,0 +1,240 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/Sirupsen/logrus"
+)
+
+// eventTargetStats - counts of the events of a bucket sent to a
+// single target ARN.
+type eventTargetStats struct {
+	// Events matched by the bucket configs for the target.
+	Generated int64 `json:"generated"`
+
+	// Events delivered successfully to the target.
+	Delivered int64 `json:"delivered"`
+
+	// Events the target failed to deliver.
+	Failed int64 `json:"failed"`
+
+	// Events being delivered at the moment.
+	Pending int64 `json:"pending"`
+}
+
+// eventTargetHealth - connectivity of a target ARN, as seen by the
+// deliveries of this node.
+type eventTargetHealth struct {
+	// Set unless the last delivery to the target failed.
+	Connected bool `json:"connected"`
+
+	// Error of the last delivery, if it failed.
+	LastError string `json:"lastError,omitempty"`
+
+	// Events of all the buckets being delivered to the target.
+	QueueDepth int64 `json:"queueDepth"`
+}
+
+// eventStats - in-memory event statistics of this node, per bucket
+// and per target ARN.
+type eventStats struct {
+	mutex   *sync.Mutex
+	buckets map[string]map[string]*eventTargetStats
+	// Error of the last delivery per target ARN, removed once a
+	// delivery succeeds.
+	lastErrors map[string]string
+}
+
+// Variable holding the event statistics of all the buckets.
+var globalEventStats = newEventStats()
+
+// newEventStats - returns empty event statistics.
+func newEventStats() *eventStats {
+	return &eventStats{
+		mutex:      &sync.Mutex{},
+		buckets:    make(map[string]map[string]*eventTargetStats),
+		lastErrors: make(map[string]string),
+	}
+}
+
+// Returns the stats of bucket and arn, lock must be held.
+func (s *eventStats) target(bucket, arn string) *eventTargetStats {
+	targets, ok := s.buckets[bucket]
+	if !ok {
+		targets = make(map[string]*eventTargetStats)
+		s.buckets[bucket] = targets
+	}
+	stats, ok := targets[arn]
+	if !ok {
+		stats = &eventTargetStats{}
+		targets[arn] = stats
+	}
+	return stats
+}
+
+// Generated records an event of a bucket about to be sent to arn.
+func (s *eventStats) Generated(bucket, arn string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	stats := s.target(bucket, arn)
+	stats.Generated++
+	stats.Pending++
+}
+
+// Sent records the delivery result of an event of a bucket to arn.
+func (s *eventStats) Sent(bucket, arn string, err error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	stats := s.target(bucket, arn)
+	if err != nil {
+		stats.Failed++
+		s.lastErrors[arn] = err.Error()
+	} else {
+		stats.Delivered++
+		delete(s.lastErrors, arn)
+	}
+	if stats.Pending > 0 {
+		stats.Pending--
+	}
+}
+
+// Get a copy of the stats of a bucket keyed by target ARN.
+func (s *eventStats) Get(bucket string) map[string]eventTargetStats {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	stats := make(map[string]eventTargetStats)
+	for arn, targetStats := range s.buckets[bucket] {
+		stats[arn] = *targetStats
+	}
+	return stats
+}
+
+// Health returns the connectivity of the target ARNs, targets
+// without any deliveries yet are reported connected.
+func (s *eventStats) Health(arns []string) map[string]eventTargetHealth {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	health := make(map[string]eventTargetHealth)
+	for _, arn := range arns {
+		lastError, failed := s.lastErrors[arn]
+		targetHealth := eventTargetHealth{
+			Connected: !failed,
+			LastError: lastError,
+		}
+		for _, targets := range s.buckets {
+			if stats, ok := targets[arn]; ok {
+				targetHealth.QueueDepth += stats.Pending
+			}
+		}
+		health[arn] = targetHealth
+	}
+	return health
+}
+
+// Remove all the stats of a bucket.
+func (s *eventStats) Remove(bucket string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	delete(s.buckets, bucket)
+}
+
+// Adds up the stats of other into stats, used to sum up the stats of
+// all the nodes.
+func mergeEventStats(stats, other map[string]eventTargetStats) {
+	for arn, o := range other {
+		s := stats[arn]
+		s.Generated += o.Generated
+		s.Delivered += o.Delivered
+		s.Failed += o.Failed
+		s.Pending += o.Pending
+		stats[arn] = s
+	}
+}
+
+// getEventTargetsHealth - returns the connectivity of all the
+// configured notification targets of this node.
+func getEventTargetsHealth() map[string]eventTargetHealth {
+	var arns []string
+	// In case initEventNotifier() was not done or failed.
+	if globalEventNotifier != nil {
+		globalEventNotifier.external.rwMutex.RLock()
+		for arn := range globalEventNotifier.external.targets {
+			arns = append(arns, arn)
+		}
+		globalEventNotifier.external.rwMutex.RUnlock()
+	}
+	return globalEventStats.Health(arns)
+}
+
+// formatEventTargetsHealth - describes the connectivity of the
+// notification targets for ServerInfo.
+func formatEventTargetsHealth(health map[string]eventTargetHealth) string {
+	if len(health) == 0 {
+		return "Targets: none"
+	}
+	var arns []string
+	for arn := range health {
+		arns = append(arns, arn)
+	}
+	sort.Strings(arns)
+	var targets []string
+	for _, arn := range arns {
+		targetHealth := health[arn]
+		state := "connected"
+		if !targetHealth.Connected {
+			state = "disconnected (" + targetHealth.LastError + ")"
+		}
+		targets = append(targets, fmt.Sprintf("%s: %s, queue %d", arn, state, targetHealth.QueueDepth))
+	}
+	return strings.Join(targets, " | ")
+}
+
+// eventStatsHook - wraps the logrus hook of a target to record the
+// result of every delivery.
+type eventStatsHook struct {
+	logrus.Hook
+	arn string
+}
+
+// Fire - delivers the event through the target hook and records the
+// result against the bucket of the event.
+func (h eventStatsHook) Fire(entry *logrus.Entry) error {
+	err := h.Hook.Fire(entry)
+	if key, ok := entry.Data["Key"].(string); ok {
+		bucket := strings.SplitN(key, slashSeparator, 2)[0]
+		globalEventStats.Sent(bucket, h.arn, err)
+	}
+	return err
+}
+
+// addEventStatsHooks - wraps all the hooks of the target logger of arn
+// to record event statistics.
+func addEventStatsHooks(arn string, log *logrus.Logger) {
+	for level, hooks := range log.Hooks {
+		for i, hook := range hooks {
+			if _, ok := hook.(eventStatsHook); ok {
+				continue
+			}
+			log.Hooks[level][i] = eventStatsHook{hook, arn}
+		}
+	}
+}
@@ -0,0 +1,128 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/Sirupsen/logrus"
+)
+
+// testEventHook - target hook failing every delivery with err.
+type testEventHook struct {
+	err error
+}
+
+func (h testEventHook) Fire(entry *logrus.Entry) error {
+	return h.err
+}
+
+func (h testEventHook) Levels() []logrus.Level {
+	return []logrus.Level{logrus.InfoLevel}
+}
+
+// Tests recording event statistics through target hooks.
+func TestEventStatsHook(t *testing.T) {
+	globalEventStats = newEventStats()
+	defer func() {
+		globalEventStats = newEventStats()
+	}()
+
+	okARN := "arn:minio:sqs:us-east-1:1:redis"
+	failARN := "arn:minio:sqs:us-east-1:1:amqp"
+	okLog := logrus.New()
+	okLog.Hooks.Add(testEventHook{})
+	addEventStatsHooks(okARN, okLog)
+	// Hooks are wrapped only once.
+	addEventStatsHooks(okARN, okLog)
+	failLog := logrus.New()
+	failLog.Hooks.Add(testEventHook{errors.New("unreachable")})
+	addEventStatsHooks(failARN, failLog)
+
+	send := func(log *logrus.Logger, arn, bucket string) {
+		globalEventStats.Generated(bucket, arn)
+		log.WithFields(logrus.Fields{"Key": bucket + "/object"}).Info()
+	}
+	send(okLog, okARN, "bucket")
+	send(okLog, okARN, "bucket")
+	send(failLog, failARN, "bucket")
+	send(okLog, okARN, "other")
+	// Event about to be sent.
+	globalEventStats.Generated("bucket", failARN)
+
+	stats := globalEventStats.Get("bucket")
+	expected := map[string]eventTargetStats{
+		okARN:   {Generated: 2, Delivered: 2},
+		failARN: {Generated: 2, Failed: 1, Pending: 1},
+	}
+	if len(stats) != len(expected) {
+		t.Fatalf("Expected %v, got %v", expected, stats)
+	}
+	for arn, want := range expected {
+		if stats[arn] != want {
+			t.Errorf("%s: expected %v, got %v", arn, want, stats[arn])
+		}
+	}
+
+	// Stats of all the nodes are summed up.
+	mergeEventStats(stats, globalEventStats.Get("other"))
+	if stats[okARN].Generated != 3 || stats[okARN].Delivered != 3 {
+		t.Errorf("Expected merged stats, got %v", stats[okARN])
+	}
+
+	globalEventStats.Remove("bucket")
+	if stats = globalEventStats.Get("bucket"); len(stats) != 0 {
+		t.Errorf("Expected no stats after removal, got %v", stats)
+	}
+}
+
+// Tests the connectivity of targets follows their last delivery.
+func TestEventTargetsHealth(t *testing.T) {
+	stats := newEventStats()
+	arn := "arn:minio:sqs:us-east-1:1:redis"
+	idleARN := "arn:minio:sqs:us-east-1:1:amqp"
+
+	stats.Generated("bucket1", arn)
+	stats.Generated("bucket2", arn)
+	stats.Generated("bucket2", arn)
+	stats.Sent("bucket2", arn, errors.New("connection refused"))
+
+	health := stats.Health([]string{arn, idleARN})
+	expected := eventTargetHealth{Connected: false, LastError: "connection refused", QueueDepth: 2}
+	if health[arn] != expected {
+		t.Fatalf("Expected %#v, got %#v", expected, health[arn])
+	}
+	if health[idleARN] != (eventTargetHealth{Connected: true}) {
+		t.Fatalf("Expected idle target to be connected, got %#v", health[idleARN])
+	}
+
+	stats.Sent("bucket1", arn, nil)
+	expected = eventTargetHealth{Connected: true, QueueDepth: 1}
+	if health = stats.Health([]string{arn}); health[arn] != expected {
+		t.Fatalf("Expected %#v, got %#v", expected, health[arn])
+	}
+
+	msg := formatEventTargetsHealth(map[string]eventTargetHealth{
+		arn:     {Connected: false, LastError: "connection refused", QueueDepth: 3},
+		idleARN: {Connected: true},
+	})
+	expectedMsg := idleARN + ": connected, queue 0 | " + arn + ": disconnected (connection refused), queue 3"
+	if msg != expectedMsg {
+		t.Fatalf("Expected %s, got %s", expectedMsg, msg)
+	}
+}
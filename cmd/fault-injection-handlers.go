@@ -0,0 +1,64 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import "errors"
+
+// errFaultInjectionDisabled - fault injection was requested on a server
+// started without MINIO_FAULT_INJECTION=on.
+var errFaultInjectionDisabled = errors.New("Fault injection is disabled, set MINIO_FAULT_INJECTION=on to enable.")
+
+// FaultInjectionArgs - argument for FaultInjection RPC handler.
+type FaultInjectionArgs struct {
+	// Authentication token generated by Login.
+	GenericArgs
+
+	// Faults to inject, replaces any previously injected faults.
+	Faults faultConfig
+}
+
+// FaultInjectionReply - reply by FaultInjection RPC handler.
+type FaultInjectionReply struct {
+	// Faults injected on the node after the call.
+	Faults faultConfig
+}
+
+// FaultInjectionHandler - RPC control handler for `minio control fault`,
+// replaces the faults injected on this node. Faults are deliberately
+// not propagated to remote nodes, so that individual nodes can be made
+// to misbehave.
+func (c *controlAPIHandlers) FaultInjectionHandler(args *FaultInjectionArgs, reply *FaultInjectionReply) (err error) {
+	defer encodeRPCError(&err)
+
+	if !isRPCTokenValid(args.Token, jwtAudienceAdmin) {
+		return errInvalidToken
+	}
+	// Retries get the result of the first call.
+	if replayed, replayErr := globalIdempotentCalls.Begin("Control.FaultInjectionHandler", args.IdempotencyKey, reply); replayed {
+		return replayErr
+	}
+	defer globalIdempotentCalls.End("Control.FaultInjectionHandler", args.IdempotencyKey, reply, &err)
+
+	if !globalFaultInjection {
+		return errFaultInjectionDisabled
+	}
+	if err := globalFaultInjector.setConfig(args.Faults); err != nil {
+		return err
+	}
+	reply.Faults = globalFaultInjector.getConfig()
+	return nil
+}
@@ -0,0 +1,217 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/mf-00/newgo/pkg/disk"
+)
+
+// Maximum delay that can be injected into an RPC call.
+const maxFaultRPCDelay = 1 * time.Minute
+
+// faultConfig - faults injected on a node, rates are in percent.
+type faultConfig struct {
+	// Percentage of disk writes failed with errFaultyDisk.
+	DiskWriteDrop int
+	// Percentage of disk reads returned with corrupted data.
+	DiskReadCorrupt int
+	// Delay added to every outgoing RPC call.
+	RPCDelay time.Duration
+}
+
+// Validates fault rates and delays.
+func (f faultConfig) validate() error {
+	if f.DiskWriteDrop < 0 || f.DiskWriteDrop > 100 {
+		return errInvalidArgument
+	}
+	if f.DiskReadCorrupt < 0 || f.DiskReadCorrupt > 100 {
+		return errInvalidArgument
+	}
+	if f.RPCDelay < 0 || f.RPCDelay > maxFaultRPCDelay {
+		return errInvalidArgument
+	}
+	return nil
+}
+
+// faultInjector - holds the faults currently injected on this node,
+// only consulted when MINIO_FAULT_INJECTION=on.
+type faultInjector struct {
+	mu     sync.Mutex
+	config faultConfig
+	rnd    *rand.Rand
+}
+
+func newFaultInjector() *faultInjector {
+	return &faultInjector{
+		rnd: rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+}
+
+// Faults injected on this node.
+var globalFaultInjector = newFaultInjector()
+
+// setConfig - replaces the injected faults, a zero config clears them.
+func (f *faultInjector) setConfig(config faultConfig) error {
+	if err := config.validate(); err != nil {
+		return err
+	}
+	f.mu.Lock()
+	f.config = config
+	f.mu.Unlock()
+	return nil
+}
+
+// getConfig - returns the injected faults.
+func (f *faultInjector) getConfig() faultConfig {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.config
+}
+
+// Returns true for percent out of every 100 calls on average.
+func (f *faultInjector) chance(percent int) bool {
+	if percent <= 0 {
+		return false
+	}
+	return f.rnd.Intn(100) < percent
+}
+
+func (f *faultInjector) dropWrite() bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.chance(f.config.DiskWriteDrop)
+}
+
+func (f *faultInjector) corruptRead() bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.chance(f.config.DiskReadCorrupt)
+}
+
+// delayRPC - sleeps for the configured RPC delay.
+func (f *faultInjector) delayRPC() {
+	if !globalFaultInjection {
+		return
+	}
+	if delay := f.getConfig().RPCDelay; delay > 0 {
+		time.Sleep(delay)
+	}
+}
+
+// faultyDisk wraps a StorageAPI and injects the faults configured
+// in globalFaultInjector, used to exercise resilience of XL and
+// distributed setups in staging.
+type faultyDisk struct {
+	disk StorageAPI
+}
+
+func newFaultyDisk(disk StorageAPI) StorageAPI {
+	return &faultyDisk{disk: disk}
+}
+
+// Flips every byte in buf, so that bitrot verification fails.
+func corruptBuf(buf []byte) {
+	for i := range buf {
+		buf[i] = ^buf[i]
+	}
+}
+
+func (d *faultyDisk) String() string {
+	return d.disk.String()
+}
+
+func (d *faultyDisk) DiskInfo() (info disk.Info, err error) {
+	return d.disk.DiskInfo()
+}
+
+func (d *faultyDisk) MakeVol(volume string) (err error) {
+	if globalFaultInjector.dropWrite() {
+		return errFaultyDisk
+	}
+	return d.disk.MakeVol(volume)
+}
+
+func (d *faultyDisk) ListVols() (vols []VolInfo, err error) {
+	return d.disk.ListVols()
+}
+
+func (d *faultyDisk) StatVol(volume string) (vol VolInfo, err error) {
+	return d.disk.StatVol(volume)
+}
+
+func (d *faultyDisk) DeleteVol(volume string) (err error) {
+	if globalFaultInjector.dropWrite() {
+		return errFaultyDisk
+	}
+	return d.disk.DeleteVol(volume)
+}
+
+func (d *faultyDisk) RenameVol(srcVolume, dstVolume string) (err error) {
+	if globalFaultInjector.dropWrite() {
+		return errFaultyDisk
+	}
+	return d.disk.RenameVol(srcVolume, dstVolume)
+}
+
+func (d *faultyDisk) ListDir(volume, path string) (entries []string, err error) {
+	return d.disk.ListDir(volume, path)
+}
+
+func (d *faultyDisk) ReadFile(volume string, path string, offset int64, buf []byte) (n int64, err error) {
+	n, err = d.disk.ReadFile(volume, path, offset, buf)
+	if err == nil && globalFaultInjector.corruptRead() {
+		corruptBuf(buf[:n])
+	}
+	return n, err
+}
+
+func (d *faultyDisk) AppendFile(volume, path string, buf []byte) error {
+	if globalFaultInjector.dropWrite() {
+		return errFaultyDisk
+	}
+	return d.disk.AppendFile(volume, path, buf)
+}
+
+func (d *faultyDisk) RenameFile(srcVolume, srcPath, dstVolume, dstPath string) error {
+	if globalFaultInjector.dropWrite() {
+		return errFaultyDisk
+	}
+	return d.disk.RenameFile(srcVolume, srcPath, dstVolume, dstPath)
+}
+
+func (d *faultyDisk) StatFile(volume, path string) (file FileInfo, err error) {
+	return d.disk.StatFile(volume, path)
+}
+
+func (d *faultyDisk) DeleteFile(volume string, path string) (err error) {
+	if globalFaultInjector.dropWrite() {
+		return errFaultyDisk
+	}
+	return d.disk.DeleteFile(volume, path)
+}
+
+func (d *faultyDisk) ReadAll(volume string, path string) (buf []byte, err error) {
+	buf, err = d.disk.ReadAll(volume, path)
+	if err == nil && globalFaultInjector.corruptRead() {
+		corruptBuf(buf)
+	}
+	return buf, err
+}
@@ -0,0 +1,98 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"bytes"
+	"testing"
+)
+
+// Tests that faultyDisk fails writes and corrupts reads as configured.
+func TestFaultyDisk(t *testing.T) {
+	posixDisk, diskPath, err := newPosixTestSetup()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer removeAll(diskPath)
+
+	disk := newFaultyDisk(posixDisk)
+	defer globalFaultInjector.setConfig(faultConfig{})
+
+	// No faults injected.
+	if err = disk.MakeVol("bucket"); err != nil {
+		t.Fatal(err)
+	}
+	data := []byte("hello, world")
+	if err = disk.AppendFile("bucket", "object", data); err != nil {
+		t.Fatal(err)
+	}
+
+	// Fail all writes.
+	if err = globalFaultInjector.setConfig(faultConfig{DiskWriteDrop: 100}); err != nil {
+		t.Fatal(err)
+	}
+	if err = disk.AppendFile("bucket", "object", data); err != errFaultyDisk {
+		t.Errorf("Expected %s, got %v", errFaultyDisk, err)
+	}
+	if err = disk.DeleteFile("bucket", "object"); err != errFaultyDisk {
+		t.Errorf("Expected %s, got %v", errFaultyDisk, err)
+	}
+	buf, err := disk.ReadAll("bucket", "object")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(buf, data) {
+		t.Errorf("Expected reads to be unaffected, got %q", buf)
+	}
+
+	// Corrupt all reads.
+	if err = globalFaultInjector.setConfig(faultConfig{DiskReadCorrupt: 100}); err != nil {
+		t.Fatal(err)
+	}
+	if buf, err = disk.ReadAll("bucket", "object"); err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Equal(buf, data) {
+		t.Error("Expected ReadAll to return corrupted data")
+	}
+	buf = make([]byte, len(data))
+	if _, err = disk.ReadFile("bucket", "object", 0, buf); err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Equal(buf, data) {
+		t.Error("Expected ReadFile to return corrupted data")
+	}
+}
+
+// Tests validation of injected faults.
+func TestFaultConfigValidate(t *testing.T) {
+	testCases := []struct {
+		config faultConfig
+		err    error
+	}{
+		{faultConfig{}, nil},
+		{faultConfig{DiskWriteDrop: 100, DiskReadCorrupt: 50, RPCDelay: maxFaultRPCDelay}, nil},
+		{faultConfig{DiskWriteDrop: -1}, errInvalidArgument},
+		{faultConfig{DiskReadCorrupt: 101}, errInvalidArgument},
+		{faultConfig{RPCDelay: maxFaultRPCDelay + 1}, errInvalidArgument},
+	}
+	for i, testCase := range testCases {
+		if err := testCase.config.validate(); err != testCase.err {
+			t.Errorf("Test %d: expected %v, got %v", i+1, testCase.err, err)
+		}
+	}
+}
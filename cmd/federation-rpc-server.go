@@ -0,0 +1,136 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package cmd
+
+import (
+	"net/rpc"
+	"time"
+
+	router "github.com/gorilla/mux"
+)
+
+// federationHandlers - RPC handlers of the federation coordinator.
+type federationHandlers struct {
+	ObjectAPI func() ObjectLayer
+}
+
+// registerFederationRPCRouter - registers the federation RPC service,
+// used by the other clusters when this cluster is the coordinator.
+func registerFederationRPCRouter(mux *router.Router) error {
+	federationRPCServer := rpc.NewServer()
+	err := federationRPCServer.RegisterName("Federation", &federationHandlers{
+		ObjectAPI: newObjectLayerFn,
+	})
+	if err != nil {
+		return traceError(err)
+	}
+
+	federationRouter := mux.NewRoute().PathPrefix(reservedBucket).Subrouter()
+	federationRouter.Path(federationPath).Handler(newRPCHandler(federationRPCServer))
+	return nil
+}
+
+// LoginHandler - login handler for the other clusters.
+func (f *federationHandlers) LoginHandler(args *RPCLoginArgs, reply *RPCLoginReply) (err error) {
+	defer encodeRPCError(&err)
+
+	jwt, err := newJWT(defaultInterNodeJWTExpiry)
+	if err != nil {
+		return err
+	}
+	if err = jwt.Authenticate(args.Username, args.Password); err != nil {
+		return err
+	}
+	token, err := jwt.GenerateToken(args.Username, jwtAudienceInterNode)
+	if err != nil {
+		return err
+	}
+	reply.Token = token
+	reply.ServerVersion = Version
+	reply.Timestamp = time.Now().UTC()
+	return nil
+}
+
+// FederationBucketArgs - bucket and the endpoint of the cluster
+// claiming or releasing it.
+type FederationBucketArgs struct {
+	// For Auth
+	GenericArgs
+
+	Bucket string
+	Owner  string
+}
+
+// FederationBucketReply - owner of a bucket looked up, and whether a
+// claim recorded a new owner.
+type FederationBucketReply struct {
+	Owner   string
+	Claimed bool
+}
+
+// ClaimBucketHandler - records the owner of a bucket.
+func (f *federationHandlers) ClaimBucketHandler(args *FederationBucketArgs, reply *FederationBucketReply) (err error) {
+	defer encodeRPCError(&err)
+
+	if !isRPCTokenValid(args.Token, jwtAudienceInterNode) {
+		return errInvalidToken
+	}
+	objAPI := f.ObjectAPI()
+	if objAPI == nil {
+		return errServerNotInitialized
+	}
+	if !IsValidBucketName(args.Bucket) || args.Owner == "" {
+		return errInvalidArgument
+	}
+	reply.Claimed, err = claimBucket(objAPI, args.Bucket, args.Owner)
+	if err == nil {
+		reply.Owner = args.Owner
+	}
+	return err
+}
+
+// ReleaseBucketHandler - forgets the owner of a bucket.
+func (f *federationHandlers) ReleaseBucketHandler(args *FederationBucketArgs, reply *FederationBucketReply) (err error) {
+	defer encodeRPCError(&err)
+
+	if !isRPCTokenValid(args.Token, jwtAudienceInterNode) {
+		return errInvalidToken
+	}
+	objAPI := f.ObjectAPI()
+	if objAPI == nil {
+		return errServerNotInitialized
+	}
+	if !IsValidBucketName(args.Bucket) || args.Owner == "" {
+		return errInvalidArgument
+	}
+	return releaseBucket(objAPI, args.Bucket, args.Owner)
+}
+
+// LookupBucketHandler - returns the owner of a bucket, empty if it is
+// not claimed.
+func (f *federationHandlers) LookupBucketHandler(args *FederationBucketArgs, reply *FederationBucketReply) (err error) {
+	defer encodeRPCError(&err)
+
+	if !isRPCTokenValid(args.Token, jwtAudienceInterNode) {
+		return errInvalidToken
+	}
+	objAPI := f.ObjectAPI()
+	if objAPI == nil {
+		return errServerNotInitialized
+	}
+	reply.Owner, err = lookupBucket(objAPI, args.Bucket)
+	return err
+}
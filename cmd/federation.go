@@ -0,0 +1,567 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"path"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Federation lets independent clusters share one bucket namespace. The
+// coordinator cluster records which cluster owns each bucket, the other
+// clusters claim their buckets with it and redirect or proxy requests
+// for buckets owned by another cluster.
+
+const (
+	// Federation RPC path on the coordinator.
+	federationPath = "/federation"
+
+	// Owners of all the buckets are stored as 'federation.json' in
+	// '.minio.sys/' of the coordinator.
+	federationConfigFile = "federation.json"
+
+	// Lock serializing updates of the owners, the object layer
+	// already locks 'federation.json' itself.
+	federationLockPath = "federation.lock"
+
+	// Time for which bucket owners looked up are cached, unclaimed
+	// buckets included.
+	federationCacheExpiry = 30 * time.Second
+
+	// Maximum number of bucket owners cached.
+	federationCacheMaxEntries = 10000
+
+	// Header set on proxied requests, they are always served locally.
+	federationProxyHeader = "X-Minio-Federated"
+)
+
+// Modes of serving requests for buckets owned by another cluster.
+const (
+	federationRedirect = "redirect"
+	federationProxy    = "proxy"
+)
+
+// errFederationBucketOwned - bucket is claimed by another cluster.
+var errFederationBucketOwned = errors.New("Bucket is owned by another cluster")
+
+// parseFederationURL - parses the URL of a cluster, only its scheme
+// and host are kept.
+func parseFederationURL(urlStr string) (*url.URL, error) {
+	u, err := url.Parse(urlStr)
+	if err != nil {
+		return nil, err
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, errInvalidArgument
+	}
+	if u.Host == "" || (u.Path != "" && u.Path != slashSeparator) || u.RawQuery != "" {
+		return nil, errInvalidArgument
+	}
+	return &url.URL{Scheme: u.Scheme, Host: u.Host}, nil
+}
+
+// parseFederationMode - parses how requests for buckets owned by
+// another cluster are served.
+func parseFederationMode(mode string) (string, error) {
+	mode = strings.ToLower(mode)
+	if mode != federationRedirect && mode != federationProxy {
+		return "", errInvalidArgument
+	}
+	return mode, nil
+}
+
+// federationBuckets - owners of all the federated buckets.
+type federationBuckets struct {
+	Version string `json:"version"`
+	// Endpoint of the owning cluster by bucket name.
+	Buckets map[string]string `json:"buckets"`
+}
+
+// readFederationBuckets - reads the bucket owners, empty if none were
+// claimed yet.
+func readFederationBuckets(objAPI ObjectLayer) (*federationBuckets, error) {
+	buckets := &federationBuckets{Version: "1", Buckets: make(map[string]string)}
+	objInfo, err := objAPI.GetObjectInfo(minioMetaBucket, federationConfigFile)
+	if err != nil {
+		if _, ok := errorCause(err).(ObjectNotFound); ok {
+			return buckets, nil
+		}
+		return nil, errorCause(err)
+	}
+	var buffer bytes.Buffer
+	if err = objAPI.GetObject(minioMetaBucket, federationConfigFile, 0, objInfo.Size, &buffer); err != nil {
+		return nil, errorCause(err)
+	}
+	if err = json.Unmarshal(buffer.Bytes(), buckets); err != nil {
+		return nil, err
+	}
+	if buckets.Buckets == nil {
+		buckets.Buckets = make(map[string]string)
+	}
+	return buckets, nil
+}
+
+// writeFederationBuckets - saves the bucket owners.
+func writeFederationBuckets(objAPI ObjectLayer, buckets *federationBuckets) error {
+	buf, err := json.Marshal(buckets)
+	if err != nil {
+		return err
+	}
+	_, err = objAPI.PutObject(minioMetaBucket, federationConfigFile, int64(len(buf)), bytes.NewReader(buf), nil, "")
+	return errorCause(err)
+}
+
+// claimBucket - records owner as the owner of bucket on the
+// coordinator, returns false if owner already owned it.
+func claimBucket(objAPI ObjectLayer, bucket, owner string) (bool, error) {
+	opsID := getOpsID()
+	nsMutex.Lock(minioMetaBucket, federationLockPath, opsID)
+	defer nsMutex.Unlock(minioMetaBucket, federationLockPath, opsID)
+
+	buckets, err := readFederationBuckets(objAPI)
+	if err != nil {
+		return false, err
+	}
+	if current, ok := buckets.Buckets[bucket]; ok {
+		if current != owner {
+			return false, errFederationBucketOwned
+		}
+		return false, nil
+	}
+	buckets.Buckets[bucket] = owner
+	if err = writeFederationBuckets(objAPI, buckets); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// releaseBucket - forgets the owner of bucket on the coordinator,
+// buckets owned by another cluster are left untouched.
+func releaseBucket(objAPI ObjectLayer, bucket, owner string) error {
+	opsID := getOpsID()
+	nsMutex.Lock(minioMetaBucket, federationLockPath, opsID)
+	defer nsMutex.Unlock(minioMetaBucket, federationLockPath, opsID)
+
+	buckets, err := readFederationBuckets(objAPI)
+	if err != nil {
+		return err
+	}
+	if buckets.Buckets[bucket] != owner {
+		return nil
+	}
+	delete(buckets.Buckets, bucket)
+	return writeFederationBuckets(objAPI, buckets)
+}
+
+// lookupBucket - returns the endpoint of the cluster owning bucket on
+// the coordinator, empty if it is not claimed.
+func lookupBucket(objAPI ObjectLayer, bucket string) (string, error) {
+	opsID := getOpsID()
+	nsMutex.RLock(minioMetaBucket, federationLockPath, opsID)
+	defer nsMutex.RUnlock(minioMetaBucket, federationLockPath, opsID)
+
+	buckets, err := readFederationBuckets(objAPI)
+	if err != nil {
+		return "", err
+	}
+	return buckets.Buckets[bucket], nil
+}
+
+// Owners of buckets are stored as 'federation/<bucket>' keys when the
+// federation is coordinated through etcd.
+const etcdFederationPrefix = "federation"
+
+// claimEtcdBucket - records owner as the owner of bucket in etcd,
+// returns false if owner already owned it.
+func claimEtcdBucket(etcd *etcdClient, bucket, owner string) (bool, error) {
+	key := path.Join(etcdFederationPrefix, bucket)
+	err := etcd.create(key, owner)
+	if err == nil {
+		return true, nil
+	}
+	if err != errEtcdKeyModified {
+		return false, err
+	}
+	current, err := etcd.get(key)
+	if err != nil && err != errEtcdKeyNotFound {
+		return false, err
+	}
+	if current != owner {
+		return false, errFederationBucketOwned
+	}
+	return false, nil
+}
+
+// releaseEtcdBucket - forgets the owner of bucket in etcd, buckets
+// owned by another cluster are left untouched.
+func releaseEtcdBucket(etcd *etcdClient, bucket, owner string) error {
+	err := etcd.compareAndDelete(path.Join(etcdFederationPrefix, bucket), owner)
+	if err == errEtcdKeyNotFound || err == errEtcdKeyModified {
+		return nil
+	}
+	return err
+}
+
+// lookupEtcdBucket - returns the endpoint of the cluster owning bucket
+// in etcd, empty if it is not claimed.
+func lookupEtcdBucket(etcd *etcdClient, bucket string) (string, error) {
+	owner, err := etcd.get(path.Join(etcdFederationPrefix, bucket))
+	if err == errEtcdKeyNotFound {
+		return "", nil
+	}
+	return owner, err
+}
+
+// federationCacheEntry - owner of a bucket and when it is looked up again.
+type federationCacheEntry struct {
+	owner  string
+	expiry time.Time
+}
+
+// federation - state of this cluster in the federation.
+type federation struct {
+	// Endpoint of this cluster, e.g "https://cluster1.example.com".
+	endpoint string
+
+	// Either federationRedirect or federationProxy.
+	mode string
+
+	// RPC client to the coordinator, nil on the coordinator itself.
+	coordinator *AuthRPCClient
+
+	// Client of etcd storing the owners instead of a coordinator.
+	etcd *etcdClient
+
+	mutex *sync.Mutex
+	cache map[string]federationCacheEntry
+}
+
+// Federation state, nil unless MINIO_FEDERATION_ENDPOINT is set.
+var globalFederation *federation
+
+// newFederation - returns the federation state of the cluster at
+// endpoint, coordinator is nil if this cluster is the coordinator.
+func newFederation(endpoint, coordinator *url.URL, mode string) *federation {
+	f := &federation{
+		endpoint: endpoint.String(),
+		mode:     mode,
+		mutex:    &sync.Mutex{},
+		cache:    make(map[string]federationCacheEntry),
+	}
+	if coordinator != nil {
+		f.coordinator = newAuthClient(&authConfig{
+			accessKey:   serverConfig.GetCredential().AccessKeyID,
+			secretKey:   serverConfig.GetCredential().SecretAccessKey,
+			secureConn:  coordinator.Scheme == "https",
+			address:     coordinator.Host,
+			path:        path.Join(reservedBucket, federationPath),
+			loginMethod: "Federation.LoginHandler",
+		})
+	}
+	return f
+}
+
+// newEtcdFederation - returns the federation state of the cluster at
+// endpoint, owners are stored in etcd.
+func newEtcdFederation(endpoint *url.URL, etcd *etcdClient, mode string) *federation {
+	f := newFederation(endpoint, nil, mode)
+	f.etcd = etcd
+	return f
+}
+
+// startEtcdWatch - forgets cached owners whenever they change in etcd,
+// until doneCh is closed.
+func (f *federation) startEtcdWatch(doneCh <-chan struct{}) {
+	f.etcd.watch(etcdFederationPrefix, doneCh, func(key string) {
+		f.mutex.Lock()
+		defer f.mutex.Unlock()
+		if key == "" {
+			f.cache = make(map[string]federationCacheEntry)
+			return
+		}
+		delete(f.cache, path.Base(key))
+	})
+}
+
+// setCache - caches the owner of bucket, empty if unclaimed. Once the
+// cache is full expired entries are dropped, then arbitrary ones.
+func (f *federation) setCache(bucket, owner string) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	now := time.Now().UTC()
+	if _, ok := f.cache[bucket]; !ok && len(f.cache) >= federationCacheMaxEntries {
+		for name, entry := range f.cache {
+			if !now.Before(entry.expiry) {
+				delete(f.cache, name)
+			}
+		}
+		for name := range f.cache {
+			if len(f.cache) < federationCacheMaxEntries {
+				break
+			}
+			delete(f.cache, name)
+		}
+	}
+	f.cache[bucket] = federationCacheEntry{owner: owner, expiry: now.Add(federationCacheExpiry)}
+}
+
+// cachedOwner - returns the cached owner of bucket, false if it is not
+// cached or expired.
+func (f *federation) cachedOwner(bucket string) (string, bool) {
+	f.mutex.Lock()
+	entry, ok := f.cache[bucket]
+	f.mutex.Unlock()
+	if !ok || !time.Now().UTC().Before(entry.expiry) {
+		return "", false
+	}
+	return entry.owner, true
+}
+
+// claim - claims bucket for this cluster, returns false if this
+// cluster already owned it.
+func (f *federation) claim(bucket string) (claimed bool, err error) {
+	if !IsValidBucketName(bucket) {
+		return false, BucketNameInvalid{Bucket: bucket}
+	}
+	switch {
+	case f.etcd != nil:
+		claimed, err = claimEtcdBucket(f.etcd, bucket, f.endpoint)
+	case f.coordinator == nil:
+		objAPI := newObjectLayerFn()
+		if objAPI == nil {
+			return false, errServerNotInitialized
+		}
+		claimed, err = claimBucket(objAPI, bucket, f.endpoint)
+	default:
+		args := FederationBucketArgs{Bucket: bucket, Owner: f.endpoint}
+		reply := FederationBucketReply{}
+		err = f.coordinator.Call("Federation.ClaimBucketHandler", &args, &reply)
+		claimed = reply.Claimed
+	}
+	if err == errFederationBucketOwned {
+		return false, BucketAlreadyExists{Bucket: bucket}
+	}
+	if err != nil {
+		return false, err
+	}
+	f.setCache(bucket, f.endpoint)
+	return claimed, nil
+}
+
+// release - releases bucket owned by this cluster.
+func (f *federation) release(bucket string) (err error) {
+	switch {
+	case f.etcd != nil:
+		err = releaseEtcdBucket(f.etcd, bucket, f.endpoint)
+	case f.coordinator == nil:
+		objAPI := newObjectLayerFn()
+		if objAPI == nil {
+			return errServerNotInitialized
+		}
+		err = releaseBucket(objAPI, bucket, f.endpoint)
+	default:
+		args := FederationBucketArgs{Bucket: bucket, Owner: f.endpoint}
+		err = f.coordinator.Call("Federation.ReleaseBucketHandler", &args, &FederationBucketReply{})
+	}
+	if err != nil {
+		return err
+	}
+	f.setCache(bucket, "")
+	return nil
+}
+
+// lookup - returns the endpoint of the cluster owning bucket, empty if
+// it is not claimed. Owners are cached for federationCacheExpiry, or
+// until they change in etcd. Invalid bucket names are never claimed,
+// they are not looked up.
+func (f *federation) lookup(bucket string) (owner string, err error) {
+	if owner, ok := f.cachedOwner(bucket); ok {
+		return owner, nil
+	}
+	if !IsValidBucketName(bucket) {
+		return "", nil
+	}
+	switch {
+	case f.etcd != nil:
+		owner, err = lookupEtcdBucket(f.etcd, bucket)
+	case f.coordinator == nil:
+		objAPI := newObjectLayerFn()
+		if objAPI == nil {
+			return "", errServerNotInitialized
+		}
+		owner, err = lookupBucket(objAPI, bucket)
+	default:
+		args := FederationBucketArgs{Bucket: bucket}
+		reply := FederationBucketReply{}
+		err = f.coordinator.Call("Federation.LookupBucketHandler", &args, &reply)
+		owner = reply.Owner
+	}
+	if err != nil {
+		return "", err
+	}
+	f.setCache(bucket, owner)
+	return owner, nil
+}
+
+// claimFederatedBucket - claims bucket before it is created, returns
+// true if it must be released should the creation fail.
+func claimFederatedBucket(bucket string) (bool, error) {
+	if globalFederation == nil {
+		return false, nil
+	}
+	return globalFederation.claim(bucket)
+}
+
+// releaseFederatedBucket - releases bucket once it is deleted.
+func releaseFederatedBucket(bucket string) {
+	if globalFederation == nil {
+		return
+	}
+	errorIf(globalFederation.release(bucket), "Unable to release federated bucket %s.", bucket)
+}
+
+// initFederation - claims all the buckets of this cluster, buckets
+// created before the cluster joined the federation included.
+func initFederation(objAPI ObjectLayer) error {
+	if globalFederation == nil {
+		return nil
+	}
+	buckets, err := objAPI.ListBuckets()
+	if err != nil {
+		return errorCause(err)
+	}
+	for _, bucket := range buckets {
+		_, err = globalFederation.claim(bucket.Name)
+		if _, ok := err.(BucketAlreadyExists); ok {
+			errorIf(err, "Bucket %s is owned by another cluster of the federation.", bucket.Name)
+			continue
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// federationHandler - serves requests for buckets owned by another
+// cluster of the federation by redirecting or proxying them to it.
+type federationHandler struct {
+	handler http.Handler
+}
+
+func setFederationHandler(h http.Handler) http.Handler {
+	return federationHandler{handler: h}
+}
+
+// getRequestBucket - returns the bucket of a path-style S3 request,
+// empty for requests to the service or reserved paths.
+func getRequestBucket(r *http.Request) string {
+	urlPath := path.Clean(r.URL.Path)
+	if urlPath == reservedBucket || strings.HasPrefix(urlPath, reservedBucket+slashSeparator) {
+		return ""
+	}
+	if urlPath == globalBrowserPrefix || strings.HasPrefix(urlPath, globalBrowserPrefix+slashSeparator) {
+		return ""
+	}
+	bucket := strings.SplitN(strings.TrimPrefix(urlPath, slashSeparator), slashSeparator, 2)[0]
+	if bucket == "." {
+		return ""
+	}
+	return bucket
+}
+
+// isFederationLookupAllowed - returns true if the owner of a bucket
+// may be looked up for r. Lookups are calls to the coordinator or
+// etcd, only requests signed with valid credentials are allowed to make
+// them. Only the headers are verified, the cluster serving the request
+// verifies the payload. Failures are recorded by that cluster too.
+func isFederationLookupAllowed(r *http.Request) bool {
+	sha256sum := r.Header.Get("X-Amz-Content-Sha256")
+	if skipContentSha256Cksum(r) {
+		sha256sum = unsignedPayload
+	}
+	switch getRequestAuthType(r) {
+	case authTypeSignedV2:
+		return doesSignV2Match(r) == ErrNone
+	case authTypePresignedV2:
+		return doesPresignV2SignatureMatch(r) == ErrNone
+	case authTypeSigned, authTypeStreamingSigned:
+		return doesSignatureMatch(sha256sum, r, serverConfig.GetRegion()) == ErrNone
+	case authTypePresigned:
+		return doesPresignedSignatureMatch(sha256sum, r, serverConfig.GetRegion()) == ErrNone
+	}
+	return false
+}
+
+func (h federationHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f := globalFederation
+	if f == nil || r.Header.Get(federationProxyHeader) != "" {
+		h.handler.ServeHTTP(w, r)
+		return
+	}
+	bucket := getRequestBucket(r)
+	if bucket == "" {
+		h.handler.ServeHTTP(w, r)
+		return
+	}
+	// Other requests only use owners already looked up, they may be
+	// anonymous requests for public buckets of another cluster.
+	if !isFederationLookupAllowed(r) {
+		owner, ok := f.cachedOwner(bucket)
+		if !ok || owner == "" || owner == f.endpoint {
+			h.handler.ServeHTTP(w, r)
+			return
+		}
+	}
+	owner, err := f.lookup(bucket)
+	if err != nil {
+		// Serve buckets of this cluster while the coordinator is unreachable.
+		errorIf(err, "Unable to lookup owner of federated bucket %s.", bucket)
+		h.handler.ServeHTTP(w, r)
+		return
+	}
+	if owner == "" || owner == f.endpoint {
+		h.handler.ServeHTTP(w, r)
+		return
+	}
+	ownerURL, err := url.Parse(owner)
+	if err != nil {
+		writeErrorResponse(w, r, ErrInternalError, r.URL.Path)
+		return
+	}
+	if f.mode == federationRedirect {
+		http.Redirect(w, r, owner+r.URL.RequestURI(), http.StatusTemporaryRedirect)
+		return
+	}
+	// The Host header is left unchanged, it is part of the signature
+	// verified by the owning cluster.
+	proxy := &httputil.ReverseProxy{
+		Director: func(req *http.Request) {
+			req.URL.Scheme = ownerURL.Scheme
+			req.URL.Host = ownerURL.Host
+			req.Header.Set(federationProxyHeader, f.endpoint)
+		},
+	}
+	proxy.ServeHTTP(w, r)
+}
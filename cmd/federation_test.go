@@ -0,0 +1,275 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package cmd
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	router "github.com/gorilla/mux"
+)
+
+// Tests parsing cluster URLs of the federation.
+func TestParseFederationURL(t *testing.T) {
+	testCases := []struct {
+		urlStr   string
+		expected string
+		err      bool
+	}{
+		{"https://cluster1.example.com", "https://cluster1.example.com", false},
+		{"http://10.0.0.1:9000/", "http://10.0.0.1:9000", false},
+		{"cluster1.example.com:9000", "", true},
+		{"ftp://cluster1.example.com", "", true},
+		{"https://cluster1.example.com/path", "", true},
+		{"https://cluster1.example.com?query=1", "", true},
+		{"https://", "", true},
+	}
+	for i, testCase := range testCases {
+		u, err := parseFederationURL(testCase.urlStr)
+		if (err != nil) != testCase.err {
+			t.Errorf("Test %d: Expected error %v, got %v", i+1, testCase.err, err)
+			continue
+		}
+		if err == nil && u.String() != testCase.expected {
+			t.Errorf("Test %d: Expected %s, got %s", i+1, testCase.expected, u.String())
+		}
+	}
+
+	if mode, err := parseFederationMode("Proxy"); err != nil || mode != federationProxy {
+		t.Errorf("Expected mode %s, got %s, %v", federationProxy, mode, err)
+	}
+	if _, err := parseFederationMode("forward"); err != errInvalidArgument {
+		t.Errorf("Expected error %v, got %v", errInvalidArgument, err)
+	}
+}
+
+// Tests claiming, looking up and releasing buckets with a coordinator,
+// both locally and over RPC.
+func TestFederation(t *testing.T) {
+	root, err := newTestConfig("us-east-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer removeAll(root)
+	obj, fsDir, err := prepareFS()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer removeRoots([]string{fsDir})
+	globalObjLayerMutex.Lock()
+	globalObjectAPI = obj
+	globalObjLayerMutex.Unlock()
+
+	mux := router.NewRouter()
+	if err = registerFederationRPCRouter(mux); err != nil {
+		t.Fatal(err)
+	}
+	server := httptest.NewServer(mux)
+	defer server.Close()
+	coordinatorURL, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cluster1 := &url.URL{Scheme: "https", Host: "cluster1.example.com"}
+	cluster2 := &url.URL{Scheme: "https", Host: "cluster2.example.com"}
+	coordinator := newFederation(cluster1, nil, federationRedirect)
+	member := newFederation(cluster2, coordinatorURL, federationRedirect)
+
+	// Claim over RPC.
+	if claimed, err := member.claim("bucket"); err != nil || !claimed {
+		t.Fatalf("Expected bucket to be claimed, got %v, %v", claimed, err)
+	}
+	if claimed, err := member.claim("bucket"); err != nil || claimed {
+		t.Fatalf("Expected bucket to be already claimed, got %v, %v", claimed, err)
+	}
+	if _, err = member.claim("b"); !isSameType(err, BucketNameInvalid{}) {
+		t.Fatalf("Expected BucketNameInvalid, got %v", err)
+	}
+
+	// Claiming locally on the coordinator fails, the bucket is owned
+	// by the member.
+	if _, err = coordinator.claim("bucket"); !isSameType(err, BucketAlreadyExists{}) {
+		t.Fatalf("Expected BucketAlreadyExists, got %v", err)
+	}
+	if owner, err := coordinator.lookup("bucket"); err != nil || owner != cluster2.String() {
+		t.Fatalf("Expected owner %s, got %s, %v", cluster2, owner, err)
+	}
+	if owner, err := member.lookup("unclaimed"); err != nil || owner != "" {
+		t.Fatalf("Expected no owner, got %s, %v", owner, err)
+	}
+
+	// Only the owner releases a bucket.
+	if err = coordinator.release("bucket"); err != nil {
+		t.Fatal(err)
+	}
+	if owner, err := lookupBucket(obj, "bucket"); err != nil || owner != cluster2.String() {
+		t.Fatalf("Expected owner %s, got %s, %v", cluster2, owner, err)
+	}
+	if err = member.release("bucket"); err != nil {
+		t.Fatal(err)
+	}
+	if owner, err := lookupBucket(obj, "bucket"); err != nil || owner != "" {
+		t.Fatalf("Expected no owner, got %s, %v", owner, err)
+	}
+	if claimed, err := coordinator.claim("bucket"); err != nil || !claimed {
+		t.Fatalf("Expected bucket to be claimed, got %v, %v", claimed, err)
+	}
+}
+
+// Tests requests for buckets owned by other clusters are redirected
+// or proxied.
+func TestFederationHandler(t *testing.T) {
+	root, err := newTestConfig("us-east-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer removeAll(root)
+	obj, fsDir, err := prepareFS()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer removeRoots([]string{fsDir})
+	globalObjLayerMutex.Lock()
+	globalObjectAPI = obj
+	globalObjLayerMutex.Unlock()
+
+	// Cluster owning the proxied bucket.
+	remote := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Served-By", "remote")
+		w.Header().Set("X-Proxied-By", r.Header.Get(federationProxyHeader))
+		w.Header().Set("X-Host", r.Host)
+	}))
+	defer remote.Close()
+
+	endpoint := &url.URL{Scheme: "http", Host: "cluster1.example.com"}
+	defer func() { globalFederation = nil }()
+	globalFederation = newFederation(endpoint, nil, federationRedirect)
+	for bucket, owner := range map[string]string{
+		"local":      endpoint.String(),
+		"redirected": "http://cluster2.example.com:9000",
+		"proxied":    remote.URL,
+		"forged":     "http://cluster2.example.com:9000",
+	} {
+		if _, err = claimBucket(obj, bucket, owner); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	handler := setFederationHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Served-By", "local")
+	}))
+	credentials := serverConfig.GetCredential()
+	testCases := []struct {
+		mode         string
+		path         string
+		signed       bool
+		proxied      bool
+		expectedCode int
+		servedBy     string
+		location     string
+	}{
+		{federationRedirect, "/", true, false, http.StatusOK, "local", ""},
+		{federationRedirect, "/local/object", true, false, http.StatusOK, "local", ""},
+		{federationRedirect, "/unclaimed/object", true, false, http.StatusOK, "local", ""},
+		{federationRedirect, "/minio/webrpc", true, false, http.StatusOK, "local", ""},
+		// Anonymous requests do not look up owners.
+		{federationRedirect, "/redirected/object", false, false, http.StatusOK, "local", ""},
+		{federationRedirect, "/redirected/a/b?uploads=", true, false, http.StatusTemporaryRedirect, "",
+			"http://cluster2.example.com:9000/redirected/a/b?uploads="},
+		// Owners already looked up are used.
+		{federationRedirect, "/redirected/object", false, false, http.StatusTemporaryRedirect, "",
+			"http://cluster2.example.com:9000/redirected/object"},
+		{federationRedirect, "/redirected/object", true, true, http.StatusOK, "local", ""},
+		{federationProxy, "/proxied/object", true, false, http.StatusOK, "remote", ""},
+	}
+	for i, testCase := range testCases {
+		globalFederation.mode = testCase.mode
+		req := httptest.NewRequest("GET", "http://cluster1.example.com"+testCase.path, nil)
+		if testCase.signed {
+			req.Header.Set("x-amz-content-sha256", unsignedPayload)
+			if err = signRequestV4(req, credentials.AccessKeyID, credentials.SecretAccessKey); err != nil {
+				t.Fatalf("Test %d: Unable to sign request: %v", i+1, err)
+			}
+		}
+		if testCase.proxied {
+			req.Header.Set(federationProxyHeader, "http://cluster2.example.com:9000")
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		if rec.Code != testCase.expectedCode {
+			t.Fatalf("Test %d: Expected status %d, got %d", i+1, testCase.expectedCode, rec.Code)
+		}
+		if got := rec.Header().Get("X-Served-By"); got != testCase.servedBy {
+			t.Errorf("Test %d: Expected served by %q, got %q", i+1, testCase.servedBy, got)
+		}
+		if got := rec.Header().Get("Location"); got != testCase.location {
+			t.Errorf("Test %d: Expected location %q, got %q", i+1, testCase.location, got)
+		}
+		if testCase.servedBy == "remote" {
+			if got := rec.Header().Get("X-Proxied-By"); got != endpoint.String() {
+				t.Errorf("Test %d: Expected proxied by %s, got %s", i+1, endpoint, got)
+			}
+			// Host is part of the signature, it must be kept.
+			if got := rec.Header().Get("X-Host"); got != "cluster1.example.com" {
+				t.Errorf("Test %d: Expected host cluster1.example.com, got %s", i+1, got)
+			}
+		}
+	}
+
+	// Requests with invalid signatures do not look up owners.
+	req := httptest.NewRequest("GET", "http://cluster1.example.com/forged/object", nil)
+	req.Header.Set("x-amz-content-sha256", unsignedPayload)
+	if err = signRequestV4(req, credentials.AccessKeyID, "invalid-secret-key"); err != nil {
+		t.Fatalf("Unable to sign request: %v", err)
+	}
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if got := rec.Header().Get("X-Served-By"); got != "local" {
+		t.Errorf("Expected request with invalid signature served locally, got %q", got)
+	}
+	if _, ok := globalFederation.cachedOwner("forged"); ok {
+		t.Errorf("Expected owner of forged not to be looked up")
+	}
+}
+
+// Tests the cache of bucket owners is bounded, expired entries are
+// dropped first.
+func TestFederationCacheBound(t *testing.T) {
+	f := newFederation(&url.URL{Scheme: "http", Host: "cluster1.example.com"}, nil, federationRedirect)
+	for i := 0; i < federationCacheMaxEntries; i++ {
+		f.setCache(fmt.Sprintf("bucket%d", i), "")
+	}
+	f.cache["bucket0"] = federationCacheEntry{expiry: time.Now().UTC().Add(-time.Second)}
+	f.setCache("new-bucket", "http://cluster2.example.com")
+	if len(f.cache) != federationCacheMaxEntries {
+		t.Fatalf("Expected %d cached owners, got %d", federationCacheMaxEntries, len(f.cache))
+	}
+	if _, ok := f.cache["bucket0"]; ok {
+		t.Errorf("Expected expired owner to be dropped")
+	}
+	if owner, ok := f.cachedOwner("new-bucket"); !ok || owner != "http://cluster2.example.com" {
+		t.Errorf("Expected new owner cached, got %q", owner)
+	}
+	f.setCache("another-bucket", "")
+	if len(f.cache) != federationCacheMaxEntries {
+		t.Errorf("Expected %d cached owners, got %d", federationCacheMaxEntries, len(f.cache))
+	}
+}
@@ -0,0 +1,193 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// FreezeArgs - argument for Freeze and Thaw RPC handlers.
+type FreezeArgs struct {
+	// Authentication token generated by Login.
+	GenericArgs
+
+	// Identifies the freeze, generated by the node
+	// coordinating the freeze.
+	FreezeID string
+
+	// Duration after which writes are thawed automatically.
+	Duration time.Duration
+}
+
+// FreezeReply - reply by Freeze RPC handler, represents the
+// consistent point at which all the nodes are frozen.
+type FreezeReply struct {
+	FreezeID string
+	// Time at which all the nodes were frozen and flushed.
+	FrozenAt time.Time
+	// Time at which writes are thawed automatically.
+	ExpiresAt time.Time
+	// All the buckets frozen.
+	Buckets []string
+	// All the nodes frozen.
+	Nodes []string
+}
+
+// Remote procedure call, calls method on all the remote nodes.
+func (c *controlAPIHandlers) remoteFreezeCall(method string, args *FreezeArgs) []error {
+	var wg sync.WaitGroup
+	remoteControls := c.getRemoteControls()
+	var errs = make([]error, len(remoteControls))
+	for index, clnt := range remoteControls {
+		wg.Add(1)
+		go func(index int, client *AuthRPCClient) {
+			defer wg.Done()
+			errs[index] = client.Call(method, args, &FreezeReply{})
+			errorIf(errs[index], "Unable to initiate control %s request to remote node %s", method, client.Node())
+		}(index, clnt)
+	}
+	wg.Wait()
+	return errs
+}
+
+// Freezes all the remote nodes, once writes are quiesced on every
+// node all the disks are flushed. Local node is expected to be frozen
+// already. Returns all the buckets frozen.
+func (c *controlAPIHandlers) freezeCluster(objAPI ObjectLayer, args *FreezeArgs) (buckets []string, err error) {
+	remoteArgs := *args
+	// Set remote as false for remote calls.
+	remoteArgs.Remote = false
+	for _, err = range c.remoteFreezeCall("Control.FreezeHandler", &remoteArgs) {
+		if err != nil {
+			// Thaw all the nodes which were frozen.
+			c.remoteFreezeCall("Control.ThawHandler", &remoteArgs)
+			return nil, err
+		}
+	}
+
+	// Bucket level writes like make and delete bucket pass through
+	// the same write gate, so the bucket list stays the same until
+	// writes are thawed.
+	bucketsInfo, err := objAPI.ListBuckets()
+	if err == nil {
+		for _, bucketInfo := range bucketsInfo {
+			buckets = append(buckets, bucketInfo.Name)
+		}
+		sort.Strings(buckets)
+	}
+	if err == nil {
+		err = flushDisks()
+	}
+	if err != nil {
+		c.remoteFreezeCall("Control.ThawHandler", &remoteArgs)
+		return nil, err
+	}
+	return buckets, nil
+}
+
+// FreezeHandler - RPC control handler for `minio control freeze`,
+// quiesces all the writes in the cluster and flushes all the disks.
+func (c *controlAPIHandlers) FreezeHandler(args *FreezeArgs, reply *FreezeReply) (err error) {
+	defer encodeRPCError(&err)
+
+	if !isRPCTokenValid(args.Token, jwtAudienceAdmin) {
+		return errInvalidToken
+	}
+	// Retries get the result of the first call.
+	if replayed, replayErr := globalIdempotentCalls.Begin("Control.FreezeHandler", args.IdempotencyKey, reply); replayed {
+		return replayErr
+	}
+	defer globalIdempotentCalls.End("Control.FreezeHandler", args.IdempotencyKey, reply, &err)
+
+	objAPI := c.ObjectAPI()
+	if objAPI == nil {
+		return errServerNotInitialized
+	}
+	if args.Duration <= 0 {
+		args.Duration = defaultFreezeDuration
+	}
+	if args.Duration > maxFreezeDuration {
+		return errInvalidArgument
+	}
+
+	if !args.Remote {
+		// Freeze only the local node, requested by the node
+		// coordinating the freeze.
+		if err := globalWriteFreeze.freeze(args.FreezeID, args.Duration); err != nil {
+			return err
+		}
+		if err := flushDisks(); err != nil {
+			globalWriteFreeze.thaw(args.FreezeID)
+			return err
+		}
+		return nil
+	}
+
+	args.FreezeID = getUUID()
+	if err := globalWriteFreeze.freeze(args.FreezeID, args.Duration); err != nil {
+		return err
+	}
+	buckets, err := c.freezeCluster(objAPI, args)
+	if err != nil {
+		globalWriteFreeze.thaw(args.FreezeID)
+		return err
+	}
+
+	frozenAt := time.Now().UTC()
+	nodes := []string{c.LocalNode}
+	for _, client := range c.getRemoteControls() {
+		nodes = append(nodes, client.Node())
+	}
+	*reply = FreezeReply{
+		FreezeID:  args.FreezeID,
+		FrozenAt:  frozenAt,
+		ExpiresAt: frozenAt.Add(args.Duration),
+		Buckets:   buckets,
+		Nodes:     nodes,
+	}
+	return nil
+}
+
+// ThawHandler - RPC control handler for `minio control thaw`,
+// unblocks all the writes frozen by FreezeHandler.
+func (c *controlAPIHandlers) ThawHandler(args *FreezeArgs, reply *FreezeReply) (err error) {
+	defer encodeRPCError(&err)
+
+	if !isRPCTokenValid(args.Token, jwtAudienceAdmin) {
+		return errInvalidToken
+	}
+	// Retries get the result of the first call.
+	if replayed, replayErr := globalIdempotentCalls.Begin("Control.ThawHandler", args.IdempotencyKey, reply); replayed {
+		return replayErr
+	}
+	defer globalIdempotentCalls.End("Control.ThawHandler", args.IdempotencyKey, reply, &err)
+
+	if args.Remote {
+		// Set remote as false for remote calls.
+		args.Remote = false
+		// Remote nodes which fail to thaw, thaw
+		// automatically once the freeze expires.
+		c.remoteFreezeCall("Control.ThawHandler", args)
+	}
+	if err := globalWriteFreeze.thaw(args.FreezeID); err != nil {
+		return err
+	}
+	reply.FreezeID = args.FreezeID
+	return nil
+}
@@ -0,0 +1,135 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// Default duration writes are frozen for, unless thawed earlier.
+	defaultFreezeDuration = 30 * time.Second
+
+	// Maximum duration writes can be frozen for.
+	maxFreezeDuration = 5 * time.Minute
+)
+
+// errWritesFrozen - writes are already frozen.
+var errWritesFrozen = errors.New("Writes are already frozen")
+
+// errWritesNotFrozen - writes are not frozen for the given freeze ID.
+var errWritesNotFrozen = errors.New("Writes are not frozen for the given freeze ID")
+
+// errFreezeExpired - writes were thawed since freeze duration elapsed.
+var errFreezeExpired = errors.New("Freeze duration expired")
+
+// writeFreeze - quiesces all the writes served by this node, used
+// to take consistent snapshots of the underlying drives. Frozen
+// writes are automatically thawed after the requested duration so
+// that a failed snapshot never blocks the server indefinitely.
+type writeFreeze struct {
+	// Held for reading by every in-flight write, held for
+	// writing while frozen.
+	gate sync.RWMutex
+
+	mutex    sync.Mutex // guards all the fields below.
+	frozen   bool
+	freezeID string
+	timer    *time.Timer
+}
+
+// Global write freeze.
+var globalWriteFreeze = &writeFreeze{}
+
+// freeze - waits for all the in-flight writes to complete and blocks
+// new writes until thaw is called with the same freezeID or duration
+// elapses.
+func (f *writeFreeze) freeze(freezeID string, duration time.Duration) error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	if f.frozen {
+		return errWritesFrozen
+	}
+
+	// Blocks until all the in-flight writes are complete.
+	f.gate.Lock()
+
+	f.frozen = true
+	f.freezeID = freezeID
+	f.timer = time.AfterFunc(duration, func() {
+		if err := f.thaw(freezeID); err == nil {
+			errorIf(errFreezeExpired, "Writes were automatically thawed for freeze ID %s.", freezeID)
+		}
+	})
+	return nil
+}
+
+// thaw - unblocks writes frozen with freezeID.
+func (f *writeFreeze) thaw(freezeID string) error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	if !f.frozen || f.freezeID != freezeID {
+		return errWritesNotFrozen
+	}
+	f.timer.Stop()
+	f.frozen = false
+	f.freezeID = ""
+	f.gate.Unlock()
+	return nil
+}
+
+// isFrozen - returns the current freeze ID if writes are frozen.
+func (f *writeFreeze) isFrozen() (string, bool) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	return f.freezeID, f.frozen
+}
+
+// Adds write freeze for all incoming write requests.
+type writeFreezeHandler struct {
+	handler http.Handler
+}
+
+func setWriteFreezeHandler(h http.Handler) http.Handler {
+	return writeFreezeHandler{handler: h}
+}
+
+// Returns true if the request may modify objects or buckets, every
+// request other than GET and HEAD is considered as a write, browser
+// RPCs included. Internal RPC requests are never considered as writes,
+// since in-flight writes on other nodes depend on them to complete.
+func isWriteRequest(r *http.Request) bool {
+	switch r.Method {
+	case "GET", "HEAD":
+		return false
+	}
+	// Requests to the reserved bucket are internal RPCs.
+	return !strings.HasPrefix(r.URL.Path, reservedBucket+"/")
+}
+
+func (h writeFreezeHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if isWriteRequest(r) {
+		// Blocks while writes are frozen.
+		globalWriteFreeze.gate.RLock()
+		defer globalWriteFreeze.gate.RUnlock()
+	}
+	h.handler.ServeHTTP(w, r)
+}
@@ -22,6 +22,8 @@ import (
 	"path"
 	"sort"
 	"strings"
+
+	"github.com/mf-00/newgo/pkg/mimedb"
 )
 
 const (
@@ -193,3 +195,30 @@ func hasExtendedHeader(metadata map[string]string) bool {
 	}
 	return false
 }
+
+// Returns content-type guessed from the object extension, empty if unknown.
+func guessContentType(object string) string {
+	if objectExt := path.Ext(object); objectExt != "" {
+		if content, ok := mimedb.DB[strings.ToLower(strings.TrimPrefix(objectExt, "."))]; ok {
+			return content.ContentType
+		}
+	}
+	return ""
+}
+
+// Return true if standard HTTP headers are set which can't be derived
+// back from the object name, i.e. any of the supported headers other
+// than a content-type guessed from the object extension.
+func hasNonDefaultHeader(object string, metadata map[string]string) bool {
+	for _, header := range supportedHeaders {
+		value := metadata[header]
+		if value == "" {
+			continue
+		}
+		if header == "content-type" && value == guessContentType(object) {
+			continue
+		}
+		return true
+	}
+	return false
+}
@@ -67,6 +67,28 @@ func TestHasExtendedHeader(t *testing.T) {
 	}
 }
 
+// Tests scenarios which can occur for hasNonDefaultHeader function.
+func TestHasNonDefaultHeader(t *testing.T) {
+	testCases := []struct {
+		object   string
+		metadata map[string]string
+		has      bool
+	}{
+		{"object.txt", map[string]string{"md5Sum": "abcd"}, false},
+		{"object.txt", map[string]string{"content-type": "text/plain"}, false},
+		{"object.txt", map[string]string{"content-type": "application/json"}, true},
+		{"object", map[string]string{"content-type": "application/octet-stream"}, true},
+		{"object.txt", map[string]string{"cache-control": "no-cache"}, true},
+		{"object.txt", map[string]string{"content-disposition": ""}, false},
+	}
+	for i, testCase := range testCases {
+		has := hasNonDefaultHeader(testCase.object, testCase.metadata)
+		if has != testCase.has {
+			t.Fatalf("Test case %d: Expected \"%#v\", but got \"%#v\"", i+1, testCase.has, has)
+		}
+	}
+}
+
 func initFSObjects(disk string, t *testing.T) (obj ObjectLayer) {
 	obj, _, err := initObjectLayer([]string{disk}, nil)
 	if err != nil {
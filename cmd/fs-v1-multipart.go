@@ -17,8 +17,6 @@
 package cmd
 
 import (
-	"crypto/md5"
-	"crypto/sha256"
 	"encoding/hex"
 	"fmt"
 	"hash"
@@ -65,7 +63,9 @@ func (fs fsObjects) listMultipartUploads(bucket, prefix, keyMarker, uploadIDMark
 		nsMutex.RLock(minioMetaBucket, pathJoin(mpartMetaPrefix, bucket, keyMarker), opsID)
 		uploads, _, err = listMultipartUploadIDs(bucket, keyMarker, uploadIDMarker, maxUploads, fs.storage)
 		nsMutex.RUnlock(minioMetaBucket, pathJoin(mpartMetaPrefix, bucket, keyMarker), opsID)
-		if err != nil {
+		// All uploads of keyMarker may have been completed or
+		// aborted since the previous listing, continue the walk.
+		if err != nil && !isErrIgnored(err, fsTreeWalkIgnoredErrs) {
 			return ListMultipartsInfo{}, err
 		}
 		maxUploads = maxUploads - len(uploads)
@@ -92,8 +92,7 @@ func (fs fsObjects) listMultipartUploads(bucket, prefix, keyMarker, uploadIDMark
 			if walkResult.err != nil {
 				// File not found or Disk not found is a valid case.
 				if isErrIgnored(walkResult.err, fsTreeWalkIgnoredErrs) {
-					eof = true
-					break
+					continue
 				}
 				return ListMultipartsInfo{}, walkResult.err
 			}
@@ -122,6 +121,9 @@ func (fs fsObjects) listMultipartUploads(bucket, prefix, keyMarker, uploadIDMark
 			tmpUploads, end, err = listMultipartUploadIDs(bucket, entry, uploadIDMarker, maxUploads, fs.storage)
 			nsMutex.RUnlock(minioMetaBucket, pathJoin(mpartMetaPrefix, bucket, entry), opsID)
 			if err != nil {
+				if isErrIgnored(err, fsTreeWalkIgnoredErrs) {
+					continue
+				}
 				return ListMultipartsInfo{}, err
 			}
 			uploads = append(uploads, tmpUploads...)
@@ -150,10 +152,11 @@ func (fs fsObjects) listMultipartUploads(bucket, prefix, keyMarker, uploadIDMark
 		result.NextUploadIDMarker = uploadID
 	}
 
-	if !eof {
+	if !eof && walkResultCh != nil {
 		// Save the go-routine state in the pool so that it can continue from where it left off on
-		// the next request.
-		fs.listPool.Set(listParams{bucket, recursive, result.NextKeyMarker, prefix, heal}, walkResultCh, endWalkCh)
+		// the next request, keyed the same way it is released above.
+		nextMarkerPath := pathJoin(mpartMetaPrefix, bucket, result.NextKeyMarker)
+		fs.listPool.Set(listParams{minioMetaBucket, recursive, nextMarkerPath, multipartPrefixPath, heal}, walkResultCh, endWalkCh)
 	}
 
 	result.IsTruncated = !eof
@@ -228,8 +231,9 @@ func (fs fsObjects) newMultipartUpload(bucket string, object string, meta map[st
 	// Initialize `fs.json` values.
 	fsMeta := newFSMetaV1()
 
-	// Save additional metadata only if extended headers such as "X-Amz-Meta-" are set.
-	if hasExtendedHeader(meta) {
+	// Save additional metadata only if extended headers such as "X-Amz-Meta-"
+	// or standard headers which can't be guessed back are set.
+	if hasExtendedHeader(meta) || hasNonDefaultHeader(object, meta) {
 		fsMeta.Meta = meta
 	}
 
@@ -423,14 +427,15 @@ func (fs fsObjects) PutObjectPart(bucket, object, uploadID string, partID int, s
 	partSuffix := fmt.Sprintf("object%d", partID)
 	tmpPartPath := path.Join(tmpMetaPrefix, uploadID+"."+getUUID()+"."+partSuffix)
 
-	// Initialize md5 writer.
-	md5Writer := md5.New()
+	// Initialize md5 writer, computing the ETag and the md5sum of
+	// Content-MD5.
+	md5Writer := newETagWriter(md5Hex)
 
 	hashWriters := []io.Writer{md5Writer}
 
 	var sha256Writer hash.Hash
 	if sha256sum != "" {
-		sha256Writer = sha256.New()
+		sha256Writer = newSHA256()
 		hashWriters = append(hashWriters, sha256Writer)
 	}
 	multiWriter := io.MultiWriter(hashWriters...)
@@ -462,12 +467,12 @@ func (fs fsObjects) PutObjectPart(bucket, object, uploadID string, partID int, s
 		return "", traceError(IncompleteBody{})
 	}
 
-	newMD5Hex := hex.EncodeToString(md5Writer.Sum(nil))
+	newMD5Hex := md5Writer.ETag()
 	if md5Hex != "" {
-		if newMD5Hex != md5Hex {
+		if gotMD5Hex := md5Writer.MD5(); gotMD5Hex != md5Hex {
 			// MD5 mismatch, delete the temporary object.
 			fs.storage.DeleteFile(minioMetaBucket, tmpPartPath)
-			return "", traceError(BadDigest{md5Hex, newMD5Hex})
+			return "", traceError(BadDigest{md5Hex, gotMD5Hex})
 		}
 	}
 
@@ -529,11 +534,7 @@ func (fs fsObjects) listObjectParts(bucket, object, uploadID string, partNumberM
 		return ListPartsInfo{}, toObjectErr(err, minioMetaBucket, fsMetaPath)
 	}
 	// Only parts with higher part numbers will be listed.
-	partIdx := fsMeta.ObjectPartIndex(partNumberMarker)
-	parts := fsMeta.Parts
-	if partIdx != -1 {
-		parts = fsMeta.Parts[partIdx+1:]
-	}
+	parts := getPartsAfterMarker(fsMeta.Parts, partNumberMarker)
 	count := maxParts
 	for _, part := range parts {
 		var fi FileInfo
@@ -678,7 +679,7 @@ func (fs fsObjects) CompleteMultipartUpload(bucket string, object string, upload
 				return "", traceError(InvalidPart{})
 			}
 			if fsMeta.Parts[partIdx].ETag != part.ETag {
-				return "", traceError(BadDigest{})
+				return "", traceError(InvalidPart{})
 			}
 			// All parts except the last part has to be atleast 5MB.
 			if (i < len(parts)-1) && !isMinAllowedPartSize(fsMeta.Parts[partIdx].Size) {
@@ -730,10 +731,23 @@ func (fs fsObjects) CompleteMultipartUpload(bucket string, object string, upload
 	}
 
 	// No need to save part info, since we have concatenated all parts.
-	fsMeta.Parts = nil
+	// With strict ETag parity the info of the completed parts is retained
+	// so that the part level md5sums can be served back to the clients,
+	// encrypted objects need it to decrypt each part.
+	var objectParts []objectPartInfo
+	if globalStrictETag || isEncryptedObject(fsMeta.Meta) {
+		for _, part := range parts {
+			if partIdx := fsMeta.ObjectPartIndex(part.PartNumber); partIdx != -1 {
+				objectParts = append(objectParts, fsMeta.Parts[partIdx])
+			}
+		}
+	}
+	fsMeta.Parts = objectParts
 
-	// Save additional metadata only if extended headers such as "X-Amz-Meta-" are set.
-	if hasExtendedHeader(fsMeta.Meta) {
+	// Save additional metadata only if extended headers such as "X-Amz-Meta-"
+	// or standard headers which can't be guessed back are set, strict ETag parity always saves the metadata to preserve the multipart ETag.
+	// Retained parts are saved along.
+	if hasExtendedHeader(fsMeta.Meta) || hasNonDefaultHeader(object, fsMeta.Meta) || objectParts != nil || globalStrictETag {
 		if len(fsMeta.Meta) == 0 {
 			fsMeta.Meta = make(map[string]string)
 		}
@@ -204,7 +204,8 @@ func TestListMultipartUploadsFaultyDisk(t *testing.T) {
 					t.Fatal("Unexpected error ", err)
 				}
 			case 3:
-				if errorCause(err) != errFileNotFound {
+				// Uploads which can no longer be read are skipped.
+				if err != nil {
 					t.Fatal("Unexpected error ", err)
 				}
 			default:
@@ -17,8 +17,6 @@
 package cmd
 
 import (
-	"crypto/md5"
-	"crypto/sha256"
 	"encoding/hex"
 	"errors"
 	"fmt"
@@ -29,7 +27,7 @@ import (
 	"sort"
 	"strings"
 
-	"github.com/mf-00/newgo/pkg/mimedb"
+	"github.com/mf-00/newgo/pkg/objcache"
 )
 
 // fsObjects - Implements fs object layer.
@@ -195,6 +193,27 @@ func (fs fsObjects) DeleteBucket(bucket string) error {
 	return nil
 }
 
+// RenameBucket - renames a bucket along with its metadata.
+func (fs fsObjects) RenameBucket(srcBucket, dstBucket string) error {
+	// Verify if buckets are valid.
+	if !IsValidBucketName(srcBucket) {
+		return traceError(BucketNameInvalid{Bucket: srcBucket})
+	}
+	if !IsValidBucketName(dstBucket) {
+		return traceError(BucketNameInvalid{Bucket: dstBucket})
+	}
+	if err := fs.storage.RenameVol(srcBucket, dstBucket); err != nil {
+		return renameBucketErr(traceError(err), srcBucket, dstBucket)
+	}
+	if err := renameBucketMetadata(fs.storage, srcBucket, dstBucket); err != nil {
+		// Move the bucket and the metadata moved so far back.
+		errorIf(renameBucketMetadata(fs.storage, dstBucket, srcBucket), "Unable to undo rename of bucket %s metadata.", srcBucket)
+		errorIf(fs.storage.RenameVol(dstBucket, srcBucket), "Unable to undo rename of bucket %s.", srcBucket)
+		return toObjectErr(err, srcBucket)
+	}
+	return nil
+}
+
 /// Object Operations
 
 // GetObject - get an object.
@@ -305,10 +324,8 @@ func (fs fsObjects) getObjectInfo(bucket, object string) (ObjectInfo, error) {
 
 	// Guess content-type from the extension if possible.
 	if fsMeta.Meta["content-type"] == "" {
-		if objectExt := path.Ext(object); objectExt != "" {
-			if content, ok := mimedb.DB[strings.ToLower(strings.TrimPrefix(objectExt, "."))]; ok {
-				fsMeta.Meta["content-type"] = content.ContentType
-			}
+		if contentType := guessContentType(object); contentType != "" {
+			fsMeta.Meta["content-type"] = contentType
 		}
 	}
 
@@ -339,6 +356,37 @@ func (fs fsObjects) GetObjectInfo(bucket, object string) (ObjectInfo, error) {
 	return fs.getObjectInfo(bucket, object)
 }
 
+// GetObjectParts - returns the parts recorded in `fs.json` for an
+// object. Parts are only retained for multipart objects completed
+// with strict ETag parity enabled, otherwise nothing is returned.
+func (fs fsObjects) GetObjectParts(bucket, object string) ([]objectPartInfo, error) {
+	// Verify if bucket is valid.
+	if !IsValidBucketName(bucket) {
+		return nil, traceError(BucketNameInvalid{Bucket: bucket})
+	}
+	// Verify if object is valid.
+	if !IsValidObjectName(object) {
+		return nil, traceError(ObjectNameInvalid{Bucket: bucket, Object: object})
+	}
+
+	// get a random ID for lock instrumentation.
+	opsID := getOpsID()
+
+	// Lock the object before reading its parts.
+	nsMutex.RLock(bucket, object, opsID)
+	defer nsMutex.RUnlock(bucket, object, opsID)
+
+	if _, err := fs.storage.StatFile(bucket, object); err != nil {
+		return nil, toObjectErr(traceError(err), bucket, object)
+	}
+	fsMeta, err := readFSMetadata(fs.storage, minioMetaBucket, path.Join(bucketMetaPrefix, bucket, object, fsMetaJSONFile))
+	// Ignore error if the metadata file is not found, other errors must be returned.
+	if err != nil && errorCause(err) != errFileNotFound {
+		return nil, toObjectErr(err, bucket, object)
+	}
+	return fsMeta.Parts, nil
+}
+
 // PutObject - create an object.
 func (fs fsObjects) PutObject(bucket string, object string, size int64, data io.Reader, metadata map[string]string, sha256sum string) (objInfo ObjectInfo, err error) {
 	// Verify if bucket is valid.
@@ -355,6 +403,8 @@ func (fs fsObjects) PutObject(bucket string, object string, size int64, data io.
 	if metadata == nil {
 		metadata = make(map[string]string)
 	}
+	// There is no object cache in FS mode.
+	takeCacheBypass(metadata)
 
 	uniqueID := getUUID()
 
@@ -363,14 +413,15 @@ func (fs fsObjects) PutObject(bucket string, object string, size int64, data io.
 	// so that cleaning it up will be easy if the server goes down.
 	tempObj := path.Join(tmpMetaPrefix, uniqueID)
 
-	// Initialize md5 writer.
-	md5Writer := md5.New()
+	// Initialize md5 writer, computing the ETag and the md5sum of
+	// Content-MD5.
+	md5Writer := newETagWriter(metadata["md5Sum"])
 
 	hashWriters := []io.Writer{md5Writer}
 
 	var sha256Writer hash.Hash
 	if sha256sum != "" {
-		sha256Writer = sha256.New()
+		sha256Writer = newSHA256()
 		hashWriters = append(hashWriters, sha256Writer)
 	}
 	multiWriter := io.MultiWriter(hashWriters...)
@@ -415,22 +466,20 @@ func (fs fsObjects) PutObject(bucket string, object string, size int64, data io.
 		}
 	}
 
-	newMD5Hex := hex.EncodeToString(md5Writer.Sum(nil))
-	// Update the md5sum if not set with the newly calculated one.
-	if len(metadata["md5Sum"]) == 0 {
-		metadata["md5Sum"] = newMD5Hex
-	}
+	newMD5Hex := md5Writer.ETag()
 
 	// md5Hex representation.
 	md5Hex := metadata["md5Sum"]
 	if md5Hex != "" {
-		if newMD5Hex != md5Hex {
+		if gotMD5Hex := md5Writer.MD5(); gotMD5Hex != md5Hex {
 			// MD5 mismatch, delete the temporary object.
 			fs.storage.DeleteFile(minioMetaBucket, tempObj)
 			// Returns md5 mismatch.
-			return ObjectInfo{}, traceError(BadDigest{md5Hex, newMD5Hex})
+			return ObjectInfo{}, traceError(BadDigest{md5Hex, gotMD5Hex})
 		}
 	}
+	// Update the md5sum with the newly calculated ETag.
+	metadata["md5Sum"] = newMD5Hex
 
 	if sha256sum != "" {
 		newSHA256sum := hex.EncodeToString(sha256Writer.Sum(nil))
@@ -454,16 +503,23 @@ func (fs fsObjects) PutObject(bucket string, object string, size int64, data io.
 		return ObjectInfo{}, toObjectErr(traceError(err), bucket, object)
 	}
 
-	// Save additional metadata only if extended headers such as "X-Amz-Meta-" are set.
-	if hasExtendedHeader(metadata) {
+	// Save additional metadata only if extended headers such as "X-Amz-Meta-"
+	// or standard headers which can't be guessed back are set.
+	fsMetaPath := path.Join(bucketMetaPrefix, bucket, object, fsMetaJSONFile)
+	if hasExtendedHeader(metadata) || hasNonDefaultHeader(object, metadata) {
 		// Initialize `fs.json` values.
 		fsMeta := newFSMetaV1()
 		fsMeta.Meta = metadata
 
-		fsMetaPath := path.Join(bucketMetaPrefix, bucket, object, fsMetaJSONFile)
 		if err = writeFSMetadata(fs.storage, minioMetaBucket, fsMetaPath, fsMeta); err != nil {
 			return ObjectInfo{}, toObjectErr(traceError(err), bucket, object)
 		}
+	} else {
+		// Remove metadata of a previous version of the object if any.
+		err = fs.storage.DeleteFile(minioMetaBucket, fsMetaPath)
+		if err != nil && err != errFileNotFound {
+			return ObjectInfo{}, toObjectErr(traceError(err), bucket, object)
+		}
 	}
 	objInfo, err = fs.getObjectInfo(bucket, object)
 	if err == nil {
@@ -501,9 +557,54 @@ func (fs fsObjects) DeleteObject(bucket, object string) error {
 	return nil
 }
 
-// ListObjects - list all objects at prefix upto maxKeys., optionally delimited by '/'. Maintains the list pool
+// UpdateObjectMetadata - calls update with the metadata of an object
+// under its lock, the metadata is saved if update changed it. Object
+// data is left untouched.
+func (fs fsObjects) UpdateObjectMetadata(bucket, object string, update func(metadata map[string]string) (bool, error)) error {
+	// Verify if bucket is valid.
+	if !IsValidBucketName(bucket) {
+		return traceError(BucketNameInvalid{Bucket: bucket})
+	}
+	if !IsValidObjectName(object) {
+		return traceError(ObjectNameInvalid{Bucket: bucket, Object: object})
+	}
+	// get a random ID for lock instrumentation.
+	opsID := getOpsID()
+
+	nsMutex.Lock(bucket, object, opsID)
+	defer nsMutex.Unlock(bucket, object, opsID)
+
+	if _, err := fs.storage.StatFile(bucket, object); err != nil {
+		return toObjectErr(traceError(err), bucket, object)
+	}
+	fsMetaPath := path.Join(bucketMetaPrefix, bucket, object, fsMetaJSONFile)
+	fsMeta, err := readFSMetadata(fs.storage, minioMetaBucket, fsMetaPath)
+	if err != nil {
+		// Objects without extended headers have no metadata file.
+		if errorCause(err) != errFileNotFound {
+			return toObjectErr(err, bucket, object)
+		}
+		fsMeta = newFSMetaV1()
+	}
+	if fsMeta.Meta == nil {
+		fsMeta.Meta = make(map[string]string)
+	}
+	changed, err := update(fsMeta.Meta)
+	if err != nil || !changed {
+		return err
+	}
+	if err = writeFSMetadata(fs.storage, minioMetaBucket, fsMetaPath, fsMeta); err != nil {
+		return toObjectErr(err, bucket, object)
+	}
+	return nil
+}
+
+// ListObjects - list all objects at prefix upto maxKeys., optionally delimited. Maintains the list pool
 // state for future re-entrant list requests.
 func (fs fsObjects) ListObjects(bucket, prefix, marker, delimiter string, maxKeys int) (ListObjectsInfo, error) {
+	// Metadata of listed objects, returned along as user defined metadata.
+	objMetas := make(map[string]map[string]string)
+
 	// Convert entry to FileInfo
 	entryToFileInfo := func(entry string) (fileInfo FileInfo, err error) {
 		if strings.HasSuffix(entry, slashSeparator) {
@@ -525,6 +626,7 @@ func (fs fsObjects) ListObjects(bucket, prefix, marker, delimiter string, maxKey
 		// Object name needs to be full path.
 		fileInfo.Name = entry
 		fileInfo.MD5Sum = fsMeta.Meta["md5Sum"]
+		objMetas[entry] = fsMeta.Meta
 		return
 	}
 
@@ -539,12 +641,6 @@ func (fs fsObjects) ListObjects(bucket, prefix, marker, delimiter string, maxKey
 	if !IsValidObjectPrefix(prefix) {
 		return ListObjectsInfo{}, traceError(ObjectNameInvalid{Bucket: bucket, Object: prefix})
 	}
-	// Verify if delimiter is anything other than '/', which we do not support.
-	if delimiter != "" && delimiter != slashSeparator {
-		return ListObjectsInfo{}, traceError(UnsupportedDelimiter{
-			Delimiter: delimiter,
-		})
-	}
 	// Verify if marker has prefix.
 	if marker != "" {
 		if !strings.HasPrefix(marker, prefix) {
@@ -574,6 +670,13 @@ func (fs fsObjects) ListObjects(bucket, prefix, marker, delimiter string, maxKey
 		maxKeys = maxObjectList
 	}
 
+	// Roll up recursively listed keys for delimiters other than '/'.
+	if delimiter != "" && delimiter != slashSeparator {
+		return listObjectsWithDelimiter(func(marker string, maxKeys int) (ListObjectsInfo, error) {
+			return fs.ListObjects(bucket, prefix, marker, "", maxKeys)
+		}, prefix, marker, delimiter, maxKeys)
+	}
+
 	// Default is recursive, if delimiter is set then list non recursive.
 	recursive := true
 	if delimiter == slashSeparator {
@@ -636,11 +739,12 @@ func (fs fsObjects) ListObjects(bucket, prefix, marker, delimiter string, maxKey
 			continue
 		}
 		result.Objects = append(result.Objects, ObjectInfo{
-			Name:    fileInfo.Name,
-			ModTime: fileInfo.ModTime,
-			Size:    fileInfo.Size,
-			MD5Sum:  fileInfo.MD5Sum,
-			IsDir:   false,
+			Name:        fileInfo.Name,
+			ModTime:     fileInfo.ModTime,
+			Size:        fileInfo.Size,
+			MD5Sum:      fileInfo.MD5Sum,
+			IsDir:       false,
+			UserDefined: objMetas[fileInfo.Name],
 		})
 	}
 	return result, nil
@@ -651,6 +755,16 @@ func (fs fsObjects) HealObject(bucket, object string) error {
 	return traceError(NotImplemented{})
 }
 
+// VerifyObject - fs stores no checksums. Valid only for XL.
+func (fs fsObjects) VerifyObject(bucket, object string) error {
+	return traceError(NotImplemented{})
+}
+
+// CacheStats - fs has no object cache. Valid only for XL.
+func (fs fsObjects) CacheStats() (objcache.Stats, error) {
+	return objcache.Stats{}, traceError(NotImplemented{})
+}
+
 // HealBucket - no-op for fs, Valid only for XL.
 func (fs fsObjects) HealBucket(bucket string) error {
 	return traceError(NotImplemented{})
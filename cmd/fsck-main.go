@@ -0,0 +1,437 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/minio/cli"
+	"github.com/minio/mc/pkg/console"
+)
+
+var fsckFlags = []cli.Flag{
+	cli.BoolFlag{
+		Name:  "fix",
+		Usage: "Remove orphaned part files found by the check.",
+	},
+}
+
+// "minio fsck" command.
+var fsckCmd = cli.Command{
+	Name:   "fsck",
+	Usage:  "Check the backend format and object metadata of stopped disks.",
+	Flags:  append(fsckFlags, globalFlags...),
+	Action: mainFsck,
+	CustomHelpTemplate: `NAME:
+   minio {{.Name}} - {{.Usage}}
+
+USAGE:
+   minio {{.Name}} [FLAGS] PATH [PATH...]
+
+FLAGS:
+  {{range .Flags}}{{.}}
+  {{end}}
+DESCRIPTION:
+   Walks all given local disks of a stopped server and cross-checks
+   format.json UUIDs and JBOD order, xl.json of every object and the part
+   files next to it. Each issue found is reported with a suggested repair.
+   Missing or stale format.json and xl.json are repaired by 'minio control
+   heal' once the server is started again. Part files not referenced by
+   xl.json are only removed with --fix.
+
+   Exits with status 1 if any issue is left unrepaired.
+
+EXAMPLES:
+   1. Check the disks of an erasure coded server.
+      $ minio {{.Name}} /mnt/export1/ /mnt/export2/ /mnt/export3/ /mnt/export4/
+
+   2. Check the disks and remove orphaned part files.
+      $ minio {{.Name}} --fix /mnt/export1/ /mnt/export2/ /mnt/export3/ /mnt/export4/
+`,
+}
+
+// Suggested repairs of the issues found by fsck.
+const (
+	fsckRepairHealFormat = "Start the server and run 'minio control heal SERVER' to heal format.json."
+	fsckRepairHealObject = "Start the server and run 'minio control heal SERVER/BUCKET/OBJECT' to heal the object."
+	fsckRepairReplace    = "Replace the disk with one belonging to this setup, or remove it from the arguments."
+	fsckRepairRestore    = "Bring the missing disks back, the object can not be healed from the remaining ones."
+	fsckRepairRemove     = "Remove the file, or rerun with --fix."
+)
+
+// fsckIssue - a single inconsistency found by fsck.
+type fsckIssue struct {
+	Disk    string // Disk the issue was found on, empty if it spans disks.
+	Path    string // Bucket and object path, empty for format issues.
+	Problem string
+	Repair  string
+	Fixed   bool
+}
+
+// String - formats the issue as a report entry.
+func (i fsckIssue) String() string {
+	location := i.Disk
+	if location == "" {
+		location = "all disks"
+	}
+	if i.Path != "" {
+		location += " " + i.Path
+	}
+	if i.Fixed {
+		return fmt.Sprintf("%s: %s\n   Fixed.", location, i.Problem)
+	}
+	return fmt.Sprintf("%s: %s\n   Repair: %s", location, i.Problem, i.Repair)
+}
+
+// fsckReport - collects the issues found on all disks.
+type fsckReport struct {
+	Disks   int
+	Objects int
+	Issues  []fsckIssue
+}
+
+// add - records a new issue.
+func (r *fsckReport) add(disk, path, repair, problem string, args ...interface{}) {
+	r.Issues = append(r.Issues, fsckIssue{
+		Disk:    disk,
+		Path:    path,
+		Problem: fmt.Sprintf(problem, args...),
+		Repair:  repair,
+	})
+}
+
+// unrepaired - returns the number of issues not fixed by fsck.
+func (r fsckReport) unrepaired() (count int) {
+	for _, issue := range r.Issues {
+		if !issue.Fixed {
+			count++
+		}
+	}
+	return count
+}
+
+// String - formats the report.
+func (r fsckReport) String() string {
+	lines := []string{fmt.Sprintf("Checked %d disks and %d objects, found %d issues, %d unrepaired.",
+		r.Disks, r.Objects, len(r.Issues), r.unrepaired())}
+	for _, issue := range r.Issues {
+		lines = append(lines, issue.String())
+	}
+	return strings.Join(lines, "\n")
+}
+
+func mainFsck(ctx *cli.Context) {
+	if !ctx.Args().Present() || ctx.Args().First() == "help" {
+		cli.ShowCommandHelpAndExit(ctx, "fsck", 1)
+	}
+
+	disks := ctx.Args()
+	for _, disk := range disks {
+		if !isLocalStorage(disk) {
+			console.Fatalf("fsck only checks local disks, %s is remote.\n", disk)
+		}
+	}
+
+	report := fsck(disks, ctx.Bool("fix"))
+	console.Println(report)
+	if report.unrepaired() > 0 {
+		os.Exit(1)
+	}
+}
+
+// fsck - checks the given local disks, removing orphaned part files if fix is set.
+func fsck(diskPaths []string, fix bool) fsckReport {
+	report := fsckReport{Disks: len(diskPaths)}
+
+	storageDisks := make([]StorageAPI, len(diskPaths))
+	for index, diskPath := range diskPaths {
+		// newPosix creates missing disk paths, which fsck must not do.
+		if _, err := os.Stat(diskPath); err != nil {
+			report.add(diskPath, "", fsckRepairReplace, "Unable to access disk: %s", err)
+			continue
+		}
+		disk, err := newPosix(diskPath)
+		if err != nil {
+			report.add(diskPath, "", fsckRepairReplace, "Unable to open disk: %s", err)
+			continue
+		}
+		storageDisks[index] = disk
+	}
+
+	if !fsckFormats(storageDisks, diskPaths, &report) {
+		return report
+	}
+	fsckObjects(storageDisks, diskPaths, fix, &report)
+	return report
+}
+
+// fsckFormats - cross-checks format.json on all disks, returns true if
+// the disks hold an XL backend whose objects should be checked.
+func fsckFormats(storageDisks []StorageAPI, diskPaths []string, report *fsckReport) bool {
+	formats, errs := loadAllFormats(storageDisks)
+
+	var xlFound bool
+	jbodCount := make(map[string]int)
+	for index, format := range formats {
+		if storageDisks[index] == nil {
+			continue
+		}
+		switch {
+		case errs[index] == errUnformattedDisk:
+			report.add(diskPaths[index], "", fsckRepairHealFormat, "format.json is missing on an empty disk.")
+		case errs[index] == errCorruptedFormat:
+			report.add(diskPaths[index], "", fsckRepairHealFormat, "format.json is missing on a disk holding data.")
+		case errs[index] != nil:
+			report.add(diskPaths[index], "", fsckRepairHealFormat, "Unable to read format.json: %s", errs[index])
+		case format.Format == "fs" && len(storageDisks) == 1:
+			// Nothing more to check for a single disk FS backend.
+			return false
+		case format.Format != "xl" || format.XL == nil:
+			report.add(diskPaths[index], "", fsckRepairReplace, "Unexpected backend format %q.", format.Format)
+			formats[index] = nil
+		default:
+			xlFound = true
+			jbodCount[strings.Join(format.XL.JBOD, ",")]++
+		}
+	}
+	if !xlFound {
+		return false
+	}
+
+	// The JBOD held by most disks is taken as the reference order.
+	var jbod []string
+	var maxCount int
+	for key, count := range jbodCount {
+		if count > maxCount || (count == maxCount && key < strings.Join(jbod, ",")) {
+			maxCount = count
+			jbod = strings.Split(key, ",")
+		}
+	}
+	if len(jbod) != len(storageDisks) {
+		report.add("", "", fsckRepairReplace, "format.json lists %d disks, %d were given.", len(jbod), len(storageDisks))
+	}
+
+	uuidDisks := make(map[string]string)
+	for index, format := range formats {
+		if format == nil {
+			continue
+		}
+		uuid := format.XL.Disk
+		if !reflect.DeepEqual(format.XL.JBOD, jbod) {
+			report.add(diskPaths[index], "", fsckRepairReplace, "JBOD order differs from the other disks.")
+		}
+		if findDiskIndex(uuid, jbod) == -1 {
+			report.add(diskPaths[index], "", fsckRepairReplace, "Disk UUID %s is not part of the JBOD.", uuid)
+		}
+		if other, ok := uuidDisks[uuid]; ok {
+			report.add(diskPaths[index], "", fsckRepairReplace, "Disk UUID %s is also used by %s.", uuid, other)
+		}
+		uuidDisks[uuid] = diskPaths[index]
+	}
+	return true
+}
+
+// fsckObjects - cross-checks xl.json and part files of all objects.
+func fsckObjects(storageDisks []StorageAPI, diskPaths []string, fix bool, report *fsckReport) {
+	buckets := make(map[string]struct{})
+	for index, disk := range storageDisks {
+		if disk == nil {
+			continue
+		}
+		vols, err := disk.ListVols()
+		if err != nil {
+			report.add(diskPaths[index], "", fsckRepairReplace, "Unable to list buckets: %s", err)
+			continue
+		}
+		for _, vol := range vols {
+			if vol.Name != minioMetaBucket {
+				buckets[vol.Name] = struct{}{}
+			}
+		}
+	}
+
+	for _, bucket := range sortedKeys(buckets) {
+		// Objects and leftover directories found on any disk.
+		objects := make(map[string]struct{})
+		for index, disk := range storageDisks {
+			if disk == nil {
+				continue
+			}
+			if err := fsckWalk(disk, bucket, "", objects); err != nil && err != errVolumeNotFound {
+				report.add(diskPaths[index], bucket, fsckRepairReplace, "Unable to walk bucket: %s", err)
+			}
+		}
+		for _, object := range sortedKeys(objects) {
+			fsckObject(storageDisks, diskPaths, bucket, object, fix, report)
+		}
+	}
+}
+
+// fsckWalk - collects all directories under prefix holding files, which
+// are objects or leftovers of incomplete writes.
+func fsckWalk(disk StorageAPI, bucket, prefix string, objects map[string]struct{}) error {
+	entries, err := disk.ListDir(bucket, prefix)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		if !strings.HasSuffix(entry, slashSeparator) {
+			if prefix != "" {
+				objects[strings.TrimSuffix(prefix, slashSeparator)] = struct{}{}
+			}
+			continue
+		}
+		if err = fsckWalk(disk, bucket, pathJoin(prefix, entry), objects); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// fsckObject - checks xl.json and the part files of a single object on all disks.
+func fsckObject(storageDisks []StorageAPI, diskPaths []string, bucket, object string, fix bool, report *fsckReport) {
+	objectPath := pathJoin(bucket, object)
+	metas := make([]xlMetaV1, len(storageDisks))
+	errs := make([]error, len(storageDisks))
+	var found int
+	for index, disk := range storageDisks {
+		if disk == nil {
+			errs[index] = errDiskNotFound
+			continue
+		}
+		var err error
+		metas[index], err = readXLMeta(disk, bucket, object)
+		errs[index] = errorCause(err)
+		if errs[index] == nil {
+			found++
+		}
+	}
+
+	// Directories without any xl.json only hold leftovers of incomplete writes.
+	if found > 0 {
+		report.Objects++
+	}
+
+	// Reference metadata is the one with the most common modification time.
+	modTimes := bootModtimes(len(metas))
+	for index := range metas {
+		if errs[index] == nil {
+			modTimes[index] = metas[index].Stat.ModTime
+		}
+	}
+	modTime := commonTime(modTimes)
+	var ref xlMetaV1
+	for index := range metas {
+		if errs[index] == nil && metas[index].Stat.ModTime.Equal(modTime) {
+			ref = metas[index]
+			break
+		}
+	}
+	if found > 0 && found < ref.Erasure.DataBlocks {
+		report.add("", objectPath, fsckRepairRestore, "xl.json found on %d disks, %d needed to read the object.",
+			found, ref.Erasure.DataBlocks)
+	}
+
+	for index, disk := range storageDisks {
+		if disk == nil {
+			continue
+		}
+		diskPath := diskPaths[index]
+		meta := metas[index]
+		switch {
+		case errs[index] == errFileNotFound:
+			if found > 0 {
+				report.add(diskPath, objectPath, fsckRepairHealObject, "xl.json is missing.")
+			}
+		case errs[index] != nil:
+			report.add(diskPath, objectPath, fsckRepairHealObject, "Unable to read xl.json: %s", errs[index])
+		case !meta.IsValid():
+			report.add(diskPath, objectPath, fsckRepairHealObject, "xl.json has unknown version %q or format %q.",
+				meta.Version, meta.Format)
+		case !meta.Stat.ModTime.Equal(ref.Stat.ModTime):
+			report.add(diskPath, objectPath, fsckRepairHealObject, "xl.json is stale, modified %s instead of %s.",
+				meta.Stat.ModTime, ref.Stat.ModTime)
+		case !fsckSameXLMeta(meta, ref):
+			report.add(diskPath, objectPath, fsckRepairHealObject, "xl.json differs from the other disks.")
+		}
+
+		// Part files referenced by the xl.json on this disk. Without
+		// one, parts of the reference xl.json are kept for healing.
+		referenced := make(map[string]bool)
+		for _, part := range ref.Parts {
+			referenced[part.Name] = true
+		}
+		if errs[index] == nil {
+			referenced = make(map[string]bool)
+			for _, part := range meta.Parts {
+				referenced[part.Name] = true
+				if _, err := meta.Erasure.GetCheckSumInfo(part.Name); err != nil {
+					report.add(diskPath, objectPath, fsckRepairHealObject, "xl.json has no checksum for %s.", part.Name)
+				}
+				if _, err := disk.StatFile(bucket, pathJoin(object, part.Name)); err != nil {
+					report.add(diskPath, objectPath, fsckRepairHealObject, "Part file %s is missing.", part.Name)
+				}
+			}
+		}
+
+		entries, err := disk.ListDir(bucket, object)
+		if err != nil {
+			if err != errFileNotFound {
+				report.add(diskPath, objectPath, fsckRepairReplace, "Unable to list object: %s", err)
+			}
+			continue
+		}
+		for _, entry := range entries {
+			if entry == xlMetaJSONFile || referenced[entry] || strings.HasSuffix(entry, slashSeparator) {
+				continue
+			}
+			report.add(diskPath, objectPath, fsckRepairRemove, "Part file %s is not referenced by xl.json.", entry)
+			if fix {
+				if err = disk.DeleteFile(bucket, pathJoin(object, entry)); err != nil {
+					report.Issues[len(report.Issues)-1].Problem += fmt.Sprintf(" Unable to remove it: %s", err)
+					continue
+				}
+				report.Issues[len(report.Issues)-1].Fixed = true
+			}
+		}
+	}
+}
+
+// fsckSameXLMeta - returns true if both xl.json agree on everything
+// but the per disk erasure index and checksums.
+func fsckSameXLMeta(meta, ref xlMetaV1) bool {
+	return meta.Stat.Size == ref.Stat.Size &&
+		meta.Erasure.Algorithm == ref.Erasure.Algorithm &&
+		meta.Erasure.DataBlocks == ref.Erasure.DataBlocks &&
+		meta.Erasure.ParityBlocks == ref.Erasure.ParityBlocks &&
+		meta.Erasure.BlockSize == ref.Erasure.BlockSize &&
+		reflect.DeepEqual(meta.Erasure.Distribution, ref.Erasure.Distribution) &&
+		reflect.DeepEqual(meta.Parts, ref.Parts)
+}
+
+// sortedKeys - returns the keys of a set in lexical order.
+func sortedKeys(set map[string]struct{}) []string {
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
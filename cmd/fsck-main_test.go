@@ -0,0 +1,155 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// Returns the problems of all issues reported on disk.
+func fsckProblems(report fsckReport, disk string) (problems []string) {
+	for _, issue := range report.Issues {
+		if issue.Disk == disk {
+			problems = append(problems, issue.Problem)
+		}
+	}
+	return problems
+}
+
+// Tests fsck on a consistent and on a damaged XL backend.
+func TestFsckXL(t *testing.T) {
+	root, err := newTestConfig("us-east-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer removeAll(root)
+
+	disks, err := getRandomDisks(4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer removeRoots(disks)
+
+	objLayer, storageDisks, err := initObjectLayer(disks, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = objLayer.MakeBucket("bucket"); err != nil {
+		t.Fatal(err)
+	}
+	data := bytes.Repeat([]byte("a"), 1024)
+	if _, err = objLayer.PutObject("bucket", "dir/object", int64(len(data)), bytes.NewReader(data), nil, ""); err != nil {
+		t.Fatal(err)
+	}
+
+	report := fsck(disks, false)
+	if len(report.Issues) != 0 || report.Objects != 1 || report.Disks != 4 {
+		t.Fatalf("Expected 4 disks, 1 object and no issues, got %s", report)
+	}
+
+	// Damage xl.json on the first disk and leave an orphaned part on the second.
+	if err = storageDisks[0].DeleteFile("bucket", "dir/object/xl.json"); err != nil {
+		t.Fatal(err)
+	}
+	if err = storageDisks[1].AppendFile("bucket", "dir/object/part.2", []byte("orphan")); err != nil {
+		t.Fatal(err)
+	}
+
+	// Assign the third disk a UUID outside of the JBOD.
+	format, err := loadFormat(storageDisks[2])
+	if err != nil {
+		t.Fatal(err)
+	}
+	format.XL.Disk = getUUID()
+	buf, err := json.Marshal(format)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = storageDisks[2].DeleteFile(minioMetaBucket, formatConfigFile); err != nil {
+		t.Fatal(err)
+	}
+	if err = storageDisks[2].AppendFile(minioMetaBucket, formatConfigFile, buf); err != nil {
+		t.Fatal(err)
+	}
+
+	report = fsck(disks, false)
+	if len(report.Issues) != 3 || report.unrepaired() != 3 {
+		t.Fatalf("Expected 3 unrepaired issues, got %s", report)
+	}
+	if problems := fsckProblems(report, disks[0]); len(problems) != 1 || problems[0] != "xl.json is missing." {
+		t.Errorf("Unexpected issues on first disk %v", problems)
+	}
+	if problems := fsckProblems(report, disks[1]); len(problems) != 1 || !strings.Contains(problems[0], "part.2") {
+		t.Errorf("Unexpected issues on second disk %v", problems)
+	}
+	if problems := fsckProblems(report, disks[2]); len(problems) != 1 || !strings.Contains(problems[0], "not part of the JBOD") {
+		t.Errorf("Unexpected issues on third disk %v", problems)
+	}
+
+	// Only the orphaned part is fixed.
+	report = fsck(disks, true)
+	if len(report.Issues) != 3 || report.unrepaired() != 2 {
+		t.Fatalf("Expected 2 unrepaired issues, got %s", report)
+	}
+	if _, err = os.Stat(filepath.Join(disks[1], "bucket", "dir", "object", "part.2")); !os.IsNotExist(err) {
+		t.Errorf("Expected orphaned part to be removed, got %v", err)
+	}
+	if report = fsck(disks, false); len(report.Issues) != 2 {
+		t.Errorf("Expected 2 issues after fix, got %s", report)
+	}
+}
+
+// Tests fsck on missing, unformatted and FS disks.
+func TestFsckDisks(t *testing.T) {
+	root, err := newTestConfig("us-east-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer removeAll(root)
+
+	// A single disk FS backend has no objects to check.
+	fsDir, err := getRandomDisks(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer removeRoots(fsDir)
+	if _, _, err = initObjectLayer(fsDir, nil); err != nil {
+		t.Fatal(err)
+	}
+	if report := fsck(fsDir, false); len(report.Issues) != 0 {
+		t.Errorf("Expected no issues on FS backend, got %s", report)
+	}
+
+	// Unformatted and missing disks are reported without being created.
+	xlDirs, err := getRandomDisks(2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer removeRoots(xlDirs)
+	missing := filepath.Join(xlDirs[1], "missing")
+	report := fsck([]string{xlDirs[0], missing}, false)
+	if len(report.Issues) != 2 {
+		t.Fatalf("Expected 2 issues, got %s", report)
+	}
+	if _, err = os.Stat(missing); !os.IsNotExist(err) {
+		t.Errorf("Expected missing disk to stay missing, got %v", err)
+	}
+}
@@ -18,7 +18,6 @@ package cmd
 
 import (
 	"net/http"
-	"os"
 	"path"
 	"regexp"
 	"strings"
@@ -73,11 +72,11 @@ const (
 )
 
 func setBrowserRedirectHandler(h http.Handler) http.Handler {
-	return redirectHandler{handler: h, locationPrefix: reservedBucket}
+	return redirectHandler{handler: h, locationPrefix: globalBrowserPrefix}
 }
 
 func (h redirectHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
-	if !strings.EqualFold(os.Getenv("MINIO_BROWSER"), "off") {
+	if globalBrowserEnabled {
 		// Re-direction handled specifically for browsers.
 		if strings.Contains(r.Header.Get("User-Agent"), "Mozilla") && !isRequestSignatureV4(r) {
 			switch r.URL.Path {
@@ -113,7 +112,7 @@ func setBrowserCacheControlHandler(h http.Handler) http.Handler {
 func (h cacheControlHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	if r.Method == "GET" && strings.Contains(r.Header.Get("User-Agent"), "Mozilla") {
 		// For all browser requests set appropriate Cache-Control policies
-		match, e := regexp.MatchString(reservedBucket+`/([^/]+\.js|favicon.ico)`, r.URL.Path)
+		match, e := regexp.MatchString(regexp.QuoteMeta(globalBrowserPrefix)+`/([^/]+\.js|favicon.ico)`, r.URL.Path)
 		if e != nil {
 			writeErrorResponse(w, r, ErrInternalError, r.URL.Path)
 			return
@@ -122,9 +121,10 @@ func (h cacheControlHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 			// For assets set cache expiry of one year. For each release, the name
 			// of the asset name will change and hence it can not be served from cache.
 			w.Header().Set("Cache-Control", "max-age=31536000")
-		} else if strings.HasPrefix(r.URL.Path, reservedBucket+"/") {
-			// For non asset requests we serve index.html which will never be cached.
-			w.Header().Set("Cache-Control", "no-store")
+		} else if strings.HasPrefix(r.URL.Path, globalBrowserPrefix+"/") {
+			// For non asset requests we serve index.html which is
+			// revalidated by its ETag on every request.
+			w.Header().Set("Cache-Control", "no-cache")
 		}
 	}
 	h.handler.ServeHTTP(w, r)
@@ -17,6 +17,7 @@
 package cmd
 
 import (
+	"net/url"
 	"time"
 
 	"github.com/fatih/color"
@@ -30,7 +31,7 @@ const (
 
 // minio configuration related constants.
 const (
-	globalMinioConfigVersion      = "9"
+	globalMinioConfigVersion      = "10"
 	globalMinioConfigDir          = ".minio"
 	globalMinioCertsDir           = "certs"
 	globalMinioCertFile           = "public.crt"
@@ -42,6 +43,7 @@ const (
 
 var (
 	globalQuiet = false // Quiet flag set via command line
+	globalJSON  = false // JSON flag set via command line
 	globalTrace = false // Trace flag set via environment setting.
 
 	// Add new global flags here.
@@ -55,10 +57,88 @@ var (
 	globalCacheExpiry = objcache.DefaultExpiry
 	// Minio local server address (in `host:port` format)
 	globalMinioAddr = ""
+	// Minio server endpoint (in `scheme://ip:port` format) this node
+	// serves requests on, not influenced by any request.
+	globalMinioEndpoint = ""
 	// Minio default port, can be changed through command line.
 	globalMinioPort = 9000
 	// Peer communication struct
 	globalS3Peers = s3Peers{}
+	// Strict AWS ETag parity for multipart objects, enabled
+	// by setting MINIO_STRICT_ETAG=on.
+	globalStrictETag = false
+	// Peers holding bucket configs which differ from the stored
+	// ones get them again, enabled by setting MINIO_CONFIG_REPAIR=on.
+	globalConfigRepair = false
+	// Master key sealing the keys of objects encrypted server side,
+	// set by MINIO_SSE_MASTER_KEY.
+	globalSSEMasterKey []byte
+	// Previous master keys, only used to read objects until their
+	// keys are rotated, set by MINIO_SSE_OLD_MASTER_KEYS.
+	globalSSEOldMasterKeys [][]byte
+	// Duration to wait for in-flight requests to complete
+	// upon stop or restart, before forcibly closing them.
+	globalShutdownGracePeriod = 5 * time.Second
+	// Minio browser is enabled unless MINIO_BROWSER=off.
+	globalBrowserEnabled = true
+	// URL prefix the browser and its JSON-RPC endpoint are served
+	// from, can be changed by setting MINIO_BROWSER_PREFIX.
+	globalBrowserPrefix = reservedBucket
+	// Log server computed canonical request and string to sign
+	// on signature mismatch, enabled by MINIO_SIGNATURE_DEBUG=on.
+	globalSignatureDebug = false
+	// Allow faults to be injected into storage and RPC calls
+	// with 'minio control fault', enabled by MINIO_FAULT_INJECTION=on.
+	globalFaultInjection = false
+	// Percentage of disk space and inodes beyond which writes are
+	// rejected, set by MINIO_DISK_HIGH_WATERMARK. Disabled when 0.
+	globalDiskHighWatermark = 0
+	// Minimum number of disks required for XL reads and writes, set
+	// by MINIO_READ_QUORUM and MINIO_WRITE_QUORUM. Defaults when 0.
+	globalReadQuorum  = 0
+	globalWriteQuorum = 0
+	// Limits on bucket creation, set by MINIO_MAX_BUCKETS,
+	// MINIO_BUCKET_NAME_PATTERN and MINIO_BUCKET_NAME_PREFIX.
+	globalBucketCreationPolicy = bucketCreationPolicy{}
+	// Source IPs are locked out after repeated authentication failures
+	// unless MINIO_AUTH_LOCKOUT=off, access keys only if set to 'keys'.
+	globalAuthLockoutEnabled = true
+	globalAuthLockoutKeys    = false
+	// Requirements for new secret keys, set by MINIO_SECRET_KEY_MIN_LENGTH
+	// and MINIO_SECRET_KEY_MIN_ENTROPY.
+	globalSecretKeyPolicy = defaultSecretKeyPolicy
+	// Passphrase encrypting secrets in config.json, set by
+	// MINIO_CONFIG_PASSPHRASE. Secrets are saved in plaintext when empty.
+	globalConfigPassphrase = ""
+	// Restricted crypto mode limits TLS to strong cipher suites and uses
+	// SHA-256 instead of MD5 for ETags, set by MINIO_RESTRICTED_CRYPTO
+	// and always on in builds with the fips tag.
+	globalRestrictedCrypto = restrictedCryptoBuild
+	// Public URL of this cluster in a federation of clusters sharing
+	// one bucket namespace, set by MINIO_FEDERATION_ENDPOINT. Disabled
+	// when nil.
+	globalFederationEndpoint *url.URL
+	// URL of a node of the federation coordinator, set by
+	// MINIO_FEDERATION_COORDINATOR. This cluster coordinates when nil.
+	globalFederationCoordinator *url.URL
+	// Requests for buckets owned by another cluster are redirected
+	// unless MINIO_FEDERATION_MODE=proxy.
+	globalFederationMode = federationRedirect
+	// Client of the etcd cluster storing config.json and the owners of
+	// federated buckets, set by MINIO_ETCD_ENDPOINTS. Disabled when nil.
+	globalEtcd *etcdClient
+	// Durability mode of metadata writes, set by MINIO_DURABILITY.
+	// Commits are left to the OS unless it is sync or batch.
+	globalDurabilityMode = durabilityOff
+	// Object data files are written with direct IO beyond
+	// globalDirectIOMinSize bytes, set by MINIO_DIRECT_IO and
+	// MINIO_DIRECT_IO_MIN_SIZE.
+	globalDirectIO        = false
+	globalDirectIOMinSize = int64(defaultDirectIOMinSize)
+
+	// Identity of this server, bound to the RPC tokens it issues so
+	// that they are not accepted by other nodes.
+	globalNodeID = getUUID()
 
 	// Add new variable global values here.
 )
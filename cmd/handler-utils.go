@@ -63,6 +63,7 @@ var supportedHeaders = []string{
 	"cache-control",
 	"content-encoding",
 	"content-disposition",
+	"x-amz-server-side-encryption",
 	// Add more supported headers here.
 }
 
@@ -0,0 +1,190 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package cmd
+
+import (
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"hash"
+	"io/ioutil"
+	"runtime"
+	"strings"
+
+	sha256simd "github.com/minio/sha256-simd"
+)
+
+// SHA-256 implementations payload hashing of signature V4 requests
+// can use, the one fastest on the CPU of the host is selected at
+// startup.
+const (
+	// Vectorized with AVX2, AVX or SSSE3 on amd64.
+	sha256ImplSIMD = "sha256-simd"
+	// Uses the SHA extensions on amd64 and arm64, generic otherwise.
+	sha256ImplStdlib = "crypto/sha256"
+)
+
+// CPU features relevant to hashing, as reported by getCPUFeatures.
+const (
+	cpuFeatureSHA   = "sha"
+	cpuFeatureAVX2  = "avx2"
+	cpuFeatureAVX   = "avx"
+	cpuFeatureSSSE3 = "ssse3"
+	cpuFeatureNEON  = "neon"
+)
+
+// errInvalidSHA256Impl - MINIO_SHA256_IMPL names no implementation.
+var errInvalidSHA256Impl = errors.New("SHA-256 implementation must be 'sha256-simd' or 'crypto/sha256'")
+
+// sha256Impl - a SHA-256 implementation and why it was selected.
+type sha256Impl struct {
+	Name   string
+	Reason string
+	New    func() hash.Hash
+}
+
+// Variable holding the SHA-256 implementation used for payload hashing.
+var globalSHA256Impl = selectSHA256Impl(getCPUFeatures())
+
+// newSHA256 - returns a new SHA-256 hash of the selected implementation.
+func newSHA256() hash.Hash {
+	return globalSHA256Impl.New()
+}
+
+// Returns the CPU features relevant to hashing, from /proc/cpuinfo on
+// linux. Other platforms report no features and use crypto/sha256.
+func getCPUFeatures() []string {
+	if runtime.GOOS != "linux" {
+		return nil
+	}
+	cpuinfo, err := ioutil.ReadFile("/proc/cpuinfo")
+	if err != nil {
+		return nil
+	}
+	return parseCPUFeatures(string(cpuinfo))
+}
+
+// Parses the flags of x86 CPUs and the features of ARM CPUs listed in
+// /proc/cpuinfo, the first CPU is representative of all of them.
+func parseCPUFeatures(cpuinfo string) []string {
+	names := map[string]string{
+		// x86 'flags'.
+		"sha_ni": cpuFeatureSHA,
+		"avx2":   cpuFeatureAVX2,
+		"avx":    cpuFeatureAVX,
+		"ssse3":  cpuFeatureSSSE3,
+		// ARM 'Features'.
+		"sha2":  cpuFeatureSHA,
+		"asimd": cpuFeatureNEON,
+		"neon":  cpuFeatureNEON,
+	}
+	for _, line := range strings.Split(cpuinfo, "\n") {
+		idx := strings.Index(line, ":")
+		if idx == -1 {
+			continue
+		}
+		key := strings.TrimSpace(line[:idx])
+		if key != "flags" && key != "Features" {
+			continue
+		}
+		var features []string
+		for _, flag := range strings.Fields(line[idx+1:]) {
+			feature, ok := names[flag]
+			if ok && !hasCPUFeature(features, feature) {
+				features = append(features, feature)
+			}
+		}
+		return features
+	}
+	return nil
+}
+
+// Returns true if feature is one of features.
+func hasCPUFeature(features []string, feature string) bool {
+	for _, f := range features {
+		if f == feature {
+			return true
+		}
+	}
+	return false
+}
+
+// Returns the vector extension sha256-simd and blake2b-simd use on
+// a CPU with features, empty if they fall back to generic code.
+func getSIMDExtension(features []string) string {
+	if runtime.GOARCH != "amd64" {
+		return ""
+	}
+	for _, feature := range []string{cpuFeatureAVX2, cpuFeatureAVX, cpuFeatureSSSE3} {
+		if hasCPUFeature(features, feature) {
+			return strings.ToUpper(feature)
+		}
+	}
+	return ""
+}
+
+// Selects the SHA-256 implementation fastest on a CPU with features,
+// SHA extensions beat the vector extensions by far.
+func selectSHA256Impl(features []string) sha256Impl {
+	if hasCPUFeature(features, cpuFeatureSHA) {
+		return newSHA256Impl(sha256ImplStdlib, "SHA extensions")
+	}
+	if extension := getSIMDExtension(features); extension != "" {
+		return newSHA256Impl(sha256ImplSIMD, extension)
+	}
+	return newSHA256Impl(sha256ImplStdlib, "generic")
+}
+
+// Returns the SHA-256 implementation name, overriding the selection.
+func parseSHA256Impl(name string, features []string) (sha256Impl, error) {
+	switch name {
+	case sha256ImplStdlib:
+		if hasCPUFeature(features, cpuFeatureSHA) {
+			return newSHA256Impl(name, "SHA extensions"), nil
+		}
+		return newSHA256Impl(name, "generic"), nil
+	case sha256ImplSIMD:
+		if extension := getSIMDExtension(features); extension != "" {
+			return newSHA256Impl(name, extension), nil
+		}
+		return newSHA256Impl(name, "generic"), nil
+	}
+	return sha256Impl{}, errInvalidSHA256Impl
+}
+
+func newSHA256Impl(name, reason string) sha256Impl {
+	impl := sha256Impl{Name: name, Reason: reason, New: sha256.New}
+	if name == sha256ImplSIMD {
+		impl.New = sha256simd.New
+	}
+	return impl
+}
+
+// Describes the implementation blake2b-simd uses for bitrot hashing.
+func getBitrotImpl(features []string) string {
+	if extension := getSIMDExtension(features); extension != "" {
+		return fmt.Sprintf("blake2b-simd (%s)", extension)
+	}
+	return "blake2b-simd (generic)"
+}
+
+// getHashingInfo - describes the hashing implementations for ServerInfo.
+func getHashingInfo() string {
+	return fmt.Sprintf("SHA-256: %s (%s) | Bitrot: %s",
+		globalSHA256Impl.Name,
+		globalSHA256Impl.Reason,
+		getBitrotImpl(getCPUFeatures()))
+}
@@ -0,0 +1,112 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package cmd
+
+import (
+	"bytes"
+	"reflect"
+	"runtime"
+	"testing"
+)
+
+// Tests parsing the hashing CPU features of /proc/cpuinfo.
+func TestParseCPUFeatures(t *testing.T) {
+	testCases := []struct {
+		cpuinfo  string
+		features []string
+	}{
+		// x86 CPU with SHA extensions, only the first CPU is parsed.
+		{
+			"processor\t: 0\nflags\t\t: fpu sse2 ssse3 sse4_1 avx avx2 sha_ni\n\nprocessor\t: 1\nflags\t\t: fpu\n",
+			[]string{cpuFeatureSSSE3, cpuFeatureAVX, cpuFeatureAVX2, cpuFeatureSHA},
+		},
+		// x86 CPU without vector extensions.
+		{"flags\t\t: fpu sse2\n", nil},
+		// ARM CPU with SHA2 and NEON.
+		{"processor\t: 0\nFeatures\t: fp asimd aes sha1 sha2 crc32\n", []string{cpuFeatureNEON, cpuFeatureSHA}},
+		// No features listed.
+		{"processor\t: 0\n", nil},
+		{"", nil},
+	}
+	for i, testCase := range testCases {
+		features := parseCPUFeatures(testCase.cpuinfo)
+		if !reflect.DeepEqual(features, testCase.features) {
+			t.Errorf("Test %d: Expected features %v, got %v", i+1, testCase.features, features)
+		}
+	}
+}
+
+// Tests selecting the SHA-256 implementation from the CPU features.
+func TestSelectSHA256Impl(t *testing.T) {
+	simd := sha256ImplStdlib
+	simdReason := "generic"
+	if runtime.GOARCH == "amd64" {
+		simd = sha256ImplSIMD
+		simdReason = "AVX2"
+	}
+	testCases := []struct {
+		features []string
+		name     string
+		reason   string
+	}{
+		{[]string{cpuFeatureAVX2, cpuFeatureSHA}, sha256ImplStdlib, "SHA extensions"},
+		{[]string{cpuFeatureNEON, cpuFeatureSHA}, sha256ImplStdlib, "SHA extensions"},
+		{[]string{cpuFeatureSSSE3, cpuFeatureAVX, cpuFeatureAVX2}, simd, simdReason},
+		{[]string{cpuFeatureNEON}, sha256ImplStdlib, "generic"},
+		{nil, sha256ImplStdlib, "generic"},
+	}
+	for i, testCase := range testCases {
+		impl := selectSHA256Impl(testCase.features)
+		if impl.Name != testCase.name || impl.Reason != testCase.reason {
+			t.Errorf("Test %d: Expected %s (%s), got %s (%s)", i+1, testCase.name, testCase.reason, impl.Name, impl.Reason)
+		}
+	}
+
+	if _, err := parseSHA256Impl("sha512", nil); err != errInvalidSHA256Impl {
+		t.Errorf("Expected %v, got %v", errInvalidSHA256Impl, err)
+	}
+	impl, err := parseSHA256Impl(sha256ImplSIMD, []string{cpuFeatureSHA})
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	if impl.Name != sha256ImplSIMD {
+		t.Errorf("Expected %s, got %s", sha256ImplSIMD, impl.Name)
+	}
+}
+
+// Tests all the SHA-256 implementations compute the same sums.
+func TestSHA256Impls(t *testing.T) {
+	data := bytes.Repeat([]byte("minio"), 100*1024)
+	var sums [][]byte
+	for _, name := range []string{sha256ImplSIMD, sha256ImplStdlib} {
+		impl, err := parseSHA256Impl(name, getCPUFeatures())
+		if err != nil {
+			t.Fatal("Unexpected error:", err)
+		}
+		h := impl.New()
+		h.Write(data)
+		sums = append(sums, h.Sum(nil))
+	}
+	if !bytes.Equal(sums[0], sums[1]) {
+		t.Fatalf("Mismatching sums %x and %x", sums[0], sums[1])
+	}
+
+	for _, b := range getHashBenchmarks(globalSHA256Impl) {
+		if runHashBenchmark(b.New, data) <= 0 {
+			t.Errorf("Expected a positive throughput of %s", b.Name)
+		}
+	}
+}
@@ -0,0 +1,103 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package cmd
+
+import (
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// Minio extension, ListObjects responses include the user metadata
+// of every listed object when requests set this header to "metadata",
+// sparing clients a HEAD request per object. PutObject expands the
+// uploaded archive into objects when it is set to "true".
+const extractHeader = "X-Minio-Extract"
+
+// MetadataEntry - user metadata entry of an object, returned by
+// ListObjects with X-Minio-Extract.
+type MetadataEntry struct {
+	Name  string
+	Value string
+}
+
+// UserMetadata - user metadata of an object, returned by ListObjects
+// with X-Minio-Extract.
+type UserMetadata struct {
+	Entries []MetadataEntry `xml:"Entry"`
+}
+
+// isListMetadataRequested - returns if the request asks for the user
+// metadata of the listed objects.
+func isListMetadataRequested(r *http.Request) bool {
+	return strings.EqualFold(r.Header.Get(extractHeader), "metadata")
+}
+
+// toMetadataEntries - returns metadata as entries sorted by name.
+func toMetadataEntries(metadata map[string]string) []MetadataEntry {
+	entries := []MetadataEntry{}
+	for name, value := range metadata {
+		entries = append(entries, MetadataEntry{name, value})
+	}
+	sort.Sort(byMetadataName(entries))
+	return entries
+}
+
+// byMetadataName - sorts metadata entries by name.
+type byMetadataName []MetadataEntry
+
+func (m byMetadataName) Len() int           { return len(m) }
+func (m byMetadataName) Swap(i, j int)      { m[i], m[j] = m[j], m[i] }
+func (m byMetadataName) Less(i, j int) bool { return m[i].Name < m[j].Name }
+
+// addListObjectsMetadata - sets the user metadata of the listed
+// objects on contents, the same metadata HEAD returns as headers.
+// Metadata missing from the listing is read from the backend. For
+// anonymous requests it is only added for objects the bucket policy
+// allows to be read.
+func addListObjectsMetadata(r *http.Request, objAPI ObjectLayer, bucket string, objects []ObjectInfo, contents []Object) error {
+	anonymous := getRequestAuthType(r) == authTypeAnonymous
+	metadata := make(map[string]map[string]string)
+	for _, object := range objects {
+		if anonymous {
+			objectURL := &url.URL{Path: "/" + bucket + "/" + object.Name}
+			if enforceBucketPolicy(bucket, "s3:GetObject", objectURL) != ErrNone {
+				continue
+			}
+		}
+		userDefined := object.UserDefined
+		if userDefined == nil {
+			objInfo, err := objAPI.GetObjectInfo(bucket, object.Name)
+			if err != nil {
+				// Objects deleted since they were listed are left
+				// without metadata.
+				if _, ok := errorCause(err).(ObjectNotFound); ok {
+					continue
+				}
+				return err
+			}
+			userDefined = objInfo.UserDefined
+		}
+		metadata[object.Name] = userDefined
+	}
+	for i := range contents {
+		if userDefined, ok := metadata[contents[i].Key]; ok {
+			contents[i].UserMetadata = &UserMetadata{toMetadataEntries(userDefined)}
+		}
+	}
+	return nil
+}
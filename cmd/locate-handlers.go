@@ -0,0 +1,139 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package cmd
+
+import (
+	"errors"
+	"path"
+	"sync"
+)
+
+// errLocateNotXL - object location was requested on a server which is
+// not running the XL backend.
+var errLocateNotXL = errors.New("Object location is only available for the XL backend.")
+
+// LocateObjectArgs - arguments for LocateObject RPC.
+type LocateObjectArgs struct {
+	// Authentication token generated by Login.
+	GenericArgs
+
+	Bucket string
+	Object string
+}
+
+// ObjectDiskLocation - describes an object on a single disk of the
+// erasure set.
+type ObjectDiskLocation struct {
+	// Disk, remote disks are in `host:path` format.
+	Disk string
+	// Erasure block index this disk is assigned by the object name.
+	BlockIndex int
+	// Erasure block index recorded in `xl.json` on this disk, zero if
+	// the disk has no `xl.json` for the object.
+	StoredIndex int
+	// Parts listed in `xl.json` present on this disk.
+	Parts []string
+	// Parts listed in `xl.json` missing on this disk.
+	MissingParts []string
+	// Error reading the object from this disk, empty if the disk holds
+	// the object or does not have it at all.
+	Err string
+}
+
+// LocateObjectReply - reply by LocateObject RPC.
+type LocateObjectReply struct {
+	// Erasure set hosting the object, all the disks form a single set.
+	Set int
+	// Disks of the erasure set, in the order of format.json.
+	Disks []ObjectDiskLocation
+}
+
+// Returns the parts of xlMeta which are present and missing on disk.
+func statObjectParts(disk StorageAPI, bucket, object string, xlMeta xlMetaV1) (parts, missingParts []string) {
+	for _, part := range xlMeta.Parts {
+		if _, err := disk.StatFile(bucket, path.Join(object, part.Name)); err != nil {
+			missingParts = append(missingParts, part.Name)
+			continue
+		}
+		parts = append(parts, part.Name)
+	}
+	return parts, missingParts
+}
+
+// Returns the location of object on each of disks, which are in the
+// order of format.json.
+func locateObject(disks []StorageAPI, bucket, object string) []ObjectDiskLocation {
+	distribution := hashOrder(object, len(disks))
+	partsMetadata, errs := readAllXLMetadata(disks, bucket, object)
+
+	locations := make([]ObjectDiskLocation, len(disks))
+	var wg = &sync.WaitGroup{}
+	for index, disk := range disks {
+		locations[index].BlockIndex = distribution[index]
+		if disk == nil {
+			locations[index].Err = errDiskNotFound.Error()
+			continue
+		}
+		locations[index].Disk = disk.String()
+		err := errorCause(errs[index])
+		if err == errFileNotFound {
+			continue
+		}
+		if err != nil {
+			locations[index].Err = err.Error()
+			continue
+		}
+		locations[index].StoredIndex = partsMetadata[index].Erasure.Index
+		wg.Add(1)
+		go func(index int, disk StorageAPI) {
+			defer wg.Done()
+			location := &locations[index]
+			location.Parts, location.MissingParts = statObjectParts(disk, bucket, object, partsMetadata[index])
+		}(index, disk)
+	}
+	wg.Wait()
+	return locations
+}
+
+// LocateObjectHandler - RPC control handler for `minio control locate`.
+// Reports the disks which host an object by its name and the disks
+// which currently hold its parts.
+func (c *controlAPIHandlers) LocateObjectHandler(args *LocateObjectArgs, reply *LocateObjectReply) (err error) {
+	defer encodeRPCError(&err)
+
+	if !isRPCTokenValid(args.Token, jwtAudienceAdmin) {
+		return errInvalidToken
+	}
+	if !c.IsXL {
+		return errLocateNotXL
+	}
+	if !IsValidBucketName(args.Bucket) || !IsValidObjectName(args.Object) {
+		return errInvalidArgument
+	}
+	disks, err := loadFormatXL(c.StorageDisks, len(c.StorageDisks)/2)
+	if err != nil {
+		return err
+	}
+
+	opsID := getOpsID()
+	nsMutex.RLock(args.Bucket, args.Object, opsID)
+	defer nsMutex.RUnlock(args.Bucket, args.Object, opsID)
+
+	*reply = LocateObjectReply{
+		Disks: locateObject(disks, args.Bucket, args.Object),
+	}
+	return nil
+}
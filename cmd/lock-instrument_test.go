@@ -19,6 +19,8 @@ package cmd
 import (
 	"testing"
 	"time"
+
+	"github.com/mf-00/newgo/pkg/locktest"
 )
 
 type lockStateCase struct {
@@ -41,81 +43,35 @@ type lockStateCase struct {
 	expectedVolPathBlockCount   int // Total locks blocked on the given <volume, path> pair.
 }
 
+// toLockTestCase - converts the case for the locktest helpers.
+func (l lockStateCase) toLockTestCase() locktest.Case {
+	lockType := locktest.WriteLock
+	if l.readLock {
+		lockType = locktest.ReadLock
+	}
+	return locktest.Case{
+		Volume:       l.volume,
+		Path:         l.path,
+		OpsID:        l.opsID,
+		LockType:     lockType,
+		Status:       string(l.expectedLockStatus),
+		GlobalLocks:  l.expectedGlobalLockCount,
+		BlockedLocks: l.expectedBlockedLockCount,
+		RunningLocks: l.expectedRunningLockCount,
+		PathLocks:    l.expectedVolPathLockCount,
+		PathBlocked:  l.expectedVolPathBlockCount,
+		PathRunning:  l.expectedVolPathRunningCount,
+	}
+}
+
 // Used for validating the Lock info obtaining from contol RPC end point for obtaining lock related info.
 func verifyRPCLockInfoResponse(l lockStateCase, rpcLockInfoMap map[string]*SystemLockState, t TestErrHandler, testNum int) {
 	for _, rpcLockInfoResponse := range rpcLockInfoMap {
-		// Assert the total number of locks (locked + acquired) in the system.
-		if rpcLockInfoResponse.TotalLocks != int64(l.expectedGlobalLockCount) {
-			t.Fatalf("Test %d: Expected the global lock counter to be %v, but got %v", testNum, int64(l.expectedGlobalLockCount),
-				rpcLockInfoResponse.TotalLocks)
-		}
-
-		// verify the count for total blocked locks.
-		if rpcLockInfoResponse.TotalBlockedLocks != int64(l.expectedBlockedLockCount) {
-			t.Fatalf("Test %d: Expected the total blocked lock counter to be %v, but got %v", testNum, int64(l.expectedBlockedLockCount),
-				rpcLockInfoResponse.TotalBlockedLocks)
-		}
-
-		// verify the count for total running locks.
-		if rpcLockInfoResponse.TotalAcquiredLocks != int64(l.expectedRunningLockCount) {
-			t.Fatalf("Test %d: Expected the total running lock counter to be %v, but got %v", testNum, int64(l.expectedRunningLockCount),
-				rpcLockInfoResponse.TotalAcquiredLocks)
+		state, err := locktest.ParseState(rpcLockInfoResponse)
+		if err != nil {
+			t.Fatalf("Test %d: Unable to parse the lock state, %v", testNum, err)
 		}
-
-		for _, locksInfoPerObject := range rpcLockInfoResponse.LocksInfoPerObject {
-			// See whether the entry for the <bucket, object> exists in the RPC response.
-			if locksInfoPerObject.Bucket == l.volume && locksInfoPerObject.Object == l.path {
-				// Assert the total number of locks (blocked + acquired) for the given <buckt, object> pair.
-				if locksInfoPerObject.LocksOnObject != int64(l.expectedVolPathLockCount) {
-					t.Errorf("Test %d: Expected the total lock count for bucket: \"%s\", object: \"%s\" to be %v, but got %v", testNum,
-						l.volume, l.path, int64(l.expectedVolPathLockCount), locksInfoPerObject.LocksOnObject)
-				}
-				// Assert the total number of acquired locks for the given <buckt, object> pair.
-				if locksInfoPerObject.LocksAcquiredOnObject != int64(l.expectedVolPathRunningCount) {
-					t.Errorf("Test %d: Expected the acquired lock count for bucket: \"%s\", object: \"%s\" to be %v, but got %v", testNum,
-						l.volume, l.path, int64(l.expectedVolPathRunningCount), locksInfoPerObject.LocksAcquiredOnObject)
-				}
-				// Assert the total number of blocked locks for the given <buckt, object> pair.
-				if locksInfoPerObject.TotalBlockedLocks != int64(l.expectedVolPathBlockCount) {
-					t.Errorf("Test %d: Expected the blocked lock count for bucket: \"%s\", object: \"%s\" to be %v, but got %v", testNum,
-						l.volume, l.path, int64(l.expectedVolPathBlockCount), locksInfoPerObject.TotalBlockedLocks)
-				}
-				// Flag to mark whether there's an entry in the RPC lock info response for given opsID.
-				var opsIDfound bool
-				for _, opsLockState := range locksInfoPerObject.LockDetailsOnObject {
-					// first check whether the entry for the given operation ID exists.
-					if opsLockState.OperationID == l.opsID {
-						opsIDfound = true
-						// asserting the type  of lock (RLock/WLock) from the RPC lock info response.
-						if l.readLock {
-							if opsLockState.LockType != debugRLockStr {
-								t.Errorf("Test case %d: Expected the lock type to be \"%s\"", testNum, debugRLockStr)
-							}
-						} else {
-							if opsLockState.LockType != debugWLockStr {
-								t.Errorf("Test case %d: Expected the lock type to be \"%s\"", testNum, debugWLockStr)
-							}
-						}
-
-						if opsLockState.Status != l.expectedLockStatus {
-							t.Errorf("Test case %d: Expected the  status of the operation to be \"%s\", got \"%s\"", testNum, l.expectedLockStatus, opsLockState.Status)
-						}
-
-						// all check satisfied, return here.
-						// Any mismatch in the earlier checks would have ended the tests due to `Fatalf`,
-						// control reaching here implies that all checks are satisfied.
-						return
-					}
-				}
-				// opsID not found.
-				// No entry for an operation with given operation ID exists.
-				if !opsIDfound {
-					t.Fatalf("Test case %d: Entry for OpsId: \"%s\" not found in <bucket>: \"%s\", <path>: \"%s\" doesn't exist in the RPC response", testNum, l.opsID, l.volume, l.path)
-				}
-			}
-		}
-		// No entry exists for given <bucket, object> pair in the RPC response.
-		t.Errorf("Test case %d: Entry for <bucket>: \"%s\", <object>: \"%s\" doesn't exist in the RPC response", testNum, l.volume, l.path)
+		locktest.Verify(t, testNum, l.toLockTestCase(), state)
 	}
 }
 
@@ -140,26 +96,15 @@ func verifyGlobalLockStats(l lockStateCase, t *testing.T, testNum int) {
 	}
 	nsMutex.lockMapMutex.Unlock()
 	// Verifying again with the JSON response of the lock info.
-	// Verifying the lock stats.
 	sysLockState, err := getSystemLockState()
 	if err != nil {
 		t.Fatalf("Obtaining lock info failed with <ERROR> %s", err)
-
 	}
-	if sysLockState.TotalLocks != int64(l.expectedGlobalLockCount) {
-		t.Errorf("Test %d: Expected the global lock counter to be %v, but got %v", testNum, int64(l.expectedGlobalLockCount),
-			sysLockState.TotalLocks)
-	}
-	// verify the count for total blocked locks.
-	if sysLockState.TotalBlockedLocks != int64(l.expectedBlockedLockCount) {
-		t.Errorf("Test %d: Expected the total blocked lock counter to be %v, but got %v", testNum, int64(l.expectedBlockedLockCount),
-			sysLockState.TotalBlockedLocks)
-	}
-	// verify the count for total running locks.
-	if sysLockState.TotalAcquiredLocks != int64(l.expectedRunningLockCount) {
-		t.Errorf("Test %d: Expected the total running lock counter to be %v, but got %v", testNum, int64(l.expectedRunningLockCount),
-			sysLockState.TotalAcquiredLocks)
+	state, err := locktest.ParseState(sysLockState)
+	if err != nil {
+		t.Fatalf("Test %d: Unable to parse the lock state, %v", testNum, err)
 	}
+	locktest.VerifyCounts(t, testNum, l.toLockTestCase(), state)
 }
 
 // Verify the lock counter for entries of given <volume, path> pair.
@@ -63,7 +63,7 @@ func (l *lockServer) validateLockArgs(args *LockArgs) error {
 	if curTime.Sub(args.Timestamp) > globalMaxSkewTime {
 		return errServerTimeMismatch
 	}
-	if !isRPCTokenValid(args.Token) {
+	if !isRPCTokenValid(args.Token, jwtAudienceInterNode) {
 		return errInvalidToken
 	}
 	return nil
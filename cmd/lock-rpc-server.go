@@ -136,7 +136,7 @@ func registerStorageLockers(mux *router.Router, lockServers []*lockServer) error
 			return traceError(err)
 		}
 		lockRouter := mux.PathPrefix(reservedBucket).Subrouter()
-		lockRouter.Path(path.Join("/lock", lockServer.rpcPath)).Handler(lockRPCServer)
+		lockRouter.Path(path.Join("/lock", lockServer.rpcPath)).Handler(newRPCHandler(lockRPCServer))
 	}
 	return nil
 }
@@ -152,7 +152,7 @@ func (l *lockServer) LoginHandler(args *RPCLoginArgs, reply *RPCLoginReply) erro
 	if err = jwt.Authenticate(args.Username, args.Password); err != nil {
 		return err
 	}
-	token, err := jwt.GenerateToken(args.Username)
+	token, err := jwt.GenerateToken(args.Username, jwtAudienceInterNode)
 	if err != nil {
 		return err
 	}
@@ -315,7 +315,7 @@ func (l *lockServer) lockMaintenance(interval time.Duration) {
 	// Validate if long lived locks are indeed clean.
 	for _, nlrip := range nlripLongLived {
 		// Initialize client based on the long live locks.
-		c := newClient(nlrip.lri.node, nlrip.lri.rpcPath, isSSL())
+		c := newClient(nlrip.lri.node, nlrip.lri.rpcPath, isSSL(), false, 0)
 
 		var expired bool
 
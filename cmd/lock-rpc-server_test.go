@@ -57,7 +57,7 @@ func createLockTestServer(t *testing.T) (string, *lockServer, string) {
 		t.Fatalf("unable for JWT to authenticate, %s", err)
 	}
 
-	token, err := jwt.GenerateToken(serverConfig.GetCredential().AccessKeyID)
+	token, err := jwt.GenerateToken(serverConfig.GetCredential().AccessKeyID, jwtAudienceInterNode)
 	if err != nil {
 		t.Fatalf("unable for JWT to generate token, %s", err)
 	}
@@ -16,10 +16,7 @@
 
 package cmd
 
-import (
-	"sync"
-	"time"
-)
+import "time"
 
 // SystemLockState - Structure to fill the lock state of entire object storage.
 // That is the total locks held, total calls blocked on locks and state of all the locks for the entire system.
@@ -32,6 +29,9 @@ type SystemLockState struct {
 	// hasn't unlocked yet( operation in progress).
 	TotalAcquiredLocks int64            `json:"totalAcquiredLocks"`
 	LocksInfoPerObject []VolumeLockInfo `json:"locksInfoPerObject"`
+	// Set instead of the lock state when the node could not be
+	// reached.
+	Error string `json:"error,omitempty"`
 }
 
 // VolumeLockInfo - Structure to contain the lock state info for volume, path pair.
@@ -95,32 +95,12 @@ func getSystemLockState() (SystemLockState, error) {
 	return lockState, nil
 }
 
-// Remote procedure call, calls LockInfo handler with given input args.
-func (c *controlAPIHandlers) remoteLockInfoCall(args *GenericArgs, replies []SystemLockState) error {
-	var wg sync.WaitGroup
-	var errs = make([]error, len(c.RemoteControls))
-	// Send remote call to all neighboring peers to restart minio servers.
-	for index, clnt := range c.RemoteControls {
-		wg.Add(1)
-		go func(index int, client *AuthRPCClient) {
-			defer wg.Done()
-			errs[index] = client.Call("Control.RemoteLockInfo", args, &replies[index])
-			errorIf(errs[index], "Unable to initiate control lockInfo request to remote node %s", client.Node())
-		}(index, clnt)
-	}
-	wg.Wait()
-	for _, err := range errs {
-		if err != nil {
-			return err
-		}
-	}
-	return nil
-}
-
 // RemoteLockInfo - RPC control handler for `minio control lock`, used internally by LockInfo to
 // make calls to neighboring peers.
-func (c *controlAPIHandlers) RemoteLockInfo(args *GenericArgs, reply *SystemLockState) error {
-	if !isRPCTokenValid(args.Token) {
+func (c *controlAPIHandlers) RemoteLockInfo(args *GenericArgs, reply *SystemLockState) (err error) {
+	defer encodeRPCError(&err)
+
+	if !isRPCTokenValid(args.Token, jwtAudienceAdmin) {
 		return errInvalidToken
 	}
 	// Obtain the lock state information of the local system.
@@ -133,22 +113,32 @@ func (c *controlAPIHandlers) RemoteLockInfo(args *GenericArgs, reply *SystemLock
 	return nil
 }
 
-// LockInfo - RPC control handler for `minio control lock`. Returns the info of the locks held in the cluster.
-func (c *controlAPIHandlers) LockInfo(args *GenericArgs, reply *map[string]SystemLockState) error {
-	if !isRPCTokenValid(args.Token) {
+// LockInfo - RPC control handler for `minio control lock`. Returns the info of the locks held in the cluster,
+// nodes which could not be reached have their error set instead.
+func (c *controlAPIHandlers) LockInfo(args *GenericArgs, reply *map[string]SystemLockState) (err error) {
+	defer encodeRPCError(&err)
+
+	if !isRPCTokenValid(args.Token, jwtAudienceAdmin) {
 		return errInvalidToken
 	}
-	var replies = make([]SystemLockState, len(c.RemoteControls))
+	remoteControls := c.getRemoteControls()
+	var replies = make([]SystemLockState, len(remoteControls))
+	errsMap := make(map[string]error)
 	if args.Remote {
-		// Fetch lock states from all the remote peers.
+		// Fetch lock states from all the remote peers, the nodes
+		// which fail are reported along with the others.
 		args.Remote = false
-		if err := c.remoteLockInfoCall(args, replies); err != nil {
-			return err
-		}
+		errsMap = callRemoteControls(remoteControls, "Control.RemoteLockInfo", args, func(index int) interface{} {
+			return &replies[index]
+		})
 	}
 	rep := make(map[string]SystemLockState)
 	// The response containing the lock info.
-	for index, client := range c.RemoteControls {
+	for index, client := range remoteControls {
+		if err, ok := errsMap[client.Node()]; ok {
+			rep[client.Node()] = SystemLockState{Error: err.Error()}
+			continue
+		}
 		rep[client.Node()] = replies[index]
 	}
 	// Obtain the lock state information of the local system.
@@ -167,3 +157,31 @@ func (c *controlAPIHandlers) LockInfo(args *GenericArgs, reply *map[string]Syste
 	// Success.
 	return nil
 }
+
+// LockInfoStream - RPC control handler for `minio control lock`. Starts
+// fetching the info of the locks held on all the nodes, to be read per
+// node with NextReplyChunks as the nodes reply.
+func (c *controlAPIHandlers) LockInfoStream(args *GenericArgs, reply *ReplyStreamStartReply) (err error) {
+	defer encodeRPCError(&err)
+
+	if !isRPCTokenValid(args.Token, jwtAudienceAdmin) {
+		return errInvalidToken
+	}
+	calls := map[string]func() (interface{}, error){
+		c.LocalNode: func() (interface{}, error) {
+			return getSystemLockState()
+		},
+	}
+	if args.Remote {
+		for _, clnt := range c.getRemoteControls() {
+			client := clnt
+			calls[client.Node()] = func() (interface{}, error) {
+				var lockState SystemLockState
+				err := client.Call("Control.RemoteLockInfo", &GenericArgs{}, &lockState)
+				return lockState, err
+			}
+		}
+	}
+	reply.StreamID, reply.Nodes = globalReplyStreams.New(calls)
+	return nil
+}
@@ -0,0 +1,158 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+)
+
+const (
+	// Maximum number of log entries buffered per forwarding target.
+	logForwardBufferSize = 10000
+
+	// Maximum number of attempts to forward a single log entry.
+	logForwardMaxRetry = 3
+
+	// Delay between consecutive attempts to forward a log entry.
+	logForwardRetryDelay = 1 * time.Second
+
+	// Maximum duration to wait for buffered entries upon shutdown.
+	logForwardFlushTimeout = 10 * time.Second
+)
+
+// errLogForwardFlushTimeout - buffered entries couldn't be forwarded in time.
+var errLogForwardFlushTimeout = errors.New("Timed out forwarding buffered log entries")
+
+// logEntry - a single formatted log entry pending to be forwarded.
+type logEntry struct {
+	level logrus.Level
+	line  []byte
+}
+
+// logForwardHook - logrus hook forwarding JSON formatted log
+// entries to a remote target. Entries are buffered and forwarded
+// asynchronously with retries so that a slow or unavailable target
+// never blocks the server, entries are dropped once the buffer is full.
+type logForwardHook struct {
+	levels     []logrus.Level
+	formatter  logrus.Formatter
+	send       func(level logrus.Level, line []byte) error
+	entries    chan logEntry
+	pending    sync.WaitGroup
+	retryDelay time.Duration
+}
+
+// newLogForwardHook - initializes a new forwarding hook for all levels
+// upto and including lvl, entries are sent via the send function.
+func newLogForwardHook(lvl logrus.Level, send func(level logrus.Level, line []byte) error) *logForwardHook {
+	var levels []logrus.Level
+	for _, level := range logrus.AllLevels {
+		if level <= lvl {
+			levels = append(levels, level)
+		}
+	}
+	hook := &logForwardHook{
+		levels:     levels,
+		formatter:  &logrus.JSONFormatter{},
+		send:       send,
+		entries:    make(chan logEntry, logForwardBufferSize),
+		retryDelay: logForwardRetryDelay,
+	}
+	go hook.forward()
+	return hook
+}
+
+// Sends a single entry, retrying upto logForwardMaxRetry times.
+func (hook *logForwardHook) sendWithRetry(entry logEntry) (err error) {
+	for i := 0; i < logForwardMaxRetry; i++ {
+		if err = hook.send(entry.level, entry.line); err == nil {
+			return nil
+		}
+		time.Sleep(hook.retryDelay)
+	}
+	return err
+}
+
+// forward - forwards all the buffered entries, runs forever.
+func (hook *logForwardHook) forward() {
+	for entry := range hook.entries {
+		// Errors are not logged here, doing so would
+		// loop back into this hook.
+		hook.sendWithRetry(entry)
+		hook.pending.Done()
+	}
+}
+
+// Fire - formats the log entry as JSON and buffers it for forwarding.
+func (hook *logForwardHook) Fire(entry *logrus.Entry) error {
+	line, err := hook.formatter.Format(entry)
+	if err != nil {
+		return err
+	}
+	lEntry := logEntry{level: entry.Level, line: line}
+
+	// Server exits right after fatal and panic messages,
+	// forward them synchronously.
+	if entry.Level <= logrus.FatalLevel {
+		return hook.sendWithRetry(lEntry)
+	}
+	hook.pending.Add(1)
+	select {
+	case hook.entries <- lEntry:
+	default:
+		// Buffer is full, drop the entry.
+		hook.pending.Done()
+	}
+	return nil
+}
+
+// flush - waits for all the buffered entries to be forwarded.
+func (hook *logForwardHook) flush(timeout time.Duration) error {
+	doneCh := make(chan struct{})
+	go func() {
+		hook.pending.Wait()
+		close(doneCh)
+	}()
+	select {
+	case <-doneCh:
+		return nil
+	case <-time.After(timeout):
+		return errLogForwardFlushTimeout
+	}
+}
+
+// Levels - indicate log levels supported.
+func (hook *logForwardHook) Levels() []logrus.Level {
+	return hook.levels
+}
+
+// addLogForwardHook - adds hook to the global logger, lowers the
+// minimum log level if needed so that the hook sees all its entries.
+// Buffered entries are flushed upon shutdown.
+func addLogForwardHook(lvl logrus.Level, hook *logForwardHook) {
+	log.Hooks.Add(hook)
+	registerShutdownHook(func() error {
+		return hook.flush(logForwardFlushTimeout)
+	})
+	if lvl > log.Level {
+		log.Level = lvl
+	}
+}
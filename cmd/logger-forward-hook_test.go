@@ -0,0 +1,107 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+)
+
+// Tests forwarding of log entries to an HTTP endpoint with retries.
+func TestHTTPLogHook(t *testing.T) {
+	var mu sync.Mutex
+	var attempts int
+	received := make(chan map[string]interface{}, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		attempts++
+		failed := attempts == 1
+		mu.Unlock()
+		// Fail the first attempt to exercise retry.
+		if failed {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			t.Error(err)
+		}
+		var fields map[string]interface{}
+		if err = json.Unmarshal(body, &fields); err != nil {
+			t.Error(err)
+		}
+		received <- fields
+	}))
+	defer server.Close()
+
+	hook := newHTTPLogHook(server.URL, false, logrus.ErrorLevel)
+	hook.retryDelay = time.Millisecond
+
+	logger := logrus.New()
+	logger.Out = ioutil.Discard
+	logger.Hooks.Add(hook)
+	logger.WithFields(logrus.Fields{"cause": "Fake error"}).Error("Failed with error.")
+	// Below the configured level, should not be forwarded.
+	logger.Info("Informational message.")
+
+	select {
+	case fields := <-received:
+		if fields["cause"] != "Fake error" {
+			t.Errorf("Expected cause \"Fake error\", got %v", fields["cause"])
+		}
+		if fields["level"] != "error" {
+			t.Errorf("Expected level error, got %v", fields["level"])
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for the forwarded log entry")
+	}
+	select {
+	case fields := <-received:
+		t.Errorf("Unexpected log entry forwarded %v", fields)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+// Tests flushing of buffered log entries.
+func TestLogForwardHookFlush(t *testing.T) {
+	releaseCh := make(chan struct{})
+	hook := newLogForwardHook(logrus.ErrorLevel, func(level logrus.Level, line []byte) error {
+		<-releaseCh
+		return nil
+	})
+
+	logger := logrus.New()
+	logger.Out = ioutil.Discard
+	logger.Hooks.Add(hook)
+	logger.Error("Failed with error.")
+
+	// Entry is still being forwarded.
+	if err := hook.flush(10 * time.Millisecond); err != errLogForwardFlushTimeout {
+		t.Fatalf("Expected %s, got %v", errLogForwardFlushTimeout, err)
+	}
+	close(releaseCh)
+	if err := hook.flush(5 * time.Second); err != nil {
+		t.Fatalf("Expected flush to succeed, got %s", err)
+	}
+}
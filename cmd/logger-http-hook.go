@@ -0,0 +1,70 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+)
+
+// httpLogger - forwards error logs as JSON to an HTTP endpoint.
+type httpLogger struct {
+	Enable   bool   `json:"enable"`
+	Endpoint string `json:"endpoint"`
+	Level    string `json:"level"`
+	// Skip verifying the TLS certificate of the endpoint.
+	Insecure bool `json:"insecure"`
+}
+
+// Timeout for a single log entry sent to an HTTP endpoint.
+const httpLoggerTimeout = 10 * time.Second
+
+// enableHTTPLogger - enables forwarding logs to the configured endpoint.
+func enableHTTPLogger() {
+	hlogger := serverConfig.GetHTTPLogger()
+	if !hlogger.Enable || hlogger.Endpoint == "" {
+		return
+	}
+
+	lvl, err := logrus.ParseLevel(hlogger.Level)
+	fatalIf(err, "Unknown log level found in the config file.")
+
+	addLogForwardHook(lvl, newHTTPLogHook(hlogger.Endpoint, hlogger.Insecure, lvl))
+}
+
+// newHTTPLogHook - returns a hook which POSTs each log entry to endpoint.
+func newHTTPLogHook(endpoint string, insecure bool, lvl logrus.Level) *logForwardHook {
+	client := &http.Client{
+		Timeout:   httpLoggerTimeout,
+		Transport: newOutboundTransport(insecure),
+	}
+	return newLogForwardHook(lvl, func(level logrus.Level, line []byte) error {
+		resp, err := client.Post(endpoint, "application/json", bytes.NewReader(line))
+		if err != nil {
+			return err
+		}
+		resp.Body.Close()
+		if resp.StatusCode < 200 || resp.StatusCode > 299 {
+			return fmt.Errorf("Log endpoint %s returned %s", endpoint, resp.Status)
+		}
+		return nil
+	})
+}
@@ -0,0 +1,64 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Maximum number of recent error messages retained in memory.
+const maxRecentErrors = 100
+
+// recentErrorLog - in-memory ring buffer of the most recent error
+// messages logged via errorIf, irrespective of the configured log
+// levels. Used to collect diagnostics from a running server.
+type recentErrorLog struct {
+	mutex   sync.Mutex
+	entries []string
+	next    int
+}
+
+// Global recent error log.
+var globalRecentErrors = &recentErrorLog{}
+
+// add - saves a new error message, overwriting the oldest one
+// once maxRecentErrors are retained.
+func (l *recentErrorLog) add(cause string, msg string, data ...interface{}) {
+	entry := fmt.Sprintf("%s %s (cause: %s)", time.Now().UTC().Format(time.RFC3339), fmt.Sprintf(msg, data...), cause)
+	entry = redactSecrets(entry)
+
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	if len(l.entries) < maxRecentErrors {
+		l.entries = append(l.entries, entry)
+		return
+	}
+	l.entries[l.next] = entry
+	l.next = (l.next + 1) % maxRecentErrors
+}
+
+// list - returns all the retained error messages, oldest first.
+func (l *recentErrorLog) list() []string {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	entries := make([]string, 0, len(l.entries))
+	entries = append(entries, l.entries[l.next:]...)
+	entries = append(entries, l.entries[:l.next]...)
+	return entries
+}
@@ -19,7 +19,6 @@
 package cmd
 
 import (
-	"fmt"
 	"log/syslog"
 
 	"github.com/Sirupsen/logrus"
@@ -31,52 +30,43 @@ type syslogLogger struct {
 	Level  string `json:"level"`
 }
 
-// syslogHook to send logs via syslog.
-type syslogHook struct {
-	writer        *syslog.Writer
-	syslogNetwork string
-	syslogRaddr   string
-}
+// enableSyslogLogger - enables forwarding logs to the configured
+// syslog address.
+func enableSyslogLogger() {
+	slogger := serverConfig.GetSyslogLogger()
+	if !slogger.Enable || slogger.Addr == "" {
+		return
+	}
 
-// enableSyslogLogger - enable logger at raddr.
-func enableSyslogLogger(raddr string) {
-	syslogHook, err := newSyslog("udp", raddr, syslog.LOG_ERR, "MINIO")
-	fatalIf(err, "Unable to initialize syslog logger.")
+	lvl, err := logrus.ParseLevel(slogger.Level)
+	fatalIf(err, "Unknown log level found in the config file.")
 
-	log.Hooks.Add(syslogHook)               // Add syslog hook.
-	log.Formatter = &logrus.JSONFormatter{} // JSON formatted log.
-	log.Level = logrus.ErrorLevel           // Minimum log level.
-}
+	hook, err := newSyslogHook("udp", slogger.Addr, lvl, "MINIO")
+	fatalIf(err, "Unable to initialize syslog logger.")
 
-// newSyslog - Creates a hook to be added to an instance of logger.
-func newSyslog(network, raddr string, priority syslog.Priority, tag string) (*syslogHook, error) {
-	w, err := syslog.Dial(network, raddr, priority, tag)
-	return &syslogHook{w, network, raddr}, err
+	addLogForwardHook(lvl, hook)
 }
 
-// Fire - fire the log event
-func (hook *syslogHook) Fire(entry *logrus.Entry) error {
-	line, err := entry.String()
+// newSyslogHook - returns a hook which writes each log entry to
+// syslog at raddr, syslog writer reconnects on write failures.
+func newSyslogHook(network, raddr string, lvl logrus.Level, tag string) (*logForwardHook, error) {
+	writer, err := syslog.Dial(network, raddr, syslog.LOG_ERR, tag)
 	if err != nil {
-		return fmt.Errorf("Unable to read entry, %v", err)
-	}
-	switch entry.Level {
-	case logrus.PanicLevel:
-		return hook.writer.Crit(line)
-	case logrus.FatalLevel:
-		return hook.writer.Crit(line)
-	case logrus.ErrorLevel:
-		return hook.writer.Err(line)
-	default:
-		return nil
-	}
-}
-
-// Levels -
-func (hook *syslogHook) Levels() []logrus.Level {
-	return []logrus.Level{
-		logrus.PanicLevel,
-		logrus.FatalLevel,
-		logrus.ErrorLevel,
+		return nil, err
 	}
+	return newLogForwardHook(lvl, func(level logrus.Level, line []byte) error {
+		msg := string(line)
+		switch level {
+		case logrus.PanicLevel, logrus.FatalLevel:
+			return writer.Crit(msg)
+		case logrus.ErrorLevel:
+			return writer.Err(msg)
+		case logrus.WarnLevel:
+			return writer.Warning(msg)
+		case logrus.InfoLevel:
+			return writer.Info(msg)
+		default:
+			return writer.Debug(msg)
+		}
+	}), nil
 }
@@ -25,6 +25,9 @@ type syslogLogger struct {
 }
 
 // enableSyslogLogger - unsupported on windows.
-func enableSyslogLogger(raddr string) {
+func enableSyslogLogger() {
+	if !serverConfig.GetSyslogLogger().Enable {
+		return
+	}
 	fatalIf(errSyslogNotSupported, "Unable to enable syslog.")
 }
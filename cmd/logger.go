@@ -31,7 +31,14 @@ import (
 
 type fields map[string]interface{}
 
-var log = logrus.New() // Default console logger.
+var log = newLogger() // Default console logger.
+
+// newLogger - returns a logger redacting secrets from all entries.
+func newLogger() *logrus.Logger {
+	l := logrus.New()
+	l.Hooks.Add(redactHook{})
+	return l
+}
 
 // logger carries logging configuration for various supported loggers.
 // Currently supported loggers are
@@ -39,10 +46,12 @@ var log = logrus.New() // Default console logger.
 //   - console [default]
 //   - file
 //   - syslog
+//   - http
 type logger struct {
 	Console consoleLogger `json:"console"`
 	File    fileLogger    `json:"file"`
 	Syslog  syslogLogger  `json:"syslog"`
+	HTTP    httpLogger    `json:"http"`
 	// Add new loggers here.
 }
 
@@ -88,6 +97,13 @@ func errorIf(err error, msg string, data ...interface{}) {
 	if e, ok := err.(*Error); ok {
 		fields["stack"] = strings.Join(e.Trace(), " ")
 	}
+	if e, ok := err.(*RPCError); ok {
+		fields["node"] = e.Node
+		fields["stack"] = strings.Join(e.Stack, " ")
+	}
+
+	// Retain for diagnostics.
+	globalRecentErrors.add(err.Error(), msg, data...)
 
 	log.WithFields(fields).Errorf(msg, data...)
 }
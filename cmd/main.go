@@ -24,6 +24,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/Sirupsen/logrus"
 	"github.com/minio/cli"
 	"github.com/minio/mc/pkg/console"
 )
@@ -44,6 +45,10 @@ var (
 			Name:  "quiet",
 			Usage: "Suppress chatty output.",
 		},
+		cli.BoolFlag{
+			Name:  "json",
+			Usage: "Print messages and errors as lines of JSON.",
+		},
 	}
 )
 
@@ -88,6 +93,8 @@ func enableLoggers() {
 	// Enable all loggers here.
 	enableConsoleLogger()
 	enableFileLogger()
+	enableSyslogLogger()
+	enableHTTPLogger()
 	// Add your logger here.
 }
 
@@ -118,6 +125,11 @@ func registerApp() *cli.App {
 	registerCommand(versionCmd)
 	registerCommand(updateCmd)
 	registerCommand(controlCmd)
+	registerCommand(testS3Cmd)
+	registerCommand(credentialsCmd)
+	registerCommand(configCmd)
+	registerCommand(fsckCmd)
+	registerCommand(benchmarkCmd)
 
 	// Set up app.
 	app := cli.NewApp()
@@ -153,6 +165,38 @@ func checkMainSyntax(c *cli.Context) {
 	}
 }
 
+// setJSONOutput - prints messages and fatal errors as lines of JSON if
+// --json is given, either before or after the command name. Called
+// before the command runs and again by commands printing messages,
+// as command flags are only parsed then.
+func setJSONOutput(c *cli.Context) {
+	if !c.Bool("json") && !c.GlobalBool("json") {
+		return
+	}
+	globalJSON = true
+	log.Formatter = new(logrus.JSONFormatter)
+}
+
+// setCredentialFromEnv - overrides the credentials in the current
+// config with MINIO_ACCESS_KEY and MINIO_SECRET_KEY, if both are set.
+func setCredentialFromEnv() {
+	accessKey := os.Getenv("MINIO_ACCESS_KEY")
+	secretKey := os.Getenv("MINIO_SECRET_KEY")
+	if accessKey != "" && secretKey != "" {
+		if !isValidAccessKey.MatchString(accessKey) {
+			fatalIf(errInvalidArgument, "Invalid access key.")
+		}
+		if err := globalSecretKeyPolicy.validate(secretKey); err != nil {
+			fatalIf(err, "Invalid secret key.")
+		}
+		// Set new credentials.
+		serverConfig.SetCredential(credential{
+			AccessKeyID:     accessKey,
+			SecretAccessKey: secretKey,
+		})
+	}
+}
+
 // Main main for minio server.
 func Main() {
 	app := registerApp()
@@ -164,32 +208,62 @@ func Main() {
 		// Sets new config folder.
 		setGlobalConfigPath(configDir)
 
+		// Print errors as JSON as early as possible.
+		setJSONOutput(c)
+
 		// Valid input arguments to main.
 		checkMainSyntax(c)
 
 		// Migrate any old version of config / state files to newer format.
 		migrate()
 
+		// Fetch the passphrase of config.json secrets, before the
+		// config is loaded.
+		globalConfigPassphrase = os.Getenv("MINIO_CONFIG_PASSPHRASE")
+
+		// Load the CA bundle before any outbound connection is made,
+		// etcd and the update check below included.
+		if bundle := os.Getenv("MINIO_CA_BUNDLE"); bundle != "" {
+			rootCAs, err := loadCABundle(bundle)
+			fatalIf(err, "Invalid MINIO_CA_BUNDLE=%s environment variable.", bundle)
+			globalRootCAs = rootCAs
+		}
+
+		// Fetch proxies of outbound connections per subsystem.
+		if proxy := os.Getenv("MINIO_UPDATE_PROXY"); proxy != "" {
+			proxyURL, err := parseProxyURL(proxy)
+			fatalIf(err, "Invalid MINIO_UPDATE_PROXY=%s environment variable.", proxy)
+			globalUpdateProxyURL = proxyURL
+		}
+		if proxy := os.Getenv("MINIO_NOTIFY_PROXY"); proxy != "" {
+			proxyURL, err := parseProxyURL(proxy)
+			fatalIf(err, "Invalid MINIO_NOTIFY_PROXY=%s environment variable.", proxy)
+			globalNotifyProxyURL = proxyURL
+		}
+
+		// Connect to etcd before the config is loaded, it is shared
+		// through etcd when MINIO_ETCD_ENDPOINTS is set.
+		if endpoints := os.Getenv("MINIO_ETCD_ENDPOINTS"); endpoints != "" {
+			etcdEndpoints, err := parseEtcdEndpoints(endpoints)
+			fatalIf(err, "Invalid MINIO_ETCD_ENDPOINTS=%s environment variable.", endpoints)
+			prefix := os.Getenv("MINIO_ETCD_PREFIX")
+			if prefix == "" {
+				prefix = etcdDefaultPrefix
+			}
+			globalEtcd = newEtcdClient(etcdEndpoints, prefix)
+		}
+
 		// Initialize config.
 		err := initConfig()
 		fatalIf(err, "Unable to initialize minio config.")
 
+		// Fetch secret key policy from environment variables, before
+		// any credential is set.
+		globalSecretKeyPolicy, err = parseSecretKeyPolicy(os.Getenv("MINIO_SECRET_KEY_MIN_LENGTH"), os.Getenv("MINIO_SECRET_KEY_MIN_ENTROPY"))
+		fatalIf(err, "Invalid MINIO_SECRET_KEY_MIN_LENGTH or MINIO_SECRET_KEY_MIN_ENTROPY environment variable.")
+
 		// Fetch access keys from environment variables and update the config.
-		accessKey := os.Getenv("MINIO_ACCESS_KEY")
-		secretKey := os.Getenv("MINIO_SECRET_KEY")
-		if accessKey != "" && secretKey != "" {
-			if !isValidAccessKey.MatchString(accessKey) {
-				fatalIf(errInvalidArgument, "Invalid access key.")
-			}
-			if !isValidSecretKey.MatchString(secretKey) {
-				fatalIf(errInvalidArgument, "Invalid secret key.")
-			}
-			// Set new credentials.
-			serverConfig.SetCredential(credential{
-				AccessKeyID:     accessKey,
-				SecretAccessKey: secretKey,
-			})
-		}
+		setCredentialFromEnv()
 
 		// Enable all loggers by now.
 		enableLoggers()
@@ -203,14 +277,18 @@ func Main() {
 		// Do not print update messages, if quiet flag is set.
 		if !globalQuiet {
 			if strings.HasPrefix(ReleaseTag, "RELEASE.") && c.Args().Get(0) != "update" {
-				updateMsg, _, err := getReleaseUpdate(minioUpdateStableURL, 1*time.Second)
+				// Sites with a mirror may not reach the official
+				// releases at all.
+				channel, err := updateChannels["stable"].onEnvMirror()
+				fatalIf(err, "Invalid MINIO_UPDATE_URL=%s environment variable.", os.Getenv("MINIO_UPDATE_URL"))
+				updateMsg, _, err := getReleaseUpdate(channel, 1*time.Second)
 				if err != nil {
 					// Ignore any errors during getReleaseUpdate(), possibly
 					// because of network errors.
 					return nil
 				}
 				if updateMsg.Update {
-					console.Println(updateMsg)
+					printUpdateMsg(updateMsg)
 				}
 			}
 		}
@@ -0,0 +1,316 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package cmd
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io/ioutil"
+	"math"
+	"net"
+)
+
+// Reader of MaxMind DB files, e.g GeoLite2 country and city
+// databases, as described at http://maxmind.github.io/MaxMind-DB/
+
+// Marker preceding the metadata at the end of the file.
+var mmdbMetadataMarker = []byte("\xab\xcd\xefMaxMind.com")
+
+// Metadata is in the last 128KiB of the file.
+const mmdbMetadataMaxSize = 128 * 1024
+
+// Size of the separator between the search tree and the data section.
+const mmdbDataSectionSeparator = 16
+
+// Data types of the data section.
+const (
+	mmdbExtended  = 0
+	mmdbPointer   = 1
+	mmdbString    = 2
+	mmdbDouble    = 3
+	mmdbBytes     = 4
+	mmdbUint16    = 5
+	mmdbUint32    = 6
+	mmdbMap       = 7
+	mmdbInt32     = 8
+	mmdbUint64    = 9
+	mmdbUint128   = 10
+	mmdbArray     = 11
+	mmdbContainer = 12
+	mmdbEndMarker = 13
+	mmdbBoolean   = 14
+	mmdbFloat     = 15
+)
+
+var errInvalidMMDB = errors.New("Invalid MaxMind DB file")
+
+// mmdbReader - MaxMind DB loaded in memory.
+type mmdbReader struct {
+	tree       []byte
+	data       []byte
+	nodeCount  uint
+	recordSize uint
+	ipVersion  uint
+	// Node of the IPv4 addresses in IPv6 trees, ::/96.
+	ipv4Start uint
+}
+
+// newMMDBReader - loads the MaxMind DB at path.
+func newMMDBReader(path string) (*mmdbReader, error) {
+	buf, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return parseMMDB(buf)
+}
+
+// parseMMDB - parses a MaxMind DB file content.
+func parseMMDB(buf []byte) (*mmdbReader, error) {
+	metadataStart := len(buf) - mmdbMetadataMaxSize
+	if metadataStart < 0 {
+		metadataStart = 0
+	}
+	i := bytes.LastIndex(buf[metadataStart:], mmdbMetadataMarker)
+	if i < 0 {
+		return nil, errInvalidMMDB
+	}
+	metadataStart += i + len(mmdbMetadataMarker)
+	metadata, _, err := decodeMMDBValue(buf[metadataStart:], 0)
+	if err != nil {
+		return nil, err
+	}
+	fields, ok := metadata.(map[string]interface{})
+	if !ok {
+		return nil, errInvalidMMDB
+	}
+	r := &mmdbReader{}
+	for key, value := range map[string]*uint{
+		"node_count":  &r.nodeCount,
+		"record_size": &r.recordSize,
+		"ip_version":  &r.ipVersion,
+	} {
+		v, ok := fields[key].(uint64)
+		if !ok {
+			return nil, errInvalidMMDB
+		}
+		*value = uint(v)
+	}
+	if r.recordSize != 24 && r.recordSize != 28 && r.recordSize != 32 {
+		return nil, errInvalidMMDB
+	}
+	if r.ipVersion != 4 && r.ipVersion != 6 {
+		return nil, errInvalidMMDB
+	}
+	treeSize := r.nodeCount * r.recordSize / 4
+	dataStart := treeSize + mmdbDataSectionSeparator
+	if dataStart > uint(metadataStart-len(mmdbMetadataMarker)) {
+		return nil, errInvalidMMDB
+	}
+	r.tree = buf[:treeSize]
+	r.data = buf[dataStart : metadataStart-len(mmdbMetadataMarker)]
+
+	// Walk the 96 zero bits prefixing IPv4 addresses in IPv6 trees.
+	if r.ipVersion == 6 {
+		for i := 0; i < 96 && r.ipv4Start < r.nodeCount; i++ {
+			r.ipv4Start = r.readRecord(r.ipv4Start, 0)
+		}
+	}
+	return r, nil
+}
+
+// readRecord - returns the left (bit 0) or right (bit 1) record of a node.
+func (r *mmdbReader) readRecord(node uint, bit uint) uint {
+	n := r.tree[node*r.recordSize/4:]
+	switch r.recordSize {
+	case 24:
+		n = n[bit*3:]
+		return uint(n[0])<<16 | uint(n[1])<<8 | uint(n[2])
+	case 28:
+		if bit == 0 {
+			return uint(n[3]&0xf0)<<20 | uint(n[0])<<16 | uint(n[1])<<8 | uint(n[2])
+		}
+		return uint(n[3]&0x0f)<<24 | uint(n[4])<<16 | uint(n[5])<<8 | uint(n[6])
+	}
+	return uint(binary.BigEndian.Uint32(n[bit*4:]))
+}
+
+// Lookup - returns the record of the network holding ip, nil when
+// the database has none.
+func (r *mmdbReader) Lookup(ip net.IP) (interface{}, error) {
+	node := uint(0)
+	if ip4 := ip.To4(); ip4 != nil {
+		ip = ip4
+		node = r.ipv4Start
+	} else if r.ipVersion == 4 {
+		return nil, nil
+	}
+	for i := 0; i < len(ip)*8 && node < r.nodeCount; i++ {
+		bit := uint(ip[i/8]>>(7-uint(i%8))) & 1
+		node = r.readRecord(node, bit)
+	}
+	if node <= r.nodeCount {
+		return nil, nil
+	}
+	offset := node - r.nodeCount - mmdbDataSectionSeparator
+	value, _, err := decodeMMDBValue(r.data, offset)
+	return value, err
+}
+
+// decodeMMDBValue - decodes the value at offset of the data section,
+// returns it along with the offset following it.
+func decodeMMDBValue(data []byte, offset uint) (interface{}, uint, error) {
+	typ, size, offset, err := decodeMMDBControl(data, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	if typ == mmdbPointer {
+		// Pointed values are decoded, decoding resumes after the pointer.
+		value, _, err := decodeMMDBValue(data, size)
+		return value, offset, err
+	}
+	if typ == mmdbMap {
+		value := make(map[string]interface{}, size)
+		for i := uint(0); i < size; i++ {
+			var k, v interface{}
+			if k, offset, err = decodeMMDBValue(data, offset); err != nil {
+				return nil, 0, err
+			}
+			key, ok := k.(string)
+			if !ok {
+				return nil, 0, errInvalidMMDB
+			}
+			if v, offset, err = decodeMMDBValue(data, offset); err != nil {
+				return nil, 0, err
+			}
+			value[key] = v
+		}
+		return value, offset, nil
+	}
+	if typ == mmdbArray {
+		value := make([]interface{}, size)
+		for i := range value {
+			if value[i], offset, err = decodeMMDBValue(data, offset); err != nil {
+				return nil, 0, err
+			}
+		}
+		return value, offset, nil
+	}
+	if typ == mmdbBoolean {
+		return size != 0, offset, nil
+	}
+	if offset+size > uint(len(data)) {
+		return nil, 0, errInvalidMMDB
+	}
+	buf := data[offset : offset+size]
+	offset += size
+	switch typ {
+	case mmdbString:
+		return string(buf), offset, nil
+	case mmdbBytes, mmdbUint128:
+		return buf, offset, nil
+	case mmdbDouble:
+		if size != 8 {
+			return nil, 0, errInvalidMMDB
+		}
+		return math.Float64frombits(binary.BigEndian.Uint64(buf)), offset, nil
+	case mmdbFloat:
+		if size != 4 {
+			return nil, 0, errInvalidMMDB
+		}
+		return float64(math.Float32frombits(binary.BigEndian.Uint32(buf))), offset, nil
+	case mmdbUint16, mmdbUint32, mmdbUint64:
+		if size > 8 {
+			return nil, 0, errInvalidMMDB
+		}
+		var value uint64
+		for _, b := range buf {
+			value = value<<8 | uint64(b)
+		}
+		return value, offset, nil
+	case mmdbInt32:
+		if size > 4 {
+			return nil, 0, errInvalidMMDB
+		}
+		var value uint32
+		for _, b := range buf {
+			value = value<<8 | uint32(b)
+		}
+		return int64(int32(value)), offset, nil
+	}
+	return nil, 0, errInvalidMMDB
+}
+
+// decodeMMDBControl - decodes the control byte at offset, returns the
+// type, the size, or the pointed offset for pointers, along with the
+// offset of the payload.
+func decodeMMDBControl(data []byte, offset uint) (typ, size, next uint, err error) {
+	readBytes := func(n uint) (uint, error) {
+		if offset+n > uint(len(data)) {
+			return 0, errInvalidMMDB
+		}
+		var value uint
+		for _, b := range data[offset : offset+n] {
+			value = value<<8 | uint(b)
+		}
+		offset += n
+		return value, nil
+	}
+	ctrl, err := readBytes(1)
+	if err != nil {
+		return 0, 0, 0, err
+	}
+	typ = ctrl >> 5
+	if typ == mmdbPointer {
+		ss, vvv := (ctrl>>3)&0x3, ctrl&0x7
+		pointer, err := readBytes(ss + 1)
+		if err != nil {
+			return 0, 0, 0, err
+		}
+		switch ss {
+		case 0:
+			pointer |= vvv << 8
+		case 1:
+			pointer = pointer | vvv<<16 + 2048
+		case 2:
+			pointer = pointer | vvv<<24 + 526336
+		}
+		return typ, pointer, offset, nil
+	}
+	if typ == mmdbExtended {
+		ext, err := readBytes(1)
+		if err != nil {
+			return 0, 0, 0, err
+		}
+		typ = ext + 7
+	}
+	size = ctrl & 0x1f
+	switch size {
+	case 29:
+		size, err = readBytes(1)
+		size += 29
+	case 30:
+		size, err = readBytes(2)
+		size += 285
+	case 31:
+		size, err = readBytes(3)
+		size += 65821
+	}
+	if err != nil {
+		return 0, 0, 0, err
+	}
+	return typ, size, offset, nil
+}
@@ -0,0 +1,167 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package cmd
+
+import (
+	"bytes"
+	"net"
+	"reflect"
+	"testing"
+)
+
+// Encodes the control byte of a value of a MaxMind DB.
+func mmdbControl(typ, size int) []byte {
+	if typ > 7 {
+		return []byte{byte(size), byte(typ - 7)}
+	}
+	return []byte{byte(typ<<5 | size)}
+}
+
+// Encodes a string value of a MaxMind DB.
+func mmdbEncodeString(s string) []byte {
+	return append(mmdbControl(mmdbString, len(s)), s...)
+}
+
+// Encodes a map of values of a MaxMind DB, keys in the given order.
+func mmdbEncodeMap(keys []string, values ...[]byte) []byte {
+	buf := mmdbControl(mmdbMap, len(keys))
+	for i, key := range keys {
+		buf = append(buf, mmdbEncodeString(key)...)
+		buf = append(buf, values[i]...)
+	}
+	return buf
+}
+
+// Returns a MaxMind DB of ip version 4 with a record size of 24,
+// holding the country FR and the continent EU for 10.0.0.0/8.
+func newTestMMDB() []byte {
+	// Continent map, pointed to by the country map.
+	data := mmdbEncodeMap([]string{"code"}, mmdbEncodeString("EU"))
+	recordOffset := len(data)
+	data = append(data, mmdbEncodeMap([]string{"continent", "country"},
+		[]byte{mmdbPointer << 5, 0},
+		mmdbEncodeMap([]string{"iso_code"}, mmdbEncodeString("FR")))...)
+
+	// One node per bit of the /8 prefix, others point nowhere.
+	const nodeCount = 8
+	prefix := byte(10)
+	var tree []byte
+	for i := uint(0); i < nodeCount; i++ {
+		next := i + 1
+		if i == nodeCount-1 {
+			next = nodeCount + mmdbDataSectionSeparator + uint(recordOffset)
+		}
+		records := [2]uint{nodeCount, nodeCount}
+		records[prefix>>(7-i)&1] = next
+		for _, r := range records {
+			tree = append(tree, byte(r>>16), byte(r>>8), byte(r))
+		}
+	}
+
+	buf := append(tree, make([]byte, mmdbDataSectionSeparator)...)
+	buf = append(buf, data...)
+	buf = append(buf, mmdbMetadataMarker...)
+	buf = append(buf, mmdbEncodeMap([]string{"node_count", "record_size", "ip_version"},
+		append(mmdbControl(mmdbUint32, 1), nodeCount),
+		append(mmdbControl(mmdbUint16, 1), 24),
+		append(mmdbControl(mmdbUint16, 1), 4))...)
+	return buf
+}
+
+// Tests looking up networks of a MaxMind DB.
+func TestMMDBLookup(t *testing.T) {
+	r, err := parseMMDB(newTestMMDB())
+	if err != nil {
+		t.Fatalf("Unable to parse MaxMind DB: %v", err)
+	}
+	expected := map[string]interface{}{
+		"continent": map[string]interface{}{"code": "EU"},
+		"country":   map[string]interface{}{"iso_code": "FR"},
+	}
+	testCases := []struct {
+		ip     string
+		record interface{}
+	}{
+		{"10.0.0.1", expected},
+		{"10.255.3.4", expected},
+		{"11.0.0.1", nil},
+		{"192.168.1.1", nil},
+		// IPv6 addresses are not in IPv4 databases.
+		{"2001:db8::1", nil},
+	}
+	for i, testCase := range testCases {
+		record, err := r.Lookup(net.ParseIP(testCase.ip))
+		if err != nil {
+			t.Fatalf("Test %d: Unable to lookup %s: %v", i+1, testCase.ip, err)
+		}
+		if testCase.record == nil && record != nil || testCase.record != nil && !reflect.DeepEqual(record, testCase.record) {
+			t.Errorf("Test %d: Expected %v for %s, got %v", i+1, testCase.record, testCase.ip, record)
+		}
+	}
+}
+
+// Tests invalid MaxMind DBs are rejected.
+func TestParseMMDBInvalid(t *testing.T) {
+	db := newTestMMDB()
+	testCases := [][]byte{
+		nil,
+		[]byte("not a database"),
+		// Truncated metadata.
+		db[:len(db)-3],
+		// Unsupported record size.
+		bytes.Replace(db, append(mmdbControl(mmdbUint16, 1), 24), append(mmdbControl(mmdbUint16, 1), 20), 1),
+	}
+	for i, testCase := range testCases {
+		if _, err := parseMMDB(testCase); err == nil {
+			t.Errorf("Test %d: Expected parsing to fail", i+1)
+		}
+	}
+}
+
+// Tests decoding the data types of MaxMind DBs.
+func TestDecodeMMDBValue(t *testing.T) {
+	testCases := []struct {
+		data  []byte
+		value interface{}
+	}{
+		{mmdbEncodeString("minio"), "minio"},
+		{[]byte{mmdbUint16<<5 | 2, 0x01, 0x02}, uint64(0x0102)},
+		{[]byte{mmdbUint32<<5 | 0}, uint64(0)},
+		{append(mmdbControl(mmdbInt32, 4), 0xff, 0xff, 0xff, 0xfe), int64(-2)},
+		{append(mmdbControl(mmdbUint64, 1), 42), uint64(42)},
+		{mmdbControl(mmdbBoolean, 1), true},
+		{[]byte{mmdbDouble<<5 | 8, 0x3f, 0xf8, 0, 0, 0, 0, 0, 0}, 1.5},
+		{append(append(mmdbControl(mmdbArray, 2), mmdbEncodeString("a")...), mmdbEncodeString("b")...),
+			[]interface{}{"a", "b"}},
+	}
+	for i, testCase := range testCases {
+		value, next, err := decodeMMDBValue(testCase.data, 0)
+		if err != nil {
+			t.Fatalf("Test %d: Unable to decode: %v", i+1, err)
+		}
+		if !reflect.DeepEqual(value, testCase.value) {
+			t.Errorf("Test %d: Expected %#v, got %#v", i+1, testCase.value, value)
+		}
+		if next != uint(len(testCase.data)) {
+			t.Errorf("Test %d: Expected next offset %d, got %d", i+1, len(testCase.data), next)
+		}
+	}
+
+	// Values overflowing the data are invalid.
+	if _, _, err := decodeMMDBValue(mmdbEncodeString("minio")[:3], 0); err != errInvalidMMDB {
+		t.Errorf("Expected %v, got %v", errInvalidMMDB, err)
+	}
+}
@@ -49,6 +49,7 @@ func initDsyncNodes(disks []string, port int) error {
 				// Construct a new rpc path for the disk.
 				path:        pathutil.Join(lockRPCPath, disk[idx+1:]),
 				loginMethod: "Dsync.LoginHandler",
+				retryDrain:  true,
 			}))
 
 			if isLocalStorage(disk) && myNode == -1 {
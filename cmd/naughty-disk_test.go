@@ -94,6 +94,13 @@ func (d *naughtyDisk) DeleteVol(volume string) (err error) {
 	return d.disk.DeleteVol(volume)
 }
 
+func (d *naughtyDisk) RenameVol(srcVolume, dstVolume string) (err error) {
+	if err := d.calcError(); err != nil {
+		return err
+	}
+	return d.disk.RenameVol(srcVolume, dstVolume)
+}
+
 func (d *naughtyDisk) ListDir(volume, path string) (entries []string, err error) {
 	if err := d.calcError(); err != nil {
 		return []string{}, err
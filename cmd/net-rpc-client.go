@@ -35,16 +35,20 @@ type RPCClient struct {
 	node       string
 	rpcPath    string
 	secureConn bool
+	compress   bool          // Ask for a compressed connection.
+	timeout    time.Duration // Maximum duration of a call, zero for no timeout.
 }
 
 // newClient constructs a RPCClient object with node and rpcPath initialized.
 // It _doesn't_ connect to the remote endpoint. See Call method to see when the
 // connect happens.
-func newClient(node, rpcPath string, secureConn bool) *RPCClient {
+func newClient(node, rpcPath string, secureConn, compress bool, timeout time.Duration) *RPCClient {
 	return &RPCClient{
 		node:       node,
 		rpcPath:    rpcPath,
 		secureConn: secureConn,
+		compress:   compress,
+		timeout:    timeout,
 	}
 }
 
@@ -77,7 +81,9 @@ func (rpcClient *RPCClient) dialRPCClient() (*rpc.Client, error) {
 	var conn net.Conn
 
 	if rpcClient.secureConn {
-		conn, err = tls.Dial("tcp", rpcClient.node, &tls.Config{})
+		config := newTLSConfig()
+		config.ClientSessionCache = globalTLSClientSessionCache
+		conn, err = tls.Dial("tcp", rpcClient.node, config)
 	} else {
 		// Have a dial timeout with 3 secs.
 		conn, err = net.DialTimeout("tcp", rpcClient.node, 3*time.Second)
@@ -85,11 +91,21 @@ func (rpcClient *RPCClient) dialRPCClient() (*rpc.Client, error) {
 	if err != nil {
 		return nil, err
 	}
-	io.WriteString(conn, "CONNECT "+rpcClient.rpcPath+" HTTP/1.0\n\n")
+	connectReq := "CONNECT " + rpcClient.rpcPath + " HTTP/1.0\n"
+	if rpcClient.compress {
+		connectReq += rpcCompressionHeader + ": " + rpcCompressionFlate + "\n"
+	}
+	io.WriteString(conn, connectReq+"\n")
 
 	// Require successful HTTP response before switching to RPC protocol.
 	resp, err := http.ReadResponse(bufio.NewReader(conn), &http.Request{Method: "CONNECT"})
-	if err == nil && resp.Status == "200 Connected to Go RPC" {
+	if err == nil && resp.Status == rpcConnectedStatus {
+		// Compress only if the server accepted it.
+		if resp.Header.Get(rpcCompressionHeader) == rpcCompressionFlate {
+			if conn, err = newCompressedConn(conn); err != nil {
+				return nil, err
+			}
+		}
 		rpc := rpc.NewClient(conn)
 		if rpc == nil {
 			return nil, errors.New("No valid RPC Client created after dial")
@@ -127,9 +143,32 @@ func (rpcClient *RPCClient) Call(serviceMethod string, args interface{}, reply i
 		}
 	}
 
+	// Wait for the reply at most until the timeout, or the deadline
+	// of args if it is earlier.
+	timeout := getRPCTimeout(args, rpcClient.timeout)
+	if timeout < 0 {
+		return errRPCTimeout
+	}
+	call := rpcLocalStack.Go(serviceMethod, args, reply, make(chan *rpc.Call, 1))
+	var timer <-chan time.Time
+	if timeout > 0 {
+		t := time.NewTimer(timeout)
+		defer t.Stop()
+		timer = t.C
+	}
+	select {
+	case <-call.Done:
+	case <-timer:
+		// The call can not be aborted, close the connection so
+		// that a wedged peer does not hold the caller forever.
+		rpcClient.clearRPCClient()
+		rpcLocalStack.Close()
+		return errRPCTimeout
+	}
+
 	// If the RPC fails due to a network-related error, then we reset
 	// rpc.Client for a subsequent reconnect.
-	err := rpcLocalStack.Call(serviceMethod, args, reply)
+	err := call.Error
 	if err != nil {
 		if err.Error() == rpc.ErrShutdown.Error() {
 			// Reset rpcClient.rpc to nil to trigger a reconnect in future
@@ -141,6 +180,9 @@ func (rpcClient *RPCClient) Call(serviceMethod string, args interface{}, reply i
 
 			// Set rpc error as rpc.ErrShutdown type.
 			err = rpc.ErrShutdown
+		} else {
+			// Map errors sent by the remote handler back to the local type.
+			err = fromRPCError(err)
 		}
 	}
 	return err
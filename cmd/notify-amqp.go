@@ -18,6 +18,8 @@ package cmd
 
 import (
 	"io/ioutil"
+	"net"
+	"time"
 
 	"github.com/Sirupsen/logrus"
 	"github.com/streadway/amqp"
@@ -37,6 +39,8 @@ type amqpNotify struct {
 	Internal     bool   `json:"internal"`
 	NoWait       bool   `json:"noWait"`
 	AutoDeleted  bool   `json:"autoDeleted"`
+	// Skip verifying the TLS certificate of amqps:// servers.
+	Insecure bool `json:"insecure"`
 }
 
 type amqpConn struct {
@@ -44,6 +48,30 @@ type amqpConn struct {
 	*amqp.Connection
 }
 
+// amqpDial - connects to the AMQP server of amqpL, through
+// MINIO_NOTIFY_PROXY if set.
+func amqpDial(amqpL amqpNotify) (*amqp.Connection, error) {
+	tlsConfig := newOutboundTLSConfig(amqpL.Insecure)
+	proxyURL := getNotifyProxyURL()
+	if proxyURL == nil {
+		return amqp.DialTLS(amqpL.URL, tlsConfig)
+	}
+	return amqp.DialConfig(amqpL.URL, amqp.Config{
+		Heartbeat:       10 * time.Second,
+		TLSClientConfig: tlsConfig,
+		Dial: func(network, addr string) (net.Conn, error) {
+			conn, err := dialProxy(proxyURL, addr, notifyDialTimeout)
+			if err != nil {
+				return nil, err
+			}
+			// Like amqp's own dialer, don't stall forever on a
+			// dead server before heartbeats start.
+			conn.SetReadDeadline(time.Now().Add(notifyDialTimeout))
+			return conn, nil
+		},
+	})
+}
+
 // dialAMQP - dials and returns an amqpConnection instance,
 // for sending notifications. Returns error if amqp logger
 // is not enabled.
@@ -51,7 +79,7 @@ func dialAMQP(amqpL amqpNotify) (amqpConn, error) {
 	if !amqpL.Enable {
 		return amqpConn{}, errNotifyNotEnabled
 	}
-	conn, err := amqp.Dial(amqpL.URL)
+	conn, err := amqpDial(amqpL)
 	if err != nil {
 		return amqpConn{}, err
 	}
@@ -92,7 +120,7 @@ func (q amqpConn) Fire(entry *logrus.Entry) error {
 		}
 		// Attempt to connect again.
 		var conn *amqp.Connection
-		conn, err = amqp.Dial(q.params.URL)
+		conn, err = amqpDial(q.params)
 		if err != nil {
 			return err
 		}
@@ -20,6 +20,7 @@ import (
 	"encoding/hex"
 	"errors"
 	"io/ioutil"
+	"net/http"
 
 	"github.com/Sirupsen/logrus"
 	"github.com/minio/sha256-simd"
@@ -31,6 +32,8 @@ type elasticSearchNotify struct {
 	Enable bool   `json:"enable"`
 	URL    string `json:"url"`
 	Index  string `json:"index"`
+	// Skip verifying the TLS certificate of the elasticsearch server.
+	Insecure bool `json:"insecure"`
 }
 
 type elasticClient struct {
@@ -47,6 +50,10 @@ func dialElastic(esNotify elasticSearchNotify) (*elastic.Client, error) {
 		elastic.SetURL(esNotify.URL),
 		elastic.SetSniff(false),
 		elastic.SetMaxRetries(10),
+		elastic.SetHttpClient(&http.Client{Transport: &outboundTransport{
+			insecure: esNotify.Insecure,
+			proxy:    getNotifyProxyURL,
+		}}),
 	)
 	if err != nil {
 		return nil, err
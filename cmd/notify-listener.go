@@ -50,7 +50,7 @@ func newListenerLogger(listenerArn, targetAddr string) (*listenerLogger, error)
 
 	lcLog.Formatter = new(logrus.JSONFormatter)
 
-	lcLog.Hooks.Add(lc)
+	lcLog.Hooks.Add(eventStatsHook{lc, listenerArn})
 
 	return &listenerLogger{lcLog, lc}, nil
 }
@@ -17,6 +17,7 @@
 package cmd
 
 import (
+	"crypto/tls"
 	"io/ioutil"
 
 	"github.com/Sirupsen/logrus"
@@ -34,6 +35,8 @@ type natsNotify struct {
 	Token        string `json:"token"`
 	Secure       bool   `json:"secure"`
 	PingInterval int64  `json:"pingInterval"`
+	// Skip verifying the TLS certificate of the server when secure.
+	Insecure bool `json:"insecure"`
 }
 
 type natsConn struct {
@@ -55,6 +58,10 @@ func dialNATS(natsL natsNotify) (natsConn, error) {
 	natsC.Password = natsL.Password
 	natsC.Token = natsL.Token
 	natsC.Secure = natsL.Secure
+	if natsL.Secure {
+		natsC.TLSConfig = newOutboundTLSConfig(natsL.Insecure)
+		natsC.TLSConfig.MinVersion = tls.VersionTLS12
+	}
 	conn, err := natsC.Connect()
 	if err != nil {
 		return natsConn{}, err
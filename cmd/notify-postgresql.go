@@ -38,16 +38,16 @@ package cmd
 
 import (
 	"database/sql"
+	"database/sql/driver"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
+	"net"
 	"strings"
+	"time"
 
 	"github.com/Sirupsen/logrus"
-
-	// libpq db driver is usually imported blank - see examples in
-	// https://godoc.org/github.com/lib/pq
-	_ "github.com/lib/pq"
+	"github.com/lib/pq"
 )
 
 const (
@@ -64,6 +64,35 @@ WHERE key = $1;`
 	tableExists = `SELECT 1 FROM %s;`
 )
 
+// Driver of connections tunnelled through MINIO_NOTIFY_PROXY.
+const pgProxyDriverName = "minio-postgres-proxy"
+
+func init() {
+	sql.Register(pgProxyDriverName, pgProxyDriver{})
+}
+
+// pgProxyDriver - libpq driver dialing through MINIO_NOTIFY_PROXY.
+type pgProxyDriver struct{}
+
+func (pgProxyDriver) Open(name string) (driver.Conn, error) {
+	return pq.DialOpen(pgProxyDialer{}, name)
+}
+
+// pgProxyDialer - pq.Dialer dialing TCP through MINIO_NOTIFY_PROXY,
+// unix sockets are local and always dialed directly.
+type pgProxyDialer struct{}
+
+func (d pgProxyDialer) Dial(network, addr string) (net.Conn, error) {
+	return d.DialTimeout(network, addr, notifyDialTimeout)
+}
+
+func (pgProxyDialer) DialTimeout(network, addr string, timeout time.Duration) (net.Conn, error) {
+	if network != "tcp" {
+		return net.DialTimeout(network, addr, timeout)
+	}
+	return dialProxy(getNotifyProxyURL(), addr, timeout)
+}
+
 type postgreSQLNotify struct {
 	Enable bool `json:"enable"`
 
@@ -125,7 +154,11 @@ func dialPostgreSQL(pgN postgreSQLNotify) (pgConn, error) {
 		connStr = strings.Join(params, " ")
 	}
 
-	db, err := sql.Open("postgres", connStr)
+	driverName := "postgres"
+	if getNotifyProxyURL() != nil {
+		driverName = pgProxyDriverName
+	}
+	db, err := sql.Open(driverName, connStr)
 	if err != nil {
 		return pgConn{}, fmt.Errorf(
 			"PostgreSQL Notifier Error: Connection opening failure (connectionString=%s): %v",
@@ -18,6 +18,7 @@ package cmd
 
 import (
 	"io/ioutil"
+	"net"
 	"time"
 
 	"github.com/Sirupsen/logrus"
@@ -50,7 +51,13 @@ func dialRedis(rNotify redisNotify) (*redis.Pool, error) {
 		MaxIdle:     3,
 		IdleTimeout: 240 * time.Second, // Time 2minutes.
 		Dial: func() (redis.Conn, error) {
-			c, err := redis.Dial("tcp", addr)
+			var options []redis.DialOption
+			if proxyURL := getNotifyProxyURL(); proxyURL != nil {
+				options = append(options, redis.DialNetDial(func(network, addr string) (net.Conn, error) {
+					return dialProxy(proxyURL, addr, notifyDialTimeout)
+				}))
+			}
+			c, err := redis.Dial("tcp", addr, options...)
 			if err != nil {
 				return nil, err
 			}
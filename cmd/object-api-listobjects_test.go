@@ -429,10 +429,10 @@ func testListObjects(obj ObjectLayer, instanceType string, t TestErrHandler) {
 		{"volatile-bucket-1", "", "", "", 0, ListObjectsInfo{}, BucketNotFound{Bucket: "volatile-bucket-1"}, false},
 		{"volatile-bucket-2", "", "", "", 0, ListObjectsInfo{}, BucketNotFound{Bucket: "volatile-bucket-2"}, false},
 		{"volatile-bucket-3", "", "", "", 0, ListObjectsInfo{}, BucketNotFound{Bucket: "volatile-bucket-3"}, false},
-		// Valid, existing bucket, but sending invalid delimeter values (9-10).
-		// Empty string < "" > and forward slash < / > are the ony two valid arguments for delimeter.
-		{"test-bucket-list-object", "", "", "*", 0, ListObjectsInfo{}, fmt.Errorf("delimiter '%s' is not supported", "*"), false},
-		{"test-bucket-list-object", "", "", "-", 0, ListObjectsInfo{}, fmt.Errorf("delimiter '%s' is not supported", "-"), false},
+		// Valid, existing bucket, with delimiters other than '/' (9-10).
+		// Any delimiter is supported, see testListObjectsWithDelimiter.
+		{"test-bucket-list-object", "", "", "*", 0, ListObjectsInfo{}, nil, true},
+		{"test-bucket-list-object", "", "", "-", 0, ListObjectsInfo{}, nil, true},
 		// Testing for failure cases with both perfix and marker (11).
 		// The prefix and marker combination to be valid it should satisy strings.HasPrefix(marker, prefix).
 		{"test-bucket-list-object", "asia", "europe-object", "", 0, ListObjectsInfo{}, fmt.Errorf("Invalid combination of marker '%s' and prefix '%s'", "europe-object", "asia"), false},
@@ -564,6 +564,78 @@ func testListObjects(obj ObjectLayer, instanceType string, t TestErrHandler) {
 	}
 }
 
+// Wrapper for calling ListObjects tests with arbitrary delimiters for both XL multiple disks and single node setup.
+func TestListObjectsWithDelimiter(t *testing.T) {
+	ExecObjectLayerTest(t, testListObjectsWithDelimiter)
+}
+
+// Tests rolling up keys into common prefixes for delimiters other than '/'.
+func testListObjectsWithDelimiter(obj ObjectLayer, instanceType string, t TestErrHandler) {
+	bucket := "delimiter-bucket"
+	if err := obj.MakeBucket(bucket); err != nil {
+		t.Fatalf("%s : %s", instanceType, err.Error())
+	}
+	for _, object := range []string{
+		"2016-01-01/a",
+		"2016-01-01/b",
+		"2016-01-02/a",
+		"2016-02-01/a",
+		"2016.txt",
+		"other",
+	} {
+		_, err := obj.PutObject(bucket, object, int64(len(object)), bytes.NewBufferString(object), nil, "")
+		if err != nil {
+			t.Fatalf("%s : %s", instanceType, err.Error())
+		}
+	}
+
+	testCases := []struct {
+		prefix      string
+		marker      string
+		delimiter   string
+		maxKeys     int
+		prefixes    []string
+		objects     []string
+		isTruncated bool
+		nextMarker  string
+	}{
+		// Single character delimiter (1-2).
+		{"", "", "-", 1000, []string{"2016-"}, []string{"2016.txt", "other"}, false, "other"},
+		{"2016-", "", "-", 1000, []string{"2016-01-", "2016-02-"}, nil, false, "2016-02-"},
+		// Paginating over common prefixes (3-4).
+		{"2016-", "", "-", 1, []string{"2016-01-"}, nil, true, "2016-01-"},
+		{"2016-", "2016-01-", "-", 1, []string{"2016-02-"}, nil, false, "2016-02-"},
+		// Marker within a common prefix skips the rest of it (5).
+		{"", "2016-01-01/a", "-", 1000, nil, []string{"2016.txt", "other"}, false, "other"},
+		// Multiple character delimiter (6).
+		{"", "", "01/", 1000, []string{"2016-01-01/", "2016-02-01/"}, []string{"2016-01-02/a", "2016.txt", "other"}, false, "other"},
+		// Delimiter not found in any key (7).
+		{"2016-01", "", "*", 2, nil, []string{"2016-01-01/a", "2016-01-01/b"}, true, "2016-01-01/b"},
+	}
+	for i, testCase := range testCases {
+		result, err := obj.ListObjects(bucket, testCase.prefix, testCase.marker, testCase.delimiter, testCase.maxKeys)
+		if err != nil {
+			t.Fatalf("Test %d: %s: Expected to pass, but failed with: <ERROR> %s", i+1, instanceType, err)
+		}
+		if strings.Join(result.Prefixes, ",") != strings.Join(testCase.prefixes, ",") {
+			t.Errorf("Test %d: %s: Expected prefixes %v, got %v", i+1, instanceType, testCase.prefixes, result.Prefixes)
+		}
+		var objects []string
+		for _, objInfo := range result.Objects {
+			objects = append(objects, objInfo.Name)
+		}
+		if strings.Join(objects, ",") != strings.Join(testCase.objects, ",") {
+			t.Errorf("Test %d: %s: Expected objects %v, got %v", i+1, instanceType, testCase.objects, objects)
+		}
+		if result.IsTruncated != testCase.isTruncated {
+			t.Errorf("Test %d: %s: Expected IsTruncated %v, got %v", i+1, instanceType, testCase.isTruncated, result.IsTruncated)
+		}
+		if result.NextMarker != testCase.nextMarker {
+			t.Errorf("Test %d: %s: Expected NextMarker %s, got %s", i+1, instanceType, testCase.nextMarker, result.NextMarker)
+		}
+	}
+}
+
 func initFSObjectsB(disk string, t *testing.B) (obj ObjectLayer) {
 	storageDisks, err := initStorageDisks([]string{disk}, nil)
 	if err != nil {
@@ -1752,6 +1752,146 @@ func testListObjectParts(obj ObjectLayer, instanceType string, t TestErrHandler)
 	}
 }
 
+// Wrapper for calling ListMultipartUploads pagination tests for both XL multiple disks and single node setup.
+func TestListMultipartUploadsPagination(t *testing.T) {
+	ExecObjectLayerTest(t, testListMultipartUploadsPagination)
+}
+
+// testListMultipartUploadsPagination - Tests that paging through the
+// multipart uploads with key and upload id markers lists every upload
+// exactly once, even when the marker upload goes away between pages.
+func testListMultipartUploadsPagination(obj ObjectLayer, instanceType string, t TestErrHandler) {
+	bucket := "minio-bucket"
+	if err := obj.MakeBucket(bucket); err != nil {
+		t.Fatalf("%s : %s", instanceType, err.Error())
+	}
+	// Two uploads for each object, listed in the order they were initiated.
+	objects := []string{"a", "b", "c", "dir/d"}
+	var expected []string
+	for _, object := range objects {
+		for i := 0; i < 2; i++ {
+			uploadID, err := obj.NewMultipartUpload(bucket, object, nil)
+			if err != nil {
+				t.Fatalf("%s : %s", instanceType, err.Error())
+			}
+			expected = append(expected, object+"/"+uploadID)
+		}
+	}
+
+	// Page through all the uploads, one at a time.
+	var listed []string
+	keyMarker, uploadIDMarker := "", ""
+	for i := 0; i <= len(expected); i++ {
+		result, err := obj.ListMultipartUploads(bucket, "", keyMarker, uploadIDMarker, "", 1)
+		if err != nil {
+			t.Fatalf("%s : %s", instanceType, err.Error())
+		}
+		for _, upload := range result.Uploads {
+			listed = append(listed, upload.Object+"/"+upload.UploadID)
+		}
+		if !result.IsTruncated {
+			break
+		}
+		keyMarker, uploadIDMarker = result.NextKeyMarker, result.NextUploadIDMarker
+	}
+	if strings.Join(listed, ",") != strings.Join(expected, ",") {
+		t.Fatalf("%s: Expected uploads %v, got %v", instanceType, expected, listed)
+	}
+
+	// A common prefix filling the page must not end the listing.
+	result, err := obj.ListMultipartUploads(bucket, "", "c", "", slashSeparator, 1)
+	if err != nil {
+		t.Fatalf("%s : %s", instanceType, err.Error())
+	}
+	if len(result.CommonPrefixes) != 1 || result.CommonPrefixes[0] != "dir/" {
+		t.Fatalf("%s: Expected common prefix \"dir/\", got %v", instanceType, result.CommonPrefixes)
+	}
+
+	// Abort the marker upload, the next page must resume with the
+	// remaining upload of the same object.
+	markerID := strings.TrimPrefix(expected[0], "a/")
+	if err = obj.AbortMultipartUpload(bucket, "a", markerID); err != nil {
+		t.Fatalf("%s : %s", instanceType, err.Error())
+	}
+	result, err = obj.ListMultipartUploads(bucket, "", "a", markerID, "", 1)
+	if err != nil {
+		t.Fatalf("%s : %s", instanceType, err.Error())
+	}
+	if len(result.Uploads) != 1 || "a/"+result.Uploads[0].UploadID != expected[1] {
+		t.Fatalf("%s: Expected upload %s after aborted marker, got %v", instanceType, expected[1], result.Uploads)
+	}
+
+	// Abort all the uploads of the marker object, the next page must
+	// resume with the following object.
+	for _, upload := range expected[2:4] {
+		if err = obj.AbortMultipartUpload(bucket, "b", strings.TrimPrefix(upload, "b/")); err != nil {
+			t.Fatalf("%s : %s", instanceType, err.Error())
+		}
+	}
+	result, err = obj.ListMultipartUploads(bucket, "", "b", strings.TrimPrefix(expected[2], "b/"), "", 1)
+	if err != nil {
+		t.Fatalf("%s : %s", instanceType, err.Error())
+	}
+	if len(result.Uploads) != 1 || "c/"+result.Uploads[0].UploadID != expected[4] {
+		t.Fatalf("%s: Expected upload %s after aborted object, got %v", instanceType, expected[4], result.Uploads)
+	}
+}
+
+// Wrapper for calling ListObjectParts pagination tests for both XL multiple disks and single node setup.
+func TestListObjectPartsPagination(t *testing.T) {
+	ExecObjectLayerTest(t, testListObjectPartsPagination)
+}
+
+// testListObjectPartsPagination - Tests that listing parts resumes after
+// the part number marker even when the marker part does not exist.
+func testListObjectPartsPagination(obj ObjectLayer, instanceType string, t TestErrHandler) {
+	bucket, object := "minio-bucket", "minio-object"
+	if err := obj.MakeBucket(bucket); err != nil {
+		t.Fatalf("%s : %s", instanceType, err.Error())
+	}
+	uploadID, err := obj.NewMultipartUpload(bucket, object, nil)
+	if err != nil {
+		t.Fatalf("%s : %s", instanceType, err.Error())
+	}
+	for _, partID := range []int{1, 3, 5} {
+		data := fmt.Sprintf("part-%d", partID)
+		_, err = obj.PutObjectPart(bucket, object, uploadID, partID, int64(len(data)), bytes.NewBufferString(data), "", "")
+		if err != nil {
+			t.Fatalf("%s : %s", instanceType, err.Error())
+		}
+	}
+
+	testCases := []struct {
+		partNumberMarker int
+		maxParts         int
+		expectedParts    []int
+		isTruncated      bool
+	}{
+		{0, 10, []int{1, 3, 5}, false},
+		{1, 1, []int{3}, true},
+		{2, 10, []int{3, 5}, false},
+		{4, 1, []int{5}, false},
+		{5, 10, nil, false},
+		{6, 10, nil, false},
+	}
+	for i, testCase := range testCases {
+		result, err := obj.ListObjectParts(bucket, object, uploadID, testCase.partNumberMarker, testCase.maxParts)
+		if err != nil {
+			t.Fatalf("%s: Test %d: %s", instanceType, i+1, err.Error())
+		}
+		var parts []int
+		for _, part := range result.Parts {
+			parts = append(parts, part.PartNumber)
+		}
+		if fmt.Sprint(parts) != fmt.Sprint(testCase.expectedParts) {
+			t.Errorf("%s: Test %d: Expected parts %v, got %v", instanceType, i+1, testCase.expectedParts, parts)
+		}
+		if result.IsTruncated != testCase.isTruncated {
+			t.Errorf("%s: Test %d: Expected IsTruncated %v, got %v", instanceType, i+1, testCase.isTruncated, result.IsTruncated)
+		}
+	}
+}
+
 // Test for validating complete Multipart upload.
 func TestObjectCompleteMultipartUpload(t *testing.T) {
 	ExecObjectLayerTest(t, testObjectCompleteMultipartUpload)
@@ -1896,12 +2036,12 @@ func testObjectCompleteMultipartUpload(obj ObjectLayer, instanceType string, t T
 		// Asserting for Invalid UploadID (Test number 9).
 		{bucketNames[0], objectNames[0], "abc", []completePart{}, "", InvalidUploadID{UploadID: "abc"}, false},
 		// Test case with invalid Part Etag (Test number 10-11).
-		{bucketNames[0], objectNames[0], uploadIDs[0], []completePart{{ETag: "abc"}}, "", fmt.Errorf("encoding/hex: odd length hex string"), false},
-		{bucketNames[0], objectNames[0], uploadIDs[0], []completePart{{ETag: "abcz"}}, "", fmt.Errorf("encoding/hex: invalid byte: U+007A 'z'"), false},
+		{bucketNames[0], objectNames[0], uploadIDs[0], []completePart{{ETag: "abc"}}, "", InvalidPart{}, false},
+		{bucketNames[0], objectNames[0], uploadIDs[0], []completePart{{ETag: "abcz"}}, "", InvalidPart{}, false},
 		// Part number 0 doesn't exist, expecting InvalidPart error (Test number 12).
 		{bucketNames[0], objectNames[0], uploadIDs[0], []completePart{{ETag: "abcd", PartNumber: 0}}, "", InvalidPart{}, false},
 		// // Upload and PartNumber exists, But a deliberate ETag mismatch is introduced (Test number 13).
-		{bucketNames[0], objectNames[0], uploadIDs[0], inputParts[0].parts, "", BadDigest{}, false},
+		{bucketNames[0], objectNames[0], uploadIDs[0], inputParts[0].parts, "", InvalidPart{}, false},
 		// Test case with non existent object name (Test number 14).
 		{bucketNames[0], "my-object", uploadIDs[0], []completePart{{ETag: "abcd", PartNumber: 1}}, "", InvalidUploadID{UploadID: uploadIDs[0]}, false},
 		// Testing for Part being too small (Test number 15).
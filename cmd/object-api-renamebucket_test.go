@@ -0,0 +1,97 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package cmd
+
+import (
+	"bytes"
+	"testing"
+)
+
+// Wrapper for calling RenameBucket tests for both XL multiple disks and single node setup.
+func TestRenameBucket(t *testing.T) {
+	ExecObjectLayerTest(t, testRenameBucket)
+}
+
+// Testing RenameBucket().
+func testRenameBucket(obj ObjectLayer, instanceType string, t TestErrHandler) {
+	for _, bucket := range []string{"src-bucket", "existing-bucket"} {
+		if err := obj.MakeBucket(bucket); err != nil {
+			t.Fatalf("%s : %s", instanceType, err.Error())
+		}
+	}
+	data := []byte("hello")
+	if _, err := obj.PutObject("src-bucket", "dir/object", int64(len(data)), bytes.NewReader(data), nil, ""); err != nil {
+		t.Fatalf("%s : %s", instanceType, err.Error())
+	}
+	settings := &bucketSettings{CacheControl: "no-cache"}
+	if err := writeBucketSettings("src-bucket", obj, settings); err != nil {
+		t.Fatalf("%s : %s", instanceType, err.Error())
+	}
+
+	testCases := []struct {
+		srcBucket string
+		dstBucket string
+		err       error
+	}{
+		// Test case - 1.
+		{"src-bucket", "Dst", BucketNameInvalid{Bucket: "Dst"}},
+		// Test case - 2.
+		{"missing-bucket", "dst-bucket", BucketNotFound{Bucket: "missing-bucket"}},
+		// Test case - 3.
+		{"src-bucket", "existing-bucket", BucketExists{Bucket: "existing-bucket"}},
+		// Test case - 4.
+		{"src-bucket", "dst-bucket", nil},
+	}
+	for i, testCase := range testCases {
+		err := errorCause(obj.RenameBucket(testCase.srcBucket, testCase.dstBucket))
+		if err != testCase.err {
+			t.Fatalf("%s: Test %d: Expected error %v, got %v", instanceType, i+1, testCase.err, err)
+		}
+	}
+
+	// Objects and metadata are found under the new name only.
+	if _, err := obj.GetBucketInfo("src-bucket"); !isBucketNotFound(errorCause(err)) {
+		t.Errorf("%s: Expected BucketNotFound, got %v", instanceType, err)
+	}
+	var buffer bytes.Buffer
+	if err := obj.GetObject("dst-bucket", "dir/object", 0, int64(len(data)), &buffer); err != nil {
+		t.Fatalf("%s : %s", instanceType, err.Error())
+	}
+	if !bytes.Equal(buffer.Bytes(), data) {
+		t.Errorf("%s: Expected object data %q, got %q", instanceType, data, buffer.Bytes())
+	}
+	if _, err := readBucketSettings("src-bucket", obj); err != (BucketSettingsNotFound{Bucket: "src-bucket"}) {
+		t.Errorf("%s: Expected settings of the old name to be gone, got %v", instanceType, err)
+	}
+	renamedSettings, err := readBucketSettings("dst-bucket", obj)
+	if err != nil {
+		t.Fatalf("%s : %s", instanceType, err.Error())
+	}
+	if renamedSettings.CacheControl != settings.CacheControl {
+		t.Errorf("%s: Expected Cache-Control %s, got %s", instanceType, settings.CacheControl, renamedSettings.CacheControl)
+	}
+
+	// The old name can be used again.
+	if err := obj.MakeBucket("src-bucket"); err != nil {
+		t.Fatalf("%s : %s", instanceType, err.Error())
+	}
+}
+
+// isBucketNotFound - returns true if err is BucketNotFound.
+func isBucketNotFound(err error) bool {
+	_, ok := err.(BucketNotFound)
+	return ok
+}
@@ -0,0 +1,210 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package cmd
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+
+	mux "github.com/gorilla/mux"
+)
+
+// Formats of archives generated by GetArchiveHandler.
+const (
+	archiveTar = "tar"
+	archiveZip = "zip"
+)
+
+// Number of objects listed at a time while generating an archive,
+// bounds the memory used for archives of any size.
+const archiveListKeys = 1000
+
+// isValidArchiveFormat - returns if archives of format can be generated.
+func isValidArchiveFormat(format string) bool {
+	return format == archiveTar || format == archiveZip
+}
+
+// getArchiveFilename - returns the file name of the archive of prefix,
+// named after its last path element or the bucket.
+func getArchiveFilename(bucket, prefix, format string) string {
+	name := path.Base(strings.TrimSuffix(prefix, slashSeparator))
+	if name == "." || name == slashSeparator {
+		name = bucket
+	}
+	return name + "." + format
+}
+
+// setArchiveHeaders - sets the headers of an archive response.
+func setArchiveHeaders(w http.ResponseWriter, bucket, prefix, format string) {
+	contentType := "application/x-tar"
+	if format == archiveZip {
+		contentType = "application/zip"
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s\"", getArchiveFilename(bucket, prefix, format)))
+}
+
+// writeObjectsArchive - streams all the objects under prefix as a tar
+// or zip archive, objects are named relative to the directory of
+// prefix. Objects for which allowed returns false are left out. Only
+// a single page of the listing is held in memory at a time.
+func writeObjectsArchive(w io.Writer, objAPI ObjectLayer, bucket, prefix, format string, allowed func(object string) bool) error {
+	var tarWriter *tar.Writer
+	var zipWriter *zip.Writer
+	if format == archiveZip {
+		zipWriter = zip.NewWriter(w)
+	} else {
+		tarWriter = tar.NewWriter(w)
+	}
+
+	// "photos/2016/" and "photos/20" are both archived as "2016/...".
+	baseDir := ""
+	if i := strings.LastIndex(strings.TrimSuffix(prefix, slashSeparator), slashSeparator); i >= 0 {
+		baseDir = prefix[:i+1]
+	}
+
+	marker := ""
+	for {
+		result, err := objAPI.ListObjects(bucket, prefix, marker, "", archiveListKeys)
+		if err != nil {
+			return err
+		}
+		for _, objInfo := range result.Objects {
+			// Directory markers have no content.
+			if strings.HasSuffix(objInfo.Name, slashSeparator) || !allowed(objInfo.Name) {
+				continue
+			}
+			name := strings.TrimPrefix(objInfo.Name, baseDir)
+			var entryWriter io.Writer
+			if zipWriter != nil {
+				header := &zip.FileHeader{Name: name, Method: zip.Deflate}
+				header.SetModTime(objInfo.ModTime)
+				if entryWriter, err = zipWriter.CreateHeader(header); err != nil {
+					return err
+				}
+			} else {
+				err = tarWriter.WriteHeader(&tar.Header{
+					Name:     name,
+					Mode:     0644,
+					Size:     objInfo.Size,
+					ModTime:  objInfo.ModTime,
+					Typeflag: tar.TypeReg,
+				})
+				if err != nil {
+					return err
+				}
+				entryWriter = tarWriter
+			}
+			if err = objAPI.GetObject(bucket, objInfo.Name, 0, objInfo.Size, entryWriter); err != nil {
+				return err
+			}
+		}
+		if !result.IsTruncated {
+			break
+		}
+		marker = result.NextMarker
+	}
+
+	if zipWriter != nil {
+		return zipWriter.Close()
+	}
+	return tarWriter.Close()
+}
+
+// GetArchiveHandler - GET Object archive (minio extension)
+// -----------
+// This operation returns all the objects under a prefix, or the whole
+// bucket, as a tar or zip archive given by the archive query. Archives
+// are generated while they are streamed. Objects served through the
+// transformation webhook of the bucket are left out, as are objects
+// which anonymous requests may not read.
+func (api objectAPIHandlers) GetArchiveHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	bucket := vars["bucket"]
+	prefix := vars["object"]
+
+	objectAPI := api.ObjectAPI()
+	if objectAPI == nil {
+		writeErrorResponse(w, r, ErrServerNotInitialized, r.URL.Path)
+		return
+	}
+
+	anonymous := false
+	switch getRequestAuthType(r) {
+	default:
+		// For all unknown auth types return error.
+		writeErrorResponse(w, r, ErrAccessDenied, r.URL.Path)
+		return
+	case authTypeAnonymous:
+		// http://docs.aws.amazon.com/AmazonS3/latest/dev/using-with-s3-actions.html
+		if s3Error := enforceBucketPolicy(bucket, "s3:ListBucket", &url.URL{Path: "/" + bucket}); s3Error != ErrNone {
+			writeErrorResponse(w, r, s3Error, r.URL.Path)
+			return
+		}
+		anonymous = true
+	case authTypePresignedV2, authTypeSignedV2:
+		// Signature V2 validation.
+		if s3Error := isReqAuthenticatedV2(r); s3Error != ErrNone {
+			errorIf(errSignatureMismatch, dumpRequest(r))
+			writeErrorResponse(w, r, s3Error, r.URL.Path)
+			return
+		}
+	case authTypePresigned, authTypeSigned:
+		if s3Error := isReqAuthenticated(r, serverConfig.GetRegion()); s3Error != ErrNone {
+			errorIf(errSignatureMismatch, dumpRequest(r))
+			writeErrorResponse(w, r, s3Error, r.URL.Path)
+			return
+		}
+	}
+
+	format := vars["archive"]
+	if !isValidArchiveFormat(format) {
+		writeErrorResponse(w, r, ErrInvalidArchiveFormat, r.URL.Path)
+		return
+	}
+	if _, err := objectAPI.GetBucketInfo(bucket); err != nil {
+		errorIf(err, "Unable to fetch bucket info.")
+		writeErrorResponse(w, r, toAPIErrorCode(err), r.URL.Path)
+		return
+	}
+
+	allowed := func(object string) bool {
+		// Archives would bypass the transformation webhook,
+		// which may redact objects.
+		if getBucketTransform(bucket, object) != nil {
+			return false
+		}
+		if anonymous {
+			objectURL := &url.URL{Path: "/" + bucket + "/" + object}
+			return enforceBucketPolicy(bucket, "s3:GetObject", objectURL) == ErrNone
+		}
+		return true
+	}
+
+	setCommonHeaders(w)
+	setArchiveHeaders(w, bucket, prefix, format)
+	w.WriteHeader(http.StatusOK)
+	// Errors cannot be reported once the archive is being sent,
+	// clients notice the archive being truncated.
+	err := writeObjectsArchive(w, objectAPI, bucket, prefix, format, allowed)
+	errorIf(err, "Unable to write archive of %s/%s.", bucket, prefix)
+}
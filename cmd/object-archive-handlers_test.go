@@ -0,0 +1,150 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package cmd
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+// Tests naming archives after the prefix.
+func TestGetArchiveFilename(t *testing.T) {
+	testCases := []struct {
+		prefix   string
+		format   string
+		expected string
+	}{
+		{"", archiveTar, "bucket.tar"},
+		{"photos/", archiveZip, "photos.zip"},
+		{"photos/2016/", archiveTar, "2016.tar"},
+		{"photos/20", archiveTar, "20.tar"},
+	}
+	for i, testCase := range testCases {
+		if name := getArchiveFilename("bucket", testCase.prefix, testCase.format); name != testCase.expected {
+			t.Errorf("Test %d: Expected %s, got %s", i+1, testCase.expected, name)
+		}
+	}
+}
+
+// Returns the names and contents of the entries of a tar or zip archive.
+func readTestArchive(format string, data []byte) (map[string]string, error) {
+	entries := make(map[string]string)
+	if format == archiveZip {
+		zipReader, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+		if err != nil {
+			return nil, err
+		}
+		for _, file := range zipReader.File {
+			reader, err := file.Open()
+			if err != nil {
+				return nil, err
+			}
+			content, err := ioutil.ReadAll(reader)
+			reader.Close()
+			if err != nil {
+				return nil, err
+			}
+			entries[file.Name] = string(content)
+		}
+		return entries, nil
+	}
+	tarReader := tar.NewReader(bytes.NewReader(data))
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			return entries, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		content, err := ioutil.ReadAll(tarReader)
+		if err != nil {
+			return nil, err
+		}
+		entries[header.Name] = string(content)
+	}
+}
+
+// Wrapper for calling GetArchive HTTP handler tests for both XL multiple disks and single node setup.
+func TestGetArchiveHandler(t *testing.T) {
+	ExecObjectLayerAPITest(t, testGetArchiveHandler, []string{"GetArchive"})
+}
+
+func testGetArchiveHandler(obj ObjectLayer, instanceType, bucketName string, apiRouter http.Handler,
+	credentials credential, t *testing.T) {
+	objects := map[string]string{
+		"photos/2016/a.txt": "a",
+		"photos/2016/b.txt": "bb",
+		"photos/other.txt":  "other",
+		"docs/c.txt":        "c",
+	}
+	for object, content := range objects {
+		if _, err := obj.PutObject(bucketName, object, int64(len(content)), bytes.NewReader([]byte(content)), nil, ""); err != nil {
+			t.Fatalf("%s: %s", instanceType, err)
+		}
+	}
+
+	testCases := []struct {
+		prefix             string
+		format             string
+		expectedStatusCode int
+		expectedEntries    map[string]string
+	}{
+		{"photos/2016/", archiveTar, http.StatusOK, map[string]string{"2016/a.txt": "a", "2016/b.txt": "bb"}},
+		{"photos/", archiveZip, http.StatusOK, map[string]string{"photos/2016/a.txt": "a", "photos/2016/b.txt": "bb", "photos/other.txt": "other"}},
+		{"", archiveZip, http.StatusOK, objects},
+		{"missing/", archiveTar, http.StatusOK, map[string]string{}},
+		{"photos/", "rar", http.StatusBadRequest, nil},
+	}
+	for i, testCase := range testCases {
+		queryValues := url.Values{}
+		queryValues.Set("archive", testCase.format)
+		rec := httptest.NewRecorder()
+		req, err := newTestSignedRequestV4("GET", makeTestTargetURL("", bucketName, testCase.prefix, queryValues),
+			0, nil, credentials.AccessKeyID, credentials.SecretAccessKey)
+		if err != nil {
+			t.Fatalf("%s: Failed to create HTTP request: <ERROR> %v", instanceType, err)
+		}
+		apiRouter.ServeHTTP(rec, req)
+		if rec.Code != testCase.expectedStatusCode {
+			t.Fatalf("%s: Test %d: Expected %d, got %d", instanceType, i+1, testCase.expectedStatusCode, rec.Code)
+		}
+		if rec.Code != http.StatusOK {
+			continue
+		}
+		entries, err := readTestArchive(testCase.format, rec.Body.Bytes())
+		if err != nil {
+			t.Fatalf("%s: Test %d: Unable to read archive: %s", instanceType, i+1, err)
+		}
+		if !reflect.DeepEqual(entries, testCase.expectedEntries) {
+			var names []string
+			for name := range entries {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			t.Errorf("%s: Test %d: Unexpected archive entries %v", instanceType, i+1, names)
+		}
+	}
+}
@@ -0,0 +1,123 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package cmd
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/minio/minio-go/pkg/set"
+)
+
+const (
+	// Minio extension header, set to cacheBypassValue to neither serve
+	// the object from the object cache nor save it in the cache.
+	cacheBypassHeader = "X-Minio-Cache"
+	cacheBypassValue  = "bypass"
+
+	// Internal metadata of a new object set to cacheBypassValue to not
+	// save it in the cache, object layers never store it.
+	cacheBypassMetaKey = "X-Minio-Internal-Cache-Bypass"
+)
+
+var (
+	// Objects larger than this are not saved in the object cache, 0
+	// for no limit.
+	globalCacheMaxObjectSize int64
+	// Buckets whose objects are saved in the object cache, empty for
+	// all the buckets.
+	globalCacheBuckets = set.NewStringSet()
+)
+
+// errInvalidCacheBucket - MINIO_CACHE_BUCKETS lists an invalid bucket name.
+var errInvalidCacheBucket = errors.New("Invalid bucket name in cache buckets")
+
+// parseCacheBuckets - parses a comma separated list of bucket names.
+func parseCacheBuckets(s string) (set.StringSet, error) {
+	buckets := set.NewStringSet()
+	for _, bucket := range strings.Split(s, ",") {
+		bucket = strings.TrimSpace(bucket)
+		if bucket == "" {
+			continue
+		}
+		if !IsValidBucketName(bucket) {
+			return nil, errInvalidCacheBucket
+		}
+		buckets.Add(bucket)
+	}
+	return buckets, nil
+}
+
+// isCacheBypassRequested - returns if the object cache should be
+// bypassed for the request.
+func isCacheBypassRequested(r *http.Request) bool {
+	return strings.EqualFold(r.Header.Get(cacheBypassHeader), cacheBypassValue)
+}
+
+// cacheBypassWriter - marks the writer of a GetObject as bypassing the
+// object cache.
+type cacheBypassWriter struct {
+	io.Writer
+}
+
+// isCacheBypassWriter - returns if writer was marked to bypass the cache.
+func isCacheBypassWriter(writer io.Writer) bool {
+	_, ok := writer.(cacheBypassWriter)
+	return ok
+}
+
+// keepCacheBypassWriter - marks writer to bypass the cache if the
+// writer it wraps was, for object layers wrapping the writers they are
+// given.
+func keepCacheBypassWriter(wrapped, writer io.Writer) io.Writer {
+	if isCacheBypassWriter(wrapped) {
+		return cacheBypassWriter{writer}
+	}
+	return writer
+}
+
+// takeCacheBypass - removes the cache bypass marker from the metadata
+// of a new object, returns if the object should bypass the cache.
+func takeCacheBypass(metadata map[string]string) bool {
+	if metadata[cacheBypassMetaKey] == "" {
+		return false
+	}
+	delete(metadata, cacheBypassMetaKey)
+	return true
+}
+
+// cacheBypassMetadata - returns the metadata of a new object marking it
+// to bypass the cache if bypass is set.
+func cacheBypassMetadata(bypass bool) map[string]string {
+	if !bypass {
+		return nil
+	}
+	return map[string]string{cacheBypassMetaKey: cacheBypassValue}
+}
+
+// objCacheAdmits - returns if an object of size bytes in bucket may
+// be saved in the object cache.
+func objCacheAdmits(bucket string, size int64) bool {
+	if globalCacheMaxObjectSize > 0 && size > globalCacheMaxObjectSize {
+		return false
+	}
+	if !globalCacheBuckets.IsEmpty() && !globalCacheBuckets.Contains(bucket) {
+		return false
+	}
+	return true
+}
@@ -0,0 +1,193 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package cmd
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/mf-00/newgo/pkg/objcache"
+	"github.com/minio/minio-go/pkg/set"
+)
+
+// Tests parsing the buckets saved in the object cache.
+func TestParseCacheBuckets(t *testing.T) {
+	testCases := []struct {
+		value   string
+		buckets set.StringSet
+		err     error
+	}{
+		{"photos", set.CreateStringSet("photos"), nil},
+		{"photos, thumbnails,", set.CreateStringSet("photos", "thumbnails"), nil},
+		{"photos,Invalid_Bucket", nil, errInvalidCacheBucket},
+	}
+	for i, testCase := range testCases {
+		buckets, err := parseCacheBuckets(testCase.value)
+		if err != testCase.err {
+			t.Errorf("Test %d: expected %v, got %v", i+1, testCase.err, err)
+			continue
+		}
+		if err == nil && !buckets.Equals(testCase.buckets) {
+			t.Errorf("Test %d: expected %v, got %v", i+1, testCase.buckets, buckets)
+		}
+	}
+}
+
+// Tests the admission of objects into the object cache.
+func TestObjCacheAdmits(t *testing.T) {
+	defer func() {
+		globalCacheMaxObjectSize = 0
+		globalCacheBuckets = set.NewStringSet()
+	}()
+
+	if !objCacheAdmits("bucket", 1<<30) {
+		t.Error("Expected all objects to be admitted by default")
+	}
+	globalCacheMaxObjectSize = 1024
+	globalCacheBuckets = set.CreateStringSet("bucket")
+	testCases := []struct {
+		bucket string
+		size   int64
+		admits bool
+	}{
+		{"bucket", 1024, true},
+		{"bucket", 1025, false},
+		{"other", 1, false},
+	}
+	for i, testCase := range testCases {
+		if admits := objCacheAdmits(testCase.bucket, testCase.size); admits != testCase.admits {
+			t.Errorf("Test %d: expected %v, got %v", i+1, testCase.admits, admits)
+		}
+	}
+}
+
+// Tests the cache bypass header is recognized.
+func TestIsCacheBypassRequested(t *testing.T) {
+	r, err := http.NewRequest("GET", "http://localhost:9000/bucket/object", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if isCacheBypassRequested(r) {
+		t.Error("Expected no cache bypass without the header")
+	}
+	r.Header.Set(cacheBypassHeader, "Bypass")
+	if !isCacheBypassRequested(r) {
+		t.Error("Expected cache bypass with the header set")
+	}
+}
+
+// Tests XL honours the cache admission policy and bypass requests.
+func TestXLObjCacheBypass(t *testing.T) {
+	obj, fsDirs, err := prepareXL()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer removeRoots(fsDirs)
+	defer func() {
+		globalCacheBuckets = set.NewStringSet()
+	}()
+
+	xl := obj.(xlObjects)
+	xl.objCache = objcache.New(1<<20, objcache.NoExpiry)
+	xl.objCacheEnabled = true
+	if err = xl.MakeBucket("bucket"); err != nil {
+		t.Fatal(err)
+	}
+	data := []byte("hello")
+	put := func(bypass bool) {
+		if _, err := xl.PutObject("bucket", "object", int64(len(data)), bytes.NewReader(data), cacheBypassMetadata(bypass), ""); err != nil {
+			t.Fatal(err)
+		}
+	}
+	entries := func() int {
+		stats, err := xl.CacheStats()
+		if err != nil {
+			t.Fatal(err)
+		}
+		return stats.Entries
+	}
+
+	put(true)
+	if n := entries(); n != 0 {
+		t.Fatalf("Expected bypassed upload not to be cached, got %d entries", n)
+	}
+	if err = xl.GetObject("bucket", "object", 0, int64(len(data)), cacheBypassWriter{ioutil.Discard}); err != nil {
+		t.Fatal(err)
+	}
+	if n := entries(); n != 0 {
+		t.Fatalf("Expected bypassed download not to be cached, got %d entries", n)
+	}
+
+	put(false)
+	if n := entries(); n != 1 {
+		t.Fatalf("Expected upload to be cached, got %d entries", n)
+	}
+
+	// Uploads to buckets not admitted invalidate the cached object.
+	globalCacheBuckets = set.CreateStringSet("other")
+	put(false)
+	if n := entries(); n != 0 {
+		t.Fatalf("Expected upload not to be cached, got %d entries", n)
+	}
+	if err = xl.GetObject("bucket", "object", 0, int64(len(data)), ioutil.Discard); err != nil {
+		t.Fatal(err)
+	}
+	stats, err := xl.CacheStats()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.Entries != 0 || stats.Hits != 0 || stats.Misses != 1 {
+		t.Fatalf("Expected a single cache miss, got %+v", stats)
+	}
+
+	// Bypass requests go through the layers wrapping the object layer,
+	// and are never stored.
+	globalCacheBuckets = set.NewStringSet()
+	globalSSEMasterKey = bytes.Repeat([]byte{1}, sseMasterKeySize)
+	defer func() { globalSSEMasterKey = nil }()
+	if err = xl.MakeBucket("dedup"); err != nil {
+		t.Fatal(err)
+	}
+	globalBucketSettings.SetBucketSettings("dedup", &bucketSettings{Dedup: true})
+	defer globalBucketSettings.SetBucketSettings("dedup", nil)
+	layered := newEncryptedObjects(newDedupObjects(xl))
+	for _, bucket := range []string{"bucket", "dedup"} {
+		metadata := map[string]string{sseMetaAlgorithm: sseAlgorithmAES256, cacheBypassMetaKey: cacheBypassValue}
+		if _, err = layered.PutObject(bucket, "encrypted", int64(len(data)), bytes.NewReader(data), metadata, ""); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err = xl.objCache.Open(path.Join("bucket", "encrypted"), time.Time{}); err == nil {
+		t.Error("Expected bypassed encrypted upload not to be cached")
+	}
+	objInfo, err := xl.GetObjectInfo("bucket", "encrypted")
+	if err != nil || objInfo.UserDefined[cacheBypassMetaKey] != "" {
+		t.Errorf("Expected the bypass marker not to be stored, got %+v (%v)", objInfo.UserDefined, err)
+	}
+	manifest, err := readManifest(xl, "dedup", "encrypted")
+	if err != nil || manifest.UserDefined[cacheBypassMetaKey] != "" {
+		t.Fatalf("Expected the bypass marker not to be stored, got %+v (%v)", manifest.UserDefined, err)
+	}
+	for _, chunk := range manifest.Chunks {
+		if _, err = xl.objCache.Open(path.Join(minioMetaBucket, dedupChunkPath("dedup", chunk.Hash)), time.Time{}); err == nil {
+			t.Errorf("Expected bypassed chunk %s not to be cached", chunk.Hash)
+		}
+	}
+}
@@ -58,10 +58,11 @@ func isErrIgnored(err error, ignoredErrs []error) bool {
 // House keeping code needed for FS.
 func fsHouseKeeping(storageDisk StorageAPI) error {
 	// Cleanup all temp entries upon start.
-	err := cleanupDir(storageDisk, minioMetaBucket, tmpMetaPrefix)
+	count, bytes, err := cleanupTmpEntries(storageDisk, 0)
 	if err != nil {
 		return toObjectErr(err, minioMetaBucket, tmpMetaPrefix)
 	}
+	globalTmpCleanupStats.update(count, bytes)
 	return nil
 }
 
@@ -116,9 +117,15 @@ func isLocalStorage(networkPath string) bool {
 // Depending on the disk type network or local, initialize storage API.
 func newStorageAPI(disk string) (storage StorageAPI, err error) {
 	if isLocalStorage(disk) {
-		return newPosix(disk)
+		storage, err = newPosix(disk)
+	} else {
+		storage, err = newRPCClient(disk)
 	}
-	return newRPCClient(disk)
+	// Inject configured faults for chaos testing.
+	if err == nil && globalFaultInjection {
+		storage = newFaultyDisk(storage)
+	}
+	return storage, err
 }
 
 // Initializes meta volume on all input storage disks.
@@ -191,7 +198,8 @@ func xlHouseKeeping(storageDisks []StorageAPI) error {
 			defer wg.Done()
 
 			// Cleanup all temp entries upon start.
-			err := cleanupDir(disk, minioMetaBucket, tmpMetaPrefix)
+			count, bytes, err := cleanupTmpEntries(disk, 0)
+			globalTmpCleanupStats.update(count, bytes)
 			if err != nil {
 				switch errorCause(err) {
 				case errDiskNotFound, errVolumeNotFound, errFileNotFound:
@@ -248,6 +256,48 @@ func cleanupDir(storage StorageAPI, volume, dirPath string) error {
 	return err
 }
 
+// Prefixes of the meta bucket holding metadata of each bucket under
+// '<prefix>/<bucket>/', moved along with renamed buckets.
+var bucketMetaPrefixes = []string{
+	bucketConfigPrefix,
+	mpartMetaPrefix,
+	browserUploadsPrefix,
+	searchPrefix,
+	dedupPrefix,
+	resumablePrefix,
+	trashPrefix,
+	thumbnailPrefix,
+}
+
+// renameBucketMetadata - moves all the metadata of srcBucket to
+// dstBucket on disk. Stale metadata left by a deleted bucket named
+// dstBucket is removed first.
+func renameBucketMetadata(disk StorageAPI, srcBucket, dstBucket string) error {
+	for _, prefix := range bucketMetaPrefixes {
+		srcPath := retainSlash(pathJoin(prefix, srcBucket))
+		dstPath := retainSlash(pathJoin(prefix, dstBucket))
+		if err := cleanupDir(disk, minioMetaBucket, dstPath); err != nil {
+			return err
+		}
+		if err := disk.RenameFile(minioMetaBucket, srcPath, minioMetaBucket, dstPath); err != nil {
+			if err == errFileNotFound || err == errVolumeNotFound {
+				continue
+			}
+			return traceError(err)
+		}
+	}
+	return nil
+}
+
+// renameBucketErr - converts errors renaming srcBucket to dstBucket,
+// volume errors refer to the bucket they are about.
+func renameBucketErr(err error, srcBucket, dstBucket string) error {
+	if errorCause(err) == errVolumeExists {
+		return toObjectErr(err, dstBucket)
+	}
+	return toObjectErr(err, srcBucket)
+}
+
 // Checks whether bucket exists.
 func isBucketExist(bucket string, obj ObjectLayer) error {
 	if !IsValidBucketName(bucket) {
@@ -259,3 +309,50 @@ func isBucketExist(bucket string, obj ObjectLayer) error {
 	}
 	return nil
 }
+
+// listObjectsWithDelimiter - lists objects like S3 does for delimiters
+// other than '/', which the tree walk handles natively. Keys are listed
+// recursively with listFn and every key containing delimiter after the
+// prefix is rolled up into a common prefix ending at its first
+// occurrence, counting once towards maxKeys. Keys sharing a prefix are
+// adjacent in the listing, so common prefixes come out sorted and are
+// never repeated across pages as long as NextMarker is passed back.
+func listObjectsWithDelimiter(listFn func(marker string, maxKeys int) (ListObjectsInfo, error), prefix, marker, delimiter string, maxKeys int) (ListObjectsInfo, error) {
+	var result ListObjectsInfo
+	var lastPrefix string
+	var count int
+	listMarker := marker
+	for {
+		listInfo, err := listFn(listMarker, maxObjectList)
+		if err != nil {
+			return ListObjectsInfo{}, err
+		}
+		for _, objInfo := range listInfo.Objects {
+			var commonPrefix string
+			if idx := strings.Index(objInfo.Name[len(prefix):], delimiter); idx != -1 {
+				commonPrefix = objInfo.Name[:len(prefix)+idx+len(delimiter)]
+			}
+			// Rolled up already, in this page or in the page ending at marker.
+			if commonPrefix != "" && (commonPrefix == lastPrefix || commonPrefix <= marker) {
+				continue
+			}
+			if count == maxKeys {
+				result.IsTruncated = true
+				return result, nil
+			}
+			count++
+			if commonPrefix != "" {
+				lastPrefix = commonPrefix
+				result.Prefixes = append(result.Prefixes, commonPrefix)
+				result.NextMarker = commonPrefix
+				continue
+			}
+			result.Objects = append(result.Objects, objInfo)
+			result.NextMarker = objInfo.Name
+		}
+		if !listInfo.IsTruncated {
+			return result, nil
+		}
+		listMarker = listInfo.NextMarker
+	}
+}
@@ -235,12 +235,16 @@ type completePart struct {
 	ETag string
 }
 
-// completedParts - is a collection satisfying sort.Interface.
-type completedParts []completePart
-
-func (a completedParts) Len() int           { return len(a) }
-func (a completedParts) Swap(i, j int)      { a[i], a[j] = a[j], a[i] }
-func (a completedParts) Less(i, j int) bool { return a[i].PartNumber < a[j].PartNumber }
+// isPartOrderValid - returns if parts are in strictly ascending order
+// of part numbers, a part may only be listed once.
+func isPartOrderValid(parts []completePart) bool {
+	for i := 1; i < len(parts); i++ {
+		if parts[i].PartNumber <= parts[i-1].PartNumber {
+			return false
+		}
+	}
+	return true
+}
 
 // completeMultipartUpload - represents input fields for completing multipart upload.
 type completeMultipartUpload struct {
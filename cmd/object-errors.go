@@ -145,6 +145,27 @@ func (e ObjectExistsAsDirectory) Error() string {
 	return "Object exists on : " + e.Bucket + " as directory " + e.Object
 }
 
+// ObjectImmutable object cannot be overwritten or deleted.
+type ObjectImmutable GenericError
+
+func (e ObjectImmutable) Error() string {
+	return "Object is immutable until its retention elapses: " + e.Bucket + "#" + e.Object
+}
+
+// ObjectCorrupted object data doesn't match its checksums on some disks.
+type ObjectCorrupted GenericError
+
+func (e ObjectCorrupted) Error() string {
+	return "Object is corrupted: " + e.Bucket + "#" + e.Object
+}
+
+// ObjectAlreadyExists object exists.
+type ObjectAlreadyExists GenericError
+
+func (e ObjectAlreadyExists) Error() string {
+	return "Object already exists: " + e.Bucket + "#" + e.Object
+}
+
 // BucketExists bucket exists.
 type BucketExists GenericError
 
@@ -152,6 +173,27 @@ func (e BucketExists) Error() string {
 	return "Bucket exists: " + e.Bucket
 }
 
+// BucketAlreadyExists bucket is owned by another cluster of the federation.
+type BucketAlreadyExists GenericError
+
+func (e BucketAlreadyExists) Error() string {
+	return "Bucket is owned by another cluster: " + e.Bucket
+}
+
+// BucketNameNotAllowed bucket name does not meet the naming policy.
+type BucketNameNotAllowed GenericError
+
+func (e BucketNameNotAllowed) Error() string {
+	return "Bucket name is not allowed by the bucket naming policy: " + e.Bucket
+}
+
+// TooManyBuckets bucket cannot be created, the maximum number of buckets exist.
+type TooManyBuckets GenericError
+
+func (e TooManyBuckets) Error() string {
+	return "Maximum number of buckets reached, cannot create bucket: " + e.Bucket
+}
+
 // BadDigest - Content-MD5 you specified did not match what we received.
 type BadDigest struct {
 	ExpectedMD5   string
@@ -189,6 +231,13 @@ func (e InvalidMarkerPrefixCombination) Error() string {
 	return fmt.Sprintf("Invalid combination of marker '%s' and prefix '%s'", e.Marker, e.Prefix)
 }
 
+// BucketSettingsNotFound - no bucket settings found.
+type BucketSettingsNotFound GenericError
+
+func (e BucketSettingsNotFound) Error() string {
+	return "No bucket settings found for bucket: " + e.Bucket
+}
+
 // BucketPolicyNotFound - no bucket policy found.
 type BucketPolicyNotFound GenericError
 
@@ -0,0 +1,269 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Metadata holding the modified time of an archive entry, set on
+// every object expanded from an archive.
+const extractModTimeMeta = "X-Amz-Meta-Mtime"
+
+// errInvalidArchive means the uploaded archive could not be read or
+// has entries which would end up outside of the target prefix.
+var errInvalidArchive = errors.New("Archive is malformed or has invalid entry names")
+
+// isExtractRequested - returns if the uploaded archive should be
+// expanded into objects.
+func isExtractRequested(r *http.Request) bool {
+	return strings.EqualFold(r.Header.Get(extractHeader), "true")
+}
+
+// getExtractFormat - returns the format of the archive uploaded as
+// object, given by its extension, or "" if it is not an archive.
+func getExtractFormat(object string) string {
+	switch strings.ToLower(path.Ext(object)) {
+	case ".tar":
+		return archiveTar
+	case ".zip":
+		return archiveZip
+	}
+	return ""
+}
+
+// getExtractObjectName - returns the name of the object an archive
+// entry is expanded to under prefix. Entries with absolute names or
+// names leading out of prefix are rejected.
+func getExtractObjectName(prefix, name string) (string, error) {
+	if name == "" || path.IsAbs(name) {
+		return "", errInvalidArchive
+	}
+	name = path.Clean(name)
+	if name == "." || name == ".." || strings.HasPrefix(name, "../") {
+		return "", errInvalidArchive
+	}
+	object := prefix + name
+	if !IsValidObjectName(object) {
+		return "", errInvalidArchive
+	}
+	return object, nil
+}
+
+// archiveEntry - regular file of an archive.
+type archiveEntry struct {
+	Name    string
+	ModTime time.Time
+	Size    int64
+}
+
+// walkArchive - calls fn for every regular file of the archive held
+// in file, directories and links are skipped.
+func walkArchive(file *os.File, size int64, format string, fn func(entry archiveEntry, data io.Reader) error) error {
+	if format == archiveZip {
+		zipReader, err := zip.NewReader(file, size)
+		if err != nil {
+			return errInvalidArchive
+		}
+		for _, f := range zipReader.File {
+			if !f.Mode().IsRegular() {
+				continue
+			}
+			data, err := f.Open()
+			if err != nil {
+				return errInvalidArchive
+			}
+			err = fn(archiveEntry{f.Name, f.ModTime(), int64(f.UncompressedSize64)}, data)
+			data.Close()
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	if _, err := file.Seek(0, 0); err != nil {
+		return err
+	}
+	tarReader := tar.NewReader(file)
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return errInvalidArchive
+		}
+		if header.Typeflag != tar.TypeReg && header.Typeflag != tar.TypeRegA {
+			continue
+		}
+		if err = fn(archiveEntry{header.Name, header.ModTime, header.Size}, tarReader); err != nil {
+			return err
+		}
+	}
+}
+
+// spoolArchive - saves the uploaded archive to a temporary file,
+// archives are read from start to end more than once. The md5sum and
+// sha256sum of the upload are verified if given.
+func spoolArchive(reader io.Reader, size int64, md5Hex, sha256sum string) (*os.File, int64, error) {
+	file, err := ioutil.TempFile("", "minio-extract-")
+	if err != nil {
+		return nil, 0, err
+	}
+	// Nothing refers to the file by name, it is gone once closed.
+	os.Remove(file.Name())
+
+	md5Writer := md5.New()
+	sha256Writer := sha256.New()
+	n, err := io.Copy(io.MultiWriter(file, md5Writer, sha256Writer), reader)
+	if err != nil {
+		file.Close()
+		return nil, 0, err
+	}
+	if size != -1 && n < size {
+		file.Close()
+		return nil, 0, IncompleteBody{}
+	}
+	if calculated := hex.EncodeToString(md5Writer.Sum(nil)); md5Hex != "" && md5Hex != calculated {
+		file.Close()
+		return nil, 0, BadDigest{md5Hex, calculated}
+	}
+	if sha256sum != "" && sha256sum != hex.EncodeToString(sha256Writer.Sum(nil)) {
+		file.Close()
+		return nil, 0, errContentSHA256Mismatch
+	}
+	return file, n, nil
+}
+
+// putObjectExtract - expands the archive uploaded as object into an
+// object per regular file under the directory of object, modified
+// times are kept as metadata. All entries are validated, and for
+// anonymous requests authorized, before any object is created. The
+// number of objects created is returned in X-Minio-Extracted-Objects.
+func (api objectAPIHandlers) putObjectExtract(w http.ResponseWriter, r *http.Request, bucket, object string, size int64, reader io.Reader, md5Hex, sha256sum string, anonymous bool) {
+	objectAPI := api.ObjectAPI()
+	format := getExtractFormat(object)
+	if format == "" {
+		writeErrorResponse(w, r, ErrInvalidArchiveFormat, r.URL.Path)
+		return
+	}
+
+	file, size, err := spoolArchive(reader, size, md5Hex, sha256sum)
+	if err != nil {
+		errorIf(err, "Unable to save archive %s/%s.", bucket, object)
+		writeErrorResponse(w, r, toAPIErrorCode(err), r.URL.Path)
+		return
+	}
+	defer file.Close()
+
+	prefix := ""
+	if dir := path.Dir(object); dir != "." {
+		prefix = dir + slashSeparator
+	}
+
+	// Validate all the entries first, so that malformed archives
+	// leave no objects behind.
+	var expandedSize int64
+	s3Error := ErrNone
+	err = walkArchive(file, size, format, func(entry archiveEntry, data io.Reader) error {
+		entryObject, err := getExtractObjectName(prefix, entry.Name)
+		if err != nil {
+			return err
+		}
+		if anonymous {
+			objectURL := &url.URL{Path: "/" + bucket + "/" + entryObject}
+			if s3Error = enforceBucketPolicy(bucket, "s3:PutObject", objectURL); s3Error != ErrNone {
+				return errInvalidArchive
+			}
+		}
+		if isMaxObjectSize(entry.Size) {
+			s3Error = ErrEntityTooLarge
+			return errInvalidArchive
+		}
+		expandedSize += entry.Size
+		return nil
+	})
+	if s3Error != ErrNone {
+		writeErrorResponse(w, r, s3Error, r.URL.Path)
+		return
+	}
+	if err != nil {
+		writeErrorResponse(w, r, toAPIErrorCode(err), r.URL.Path)
+		return
+	}
+	if err = globalDiskUsage.admit(expandedSize); err != nil {
+		writeErrorResponse(w, r, ErrStorageFull, r.URL.Path)
+		return
+	}
+
+	var objInfos []ObjectInfo
+	err = walkArchive(file, size, format, func(entry archiveEntry, data io.Reader) error {
+		entryObject, err := getExtractObjectName(prefix, entry.Name)
+		if err != nil {
+			return err
+		}
+		metadata := extractMetadataFromHeader(r.Header)
+		// Content-Type of the request is the one of the archive.
+		delete(metadata, "content-type")
+		applyBucketDefaults(bucket, entryObject, metadata)
+		metadata[extractModTimeMeta] = entry.ModTime.UTC().Format(time.RFC3339)
+		if data, err = sniffContentType(bucket, entryObject, metadata, data); err != nil {
+			return err
+		}
+		objInfo, err := objectAPI.PutObject(bucket, entryObject, entry.Size, data, metadata, "")
+		if err != nil {
+			return err
+		}
+		objInfos = append(objInfos, objInfo)
+		return nil
+	})
+	if err != nil {
+		errorIf(err, "Unable to expand archive %s/%s.", bucket, object)
+		writeErrorResponse(w, r, toAPIErrorCode(err), r.URL.Path)
+	} else {
+		w.Header().Set("X-Minio-Extracted-Objects", strconv.Itoa(len(objInfos)))
+		writeSuccessResponse(w, nil)
+	}
+
+	// Notify object created events, also for the objects created
+	// before a failure.
+	for _, objInfo := range objInfos {
+		eventNotify(eventData{
+			Type:    ObjectCreatedPut,
+			Bucket:  bucket,
+			ObjInfo: objInfo,
+			ReqParams: map[string]string{
+				"sourceIPAddress": r.RemoteAddr,
+			},
+		})
+	}
+}
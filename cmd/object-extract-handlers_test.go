@@ -0,0 +1,160 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// Tests naming the objects expanded from archive entries.
+func TestGetExtractObjectName(t *testing.T) {
+	testCases := []struct {
+		prefix     string
+		name       string
+		expected   string
+		shouldPass bool
+	}{
+		{"", "a.txt", "a.txt", true},
+		{"photos/", "2016/a.txt", "photos/2016/a.txt", true},
+		{"photos/", "./2016/../a.txt", "photos/a.txt", true},
+		{"photos/", "../a.txt", "", false},
+		{"photos/", "/etc/passwd", "", false},
+		{"photos/", "..", "", false},
+		{"photos/", "", "", false},
+	}
+	for i, testCase := range testCases {
+		object, err := getExtractObjectName(testCase.prefix, testCase.name)
+		if testCase.shouldPass && err != nil {
+			t.Errorf("Test %d: Unexpected error %s", i+1, err)
+		}
+		if !testCase.shouldPass && err == nil {
+			t.Errorf("Test %d: Expected to fail", i+1)
+		}
+		if object != testCase.expected {
+			t.Errorf("Test %d: Expected %s, got %s", i+1, testCase.expected, object)
+		}
+	}
+}
+
+// Returns a tar or zip archive of entries.
+func makeTestArchive(format string, entries map[string]string, modTime time.Time) []byte {
+	var buf bytes.Buffer
+	if format == archiveZip {
+		zipWriter := zip.NewWriter(&buf)
+		for name, content := range entries {
+			header := &zip.FileHeader{Name: name, Method: zip.Deflate}
+			header.SetModTime(modTime)
+			writer, _ := zipWriter.CreateHeader(header)
+			writer.Write([]byte(content))
+		}
+		zipWriter.Close()
+		return buf.Bytes()
+	}
+	tarWriter := tar.NewWriter(&buf)
+	for name, content := range entries {
+		tarWriter.WriteHeader(&tar.Header{
+			Name:     name,
+			Mode:     0644,
+			Size:     int64(len(content)),
+			ModTime:  modTime,
+			Typeflag: tar.TypeReg,
+		})
+		tarWriter.Write([]byte(content))
+	}
+	tarWriter.Close()
+	return buf.Bytes()
+}
+
+// Wrapper for calling PutObject extract HTTP handler tests for both XL multiple disks and single node setup.
+func TestPutObjectExtractHandler(t *testing.T) {
+	ExecObjectLayerAPITest(t, testPutObjectExtractHandler, []string{"PutObject"})
+}
+
+func testPutObjectExtractHandler(obj ObjectLayer, instanceType, bucketName string, apiRouter http.Handler,
+	credentials credential, t *testing.T) {
+	modTime := time.Date(2016, 10, 1, 12, 0, 0, 0, time.UTC)
+	entries := map[string]string{
+		"a.txt":      "a",
+		"2016/b.txt": "bb",
+	}
+
+	testCases := []struct {
+		object             string
+		data               []byte
+		expectedStatusCode int
+		expectedObjects    map[string]string
+	}{
+		{"photos/upload.tar", makeTestArchive(archiveTar, entries, modTime), http.StatusOK,
+			map[string]string{"photos/a.txt": "a", "photos/2016/b.txt": "bb"}},
+		{"upload.zip", makeTestArchive(archiveZip, entries, modTime), http.StatusOK,
+			map[string]string{"a.txt": "a", "2016/b.txt": "bb"}},
+		{"photos/upload.rar", []byte("rar"), http.StatusBadRequest, nil},
+		{"photos/upload.zip", []byte("not a zip"), http.StatusBadRequest, nil},
+		{"escape/upload.tar", makeTestArchive(archiveTar, map[string]string{"../x.txt": "x"}, modTime), http.StatusBadRequest, nil},
+	}
+	for i, testCase := range testCases {
+		rec := httptest.NewRecorder()
+		req, err := newTestSignedRequestV4("PUT", getPutObjectURL("", bucketName, testCase.object),
+			int64(len(testCase.data)), bytes.NewReader(testCase.data), credentials.AccessKeyID, credentials.SecretAccessKey)
+		if err != nil {
+			t.Fatalf("%s: Failed to create HTTP request: <ERROR> %v", instanceType, err)
+		}
+		req.Header.Set(extractHeader, "true")
+		apiRouter.ServeHTTP(rec, req)
+		if rec.Code != testCase.expectedStatusCode {
+			t.Fatalf("%s: Test %d: Expected %d, got %d", instanceType, i+1, testCase.expectedStatusCode, rec.Code)
+		}
+		if rec.Code != http.StatusOK {
+			continue
+		}
+		if count := rec.Header().Get("X-Minio-Extracted-Objects"); count != "2" {
+			t.Errorf("%s: Test %d: Expected 2 extracted objects, got %s", instanceType, i+1, count)
+		}
+		// The archive itself is not stored.
+		if _, err = obj.GetObjectInfo(bucketName, testCase.object); err == nil {
+			t.Errorf("%s: Test %d: Archive should not be stored", instanceType, i+1)
+		}
+		for object, content := range testCase.expectedObjects {
+			objInfo, err := obj.GetObjectInfo(bucketName, object)
+			if err != nil {
+				t.Fatalf("%s: Test %d: %s", instanceType, i+1, err)
+			}
+			if mtime := objInfo.UserDefined[extractModTimeMeta]; mtime != modTime.Format(time.RFC3339) {
+				t.Errorf("%s: Test %d: Expected mtime %s, got %s", instanceType, i+1, modTime.Format(time.RFC3339), mtime)
+			}
+			var buf bytes.Buffer
+			if err = obj.GetObject(bucketName, object, 0, objInfo.Size, &buf); err != nil {
+				t.Fatalf("%s: Test %d: %s", instanceType, i+1, err)
+			}
+			if data, _ := ioutil.ReadAll(&buf); string(data) != content {
+				t.Errorf("%s: Test %d: Expected %s, got %s", instanceType, i+1, content, string(data))
+			}
+		}
+	}
+
+	// Rejected archives leave no objects behind.
+	if _, err := obj.GetObjectInfo(bucketName, "x.txt"); err == nil {
+		t.Errorf("%s: Entries outside of the prefix should not be created", instanceType)
+	}
+}
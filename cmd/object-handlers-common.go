@@ -132,6 +132,12 @@ func checkPreconditions(w http.ResponseWriter, r *http.Request, objInfo ObjectIn
 		if objInfo.MD5Sum != "" {
 			w.Header().Set("ETag", "\""+objInfo.MD5Sum+"\"")
 		}
+
+		// Caches refreshing their copy with a 304 (not modified) keep
+		// the caching policy they are sent.
+		if cacheControl := objInfo.UserDefined["cache-control"]; cacheControl != "" {
+			w.Header().Set("Cache-Control", cacheControl)
+		}
 	}
 	// If-Modified-Since : Return the object only if it has been modified since the specified time,
 	// otherwise return a 304 (not modified).
@@ -24,7 +24,6 @@ import (
 	"net/http"
 	"net/url"
 	"path"
-	"sort"
 	"strconv"
 	"strings"
 
@@ -67,6 +66,21 @@ func errAllowableObjectNotFound(bucket string, r *http.Request) APIErrorCode {
 	return ErrNoSuchKey
 }
 
+// Minio extension, GET requests setting this header to "true" have
+// the checksums of the whole object verified on all the disks before
+// it is sent. Corrupted objects fail with XMinioObjectCorrupted and
+// are healed in the background. Verified responses set verifiedHeader.
+const (
+	verifyHeader   = "X-Minio-Verify"
+	verifiedHeader = "X-Minio-Verified"
+)
+
+// isVerifyRequested - returns if the object should be verified before
+// it is sent.
+func isVerifyRequested(r *http.Request) bool {
+	return strings.EqualFold(r.Header.Get(verifyHeader), "true")
+}
+
 // Simple way to convert a func to io.Writer type.
 type funcToWriter func([]byte) (int, error)
 
@@ -91,29 +105,34 @@ func (api objectAPIHandlers) GetObjectHandler(w http.ResponseWriter, r *http.Req
 		return
 	}
 
-	switch getRequestAuthType(r) {
-	default:
-		// For all unknown auth types return error.
-		writeErrorResponse(w, r, ErrAccessDenied, r.URL.Path)
-		return
-	case authTypeAnonymous:
-		// http://docs.aws.amazon.com/AmazonS3/latest/dev/using-with-s3-actions.html
-		if s3Error := enforceBucketPolicy(bucket, "s3:GetObject", r.URL); s3Error != ErrNone {
-			writeErrorResponse(w, r, s3Error, r.URL.Path)
-			return
-		}
-	case authTypePresignedV2, authTypeSignedV2:
-		// Signature V2 validation.
-		if s3Error := isReqAuthenticatedV2(r); s3Error != ErrNone {
-			errorIf(errSignatureMismatch, dumpRequest(r))
-			writeErrorResponse(w, r, s3Error, r.URL.Path)
-			return
-		}
-	case authTypePresigned, authTypeSigned:
-		if s3Error := isReqAuthenticated(r, serverConfig.GetRegion()); s3Error != ErrNone {
-			errorIf(errSignatureMismatch, dumpRequest(r))
-			writeErrorResponse(w, r, s3Error, r.URL.Path)
+	// Transformation webhooks fetch the untransformed object with a
+	// one-time bypass token in place of credentials.
+	transformBypassed := isTransformBypassed(r, bucket, object)
+	if !transformBypassed {
+		switch getRequestAuthType(r) {
+		default:
+			// For all unknown auth types return error.
+			writeErrorResponse(w, r, ErrAccessDenied, r.URL.Path)
 			return
+		case authTypeAnonymous:
+			// http://docs.aws.amazon.com/AmazonS3/latest/dev/using-with-s3-actions.html
+			if s3Error := enforceBucketPolicy(bucket, "s3:GetObject", r.URL); s3Error != ErrNone {
+				writeErrorResponse(w, r, s3Error, r.URL.Path)
+				return
+			}
+		case authTypePresignedV2, authTypeSignedV2:
+			// Signature V2 validation.
+			if s3Error := isReqAuthenticatedV2(r); s3Error != ErrNone {
+				errorIf(errSignatureMismatch, dumpRequest(r))
+				writeErrorResponse(w, r, s3Error, r.URL.Path)
+				return
+			}
+		case authTypePresigned, authTypeSigned:
+			if s3Error := isReqAuthenticated(r, serverConfig.GetRegion()); s3Error != ErrNone {
+				errorIf(errSignatureMismatch, dumpRequest(r))
+				writeErrorResponse(w, r, s3Error, r.URL.Path)
+				return
+			}
 		}
 	}
 	objInfo, err := objectAPI.GetObjectInfo(bucket, object)
@@ -127,6 +146,13 @@ func (api objectAPIHandlers) GetObjectHandler(w http.ResponseWriter, r *http.Req
 		return
 	}
 
+	// Objects matching the transformation webhook of the bucket are
+	// served by the webhook.
+	if transform := getBucketTransform(bucket, object); transform != nil && !transformBypassed {
+		transformObject(w, r, transform, bucket, object)
+		return
+	}
+
 	// Get request range.
 	var hrange *httpRange
 	rangeHeader := r.Header.Get("Range")
@@ -150,6 +176,16 @@ func (api objectAPIHandlers) GetObjectHandler(w http.ResponseWriter, r *http.Req
 		return
 	}
 
+	// Verify the stored checksums before sending any data.
+	if isVerifyRequested(r) {
+		if err = objectAPI.VerifyObject(bucket, object); err != nil {
+			errorIf(err, "Unable to verify object %s/%s.", bucket, object)
+			writeErrorResponse(w, r, toAPIErrorCode(err), r.URL.Path)
+			return
+		}
+		w.Header().Set(verifiedHeader, "true")
+	}
+
 	// Get the object.
 	startOffset := int64(0)
 	length := objInfo.Size
@@ -173,9 +209,13 @@ func (api objectAPIHandlers) GetObjectHandler(w http.ResponseWriter, r *http.Req
 		}
 		return w.Write(p)
 	})
+	var objWriter io.Writer = writer
+	if isCacheBypassRequested(r) {
+		objWriter = cacheBypassWriter{writer}
+	}
 
 	// Reads the object at startOffset and writes to mw.
-	if err := objectAPI.GetObject(bucket, object, startOffset, length, writer); err != nil {
+	if err := objectAPI.GetObject(bucket, object, startOffset, length, objWriter); err != nil {
 		errorIf(err, "Unable to write to client.")
 		if !dataWritten {
 			// Error response only if no data has been written to client yet. i.e if
@@ -259,6 +299,87 @@ func (api objectAPIHandlers) HeadObjectHandler(w http.ResponseWriter, r *http.Re
 	w.WriteHeader(http.StatusOK)
 }
 
+// GetObjectAttributesHandler - GET Object attributes
+// -----------
+// Retrieves the ETag, size, storage class and for multipart objects the
+// individual part sizes and md5sums, so that clients can verify the
+// integrity of a multipart object without downloading it.
+func (api objectAPIHandlers) GetObjectAttributesHandler(w http.ResponseWriter, r *http.Request) {
+	var object, bucket string
+	vars := mux.Vars(r)
+	bucket = vars["bucket"]
+	object = vars["object"]
+
+	objectAPI := api.ObjectAPI()
+	if objectAPI == nil {
+		writeErrorResponse(w, r, ErrServerNotInitialized, r.URL.Path)
+		return
+	}
+
+	switch getRequestAuthType(r) {
+	default:
+		// For all unknown auth types return error.
+		writeErrorResponse(w, r, ErrAccessDenied, r.URL.Path)
+		return
+	case authTypeAnonymous:
+		// http://docs.aws.amazon.com/AmazonS3/latest/dev/using-with-s3-actions.html
+		if s3Error := enforceBucketPolicy(bucket, "s3:GetObject", r.URL); s3Error != ErrNone {
+			writeErrorResponse(w, r, s3Error, r.URL.Path)
+			return
+		}
+	case authTypePresignedV2, authTypeSignedV2:
+		// Signature V2 validation.
+		if s3Error := isReqAuthenticatedV2(r); s3Error != ErrNone {
+			errorIf(errSignatureMismatch, dumpRequest(r))
+			writeErrorResponse(w, r, s3Error, r.URL.Path)
+			return
+		}
+	case authTypePresigned, authTypeSigned:
+		if s3Error := isReqAuthenticated(r, serverConfig.GetRegion()); s3Error != ErrNone {
+			errorIf(errSignatureMismatch, dumpRequest(r))
+			writeErrorResponse(w, r, s3Error, r.URL.Path)
+			return
+		}
+	}
+
+	attributes, partNumberMarker, maxParts, s3Error := getObjectAttributesResources(r.Header)
+	if s3Error != ErrNone {
+		writeErrorResponse(w, r, s3Error, r.URL.Path)
+		return
+	}
+
+	objInfo, err := objectAPI.GetObjectInfo(bucket, object)
+	if err != nil {
+		errorIf(err, "Unable to fetch object info.")
+		apiErr := toAPIErrorCode(err)
+		if apiErr == ErrNoSuchKey {
+			apiErr = errAllowableObjectNotFound(bucket, r)
+		}
+		writeErrorResponse(w, r, apiErr, r.URL.Path)
+		return
+	}
+
+	// Validate pre-conditions if any.
+	if checkPreconditions(w, r, objInfo) {
+		return
+	}
+
+	parts, err := objectAPI.GetObjectParts(bucket, object)
+	if err != nil {
+		errorIf(err, "Unable to fetch object parts.")
+		writeErrorResponse(w, r, toAPIErrorCode(err), r.URL.Path)
+		return
+	}
+
+	response := generateGetObjectAttributesResponse(objInfo, parts, attributes, partNumberMarker, maxParts)
+	encodedSuccessResponse := encodeResponse(response)
+	// Write headers.
+	setCommonHeaders(w)
+	w.Header().Set("Last-Modified", objInfo.ModTime.UTC().Format(http.TimeFormat))
+	// Write success response.
+	writeSuccessResponse(w, encodedSuccessResponse)
+}
+
 // CopyObjectHandler - Copy Object
 // ----------
 // This implementation of the PUT operation adds an object to a bucket
@@ -349,6 +470,12 @@ func (api objectAPIHandlers) CopyObjectHandler(w http.ResponseWriter, r *http.Re
 		return
 	}
 
+	// Reject early if the copy would fill up the disks.
+	if err = globalDiskUsage.admit(objInfo.Size); err != nil {
+		writeErrorResponse(w, r, ErrStorageFull, r.URL.Path)
+		return
+	}
+
 	// Size of object.
 	size := objInfo.Size
 
@@ -372,6 +499,12 @@ func (api objectAPIHandlers) CopyObjectHandler(w http.ResponseWriter, r *http.Re
 	// then its ETag will not be MD5sum of the object.
 	delete(metadata, "md5Sum")
 
+	// Encrypt the copy if asked for, or by default of the bucket.
+	if algorithm := r.Header.Get(sseMetaAlgorithm); algorithm != "" {
+		metadata[sseMetaAlgorithm] = algorithm
+	}
+	applyBucketEncryption(bucket, metadata)
+
 	sha256sum := ""
 	// Create the object.
 	objInfo, err = objectAPI.PutObject(bucket, object, size, pipeReader, metadata, sha256sum)
@@ -455,14 +588,22 @@ func (api objectAPIHandlers) PutObjectHandler(w http.ResponseWriter, r *http.Req
 		return
 	}
 
+	// Reject early if the upload would fill up the disks.
+	if err = globalDiskUsage.admit(size); err != nil {
+		writeErrorResponse(w, r, ErrStorageFull, r.URL.Path)
+		return
+	}
+
 	// Extract metadata to be saved from incoming HTTP header.
 	metadata := extractMetadataFromHeader(r.Header)
+	// Apply bucket defaults for any metadata not provided.
+	applyBucketDefaults(bucket, object, metadata)
 	// Make sure we hex encode md5sum here.
 	metadata["md5Sum"] = hex.EncodeToString(md5Bytes)
 
 	sha256sum := ""
 
-	var objInfo ObjectInfo
+	var reader io.Reader = r.Body
 	switch rAuthType {
 	default:
 		// For all unknown auth types return error.
@@ -474,17 +615,15 @@ func (api objectAPIHandlers) PutObjectHandler(w http.ResponseWriter, r *http.Req
 			writeErrorResponse(w, r, s3Error, r.URL.Path)
 			return
 		}
-		// Create anonymous object.
-		objInfo, err = objectAPI.PutObject(bucket, object, size, r.Body, metadata, sha256sum)
 	case authTypeStreamingSigned:
 		// Initialize stream signature verifier.
-		reader, s3Error := newSignV4ChunkedReader(r)
+		var s3Error APIErrorCode
+		reader, s3Error = newSignV4ChunkedReader(r)
 		if s3Error != ErrNone {
 			errorIf(errSignatureMismatch, dumpRequest(r))
 			writeErrorResponse(w, r, s3Error, r.URL.Path)
 			return
 		}
-		objInfo, err = objectAPI.PutObject(bucket, object, size, reader, metadata, sha256sum)
 	case authTypeSignedV2, authTypePresignedV2:
 		s3Error := isReqAuthenticatedV2(r)
 		if s3Error != ErrNone {
@@ -492,7 +631,6 @@ func (api objectAPIHandlers) PutObjectHandler(w http.ResponseWriter, r *http.Req
 			writeErrorResponse(w, r, s3Error, r.URL.Path)
 			return
 		}
-		objInfo, err = objectAPI.PutObject(bucket, object, size, r.Body, metadata, sha256sum)
 	case authTypePresigned, authTypeSigned:
 		if s3Error := reqSignatureV4Verify(r); s3Error != ErrNone {
 			errorIf(errSignatureMismatch, dumpRequest(r))
@@ -502,15 +640,35 @@ func (api objectAPIHandlers) PutObjectHandler(w http.ResponseWriter, r *http.Req
 		if !skipContentSha256Cksum(r) {
 			sha256sum = r.Header.Get("X-Amz-Content-Sha256")
 		}
-		// Create object.
-		objInfo, err = objectAPI.PutObject(bucket, object, size, r.Body, metadata, sha256sum)
 	}
+
+	// Expand the uploaded archive into objects if asked to.
+	if isExtractRequested(r) {
+		api.putObjectExtract(w, r, bucket, object, size, reader, metadata["md5Sum"], sha256sum, rAuthType == authTypeAnonymous)
+		return
+	}
+
+	// Detect the content-type if the bucket asks for it.
+	if reader, err = sniffContentType(bucket, object, metadata, reader); err != nil {
+		errorIf(err, "Unable to detect content-type of an object.")
+		writeErrorResponse(w, r, toAPIErrorCode(err), r.URL.Path)
+		return
+	}
+	if isCacheBypassRequested(r) {
+		metadata[cacheBypassMetaKey] = cacheBypassValue
+	}
+
+	// Create object.
+	objInfo, err := objectAPI.PutObject(bucket, object, size, reader, metadata, sha256sum)
 	if err != nil {
 		errorIf(err, "Unable to create an object.")
 		writeErrorResponse(w, r, toAPIErrorCode(err), r.URL.Path)
 		return
 	}
 	w.Header().Set("ETag", "\""+objInfo.MD5Sum+"\"")
+	if algorithm := objInfo.UserDefined[sseMetaAlgorithm]; algorithm != "" {
+		w.Header().Set(sseMetaAlgorithm, algorithm)
+	}
 	writeSuccessResponse(w, nil)
 
 	// Notify object created event.
@@ -567,6 +725,8 @@ func (api objectAPIHandlers) NewMultipartUploadHandler(w http.ResponseWriter, r
 
 	// Extract metadata that needs to be saved.
 	metadata := extractMetadataFromHeader(r.Header)
+	// Apply bucket defaults for any metadata not provided.
+	applyBucketDefaults(bucket, object, metadata)
 
 	uploadID, err := objectAPI.NewMultipartUpload(bucket, object, metadata)
 	if err != nil {
@@ -627,6 +787,12 @@ func (api objectAPIHandlers) PutObjectPartHandler(w http.ResponseWriter, r *http
 		return
 	}
 
+	// Reject early if the part would fill up the disks.
+	if err = globalDiskUsage.admit(size); err != nil {
+		writeErrorResponse(w, r, ErrStorageFull, r.URL.Path)
+		return
+	}
+
 	uploadID := r.URL.Query().Get("uploadId")
 	partIDString := r.URL.Query().Get("partNumber")
 
@@ -864,7 +1030,7 @@ func (api objectAPIHandlers) CompleteMultipartUploadHandler(w http.ResponseWrite
 		writeErrorResponse(w, r, ErrMalformedXML, r.URL.Path)
 		return
 	}
-	if !sort.IsSorted(completedParts(complMultipartUpload.Parts)) {
+	if !isPartOrderValid(complMultipartUpload.Parts) {
 		writeErrorResponse(w, r, ErrInvalidPartOrder, r.URL.Path)
 		return
 	}
@@ -966,8 +1132,13 @@ func (api objectAPIHandlers) DeleteObjectHandler(w http.ResponseWriter, r *http.
 	}
 	/// http://docs.aws.amazon.com/AmazonS3/latest/API/RESTObjectDELETE.html
 	/// Ignore delete object errors, since we are suppposed to reply
-	/// only 204.
+	/// only 204. Objects still retained are the exception, since the
+	/// client must know they were not deleted.
 	if err := objectAPI.DeleteObject(bucket, object); err != nil {
+		if _, ok := errorCause(err).(ObjectImmutable); ok {
+			writeErrorResponse(w, r, ErrObjectImmutable, r.URL.Path)
+			return
+		}
 		writeSuccessNoContent(w)
 		return
 	}
@@ -27,6 +27,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"path"
 	"strconv"
 	"sync"
 	"testing"
@@ -1242,6 +1243,21 @@ func testAPICompleteMultipartHandler(obj ObjectLayer, instanceType, bucketName s
 				{ETag: validPartMD5, PartNumber: 2},
 			},
 		},
+		// inputParts - 6.
+		// Case with valid parts, but a part is listed twice.
+		{
+			[]completePart{
+				{ETag: validPartMD5, PartNumber: 5},
+				{ETag: validPartMD5, PartNumber: 5},
+			},
+		},
+		// inputParts - 7.
+		// Case with an ETag which is not hex encoded.
+		{
+			[]completePart{
+				{ETag: "abcz", PartNumber: 5},
+			},
+		},
 	}
 
 	// on successfull complete multipart operation the s3MD5 for the parts uploaded will be returned.
@@ -1276,7 +1292,7 @@ func testAPICompleteMultipartHandler(obj ObjectLayer, instanceType, bucketName s
 			accessKey: credentials.AccessKeyID,
 			secretKey: credentials.SecretAccessKey,
 
-			expectedContent: encodeResponse(getAPIErrorResponse(getAPIError(toAPIErrorCode(BadDigest{})),
+			expectedContent: encodeResponse(getAPIErrorResponse(getAPIError(toAPIErrorCode(InvalidPart{})),
 				getGetObjectURL("", bucketName, objectName))),
 			expectedRespStatus: http.StatusBadRequest,
 		},
@@ -1355,6 +1371,36 @@ func testAPICompleteMultipartHandler(obj ObjectLayer, instanceType, bucketName s
 			expectedRespStatus: http.StatusBadRequest,
 		},
 		// Test case - 7.
+		// A part is listed twice.
+		// This should return ErrInvalidPartOrder in the response body.
+		{
+			bucket:    bucketName,
+			object:    objectName,
+			uploadID:  uploadIDs[0],
+			parts:     inputParts[6].parts,
+			accessKey: credentials.AccessKeyID,
+			secretKey: credentials.SecretAccessKey,
+
+			expectedContent: encodeResponse(getAPIErrorResponse(getAPIError(ErrInvalidPartOrder),
+				getGetObjectURL("", bucketName, objectName))),
+			expectedRespStatus: http.StatusBadRequest,
+		},
+		// Test case - 8.
+		// ETag of a part is not hex encoded.
+		// This should return ErrInvalidPart in the response body.
+		{
+			bucket:    bucketName,
+			object:    objectName,
+			uploadID:  uploadIDs[0],
+			parts:     inputParts[7].parts,
+			accessKey: credentials.AccessKeyID,
+			secretKey: credentials.SecretAccessKey,
+
+			expectedContent: encodeResponse(getAPIErrorResponse(getAPIError(ErrInvalidPart),
+				getGetObjectURL("", bucketName, objectName))),
+			expectedRespStatus: http.StatusBadRequest,
+		},
+		// Test case - 9.
 		// Test case with proper parts.
 		// Should successed and the content in the response body is asserted.
 		{
@@ -1369,7 +1415,7 @@ func testAPICompleteMultipartHandler(obj ObjectLayer, instanceType, bucketName s
 				getGetObjectURL("", bucketName, objectName))),
 			expectedRespStatus: http.StatusForbidden,
 		},
-		// Test case - 8.
+		// Test case - 10.
 		// Test case with proper parts.
 		// Should successed and the content in the response body is asserted.
 		{
@@ -2361,3 +2407,220 @@ func testAPIListObjectPartsHandler(obj ObjectLayer, instanceType, bucketName str
 	// `ExecObjectLayerAPINilTest` sets the Object Layer to `nil` and calls the handler.
 	ExecObjectLayerAPINilTest(t, nilBucket, nilObject, instanceType, apiRouter, nilReq)
 }
+
+// Wrapper for calling GetObjectAttributes HTTP handler tests for both XL multiple disks and single node setup.
+func TestAPIGetObjectAttributesHandler(t *testing.T) {
+	defer func(strict bool) { globalStrictETag = strict }(globalStrictETag)
+	// Parts are only retained on FS with strict ETag parity.
+	globalStrictETag = true
+	ExecObjectLayerAPITest(t, testAPIGetObjectAttributesHandler, []string{"GetObjectAttributes"})
+}
+
+func testAPIGetObjectAttributesHandler(obj ObjectLayer, instanceType, bucketName string, apiRouter http.Handler,
+	credentials credential, t *testing.T) {
+	objectName := "test-object"
+	// Upload a single part multipart object.
+	uploadID, err := obj.NewMultipartUpload(bucketName, objectName, nil)
+	if err != nil {
+		t.Fatalf("Minio %s : <ERROR>  %s", instanceType, err)
+	}
+	partMD5, err := obj.PutObjectPart(bucketName, objectName, uploadID, 1, int64(len("hello")), bytes.NewReader([]byte("hello")),
+		"5d41402abc4b2a76b9719d911017c592", "")
+	if err != nil {
+		t.Fatalf("Minio %s : %s.", instanceType, err)
+	}
+	objectMD5, err := obj.CompleteMultipartUpload(bucketName, objectName, uploadID, []completePart{{PartNumber: 1, ETag: partMD5}})
+	if err != nil {
+		t.Fatalf("Minio %s : %s.", instanceType, err)
+	}
+	expectedMD5, err := completeMultipartMD5(completePart{PartNumber: 1, ETag: partMD5})
+	if err != nil {
+		t.Fatalf("Minio %s : %s.", instanceType, err)
+	}
+	if objectMD5 != expectedMD5 {
+		t.Fatalf("Minio %s: Expected multipart ETag %s, got %s", instanceType, expectedMD5, objectMD5)
+	}
+
+	testCases := []struct {
+		attributes    string
+		maxParts      string
+		expectedCode  int
+		expectedParts int
+	}{
+		// Test case - 1.
+		// Fetch all the attributes.
+		{"", "", http.StatusOK, 1},
+		// Test case - 2.
+		// Fetch only the ETag.
+		{"ETag", "", http.StatusOK, 0},
+		// Test case - 3.
+		// Fetch the parts with no more than zero parts.
+		{"ETag,ObjectParts", "0", http.StatusOK, 0},
+		// Test case - 4.
+		// Invalid max parts.
+		{"ObjectParts", "-1", http.StatusBadRequest, 0},
+	}
+	for i, testCase := range testCases {
+		rec := httptest.NewRecorder()
+		req, err := newTestSignedRequestV4("GET", getGetObjectAttributesURL("", bucketName, objectName),
+			0, nil, credentials.AccessKeyID, credentials.SecretAccessKey)
+		if err != nil {
+			t.Fatalf("Minio %s: Test %d: Failed to create HTTP request for GetObjectAttributes: <ERROR> %v", instanceType, i+1, err)
+		}
+		if testCase.attributes != "" {
+			req.Header.Set("X-Amz-Object-Attributes", testCase.attributes)
+		}
+		if testCase.maxParts != "" {
+			req.Header.Set("X-Amz-Max-Parts", testCase.maxParts)
+		}
+		apiRouter.ServeHTTP(rec, req)
+		if rec.Code != testCase.expectedCode {
+			t.Fatalf("Minio %s: Test %d: Expected the response status to be `%d`, but instead found `%d`", instanceType, i+1, testCase.expectedCode, rec.Code)
+		}
+		if rec.Code != http.StatusOK {
+			continue
+		}
+		response := GetObjectAttributesResponse{}
+		if err = xml.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+			t.Fatalf("Minio %s: Test %d: Unable to parse response: <ERROR> %v", instanceType, i+1, err)
+		}
+		if response.ETag != expectedMD5 {
+			t.Errorf("Minio %s: Test %d: Expected ETag %s, got %s", instanceType, i+1, expectedMD5, response.ETag)
+		}
+		var gotParts []ObjectAttributesPart
+		if response.ObjectParts != nil {
+			gotParts = response.ObjectParts.Parts
+		}
+		if len(gotParts) != testCase.expectedParts {
+			t.Fatalf("Minio %s: Test %d: Expected %d parts, got %d", instanceType, i+1, testCase.expectedParts, len(gotParts))
+		}
+		if testCase.expectedParts > 0 && gotParts[0].ETag != partMD5 {
+			t.Errorf("Minio %s: Test %d: Expected part ETag %s, got %s", instanceType, i+1, partMD5, gotParts[0].ETag)
+		}
+	}
+}
+
+// Wrapper for calling conditional GetObject and HeadObject HTTP handler tests for both XL multiple disks and single node setup.
+func TestAPIGetObjectCacheControlHandler(t *testing.T) {
+	ExecObjectLayerAPITest(t, testAPIGetObjectCacheControlHandler, []string{"GetObject", "HeadObject"})
+}
+
+func testAPIGetObjectCacheControlHandler(obj ObjectLayer, instanceType, bucketName string, apiRouter http.Handler,
+	credentials credential, t *testing.T) {
+	objectName := "test-object"
+	cacheControl := "public, max-age=3600"
+	metadata := map[string]string{"cache-control": cacheControl}
+	objInfo, err := obj.PutObject(bucketName, objectName, int64(len("hello")), bytes.NewReader([]byte("hello")), metadata, "")
+	if err != nil {
+		t.Fatalf("Minio %s : %s.", instanceType, err)
+	}
+	etag := "\"" + objInfo.MD5Sum + "\""
+	lastModified := objInfo.ModTime.UTC().Format(http.TimeFormat)
+
+	testCases := []struct {
+		method       string
+		header       string
+		value        string
+		expectedCode int
+	}{
+		// Test case - 1.
+		// Unconditional GET.
+		{"GET", "", "", http.StatusOK},
+		// Test case - 2.
+		// GET revalidated by ETag.
+		{"GET", "If-None-Match", etag, http.StatusNotModified},
+		// Test case - 3.
+		// GET revalidated by modification time.
+		{"GET", "If-Modified-Since", lastModified, http.StatusNotModified},
+		// Test case - 4.
+		// Stale ETag.
+		{"GET", "If-None-Match", "\"stale\"", http.StatusOK},
+		// Test case - 5.
+		// HEAD revalidated by ETag.
+		{"HEAD", "If-None-Match", etag, http.StatusNotModified},
+	}
+	for i, testCase := range testCases {
+		urlStr := getGetObjectURL("", bucketName, objectName)
+		if testCase.method == "HEAD" {
+			urlStr = getHeadObjectURL("", bucketName, objectName)
+		}
+		rec := httptest.NewRecorder()
+		req, err := newTestSignedRequestV4(testCase.method, urlStr, 0, nil, credentials.AccessKeyID, credentials.SecretAccessKey)
+		if err != nil {
+			t.Fatalf("Minio %s: Test %d: Failed to create HTTP request: <ERROR> %v", instanceType, i+1, err)
+		}
+		if testCase.header != "" {
+			req.Header.Set(testCase.header, testCase.value)
+		}
+		apiRouter.ServeHTTP(rec, req)
+		if rec.Code != testCase.expectedCode {
+			t.Fatalf("Minio %s: Test %d: Expected the response status to be `%d`, but instead found `%d`", instanceType, i+1, testCase.expectedCode, rec.Code)
+		}
+		if got := rec.Header().Get("Cache-Control"); got != cacheControl {
+			t.Errorf("Minio %s: Test %d: Expected Cache-Control %q, got %q", instanceType, i+1, cacheControl, got)
+		}
+		if got := rec.Header().Get("ETag"); got != etag {
+			t.Errorf("Minio %s: Test %d: Expected ETag %s, got %s", instanceType, i+1, etag, got)
+		}
+	}
+}
+
+// Wrapper for calling GetObject API handler tests with X-Minio-Verify
+// for both XL multiple disks and FS single drive setup.
+func TestAPIGetObjectVerifyHandler(t *testing.T) {
+	ExecObjectLayerAPITest(t, testAPIGetObjectVerifyHandler, []string{"GetObject"})
+}
+
+func testAPIGetObjectVerifyHandler(obj ObjectLayer, instanceType, bucketName string, apiRouter http.Handler,
+	credentials credential, t *testing.T) {
+	objectName := "test-object"
+	data := generateBytesData(1024)
+	if _, err := obj.PutObject(bucketName, objectName, int64(len(data)), bytes.NewReader(data), nil, ""); err != nil {
+		t.Fatalf("%s: Error uploading object: <ERROR> %v", instanceType, err)
+	}
+
+	getObject := func() *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		req, err := newTestSignedRequestV4("GET", getGetObjectURL("", bucketName, objectName),
+			0, nil, credentials.AccessKeyID, credentials.SecretAccessKey)
+		if err != nil {
+			t.Fatalf("%s: Failed to create HTTP request for Get Object: <ERROR> %v", instanceType, err)
+		}
+		req.Header.Set(verifyHeader, "true")
+		apiRouter.ServeHTTP(rec, req)
+		return rec
+	}
+
+	// FS stores no checksums.
+	xl, isXL := obj.(xlObjects)
+	if !isXL {
+		if rec := getObject(); rec.Code != http.StatusNotImplemented {
+			t.Fatalf("%s: Expected the response status to be `%d`, but instead found `%d`", instanceType, http.StatusNotImplemented, rec.Code)
+		}
+		return
+	}
+
+	rec := getObject()
+	if rec.Code != http.StatusOK || rec.Header().Get(verifiedHeader) != "true" {
+		t.Fatalf("%s: Expected a verified response, got `%d` %v", instanceType, rec.Code, rec.Header())
+	}
+	if !bytes.Equal(rec.Body.Bytes(), data) {
+		t.Fatalf("%s: Expected the object data", instanceType)
+	}
+
+	// Missing parts are corruptions.
+	if err := xl.storageDisks[0].DeleteFile(bucketName, path.Join(objectName, "part.1")); err != nil {
+		t.Fatal(err)
+	}
+	rec = getObject()
+	if rec.Code != http.StatusInternalServerError {
+		t.Fatalf("%s: Expected the response status to be `%d`, but instead found `%d`", instanceType, http.StatusInternalServerError, rec.Code)
+	}
+	expected := encodeResponse(getAPIErrorResponse(getAPIError(ErrObjectCorrupted), getGetObjectURL("", bucketName, objectName)))
+	if !bytes.Equal(rec.Body.Bytes(), expected) {
+		t.Errorf("%s: Expected %s, got %s", instanceType, expected, rec.Body.Bytes())
+	}
+	if err := waitForVerifyObject(obj, bucketName, objectName); err != nil {
+		t.Fatalf("%s: Expected corrupted object to be healed, got %v", instanceType, err)
+	}
+}
@@ -16,7 +16,11 @@
 
 package cmd
 
-import "io"
+import (
+	"io"
+
+	"github.com/mf-00/newgo/pkg/objcache"
+)
 
 // ObjectLayer implements primitives for object API layer.
 type ObjectLayer interface {
@@ -29,13 +33,16 @@ type ObjectLayer interface {
 	GetBucketInfo(bucket string) (bucketInfo BucketInfo, err error)
 	ListBuckets() (buckets []BucketInfo, err error)
 	DeleteBucket(bucket string) error
+	RenameBucket(srcBucket, dstBucket string) error
 	ListObjects(bucket, prefix, marker, delimiter string, maxKeys int) (result ListObjectsInfo, err error)
 
 	// Object operations.
 	GetObject(bucket, object string, startOffset int64, length int64, writer io.Writer) (err error)
 	GetObjectInfo(bucket, object string) (objInfo ObjectInfo, err error)
+	GetObjectParts(bucket, object string) (parts []objectPartInfo, err error)
 	PutObject(bucket, object string, size int64, data io.Reader, metadata map[string]string, sha256sum string) (objInto ObjectInfo, err error)
 	DeleteObject(bucket, object string) error
+	UpdateObjectMetadata(bucket, object string, update func(metadata map[string]string) (bool, error)) error
 
 	// Multipart operations.
 	ListMultipartUploads(bucket, prefix, keyMarker, uploadIDMarker, delimiter string, maxUploads int) (result ListMultipartsInfo, err error)
@@ -48,5 +55,9 @@ type ObjectLayer interface {
 	// Healing operations.
 	HealBucket(bucket string) error
 	HealObject(bucket, object string) error
+	VerifyObject(bucket, object string) error
 	ListObjectsHeal(bucket, prefix, marker, delimiter string, maxKeys int) (ListObjectsInfo, error)
+
+	// Cache operations.
+	CacheStats() (objcache.Stats, error)
 }
@@ -141,26 +141,37 @@ func listMultipartUploadIDs(bucketName, objectName, uploadIDMarker string, count
 	}
 	index := 0
 	if uploadIDMarker != "" {
-		for ; index < len(uploadsJSON.Uploads); index++ {
-			if uploadsJSON.Uploads[index].UploadID == uploadIDMarker {
-				// Skip the uploadID as it would already be listed in previous listing.
-				index++
+		// Uploads are listed in the order they were initiated, starting
+		// after the marker. If the marker upload has been completed or
+		// aborted since the previous listing its position is unknown,
+		// all the uploads are listed again rather than skipping any.
+		for i, upload := range uploadsJSON.Uploads {
+			if upload.UploadID == uploadIDMarker {
+				index = i + 1
 				break
 			}
 		}
 	}
-	for index < len(uploadsJSON.Uploads) {
+	for ; index < len(uploadsJSON.Uploads) && count > 0; index++ {
 		uploads = append(uploads, uploadMetadata{
 			Object:    objectName,
 			UploadID:  uploadsJSON.Uploads[index].UploadID,
 			Initiated: uploadsJSON.Uploads[index].Initiated,
 		})
 		count--
-		index++
-		if count == 0 {
-			break
-		}
 	}
 	end := (index == len(uploadsJSON.Uploads))
 	return uploads, end, nil
 }
+
+// getPartsAfterMarker - returns the parts, sorted by part number, with
+// a part number higher than partNumberMarker. The marker part need not
+// exist, so that listing resumes after parts which have been dropped.
+func getPartsAfterMarker(parts []objectPartInfo, partNumberMarker int) []objectPartInfo {
+	for i, part := range parts {
+		if part.Number > partNumberMarker {
+			return parts[i:]
+		}
+	}
+	return nil
+}
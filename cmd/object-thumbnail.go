@@ -0,0 +1,363 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	// Registers the GIF decoder.
+	_ "image/gif"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// Thumbnails are cached in '.minio.sys/thumbnails/<bucket>/<object>/<WxH>/'.
+const thumbnailPrefix = "thumbnails"
+
+const (
+	// Maximum width and height of thumbnails.
+	maxThumbnailDimension = 1024
+	// Maximum size of images thumbnails are generated for.
+	maxThumbnailSourceSize = 20 * 1024 * 1024 // 20MiB.
+	// Maximum number of pixels of images thumbnails are generated
+	// for, protects from images decompressing to huge bitmaps.
+	maxThumbnailSourcePixels = 50 * 1000 * 1000
+	// JPEG quality of thumbnails of JPEG images.
+	thumbnailJPEGQuality = 85
+)
+
+// errInvalidThumbnailSize - thumbnail size is not of the form WxH.
+var errInvalidThumbnailSize = errors.New("Invalid thumbnail size")
+
+// errThumbnailNotSupported - thumbnails can't be generated for the object.
+var errThumbnailNotSupported = errors.New("Thumbnails are not supported for this object")
+
+// parseThumbnailSize - parses a thumbnail size of the form WxH.
+func parseThumbnailSize(size string) (width, height int, err error) {
+	tokens := strings.Split(size, "x")
+	if len(tokens) != 2 {
+		return 0, 0, errInvalidThumbnailSize
+	}
+	if width, err = strconv.Atoi(tokens[0]); err != nil || width < 1 || width > maxThumbnailDimension {
+		return 0, 0, errInvalidThumbnailSize
+	}
+	if height, err = strconv.Atoi(tokens[1]); err != nil || height < 1 || height > maxThumbnailDimension {
+		return 0, 0, errInvalidThumbnailSize
+	}
+	return width, height, nil
+}
+
+// getThumbnailDimensions - returns the dimensions of an image of
+// srcWidth x srcHeight scaled to fit in width x height, keeping its
+// aspect ratio. Images are never enlarged.
+func getThumbnailDimensions(srcWidth, srcHeight, width, height int) (int, int) {
+	if srcWidth <= width && srcHeight <= height {
+		return srcWidth, srcHeight
+	}
+	// Compare width/srcWidth with height/srcHeight.
+	if width*srcHeight <= height*srcWidth {
+		height = (srcHeight*width + srcWidth/2) / srcWidth
+	} else {
+		width = (srcWidth*height + srcHeight/2) / srcHeight
+	}
+	if width < 1 {
+		width = 1
+	}
+	if height < 1 {
+		height = 1
+	}
+	return width, height
+}
+
+// newImageRowReader - returns a function storing the premultiplied
+// 16-bit RGBA values of every pixel of row y of src in row, as returned
+// by At(x, y).RGBA(). Images of the types returned by the decoders are
+// read without the allocation per pixel of At.
+func newImageRowReader(src image.Image) func(y int, row []uint32) {
+	bounds := src.Bounds()
+	switch img := src.(type) {
+	case *image.RGBA:
+		return func(y int, row []uint32) {
+			pix := img.Pix[img.PixOffset(bounds.Min.X, y):]
+			for i := range row {
+				row[i] = uint32(pix[i]) * 0x101
+			}
+		}
+	case *image.NRGBA:
+		return func(y int, row []uint32) {
+			pix := img.Pix[img.PixOffset(bounds.Min.X, y):]
+			for i := 0; i < len(row); i += 4 {
+				a := uint32(pix[i+3]) * 0x101
+				row[i] = uint32(pix[i]) * 0x101 * a / 0xffff
+				row[i+1] = uint32(pix[i+1]) * 0x101 * a / 0xffff
+				row[i+2] = uint32(pix[i+2]) * 0x101 * a / 0xffff
+				row[i+3] = a
+			}
+		}
+	case *image.YCbCr:
+		return func(y int, row []uint32) {
+			for i, x := 0, bounds.Min.X; i < len(row); i, x = i+4, x+1 {
+				yi, ci := img.YOffset(x, y), img.COffset(x, y)
+				row[i], row[i+1], row[i+2], row[i+3] = color.YCbCr{Y: img.Y[yi], Cb: img.Cb[ci], Cr: img.Cr[ci]}.RGBA()
+			}
+		}
+	case *image.Gray:
+		return func(y int, row []uint32) {
+			pix := img.Pix[img.PixOffset(bounds.Min.X, y):]
+			for i, j := 0, 0; i < len(row); i, j = i+4, j+1 {
+				gray := uint32(pix[j]) * 0x101
+				row[i], row[i+1], row[i+2], row[i+3] = gray, gray, gray, 0xffff
+			}
+		}
+	case *image.Paletted:
+		palette := make([][4]uint32, len(img.Palette))
+		for i, c := range img.Palette {
+			palette[i][0], palette[i][1], palette[i][2], palette[i][3] = c.RGBA()
+		}
+		return func(y int, row []uint32) {
+			pix := img.Pix[img.PixOffset(bounds.Min.X, y):]
+			for i, j := 0, 0; i < len(row); i, j = i+4, j+1 {
+				var c [4]uint32
+				// Indexes out of the palette are transparent black.
+				if int(pix[j]) < len(palette) {
+					c = palette[pix[j]]
+				}
+				row[i], row[i+1], row[i+2], row[i+3] = c[0], c[1], c[2], c[3]
+			}
+		}
+	}
+	return func(y int, row []uint32) {
+		for i, x := 0, bounds.Min.X; i < len(row); i, x = i+4, x+1 {
+			row[i], row[i+1], row[i+2], row[i+3] = src.At(x, y).RGBA()
+		}
+	}
+}
+
+// resizeImage - scales down src to width x height, every pixel is the
+// average of the source pixels it covers. Images are never enlarged,
+// width and height are capped at the size of src. The source is read
+// once, row by row.
+func resizeImage(src image.Image, width, height int) *image.RGBA64 {
+	bounds := src.Bounds()
+	srcWidth, srcHeight := bounds.Dx(), bounds.Dy()
+	if width > srcWidth {
+		width = srcWidth
+	}
+	if height > srcHeight {
+		height = srcHeight
+	}
+	dst := image.NewRGBA64(image.Rect(0, 0, width, height))
+
+	// Destination column of every source column, and the number of
+	// source columns of every destination column.
+	dstX := make([]int, srcWidth)
+	columns := make([]uint64, width)
+	for sx := range dstX {
+		dstX[sx] = ((sx+1)*width - 1) / srcWidth
+		columns[dstX[sx]]++
+	}
+
+	readRow := newImageRowReader(src)
+	row := make([]uint32, 4*srcWidth)
+	sums := make([]uint64, 4*width)
+	var y, rows int
+	for sy := 0; sy < srcHeight; sy++ {
+		readRow(bounds.Min.Y+sy, row)
+		for sx, x := range dstX {
+			sums[4*x] += uint64(row[4*sx])
+			sums[4*x+1] += uint64(row[4*sx+1])
+			sums[4*x+2] += uint64(row[4*sx+2])
+			sums[4*x+3] += uint64(row[4*sx+3])
+		}
+		rows++
+		// Write out destination row y after its last source row.
+		if sy < srcHeight-1 && ((sy+2)*height-1)/srcHeight == y {
+			continue
+		}
+		for x := 0; x < width; x++ {
+			n := columns[x] * uint64(rows)
+			dst.SetRGBA64(x, y, color.RGBA64{
+				R: uint16(sums[4*x] / n),
+				G: uint16(sums[4*x+1] / n),
+				B: uint16(sums[4*x+2] / n),
+				A: uint16(sums[4*x+3] / n),
+			})
+		}
+		for i := range sums {
+			sums[i] = 0
+		}
+		rows = 0
+		y++
+	}
+	return dst
+}
+
+// generateThumbnail - decodes a JPEG, PNG or GIF image and returns its
+// thumbnail fitting in width x height and its content type. Thumbnails
+// of JPEG images are JPEG, all others are PNG.
+func generateThumbnail(data []byte, width, height int) ([]byte, string, error) {
+	config, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, "", errThumbnailNotSupported
+	}
+	if config.Width < 1 || config.Height < 1 || int64(config.Width)*int64(config.Height) > maxThumbnailSourcePixels {
+		return nil, "", errThumbnailNotSupported
+	}
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, "", errThumbnailNotSupported
+	}
+
+	width, height = getThumbnailDimensions(config.Width, config.Height, width, height)
+	thumbnail := resizeImage(src, width, height)
+
+	var buf bytes.Buffer
+	if format == "jpeg" {
+		err = jpeg.Encode(&buf, thumbnail, &jpeg.Options{Quality: thumbnailJPEGQuality})
+		return buf.Bytes(), "image/jpeg", err
+	}
+	err = png.Encode(&buf, thumbnail)
+	return buf.Bytes(), "image/png", err
+}
+
+// getThumbnailPath - returns the path of the cached thumbnail of an
+// object version, identified by its ETag, size and modification time,
+// so that thumbnails of overwritten objects are never served.
+func getThumbnailPath(objInfo ObjectInfo, width, height int) string {
+	version := sha256.Sum256([]byte(fmt.Sprintf("%s:%d:%d", objInfo.MD5Sum, objInfo.Size, objInfo.ModTime.UnixNano())))
+	return path.Join(thumbnailPrefix, objInfo.Bucket, objInfo.Name,
+		fmt.Sprintf("%dx%d", width, height), fmt.Sprintf("%x", version[:8]))
+}
+
+// getObjectThumbnail - returns the thumbnail of an object from the
+// cache, the thumbnail is generated and cached if not found.
+func getObjectThumbnail(objAPI ObjectLayer, objInfo ObjectInfo, width, height int) ([]byte, string, error) {
+	if objInfo.Size > maxThumbnailSourceSize {
+		return nil, "", errThumbnailNotSupported
+	}
+
+	thumbnailPath := getThumbnailPath(objInfo, width, height)
+	if cachedInfo, err := objAPI.GetObjectInfo(minioMetaBucket, thumbnailPath); err == nil {
+		var buf bytes.Buffer
+		if err = objAPI.GetObject(minioMetaBucket, thumbnailPath, 0, cachedInfo.Size, &buf); err == nil {
+			return buf.Bytes(), http.DetectContentType(buf.Bytes()), nil
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := objAPI.GetObject(objInfo.Bucket, objInfo.Name, 0, objInfo.Size, &buf); err != nil {
+		return nil, "", err
+	}
+	thumbnail, contentType, err := generateThumbnail(buf.Bytes(), width, height)
+	if err != nil {
+		return nil, "", err
+	}
+
+	// Failing to cache the thumbnail only costs generating it again.
+	_, err = objAPI.PutObject(minioMetaBucket, thumbnailPath, int64(len(thumbnail)), bytes.NewReader(thumbnail), nil, "")
+	errorIf(err, "Unable to cache thumbnail of %s/%s.", objInfo.Bucket, objInfo.Name)
+	return thumbnail, contentType, nil
+}
+
+// removeObjectThumbnails - removes all the cached thumbnails of object.
+func removeObjectThumbnails(objAPI ObjectLayer, bucket, object string) error {
+	prefix := path.Join(thumbnailPrefix, bucket, object) + slashSeparator
+	var thumbnails []string
+	marker := ""
+	for {
+		result, err := objAPI.ListObjects(minioMetaBucket, prefix, marker, "", maxObjectList)
+		if err != nil {
+			return err
+		}
+		for _, objInfo := range result.Objects {
+			// Thumbnails of object are at '<WxH>/<version>', deeper
+			// entries belong to objects under object as a prefix.
+			if strings.Count(strings.TrimPrefix(objInfo.Name, prefix), slashSeparator) == 1 {
+				thumbnails = append(thumbnails, objInfo.Name)
+			}
+		}
+		if !result.IsTruncated {
+			break
+		}
+		marker = result.NextMarker
+	}
+	for _, thumbnail := range thumbnails {
+		err := objAPI.DeleteObject(minioMetaBucket, thumbnail)
+		if _, ok := errorCause(err).(ObjectNotFound); err != nil && !ok {
+			return err
+		}
+	}
+	return nil
+}
+
+// thumbnailObjects - object layer removing the cached thumbnails of
+// objects which are overwritten, copied over or deleted, all other
+// operations are served by the underlying object layer.
+type thumbnailObjects struct {
+	ObjectLayer
+}
+
+// newThumbnailObjects - returns objAPI removing cached thumbnails of
+// modified objects.
+func newThumbnailObjects(objAPI ObjectLayer) ObjectLayer {
+	return thumbnailObjects{objAPI}
+}
+
+// invalidate - removes the cached thumbnails of object, failing to
+// remove them only leaves unused thumbnails behind.
+func (t thumbnailObjects) invalidate(bucket, object string) {
+	if bucket == minioMetaBucket {
+		return
+	}
+	errorIf(removeObjectThumbnails(t.ObjectLayer, bucket, object), "Unable to remove thumbnails of %s/%s.", bucket, object)
+}
+
+// PutObject - creates or overwrites an object, copies included.
+func (t thumbnailObjects) PutObject(bucket, object string, size int64, data io.Reader, metadata map[string]string, sha256sum string) (ObjectInfo, error) {
+	objInfo, err := t.ObjectLayer.PutObject(bucket, object, size, data, metadata, sha256sum)
+	if err == nil {
+		t.invalidate(bucket, object)
+	}
+	return objInfo, err
+}
+
+// CompleteMultipartUpload - creates or overwrites an object.
+func (t thumbnailObjects) CompleteMultipartUpload(bucket, object, uploadID string, uploadedParts []completePart) (string, error) {
+	md5, err := t.ObjectLayer.CompleteMultipartUpload(bucket, object, uploadID, uploadedParts)
+	if err == nil {
+		t.invalidate(bucket, object)
+	}
+	return md5, err
+}
+
+// DeleteObject - deletes an object.
+func (t thumbnailObjects) DeleteObject(bucket, object string) error {
+	err := t.ObjectLayer.DeleteObject(bucket, object)
+	if err == nil {
+		t.invalidate(bucket, object)
+	}
+	return err
+}
@@ -0,0 +1,320 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+// Tests parsing thumbnail sizes.
+func TestParseThumbnailSize(t *testing.T) {
+	testCases := []struct {
+		size          string
+		width, height int
+		err           error
+	}{
+		{"200x100", 200, 100, nil},
+		{"1x1024", 1, 1024, nil},
+		{"0x100", 0, 0, errInvalidThumbnailSize},
+		{"1025x100", 0, 0, errInvalidThumbnailSize},
+		{"200", 0, 0, errInvalidThumbnailSize},
+		{"200x100x3", 0, 0, errInvalidThumbnailSize},
+		{"ax100", 0, 0, errInvalidThumbnailSize},
+		{"", 0, 0, errInvalidThumbnailSize},
+	}
+	for i, testCase := range testCases {
+		width, height, err := parseThumbnailSize(testCase.size)
+		if err != testCase.err {
+			t.Errorf("Test %d: Expected error %v, got %v", i+1, testCase.err, err)
+		}
+		if width != testCase.width || height != testCase.height {
+			t.Errorf("Test %d: Expected %dx%d, got %dx%d", i+1, testCase.width, testCase.height, width, height)
+		}
+	}
+}
+
+// Tests thumbnails keep the aspect ratio and are never enlarged.
+func TestGetThumbnailDimensions(t *testing.T) {
+	testCases := []struct {
+		srcWidth, srcHeight int
+		width, height       int
+		expectedW           int
+		expectedH           int
+	}{
+		{1000, 500, 200, 200, 200, 100},
+		{500, 1000, 200, 200, 100, 200},
+		{1000, 500, 100, 200, 100, 50},
+		{100, 50, 200, 200, 100, 50},
+		{10000, 1, 100, 100, 100, 1},
+	}
+	for i, testCase := range testCases {
+		width, height := getThumbnailDimensions(testCase.srcWidth, testCase.srcHeight, testCase.width, testCase.height)
+		if width != testCase.expectedW || height != testCase.expectedH {
+			t.Errorf("Test %d: Expected %dx%d, got %dx%d", i+1, testCase.expectedW, testCase.expectedH, width, height)
+		}
+	}
+}
+
+// newTestImage - returns a width x height image, white on the left
+// half and black on the right half.
+func newTestImage(width, height int) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			if x < width/2 {
+				img.Set(x, y, color.White)
+			} else {
+				img.Set(x, y, color.Black)
+			}
+		}
+	}
+	return img
+}
+
+// resizeImageAt - scales down src like resizeImage, reading every pixel
+// with At.
+func resizeImageAt(src image.Image, width, height int) *image.RGBA64 {
+	bounds := src.Bounds()
+	dst := image.NewRGBA64(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		y0 := bounds.Min.Y + y*bounds.Dy()/height
+		y1 := bounds.Min.Y + (y+1)*bounds.Dy()/height
+		for x := 0; x < width; x++ {
+			x0 := bounds.Min.X + x*bounds.Dx()/width
+			x1 := bounds.Min.X + (x+1)*bounds.Dx()/width
+			var r, g, b, a, n uint64
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					sr, sg, sb, sa := src.At(sx, sy).RGBA()
+					r, g, b, a = r+uint64(sr), g+uint64(sg), b+uint64(sb), a+uint64(sa)
+					n++
+				}
+			}
+			dst.SetRGBA64(x, y, color.RGBA64{R: uint16(r / n), G: uint16(g / n), B: uint16(b / n), A: uint16(a / n)})
+		}
+	}
+	return dst
+}
+
+// Tests resizing images of all the types returned by the decoders
+// matches averaging the pixels returned by At.
+func TestResizeImage(t *testing.T) {
+	rect := image.Rect(3, 5, 3+97, 5+61)
+	rgba := image.NewRGBA(rect)
+	nrgba := image.NewNRGBA(rect)
+	gray := image.NewGray(rect)
+	paletted := image.NewPaletted(rect, color.Palette{color.Black, color.White, color.NRGBA{R: 200, G: 100, B: 50, A: 128}})
+	ycbcr := image.NewYCbCr(rect, image.YCbCrSubsampleRatio420)
+	rgba64 := image.NewRGBA64(rect)
+	for y := rect.Min.Y; y < rect.Max.Y; y++ {
+		for x := rect.Min.X; x < rect.Max.X; x++ {
+			c := color.NRGBA{R: uint8(x * 7), G: uint8(y * 13), B: uint8(x * y), A: uint8(x + y*3)}
+			rgba.Set(x, y, c)
+			nrgba.Set(x, y, c)
+			gray.Set(x, y, c)
+			paletted.Set(x, y, c)
+			rgba64.Set(x, y, c)
+			ycbcr.Y[ycbcr.YOffset(x, y)] = uint8(x * 5)
+			ycbcr.Cb[ycbcr.COffset(x, y)] = uint8(y * 3)
+			ycbcr.Cr[ycbcr.COffset(x, y)] = uint8(x + y)
+		}
+	}
+	for i, src := range []image.Image{rgba, nrgba, gray, paletted, ycbcr, rgba64} {
+		for _, size := range [][2]int{{97, 61}, {40, 30}, {13, 7}, {1, 1}} {
+			expected := resizeImageAt(src, size[0], size[1])
+			if resized := resizeImage(src, size[0], size[1]); !bytes.Equal(resized.Pix, expected.Pix) {
+				t.Errorf("Test %d: Resizing %T to %dx%d does not match averaging pixels", i+1, src, size[0], size[1])
+			}
+		}
+	}
+
+	// Images are never enlarged.
+	if bounds := resizeImage(rgba, 200, 100).Bounds(); bounds.Dx() != 97 || bounds.Dy() != 61 {
+		t.Errorf("Expected 97x61, got %dx%d", bounds.Dx(), bounds.Dy())
+	}
+}
+
+// Tests generating thumbnails of PNG and JPEG images.
+func TestGenerateThumbnail(t *testing.T) {
+	var pngBuf, jpegBuf bytes.Buffer
+	if err := png.Encode(&pngBuf, newTestImage(400, 200)); err != nil {
+		t.Fatal(err)
+	}
+	if err := jpeg.Encode(&jpegBuf, newTestImage(400, 200), nil); err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		data        []byte
+		contentType string
+		width       int
+		height      int
+		err         error
+	}{
+		{pngBuf.Bytes(), "image/png", 100, 50, nil},
+		{jpegBuf.Bytes(), "image/jpeg", 100, 50, nil},
+		{[]byte("not an image"), "", 0, 0, errThumbnailNotSupported},
+	}
+	for i, testCase := range testCases {
+		thumbnail, contentType, err := generateThumbnail(testCase.data, 100, 100)
+		if err != testCase.err {
+			t.Fatalf("Test %d: Expected error %v, got %v", i+1, testCase.err, err)
+		}
+		if err != nil {
+			continue
+		}
+		if contentType != testCase.contentType {
+			t.Errorf("Test %d: Expected %s, got %s", i+1, testCase.contentType, contentType)
+		}
+		img, _, err := image.Decode(bytes.NewReader(thumbnail))
+		if err != nil {
+			t.Fatalf("Test %d: Unable to decode thumbnail: %s", i+1, err)
+		}
+		if img.Bounds().Dx() != testCase.width || img.Bounds().Dy() != testCase.height {
+			t.Errorf("Test %d: Expected %dx%d, got %v", i+1, testCase.width, testCase.height, img.Bounds())
+		}
+		// Left half stays white, right half black.
+		if r, _, _, _ := img.At(10, 25).RGBA(); r < 0xf000 {
+			t.Errorf("Test %d: Expected white pixel, got %v", i+1, img.At(10, 25))
+		}
+		if r, _, _, _ := img.At(90, 25).RGBA(); r > 0x1000 {
+			t.Errorf("Test %d: Expected black pixel, got %v", i+1, img.At(90, 25))
+		}
+	}
+}
+
+// Wrapper for calling GetObjectThumbnail HTTP handler tests for both XL multiple disks and single node setup.
+func TestGetObjectThumbnailHandler(t *testing.T) {
+	ExecObjectLayerAPITest(t, testGetObjectThumbnailHandler, []string{"GetObjectThumbnail"})
+}
+
+func testGetObjectThumbnailHandler(obj ObjectLayer, instanceType, bucketName string, apiRouter http.Handler,
+	credentials credential, t *testing.T) {
+	var pngBuf bytes.Buffer
+	if err := png.Encode(&pngBuf, newTestImage(400, 200)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := obj.PutObject(bucketName, "image.png", int64(pngBuf.Len()), bytes.NewReader(pngBuf.Bytes()), nil, ""); err != nil {
+		t.Fatalf("%s: %s", instanceType, err)
+	}
+	if _, err := obj.PutObject(bucketName, "text.txt", 5, bytes.NewReader([]byte("hello")), nil, ""); err != nil {
+		t.Fatalf("%s: %s", instanceType, err)
+	}
+
+	testCases := []struct {
+		object             string
+		size               string
+		expectedStatusCode int
+	}{
+		{"image.png", "100x100", http.StatusOK},
+		// Served from the cache.
+		{"image.png", "100x100", http.StatusOK},
+		{"image.png", "100", http.StatusBadRequest},
+		{"text.txt", "100x100", http.StatusBadRequest},
+		{"missing.png", "100x100", http.StatusNotFound},
+	}
+	for i, testCase := range testCases {
+		queryValues := url.Values{}
+		queryValues.Set("thumbnail", testCase.size)
+		rec := httptest.NewRecorder()
+		req, err := newTestSignedRequestV4("GET", makeTestTargetURL("", bucketName, testCase.object, queryValues),
+			0, nil, credentials.AccessKeyID, credentials.SecretAccessKey)
+		if err != nil {
+			t.Fatalf("%s: Failed to create HTTP request: <ERROR> %v", instanceType, err)
+		}
+		apiRouter.ServeHTTP(rec, req)
+		if rec.Code != testCase.expectedStatusCode {
+			t.Fatalf("%s: Test %d: Expected %d, got %d", instanceType, i+1, testCase.expectedStatusCode, rec.Code)
+		}
+		if rec.Code != http.StatusOK {
+			continue
+		}
+		if rec.Header().Get("Content-Type") != "image/png" {
+			t.Errorf("%s: Test %d: Expected image/png, got %s", instanceType, i+1, rec.Header().Get("Content-Type"))
+		}
+		config, err := png.DecodeConfig(rec.Body)
+		if err != nil || config.Width != 100 || config.Height != 50 {
+			t.Errorf("%s: Test %d: Expected a 100x50 PNG, got %v %v", instanceType, i+1, config, err)
+		}
+	}
+
+	// Thumbnail was cached.
+	objInfo, err := obj.GetObjectInfo(bucketName, "image.png")
+	if err != nil {
+		t.Fatalf("%s: %s", instanceType, err)
+	}
+	if _, err = obj.GetObjectInfo(minioMetaBucket, getThumbnailPath(objInfo, 100, 100)); err != nil {
+		t.Errorf("%s: Expected cached thumbnail, got %s", instanceType, err)
+	}
+}
+
+// Wrapper for calling thumbnail invalidation tests for both XL and FS.
+func TestThumbnailObjects(t *testing.T) {
+	ExecObjectLayerTest(t, testThumbnailObjects)
+}
+
+// Tests cached thumbnails are removed once their object is overwritten
+// or deleted.
+func testThumbnailObjects(obj ObjectLayer, instanceType string, t TestErrHandler) {
+	thumbObj := newThumbnailObjects(obj)
+	if err := thumbObj.MakeBucket("bucket"); err != nil {
+		t.Fatalf("%s: %s", instanceType, err)
+	}
+	var pngBuf bytes.Buffer
+	if err := png.Encode(&pngBuf, newTestImage(400, 200)); err != nil {
+		t.Fatal(err)
+	}
+
+	// Puts the image and caches its thumbnail, returns the path of the
+	// cached thumbnail.
+	putAndCache := func() string {
+		objInfo, err := thumbObj.PutObject("bucket", "image.png", int64(pngBuf.Len()), bytes.NewReader(pngBuf.Bytes()), nil, "")
+		if err != nil {
+			t.Fatalf("%s: %s", instanceType, err)
+		}
+		if _, _, err = getObjectThumbnail(thumbObj, objInfo, 100, 100); err != nil {
+			t.Fatalf("%s: %s", instanceType, err)
+		}
+		thumbnailPath := getThumbnailPath(objInfo, 100, 100)
+		if _, err = obj.GetObjectInfo(minioMetaBucket, thumbnailPath); err != nil {
+			t.Fatalf("%s: Expected cached thumbnail, got %s", instanceType, err)
+		}
+		return thumbnailPath
+	}
+
+	thumbnailPath := putAndCache()
+	putAndCache()
+	if _, err := obj.GetObjectInfo(minioMetaBucket, thumbnailPath); err == nil {
+		t.Errorf("%s: Expected thumbnail of the overwritten object to be removed", instanceType)
+	}
+
+	thumbnailPath = putAndCache()
+	if err := thumbObj.DeleteObject("bucket", "image.png"); err != nil {
+		t.Fatalf("%s: %s", instanceType, err)
+	}
+	if _, err := obj.GetObjectInfo(minioMetaBucket, thumbnailPath); err == nil {
+		t.Errorf("%s: Expected thumbnail of the deleted object to be removed", instanceType)
+	}
+}
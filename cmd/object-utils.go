@@ -18,10 +18,12 @@ package cmd
 
 import (
 	"crypto/md5"
+	"crypto/sha256"
 	"encoding/hex"
 	"fmt"
 	"path"
 	"regexp"
+	"strconv"
 	"strings"
 	"unicode/utf8"
 
@@ -139,22 +141,38 @@ func getUUID() (uuidStr string) {
 	return uuidStr
 }
 
-// Create an s3 compatible MD5sum for complete multipart transaction.
+// Create an s3 compatible MD5sum for complete multipart transaction,
+// parts with an ETag which is not hex encoded are invalid.
 func completeMultipartMD5(parts ...completePart) (string, error) {
 	var finalMD5Bytes []byte
 	for _, part := range parts {
 		md5Bytes, err := hex.DecodeString(part.ETag)
-		if err != nil {
-			return "", traceError(err)
+		if err != nil || len(md5Bytes) == 0 {
+			return "", traceError(InvalidPart{})
 		}
 		finalMD5Bytes = append(finalMD5Bytes, md5Bytes...)
 	}
-	md5Hasher := md5.New()
+	md5Hasher := newETagHash()
 	md5Hasher.Write(finalMD5Bytes)
 	s3MD5 := fmt.Sprintf("%s-%d", hex.EncodeToString(md5Hasher.Sum(nil)), len(parts))
 	return s3MD5, nil
 }
 
+// isMultipartETag - returns true if the ETag is of the form `md5(md5parts)-N`
+// as generated by completeMultipartMD5, or with SHA-256 in restricted
+// crypto mode.
+func isMultipartETag(etag string) bool {
+	idx := strings.LastIndex(etag, "-")
+	if idx == -1 {
+		return false
+	}
+	if _, err := hex.DecodeString(etag[:idx]); err != nil || (idx != md5.Size*2 && idx != sha256.Size*2) {
+		return false
+	}
+	partsCount, err := strconv.Atoi(etag[idx+1:])
+	return err == nil && partsCount > 0
+}
+
 // byBucketName is a collection satisfying sort.Interface.
 type byBucketName []BucketInfo
 
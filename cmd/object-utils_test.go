@@ -113,3 +113,30 @@ func TestIsValidObjectName(t *testing.T) {
 		}
 	}
 }
+
+// Tests validate multipart ETag detection.
+func TestIsMultipartETag(t *testing.T) {
+	testCases := []struct {
+		etag       string
+		shouldPass bool
+	}{
+		{"3b83ef96387f14655fc854ddc3c6bd57-2", true},
+		{"7e10e7d25dc4581d89b9285be5f384fd-10000", true},
+		{"3b83ef96387f14655fc854ddc3c6bd57", false},
+		{"3b83ef96387f14655fc854ddc3c6bd57-", false},
+		{"3b83ef96387f14655fc854ddc3c6bd57-0", false},
+		{"3b83ef96387f14655fc854ddc3c6bd57-a", false},
+		{"3b83ef96387f14655fc854ddc3c6bd-2", false},
+		{"zz83ef96387f14655fc854ddc3c6bd57-2", false},
+		// SHA-256 ETags of restricted crypto mode.
+		{"f2ca1bb6c7e907d06dafe4687e579fce76b37e4e93b7605022da52e6ccc26fd2-2", true},
+		{"", false},
+	}
+
+	for i, testCase := range testCases {
+		isMultipart := isMultipartETag(testCase.etag)
+		if isMultipart != testCase.shouldPass {
+			t.Errorf("Test case %d: Expected %t for \"%s\", got %t", i+1, testCase.shouldPass, testCase.etag, isMultipart)
+		}
+	}
+}
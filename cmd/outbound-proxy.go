@@ -0,0 +1,304 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"bufio"
+	"crypto/tls"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Proxies outbound connections of each subsystem are sent through.
+// When nil HTTP clients honor HTTP_PROXY, HTTPS_PROXY and NO_PROXY,
+// other connections are made directly.
+var (
+	// Update checks and downloads, set by MINIO_UPDATE_PROXY or
+	// `minio update --proxy`.
+	globalUpdateProxyURL *url.URL
+
+	// Notification targets and alert webhooks, set by
+	// MINIO_NOTIFY_PROXY.
+	globalNotifyProxyURL *url.URL
+)
+
+// Time allowed to connect to a notification target, the proxy
+// handshake included.
+const notifyDialTimeout = 30 * time.Second
+
+var (
+	errProxyScheme  = errors.New("Proxy URL scheme must be one of http, https or socks5")
+	errProxyNoHost  = errors.New("Proxy URL is missing a host")
+	errSOCKS5Auth   = errors.New("SOCKS5 proxy rejected authentication")
+	errSOCKS5Reply  = errors.New("SOCKS5 proxy sent a malformed reply")
+	errSOCKS5NoAuth = errors.New("SOCKS5 proxy accepts none of the offered authentication methods")
+)
+
+// SOCKS5 error replies, RFC 1928 section 6.
+var socks5Errors = []string{
+	"",
+	"general SOCKS server failure",
+	"connection not allowed by ruleset",
+	"network unreachable",
+	"host unreachable",
+	"connection refused",
+	"TTL expired",
+	"command not supported",
+	"address type not supported",
+}
+
+// parseProxyURL - validates a proxy URL.
+func parseProxyURL(proxy string) (*url.URL, error) {
+	proxyURL, err := url.Parse(proxy)
+	if err != nil {
+		return nil, err
+	}
+	switch proxyURL.Scheme {
+	case "http", "https", "socks5":
+	default:
+		return nil, errProxyScheme
+	}
+	if proxyURL.Host == "" {
+		return nil, errProxyNoHost
+	}
+	return proxyURL, nil
+}
+
+// getNotifyProxyURL - returns the proxy of notification targets.
+func getNotifyProxyURL() *url.URL {
+	return globalNotifyProxyURL
+}
+
+// setTransportProxy - sets transport to send requests through
+// proxyURL, or as configured by the environment when nil. http.Transport
+// only speaks plain HTTP to proxies, connections through https and
+// socks5 proxies are tunnelled by dialProxy instead.
+func setTransportProxy(transport *http.Transport, proxyURL *url.URL, timeout time.Duration) {
+	switch {
+	case proxyURL == nil:
+		transport.Proxy = http.ProxyFromEnvironment
+	case proxyURL.Scheme == "http":
+		transport.Proxy = http.ProxyURL(proxyURL)
+	default:
+		transport.Dial = func(network, addr string) (net.Conn, error) {
+			return dialProxy(proxyURL, addr, timeout)
+		}
+	}
+}
+
+// getProxyHostPort - returns the host and port of proxyURL, the port
+// defaults to the one of the proxy scheme.
+func getProxyHostPort(proxyURL *url.URL) (host, port string) {
+	host, port, err := net.SplitHostPort(proxyURL.Host)
+	if err != nil {
+		// No port in the URL, strip brackets of IPv6 addresses.
+		host = strings.TrimSuffix(strings.TrimPrefix(proxyURL.Host, "["), "]")
+		port = map[string]string{"http": "80", "https": "443", "socks5": "1080"}[proxyURL.Scheme]
+	}
+	return host, port
+}
+
+// dialProxy - opens a TCP connection to addr through proxyURL, with a
+// CONNECT request for http and https proxies. The connection is made
+// directly when proxyURL is nil.
+func dialProxy(proxyURL *url.URL, addr string, timeout time.Duration) (net.Conn, error) {
+	if proxyURL == nil {
+		return net.DialTimeout("tcp", addr, timeout)
+	}
+
+	proxyHost, proxyPort := getProxyHostPort(proxyURL)
+	conn, err := net.DialTimeout("tcp", net.JoinHostPort(proxyHost, proxyPort), timeout)
+	if err != nil {
+		return nil, err
+	}
+	// The whole handshake has to complete within timeout.
+	conn.SetDeadline(time.Now().Add(timeout))
+
+	switch proxyURL.Scheme {
+	case "socks5":
+		err = socks5Connect(conn, proxyURL.User, addr)
+	case "https":
+		config := newOutboundTLSConfig(false)
+		config.ServerName = proxyHost
+		tlsConn := tls.Client(conn, config)
+		if err = tlsConn.Handshake(); err == nil {
+			conn, err = httpConnect(tlsConn, proxyURL.User, addr)
+		}
+	default:
+		conn, err = httpConnect(conn, proxyURL.User, addr)
+	}
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	conn.SetDeadline(time.Time{})
+	return conn, nil
+}
+
+// bufferedConn - net.Conn whose reads first drain what was buffered
+// while reading the proxy's response.
+type bufferedConn struct {
+	net.Conn
+	r *bufio.Reader
+}
+
+func (c bufferedConn) Read(p []byte) (int, error) {
+	return c.r.Read(p)
+}
+
+// httpConnect - asks the HTTP proxy at the other end of conn to
+// connect to addr.
+func httpConnect(conn net.Conn, user *url.Userinfo, addr string) (net.Conn, error) {
+	req := &http.Request{
+		Method: "CONNECT",
+		URL:    &url.URL{Opaque: addr},
+		Host:   addr,
+		Header: make(http.Header),
+	}
+	if user != nil {
+		password, _ := user.Password()
+		credentials := base64.StdEncoding.EncodeToString([]byte(user.Username() + ":" + password))
+		req.Header.Set("Proxy-Authorization", "Basic "+credentials)
+	}
+	if err := req.Write(conn); err != nil {
+		return conn, err
+	}
+	r := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(r, req)
+	if err != nil {
+		return conn, err
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return conn, fmt.Errorf("Proxy refused to connect to %s: %s", addr, resp.Status)
+	}
+	return bufferedConn{Conn: conn, r: r}, nil
+}
+
+// socks5Connect - asks the SOCKS5 proxy at the other end of conn to
+// connect to addr, authenticating with user if set (RFC 1928, 1929).
+func socks5Connect(conn net.Conn, user *url.Userinfo, addr string) error {
+	host, portStr, err := net.SplitHostPort(addr)
+	if err != nil {
+		return err
+	}
+	port, err := strconv.Atoi(portStr)
+	if err != nil || port < 1 || port > 65535 {
+		return fmt.Errorf("Invalid port in %s", addr)
+	}
+
+	// Offer no authentication, and username/password if configured.
+	methods := []byte{0x00}
+	if user != nil {
+		methods = append(methods, 0x02)
+	}
+	if _, err = conn.Write(append([]byte{0x05, byte(len(methods))}, methods...)); err != nil {
+		return err
+	}
+	reply := make([]byte, 2)
+	if _, err = io.ReadFull(conn, reply); err != nil {
+		return err
+	}
+	if reply[0] != 0x05 {
+		return errSOCKS5Reply
+	}
+	switch reply[1] {
+	case 0x00:
+	case 0x02:
+		if user == nil {
+			return errSOCKS5NoAuth
+		}
+		password, _ := user.Password()
+		if len(user.Username()) > 255 || len(password) > 255 {
+			return errSOCKS5Auth
+		}
+		auth := []byte{0x01, byte(len(user.Username()))}
+		auth = append(auth, user.Username()...)
+		auth = append(auth, byte(len(password)))
+		auth = append(auth, password...)
+		if _, err = conn.Write(auth); err != nil {
+			return err
+		}
+		if _, err = io.ReadFull(conn, reply); err != nil {
+			return err
+		}
+		if reply[1] != 0x00 {
+			return errSOCKS5Auth
+		}
+	default:
+		return errSOCKS5NoAuth
+	}
+
+	// CONNECT request, the proxy resolves host names.
+	req := []byte{0x05, 0x01, 0x00}
+	if ip := net.ParseIP(host); ip != nil {
+		if ip4 := ip.To4(); ip4 != nil {
+			req = append(append(req, 0x01), ip4...)
+		} else {
+			req = append(append(req, 0x04), ip.To16()...)
+		}
+	} else {
+		if len(host) > 255 {
+			return fmt.Errorf("Host name too long in %s", addr)
+		}
+		req = append(append(req, 0x03, byte(len(host))), host...)
+	}
+	req = append(req, byte(port>>8), byte(port))
+	if _, err = conn.Write(req); err != nil {
+		return err
+	}
+
+	header := make([]byte, 4)
+	if _, err = io.ReadFull(conn, header); err != nil {
+		return err
+	}
+	if header[0] != 0x05 {
+		return errSOCKS5Reply
+	}
+	if header[1] != 0x00 {
+		if int(header[1]) < len(socks5Errors) {
+			return fmt.Errorf("SOCKS5 proxy failed to connect to %s: %s", addr, socks5Errors[header[1]])
+		}
+		return errSOCKS5Reply
+	}
+	// Skip the bound address and port.
+	var skip int
+	switch header[3] {
+	case 0x01:
+		skip = net.IPv4len + 2
+	case 0x04:
+		skip = net.IPv6len + 2
+	case 0x03:
+		length := make([]byte, 1)
+		if _, err = io.ReadFull(conn, length); err != nil {
+			return err
+		}
+		skip = int(length[0]) + 2
+	default:
+		return errSOCKS5Reply
+	}
+	_, err = io.ReadFull(conn, make([]byte, skip))
+	return err
+}
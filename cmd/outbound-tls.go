@@ -0,0 +1,103 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// Root CAs outbound TLS connections to external services are verified
+// against, the system roots plus the certificates of MINIO_CA_BUNDLE.
+// Nil when no bundle is configured, the system roots are used.
+var globalRootCAs *x509.CertPool
+
+// errCABundleEmpty - MINIO_CA_BUNDLE holds no PEM certificate.
+var errCABundleEmpty = errors.New("No PEM encoded certificates found in CA bundle")
+
+// loadCABundle - returns the system roots with the PEM encoded
+// certificates at bundlePath added.
+func loadCABundle(bundlePath string) (*x509.CertPool, error) {
+	data, err := ioutil.ReadFile(bundlePath)
+	if err != nil {
+		return nil, err
+	}
+	pool, err := x509.SystemCertPool()
+	if err != nil {
+		// No system roots, e.g. on windows, trust the bundle only.
+		pool = x509.NewCertPool()
+	}
+	if !pool.AppendCertsFromPEM(data) {
+		return nil, errCABundleEmpty
+	}
+	return pool, nil
+}
+
+// newOutboundTLSConfig - returns a TLS config for connections to
+// external services, the update server, etcd, notification targets and
+// webhooks. insecure disables certificate verification, for targets
+// which explicitly opt into it.
+func newOutboundTLSConfig(insecure bool) *tls.Config {
+	config := newTLSConfig()
+	config.RootCAs = globalRootCAs
+	config.InsecureSkipVerify = insecure
+	return config
+}
+
+// outboundTransport - http.RoundTripper for external services. The
+// underlying transport is only built on first use, so that clients
+// created at package initialization still verify against the CA
+// bundle loaded at startup.
+type outboundTransport struct {
+	once     sync.Once
+	insecure bool
+	// Returns the proxy of the subsystem, nil if requests are
+	// proxied as configured by the environment.
+	proxy func() *url.URL
+	// Time to wait for response headers, zero means no limit.
+	responseHeaderTimeout time.Duration
+	transport             *http.Transport
+}
+
+// newOutboundTransport - returns a transport for external services,
+// see newOutboundTLSConfig.
+func newOutboundTransport(insecure bool) *outboundTransport {
+	return &outboundTransport{insecure: insecure}
+}
+
+// RoundTrip - implements http.RoundTripper.
+func (t *outboundTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.once.Do(func() {
+		var proxyURL *url.URL
+		if t.proxy != nil {
+			proxyURL = t.proxy()
+		}
+		t.transport = &http.Transport{
+			TLSClientConfig:       newOutboundTLSConfig(t.insecure),
+			TLSHandshakeTimeout:   10 * time.Second,
+			ResponseHeaderTimeout: t.responseHeaderTimeout,
+		}
+		setTransportProxy(t.transport, proxyURL, notifyDialTimeout)
+	})
+	return t.transport.RoundTrip(req)
+}
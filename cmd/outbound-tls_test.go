@@ -0,0 +1,88 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"encoding/pem"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+)
+
+// Validates loading MINIO_CA_BUNDLE and verifying outbound TLS
+// connections against it.
+func TestOutboundTLSCABundle(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	bundle, err := ioutil.TempFile("", "minio-ca-bundle-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(bundle.Name())
+	pem.Encode(bundle, &pem.Block{Type: "CERTIFICATE", Bytes: server.TLS.Certificates[0].Certificate[0]})
+	bundle.Close()
+
+	defer func() { globalRootCAs = nil }()
+
+	get := func(insecure bool) error {
+		client := &http.Client{Transport: newOutboundTransport(insecure)}
+		resp, err := client.Get(server.URL)
+		if err != nil {
+			return err
+		}
+		resp.Body.Close()
+		return nil
+	}
+
+	// The test server's certificate is not trusted by default.
+	if err = get(false); err == nil {
+		t.Fatal("Expected certificate of the test server to be rejected")
+	}
+	// Unless verification is skipped.
+	if err = get(true); err != nil {
+		t.Fatalf("Expected insecure connection to succeed, got %s", err)
+	}
+
+	globalRootCAs, err = loadCABundle(bundle.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = get(false); err != nil {
+		t.Fatalf("Expected certificate of the test server to be trusted, got %s", err)
+	}
+}
+
+// Validates errors loading MINIO_CA_BUNDLE.
+func TestLoadCABundleErrors(t *testing.T) {
+	if _, err := loadCABundle("/nonexistent/ca.pem"); !os.IsNotExist(err) {
+		t.Errorf("Expected not exist error, got %v", err)
+	}
+
+	bundle, err := ioutil.TempFile("", "minio-ca-bundle-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(bundle.Name())
+	bundle.WriteString("not a certificate")
+	bundle.Close()
+	if _, err = loadCABundle(bundle.Name()); err != errCABundleEmpty {
+		t.Errorf("Expected %v, got %v", errCABundleEmpty, err)
+	}
+}
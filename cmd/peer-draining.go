@@ -0,0 +1,198 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package cmd
+
+import (
+	"io"
+	"net"
+	"net/rpc"
+	"sync"
+	"time"
+)
+
+const (
+	// Peers mark a restarting node draining for this long at most,
+	// calls to it are retried or queued meanwhile.
+	peerDrainGracePeriod = 2 * time.Minute
+
+	// First wait before retrying a call to a draining node, doubled
+	// on every retry up to peerDrainMaxBackoff.
+	peerDrainBackoff    = 100 * time.Millisecond
+	peerDrainMaxBackoff = 5 * time.Second
+
+	// Maximum number of calls queued for a draining node, later calls
+	// are sent right away and fail like before.
+	peerDrainQueueSize = 1000
+)
+
+// A non-urgent call queued until a draining node is back.
+type queuedPeerCall struct {
+	method string
+	args   interface {
+		SetToken(token string)
+		SetTimestamp(tstamp time.Time)
+	}
+}
+
+// drainingPeers - nodes which announced a restart, with the time
+// until which they are considered draining and their queued calls.
+type drainingPeers struct {
+	mutex  *sync.Mutex
+	until  map[string]time.Time
+	queued map[string][]queuedPeerCall
+}
+
+// Variable holding the nodes draining for a restart.
+var globalDrainingPeers = newDrainingPeers()
+
+func newDrainingPeers() *drainingPeers {
+	return &drainingPeers{
+		mutex:  &sync.Mutex{},
+		until:  make(map[string]time.Time),
+		queued: make(map[string][]queuedPeerCall),
+	}
+}
+
+// Mark - marks peer (in `host:port` format) draining for grace,
+// queued calls are replayed once it expires unless the peer
+// announces it is back earlier.
+func (d *drainingPeers) Mark(peer string, grace time.Duration) {
+	if grace <= 0 {
+		grace = peerDrainGracePeriod
+	}
+	d.mutex.Lock()
+	d.until[peer] = time.Now().UTC().Add(grace)
+	d.mutex.Unlock()
+
+	time.AfterFunc(grace, func() {
+		if !d.IsDraining(peer) {
+			replayPeerCalls(peer, d.Resume(peer))
+		}
+	})
+}
+
+// IsDraining - returns true if peer is draining for a restart.
+func (d *drainingPeers) IsDraining(peer string) bool {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	until, ok := d.until[peer]
+	return ok && time.Now().UTC().Before(until)
+}
+
+// Queue - queues a call to peer if it is draining, returns false if
+// the call has to be sent right away.
+func (d *drainingPeers) Queue(peer, method string, args interface {
+	SetToken(token string)
+	SetTimestamp(tstamp time.Time)
+}) bool {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	until, ok := d.until[peer]
+	if !ok || !time.Now().UTC().Before(until) {
+		return false
+	}
+	if len(d.queued[peer]) >= peerDrainQueueSize {
+		return false
+	}
+	d.queued[peer] = append(d.queued[peer], queuedPeerCall{method, args})
+	return true
+}
+
+// Resume - clears the draining mark of peer and returns the calls
+// queued for it.
+func (d *drainingPeers) Resume(peer string) []queuedPeerCall {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	calls := d.queued[peer]
+	delete(d.until, peer)
+	delete(d.queued, peer)
+	return calls
+}
+
+// Sends the calls queued while peer was draining, in their order.
+func replayPeerCalls(peer string, calls []queuedPeerCall) {
+	if len(calls) == 0 {
+		return
+	}
+	client := globalS3Peers.GetPeerClient(peer)
+	if client == nil {
+		return
+	}
+	for _, call := range calls {
+		reply := &GenericReply{}
+		err := client.Call(call.method, call.args, reply)
+		errorIf(err, "Unable to replay %s queued for draining peer %s.", call.method, peer)
+	}
+}
+
+// isPeerDownErr - returns true if err means the call did not reach
+// the peer, or the peer has not initialized yet after a restart.
+func isPeerDownErr(err error) bool {
+	if err == nil {
+		return false
+	}
+	if _, ok := err.(net.Error); ok {
+		return true
+	}
+	switch err {
+	case rpc.ErrShutdown, io.EOF, io.ErrUnexpectedEOF, errServerNotInitialized:
+		return true
+	}
+	return false
+}
+
+// DrainPeerArgs - arguments for DrainPeer RPC.
+type DrainPeerArgs struct {
+	// For Auth
+	GenericArgs
+
+	// Node announcing the restart, in `host:port` format.
+	Peer string
+
+	// True before the node goes down, false once it is back.
+	Draining bool
+
+	// How long the node expects to be away.
+	GracePeriod time.Duration
+}
+
+// Announces to all the peers that the local node is about to restart,
+// or that it is back after a restart. Errors are only logged, peers
+// stop considering the node draining after the grace period anyway.
+func announceDraining(draining bool) {
+	args := DrainPeerArgs{
+		Peer:        globalMinioAddr,
+		Draining:    draining,
+		GracePeriod: peerDrainGracePeriod,
+	}
+	var wg = &sync.WaitGroup{}
+	for _, peer := range globalS3Peers.GetPeers() {
+		if peer == globalMinioAddr {
+			continue
+		}
+		client := globalS3Peers.GetPeerClient(peer)
+		if client == nil {
+			continue
+		}
+		wg.Add(1)
+		go func(peer string, client *AuthRPCClient, args DrainPeerArgs) {
+			defer wg.Done()
+			err := client.Call("S3.DrainPeer", &args, &GenericReply{})
+			errorIf(err, "Unable to announce draining to peer %s.", peer)
+		}(peer, client, args)
+	}
+	wg.Wait()
+}
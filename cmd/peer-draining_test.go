@@ -0,0 +1,168 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package cmd
+
+import (
+	"fmt"
+	"net"
+	"net/http/httptest"
+	"path"
+	"testing"
+	"time"
+
+	router "github.com/gorilla/mux"
+)
+
+// Tests marking, queueing for and resuming draining peers.
+func TestDrainingPeers(t *testing.T) {
+	d := newDrainingPeers()
+	args := &GenericArgs{}
+
+	if d.IsDraining("node1:9000") {
+		t.Fatal("Expected node1 not to be draining")
+	}
+	if d.Queue("node1:9000", "S3.SetBucketPolicyPeer", args) {
+		t.Fatal("Expected calls to node1 not to be queued")
+	}
+
+	d.Mark("node1:9000", time.Hour)
+	if !d.IsDraining("node1:9000") {
+		t.Fatal("Expected node1 to be draining")
+	}
+	if d.IsDraining("node2:9000") {
+		t.Fatal("Expected node2 not to be draining")
+	}
+	for _, method := range []string{"S3.SetBucketPolicyPeer", "S3.SetBucketSettingsPeer"} {
+		if !d.Queue("node1:9000", method, args) {
+			t.Fatalf("Expected %s to node1 to be queued", method)
+		}
+	}
+	calls := d.Resume("node1:9000")
+	if len(calls) != 2 || calls[0].method != "S3.SetBucketPolicyPeer" || calls[1].method != "S3.SetBucketSettingsPeer" {
+		t.Fatalf("Unexpected queued calls %v", calls)
+	}
+	if d.IsDraining("node1:9000") {
+		t.Fatal("Expected node1 not to be draining once resumed")
+	}
+
+	// Peers stop draining after the grace period.
+	d.Mark("node2:9000", 50*time.Millisecond)
+	time.Sleep(100 * time.Millisecond)
+	if d.IsDraining("node2:9000") {
+		t.Fatal("Expected node2 not to be draining after the grace period")
+	}
+	if d.Queue("node2:9000", "S3.SetBucketPolicyPeer", args) {
+		t.Fatal("Expected calls to node2 not to be queued after the grace period")
+	}
+}
+
+// Returns a new auth client of the S3 peer RPC server at addr.
+func newTestS3PeerClient(addr string) *AuthRPCClient {
+	cred := serverConfig.GetCredential()
+	return newAuthClient(&authConfig{
+		accessKey:   cred.AccessKeyID,
+		secretKey:   cred.SecretAccessKey,
+		address:     addr,
+		path:        path.Join(reservedBucket, s3Path),
+		loginMethod: "S3.LoginHandler",
+		retryDrain:  true,
+	})
+}
+
+// Returns a new unstarted server of the S3 peer RPCs.
+func newTestS3PeerServer(t *testing.T) *httptest.Server {
+	mux := router.NewRouter()
+	if err := registerS3PeerRPCRouter(mux); err != nil {
+		t.Fatal(err)
+	}
+	return httptest.NewUnstartedServer(mux)
+}
+
+// Tests the DrainPeer RPC marks and resumes the announcing peer.
+func TestS3PeerDrainPeer(t *testing.T) {
+	root, err := newTestConfig("us-east-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer removeAll(root)
+
+	ts := newTestS3PeerServer(t)
+	ts.Start()
+	defer ts.Close()
+
+	peer := fmt.Sprintf("127.0.0.1:%d", getFreePort())
+	defer globalDrainingPeers.Resume(peer)
+
+	client := newTestS3PeerClient(ts.Listener.Addr().String())
+	defer client.Close()
+	args := &DrainPeerArgs{Peer: peer, Draining: true, GracePeriod: time.Hour}
+	if err := client.Call("S3.DrainPeer", args, &GenericReply{}); err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	if !globalDrainingPeers.IsDraining(peer) {
+		t.Fatal("Expected the peer to be draining")
+	}
+
+	args = &DrainPeerArgs{Peer: peer, Draining: false}
+	if err := client.Call("S3.DrainPeer", args, &GenericReply{}); err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	if globalDrainingPeers.IsDraining(peer) {
+		t.Fatal("Expected the peer not to be draining once back")
+	}
+}
+
+// Tests calls to a draining peer are retried until it is back.
+func TestAuthRPCClientRetryDrain(t *testing.T) {
+	root, err := newTestConfig("us-east-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer removeAll(root)
+
+	addr := fmt.Sprintf("127.0.0.1:%d", getFreePort())
+	client := newTestS3PeerClient(addr)
+	defer client.Close()
+
+	args := &DrainPeerArgs{Peer: "node1:9000"}
+
+	// Peers which are not draining fail right away.
+	if err := client.Call("S3.DrainPeer", args, &GenericReply{}); !isPeerDownErr(err) {
+		t.Fatalf("Expected a peer down error, got %v", err)
+	}
+
+	globalDrainingPeers.Mark(addr, time.Minute)
+	defer globalDrainingPeers.Resume(addr)
+
+	// Bring the peer up after a while.
+	ts := newTestS3PeerServer(t)
+	defer ts.Close()
+	go func() {
+		time.Sleep(300 * time.Millisecond)
+		l, err := net.Listen("tcp", addr)
+		if err != nil {
+			t.Error("Unable to listen:", err)
+			return
+		}
+		ts.Listener.Close()
+		ts.Listener = l
+		ts.Start()
+	}()
+
+	if err := client.Call("S3.DrainPeer", args, &GenericReply{}); err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+}
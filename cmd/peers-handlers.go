@@ -0,0 +1,275 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package cmd
+
+import (
+	"errors"
+	"net"
+	"strings"
+	"sync"
+)
+
+// errPeerExists - peer is already a member of the cluster.
+var errPeerExists = errors.New("Peer is already a member of the cluster.")
+
+// errPeerNotFound - peer is not a member of the cluster.
+var errPeerNotFound = errors.New("Peer is not a member of the cluster.")
+
+// errPeerIsLocal - the local node cannot be removed from its own peers.
+var errPeerIsLocal = errors.New("Local node cannot be removed from the cluster.")
+
+// errPeerHasDisks - peer still serves disks of the object layer, its
+// disks have to be decommissioned before it can be removed.
+var errPeerHasDisks = errors.New("Peer still serves disks of the object layer.")
+
+// errPeerQuorum - membership change was not acknowledged by a quorum of nodes.
+var errPeerQuorum = errors.New("Peer membership change was not acknowledged by a quorum of nodes.")
+
+// PeerArgs - arguments for AddPeer and RemovePeer RPC.
+type PeerArgs struct {
+	// Authentication token generated by Login.
+	GenericArgs
+
+	// Peer address in `host:port` format.
+	Peer string
+}
+
+// PeersReply - reply by ListPeers, AddPeer and RemovePeer RPC.
+type PeersReply struct {
+	// Address of the node which served the request.
+	LocalNode string
+	// All the s3 peers, including the local node.
+	S3Peers []string
+	// All the remote control peers.
+	ControlPeers []string
+}
+
+// Returns the number of nodes, out of total nodes, which need to
+// acknowledge a membership change.
+func peerQuorum(nodes int) int {
+	return nodes/2 + 1
+}
+
+// Validates peer address is in `host:port` format.
+func isValidPeerAddr(peer string) bool {
+	host, port, err := net.SplitHostPort(peer)
+	return err == nil && host != "" && port != ""
+}
+
+// Returns the current peers of the local node.
+func (c *controlAPIHandlers) getPeers() PeersReply {
+	reply := PeersReply{
+		LocalNode: c.LocalNode,
+		S3Peers:   globalS3Peers.GetPeers(),
+	}
+	for _, client := range c.getRemoteControls() {
+		reply.ControlPeers = append(reply.ControlPeers, client.Node())
+	}
+	return reply
+}
+
+// Returns true if peer is either an s3 peer or a control peer.
+func (c *controlAPIHandlers) hasPeer(peer string) bool {
+	if globalS3Peers.GetPeerClient(peer) != nil {
+		return true
+	}
+	for _, client := range c.getRemoteControls() {
+		if client.Node() == peer {
+			return true
+		}
+	}
+	return false
+}
+
+// Returns true if any of the disks of the object layer is served by peer.
+func (c *controlAPIHandlers) peerHasDisks(peer string) bool {
+	host, _, err := net.SplitHostPort(peer)
+	if err != nil {
+		return false
+	}
+	for _, disk := range c.StorageDisks {
+		if disk == nil {
+			continue
+		}
+		if strings.HasPrefix(disk.String(), host+":") {
+			return true
+		}
+	}
+	return false
+}
+
+// Adds peer to the s3 peers and the control peers of the local node.
+func (c *controlAPIHandlers) addPeer(peer string) {
+	globalS3Peers.AddPeer(peer)
+
+	c.peersMutex.Lock()
+	defer c.peersMutex.Unlock()
+	for _, client := range c.RemoteControls {
+		if client.Node() == peer {
+			return
+		}
+	}
+	c.RemoteControls = append(c.RemoteControls, newRemoteControlClient(peer))
+}
+
+// Removes peer from the s3 peers and the control peers of the local node.
+func (c *controlAPIHandlers) removePeer(peer string) {
+	globalS3Peers.RemovePeer(peer)
+
+	c.peersMutex.Lock()
+	defer c.peersMutex.Unlock()
+	var remoteControls []*AuthRPCClient
+	for _, client := range c.RemoteControls {
+		if client.Node() == peer {
+			_ = client.Close()
+			continue
+		}
+		remoteControls = append(remoteControls, client)
+	}
+	c.RemoteControls = remoteControls
+}
+
+// Remote procedure call, calls method on the given remote nodes.
+func remotePeerCall(remoteControls []*AuthRPCClient, method string, args *PeerArgs) []error {
+	var wg sync.WaitGroup
+	var errs = make([]error, len(remoteControls))
+	for index, clnt := range remoteControls {
+		wg.Add(1)
+		go func(index int, client *AuthRPCClient) {
+			defer wg.Done()
+			errs[index] = client.Call(method, args, &PeersReply{})
+			errorIf(errs[index], "Unable to initiate control %s request to remote node %s", method, client.Node())
+		}(index, clnt)
+	}
+	wg.Wait()
+	return errs
+}
+
+// Broadcasts a membership change to the given remote nodes. If less
+// than a quorum of nodes, counting the local node, acknowledge the
+// change it is reverted with undoMethod on the nodes which applied it.
+func broadcastPeerChange(remoteControls []*AuthRPCClient, method, undoMethod string, args *PeerArgs) error {
+	remoteArgs := *args
+	// Set remote as false for remote calls.
+	remoteArgs.Remote = false
+
+	acks := 1
+	var applied []*AuthRPCClient
+	for index, err := range remotePeerCall(remoteControls, method, &remoteArgs) {
+		if err == nil {
+			acks++
+			applied = append(applied, remoteControls[index])
+		}
+	}
+	if acks < peerQuorum(len(remoteControls)+1) {
+		remotePeerCall(applied, undoMethod, &remoteArgs)
+		return errPeerQuorum
+	}
+	return nil
+}
+
+// ListPeersHandler - RPC control handler for `minio control peers list`.
+// Returns the s3 peers and the control peers of the node.
+func (c *controlAPIHandlers) ListPeersHandler(args *GenericArgs, reply *PeersReply) (err error) {
+	defer encodeRPCError(&err)
+
+	if !isRPCTokenValid(args.Token, jwtAudienceAdmin) {
+		return errInvalidToken
+	}
+	*reply = c.getPeers()
+	return nil
+}
+
+// AddPeerHandler - RPC control handler for `minio control peers add`.
+// Adds a new node to the peers of the cluster, the new node has to be
+// reachable with the credentials of the cluster.
+func (c *controlAPIHandlers) AddPeerHandler(args *PeerArgs, reply *PeersReply) (err error) {
+	defer encodeRPCError(&err)
+
+	if !isRPCTokenValid(args.Token, jwtAudienceAdmin) {
+		return errInvalidToken
+	}
+	// Retries get the result of the first call.
+	if replayed, replayErr := globalIdempotentCalls.Begin("Control.AddPeerHandler", args.IdempotencyKey, reply); replayed {
+		return replayErr
+	}
+	defer globalIdempotentCalls.End("Control.AddPeerHandler", args.IdempotencyKey, reply, &err)
+
+	if !isValidPeerAddr(args.Peer) {
+		return errInvalidArgument
+	}
+	if args.Remote {
+		if args.Peer == c.LocalNode || c.hasPeer(args.Peer) {
+			return errPeerExists
+		}
+		client := newRemoteControlClient(args.Peer)
+		err = client.Call("Control.ListPeersHandler", &GenericArgs{}, &PeersReply{})
+		client.Close()
+		if err != nil {
+			return err
+		}
+		if err = broadcastPeerChange(c.getRemoteControls(), "Control.AddPeerHandler", "Control.RemovePeerHandler", args); err != nil {
+			return err
+		}
+	}
+	c.addPeer(args.Peer)
+	*reply = c.getPeers()
+	return nil
+}
+
+// RemovePeerHandler - RPC control handler for `minio control peers remove`.
+// Removes a node from the peers of the cluster, refuses to remove a node
+// which still serves disks of the object layer.
+func (c *controlAPIHandlers) RemovePeerHandler(args *PeerArgs, reply *PeersReply) (err error) {
+	defer encodeRPCError(&err)
+
+	if !isRPCTokenValid(args.Token, jwtAudienceAdmin) {
+		return errInvalidToken
+	}
+	// Retries get the result of the first call.
+	if replayed, replayErr := globalIdempotentCalls.Begin("Control.RemovePeerHandler", args.IdempotencyKey, reply); replayed {
+		return replayErr
+	}
+	defer globalIdempotentCalls.End("Control.RemovePeerHandler", args.IdempotencyKey, reply, &err)
+
+	if args.Peer == c.LocalNode {
+		return errPeerIsLocal
+	}
+	if !isValidPeerAddr(args.Peer) {
+		return errInvalidArgument
+	}
+	if c.peerHasDisks(args.Peer) {
+		return errPeerHasDisks
+	}
+	if args.Remote {
+		if !c.hasPeer(args.Peer) {
+			return errPeerNotFound
+		}
+		// The removed node is not told about its removal.
+		var remoteControls []*AuthRPCClient
+		for _, client := range c.getRemoteControls() {
+			if client.Node() != args.Peer {
+				remoteControls = append(remoteControls, client)
+			}
+		}
+		if err = broadcastPeerChange(remoteControls, "Control.RemovePeerHandler", "Control.AddPeerHandler", args); err != nil {
+			return err
+		}
+	}
+	c.removePeer(args.Peer)
+	*reply = c.getPeers()
+	return nil
+}
@@ -0,0 +1,142 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/minio/minio-go/pkg/set"
+)
+
+// Layers of the access stack which decide on simulated requests.
+const (
+	policySourceCredential   = "credential"
+	policySourceBucketPolicy = "bucket-policy"
+)
+
+// PolicySimulateArgs - argument for PolicySimulate RPC handler.
+type PolicySimulateArgs struct {
+	// Authentication token generated by Login.
+	GenericArgs
+
+	// Access key the request is signed with, anonymous if empty.
+	AccessKey string
+
+	// Action requested, such as s3:GetObject.
+	Action string
+
+	Bucket string
+	// Object requested, empty for bucket level actions.
+	Object string
+
+	// Condition context keyed by query parameter, such as prefix
+	// and max-keys of s3:ListBucket.
+	Conditions map[string]string
+}
+
+// PolicySimulateReply - reply by PolicySimulate RPC handler.
+type PolicySimulateReply struct {
+	Allowed bool
+
+	// Layer which decided, credential or bucket-policy.
+	Source string
+
+	// Statement of the bucket policy which decided, its index is 1
+	// based in evaluation order, where Deny statements come first,
+	// and 0 if no statement matched.
+	StatementIndex int
+	Statement      string
+
+	// Explanation of the decision.
+	Reason string
+}
+
+// simulatePolicy - decides on a request the way the request handlers
+// do, along with the statement which decided.
+func simulatePolicy(objAPI ObjectLayer, args *PolicySimulateArgs) (reply PolicySimulateReply, err error) {
+	if args.AccessKey != "" {
+		reply.Source = policySourceCredential
+		if args.AccessKey != serverConfig.GetCredential().AccessKeyID {
+			reply.Reason = "Access key is unknown, requests are rejected with InvalidAccessKeyId."
+			return reply, nil
+		}
+		reply.Allowed = true
+		reply.Reason = "Access key has full access to all buckets, bucket policies only apply to anonymous requests."
+		return reply, nil
+	}
+
+	if err = isBucketExist(args.Bucket, objAPI); err != nil {
+		return reply, err
+	}
+	reply.Source = policySourceBucketPolicy
+	policy := globalBucketPolicies.GetBucketPolicy(args.Bucket)
+	if policy == nil {
+		reply.Reason = "Bucket has no policy, anonymous requests are denied."
+		return reply, nil
+	}
+
+	resource := AWSResourcePrefix + args.Bucket
+	if args.Object != "" {
+		resource += slashSeparator + args.Object
+	}
+	conditions := make(map[string]set.StringSet)
+	for key, value := range args.Conditions {
+		conditions[key] = set.CreateStringSet(value)
+	}
+
+	index := bucketPolicyFindStatement(args.Action, resource, conditions, policy.Statements)
+	if index < 0 {
+		reply.Reason = "No statement of the bucket policy matches, denied by default."
+		return reply, nil
+	}
+	statement := policy.Statements[index]
+	statementBytes, err := json.Marshal(statement)
+	if err != nil {
+		return reply, err
+	}
+	reply.Allowed = statement.Effect == "Allow"
+	reply.StatementIndex = index + 1
+	reply.Statement = string(statementBytes)
+	reply.Reason = "First statement matching action, resource and conditions, Deny statements are evaluated before Allow statements."
+	return reply, nil
+}
+
+// PolicySimulateHandler - RPC control handler for `minio control
+// simulate-policy`, reports whether a request would be allowed and
+// which layer and bucket policy statement decide on it, without
+// making the request.
+func (c *controlAPIHandlers) PolicySimulateHandler(args *PolicySimulateArgs, reply *PolicySimulateReply) (err error) {
+	defer encodeRPCError(&err)
+
+	if !isRPCTokenValid(args.Token, jwtAudienceAdmin) {
+		return errInvalidToken
+	}
+	if !strings.HasPrefix(args.Action, "s3:") || !IsValidBucketName(args.Bucket) {
+		return errInvalidArgument
+	}
+	if args.Object != "" && !IsValidObjectName(args.Object) {
+		return errInvalidArgument
+	}
+	objAPI := c.ObjectAPI()
+	if objAPI == nil {
+		return errServerNotInitialized
+	}
+
+	*reply, err = simulatePolicy(objAPI, args)
+	return err
+}
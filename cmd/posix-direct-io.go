@@ -0,0 +1,112 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package cmd
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"sync/atomic"
+	"unsafe"
+)
+
+const (
+	// Alignment of buffers, file offsets and sizes of direct IO,
+	// the logical block size of most disks.
+	directIOAlignment = 4096
+
+	// Object data files are written with direct IO beyond this size,
+	// unless MINIO_DIRECT_IO_MIN_SIZE is set.
+	defaultDirectIOMinSize = 4 * 1024 * 1024
+)
+
+// errDirectIOUnsupported - direct IO is not supported by the platform.
+var errDirectIOUnsupported = errors.New("Direct IO is not supported")
+
+// isDirectIOFile - returns true for object data files written to the
+// tmp directory, metadata files are always written through the page
+// cache.
+func isDirectIOFile(volume, path string) bool {
+	return isTmpPath(volume, path) && !strings.HasSuffix(path, ".json")
+}
+
+// alignedBlock - returns a buffer of size bytes aligned to
+// directIOAlignment.
+func alignedBlock(size int) []byte {
+	block := make([]byte, size+directIOAlignment)
+	if offset := int(uintptr(unsafe.Pointer(&block[0])) % directIOAlignment); offset != 0 {
+		block = block[directIOAlignment-offset:]
+	}
+	return block[:size]
+}
+
+// isAligned - returns true if block starts at an address aligned to
+// directIOAlignment.
+func isAligned(block []byte) bool {
+	return uintptr(unsafe.Pointer(&block[0]))%directIOAlignment == 0
+}
+
+// appendDirect - writes the largest prefix of buf which is a multiple
+// of directIOAlignment at the end of the file with direct IO, if the
+// file ends at an aligned offset and reaches globalDirectIOMinSize.
+// Returns the rest of buf, to be written through the page cache.
+// Direct IO is disabled on the disk if it turns out unsupported.
+func (s *posix) appendDirect(w *os.File, filePath string, buf []byte) ([]byte, error) {
+	if atomic.LoadInt32(&s.directIODisabled) == 1 {
+		return buf, nil
+	}
+	st, err := w.Stat()
+	if err != nil {
+		return nil, err
+	}
+	offset := st.Size()
+	size := len(buf) / directIOAlignment * directIOAlignment
+	if size == 0 || offset%directIOAlignment != 0 || offset+int64(len(buf)) < globalDirectIOMinSize {
+		return buf, nil
+	}
+
+	d, err := openFileDirect(filePath)
+	if err != nil {
+		if isDirectIOUnsupported(err) {
+			s.disableDirectIO(err)
+			return buf, nil
+		}
+		return nil, err
+	}
+	defer d.Close()
+
+	block := buf[:size]
+	if !isAligned(block) {
+		block = alignedBlock(size)
+		copy(block, buf)
+	}
+	if _, err = d.WriteAt(block, offset); err != nil {
+		if isDirectIOUnsupported(err) {
+			s.disableDirectIO(err)
+			return buf, nil
+		}
+		return nil, err
+	}
+	return buf[size:], nil
+}
+
+// disableDirectIO - falls back to writes through the page cache on the
+// disk, direct IO is unsupported by its filesystem.
+func (s *posix) disableDirectIO(err error) {
+	if atomic.CompareAndSwapInt32(&s.directIODisabled, 0, 1) {
+		errorIf(err, "Direct IO is unsupported on %s, writing through the page cache.", s.diskPath)
+	}
+}
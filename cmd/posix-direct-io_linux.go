@@ -0,0 +1,38 @@
+// +build linux
+
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package cmd
+
+import (
+	"os"
+	"syscall"
+)
+
+// openFileDirect - opens an existing file for writes bypassing the
+// page cache.
+func openFileDirect(filePath string) (*os.File, error) {
+	return os.OpenFile(filePath, os.O_WRONLY|syscall.O_DIRECT, 0666)
+}
+
+// isDirectIOUnsupported - returns true if err means the filesystem,
+// e.g tmpfs, doesn't support direct IO.
+func isDirectIOUnsupported(err error) bool {
+	if pathErr, ok := err.(*os.PathError); ok {
+		err = pathErr.Err
+	}
+	return err == syscall.EINVAL || err == errDirectIOUnsupported
+}
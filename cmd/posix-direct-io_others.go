@@ -0,0 +1,31 @@
+// +build !linux
+
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package cmd
+
+import "os"
+
+// openFileDirect - direct IO is only supported on linux.
+func openFileDirect(filePath string) (*os.File, error) {
+	return nil, errDirectIOUnsupported
+}
+
+// isDirectIOUnsupported - returns true if err means direct IO is
+// unsupported.
+func isDirectIOUnsupported(err error) bool {
+	return err == errDirectIOUnsupported
+}
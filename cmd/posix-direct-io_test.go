@@ -0,0 +1,134 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package cmd
+
+import (
+	"bytes"
+	"crypto/rand"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+// Tests large object data is appended with direct IO, the rest
+// through the page cache.
+func TestPosixAppendFileDirectIO(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("Direct IO is only supported on linux")
+	}
+	disk, diskPath, err := newPosixTestSetup()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer removeAll(diskPath)
+	if err = disk.MakeVol(minioMetaBucket); err != nil {
+		t.Fatal(err)
+	}
+	globalDirectIO = true
+	globalDirectIOMinSize = 2 * directIOAlignment
+	defer func() {
+		globalDirectIO = false
+		globalDirectIOMinSize = defaultDirectIOMinSize
+	}()
+
+	data := make([]byte, 10*directIOAlignment)
+	if _, err = rand.Read(data); err != nil {
+		t.Fatal(err)
+	}
+	filePath := filepath.Join(diskPath, minioMetaBucket, "tmp", "uuid", "part.1")
+	if err = os.MkdirAll(filepath.Dir(filePath), 0777); err != nil {
+		t.Fatal(err)
+	}
+	w, err := os.OpenFile(filePath, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0666)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+	s := disk.(*posix)
+
+	testCases := []struct {
+		buf  []byte
+		rest int
+	}{
+		// Below the minimum size.
+		{data[:directIOAlignment], directIOAlignment},
+		// Aligned offset, the unaligned end is left.
+		{data[directIOAlignment : 4*directIOAlignment+10], 10},
+		// Unaligned offset.
+		{data[4*directIOAlignment+10 : 6*directIOAlignment+10], 2 * directIOAlignment},
+	}
+	for i, testCase := range testCases {
+		rest, err := s.appendDirect(w, filePath, testCase.buf)
+		if err != nil {
+			t.Fatalf("Test %d: %v", i+1, err)
+		}
+		if len(rest) != testCase.rest {
+			t.Fatalf("Test %d: Expected %d bytes left, got %d", i+1, testCase.rest, len(rest))
+		}
+		if _, err = w.Write(rest); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if s.directIODisabled != 0 {
+		t.Fatal("Expected direct IO to be supported")
+	}
+
+	// Unaligned buffers are copied, appends through AppendFile.
+	if err = os.Truncate(filePath, 4*directIOAlignment); err != nil {
+		t.Fatal(err)
+	}
+	for _, buf := range [][]byte{data[4*directIOAlignment+1 : 8*directIOAlignment+1], data[8*directIOAlignment+1:]} {
+		if err = disk.AppendFile(minioMetaBucket, "tmp/uuid/part.1", buf); err != nil {
+			t.Fatal(err)
+		}
+	}
+	got, err := ioutil.ReadFile(filePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := append(append([]byte{}, data[:4*directIOAlignment]...), data[4*directIOAlignment+1:]...)
+	if !bytes.Equal(got, expected) {
+		t.Fatal("Contents of the file differ")
+	}
+
+	// Nothing is written with direct IO once disabled.
+	s.directIODisabled = 1
+	if rest, err := s.appendDirect(w, filePath, data); err != nil || len(rest) != len(data) {
+		t.Fatalf("Expected %d bytes left, got %d, %v", len(data), len(rest), err)
+	}
+}
+
+// Tests only object data files in the tmp directory use direct IO.
+func TestIsDirectIOFile(t *testing.T) {
+	testCases := []struct {
+		volume, path string
+		expected     bool
+	}{
+		{minioMetaBucket, "tmp/uuid/part.1", true},
+		{minioMetaBucket, "tmp/uuid", true},
+		{minioMetaBucket, "tmp/uuid/xl.json", false},
+		{minioMetaBucket, "tmp/uploadid.json", false},
+		{minioMetaBucket, "multipart/bucket/object/uploads.json", false},
+		{"bucket", "tmp/object", false},
+	}
+	for i, testCase := range testCases {
+		if got := isDirectIOFile(testCase.volume, testCase.path); got != testCase.expected {
+			t.Errorf("Test %d: Expected %v, got %v", i+1, testCase.expected, got)
+		}
+	}
+}
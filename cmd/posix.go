@@ -41,6 +41,7 @@ const (
 // posix - implements StorageAPI interface.
 type posix struct {
 	ioErrCount       int32 // ref: https://golang.org/pkg/sync/atomic/#pkg-note-BUG
+	directIODisabled int32 // set once direct IO turned out unsupported.
 	diskPath         string
 	suppliedDiskPath string
 	minFreeSpace     int64
@@ -360,6 +361,55 @@ func (s *posix) DeleteVol(volume string) (err error) {
 	return nil
 }
 
+// RenameVol - renames a volume, the destination volume must not exist.
+func (s *posix) RenameVol(srcVolume, dstVolume string) (err error) {
+	defer func() {
+		if err == syscall.EIO {
+			atomic.AddInt32(&s.ioErrCount, 1)
+		}
+	}()
+
+	if s.ioErrCount > maxAllowedIOError {
+		return errFaultyDisk
+	}
+
+	// Check disk availability.
+	if _, err = getDiskInfo(s.diskPath); err != nil {
+		return err
+	}
+
+	srcVolumeDir, err := s.getVolDir(srcVolume)
+	if err != nil {
+		return err
+	}
+	dstVolumeDir, err := s.getVolDir(dstVolume)
+	if err != nil {
+		return err
+	}
+	if _, err = os.Stat(preparePath(srcVolumeDir)); err != nil {
+		if os.IsNotExist(err) {
+			return errVolumeNotFound
+		}
+		return err
+	}
+	// os.Rename replaces empty directories, which must not happen
+	// to an existing volume.
+	if _, err = os.Stat(preparePath(dstVolumeDir)); err == nil {
+		return errVolumeExists
+	} else if !os.IsNotExist(err) {
+		return err
+	}
+	if err = os.Rename(preparePath(srcVolumeDir), preparePath(dstVolumeDir)); err != nil {
+		if os.IsNotExist(err) {
+			return errVolumeNotFound
+		} else if os.IsExist(err) || isSysErrNotEmpty(err) {
+			return errVolumeExists
+		}
+		return err
+	}
+	return nil
+}
+
 // ListDir - return all the entries at the given directory path.
 // If an entry is a directory it will be returned with a trailing "/".
 func (s *posix) ListDir(volume, dirPath string) (entries []string, err error) {
@@ -606,9 +656,23 @@ func (s *posix) AppendFile(volume, path string, buf []byte) (err error) {
 	// Close upon return.
 	defer w.Close()
 
+	// Write large object data bypassing the page cache, the rest is
+	// written through it.
+	if globalDirectIO && isDirectIOFile(volume, path) {
+		if buf, err = s.appendDirect(w, filePath, buf); err != nil {
+			return err
+		}
+	}
+
 	// Return io.Copy
-	_, err = io.Copy(w, bytes.NewReader(buf))
-	return err
+	if _, err = io.Copy(w, bytes.NewReader(buf)); err != nil {
+		return err
+	}
+	// Commit xl.json, and the data written before it, to the disks.
+	if globalFsyncBatcher != nil && slashpath.Base(path) == xlMetaJSONFile {
+		return globalFsyncBatcher.sync()
+	}
+	return nil
 }
 
 // StatFile - get file info.
@@ -835,5 +899,10 @@ func (s *posix) RenameFile(srcVolume, srcPath, dstVolume, dstPath string) (err e
 		}
 		return err
 	}
+	// Commit renames out of the tmp directory, which make new objects
+	// and parts visible, to the disks.
+	if globalFsyncBatcher != nil && !isTmpPath(dstVolume, dstPath) {
+		return globalFsyncBatcher.sync()
+	}
 	return nil
 }
@@ -483,6 +483,65 @@ func TestStatVol(t *testing.T) {
 	}
 }
 
+// TestRenameVol - Validates the error output for posix volume rename functionality posix.RenameVol().
+func TestRenameVol(t *testing.T) {
+	// create posix test setup
+	posixStorage, path, err := newPosixTestSetup()
+	if err != nil {
+		t.Fatalf("Unable to create posix test setup, %s", err)
+	}
+	defer removeAll(path)
+
+	// Setup test environment.
+	for _, volume := range []string{"src-vol", "existing-vol"} {
+		if err = posixStorage.MakeVol(volume); err != nil {
+			t.Fatalf("Unable to create volume, %s", err)
+		}
+	}
+	if err = posixStorage.AppendFile("src-vol", "file", []byte("hello")); err != nil {
+		t.Fatalf("Unable to create file, %s", err)
+	}
+
+	testCases := []struct {
+		srcVol      string
+		dstVol      string
+		ioErrCount  int
+		expectedErr error
+	}{
+		// Test case - 1.
+		// Rename onto an existing empty volume.
+		{"src-vol", "existing-vol", 0, errVolumeExists},
+		// Test case - 2.
+		{"nonexistent-vol", "dst-vol", 0, errVolumeNotFound},
+		// Test case - 3.
+		{"src-vol", "ab", 0, errInvalidArgument},
+		// Test case - 4.
+		{"src-vol", "dst-vol", 6, errFaultyDisk},
+		// Test case - 5.
+		{"src-vol", "dst-vol", 0, nil},
+	}
+
+	for i, testCase := range testCases {
+		if posixType, ok := posixStorage.(*posix); ok {
+			// setting the io error count from as specified in the test case.
+			posixType.ioErrCount = int32(testCase.ioErrCount)
+		} else {
+			t.Errorf("Expected the StorageAPI to be of type *posix")
+		}
+		if err = posixStorage.RenameVol(testCase.srcVol, testCase.dstVol); err != testCase.expectedErr {
+			t.Fatalf("Test case : %d, Expected: \"%s\", got: \"%s\"", i+1, testCase.expectedErr, err)
+		}
+	}
+
+	// Files are moved along with the volume.
+	if _, err = posixStorage.StatVol("src-vol"); err != errVolumeNotFound {
+		t.Errorf("Expected: \"%s\", got \"%s\"", errVolumeNotFound, err)
+	}
+	if buf, err := posixStorage.ReadAll("dst-vol", "file"); err != nil || string(buf) != "hello" {
+		t.Errorf("Expected renamed file content \"hello\", got \"%s\" (%v)", buf, err)
+	}
+}
+
 // TestListVols - Validates the result and the error output for posix volume listing functionality posix.ListVols().
 func TestListVols(t *testing.T) {
 	// create posix test setup
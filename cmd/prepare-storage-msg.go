@@ -17,6 +17,7 @@
 package cmd
 
 import (
+	"encoding/json"
 	"fmt"
 	"sync"
 
@@ -35,6 +36,46 @@ func int2Str(i int, t int) string {
 	return fmt.Sprintf("%d/%d", i, t)
 }
 
+// startupMsg - message printed on startup, as text or as a line of
+// JSON with 'minio server --json'.
+type startupMsg interface {
+	String() string
+	JSON() string
+}
+
+// Returns msg formatted as requested on the command line.
+func formatStartupMsg(msg startupMsg) string {
+	if globalJSON {
+		return msg.JSON()
+	}
+	return msg.String()
+}
+
+// Returns v marshalled into a line of JSON.
+func marshalStartupMsg(v interface{}) string {
+	data, err := json.Marshal(v)
+	fatalIf(err, "Unable to marshal startup message.")
+	return string(data)
+}
+
+// eraseLine - erases the current console line, unless printing JSON
+// which has to stay parsable.
+func eraseLine() {
+	if !globalJSON {
+		console.Eraseline()
+	}
+}
+
+// printWaitMsg - prints message while waiting for other servers, on
+// every retry.
+func printWaitMsg(message string) {
+	if globalJSON {
+		console.Println(storageStateMsg{Status: storageWait, Message: message, Disks: []diskStateMsg{}}.JSON())
+		return
+	}
+	console.Println(message)
+}
+
 // Print a given message once.
 type printOnceFunc func(msg string)
 
@@ -47,111 +88,153 @@ func printOnceFn() printOnceFunc {
 	}
 }
 
-// Prints custom message when healing is required for XL and Distributed XL backend.
-func printHealMsg(firstEndpoint string, storageDisks []StorageAPI, fn printOnceFunc) {
-	msg := getHealMsg(firstEndpoint, storageDisks)
-	fn(msg)
+// Status of the storage reported by storageStateMsg.
+const (
+	storageFormat      = "format"
+	storageInitialize  = "initialize"
+	storageHeal        = "heal"
+	storageConfigError = "configError"
+	storageWait        = "wait"
+)
+
+// diskStateMsg - state of a disk while preparing the storage.
+type diskStateMsg struct {
+	// Position of the disk, starting at 1.
+	Index int    `json:"index"`
+	Disk  string `json:"disk"`
+	Total int64  `json:"total"`
+	State string `json:"state"`
+	// Set for disks with configuration inconsistencies.
+	Error string `json:"error,omitempty"`
 }
 
-// Constructs a formatted heal message, when cluster is found to be in state where it requires healing.
-// healing is optional, server continues to initialize object layer after printing this message.
-// it is upto the end user to perform a heal if needed.
-func getHealMsg(firstEndpoint string, storageDisks []StorageAPI) string {
-	msg := fmt.Sprintln("\nData volume requires HEALING. Please run the following command:")
-	msg += "MINIO_ACCESS_KEY=%s "
-	msg += "MINIO_SECRET_KEY=%s "
-	msg += "minio control heal %s"
-	creds := serverConfig.GetCredential()
-	msg = fmt.Sprintf(msg, creds.AccessKeyID, creds.SecretAccessKey, firstEndpoint)
-	disksInfo, _, _ := getDisksInfo(storageDisks)
-	for i, info := range disksInfo {
-		if storageDisks[i] == nil {
+// storageStateMsg - message printed while preparing the storage, lists
+// the state of the disks.
+type storageStateMsg struct {
+	Status  string `json:"status"`
+	Message string `json:"message"`
+	// Command to heal the storage, set if status is heal.
+	HealCommand string         `json:"healCommand,omitempty"`
+	TotalDisks  int            `json:"totalDisks"`
+	Disks       []diskStateMsg `json:"disks"`
+}
+
+// String - formats the message for the console.
+func (m storageStateMsg) String() string {
+	var msg string
+	if m.Status == storageHeal {
+		msg = fmt.Sprintln("\n"+m.Message) + m.HealCommand
+	} else {
+		msg = colorBlue("\n" + m.Message)
+	}
+	for _, disk := range m.Disks {
+		if m.Status == storageConfigError {
+			msg += fmt.Sprintf("\n[%s] %s : %s", int2Str(disk.Index, m.TotalDisks), disk.Disk, disk.Error)
 			continue
 		}
 		msg += fmt.Sprintf(
 			"\n[%s] %s - %s %s",
-			int2Str(i+1, len(storageDisks)),
-			storageDisks[i],
-			humanize.IBytes(uint64(info.Total)),
-			func() string {
-				if info.Total > 0 {
-					return "online"
-				}
-				return "offline"
-			}(),
+			int2Str(disk.Index, m.TotalDisks),
+			disk.Disk,
+			humanize.IBytes(uint64(disk.Total)),
+			disk.State,
 		)
 	}
 	return msg
 }
 
-// Prints regular message when we have sufficient disks to start the cluster.
-func printRegularMsg(storageDisks []StorageAPI, fn printOnceFunc) {
-	msg := getRegularMsg(storageDisks)
-	fn(msg)
+// JSON - formats the message as a line of JSON.
+func (m storageStateMsg) JSON() string {
+	return marshalStartupMsg(m)
 }
 
-// Constructs a formatted regular message when we have sufficient disks to start the cluster.
-func getRegularMsg(storageDisks []StorageAPI) string {
-	msg := colorBlue("\nInitializing data volume.")
+// newStorageStateMsg - returns a message listing all the disks
+// along with their capacity and whether they are online.
+func newStorageStateMsg(status, message string, storageDisks []StorageAPI) storageStateMsg {
+	msg := storageStateMsg{
+		Status:     status,
+		Message:    message,
+		TotalDisks: len(storageDisks),
+		Disks:      []diskStateMsg{},
+	}
 	disksInfo, _, _ := getDisksInfo(storageDisks)
 	for i, info := range disksInfo {
 		if storageDisks[i] == nil {
 			continue
 		}
-		msg += fmt.Sprintf(
-			"\n[%s] %s - %s %s",
-			int2Str(i+1, len(storageDisks)),
-			storageDisks[i],
-			humanize.IBytes(uint64(info.Total)),
-			func() string {
-				if info.Total > 0 {
-					return "online"
-				}
-				return "offline"
-			}(),
-		)
+		state := "offline"
+		if info.Total > 0 {
+			state = "online"
+		}
+		msg.Disks = append(msg.Disks, diskStateMsg{
+			Index: i + 1,
+			Disk:  storageDisks[i].String(),
+			Total: info.Total,
+			State: state,
+		})
 	}
 	return msg
 }
 
+// Prints custom message when healing is required for XL and Distributed XL backend.
+func printHealMsg(firstEndpoint string, storageDisks []StorageAPI, fn printOnceFunc) {
+	fn(formatStartupMsg(newHealMsg(firstEndpoint, storageDisks)))
+}
+
+// Constructs a heal message, when cluster is found to be in state where it requires healing.
+// healing is optional, server continues to initialize object layer after printing this message.
+// it is upto the end user to perform a heal if needed.
+func newHealMsg(firstEndpoint string, storageDisks []StorageAPI) storageStateMsg {
+	msg := newStorageStateMsg(storageHeal, "Data volume requires HEALING. Please run the following command:", storageDisks)
+	// Print a token only allowed to heal instead of the credentials.
+	healToken, err := generateHealToken()
+	if err != nil {
+		errorIf(err, "Unable to generate heal token.")
+		msg.HealCommand = fmt.Sprintf("minio control heal %s", firstEndpoint)
+		return msg
+	}
+	msg.HealCommand = fmt.Sprintf("MINIO_HEAL_TOKEN=%s minio control heal %s", healToken, firstEndpoint)
+	return msg
+}
+
+// Constructs a formatted heal message.
+func getHealMsg(firstEndpoint string, storageDisks []StorageAPI) string {
+	return newHealMsg(firstEndpoint, storageDisks).String()
+}
+
+// Prints regular message when we have sufficient disks to start the cluster.
+func printRegularMsg(storageDisks []StorageAPI, fn printOnceFunc) {
+	fn(formatStartupMsg(newStorageStateMsg(storageInitialize, "Initializing data volume.", storageDisks)))
+}
+
+// Constructs a formatted regular message when we have sufficient disks to start the cluster.
+func getRegularMsg(storageDisks []StorageAPI) string {
+	return newStorageStateMsg(storageInitialize, "Initializing data volume.", storageDisks).String()
+}
+
 // Prints initialization message when cluster is being initialized for the first time.
 func printFormatMsg(storageDisks []StorageAPI, fn printOnceFunc) {
-	msg := getFormatMsg(storageDisks)
-	fn(msg)
+	fn(formatStartupMsg(newStorageStateMsg(storageFormat, "Initializing data volume for the first time.", storageDisks)))
 }
 
 // Generate a formatted message when cluster is being initialized for the first time.
 func getFormatMsg(storageDisks []StorageAPI) string {
-	msg := colorBlue("\nInitializing data volume for the first time.")
-	disksInfo, _, _ := getDisksInfo(storageDisks)
-	for i, info := range disksInfo {
-		if storageDisks[i] == nil {
-			continue
-		}
-		msg += fmt.Sprintf(
-			"\n[%s] %s - %s %s",
-			int2Str(i+1, len(storageDisks)),
-			storageDisks[i],
-			humanize.IBytes(uint64(info.Total)),
-			func() string {
-				if info.Total > 0 {
-					return "online"
-				}
-				return "offline"
-			}(),
-		)
-	}
-	return msg
+	return newStorageStateMsg(storageFormat, "Initializing data volume for the first time.", storageDisks).String()
 }
 
 func printConfigErrMsg(storageDisks []StorageAPI, sErrs []error, fn printOnceFunc) {
-	msg := getConfigErrMsg(storageDisks, sErrs)
-	fn(msg)
+	fn(formatStartupMsg(newConfigErrMsg(storageDisks, sErrs)))
 }
 
-// Generate a formatted message when cluster is misconfigured.
-func getConfigErrMsg(storageDisks []StorageAPI, sErrs []error) string {
-	msg := colorBlue("\nDetected configuration inconsistencies in the cluster. Please fix following servers.")
+// Constructs a message when cluster is misconfigured, lists only
+// the disks with configuration inconsistencies.
+func newConfigErrMsg(storageDisks []StorageAPI, sErrs []error) storageStateMsg {
+	msg := storageStateMsg{
+		Status:     storageConfigError,
+		Message:    "Detected configuration inconsistencies in the cluster. Please fix following servers.",
+		TotalDisks: len(storageDisks),
+		Disks:      []diskStateMsg{},
+	}
 	for i, disk := range storageDisks {
 		if disk == nil {
 			continue
@@ -159,12 +242,16 @@ func getConfigErrMsg(storageDisks []StorageAPI, sErrs []error) string {
 		if sErrs[i] == nil {
 			continue
 		}
-		msg += fmt.Sprintf(
-			"\n[%s] %s : %s",
-			int2Str(i+1, len(storageDisks)),
-			storageDisks[i],
-			sErrs[i],
-		)
+		msg.Disks = append(msg.Disks, diskStateMsg{
+			Index: i + 1,
+			Disk:  disk.String(),
+			Error: sErrs[i].Error(),
+		})
 	}
 	return msg
 }
+
+// Generate a formatted message when cluster is misconfigured.
+func getConfigErrMsg(storageDisks []StorageAPI, sErrs []error) string {
+	return newConfigErrMsg(storageDisks, sErrs).String()
+}
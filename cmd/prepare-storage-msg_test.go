@@ -16,7 +16,11 @@
 
 package cmd
 
-import "testing"
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
 
 // Tests heal message to be correct and properly formatted.
 func TestHealMsg(t *testing.T) {
@@ -61,6 +65,9 @@ func TestHealMsg(t *testing.T) {
 		if msg == "" {
 			t.Fatalf("Test: %d Unable to get heal message.", i+1)
 		}
+		if strings.Contains(msg, serverConfig.GetCredential().SecretAccessKey) {
+			t.Fatalf("Test: %d Heal message must not print the secret key.", i+1)
+		}
 		msg = getRegularMsg(testCase.storageDisks)
 		if msg == "" {
 			t.Fatalf("Test: %d Unable to get regular message.", i+1)
@@ -76,6 +83,60 @@ func TestHealMsg(t *testing.T) {
 	}
 }
 
+// Tests the heal message prints a token accepted by the heal handlers.
+func TestHealMsgToken(t *testing.T) {
+	rootPath, err := newTestConfig("us-east-1")
+	if err != nil {
+		t.Fatal("Unable to initialize test config", err)
+	}
+	defer removeAll(rootPath)
+	storageDisks, fsDirs := prepareXLStorageDisks(t)
+	defer removeRoots(fsDirs)
+
+	healCommand := newHealMsg("http://10.1.10.1:9000", storageDisks).HealCommand
+	fields := strings.Fields(healCommand)
+	if len(fields) == 0 || !strings.HasPrefix(fields[0], "MINIO_HEAL_TOKEN=") {
+		t.Fatalf("Expected heal command to set MINIO_HEAL_TOKEN, got %s", healCommand)
+	}
+	healToken := strings.TrimPrefix(fields[0], "MINIO_HEAL_TOKEN=")
+	if !isHealTokenValid(healToken) {
+		t.Fatal("Expected heal token to be accepted by the heal handlers")
+	}
+	if isRPCTokenValid(healToken, jwtAudienceAdmin) {
+		t.Fatal("Expected heal token to be rejected by the control handlers")
+	}
+}
+
+// Tests storage messages formatted as JSON.
+func TestStorageStateMsgJSON(t *testing.T) {
+	storageDisks, fsDirs := prepareXLStorageDisks(t)
+	defer removeRoots(fsDirs)
+	storageDisks[5] = nil
+
+	var msg storageStateMsg
+	if err := json.Unmarshal([]byte(newStorageStateMsg(storageFormat, "Initializing", storageDisks).JSON()), &msg); err != nil {
+		t.Fatal("Unable to decode format message", err)
+	}
+	if msg.Status != storageFormat || msg.TotalDisks != len(storageDisks) || len(msg.Disks) != len(storageDisks)-1 {
+		t.Fatalf("Unexpected format message %#v", msg)
+	}
+	for _, disk := range msg.Disks {
+		if disk.State != "online" || disk.Total <= 0 || disk.Index == 6 {
+			t.Fatalf("Unexpected disk state %#v", disk)
+		}
+	}
+
+	sErrs := make([]error, len(storageDisks))
+	sErrs[2] = errAuthentication
+	msg = storageStateMsg{}
+	if err := json.Unmarshal([]byte(newConfigErrMsg(storageDisks, sErrs).JSON()), &msg); err != nil {
+		t.Fatal("Unable to decode config error message", err)
+	}
+	if len(msg.Disks) != 1 || msg.Disks[0].Index != 3 || msg.Disks[0].Error != errAuthentication.Error() {
+		t.Fatalf("Unexpected config error message %#v", msg)
+	}
+}
+
 // Tests disk info, validates if we do return proper disk info structure
 // even in case of certain disks not available.
 func TestDisksInfo(t *testing.T) {
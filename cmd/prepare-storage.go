@@ -17,9 +17,9 @@
 package cmd
 
 import (
+	"fmt"
 	"time"
 
-	"github.com/minio/mc/pkg/console"
 	"github.com/minio/minio-go/pkg/set"
 )
 
@@ -206,11 +206,11 @@ func retryFormattingDisks(firstDisk bool, firstEndpoint string, storageDisks []S
 			case Abort:
 				return errCorruptedFormat
 			case FormatDisks:
-				console.Eraseline()
+				eraseLine()
 				printFormatMsg(storageDisks, printOnceFn())
 				return initFormatXL(storageDisks)
 			case InitObjectLayer:
-				console.Eraseline()
+				eraseLine()
 				// Validate formats load before proceeding forward.
 				err := genericFormatCheck(formatConfigs, sErrs)
 				if err == nil {
@@ -225,22 +225,22 @@ func retryFormattingDisks(firstDisk bool, firstEndpoint string, storageDisks []S
 				}
 				return err
 			case WaitForQuorum:
-				console.Printf(
-					"Initializing data volume. Waiting for minimum %d servers to come online.\n",
+				printWaitMsg(fmt.Sprintf(
+					"Initializing data volume. Waiting for minimum %d servers to come online.",
 					len(storageDisks)/2+1,
-				)
+				))
 			case WaitForConfig:
 				// Print configuration errors.
 				printConfigErrMsg(storageDisks, sErrs, printOnceFn())
 			case WaitForAll:
-				console.Println("Initializing data volume for first time. Waiting for other servers to come online.")
+				printWaitMsg("Initializing data volume for first time. Waiting for other servers to come online.")
 			case WaitForFormatting:
-				console.Println("Initializing data volume for first time. Waiting for first server to come online.")
+				printWaitMsg("Initializing data volume for first time. Waiting for first server to come online.")
 			}
 			continue
 		} // else We have FS backend now. Check fs format as well now.
 		if isFormatFound(formatConfigs) {
-			console.Eraseline()
+			eraseLine()
 			// Validate formats load before proceeding forward.
 			return genericFormatCheck(formatConfigs, sErrs)
 		} // else initialize the format for FS.
@@ -0,0 +1,138 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package cmd
+
+// RenameBucketArgs - argument for RenameBucket RPC handler.
+type RenameBucketArgs struct {
+	// Authentication token generated by Login.
+	GenericArgs
+
+	// Bucket to rename.
+	SrcBucket string
+
+	// New name of the bucket.
+	DstBucket string
+}
+
+// RenameBucketHandler - RPC control handler for `minio control
+// rename-bucket`, renames a bucket in place without copying its
+// objects.
+func (c *controlAPIHandlers) RenameBucketHandler(args *RenameBucketArgs, reply *GenericReply) (err error) {
+	defer encodeRPCError(&err)
+
+	if !isRPCTokenValid(args.Token, jwtAudienceAdmin) {
+		return errInvalidToken
+	}
+	// Retries get the result of the first call.
+	if replayed, replayErr := globalIdempotentCalls.Begin("Control.RenameBucketHandler", args.IdempotencyKey, reply); replayed {
+		return replayErr
+	}
+	defer globalIdempotentCalls.End("Control.RenameBucketHandler", args.IdempotencyKey, reply, &err)
+
+	objAPI := c.ObjectAPI()
+	if objAPI == nil {
+		return errServerNotInitialized
+	}
+	return renameBucket(objAPI, args.SrcBucket, args.DstBucket)
+}
+
+// renameBucket - renames srcBucket to dstBucket along with its
+// policy, notification and listener configs and settings, which are
+// reloaded on all the nodes. Objects are notified as removed from
+// srcBucket and created in dstBucket.
+func renameBucket(objAPI ObjectLayer, srcBucket, dstBucket string) error {
+	// Claim the new bucket name in the federation of clusters, if any.
+	claimed, err := claimFederatedBucket(dstBucket)
+	if err != nil {
+		return err
+	}
+	if err = objAPI.RenameBucket(srcBucket, dstBucket); err != nil {
+		if claimed {
+			releaseFederatedBucket(dstBucket)
+		}
+		return err
+	}
+	releaseFederatedBucket(srcBucket)
+
+	// Notifications for the old name are sent while its configs are
+	// still loaded.
+	notifyRenamedObjects(objAPI, srcBucket, dstBucket, ObjectRemovedDelete)
+	reloadRenamedBucket(objAPI, srcBucket, dstBucket)
+	notifyRenamedObjects(objAPI, dstBucket, dstBucket, ObjectCreatedCopy)
+	return nil
+}
+
+// reloadRenamedBucket - moves the in-memory state of srcBucket to
+// dstBucket on all the nodes, from the renamed metadata of dstBucket.
+func reloadRenamedBucket(objAPI ObjectLayer, srcBucket, dstBucket string) {
+	if policy, err := readBucketPolicy(dstBucket, objAPI); err == nil {
+		S3PeersUpdateBucketPolicy(dstBucket, policyChange{
+			BktPolicy: policy,
+			Version:   globalBucketPolicies.NextBucketPolicyVersion(dstBucket),
+		})
+		S3PeersUpdateBucketPolicy(srcBucket, policyChange{
+			IsRemove: true,
+			Version:  globalBucketPolicies.NextBucketPolicyVersion(srcBucket),
+		})
+	}
+	if ncfg, err := loadNotificationConfig(dstBucket, objAPI); err == nil {
+		S3PeersUpdateBucketNotification(dstBucket, ncfg)
+		S3PeersUpdateBucketNotification(srcBucket, nil)
+	}
+	if lcfg, err := loadListenerConfig(dstBucket, objAPI); err == nil {
+		S3PeersUpdateBucketListener(dstBucket, lcfg)
+		S3PeersUpdateBucketListener(srcBucket, nil)
+	}
+	if settings, err := readBucketSettings(dstBucket, objAPI); err == nil {
+		S3PeersUpdateBucketSettings(dstBucket, settings)
+		S3PeersUpdateBucketSettings(srcBucket, nil)
+	}
+
+	// Forget recent events and event statistics of the old name.
+	globalRecentEvents.Remove(srcBucket)
+	globalEventStats.Remove(srcBucket)
+}
+
+// notifyRenamedObjects - sends an event of type for all the objects
+// of the renamed bucket as objects of eventBucket, if eventBucket has
+// notifications or listeners configured.
+func notifyRenamedObjects(objAPI ObjectLayer, bucket, eventBucket string, eventType EventName) {
+	if globalEventNotifier.GetBucketNotificationConfig(eventBucket) == nil &&
+		len(globalEventNotifier.GetBucketListenerConfig(eventBucket)) == 0 {
+		return
+	}
+	marker := ""
+	for {
+		result, err := objAPI.ListObjects(bucket, "", marker, "", maxObjectList)
+		if err != nil {
+			errorIf(err, "Unable to list objects of renamed bucket %s.", bucket)
+			return
+		}
+		for _, objInfo := range result.Objects {
+			objInfo.Bucket = eventBucket
+			eventNotify(eventData{
+				Type:      eventType,
+				Bucket:    eventBucket,
+				ObjInfo:   objInfo,
+				ReqParams: map[string]string{},
+			})
+		}
+		if !result.IsTruncated {
+			return
+		}
+		marker = result.NextMarker
+	}
+}
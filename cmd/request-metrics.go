@@ -0,0 +1,161 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package cmd
+
+import (
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// metricsWindow - counters of the requests served by this node in a
+// window of time, evaluated by alert rules.
+type metricsWindow struct {
+	Start time.Time
+	End   time.Time
+
+	// Requests served, and those answered with a 5xx status.
+	Requests     int64
+	ServerErrors int64
+
+	// Failed authentications, of signed requests and browser logins.
+	AuthFailures int64
+
+	// Bytes sent by successful requests, per bucket.
+	Egress map[string]int64
+}
+
+// requestMetrics - counters of the current window.
+type requestMetrics struct {
+	mutex   *sync.Mutex
+	current metricsWindow
+}
+
+// Variable holding the request metrics of this node.
+var globalRequestMetrics = newRequestMetrics()
+
+// newRequestMetrics - returns request metrics with a window starting now.
+func newRequestMetrics() *requestMetrics {
+	return &requestMetrics{
+		mutex:   &sync.Mutex{},
+		current: metricsWindow{Start: time.Now().UTC(), Egress: make(map[string]int64)},
+	}
+}
+
+// Served records a request answered with status, sent bytes are
+// accounted to bucket if set.
+func (m *requestMetrics) Served(bucket string, status int, sent int64) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.current.Requests++
+	if status >= 500 {
+		m.current.ServerErrors++
+	}
+	// Only successful requests are accounted, to only keep existing buckets.
+	if bucket != "" && status < 300 && sent > 0 {
+		m.current.Egress[bucket] += sent
+	}
+}
+
+// AuthFailed records a failed authentication.
+func (m *requestMetrics) AuthFailed() {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.current.AuthFailures++
+}
+
+// Rotate ends the current window at now and returns it, a new window
+// starts.
+func (m *requestMetrics) Rotate(now time.Time) metricsWindow {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	window := m.current
+	window.End = now.UTC()
+	m.current = metricsWindow{Start: window.End, Egress: make(map[string]int64)}
+	return window
+}
+
+// metricsResponseWriter - records the status and the number of bytes
+// of a response.
+type metricsResponseWriter struct {
+	http.ResponseWriter
+	status int
+	sent   int64
+}
+
+func (w *metricsResponseWriter) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *metricsResponseWriter) Write(p []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	n, err := w.ResponseWriter.Write(p)
+	w.sent += int64(n)
+	return n, err
+}
+
+// Flush - streaming responses, e.g of listen bucket notification,
+// are flushed as they are written.
+func (w *metricsResponseWriter) Flush() {
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Records metrics of all the S3 API requests and browser downloads.
+type requestMetricsHandler struct {
+	handler http.Handler
+}
+
+func setRequestMetricsHandler(h http.Handler) http.Handler {
+	return requestMetricsHandler{handler: h}
+}
+
+// Returns the bucket the sent bytes of a request are accounted to,
+// and false for internal RPC and browser requests which are not
+// recorded at all.
+func getMetricsBucket(r *http.Request) (string, bool) {
+	downloadPrefix := globalBrowserPrefix + "/download/"
+	if strings.HasPrefix(r.URL.Path, downloadPrefix) {
+		bucket, _ := urlPathSplit(strings.TrimPrefix(r.URL.Path, downloadPrefix))
+		return bucket, true
+	}
+	if strings.HasPrefix(r.URL.Path, reservedBucket+"/") || strings.HasPrefix(r.URL.Path, globalBrowserPrefix+"/") {
+		return "", false
+	}
+	bucket, _ := urlPathSplit(r.URL.Path)
+	return bucket, true
+}
+
+func (h requestMetricsHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	bucket, ok := getMetricsBucket(r)
+	if !ok {
+		h.handler.ServeHTTP(w, r)
+		return
+	}
+	mw := &metricsResponseWriter{ResponseWriter: w}
+	h.handler.ServeHTTP(mw, r)
+	if mw.status == 0 {
+		mw.status = http.StatusOK
+	}
+	globalRequestMetrics.Served(bucket, mw.status, mw.sent)
+}
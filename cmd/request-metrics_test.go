@@ -0,0 +1,76 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package cmd
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// Tests requests are recorded with their status and the bytes sent.
+func TestRequestMetricsHandler(t *testing.T) {
+	globalRequestMetrics = newRequestMetrics()
+	defer func() { globalRequestMetrics = newRequestMetrics() }()
+
+	handler := setRequestMetricsHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/bucket/object":
+			w.Write([]byte("hello"))
+		case "/bucket/missing":
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte("not found"))
+		case "/bucket/broken":
+			w.WriteHeader(http.StatusInternalServerError)
+		case globalBrowserPrefix + "/download/photos/image.png":
+			w.Write([]byte("image"))
+		}
+		w.(http.Flusher).Flush()
+	}))
+	for _, path := range []string{
+		"/bucket/object",
+		"/bucket/object",
+		"/bucket/missing",
+		"/bucket/broken",
+		globalBrowserPrefix + "/download/photos/image.png",
+		// Internal RPC requests are not recorded.
+		reservedBucket + "/controller",
+	} {
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", path, nil))
+	}
+
+	now := time.Now()
+	window := globalRequestMetrics.Rotate(now)
+	if window.Requests != 5 || window.ServerErrors != 1 {
+		t.Errorf("Expected 5 requests and 1 server error, got %d and %d", window.Requests, window.ServerErrors)
+	}
+	expectedEgress := map[string]int64{"bucket": 10, "photos": 5}
+	if !reflect.DeepEqual(window.Egress, expectedEgress) {
+		t.Errorf("Expected egress %v, got %v", expectedEgress, window.Egress)
+	}
+
+	// Next window starts empty.
+	globalRequestMetrics.AuthFailed()
+	window = globalRequestMetrics.Rotate(now.Add(time.Minute))
+	if window.Requests != 0 || window.AuthFailures != 1 || len(window.Egress) != 0 {
+		t.Errorf("Unexpected window %+v", window)
+	}
+	if !window.Start.Equal(now.UTC()) {
+		t.Errorf("Expected window to start at %s, got %s", now.UTC(), window.Start)
+	}
+}
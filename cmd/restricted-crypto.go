@@ -0,0 +1,116 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"crypto/md5"
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/hex"
+	"hash"
+)
+
+// TLS cipher suites allowed in restricted crypto mode, only ECDHE key
+// exchanges with AES-GCM.
+var restrictedCipherSuites = []uint16{
+	tls.TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384,
+	tls.TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384,
+	tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256,
+	tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256,
+}
+
+// Elliptic curves allowed in restricted crypto mode.
+var restrictedCurves = []tls.CurveID{
+	tls.CurveP384,
+	tls.CurveP256,
+}
+
+// newTLSConfig - returns a TLS config for the server and for RPC
+// clients, restricted to TLS 1.2 and restrictedCipherSuites in
+// restricted crypto mode.
+func newTLSConfig() *tls.Config {
+	config := &tls.Config{}
+	if globalRestrictedCrypto {
+		config.MinVersion = tls.VersionTLS12
+		config.CipherSuites = restrictedCipherSuites
+		config.CurvePreferences = restrictedCurves
+		config.PreferServerCipherSuites = true
+	}
+	return config
+}
+
+// newETagHash - returns the hash computing ETags, MD5 or SHA-256 in
+// restricted crypto mode.
+func newETagHash() hash.Hash {
+	if globalRestrictedCrypto {
+		return sha256.New()
+	}
+	return md5.New()
+}
+
+// etagWriter - hashes object data into its ETag and its MD5 sum, used
+// to verify the Content-MD5 sent by clients. Both are the same MD5 sum
+// unless in restricted crypto mode, where the MD5 sum is only computed
+// if the client sent a Content-MD5.
+type etagWriter struct {
+	etag hash.Hash
+	md5  hash.Hash
+}
+
+// newETagWriter - returns an etagWriter, md5Hex is the Content-MD5 sent
+// by the client if any.
+func newETagWriter(md5Hex string) *etagWriter {
+	if !globalRestrictedCrypto {
+		md5Hash := md5.New()
+		return &etagWriter{etag: md5Hash, md5: md5Hash}
+	}
+	w := &etagWriter{etag: sha256.New()}
+	if md5Hex != "" {
+		w.md5 = md5.New()
+	}
+	return w
+}
+
+// Write - writes p to both hashes.
+func (w *etagWriter) Write(p []byte) (int, error) {
+	w.etag.Write(p)
+	if w.md5 != nil && w.md5 != w.etag {
+		w.md5.Write(p)
+	}
+	return len(p), nil
+}
+
+// ETag - returns the hex encoded ETag.
+func (w *etagWriter) ETag() string {
+	return hex.EncodeToString(w.etag.Sum(nil))
+}
+
+// MD5 - returns the hex encoded MD5 sum, empty if not computed.
+func (w *etagWriter) MD5() string {
+	if w.md5 == nil {
+		return ""
+	}
+	return hex.EncodeToString(w.md5.Sum(nil))
+}
+
+// getCryptoPosture - describes the crypto mode for ServerInfo.
+func getCryptoPosture() string {
+	if globalRestrictedCrypto {
+		return "Mode: restricted | TLS: 1.2+ ECDHE AES-GCM | ETag: SHA-256"
+	}
+	return "Mode: standard | TLS: Go defaults | ETag: MD5"
+}
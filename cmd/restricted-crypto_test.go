@@ -0,0 +1,135 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"bytes"
+	"crypto/md5"
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/hex"
+	"testing"
+)
+
+// Tests TLS configs in standard and restricted crypto mode.
+func TestNewTLSConfig(t *testing.T) {
+	defer func(restricted bool) { globalRestrictedCrypto = restricted }(globalRestrictedCrypto)
+
+	globalRestrictedCrypto = false
+	config := newTLSConfig()
+	if config.MinVersion != 0 || config.CipherSuites != nil {
+		t.Errorf("Expected Go default TLS config, got %#v", config)
+	}
+
+	globalRestrictedCrypto = true
+	config = newTLSConfig()
+	if config.MinVersion != tls.VersionTLS12 {
+		t.Errorf("Expected minimum version TLS 1.2, got %x", config.MinVersion)
+	}
+	if len(config.CipherSuites) != len(restrictedCipherSuites) {
+		t.Errorf("Expected cipher suites %v, got %v", restrictedCipherSuites, config.CipherSuites)
+	}
+}
+
+// Tests ETags and md5sums computed by etagWriter.
+func TestETagWriter(t *testing.T) {
+	defer func(restricted bool) { globalRestrictedCrypto = restricted }(globalRestrictedCrypto)
+
+	data := []byte("Hello, World")
+	md5Sum := md5.Sum(data)
+	md5Hex := hex.EncodeToString(md5Sum[:])
+	sha256Sum := sha256.Sum256(data)
+	sha256Hex := hex.EncodeToString(sha256Sum[:])
+
+	testCases := []struct {
+		restricted bool
+		md5Hex     string
+		etag       string
+		md5        string
+	}{
+		{false, "", md5Hex, md5Hex},
+		{false, md5Hex, md5Hex, md5Hex},
+		// MD5 is only computed for Content-MD5 in restricted mode.
+		{true, "", sha256Hex, ""},
+		{true, md5Hex, sha256Hex, md5Hex},
+	}
+	for i, testCase := range testCases {
+		globalRestrictedCrypto = testCase.restricted
+		w := newETagWriter(testCase.md5Hex)
+		w.Write(data)
+		if etag := w.ETag(); etag != testCase.etag {
+			t.Errorf("Test %d: Expected ETag %s, got %s", i+1, testCase.etag, etag)
+		}
+		if md5 := w.MD5(); md5 != testCase.md5 {
+			t.Errorf("Test %d: Expected md5sum %s, got %s", i+1, testCase.md5, md5)
+		}
+	}
+}
+
+// Wrapper for calling PutObject tests in restricted crypto mode for
+// both XL and FS.
+func TestPutObjectRestrictedCrypto(t *testing.T) {
+	ExecObjectLayerTest(t, testPutObjectRestrictedCrypto)
+}
+
+func testPutObjectRestrictedCrypto(obj ObjectLayer, instanceType string, t TestErrHandler) {
+	defer func(restricted bool) { globalRestrictedCrypto = restricted }(globalRestrictedCrypto)
+	globalRestrictedCrypto = true
+
+	bucket := "bucket"
+	if err := obj.MakeBucket(bucket); err != nil {
+		t.Fatalf("%s: %s", instanceType, err)
+	}
+
+	data := []byte("Hello, World")
+	md5Sum := md5.Sum(data)
+	sha256Sum := sha256.Sum256(data)
+	sha256Hex := hex.EncodeToString(sha256Sum[:])
+
+	// Content-MD5 is still verified.
+	_, err := obj.PutObject(bucket, "object", int64(len(data)), bytes.NewReader(data), map[string]string{"md5Sum": sha256Hex}, "")
+	if _, ok := errorCause(err).(BadDigest); !ok {
+		t.Fatalf("%s: Expected BadDigest, got %v", instanceType, err)
+	}
+
+	objInfo, err := obj.PutObject(bucket, "object", int64(len(data)), bytes.NewReader(data), map[string]string{"md5Sum": hex.EncodeToString(md5Sum[:])}, "")
+	if err != nil {
+		t.Fatalf("%s: %s", instanceType, err)
+	}
+	if objInfo.MD5Sum != sha256Hex {
+		t.Errorf("%s: Expected ETag %s, got %s", instanceType, sha256Hex, objInfo.MD5Sum)
+	}
+
+	uploadID, err := obj.NewMultipartUpload(bucket, "multipart", nil)
+	if err != nil {
+		t.Fatalf("%s: %s", instanceType, err)
+	}
+	partETag, err := obj.PutObjectPart(bucket, "multipart", uploadID, 1, int64(len(data)), bytes.NewReader(data), "", "")
+	if err != nil {
+		t.Fatalf("%s: %s", instanceType, err)
+	}
+	if partETag != sha256Hex {
+		t.Errorf("%s: Expected part ETag %s, got %s", instanceType, sha256Hex, partETag)
+	}
+	etag, err := obj.CompleteMultipartUpload(bucket, "multipart", uploadID, []completePart{{PartNumber: 1, ETag: partETag}})
+	if err != nil {
+		t.Fatalf("%s: %s", instanceType, err)
+	}
+	if !isMultipartETag(etag) || len(etag) != sha256.Size*2+2 {
+		t.Errorf("%s: Expected SHA-256 multipart ETag, got %s", instanceType, etag)
+	}
+}
@@ -0,0 +1,304 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package cmd
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+
+	mux "github.com/gorilla/mux"
+)
+
+// resumableUploadResponse - response of NewResumableUpload, chunks of
+// the object are PUT to Location.
+type resumableUploadResponse struct {
+	Bucket    string `json:"bucket"`
+	Key       string `json:"key"`
+	SessionID string `json:"sessionId"`
+	Location  string `json:"location"`
+}
+
+// checkResumableUploadAuth - validates requests managing resumable
+// uploads, anonymous requests need the s3:PutObject bucket policy.
+// Requests uploading chunks verify their signature along with the
+// chunk.
+func checkResumableUploadAuth(r *http.Request, bucket string) APIErrorCode {
+	switch getRequestAuthType(r) {
+	case authTypeAnonymous:
+		return enforceBucketPolicy(bucket, "s3:PutObject", r.URL)
+	case authTypePresignedV2, authTypeSignedV2:
+		return isReqAuthenticatedV2(r)
+	case authTypePresigned, authTypeSigned:
+		return isReqAuthenticated(r, serverConfig.GetRegion())
+	}
+	// For all unknown auth types return error.
+	return ErrAccessDenied
+}
+
+// setResumableUploadHeaders - reports the data uploaded so far in a
+// Range header, and the size of the object once known.
+func setResumableUploadHeaders(w http.ResponseWriter, session *resumableSession) {
+	if offset := session.offset(); offset > 0 {
+		w.Header().Set("Range", "bytes=0-"+strconv.FormatInt(offset-1, 10))
+	}
+	if session.Size != -1 {
+		w.Header().Set(resumableLengthHeader, strconv.FormatInt(session.Size, 10))
+	}
+}
+
+// NewResumableUploadHandler - POST Object resumable (minio extension)
+// -----------------
+// This operation uses the resumable subresource to start a resumable
+// upload of an object, its chunks are then PUT to the returned
+// location with a Content-Range each.
+func (api objectAPIHandlers) NewResumableUploadHandler(w http.ResponseWriter, r *http.Request) {
+	objAPI := api.ObjectAPI()
+	if objAPI == nil {
+		writeErrorResponse(w, r, ErrServerNotInitialized, r.URL.Path)
+		return
+	}
+
+	vars := mux.Vars(r)
+	bucket := vars["bucket"]
+	object := vars["object"]
+
+	if s3Error := checkResumableUploadAuth(r, bucket); s3Error != ErrNone {
+		writeErrorResponse(w, r, s3Error, r.URL.Path)
+		return
+	}
+
+	if err := isBucketExist(bucket, objAPI); err != nil {
+		writeErrorResponse(w, r, toAPIErrorCode(err), r.URL.Path)
+		return
+	}
+	if !IsValidObjectName(object) {
+		writeErrorResponse(w, r, ErrInvalidObjectName, r.URL.Path)
+		return
+	}
+
+	// Extract metadata to be saved from incoming HTTP header.
+	metadata := extractMetadataFromHeader(r.Header)
+	// Apply bucket defaults for any metadata not provided.
+	applyBucketDefaults(bucket, object, metadata)
+
+	sessionID, err := newResumableUpload(objAPI, bucket, object, metadata)
+	if err != nil {
+		errorIf(err, "Unable to start resumable upload of %s.", object)
+		writeErrorResponse(w, r, toAPIErrorCode(err), r.URL.Path)
+		return
+	}
+
+	response := resumableUploadResponse{
+		Bucket:    bucket,
+		Key:       object,
+		SessionID: sessionID,
+		Location:  getObjectLocation(bucket, object) + "?resumable=" + sessionID,
+	}
+	responseBytes, err := json.Marshal(response)
+	if err != nil {
+		errorIf(err, "Unable to marshal resumable upload response.")
+		writeErrorResponse(w, r, ErrInternalError, r.URL.Path)
+		return
+	}
+	w.Header().Set("Location", response.Location)
+	w.Header().Set("Content-Type", "application/json")
+	writeSuccessResponse(w, responseBytes)
+}
+
+// PutResumableUploadHandler - PUT Object resumable (minio extension)
+// -----------------
+// This operation appends a chunk to a resumable upload, the chunk has
+// to start at the end of the data uploaded so far as reported by the
+// Range header. `Content-Range: bytes */size` without a body only sets
+// the size of the object. The object is created once all of its data
+// was uploaded, incomplete uploads are answered with 202 Accepted.
+func (api objectAPIHandlers) PutResumableUploadHandler(w http.ResponseWriter, r *http.Request) {
+	objAPI := api.ObjectAPI()
+	if objAPI == nil {
+		writeErrorResponse(w, r, ErrServerNotInitialized, r.URL.Path)
+		return
+	}
+
+	vars := mux.Vars(r)
+	bucket := vars["bucket"]
+	object := vars["object"]
+	sessionID := vars["resumable"]
+
+	cr, err := parseContentRange(r.Header.Get("Content-Range"))
+	if err != nil {
+		writeErrorResponse(w, r, toAPIErrorCode(err), r.URL.Path)
+		return
+	}
+	if isMaxObjectSize(cr.size) {
+		writeErrorResponse(w, r, ErrEntityTooLarge, r.URL.Path)
+		return
+	}
+
+	/// Chunks have to be sent whole, with the length of their range.
+	size := r.ContentLength
+	rAuthType := getRequestAuthType(r)
+	if rAuthType == authTypeStreamingSigned {
+		sizeStr := r.Header.Get("x-amz-decoded-content-length")
+		size, err = strconv.ParseInt(sizeStr, 10, 64)
+		if err != nil {
+			errorIf(err, "Unable to parse `x-amz-decoded-content-length` into its integer value", sizeStr)
+			writeErrorResponse(w, r, toAPIErrorCode(err), r.URL.Path)
+			return
+		}
+	}
+	if size == -1 {
+		writeErrorResponse(w, r, ErrMissingContentLength, r.URL.Path)
+		return
+	}
+	if size != cr.length() {
+		writeErrorResponse(w, r, ErrInvalidContentRange, r.URL.Path)
+		return
+	}
+
+	// Reject early if the chunk would fill up the disks.
+	if err = globalDiskUsage.admit(size); err != nil {
+		writeErrorResponse(w, r, ErrStorageFull, r.URL.Path)
+		return
+	}
+
+	sha256sum := ""
+	var reader io.Reader = r.Body
+	switch rAuthType {
+	default:
+		// For all unknown auth types return error.
+		writeErrorResponse(w, r, ErrAccessDenied, r.URL.Path)
+		return
+	case authTypeAnonymous:
+		if s3Error := enforceBucketPolicy(bucket, "s3:PutObject", r.URL); s3Error != ErrNone {
+			writeErrorResponse(w, r, s3Error, r.URL.Path)
+			return
+		}
+	case authTypeStreamingSigned:
+		// Initialize stream signature verifier.
+		var s3Error APIErrorCode
+		reader, s3Error = newSignV4ChunkedReader(r)
+		if s3Error != ErrNone {
+			errorIf(errSignatureMismatch, dumpRequest(r))
+			writeErrorResponse(w, r, s3Error, r.URL.Path)
+			return
+		}
+	case authTypeSignedV2, authTypePresignedV2:
+		if s3Error := isReqAuthenticatedV2(r); s3Error != ErrNone {
+			errorIf(errSignatureMismatch, dumpRequest(r))
+			writeErrorResponse(w, r, s3Error, r.URL.Path)
+			return
+		}
+	case authTypePresigned, authTypeSigned:
+		if s3Error := reqSignatureV4Verify(r); s3Error != ErrNone {
+			errorIf(errSignatureMismatch, dumpRequest(r))
+			writeErrorResponse(w, r, s3Error, r.URL.Path)
+			return
+		}
+		if !skipContentSha256Cksum(r) {
+			sha256sum = r.Header.Get("X-Amz-Content-Sha256")
+		}
+	}
+
+	session, objInfo, err := appendResumableUpload(objAPI, bucket, object, sessionID, cr, reader, sha256sum)
+	if err != nil {
+		if session != nil {
+			// Tell the client where to resume from.
+			setResumableUploadHeaders(w, session)
+		}
+		errorIf(err, "Unable to append to resumable upload of %s.", object)
+		writeErrorResponse(w, r, toAPIErrorCode(err), r.URL.Path)
+		return
+	}
+	setResumableUploadHeaders(w, session)
+	if !session.isComplete() {
+		setCommonHeaders(w)
+		w.WriteHeader(http.StatusAccepted)
+		return
+	}
+	w.Header().Set("ETag", "\""+objInfo.MD5Sum+"\"")
+	writeSuccessResponse(w, nil)
+
+	// Notify object created event.
+	eventNotify(eventData{
+		Type:    ObjectCreatedPut,
+		Bucket:  bucket,
+		ObjInfo: objInfo,
+		ReqParams: map[string]string{
+			"sourceIPAddress": r.RemoteAddr,
+		},
+	})
+}
+
+// HeadResumableUploadHandler - HEAD Object resumable (minio extension)
+// -----------------
+// This operation reports the data uploaded so far to a resumable
+// upload in the Range header, for clients to resume from.
+func (api objectAPIHandlers) HeadResumableUploadHandler(w http.ResponseWriter, r *http.Request) {
+	objAPI := api.ObjectAPI()
+	if objAPI == nil {
+		writeErrorResponse(w, r, ErrServerNotInitialized, r.URL.Path)
+		return
+	}
+
+	vars := mux.Vars(r)
+	bucket := vars["bucket"]
+	object := vars["object"]
+	sessionID := vars["resumable"]
+
+	if s3Error := checkResumableUploadAuth(r, bucket); s3Error != ErrNone {
+		writeErrorResponse(w, r, s3Error, r.URL.Path)
+		return
+	}
+
+	session, err := readResumableSession(objAPI, bucket, object, sessionID)
+	if err != nil {
+		errorIf(err, "Unable to read resumable upload of %s.", object)
+		writeErrorResponse(w, r, toAPIErrorCode(err), r.URL.Path)
+		return
+	}
+	setResumableUploadHeaders(w, session)
+	writeSuccessResponse(w, nil)
+}
+
+// AbortResumableUploadHandler - DELETE Object resumable (minio extension)
+// -----------------
+// This operation aborts a resumable upload, removing its chunks.
+func (api objectAPIHandlers) AbortResumableUploadHandler(w http.ResponseWriter, r *http.Request) {
+	objAPI := api.ObjectAPI()
+	if objAPI == nil {
+		writeErrorResponse(w, r, ErrServerNotInitialized, r.URL.Path)
+		return
+	}
+
+	vars := mux.Vars(r)
+	bucket := vars["bucket"]
+	object := vars["object"]
+	sessionID := vars["resumable"]
+
+	if s3Error := checkResumableUploadAuth(r, bucket); s3Error != ErrNone {
+		writeErrorResponse(w, r, s3Error, r.URL.Path)
+		return
+	}
+
+	if err := abortResumableUpload(objAPI, bucket, object, sessionID); err != nil {
+		errorIf(err, "Unable to abort resumable upload of %s.", object)
+		writeErrorResponse(w, r, toAPIErrorCode(err), r.URL.Path)
+		return
+	}
+	writeSuccessNoContent(w)
+}
@@ -0,0 +1,372 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/skyrings/skyring-common/tools/uuid"
+)
+
+// Resumable uploads (minio extension) create a single object from
+// chunks PUT with a Content-Range to the session of the upload. Chunks
+// can be of any size and are kept in the meta bucket, under
+// .minio.sys/resumable/<bucket>/<session>/, until the object is
+// assembled from them once all of its data was uploaded.
+const (
+	resumablePrefix      = "resumable"
+	resumableSessionFile = "session.json"
+
+	// Sessions without any upload for this long are purged.
+	resumableSessionExpiry = 24 * time.Hour
+	resumablePurgeInterval = time.Hour
+
+	// Header reporting the total size of the object, once known.
+	resumableLengthHeader = "X-Minio-Upload-Length"
+)
+
+// errInvalidContentRange - Content-Range of a chunk is missing or
+// does not match its data.
+var errInvalidContentRange = errors.New("Content-Range is missing or invalid")
+
+// errResumableOffsetMismatch - chunk does not start at the end of the
+// data uploaded so far.
+var errResumableOffsetMismatch = errors.New("Content-Range does not start at the end of the uploaded data")
+
+// resumableSession - state of a resumable upload.
+type resumableSession struct {
+	Object   string            `json:"object"`
+	Metadata map[string]string `json:"metadata"`
+	Size     int64             `json:"size"`   // Total size of the object, -1 until known.
+	Chunks   []int64           `json:"chunks"` // Sizes of the uploaded chunks.
+}
+
+// offset - returns the number of bytes uploaded so far.
+func (s *resumableSession) offset() (offset int64) {
+	for _, size := range s.Chunks {
+		offset += size
+	}
+	return offset
+}
+
+// isComplete - returns true once all the data of the object was
+// uploaded.
+func (s *resumableSession) isComplete() bool {
+	return s.Size != -1 && s.offset() == s.Size
+}
+
+// Sessions are kept in the meta bucket, they are locked at their path
+// while chunks are appended.
+func resumableSessionPath(bucket, sessionID string) string {
+	return path.Join(resumablePrefix, bucket, sessionID)
+}
+
+func resumableChunkPath(bucket, sessionID string, chunk int) string {
+	return path.Join(resumableSessionPath(bucket, sessionID), fmt.Sprintf("chunk.%d", chunk))
+}
+
+// contentRange - parsed Content-Range of a chunk, `bytes start-end/size`.
+// Start and end are -1 for `bytes */size`, which only sets the size.
+// Size is -1 for `bytes start-end/*`, while the size is unknown.
+type contentRange struct {
+	start int64
+	end   int64
+	size  int64
+}
+
+// parseContentRange - parses the Content-Range header of a chunk.
+func parseContentRange(header string) (cr contentRange, err error) {
+	spec := strings.TrimPrefix(header, "bytes ")
+	if spec == header {
+		return cr, errInvalidContentRange
+	}
+	slash := strings.Index(spec, "/")
+	if slash == -1 {
+		return cr, errInvalidContentRange
+	}
+	byteRange, size := spec[:slash], spec[slash+1:]
+
+	cr.size = -1
+	if size != "*" {
+		if cr.size, err = strconv.ParseInt(size, 10, 64); err != nil || cr.size < 0 {
+			return cr, errInvalidContentRange
+		}
+	}
+	if byteRange == "*" {
+		// Only the size is set, which has to be known then.
+		if cr.size == -1 {
+			return cr, errInvalidContentRange
+		}
+		cr.start, cr.end = -1, -1
+		return cr, nil
+	}
+	dash := strings.Index(byteRange, "-")
+	if dash == -1 {
+		return cr, errInvalidContentRange
+	}
+	if cr.start, err = strconv.ParseInt(byteRange[:dash], 10, 64); err != nil || cr.start < 0 {
+		return cr, errInvalidContentRange
+	}
+	if cr.end, err = strconv.ParseInt(byteRange[dash+1:], 10, 64); err != nil || cr.end < cr.start {
+		return cr, errInvalidContentRange
+	}
+	if cr.size != -1 && cr.end >= cr.size {
+		return cr, errInvalidContentRange
+	}
+	return cr, nil
+}
+
+// length - returns the number of bytes of the chunk.
+func (cr contentRange) length() int64 {
+	if cr.start == -1 {
+		return 0
+	}
+	return cr.end - cr.start + 1
+}
+
+// readResumableSession - reads the session of a resumable upload of
+// object, returns InvalidUploadID if there is none.
+func readResumableSession(objAPI ObjectLayer, bucket, object, sessionID string) (*resumableSession, error) {
+	// Session ids are used in paths, only accept the ones generated.
+	if _, err := uuid.Parse(sessionID); err != nil {
+		return nil, traceError(InvalidUploadID{UploadID: sessionID})
+	}
+	sessionPath := path.Join(resumableSessionPath(bucket, sessionID), resumableSessionFile)
+	objInfo, err := objAPI.GetObjectInfo(minioMetaBucket, sessionPath)
+	if err != nil {
+		if _, ok := errorCause(err).(ObjectNotFound); ok {
+			return nil, traceError(InvalidUploadID{UploadID: sessionID})
+		}
+		return nil, err
+	}
+	var buffer bytes.Buffer
+	if err = objAPI.GetObject(minioMetaBucket, sessionPath, 0, objInfo.Size, &buffer); err != nil {
+		return nil, err
+	}
+	session := &resumableSession{}
+	if err = json.Unmarshal(buffer.Bytes(), session); err != nil {
+		return nil, err
+	}
+	if session.Object != object {
+		return nil, traceError(InvalidUploadID{UploadID: sessionID})
+	}
+	return session, nil
+}
+
+// writeResumableSession - saves the session of a resumable upload.
+func writeResumableSession(objAPI ObjectLayer, bucket, sessionID string, session *resumableSession) error {
+	buf, err := json.Marshal(session)
+	if err != nil {
+		return err
+	}
+	sessionPath := path.Join(resumableSessionPath(bucket, sessionID), resumableSessionFile)
+	_, err = objAPI.PutObject(minioMetaBucket, sessionPath, int64(len(buf)), bytes.NewReader(buf), nil, "")
+	return err
+}
+
+// removeResumableSession - removes the chunks of a session, and the
+// session itself last.
+func removeResumableSession(objAPI ObjectLayer, bucket, sessionID string) error {
+	sessionPrefix := resumableSessionPath(bucket, sessionID) + slashSeparator
+	var names []string
+	marker := ""
+	for {
+		result, err := objAPI.ListObjects(minioMetaBucket, sessionPrefix, marker, "", maxObjectList)
+		if err != nil {
+			return err
+		}
+		for _, objInfo := range result.Objects {
+			if path.Base(objInfo.Name) != resumableSessionFile {
+				names = append(names, objInfo.Name)
+			}
+		}
+		if !result.IsTruncated {
+			break
+		}
+		marker = result.NextMarker
+	}
+	names = append(names, path.Join(resumableSessionPath(bucket, sessionID), resumableSessionFile))
+	for _, name := range names {
+		if err := objAPI.DeleteObject(minioMetaBucket, name); err != nil {
+			if _, ok := errorCause(err).(ObjectNotFound); !ok {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// newResumableUpload - starts a resumable upload of object, returns
+// the id of its session.
+func newResumableUpload(objAPI ObjectLayer, bucket, object string, metadata map[string]string) (string, error) {
+	sessionID := getUUID()
+	session := &resumableSession{
+		Object:   object,
+		Metadata: metadata,
+		Size:     -1,
+		Chunks:   []int64{},
+	}
+	if err := writeResumableSession(objAPI, bucket, sessionID, session); err != nil {
+		return "", err
+	}
+	return sessionID, nil
+}
+
+// appendResumableUpload - appends a chunk with the data of cr to the
+// resumable upload, the object is created once all of its data was
+// uploaded. Chunks have to start at the end of the data uploaded so
+// far, otherwise errResumableOffsetMismatch is returned.
+func appendResumableUpload(objAPI ObjectLayer, bucket, object, sessionID string, cr contentRange, data io.Reader, sha256sum string) (*resumableSession, ObjectInfo, error) {
+	opsID := getOpsID()
+	lockPath := resumableSessionPath(bucket, sessionID)
+	nsMutex.Lock(minioMetaBucket, lockPath, opsID)
+	defer nsMutex.Unlock(minioMetaBucket, lockPath, opsID)
+
+	session, err := readResumableSession(objAPI, bucket, object, sessionID)
+	if err != nil {
+		return nil, ObjectInfo{}, err
+	}
+	size := session.Size
+	if cr.size != -1 {
+		if size != -1 && size != cr.size {
+			return nil, ObjectInfo{}, traceError(errInvalidContentRange)
+		}
+		size = cr.size
+	}
+	offset := session.offset()
+	if cr.start != -1 && cr.start != offset {
+		return session, ObjectInfo{}, traceError(errResumableOffsetMismatch)
+	}
+	if size != -1 && offset+cr.length() > size {
+		return nil, ObjectInfo{}, traceError(errInvalidContentRange)
+	}
+
+	if cr.length() > 0 {
+		chunkPath := resumableChunkPath(bucket, sessionID, len(session.Chunks)+1)
+		if _, err = objAPI.PutObject(minioMetaBucket, chunkPath, cr.length(), data, nil, sha256sum); err != nil {
+			return nil, ObjectInfo{}, err
+		}
+		session.Chunks = append(session.Chunks, cr.length())
+	}
+	session.Size = size
+	if !session.isComplete() {
+		return session, ObjectInfo{}, writeResumableSession(objAPI, bucket, sessionID, session)
+	}
+
+	objInfo, err := assembleResumableUpload(objAPI, bucket, sessionID, session)
+	if err != nil {
+		// Keep the uploaded chunk, the object can be assembled again
+		// by sending its size once more.
+		errorIf(writeResumableSession(objAPI, bucket, sessionID, session), "Unable to save resumable upload session %s.", sessionID)
+		return nil, ObjectInfo{}, err
+	}
+	errorIf(removeResumableSession(objAPI, bucket, sessionID), "Unable to remove resumable upload session %s.", sessionID)
+	return session, objInfo, nil
+}
+
+// assembleResumableUpload - creates the object of a complete session
+// from its chunks.
+func assembleResumableUpload(objAPI ObjectLayer, bucket, sessionID string, session *resumableSession) (ObjectInfo, error) {
+	pipeReader, pipeWriter := io.Pipe()
+	go func() {
+		for i, size := range session.Chunks {
+			if err := objAPI.GetObject(minioMetaBucket, resumableChunkPath(bucket, sessionID, i+1), 0, size, pipeWriter); err != nil {
+				pipeWriter.CloseWithError(err)
+				return
+			}
+		}
+		pipeWriter.Close()
+	}()
+	metadata := make(map[string]string)
+	for k, v := range session.Metadata {
+		metadata[k] = v
+	}
+	objInfo, err := objAPI.PutObject(bucket, session.Object, session.Size, pipeReader, metadata, "")
+	pipeReader.Close()
+	return objInfo, err
+}
+
+// abortResumableUpload - removes a resumable upload and its chunks.
+func abortResumableUpload(objAPI ObjectLayer, bucket, object, sessionID string) error {
+	opsID := getOpsID()
+	lockPath := resumableSessionPath(bucket, sessionID)
+	nsMutex.Lock(minioMetaBucket, lockPath, opsID)
+	defer nsMutex.Unlock(minioMetaBucket, lockPath, opsID)
+
+	if _, err := readResumableSession(objAPI, bucket, object, sessionID); err != nil {
+		return err
+	}
+	return removeResumableSession(objAPI, bucket, sessionID)
+}
+
+// purgeResumableUploads - removes the sessions which were not updated
+// for expiry, returns the number of purged sessions.
+func purgeResumableUploads(objAPI ObjectLayer, expiry time.Duration) (purged int, err error) {
+	type expiredSession struct{ bucket, sessionID string }
+	var expired []expiredSession
+	marker := ""
+	for {
+		result, err := objAPI.ListObjects(minioMetaBucket, resumablePrefix+slashSeparator, marker, "", maxObjectList)
+		if err != nil {
+			return purged, err
+		}
+		for _, objInfo := range result.Objects {
+			// Sessions are saved after every chunk.
+			if path.Base(objInfo.Name) != resumableSessionFile || time.Since(objInfo.ModTime) < expiry {
+				continue
+			}
+			sessionPath := path.Dir(objInfo.Name)
+			expired = append(expired, expiredSession{
+				bucket:    path.Base(path.Dir(sessionPath)),
+				sessionID: path.Base(sessionPath),
+			})
+		}
+		if !result.IsTruncated {
+			break
+		}
+		marker = result.NextMarker
+	}
+
+	for _, session := range expired {
+		opsID := getOpsID()
+		lockPath := resumableSessionPath(session.bucket, session.sessionID)
+		nsMutex.Lock(minioMetaBucket, lockPath, opsID)
+		err = removeResumableSession(objAPI, session.bucket, session.sessionID)
+		nsMutex.Unlock(minioMetaBucket, lockPath, opsID)
+		if err != nil {
+			return purged, err
+		}
+		purged++
+	}
+	return purged, nil
+}
+
+// startResumablePurge - periodically purges expired resumable uploads.
+func startResumablePurge(objAPI ObjectLayer, interval, expiry time.Duration) {
+	globalTaskManager.Start(resumablePurgeTask, interval, func() error {
+		_, err := purgeResumableUploads(objAPI, expiry)
+		errorIf(err, "Unable to purge expired resumable uploads.")
+		return err
+	})
+}
@@ -0,0 +1,297 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// Tests parsing of the Content-Range of chunks.
+func TestParseContentRange(t *testing.T) {
+	testCases := []struct {
+		header   string
+		expected contentRange
+		err      error
+	}{
+		{"bytes 0-9/100", contentRange{0, 9, 100}, nil},
+		{"bytes 10-19/*", contentRange{10, 19, -1}, nil},
+		{"bytes */100", contentRange{-1, -1, 100}, nil},
+		{"bytes */0", contentRange{-1, -1, 0}, nil},
+		{"", contentRange{}, errInvalidContentRange},
+		{"0-9/100", contentRange{}, errInvalidContentRange},
+		{"bytes 0-9", contentRange{}, errInvalidContentRange},
+		{"bytes */*", contentRange{}, errInvalidContentRange},
+		{"bytes 9-0/100", contentRange{}, errInvalidContentRange},
+		{"bytes 0-100/100", contentRange{}, errInvalidContentRange},
+		{"bytes -1-9/100", contentRange{}, errInvalidContentRange},
+		{"bytes 0-9/-1", contentRange{}, errInvalidContentRange},
+	}
+	for i, testCase := range testCases {
+		cr, err := parseContentRange(testCase.header)
+		if err != testCase.err {
+			t.Errorf("Test %d: Expected error %v, got %v", i+1, testCase.err, err)
+			continue
+		}
+		if err == nil && cr != testCase.expected {
+			t.Errorf("Test %d: Expected %+v, got %+v", i+1, testCase.expected, cr)
+		}
+	}
+}
+
+// Wrapper for calling resumable upload tests for both XL and FS.
+func TestResumableUpload(t *testing.T) {
+	ExecObjectLayerTest(t, testResumableUpload)
+}
+
+// Tests objects are assembled from the chunks of resumable uploads.
+func testResumableUpload(obj ObjectLayer, instanceType string, t TestErrHandler) {
+	bucket := "resumable-bucket"
+	if err := obj.MakeBucket(bucket); err != nil {
+		t.Fatalf("%s: Unable to make bucket: %v", instanceType, err)
+	}
+	metadata := map[string]string{"content-type": "text/plain"}
+	sessionID, err := newResumableUpload(obj, bucket, "object", metadata)
+	if err != nil {
+		t.Fatalf("%s: Unable to start resumable upload: %v", instanceType, err)
+	}
+
+	// Sessions belong to their object.
+	if _, err = readResumableSession(obj, bucket, "other", sessionID); !isInvalidUploadID(err) {
+		t.Errorf("%s: Expected InvalidUploadID, got %v", instanceType, err)
+	}
+	if _, err = readResumableSession(obj, bucket, "object", "../../config"); !isInvalidUploadID(err) {
+		t.Errorf("%s: Expected InvalidUploadID, got %v", instanceType, err)
+	}
+
+	data := []byte("hello, world")
+	testCases := []struct {
+		cr       contentRange
+		offset   int64
+		complete bool
+		err      error
+	}{
+		// Test case - 1, chunks of unknown total size.
+		{contentRange{0, 4, -1}, 5, false, nil},
+		// Test case - 2, chunks have to start at the end of the uploaded data.
+		{contentRange{0, 4, -1}, 5, false, errResumableOffsetMismatch},
+		// Test case - 3.
+		{contentRange{7, 8, -1}, 5, false, errResumableOffsetMismatch},
+		// Test case - 4, chunks cannot exceed the total size.
+		{contentRange{5, 9, 8}, 5, false, errInvalidContentRange},
+		// Test case - 5.
+		{contentRange{5, 9, -1}, 10, false, nil},
+		// Test case - 6, the object is created once complete.
+		{contentRange{10, 11, 12}, 12, true, nil},
+	}
+	for i, testCase := range testCases {
+		chunk := data[:0]
+		if testCase.cr.start != -1 {
+			chunk = data[testCase.cr.start : testCase.cr.end+1]
+		}
+		session, objInfo, err := appendResumableUpload(obj, bucket, "object", sessionID, testCase.cr, bytes.NewReader(chunk), "")
+		if errorCause(err) != testCase.err {
+			t.Fatalf("%s: Test %d: Expected error %v, got %v", instanceType, i+1, testCase.err, err)
+		}
+		if session == nil {
+			continue
+		}
+		if session.offset() != testCase.offset || session.isComplete() != testCase.complete {
+			t.Errorf("%s: Test %d: Expected offset %d complete %t, got %d %t", instanceType, i+1, testCase.offset, testCase.complete, session.offset(), session.isComplete())
+		}
+		if testCase.complete && objInfo.Size != int64(len(data)) {
+			t.Errorf("%s: Test %d: Expected object of size %d, got %d", instanceType, i+1, len(data), objInfo.Size)
+		}
+	}
+
+	var buffer bytes.Buffer
+	if err = obj.GetObject(bucket, "object", 0, int64(len(data)), &buffer); err != nil {
+		t.Fatalf("%s: Unable to get object: %v", instanceType, err)
+	}
+	if !bytes.Equal(buffer.Bytes(), data) {
+		t.Errorf("%s: Expected %q, got %q", instanceType, data, buffer.Bytes())
+	}
+	objInfo, err := obj.GetObjectInfo(bucket, "object")
+	if err != nil {
+		t.Fatalf("%s: Unable to get object info: %v", instanceType, err)
+	}
+	if objInfo.ContentType != "text/plain" {
+		t.Errorf("%s: Expected content-type text/plain, got %s", instanceType, objInfo.ContentType)
+	}
+
+	// Completed sessions are removed.
+	if _, err = readResumableSession(obj, bucket, "object", sessionID); !isInvalidUploadID(err) {
+		t.Errorf("%s: Expected InvalidUploadID, got %v", instanceType, err)
+	}
+	result, err := obj.ListObjects(minioMetaBucket, resumablePrefix+slashSeparator, "", "", maxObjectList)
+	if err != nil {
+		t.Fatalf("%s: Unable to list sessions: %v", instanceType, err)
+	}
+	if len(result.Objects) != 0 {
+		t.Errorf("%s: Expected sessions to be removed, got %v", instanceType, result.Objects)
+	}
+
+	// Empty objects only need their size.
+	if sessionID, err = newResumableUpload(obj, bucket, "empty", nil); err != nil {
+		t.Fatalf("%s: Unable to start resumable upload: %v", instanceType, err)
+	}
+	if _, objInfo, err = appendResumableUpload(obj, bucket, "empty", sessionID, contentRange{-1, -1, 0}, bytes.NewReader(nil), ""); err != nil {
+		t.Fatalf("%s: Unable to complete resumable upload: %v", instanceType, err)
+	}
+	if objInfo.Name != "empty" || objInfo.Size != 0 {
+		t.Errorf("%s: Unexpected object %v", instanceType, objInfo)
+	}
+
+	// Aborted sessions are removed along with their chunks.
+	if sessionID, err = newResumableUpload(obj, bucket, "aborted", nil); err != nil {
+		t.Fatalf("%s: Unable to start resumable upload: %v", instanceType, err)
+	}
+	if _, _, err = appendResumableUpload(obj, bucket, "aborted", sessionID, contentRange{0, 4, -1}, bytes.NewReader(data[:5]), ""); err != nil {
+		t.Fatalf("%s: Unable to append to resumable upload: %v", instanceType, err)
+	}
+	if err = abortResumableUpload(obj, bucket, "aborted", sessionID); err != nil {
+		t.Fatalf("%s: Unable to abort resumable upload: %v", instanceType, err)
+	}
+	if err = abortResumableUpload(obj, bucket, "aborted", sessionID); !isInvalidUploadID(err) {
+		t.Errorf("%s: Expected InvalidUploadID, got %v", instanceType, err)
+	}
+	if _, err = obj.GetObjectInfo(minioMetaBucket, resumableChunkPath(bucket, sessionID, 1)); !isObjectNotFound(err) {
+		t.Errorf("%s: Expected chunk to be removed, got %v", instanceType, err)
+	}
+}
+
+// Wrapper for calling resumable upload purge tests for both XL and FS.
+func TestPurgeResumableUploads(t *testing.T) {
+	ExecObjectLayerTest(t, testPurgeResumableUploads)
+}
+
+// Tests only sessions not updated for the expiry are purged.
+func testPurgeResumableUploads(obj ObjectLayer, instanceType string, t TestErrHandler) {
+	bucket := "resumable-bucket"
+	if err := obj.MakeBucket(bucket); err != nil {
+		t.Fatalf("%s: Unable to make bucket: %v", instanceType, err)
+	}
+	sessionID, err := newResumableUpload(obj, bucket, "object", nil)
+	if err != nil {
+		t.Fatalf("%s: Unable to start resumable upload: %v", instanceType, err)
+	}
+	if purged, err := purgeResumableUploads(obj, resumableSessionExpiry); err != nil || purged != 0 {
+		t.Fatalf("%s: Expected no session to be purged, got %d: %v", instanceType, purged, err)
+	}
+	if purged, err := purgeResumableUploads(obj, 0); err != nil || purged != 1 {
+		t.Fatalf("%s: Expected one session to be purged, got %d: %v", instanceType, purged, err)
+	}
+	if _, err = readResumableSession(obj, bucket, "object", sessionID); !isInvalidUploadID(err) {
+		t.Errorf("%s: Expected InvalidUploadID, got %v", instanceType, err)
+	}
+}
+
+// Wrapper for calling resumable upload HTTP handler tests for both XL multiple disks and single node setup.
+func TestResumableUploadHandlers(t *testing.T) {
+	ExecObjectLayerAPITest(t, testResumableUploadHandlers, []string{"NewResumableUpload", "PutResumableUpload", "HeadResumableUpload", "AbortResumableUpload"})
+}
+
+func testResumableUploadHandlers(obj ObjectLayer, instanceType, bucketName string, apiRouter http.Handler,
+	credentials credential, t *testing.T) {
+	doRequest := func(method, url string, data []byte, contentRange string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		req, err := newTestSignedRequestV4(method, url, int64(len(data)), bytes.NewReader(data), credentials.AccessKeyID, credentials.SecretAccessKey)
+		if err != nil {
+			t.Fatalf("%s: Failed to create HTTP request: <ERROR> %v", instanceType, err)
+		}
+		if contentRange != "" {
+			req.Header.Set("Content-Range", contentRange)
+		}
+		apiRouter.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := doRequest("POST", getNewResumableUploadURL("", bucketName, "object"), nil, "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("%s: Expected %d, got %d", instanceType, http.StatusOK, rec.Code)
+	}
+	var response resumableUploadResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+		t.Fatalf("%s: Unable to parse response: %v", instanceType, err)
+	}
+	if rec.Header().Get("Location") != response.Location {
+		t.Errorf("%s: Expected Location %s, got %s", instanceType, response.Location, rec.Header().Get("Location"))
+	}
+	sessionURL := getResumableUploadURL("", bucketName, "object", response.SessionID)
+
+	data := []byte("hello, world")
+	testCases := []struct {
+		method       string
+		data         []byte
+		contentRange string
+		code         int
+		rangeHeader  string
+	}{
+		// Test case - 1.
+		{"PUT", data[:5], "bytes 0-4/*", http.StatusAccepted, "bytes=0-4"},
+		// Test case - 2, resuming at the wrong offset.
+		{"PUT", data[7:], "bytes 7-11/12", http.StatusConflict, "bytes=0-4"},
+		// Test case - 3, chunks have to match their range.
+		{"PUT", data[5:], "bytes 5-6/12", http.StatusBadRequest, ""},
+		// Test case - 4.
+		{"PUT", data[5:], "", http.StatusBadRequest, ""},
+		// Test case - 5.
+		{"HEAD", nil, "", http.StatusOK, "bytes=0-4"},
+		// Test case - 6.
+		{"PUT", data[5:], "bytes 5-11/12", http.StatusOK, "bytes=0-11"},
+		// Test case - 7, completed sessions are removed.
+		{"HEAD", nil, "", http.StatusNotFound, ""},
+	}
+	for i, testCase := range testCases {
+		rec = doRequest(testCase.method, sessionURL, testCase.data, testCase.contentRange)
+		if rec.Code != testCase.code {
+			t.Fatalf("%s: Test %d: Expected %d, got %d", instanceType, i+1, testCase.code, rec.Code)
+		}
+		if rec.Header().Get("Range") != testCase.rangeHeader {
+			t.Errorf("%s: Test %d: Expected Range %q, got %q", instanceType, i+1, testCase.rangeHeader, rec.Header().Get("Range"))
+		}
+	}
+
+	var buffer bytes.Buffer
+	if err := obj.GetObject(bucketName, "object", 0, int64(len(data)), &buffer); err != nil {
+		t.Fatalf("%s: Unable to get object: %v", instanceType, err)
+	}
+	if !bytes.Equal(buffer.Bytes(), data) {
+		t.Errorf("%s: Expected %q, got %q", instanceType, data, buffer.Bytes())
+	}
+
+	// Aborting a session.
+	rec = doRequest("POST", getNewResumableUploadURL("", bucketName, "aborted"), nil, "")
+	if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+		t.Fatalf("%s: Unable to parse response: %v", instanceType, err)
+	}
+	sessionURL = getResumableUploadURL("", bucketName, "aborted", response.SessionID)
+	if rec = doRequest("DELETE", sessionURL, nil, ""); rec.Code != http.StatusNoContent {
+		t.Fatalf("%s: Expected %d, got %d", instanceType, http.StatusNoContent, rec.Code)
+	}
+	if rec = doRequest("DELETE", sessionURL, nil, ""); rec.Code != http.StatusNotFound {
+		t.Errorf("%s: Expected %d, got %d", instanceType, http.StatusNotFound, rec.Code)
+	}
+}
+
+// isInvalidUploadID - returns true if err is InvalidUploadID.
+func isInvalidUploadID(err error) bool {
+	_, ok := errorCause(err).(InvalidUploadID)
+	return ok
+}
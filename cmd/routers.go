@@ -18,8 +18,6 @@ package cmd
 
 import (
 	"net/http"
-	"os"
-	"strings"
 
 	router "github.com/gorilla/mux"
 )
@@ -69,10 +67,18 @@ func newObjectLayer(storageDisks []StorageAPI) (ObjectLayer, error) {
 	err = initBucketPolicies(objAPI)
 	fatalIf(err, "Unable to load all bucket policies.")
 
+	// Initialize and load bucket settings.
+	err = initBucketSettings(objAPI)
+	fatalIf(err, "Unable to load all bucket settings.")
+
 	// Initialize a new event notifier.
 	err = initEventNotifier(objAPI)
 	fatalIf(err, "Unable to initialize event notification.")
 
+	// Load the alert rules evaluated on request metrics.
+	err = loadAlertRules(objAPI)
+	fatalIf(err, "Unable to load alert rules.")
+
 	// Success.
 	return objAPI, nil
 }
@@ -109,9 +115,17 @@ func configureServerHandler(srvCmdConfig serverCmdConfig) (http.Handler, error)
 		return nil, err
 	}
 
+	// Register federation rpc router only on the coordinator, there is
+	// none when the owners are stored in etcd.
+	if globalFederation != nil && globalFederation.coordinator == nil && globalFederation.etcd == nil {
+		if err = registerFederationRPCRouter(mux); err != nil {
+			return nil, err
+		}
+	}
+
 	// set environmental variable MINIO_BROWSER=off to disable minio web browser.
 	// By default minio web browser is enabled.
-	if !strings.EqualFold(os.Getenv("MINIO_BROWSER"), "off") {
+	if globalBrowserEnabled {
 		// Register RPC router for web related calls.
 		if err = registerBrowserRPCRouter(mux); err != nil {
 			return nil, err
@@ -144,10 +158,21 @@ func configureServerHandler(srvCmdConfig serverCmdConfig) (http.Handler, error)
 		// Validates all incoming URL resources, for invalid/unsupported
 		// resources client receives a HTTP error.
 		setIgnoreResourcesHandler,
+		// Rejects signed requests from source IPs and for access keys
+		// locked out after repeated authentication failures.
+		setAuthLockoutHandler,
 		// Auth handler verifies incoming authorization headers and
 		// routes them accordingly. Client receives a HTTP error for
 		// invalid/unsupported signatures.
 		setAuthHandler,
+		// Redirects or proxies requests for buckets owned by another
+		// cluster of the federation.
+		setFederationHandler,
+		// Blocks all the writes while frozen for a snapshot.
+		setWriteFreezeHandler,
+		// Records request metrics evaluated by alert rules, last to
+		// see the requests rejected by all the other handlers.
+		setRequestMetricsHandler,
 		// Add new handlers here.
 	}
 
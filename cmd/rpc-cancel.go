@@ -0,0 +1,178 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"errors"
+	"os"
+	"os/signal"
+	"sync"
+	"time"
+
+	"github.com/minio/mc/pkg/console"
+)
+
+const (
+	// Maximum duration of control calls between the nodes.
+	controlRPCTimeout = 10 * time.Minute
+
+	// Maximum duration of S3 and browser peer calls.
+	peerRPCTimeout = time.Minute
+
+	// Cancelled calls are forgotten after this duration.
+	rpcCancelExpiry = time.Hour
+)
+
+// errRPCTimeout - RPC call did not complete before its deadline.
+var errRPCTimeout = errors.New("RPC call timed out")
+
+// errRPCCancelled - RPC call was cancelled by the caller.
+var errRPCCancelled = errors.New("RPC call cancelled")
+
+// rpcDeadlineArgs - RPC arguments carrying the deadline of the call.
+type rpcDeadlineArgs interface {
+	SetDeadline(deadline time.Time)
+	GetDeadline() time.Time
+}
+
+// setRPCDeadline - sets the deadline of args to timeout from now,
+// unless args already has an earlier deadline, e.g. propagated from
+// the call being served.
+func setRPCDeadline(args interface{}, timeout time.Duration) {
+	dargs, ok := args.(rpcDeadlineArgs)
+	if !ok || timeout <= 0 {
+		return
+	}
+	deadline := time.Now().UTC().Add(timeout)
+	if cur := dargs.GetDeadline(); cur.IsZero() || cur.After(deadline) {
+		dargs.SetDeadline(deadline)
+	}
+}
+
+// getRPCTimeout - returns how long to wait for the reply of a call
+// with args, the earliest of timeout and the deadline of args. Zero
+// means no timeout, negative means the deadline has passed.
+func getRPCTimeout(args interface{}, timeout time.Duration) time.Duration {
+	dargs, ok := args.(rpcDeadlineArgs)
+	if !ok || dargs.GetDeadline().IsZero() {
+		return timeout
+	}
+	remaining := dargs.GetDeadline().Sub(time.Now().UTC())
+	if remaining <= 0 {
+		return -1
+	}
+	if timeout <= 0 || remaining < timeout {
+		return remaining
+	}
+	return timeout
+}
+
+// rpcCancels - ids of the calls cancelled on this server.
+type rpcCancels struct {
+	mutex     *sync.Mutex
+	cancelled map[string]time.Time
+}
+
+// Variable holding the cancelled calls.
+var globalRPCCancels = newRPCCancels()
+
+// newRPCCancels - returns empty cancelled calls.
+func newRPCCancels() *rpcCancels {
+	return &rpcCancels{
+		mutex:     &sync.Mutex{},
+		cancelled: make(map[string]time.Time),
+	}
+}
+
+// Cancel the call callID, its handler stops at its next check.
+func (r *rpcCancels) Cancel(callID string) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	now := time.Now().UTC()
+	for id, cancelledAt := range r.cancelled {
+		if now.Sub(cancelledAt) > rpcCancelExpiry {
+			delete(r.cancelled, id)
+		}
+	}
+	r.cancelled[callID] = now
+}
+
+// IsCancelled returns true if the call callID was cancelled.
+func (r *rpcCancels) IsCancelled(callID string) bool {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	_, ok := r.cancelled[callID]
+	return ok
+}
+
+// isCancelled - returns true if the caller abandoned the call, meant
+// to be checked by long running handlers between units of work.
+func (ga *GenericArgs) isCancelled() bool {
+	if !ga.Deadline.IsZero() && time.Now().UTC().After(ga.Deadline) {
+		return true
+	}
+	return ga.CallID != "" && globalRPCCancels.IsCancelled(ga.CallID)
+}
+
+// CancelArgs - argument for CancelHandler RPC.
+type CancelArgs struct {
+	// Authentication token generated by Login.
+	GenericArgs
+
+	// Call to cancel.
+	TargetCallID string
+}
+
+// CancelHandler - RPC control handler cancelling the call with the
+// given call id, issued by the CLI when interrupted.
+func (c *controlAPIHandlers) CancelHandler(args *CancelArgs, reply *GenericReply) (err error) {
+	defer encodeRPCError(&err)
+
+	// Heal tokens may cancel the heal calls they made.
+	if !isHealTokenValid(args.Token) {
+		return errInvalidToken
+	}
+	if args.TargetCallID == "" {
+		return errInvalidArgument
+	}
+	globalRPCCancels.Cancel(args.TargetCallID)
+	return nil
+}
+
+// cancelOnInterrupt - cancels the calls made with callID on the server
+// of client when the command is interrupted, then exits. The returned
+// function stops watching for interrupts.
+func cancelOnInterrupt(client *AuthRPCClient, callID string) (stop func()) {
+	sigCh := make(chan os.Signal, 1)
+	doneCh := make(chan struct{})
+	signal.Notify(sigCh, os.Interrupt)
+	go func() {
+		select {
+		case <-sigCh:
+		case <-doneCh:
+			return
+		}
+		err := client.Call("Control.CancelHandler", &CancelArgs{TargetCallID: callID}, &GenericReply{})
+		fatalIf(err, "Unable to cancel the operation on the server.")
+		console.Println("Operation cancelled.")
+		os.Exit(1)
+	}()
+	return func() {
+		signal.Stop(sigCh)
+		close(doneCh)
+	}
+}
@@ -0,0 +1,112 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"net/http/httptest"
+	"net/rpc"
+	"testing"
+	"time"
+)
+
+// Service used to test RPC call timeouts.
+type rpcSlowService struct{}
+
+func (rpcSlowService) Sleep(args *GenericArgs, reply *GenericReply) error {
+	time.Sleep(time.Second)
+	return nil
+}
+
+// Tests calls are abandoned once they time out.
+func TestRPCCallTimeout(t *testing.T) {
+	server := rpc.NewServer()
+	if err := server.RegisterName("Slow", rpcSlowService{}); err != nil {
+		t.Fatal(err)
+	}
+	ts := httptest.NewServer(newRPCHandler(server))
+	defer ts.Close()
+
+	client := newClient(ts.Listener.Addr().String(), "/", false, false, 10*time.Millisecond)
+	defer client.Close()
+	if err := client.Call("Slow.Sleep", &GenericArgs{}, &GenericReply{}); err != errRPCTimeout {
+		t.Fatalf("Expected %v, got %v", errRPCTimeout, err)
+	}
+
+	// The deadline of args is used when earlier than the timeout.
+	client = newClient(ts.Listener.Addr().String(), "/", false, false, 0)
+	defer client.Close()
+	args := &GenericArgs{Deadline: time.Now().UTC().Add(10 * time.Millisecond)}
+	if err := client.Call("Slow.Sleep", args, &GenericReply{}); err != errRPCTimeout {
+		t.Fatalf("Expected %v, got %v", errRPCTimeout, err)
+	}
+	// Calls past their deadline are not sent.
+	if err := client.Call("Slow.Sleep", args, &GenericReply{}); err != errRPCTimeout {
+		t.Fatalf("Expected %v, got %v", errRPCTimeout, err)
+	}
+	// The connection is re-established after a timeout.
+	args = &GenericArgs{Deadline: time.Now().UTC().Add(time.Minute)}
+	if err := client.Call("Slow.Sleep", args, &GenericReply{}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+}
+
+// Tests deadlines set on args before the call.
+func TestSetRPCDeadline(t *testing.T) {
+	args := &GenericArgs{}
+	setRPCDeadline(args, 0)
+	if !args.Deadline.IsZero() {
+		t.Fatalf("Expected no deadline, got %v", args.Deadline)
+	}
+	setRPCDeadline(args, time.Minute)
+	if args.Deadline.IsZero() {
+		t.Fatal("Expected a deadline")
+	}
+	// Earlier deadlines are kept.
+	early := time.Now().UTC().Add(time.Second)
+	args.Deadline = early
+	setRPCDeadline(args, time.Minute)
+	if !args.Deadline.Equal(early) {
+		t.Fatalf("Expected deadline %v, got %v", early, args.Deadline)
+	}
+	if timeout := getRPCTimeout(args, time.Minute); timeout <= 0 || timeout > time.Second {
+		t.Fatalf("Expected the timeout of the deadline, got %v", timeout)
+	}
+	// Args without deadline use the client timeout.
+	if timeout := getRPCTimeout(&RPCLoginArgs{}, time.Minute); timeout != time.Minute {
+		t.Fatalf("Expected %v, got %v", time.Minute, timeout)
+	}
+}
+
+// Tests cancellation of calls.
+func TestRPCCancels(t *testing.T) {
+	args := &GenericArgs{CallID: "call-1"}
+	if args.isCancelled() {
+		t.Fatal("Expected call not to be cancelled")
+	}
+	globalRPCCancels.Cancel("call-1")
+	if !args.isCancelled() {
+		t.Fatal("Expected call to be cancelled")
+	}
+	if (&GenericArgs{}).isCancelled() {
+		t.Fatal("Expected calls without id not to be cancelled")
+	}
+	// Calls past their deadline are cancelled.
+	args = &GenericArgs{Deadline: time.Now().UTC().Add(-time.Second)}
+	if !args.isCancelled() {
+		t.Fatal("Expected call past its deadline to be cancelled")
+	}
+}
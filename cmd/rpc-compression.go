@@ -0,0 +1,114 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"compress/flate"
+	"io"
+	"net"
+	"net/http"
+	"net/rpc"
+	"sync"
+)
+
+const (
+	// Status line sent by net/rpc once the connection is hijacked.
+	rpcConnectedStatus = "200 Connected to Go RPC"
+
+	// Header used by the client to offer, and by the server to accept,
+	// compression of the RPC connection. Servers not aware of it reply
+	// without it and the connection stays uncompressed.
+	rpcCompressionHeader = "X-Minio-Rpc-Compression"
+	rpcCompressionFlate  = "deflate"
+)
+
+// compressedConn - net.Conn which deflates everything written to it
+// and inflates everything read from it. Every Write is flushed, so
+// that gob messages are never held back, while the compression window
+// is kept across messages.
+type compressedConn struct {
+	net.Conn
+	reader io.ReadCloser
+
+	mu     sync.Mutex
+	writer *flate.Writer
+}
+
+func newCompressedConn(conn net.Conn) (net.Conn, error) {
+	writer, err := flate.NewWriter(conn, flate.BestSpeed)
+	if err != nil {
+		return nil, err
+	}
+	return &compressedConn{
+		Conn:   conn,
+		reader: flate.NewReader(conn),
+		writer: writer,
+	}, nil
+}
+
+func (c *compressedConn) Read(b []byte) (int, error) {
+	return c.reader.Read(b)
+}
+
+func (c *compressedConn) Write(b []byte) (n int, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if n, err = c.writer.Write(b); err != nil {
+		return n, err
+	}
+	return n, c.writer.Flush()
+}
+
+func (c *compressedConn) Close() error {
+	c.reader.Close()
+	return c.Conn.Close()
+}
+
+// rpcHandler - serves net/rpc over HTTP CONNECT like rpc.Server does,
+// compressing the connection when the client asks for it.
+type rpcHandler struct {
+	server *rpc.Server
+}
+
+// newRPCHandler - returns a http.Handler serving server.
+func newRPCHandler(server *rpc.Server) http.Handler {
+	return rpcHandler{server: server}
+}
+
+func (h rpcHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "CONNECT" || r.Header.Get(rpcCompressionHeader) != rpcCompressionFlate {
+		h.server.ServeHTTP(w, r)
+		return
+	}
+	hijacker, ok := w.(http.Hijacker)
+	if !ok {
+		h.server.ServeHTTP(w, r)
+		return
+	}
+	conn, _, err := hijacker.Hijack()
+	if err != nil {
+		errorIf(err, "Unable to hijack RPC connection from %s", r.RemoteAddr)
+		return
+	}
+	io.WriteString(conn, "HTTP/1.0 "+rpcConnectedStatus+"\n"+rpcCompressionHeader+": "+rpcCompressionFlate+"\n\n")
+	compressed, err := newCompressedConn(conn)
+	if err != nil {
+		conn.Close()
+		return
+	}
+	h.server.ServeConn(compressed)
+}
@@ -0,0 +1,71 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/rpc"
+	"strings"
+	"testing"
+)
+
+// Service used to test RPC compression.
+type rpcEchoService struct{}
+
+func (rpcEchoService) Echo(args *string, reply *string) error {
+	*reply = *args
+	return nil
+}
+
+// Tests RPC calls between clients and servers with and without
+// compression support.
+func TestRPCCompression(t *testing.T) {
+	server := rpc.NewServer()
+	if err := server.RegisterName("Echo", rpcEchoService{}); err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		handler  http.Handler
+		compress bool
+	}{
+		// Compression negotiated.
+		{newRPCHandler(server), true},
+		// Client not asking for compression.
+		{newRPCHandler(server), false},
+		// Server not supporting compression.
+		{server, true},
+	}
+	for i, testCase := range testCases {
+		ts := httptest.NewServer(testCase.handler)
+
+		client := newClient(ts.Listener.Addr().String(), "/", false, testCase.compress, 0)
+		// Multiple calls exercise the compression window across messages.
+		for j, args := range []string{"hello", strings.Repeat("minio", 64*1024), ""} {
+			var reply string
+			if err := client.Call("Echo.Echo", &args, &reply); err != nil {
+				t.Fatalf("Test %d.%d: Unexpected error %v", i+1, j+1, err)
+			}
+			if reply != args {
+				t.Errorf("Test %d.%d: Expected reply of length %d, got %d", i+1, j+1, len(args), len(reply))
+			}
+		}
+		client.Close()
+		ts.Close()
+	}
+}
@@ -0,0 +1,127 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"net/rpc"
+	"strings"
+)
+
+// net/rpc only carries the error string of a failed call, so errors
+// returned by control and peer RPC handlers are sent as a JSON encoded
+// RPCError prefixed with rpcErrorPrefix. Servers not aware of it send
+// plain strings which are passed through unchanged.
+const rpcErrorPrefix = "minio-rpc-error:"
+
+// rpcSentinelErrors - errors which are turned back into the same
+// sentinel on the caller, indexed by their code.
+var rpcSentinelErrors = map[string]error{
+	"InvalidToken":           errInvalidToken,
+	"InvalidArgument":        errInvalidArgument,
+	"InvalidAccessKeyID":     errInvalidAccessKeyID,
+	"Authentication":         errAuthentication,
+	"ServerNotInitialized":   errServerNotInitialized,
+	"ServerVersionMismatch":  errServerVersionMismatch,
+	"ServerTimeMismatch":     errServerTimeMismatch,
+	"WritesFrozen":           errWritesFrozen,
+	"WritesNotFrozen":        errWritesNotFrozen,
+	"FreezeExpired":          errFreezeExpired,
+	"FaultInjectionDisabled": errFaultInjectionDisabled,
+	"PeerExists":             errPeerExists,
+	"PeerNotFound":           errPeerNotFound,
+	"PeerIsLocal":            errPeerIsLocal,
+	"PeerHasDisks":           errPeerHasDisks,
+	"PeerQuorum":             errPeerQuorum,
+	"LocateNotXL":            errLocateNotXL,
+	"FederationBucketOwned":  errFederationBucketOwned,
+	"RPCTimeout":             errRPCTimeout,
+	"RPCCancelled":           errRPCCancelled,
+	"TaskNotFound":           errTaskNotFound,
+	"InvalidAlertRule":       errInvalidAlertRule,
+	"AlertRuleNotFound":      errAlertRuleNotFound,
+	"InvalidReplyStream":     errInvalidReplyStream,
+}
+
+// RPCError - error returned by a remote RPC handler.
+type RPCError struct {
+	Code    string   // Code of a known sentinel error, empty otherwise.
+	Message string   // Message of the error.
+	Node    string   // Node which returned the error.
+	Stack   []string // Stack trace on the remote node, if traced.
+}
+
+// Implement error interface.
+func (e *RPCError) Error() string {
+	return e.Message
+}
+
+// Returns the code of the sentinel error err, empty if it is unknown.
+func getRPCErrorCode(err error) string {
+	for code, sentinel := range rpcSentinelErrors {
+		if err == sentinel {
+			return code
+		}
+	}
+	return ""
+}
+
+// toRPCError - converts err returned by a RPC handler into an error
+// whose string carries the encoded RPCError.
+func toRPCError(err error) error {
+	if err == nil {
+		return nil
+	}
+	rpcErr := &RPCError{
+		Code:    getRPCErrorCode(errorCause(err)),
+		Message: err.Error(),
+		Node:    globalMinioAddr,
+	}
+	if e, ok := err.(*Error); ok {
+		rpcErr.Stack = e.Trace()
+	}
+	data, mErr := json.Marshal(rpcErr)
+	if mErr != nil {
+		return err
+	}
+	return errors.New(rpcErrorPrefix + string(data))
+}
+
+// encodeRPCError - converts the error pointed to by err with
+// toRPCError, meant to be deferred by RPC handlers.
+func encodeRPCError(err *error) {
+	*err = toRPCError(*err)
+}
+
+// fromRPCError - converts an error received from a RPC call back to
+// the sentinel error it was created from, or to a RPCError when the
+// error is not known.
+func fromRPCError(err error) error {
+	serverErr, ok := err.(rpc.ServerError)
+	if !ok || !strings.HasPrefix(string(serverErr), rpcErrorPrefix) {
+		return err
+	}
+	rpcErr := &RPCError{}
+	if jErr := json.Unmarshal([]byte(strings.TrimPrefix(string(serverErr), rpcErrorPrefix)), rpcErr); jErr != nil {
+		return err
+	}
+	if sentinel, ok := rpcSentinelErrors[rpcErr.Code]; ok {
+		return sentinel
+	}
+	return rpcErr
+}
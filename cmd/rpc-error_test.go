@@ -0,0 +1,118 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"errors"
+	"net/http/httptest"
+	"net/rpc"
+	"reflect"
+	"testing"
+)
+
+// Service used to test errors returned over RPC.
+type rpcErrorService struct{}
+
+func (rpcErrorService) Fail(args *string, reply *string) (err error) {
+	defer encodeRPCError(&err)
+
+	switch *args {
+	case "token":
+		return errInvalidToken
+	case "traced":
+		return traceError(errServerNotInitialized)
+	case "unknown":
+		return traceError(errors.New("unknown failure"))
+	}
+	return nil
+}
+
+// Tests errors crossing RPC calls are mapped back to sentinel errors.
+func TestRPCErrorCall(t *testing.T) {
+	server := rpc.NewServer()
+	if err := server.RegisterName("Error", rpcErrorService{}); err != nil {
+		t.Fatal(err)
+	}
+	ts := httptest.NewServer(newRPCHandler(server))
+	defer ts.Close()
+
+	client := newClient(ts.Listener.Addr().String(), "/", false, false, 0)
+	defer client.Close()
+
+	testCases := []struct {
+		args        string
+		expectedErr error
+	}{
+		{"", nil},
+		{"token", errInvalidToken},
+		{"traced", errServerNotInitialized},
+	}
+	for i, testCase := range testCases {
+		var reply string
+		if err := client.Call("Error.Fail", &testCase.args, &reply); err != testCase.expectedErr {
+			t.Errorf("Test %d: Expected error %v, got %v", i+1, testCase.expectedErr, err)
+		}
+	}
+
+	// Unknown errors carry the message and the remote stack.
+	var reply string
+	args := "unknown"
+	err := client.Call("Error.Fail", &args, &reply)
+	rpcErr, ok := err.(*RPCError)
+	if !ok {
+		t.Fatalf("Expected *RPCError, got %#v", err)
+	}
+	if rpcErr.Code != "" || rpcErr.Error() != "unknown failure" {
+		t.Errorf("Unexpected error %#v", rpcErr)
+	}
+	if len(rpcErr.Stack) == 0 {
+		t.Error("Expected remote stack trace to be sent")
+	}
+}
+
+// Tests conversion of errors to and from the RPC error envelope.
+func TestRPCErrorConversion(t *testing.T) {
+	if err := toRPCError(nil); err != nil {
+		t.Errorf("Expected nil, got %v", err)
+	}
+
+	globalMinioAddr = "node1:9000"
+	defer func() { globalMinioAddr = "" }()
+
+	err := fromRPCError(rpc.ServerError(toRPCError(errors.New("disk is on fire")).Error()))
+	expected := &RPCError{Message: "disk is on fire", Node: "node1:9000"}
+	if !reflect.DeepEqual(err, expected) {
+		t.Errorf("Expected %#v, got %#v", expected, err)
+	}
+
+	// Every sentinel error is mapped back to itself.
+	for code, sentinel := range rpcSentinelErrors {
+		if err = fromRPCError(rpc.ServerError(toRPCError(sentinel).Error())); err != sentinel {
+			t.Errorf("%s: Expected %v, got %v", code, sentinel, err)
+		}
+	}
+
+	// Errors from servers not sending the envelope are left untouched.
+	plainErr := rpc.ServerError(errFileNotFound.Error())
+	if err = fromRPCError(plainErr); err != plainErr {
+		t.Errorf("Expected %v, got %v", plainErr, err)
+	}
+	badErr := rpc.ServerError(rpcErrorPrefix + "{")
+	if err = fromRPCError(badErr); err != badErr {
+		t.Errorf("Expected %v, got %v", badErr, err)
+	}
+}
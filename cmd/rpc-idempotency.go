@@ -0,0 +1,106 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"encoding/json"
+	"sync"
+	"time"
+)
+
+// Results of calls made with an idempotency key are kept this long
+// for retries.
+const idempotencyKeyExpiry = time.Hour
+
+// idempotentCall - result of a call made with an idempotency key.
+type idempotentCall struct {
+	doneCh  chan struct{}
+	reply   []byte
+	err     error
+	endTime time.Time
+}
+
+// idempotentCalls - calls made with an idempotency key on this server,
+// keyed by method and key.
+type idempotentCalls struct {
+	mutex *sync.Mutex
+	calls map[string]*idempotentCall
+}
+
+// Variable holding the calls made with an idempotency key.
+var globalIdempotentCalls = newIdempotentCalls()
+
+// newIdempotentCalls - returns empty idempotent calls.
+func newIdempotentCalls() *idempotentCalls {
+	return &idempotentCalls{
+		mutex: &sync.Mutex{},
+		calls: make(map[string]*idempotentCall),
+	}
+}
+
+// Begin a call of method with the idempotency key. If the call was
+// already made, waits for it to end and returns true along with its
+// error, its reply is copied into reply. Otherwise the call is recorded
+// as in progress and End must be called once it is done. Calls without
+// a key are never recorded.
+func (r *idempotentCalls) Begin(method, key string, reply interface{}) (bool, error) {
+	if key == "" {
+		return false, nil
+	}
+	r.mutex.Lock()
+	now := time.Now().UTC()
+	for callKey, call := range r.calls {
+		if !call.endTime.IsZero() && now.Sub(call.endTime) > idempotencyKeyExpiry {
+			delete(r.calls, callKey)
+		}
+	}
+	call, ok := r.calls[method+"/"+key]
+	if !ok {
+		r.calls[method+"/"+key] = &idempotentCall{doneCh: make(chan struct{})}
+		r.mutex.Unlock()
+		return false, nil
+	}
+	r.mutex.Unlock()
+
+	// Retried while the first call is still running.
+	<-call.doneCh
+	if call.reply != nil {
+		if err := json.Unmarshal(call.reply, reply); err != nil {
+			return true, err
+		}
+	}
+	return true, call.err
+}
+
+// End the call of method with the idempotency key, saving its reply
+// and the error pointed to by err for retries. Meant to be deferred
+// by RPC handlers.
+func (r *idempotentCalls) End(method, key string, reply interface{}, err *error) {
+	if key == "" {
+		return
+	}
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	call, ok := r.calls[method+"/"+key]
+	if !ok {
+		return
+	}
+	call.reply, _ = json.Marshal(reply)
+	call.err = *err
+	call.endTime = time.Now().UTC()
+	close(call.doneCh)
+}
@@ -0,0 +1,69 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"testing"
+	"time"
+)
+
+// Tests retried calls get the result of the first call.
+func TestIdempotentCalls(t *testing.T) {
+	calls := newIdempotentCalls()
+
+	// Calls without a key are never recorded.
+	if replayed, _ := calls.Begin("Control.Test", "", &FreezeReply{}); replayed {
+		t.Fatal("Expected call without key not to be replayed")
+	}
+
+	reply := &FreezeReply{FreezeID: "freeze-1"}
+	if replayed, _ := calls.Begin("Control.Test", "key-1", reply); replayed {
+		t.Fatal("Expected first call not to be replayed")
+	}
+
+	// Retries wait for the first call to end.
+	replayedCh := make(chan *FreezeReply)
+	errCh := make(chan error)
+	go func() {
+		retryReply := &FreezeReply{}
+		replayed, err := calls.Begin("Control.Test", "key-1", retryReply)
+		if !replayed {
+			retryReply = nil
+		}
+		replayedCh <- retryReply
+		errCh <- err
+	}()
+	select {
+	case <-replayedCh:
+		t.Fatal("Expected retry to wait for the first call")
+	case <-time.After(10 * time.Millisecond):
+	}
+	err := errWritesFrozen
+	calls.End("Control.Test", "key-1", reply, &err)
+	retryReply := <-replayedCh
+	if retryReply == nil || retryReply.FreezeID != "freeze-1" {
+		t.Fatalf("Expected the reply of the first call, got %v", retryReply)
+	}
+	if err = <-errCh; err != errWritesFrozen {
+		t.Fatalf("Expected %v, got %v", errWritesFrozen, err)
+	}
+
+	// Keys are scoped by method.
+	if replayed, _ := calls.Begin("Control.Other", "key-1", &FreezeReply{}); replayed {
+		t.Fatal("Expected call of another method not to be replayed")
+	}
+}
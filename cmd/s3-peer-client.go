@@ -61,7 +61,10 @@ func initGlobalS3Peers(disks []string) {
 }
 
 func (s3p *s3Peers) GetPeers() []string {
-	return s3p.peers
+	// Take a read lock
+	s3p.mutex.RLock()
+	defer s3p.mutex.RUnlock()
+	return append([]string(nil), s3p.peers...)
 }
 
 func (s3p *s3Peers) GetPeerClient(peer string) *AuthRPCClient {
@@ -83,14 +86,62 @@ func (s3p *s3Peers) InitS3PeerClient(peer string) {
 		_ = s3p.rpcClients[peer].Close()
 		delete(s3p.rpcClients, peer)
 	}
+	s3p.rpcClients[peer] = newS3PeerClient(peer)
+}
+
+// Returns a new RPC client for the peer at `host:port`.
+func newS3PeerClient(peer string) *AuthRPCClient {
 	authCfg := &authConfig{
 		accessKey:   serverConfig.GetCredential().AccessKeyID,
 		secretKey:   serverConfig.GetCredential().SecretAccessKey,
 		address:     peer,
 		path:        path.Join(reservedBucket, s3Path),
 		loginMethod: "S3.LoginHandler",
+		compress:    true,
+		timeout:     peerRPCTimeout,
+		retryDrain:  true,
+	}
+	return newAuthClient(authCfg)
+}
+
+// AddPeer - adds a peer (in `host:port` format) to the list of peers
+// and initializes its RPC connection, returns false if the peer
+// already exists.
+func (s3p *s3Peers) AddPeer(peer string) bool {
+	// Take a write lock
+	s3p.mutex.Lock()
+	defer s3p.mutex.Unlock()
+
+	if s3p.rpcClients[peer] != nil {
+		return false
+	}
+	s3p.rpcClients[peer] = newS3PeerClient(peer)
+	s3p.peers = append(s3p.peers, peer)
+	return true
+}
+
+// RemovePeer - removes a peer from the list of peers and closes its
+// RPC connection, returns false if the peer does not exist.
+func (s3p *s3Peers) RemovePeer(peer string) bool {
+	// Take a write lock
+	s3p.mutex.Lock()
+	defer s3p.mutex.Unlock()
+
+	client, ok := s3p.rpcClients[peer]
+	if !ok {
+		return false
+	}
+	_ = client.Close()
+	delete(s3p.rpcClients, peer)
+
+	var peers []string
+	for _, p := range s3p.peers {
+		if p != peer {
+			peers = append(peers, p)
+		}
 	}
-	s3p.rpcClients[peer] = newAuthClient(authCfg)
+	s3p.peers = peers
+	return true
 }
 
 func (s3p *s3Peers) Close() error {
@@ -137,7 +188,8 @@ func getAllPeers(disks []string) []string {
 // do not return/inspect the `reply` parameter in the RPC call. The
 // function attempts to connect to a peer only once, and returns a map
 // of peer address to error response. If the error is nil, it means
-// the RPC succeeded.
+// the RPC succeeded, or was queued as the peer is draining for a
+// restart.
 func (s3p *s3Peers) SendRPC(peers []string, method string, args interface {
 	SetToken(token string)
 	SetTimestamp(tstamp time.Time)
@@ -159,6 +211,9 @@ func (s3p *s3Peers) SendRPC(peers []string, method string, args interface {
 		if client == nil {
 			err = fmt.Errorf("Requested client was not initialized - %v",
 				target)
+		} else if globalDrainingPeers.Queue(target, method, args) {
+			// Sent once the peer is back from its restart.
+			err = nil
 		} else {
 			err = client.Call(method, args, reply)
 		}
@@ -222,3 +277,15 @@ func S3PeersUpdateBucketPolicy(bucket string, pCh policyChange) {
 		errorIf(err, "Error sending peer update bucket policy to %s - %v", peer, err)
 	}
 }
+
+// S3PeersUpdateBucketSettings - Sends update bucket settings request
+// to all peers, nil settings removes them. Currently we log an error
+// and continue.
+func S3PeersUpdateBucketSettings(bucket string, settings *bucketSettings) {
+	setBSPArgs := &SetBSPArgs{Bucket: bucket, Settings: settings}
+	peers := globalS3Peers.GetPeers()
+	errsMap := globalS3Peers.SendRPC(peers, "S3.SetBucketSettingsPeer", setBSPArgs)
+	for peer, err := range errsMap {
+		errorIf(err, "Error sending peer update bucket settings to %s - %v", peer, err)
+	}
+}
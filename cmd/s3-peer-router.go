@@ -42,6 +42,6 @@ func registerS3PeerRPCRouter(mux *router.Router) error {
 	}
 
 	s3PeerRouter := mux.NewRoute().PathPrefix(reservedBucket).Subrouter()
-	s3PeerRouter.Path(s3Path).Handler(s3PeerRPCServer)
+	s3PeerRouter.Path(s3Path).Handler(newRPCHandler(s3PeerRPCServer))
 	return nil
 }
@@ -21,7 +21,9 @@ import (
 	"time"
 )
 
-func (s3 *s3PeerAPIHandlers) LoginHandler(args *RPCLoginArgs, reply *RPCLoginReply) error {
+func (s3 *s3PeerAPIHandlers) LoginHandler(args *RPCLoginArgs, reply *RPCLoginReply) (err error) {
+	defer encodeRPCError(&err)
+
 	jwt, err := newJWT(defaultInterNodeJWTExpiry)
 	if err != nil {
 		return err
@@ -29,7 +31,7 @@ func (s3 *s3PeerAPIHandlers) LoginHandler(args *RPCLoginArgs, reply *RPCLoginRep
 	if err = jwt.Authenticate(args.Username, args.Password); err != nil {
 		return err
 	}
-	token, err := jwt.GenerateToken(args.Username)
+	token, err := jwt.GenerateToken(args.Username, jwtAudienceInterNode)
 	if err != nil {
 		return err
 	}
@@ -51,9 +53,11 @@ type SetBNPArgs struct {
 	NCfg *notificationConfig
 }
 
-func (s3 *s3PeerAPIHandlers) SetBucketNotificationPeer(args *SetBNPArgs, reply *GenericReply) error {
+func (s3 *s3PeerAPIHandlers) SetBucketNotificationPeer(args *SetBNPArgs, reply *GenericReply) (err error) {
+	defer encodeRPCError(&err)
+
 	// check auth
-	if !isRPCTokenValid(args.Token) {
+	if !isRPCTokenValid(args.Token, jwtAudienceInterNode) {
 		return errInvalidToken
 	}
 
@@ -80,9 +84,11 @@ type SetBLPArgs struct {
 	LCfg []listenerConfig
 }
 
-func (s3 *s3PeerAPIHandlers) SetBucketListenerPeer(args SetBLPArgs, reply *GenericReply) error {
+func (s3 *s3PeerAPIHandlers) SetBucketListenerPeer(args SetBLPArgs, reply *GenericReply) (err error) {
+	defer encodeRPCError(&err)
+
 	// check auth
-	if !isRPCTokenValid(args.Token) {
+	if !isRPCTokenValid(args.Token, jwtAudienceInterNode) {
 		return errInvalidToken
 	}
 
@@ -96,6 +102,41 @@ func (s3 *s3PeerAPIHandlers) SetBucketListenerPeer(args SetBLPArgs, reply *Gener
 	return globalEventNotifier.SetBucketListenerConfig(args.Bucket, args.LCfg)
 }
 
+// BucketConfigHashesArgs - Arguments collection for
+// BucketConfigHashesPeer RPC call
+type BucketConfigHashesArgs struct {
+	// For Auth
+	GenericArgs
+
+	Buckets []string
+}
+
+// BucketConfigHashesReply - Reply of BucketConfigHashesPeer RPC call
+type BucketConfigHashesReply struct {
+	// Hashes of the in-memory configs by bucket.
+	Hashes map[string]bucketConfigHashes
+}
+
+// return hashes of the in-memory configs of buckets, compared with
+// the stored configs by the bucket owner node.
+func (s3 *s3PeerAPIHandlers) BucketConfigHashesPeer(args *BucketConfigHashesArgs, reply *BucketConfigHashesReply) (err error) {
+	defer encodeRPCError(&err)
+
+	// check auth
+	if !isRPCTokenValid(args.Token, jwtAudienceInterNode) {
+		return errInvalidToken
+	}
+
+	// check if object layer is available.
+	objAPI := s3.ObjectAPI()
+	if objAPI == nil {
+		return errServerNotInitialized
+	}
+
+	reply.Hashes, err = getLocalBucketConfigHashes(args.Buckets)
+	return err
+}
+
 // EventArgs - Arguments collection for Event RPC call
 type EventArgs struct {
 	// For Auth
@@ -109,9 +150,11 @@ type EventArgs struct {
 }
 
 // submit an event to the receiving server.
-func (s3 *s3PeerAPIHandlers) Event(args *EventArgs, reply *GenericReply) error {
+func (s3 *s3PeerAPIHandlers) Event(args *EventArgs, reply *GenericReply) (err error) {
+	defer encodeRPCError(&err)
+
 	// check auth
-	if !isRPCTokenValid(args.Token) {
+	if !isRPCTokenValid(args.Token, jwtAudienceInterNode) {
 		return errInvalidToken
 	}
 
@@ -136,9 +179,11 @@ type SetBPPArgs struct {
 }
 
 // tell receiving server to update a bucket policy
-func (s3 *s3PeerAPIHandlers) SetBucketPolicyPeer(args SetBPPArgs, reply *GenericReply) error {
+func (s3 *s3PeerAPIHandlers) SetBucketPolicyPeer(args SetBPPArgs, reply *GenericReply) (err error) {
+	defer encodeRPCError(&err)
+
 	// check auth
-	if !isRPCTokenValid(args.Token) {
+	if !isRPCTokenValid(args.Token, jwtAudienceInterNode) {
 		return errInvalidToken
 	}
 
@@ -155,3 +200,50 @@ func (s3 *s3PeerAPIHandlers) SetBucketPolicyPeer(args SetBPPArgs, reply *Generic
 
 	return globalBucketPolicies.SetBucketPolicy(args.Bucket, pCh)
 }
+
+// SetBSPArgs - Arguments collection for SetBucketSettingsPeer RPC call
+type SetBSPArgs struct {
+	// For Auth
+	GenericArgs
+
+	Bucket string
+
+	// Settings for the given bucket, nil if removed.
+	Settings *bucketSettings
+}
+
+// tell receiving server to update bucket settings
+func (s3 *s3PeerAPIHandlers) SetBucketSettingsPeer(args *SetBSPArgs, reply *GenericReply) (err error) {
+	defer encodeRPCError(&err)
+
+	// check auth
+	if !isRPCTokenValid(args.Token, jwtAudienceInterNode) {
+		return errInvalidToken
+	}
+
+	// check if object layer is available.
+	objAPI := s3.ObjectAPI()
+	if objAPI == nil {
+		return errServerNotInitialized
+	}
+
+	globalBucketSettings.SetBucketSettings(args.Bucket, args.Settings)
+	return nil
+}
+
+// tell receiving server a peer is about to restart, or is back
+func (s3 *s3PeerAPIHandlers) DrainPeer(args *DrainPeerArgs, reply *GenericReply) (err error) {
+	defer encodeRPCError(&err)
+
+	// check auth
+	if !isRPCTokenValid(args.Token, jwtAudienceInterNode) {
+		return errInvalidToken
+	}
+
+	if args.Draining {
+		globalDrainingPeers.Mark(args.Peer, args.GracePeriod)
+		return nil
+	}
+	go replayPeerCalls(args.Peer, globalDrainingPeers.Resume(args.Peer))
+	return nil
+}
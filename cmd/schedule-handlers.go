@@ -0,0 +1,87 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package cmd
+
+import "time"
+
+// ScheduleArgs - argument for Schedule RPC handler.
+type ScheduleArgs struct {
+	// Authentication token generated by Login.
+	GenericArgs
+
+	// Run background tasks regardless of the window for this long.
+	RunFor time.Duration
+
+	// Cancel a previous override, background tasks run only in the
+	// window again.
+	Reset bool
+}
+
+// ScheduleReply - reply by Schedule RPC handler.
+type ScheduleReply struct {
+	// Window background tasks run in, empty if they run always.
+	Window string
+
+	// Time until which the window is overridden, zero if it is not.
+	OverrideUntil time.Time
+
+	// Errors of the nodes which failed to apply the override, keyed
+	// by node.
+	Errors map[string]string
+}
+
+// ScheduleHandler - RPC control handler for `minio control schedule`,
+// overrides the window background tasks run in on all the reachable
+// nodes if args.Remote is set and returns the schedule of this node.
+func (c *controlAPIHandlers) ScheduleHandler(args *ScheduleArgs, reply *ScheduleReply) (err error) {
+	defer encodeRPCError(&err)
+
+	if !isRPCTokenValid(args.Token, jwtAudienceAdmin) {
+		return errInvalidToken
+	}
+	if args.RunFor < 0 || (args.RunFor > 0 && args.Reset) {
+		return errInvalidArgument
+	}
+	// Retries get the result of the first call.
+	if replayed, replayErr := globalIdempotentCalls.Begin("Control.ScheduleHandler", args.IdempotencyKey, reply); replayed {
+		return replayErr
+	}
+	defer globalIdempotentCalls.End("Control.ScheduleHandler", args.IdempotencyKey, reply, &err)
+
+	now := time.Now()
+	if args.RunFor > 0 {
+		globalBackgroundSchedule.Override(now.Add(args.RunFor))
+	} else if args.Reset {
+		globalBackgroundSchedule.Override(time.Time{})
+	}
+
+	if args.Remote && (args.RunFor > 0 || args.Reset) {
+		remoteControls := c.getRemoteControls()
+		remoteArgs := *args
+		remoteArgs.Remote = false
+		errsMap := callRemoteControls(remoteControls, "Control.ScheduleHandler", &remoteArgs, func(index int) interface{} {
+			return &ScheduleReply{}
+		})
+		for node, nodeErr := range errsMap {
+			if reply.Errors == nil {
+				reply.Errors = make(map[string]string)
+			}
+			reply.Errors[node] = nodeErr.Error()
+		}
+	}
+	reply.Window, reply.OverrideUntil = globalBackgroundSchedule.Status(now)
+	return nil
+}
@@ -0,0 +1,106 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	// Bounds of the secret key length, in characters.
+	secretKeyMinLength = 8
+	secretKeyMaxLength = 40
+
+	// Highest entropy a policy may require, generated secret keys
+	// are well above it.
+	secretKeyMaxEntropy = 128
+)
+
+// secretKeyPolicy - requirements new secret keys have to meet when
+// credentials are set or rotated.
+type secretKeyPolicy struct {
+	MinLength  int     // Minimum number of characters.
+	MinEntropy float64 // Minimum estimated entropy in bits, 0 disables the check.
+}
+
+// Default secret key policy, only enforcing the length bounds.
+var defaultSecretKeyPolicy = secretKeyPolicy{MinLength: secretKeyMinLength}
+
+// isValidSecretKey - validate secret key.
+func isValidSecretKey(secretKey string) bool {
+	length := utf8.RuneCountInString(secretKey)
+	return length >= secretKeyMinLength && length <= secretKeyMaxLength && !strings.ContainsRune(secretKey, '\n')
+}
+
+// getSecretKeyEntropy - estimates the entropy of a secret key in bits,
+// as its length times the Shannon entropy of its characters. Repeated
+// characters lower the estimate, random keys come close to
+// length * log2(length).
+func getSecretKeyEntropy(secretKey string) float64 {
+	counts := make(map[rune]int)
+	length := 0
+	for _, r := range secretKey {
+		counts[r]++
+		length++
+	}
+	var entropy float64
+	for _, count := range counts {
+		p := float64(count) / float64(length)
+		entropy -= p * math.Log2(p)
+	}
+	return entropy * float64(length)
+}
+
+// validate - returns an error describing why secretKey does not meet
+// the policy.
+func (policy secretKeyPolicy) validate(secretKey string) error {
+	if !isValidSecretKey(secretKey) {
+		return fmt.Errorf("Secret key should be %d to %d characters in length", secretKeyMinLength, secretKeyMaxLength)
+	}
+	if utf8.RuneCountInString(secretKey) < policy.MinLength {
+		return fmt.Errorf("Secret key should be at least %d characters in length", policy.MinLength)
+	}
+	if getSecretKeyEntropy(secretKey) < policy.MinEntropy {
+		return fmt.Errorf("Secret key is too weak, use a longer key with fewer repeated characters")
+	}
+	return nil
+}
+
+// parseSecretKeyPolicy - parses the minimum length and entropy of a
+// secret key policy, empty values are left to their defaults.
+func parseSecretKeyPolicy(minLength, minEntropy string) (secretKeyPolicy, error) {
+	policy := defaultSecretKeyPolicy
+	if minLength != "" {
+		length, err := strconv.Atoi(minLength)
+		if err != nil || length < secretKeyMinLength || length > secretKeyMaxLength {
+			return policy, errInvalidArgument
+		}
+		policy.MinLength = length
+	}
+	if minEntropy != "" {
+		entropy, err := strconv.ParseFloat(minEntropy, 64)
+		if err != nil || entropy < 0 || entropy > secretKeyMaxEntropy {
+			return policy, errInvalidArgument
+		}
+		policy.MinEntropy = entropy
+	}
+	return policy, nil
+}
@@ -0,0 +1,114 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+// Tests validating secret keys against secret key policies.
+func TestSecretKeyPolicyValidate(t *testing.T) {
+	testCases := []struct {
+		policy    secretKeyPolicy
+		secretKey string
+		shouldErr bool
+	}{
+		{defaultSecretKeyPolicy, "minio123", false},
+		{defaultSecretKeyPolicy, "aaaaaaaa", false},
+		{defaultSecretKeyPolicy, "minio12", true},
+		{defaultSecretKeyPolicy, strings.Repeat("a", 41), true},
+		{defaultSecretKeyPolicy, "minio\n123", true},
+		{secretKeyPolicy{MinLength: 12}, "minio1234567", false},
+		{secretKeyPolicy{MinLength: 12}, "minio123456", true},
+		{secretKeyPolicy{MinLength: 8, MinEntropy: 20}, "password", false},
+		{secretKeyPolicy{MinLength: 8, MinEntropy: 20}, "aaaaaaaaaaaaaaaa", true},
+		{secretKeyPolicy{MinLength: 8, MinEntropy: 64}, "password", true},
+		{secretKeyPolicy{MinLength: 8, MinEntropy: 64}, "Tr0ub4dor&3-correct-horse", false},
+	}
+	for i, testCase := range testCases {
+		err := testCase.policy.validate(testCase.secretKey)
+		if testCase.shouldErr != (err != nil) {
+			t.Errorf("Test %d: Expected error %v, got %v", i+1, testCase.shouldErr, err)
+		}
+	}
+}
+
+// Tests estimating the entropy of secret keys.
+func TestGetSecretKeyEntropy(t *testing.T) {
+	testCases := []struct {
+		secretKey string
+		entropy   float64
+	}{
+		{"", 0},
+		{"aaaaaaaa", 0},
+		{"abababab", 8},
+		{"abcdefgh", 24},
+		{"password", 22},
+	}
+	for i, testCase := range testCases {
+		if entropy := getSecretKeyEntropy(testCase.secretKey); math.Abs(entropy-testCase.entropy) > 1e-9 {
+			t.Errorf("Test %d: Expected entropy %f, got %f", i+1, testCase.entropy, entropy)
+		}
+	}
+}
+
+// Tests parsing secret key policies.
+func TestParseSecretKeyPolicy(t *testing.T) {
+	testCases := []struct {
+		minLength      string
+		minEntropy     string
+		expectedPolicy secretKeyPolicy
+		expectedErr    error
+	}{
+		{"", "", defaultSecretKeyPolicy, nil},
+		{"16", "", secretKeyPolicy{MinLength: 16}, nil},
+		{"", "64.5", secretKeyPolicy{MinLength: 8, MinEntropy: 64.5}, nil},
+		{"7", "", defaultSecretKeyPolicy, errInvalidArgument},
+		{"41", "", defaultSecretKeyPolicy, errInvalidArgument},
+		{"sixteen", "", defaultSecretKeyPolicy, errInvalidArgument},
+		{"", "-1", defaultSecretKeyPolicy, errInvalidArgument},
+		{"", "129", defaultSecretKeyPolicy, errInvalidArgument},
+	}
+	for i, testCase := range testCases {
+		policy, err := parseSecretKeyPolicy(testCase.minLength, testCase.minEntropy)
+		if err != testCase.expectedErr {
+			t.Errorf("Test %d: Expected error %v, got %v", i+1, testCase.expectedErr, err)
+		}
+		if err == nil && policy != testCase.expectedPolicy {
+			t.Errorf("Test %d: Expected policy %v, got %v", i+1, testCase.expectedPolicy, policy)
+		}
+	}
+}
+
+// Tests generated credentials meet the strictest secret key policy.
+func TestGenAccessKeysPolicy(t *testing.T) {
+	savedPolicy := globalSecretKeyPolicy
+	defer func() { globalSecretKeyPolicy = savedPolicy }()
+	globalSecretKeyPolicy = secretKeyPolicy{MinLength: secretKeyMaxLength, MinEntropy: secretKeyMaxEntropy}
+
+	for i := 0; i < 100; i++ {
+		cred, err := genAccessKeys()
+		if err != nil {
+			t.Fatalf("Unable to generate credentials, %v", err)
+		}
+		if err = globalSecretKeyPolicy.validate(cred.SecretAccessKey); err != nil {
+			t.Fatalf("Generated secret key %s does not meet the policy, %v", cred.SecretAccessKey, err)
+		}
+	}
+}
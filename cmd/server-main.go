@@ -21,6 +21,8 @@ import (
 	"net"
 	"net/http"
 	"os"
+	"path"
+	"regexp"
 	"strconv"
 	"strings"
 	"time"
@@ -62,9 +64,77 @@ ENVIRONMENT VARIABLES:
   CACHING:
      MINIO_CACHE_SIZE: Set total cache size in NN[GB|MB|KB]. Defaults to 8GB.
      MINIO_CACHE_EXPIRY: Set cache expiration duration in NN[h|m|s]. Defaults to 72 hours.
+     MINIO_CACHE_MAX_OBJECT_SIZE: Set size in NN[GB|MB|KB] of the largest object saved in the cache. Defaults to no limit.
+     MINIO_CACHE_BUCKETS: Set comma separated list of buckets whose objects are saved in the cache. Defaults to all buckets.
+
+  BROWSER:
+     MINIO_BROWSER: Set to 'off' to disable the web browser. Defaults to 'on'.
+     MINIO_BROWSER_PREFIX: Set URL path the web browser is served from. Defaults to '/minio'.
+     MINIO_PRESIGNED_MAX_EXPIRY: Set maximum duration in NN[h|m|s] of presigned URLs handed out by the web browser. Defaults to 168h.
+
+  STORAGE:
+     MINIO_DISK_HIGH_WATERMARK: Set percentage of disk space and inodes beyond which writes are rejected. Defaults to only keeping 1GiB and 5% of inodes free.
+     MINIO_READ_QUORUM: Set number of disks required for reads, between N/2 and N disks. Defaults to N/2.
+     MINIO_WRITE_QUORUM: Set number of disks required for writes, between N/2+1 and N disks. Defaults to N/2+1.
+     MINIO_DURABILITY: Set to 'sync' or 'batch' to commit metadata writes to disks before acknowledging them. Defaults to 'off'.
+     MINIO_DIRECT_IO: Set to 'on' to write large object data with direct IO, bypassing the page cache. Defaults to 'off'.
+     MINIO_DIRECT_IO_MIN_SIZE: Set size in NN[GB|MB|KB] of object data written through the page cache before direct IO is used. Defaults to 4MB.
+
+  BUCKETS:
+     MINIO_MAX_BUCKETS: Set maximum number of buckets of this deployment. Defaults to no limit.
+     MINIO_BUCKET_NAME_PATTERN: Set regular expression names of new buckets have to match as a whole.
+     MINIO_BUCKET_NAME_PREFIX: Set prefix names of new buckets have to start with.
+
+  FEDERATION:
+     MINIO_FEDERATION_ENDPOINT: Set public URL of this cluster to share one bucket namespace with other clusters.
+     MINIO_FEDERATION_COORDINATOR: Set URL of a node of the cluster recording bucket owners. Defaults to this cluster.
+     MINIO_FEDERATION_MODE: Set to 'proxy' to proxy requests for buckets of other clusters. Defaults to 'redirect'.
+
+  ETCD:
+     MINIO_ETCD_ENDPOINTS: Set comma separated URLs of etcd to share config.json and federated bucket owners through etcd.
+     MINIO_ETCD_PREFIX: Set prefix of the keys stored in etcd. Defaults to '/minio'.
+
+  BACKGROUND:
+     MINIO_BACKGROUND_WINDOW: Set daily window in HH:MM-HH:MM local time in which background cleanup and purges run. Defaults to always.
+     MINIO_CONFIG_REPAIR: Set to 'on' to send stored bucket configs again to peers holding different ones. Defaults to 'off'.
+
+  ENCRYPTION:
+     MINIO_SSE_MASTER_KEY: Set hex encoded 256 bit master key to enable server side encryption of objects.
+     MINIO_SSE_OLD_MASTER_KEYS: Set comma separated previous master keys, to read objects until 'minio control rotate-keys' re-seals their keys.
+
+  EVENTS:
+     MINIO_EVENT_REVERSE_DNS: Set to 'on' to add the host name of source IPs to notification events. Defaults to 'off'.
+     MINIO_EVENT_GEOIP_DB: Set path of a MaxMind DB, e.g GeoLite2-Country.mmdb, to add the country of source IPs to notification events.
+
+  UPDATE:
+     MINIO_UPDATE_CHECK_INTERVAL: Set duration in NN[h|m|s] between checks for a new release, e.g 168h. Defaults to never.
+     MINIO_UPDATE_URL: Set mirror URL releases are checked for on. Defaults to https://dl.minio.io/server/minio/release.
+
+  PROXY:
+     MINIO_UPDATE_PROXY: Set http, https or socks5 proxy URL of update checks. Defaults to HTTP_PROXY/HTTPS_PROXY.
+     MINIO_NOTIFY_PROXY: Set http, https or socks5 proxy URL of notification targets and alert webhooks. Defaults to HTTP_PROXY/HTTPS_PROXY for HTTP targets, direct connections otherwise.
+
+  SHUTDOWN:
+     MINIO_SHUTDOWN_GRACE_PERIOD: Set duration in NN[h|m|s] to wait for in-flight requests on stop. Defaults to 5 seconds.
 
   SECURITY:
      MINIO_SECURE_CONSOLE: Set secure console to '0' to disable printing secret key. Defaults to '1'.
+     MINIO_SECRET_KEY_MIN_LENGTH: Set minimum length of new secret keys, between 8 and 40 characters. Defaults to 8.
+     MINIO_SECRET_KEY_MIN_ENTROPY: Set minimum estimated entropy in bits of new secret keys, up to 128. Defaults to 0.
+     MINIO_CONFIG_PASSPHRASE: Set passphrase encrypting the secrets in config.json, see 'minio config encrypt'.
+     MINIO_AUTH_LOCKOUT: Set to 'off' to disable locking out source IPs after repeated authentication failures, 'keys' to lock out access keys too. Defaults to 'on'.
+     MINIO_RESTRICTED_CRYPTO: Set to 'on' to restrict TLS cipher suites and use SHA-256 instead of MD5 for ETags. Defaults to 'off'.
+     MINIO_CA_BUNDLE: Set path of PEM encoded CA certificates trusted, besides the system roots, for outbound TLS connections.
+
+  COMPATIBILITY:
+     MINIO_STRICT_ETAG: Set to 'on' to always persist multipart ETags and their part md5sums. Defaults to 'off'.
+     MINIO_SIGNATURE_DEBUG: Set to 'on' to log the canonical request and string to sign on signature mismatch. Defaults to 'off'.
+
+  PERFORMANCE:
+     MINIO_SHA256_IMPL: Set to 'sha256-simd' or 'crypto/sha256' to override the SHA-256 implementation selected from the CPU features, see 'minio benchmark'.
+
+  TESTING:
+     MINIO_FAULT_INJECTION: Set to 'on' to allow 'minio control fault' to inject storage and RPC faults. Defaults to 'off'.
 
 EXAMPLES:
   1. Start minio server.
@@ -92,6 +162,9 @@ EXAMPLES:
       $ minio {{.Name}} 192.168.1.11:/mnt/export/ 192.168.1.12:/mnt/export/ \
           192.168.1.13:/mnt/export/ 192.168.1.14:/mnt/export/
 
+  7. Start minio server printing startup messages as JSON for provisioning tools.
+      $ minio {{.Name}} --json /home/shared
+
 `,
 }
 
@@ -147,6 +220,55 @@ func finalizeEndpoints(tls bool, apiServer *http.Server) (endPoints []string) {
 	return endPoints
 }
 
+// Valid browser URL prefix, one or more unreserved path elements.
+var validBrowserPrefix = regexp.MustCompile(`^(/[A-Za-z0-9._~-]+)+$`)
+
+// parseBrowserPrefix - validates and normalizes the URL prefix the
+// browser is served from to the form '/prefix'.
+func parseBrowserPrefix(prefix string) (string, error) {
+	prefix = path.Clean("/" + prefix)
+	if !validBrowserPrefix.MatchString(prefix) {
+		return "", errInvalidArgument
+	}
+	return prefix, nil
+}
+
+// browserPrefixBucket - returns the bucket name shadowed by the browser
+// URL prefix, its first element if that is a valid bucket name. The
+// reserved bucket is never served as a bucket and is not returned.
+func browserPrefixBucket(prefix string) string {
+	bucket := strings.SplitN(strings.TrimPrefix(prefix, slashSeparator), slashSeparator, 2)[0]
+	if bucket == strings.TrimPrefix(reservedBucket, slashSeparator) || !IsValidBucketName(bucket) {
+		return ""
+	}
+	return bucket
+}
+
+// parseDiskHighWatermark - parses disk high-water mark percentage.
+func parseDiskHighWatermark(highWatermark string) (int, error) {
+	percent, err := strconv.Atoi(highWatermark)
+	if err != nil {
+		return 0, err
+	}
+	if percent < 1 || percent > 100 {
+		return 0, errInvalidArgument
+	}
+	return percent, nil
+}
+
+// parseQuorum - parses number of disks required for a quorum, it is
+// validated against the number of disks by getXLQuorum.
+func parseQuorum(quorum string) (int, error) {
+	disks, err := strconv.Atoi(quorum)
+	if err != nil {
+		return 0, err
+	}
+	if disks < 1 {
+		return 0, errInvalidArgument
+	}
+	return disks, nil
+}
+
 // initServerConfig initialize server config.
 func initServerConfig(c *cli.Context) {
 	// Create certs path.
@@ -174,6 +296,179 @@ func initServerConfig(c *cli.Context) {
 		fatalIf(err, "Unable to convert MINIO_CACHE_EXPIRY=%s environment variable into its time.Duration value.", cacheExpiryStr)
 	}
 
+	// Fetch largest object size saved in cache from environment variable.
+	if maxObjectSizeStr := os.Getenv("MINIO_CACHE_MAX_OBJECT_SIZE"); maxObjectSizeStr != "" {
+		var maxObjectSize uint64
+		maxObjectSize, err = strconvBytes(maxObjectSizeStr)
+		fatalIf(err, "Unable to convert MINIO_CACHE_MAX_OBJECT_SIZE=%s environment variable into its integer value.", maxObjectSizeStr)
+		globalCacheMaxObjectSize = int64(maxObjectSize)
+	}
+
+	// Fetch buckets saved in cache from environment variable.
+	if cacheBuckets := os.Getenv("MINIO_CACHE_BUCKETS"); cacheBuckets != "" {
+		globalCacheBuckets, err = parseCacheBuckets(cacheBuckets)
+		fatalIf(err, "Invalid MINIO_CACHE_BUCKETS=%s environment variable.", cacheBuckets)
+	}
+
+	// Fetch shutdown grace period from environment variable.
+	if gracePeriodStr := os.Getenv("MINIO_SHUTDOWN_GRACE_PERIOD"); gracePeriodStr != "" {
+		// We need to parse grace period to its time.Duration value.
+		globalShutdownGracePeriod, err = time.ParseDuration(gracePeriodStr)
+		fatalIf(err, "Unable to convert MINIO_SHUTDOWN_GRACE_PERIOD=%s environment variable into its time.Duration value.", gracePeriodStr)
+	}
+
+	// Fetch interval of update checks from environment variable.
+	if intervalStr := os.Getenv("MINIO_UPDATE_CHECK_INTERVAL"); intervalStr != "" {
+		globalUpdateCheckInterval, err = time.ParseDuration(intervalStr)
+		fatalIf(err, "Unable to convert MINIO_UPDATE_CHECK_INTERVAL=%s environment variable into its time.Duration value.", intervalStr)
+		if globalUpdateCheckInterval < time.Hour {
+			fatalIf(errInvalidArgument, "MINIO_UPDATE_CHECK_INTERVAL=%s must be at least one hour.", intervalStr)
+		}
+	}
+	if mirror := os.Getenv("MINIO_UPDATE_URL"); mirror != "" {
+		channel, err := updateChannels["stable"].onEnvMirror()
+		fatalIf(err, "Invalid MINIO_UPDATE_URL=%s environment variable.", mirror)
+		globalUpdateNotifier = newUpdateNotifier(channel)
+	}
+
+	// Fetch window background tasks run in from environment variable.
+	if window := os.Getenv("MINIO_BACKGROUND_WINDOW"); window != "" {
+		backgroundWindow, err := parseBackgroundWindow(window)
+		fatalIf(err, "Invalid MINIO_BACKGROUND_WINDOW=%s environment variable.", window)
+		globalBackgroundSchedule.SetWindow(backgroundWindow)
+	}
+
+	// Enable or disable minio browser from environment variable.
+	globalBrowserEnabled = !strings.EqualFold(os.Getenv("MINIO_BROWSER"), "off")
+
+	// Fetch browser URL prefix from environment variable.
+	if browserPrefix := os.Getenv("MINIO_BROWSER_PREFIX"); browserPrefix != "" {
+		globalBrowserPrefix, err = parseBrowserPrefix(browserPrefix)
+		fatalIf(err, "Invalid MINIO_BROWSER_PREFIX=%s environment variable.", browserPrefix)
+	}
+
+	// Fetch maximum expiry of presigned URLs from environment variable.
+	if maxExpiry := os.Getenv("MINIO_PRESIGNED_MAX_EXPIRY"); maxExpiry != "" {
+		globalPresignedMaxExpiry, err = parsePresignedMaxExpiry(maxExpiry)
+		fatalIf(err, "Invalid MINIO_PRESIGNED_MAX_EXPIRY=%s environment variable.", maxExpiry)
+	}
+
+	// Enable signature mismatch debugging from environment variable.
+	globalSignatureDebug = strings.EqualFold(os.Getenv("MINIO_SIGNATURE_DEBUG"), "on")
+
+	// Enable or disable authentication lockouts from environment variable.
+	authLockout := os.Getenv("MINIO_AUTH_LOCKOUT")
+	globalAuthLockoutEnabled, globalAuthLockoutKeys, err = parseAuthLockout(authLockout)
+	fatalIf(err, "Invalid MINIO_AUTH_LOCKOUT=%s environment variable.", authLockout)
+
+	// Enable restricted crypto mode from environment variable, it
+	// cannot be disabled in builds with the fips tag.
+	globalRestrictedCrypto = restrictedCryptoBuild || strings.EqualFold(os.Getenv("MINIO_RESTRICTED_CRYPTO"), "on")
+
+	// Enable fault injection for chaos testing from environment variable.
+	globalFaultInjection = strings.EqualFold(os.Getenv("MINIO_FAULT_INJECTION"), "on")
+
+	// Fetch disk high-water mark from environment variable.
+	if highWatermark := os.Getenv("MINIO_DISK_HIGH_WATERMARK"); highWatermark != "" {
+		globalDiskHighWatermark, err = parseDiskHighWatermark(highWatermark)
+		fatalIf(err, "Invalid MINIO_DISK_HIGH_WATERMARK=%s environment variable.", highWatermark)
+	}
+
+	// Fetch read and write quorum from environment variables.
+	if readQuorum := os.Getenv("MINIO_READ_QUORUM"); readQuorum != "" {
+		globalReadQuorum, err = parseQuorum(readQuorum)
+		fatalIf(err, "Invalid MINIO_READ_QUORUM=%s environment variable.", readQuorum)
+	}
+	if writeQuorum := os.Getenv("MINIO_WRITE_QUORUM"); writeQuorum != "" {
+		globalWriteQuorum, err = parseQuorum(writeQuorum)
+		fatalIf(err, "Invalid MINIO_WRITE_QUORUM=%s environment variable.", writeQuorum)
+	}
+
+	// Fetch durability mode of metadata writes from environment variable.
+	if mode := os.Getenv("MINIO_DURABILITY"); mode != "" {
+		globalDurabilityMode, err = parseDurabilityMode(mode)
+		fatalIf(err, "Invalid MINIO_DURABILITY=%s environment variable.", mode)
+		globalFsyncBatcher = newFsyncBatcher(globalDurabilityMode)
+	}
+
+	// Override the SHA-256 implementation selected from the CPU features.
+	if impl := os.Getenv("MINIO_SHA256_IMPL"); impl != "" {
+		globalSHA256Impl, err = parseSHA256Impl(impl, getCPUFeatures())
+		fatalIf(err, "Invalid MINIO_SHA256_IMPL=%s environment variable.", impl)
+	}
+
+	// Enable direct IO for large object data from environment variables.
+	globalDirectIO = strings.EqualFold(os.Getenv("MINIO_DIRECT_IO"), "on")
+	if minSize := os.Getenv("MINIO_DIRECT_IO_MIN_SIZE"); minSize != "" {
+		var size uint64
+		size, err = strconvBytes(minSize)
+		fatalIf(err, "Unable to convert MINIO_DIRECT_IO_MIN_SIZE=%s environment variable into its integer value.", minSize)
+		globalDirectIOMinSize = int64(size)
+	}
+
+	// Fetch bucket creation limits from environment variables.
+	globalBucketCreationPolicy, err = parseBucketCreationPolicy(os.Getenv("MINIO_MAX_BUCKETS"), os.Getenv("MINIO_BUCKET_NAME_PATTERN"), os.Getenv("MINIO_BUCKET_NAME_PREFIX"))
+	fatalIf(err, "Invalid MINIO_MAX_BUCKETS, MINIO_BUCKET_NAME_PATTERN or MINIO_BUCKET_NAME_PREFIX environment variables.")
+
+	// The browser is routed before buckets, buckets named after the
+	// browser URL prefix would not be reachable over S3.
+	if globalBrowserEnabled {
+		globalBucketCreationPolicy.ReservedName = browserPrefixBucket(globalBrowserPrefix)
+	}
+
+	// Fetch federation of clusters from environment variables.
+	if endpoint := os.Getenv("MINIO_FEDERATION_ENDPOINT"); endpoint != "" {
+		globalFederationEndpoint, err = parseFederationURL(endpoint)
+		fatalIf(err, "Invalid MINIO_FEDERATION_ENDPOINT=%s environment variable.", endpoint)
+	}
+	if coordinator := os.Getenv("MINIO_FEDERATION_COORDINATOR"); coordinator != "" {
+		if globalFederationEndpoint == nil {
+			fatalIf(errInvalidArgument, "MINIO_FEDERATION_COORDINATOR requires MINIO_FEDERATION_ENDPOINT to be set.")
+		}
+		if globalEtcd != nil {
+			fatalIf(errInvalidArgument, "MINIO_FEDERATION_COORDINATOR cannot be set along with MINIO_ETCD_ENDPOINTS.")
+		}
+		globalFederationCoordinator, err = parseFederationURL(coordinator)
+		fatalIf(err, "Invalid MINIO_FEDERATION_COORDINATOR=%s environment variable.", coordinator)
+	}
+	if mode := os.Getenv("MINIO_FEDERATION_MODE"); mode != "" {
+		globalFederationMode, err = parseFederationMode(mode)
+		fatalIf(err, "Invalid MINIO_FEDERATION_MODE=%s environment variable.", mode)
+	}
+
+	// Enable strict AWS ETag parity from environment variable.
+	globalStrictETag = strings.EqualFold(os.Getenv("MINIO_STRICT_ETAG"), "on")
+
+	// Enable repair of diverged bucket configs from environment variable.
+	globalConfigRepair = strings.EqualFold(os.Getenv("MINIO_CONFIG_REPAIR"), "on")
+
+	// Enable server side encryption from environment variable.
+	if masterKey := os.Getenv("MINIO_SSE_MASTER_KEY"); masterKey != "" {
+		globalSSEMasterKey, err = parseSSEMasterKey(masterKey)
+		fatalIf(err, "Invalid MINIO_SSE_MASTER_KEY environment variable, it must be 64 hex characters.")
+	}
+	if oldMasterKeys := os.Getenv("MINIO_SSE_OLD_MASTER_KEYS"); oldMasterKeys != "" {
+		if globalSSEMasterKey == nil {
+			fatalIf(errInvalidArgument, "MINIO_SSE_OLD_MASTER_KEYS requires MINIO_SSE_MASTER_KEY to be set.")
+		}
+		for _, oldMasterKey := range strings.Split(oldMasterKeys, ",") {
+			masterKey, kErr := parseSSEMasterKey(oldMasterKey)
+			fatalIf(kErr, "Invalid MINIO_SSE_OLD_MASTER_KEYS environment variable, it must be comma separated keys of 64 hex characters.")
+			globalSSEOldMasterKeys = append(globalSSEOldMasterKeys, masterKey)
+		}
+	}
+
+	// Enrich notification events with the context of source IPs.
+	reverseDNS := strings.EqualFold(os.Getenv("MINIO_EVENT_REVERSE_DNS"), "on")
+	var geoDB *mmdbReader
+	if geoDBPath := os.Getenv("MINIO_EVENT_GEOIP_DB"); geoDBPath != "" {
+		geoDB, err = newMMDBReader(geoDBPath)
+		fatalIf(err, "Unable to load MINIO_EVENT_GEOIP_DB=%s geoip database.", geoDBPath)
+	}
+	if reverseDNS || geoDB != nil {
+		globalClientEnricher = newClientEnricher(reverseDNS, geoDB)
+	}
+
 	// When credentials inherited from the env, server cmd has to save them in the disk
 	if os.Getenv("MINIO_ACCESS_KEY") != "" && os.Getenv("MINIO_SECRET_KEY") != "" {
 		// Env credentials are already loaded in serverConfig, just save in the disk
@@ -247,6 +542,10 @@ func validateDisks(disks []string, ignoredDisks []string) []StorageAPI {
 		//  - ip:/mnt/disk1
 		err = checkNamingDisks(disks)
 		fatalIf(err, "Invalid disk arguments for server.")
+
+		// Validate if configured quorum is achievable with input disks.
+		_, _, err = getXLQuorum(len(disks))
+		fatalIf(err, "Invalid quorum for %d disks.", len(disks))
 	}
 	storageDisks, err := initStorageDisks(disks, ignoredDisks)
 	fatalIf(err, "Unable to initialize storage disks.")
@@ -291,9 +590,28 @@ func serverMain(c *cli.Context) {
 		cli.ShowCommandHelpAndExit(c, "server", 1)
 	}
 
+	// Print startup messages as JSON.
+	setJSONOutput(c)
+
 	// Server address.
 	serverAddr := c.String("address")
 
+	// Disks to be ignored in server init, to skip format healing.
+	ignoredDisks := strings.Split(c.String("ignore-disks"), ",")
+
+	// Disks to be used in server init.
+	disks := c.Args()
+
+	// Start the server.
+	startServer(c, serverAddr, disks, ignoredDisks)
+
+	// Waits on the server.
+	<-globalServiceDoneCh
+}
+
+// startServer - initializes the server on serverAddr with the given
+// disks and returns once the object layer is ready to serve requests.
+func startServer(c *cli.Context, serverAddr string, disks, ignoredDisks []string) {
 	// Check if requested port is available.
 	port := getPort(serverAddr)
 	fatalIf(checkPortAvailability(port), "Port unavailable %d", port)
@@ -301,12 +619,6 @@ func serverMain(c *cli.Context) {
 	// Saves port in a globally accessible value.
 	globalMinioPort = port
 
-	// Disks to be ignored in server init, to skip format healing.
-	ignoredDisks := strings.Split(c.String("ignore-disks"), ",")
-
-	// Disks to be used in server init.
-	disks := c.Args()
-
 	// Initialize server config.
 	initServerConfig(c)
 
@@ -328,6 +640,19 @@ func serverMain(c *cli.Context) {
 		isDistXL:     isDistributedSetup(disks),
 	}
 
+	// Join the federation of clusters if configured.
+	if globalFederationEndpoint != nil && globalEtcd != nil {
+		globalFederation = newEtcdFederation(globalFederationEndpoint, globalEtcd, globalFederationMode)
+		go globalFederation.startEtcdWatch(nil)
+	} else if globalFederationEndpoint != nil {
+		globalFederation = newFederation(globalFederationEndpoint, globalFederationCoordinator, globalFederationMode)
+	}
+
+	// Reload the config whenever another server changes it in etcd.
+	if globalEtcd != nil {
+		go startEtcdConfigWatch(nil)
+	}
+
 	// Configure server.
 	handler, err := configureServerHandler(srvConfig)
 	fatalIf(err, "Unable to configure one of server's RPC services.")
@@ -342,12 +667,14 @@ func serverMain(c *cli.Context) {
 
 	// Initialize a new HTTP server.
 	apiServer := NewServerMux(serverAddr, handler)
+	apiServer.GracefulTimeout = globalShutdownGracePeriod
 
 	// Fetch endpoints which we are going to serve from.
 	endPoints := finalizeEndpoints(tls, &apiServer.Server)
 
 	// Initialize local server address
 	globalMinioAddr = getLocalAddress(srvConfig)
+	globalMinioEndpoint = endPoints[0]
 
 	// Initialize S3 Peers inter-node communication
 	initGlobalS3Peers(disks)
@@ -368,17 +695,64 @@ func serverMain(c *cli.Context) {
 	err = waitForFormatDisks(firstDisk, endPoints[0], storageDisks)
 	fatalIf(err, "formatting storage disks failed")
 
+	// Track disk usage to reject writes early once disks fill up.
+	globalDiskUsage.setDisks(storageDisks)
+	go globalDiskUsage.monitor(diskUsageRefreshInterval)
+
+	// Evaluate alert rules on request metrics every minute.
+	go evaluateAlerts(alertEvaluationInterval)
+
 	// Once formatted, initialize object layer.
 	newObject, err := newObjectLayer(storageDisks)
 	fatalIf(err, "intializing object layer failed")
 
+	objAPI := newBucketCreationObjects(newThumbnailObjects(newRetentionObjects(newTrashObjects(newEncryptedObjects(newDedupObjects(newObject))))), globalBucketCreationPolicy)
 	globalObjLayerMutex.Lock()
-	globalObjectAPI = newObject
+	globalObjectAPI = objAPI
 	globalObjLayerMutex.Unlock()
 
+	// Refuse to shadow an existing bucket with the browser URL prefix.
+	if bucket := globalBucketCreationPolicy.ReservedName; bucket != "" {
+		if _, err = newObject.GetBucketInfo(bucket); err == nil {
+			fatalIf(errInvalidArgument, "MINIO_BROWSER_PREFIX=%s shadows the existing bucket %s.", globalBrowserPrefix, bucket)
+		}
+	}
+
+	// Claim buckets of this cluster with the federation coordinator.
+	errorIf(initFederation(newObject), "Unable to claim buckets with the federation coordinator.")
+
+	// Periodically cleanup orphaned tmp entries on the local disks.
+	// Abandoned multipart uploads are aborted only by the node with
+	// the first disk, all the other nodes would race with it. They are
+	// aborted through all the object layers to drop the object keys of
+	// encrypted uploads as well.
+	startTmpCleanup(objAPI, getLocalDisks(disks, storageDisks), firstDisk, tmpCleanupInterval, tmpCleanupExpiry)
+
+	// Periodically delete unreferenced deduplicated chunks.
+	startDedupGC(newObject, dedupGCInterval, dedupGCExpiry)
+
+	// Periodically purge expired objects from the trash of buckets.
+	startTrashPurge(newObject, trashPurgeInterval)
+
+	// Periodically purge abandoned resumable uploads.
+	startResumablePurge(newObject, resumablePurgeInterval, resumableSessionExpiry)
+
+	// Periodically abort multipart uploads abandoned by browsers.
+	startBrowserUploadPurge(newObject, browserUploadPurgeInterval, browserUploadExpiry)
+
+	// Periodically check all the peers hold the same bucket configs.
+	if srvConfig.isDistXL {
+		startConfigCheck(newObject, configCheckInterval, globalConfigRepair)
+
+		// Let the peers know this node is back in case it was restarted.
+		go announceDraining(false)
+	}
+
 	// Prints the formatted startup message once object layer is initialized.
 	printStartupMessage(endPoints)
 
-	// Waits on the server.
-	<-globalServiceDoneCh
+	// Periodically check for a new release if asked for.
+	if globalUpdateCheckInterval > 0 {
+		startUpdateNotifier(globalUpdateCheckInterval)
+	}
 }
@@ -25,6 +25,7 @@ import (
 	"runtime"
 	"testing"
 
+	"github.com/Sirupsen/logrus"
 	"github.com/minio/cli"
 )
 
@@ -293,6 +294,7 @@ func TestInitServerConfig(t *testing.T) {
 		{"MINIO_ACCESS_KEY", "abcd1"},
 		{"MINIO_SECRET_KEY", "abcd12345"},
 	}
+	defer func() { globalAuthLockoutEnabled = false }()
 	for i, test := range testCases {
 		tErr := os.Setenv(test.envVar, test.val)
 		if tErr != nil {
@@ -301,3 +303,135 @@ func TestInitServerConfig(t *testing.T) {
 		initServerConfig(ctx)
 	}
 }
+
+// Tests browser prefix validation and normalization.
+func TestParseBrowserPrefix(t *testing.T) {
+	testCases := []struct {
+		prefix         string
+		expectedPrefix string
+		expectedErr    error
+	}{
+		{"/console", "/console", nil},
+		{"console/", "/console", nil},
+		{"/tools//minio-ui/", "/tools/minio-ui", nil},
+		{"/", "", errInvalidArgument},
+		{"/..", "", errInvalidArgument},
+		{"/con sole", "", errInvalidArgument},
+		{"/console?x=y", "", errInvalidArgument},
+	}
+	for i, testCase := range testCases {
+		prefix, err := parseBrowserPrefix(testCase.prefix)
+		if err != testCase.expectedErr {
+			t.Errorf("Test %d: expected error %v, got %v", i+1, testCase.expectedErr, err)
+		}
+		if prefix != testCase.expectedPrefix {
+			t.Errorf("Test %d: expected prefix %s, got %s", i+1, testCase.expectedPrefix, prefix)
+		}
+	}
+}
+
+// Tests the bucket name shadowed by a browser prefix.
+func TestBrowserPrefixBucket(t *testing.T) {
+	testCases := []struct {
+		prefix string
+		bucket string
+	}{
+		{"/console", "console"},
+		{"/storage/console", "storage"},
+		{reservedBucket, ""},
+		{reservedBucket + "/console", ""},
+		{"/ui", ""},
+		{"/Console", ""},
+		{"/_console/ui", ""},
+	}
+	for i, testCase := range testCases {
+		if bucket := browserPrefixBucket(testCase.prefix); bucket != testCase.bucket {
+			t.Errorf("Test %d: expected bucket %q, got %q", i+1, testCase.bucket, bucket)
+		}
+	}
+}
+
+func TestParseDiskHighWatermark(t *testing.T) {
+	testCases := []struct {
+		highWatermark string
+		expected      int
+		success       bool
+	}{
+		{"90", 90, true},
+		{"100", 100, true},
+		{"0", 0, false},
+		{"101", 0, false},
+		{"90%", 0, false},
+	}
+	for i, testCase := range testCases {
+		percent, err := parseDiskHighWatermark(testCase.highWatermark)
+		if testCase.success != (err == nil) {
+			t.Errorf("Test %d: expected success %v, got error %v", i+1, testCase.success, err)
+		}
+		if percent != testCase.expected {
+			t.Errorf("Test %d: expected %d, got %d", i+1, testCase.expected, percent)
+		}
+	}
+}
+
+func TestParseQuorum(t *testing.T) {
+	testCases := []struct {
+		quorum   string
+		expected int
+		success  bool
+	}{
+		{"10", 10, true},
+		{"1", 1, true},
+		{"0", 0, false},
+		{"-1", 0, false},
+		{"N/2+2", 0, false},
+	}
+	for i, testCase := range testCases {
+		disks, err := parseQuorum(testCase.quorum)
+		if testCase.success != (err == nil) {
+			t.Errorf("Test %d: expected success %v, got error %v", i+1, testCase.success, err)
+		}
+		if disks != testCase.expected {
+			t.Errorf("Test %d: expected %d, got %d", i+1, testCase.expected, disks)
+		}
+	}
+}
+
+// Validates --json given before or after the command name.
+func TestSetJSONOutput(t *testing.T) {
+	defer func(formatter logrus.Formatter) {
+		globalJSON = false
+		log.Formatter = formatter
+	}(log.Formatter)
+
+	testCases := []struct {
+		globalArgs []string
+		args       []string
+		json       bool
+	}{
+		{nil, []string{"/mnt/export"}, false},
+		{[]string{"--json"}, []string{"/mnt/export"}, true},
+		{nil, []string{"--json", "/mnt/export"}, true},
+	}
+	for i, testCase := range testCases {
+		globalJSON = false
+		log.Formatter = new(logrus.TextFormatter)
+		globalSet := flag.NewFlagSet("minio", 0)
+		globalSet.Bool("json", false, "")
+		set := flag.NewFlagSet("server", 0)
+		set.Bool("json", false, "")
+		if err := globalSet.Parse(testCase.globalArgs); err != nil {
+			t.Fatal(err)
+		}
+		if err := set.Parse(testCase.args); err != nil {
+			t.Fatal(err)
+		}
+		setJSONOutput(cli.NewContext(cli.NewApp(), set, globalSet))
+		if globalJSON != testCase.json {
+			t.Errorf("Test %d: Expected JSON output %t, got %t", i+1, testCase.json, globalJSON)
+		}
+		if _, ok := log.Formatter.(*logrus.JSONFormatter); ok != testCase.json {
+			t.Errorf("Test %d: Expected JSON formatted errors %t, got %t", i+1, testCase.json, ok)
+		}
+	}
+}
@@ -203,7 +203,7 @@ func NewServerMux(addr string, handler http.Handler) *ServerMux {
 // ability to redirect http requests to the correct HTTPS url if the client
 // mistakenly initiates a http connection over the https port
 func (m *ServerMux) ListenAndServeTLS(certFile, keyFile string) (err error) {
-	config := &tls.Config{} // Always instantiate.
+	config := newTLSConfig() // Always instantiate.
 	if config.NextProtos == nil {
 		config.NextProtos = []string{"http/1.1", "h2"}
 	}
@@ -213,6 +213,14 @@ func (m *ServerMux) ListenAndServeTLS(certFile, keyFile string) (err error) {
 		return err
 	}
 
+	// Rotate session ticket keys, clients resume sessions across
+	// connections until their ticket key is dropped.
+	ticketKeys := &tlsTicketKeys{config: config}
+	if err = ticketKeys.rotate(); err != nil {
+		return err
+	}
+	go ticketKeys.startTicketKeyRotation(tlsTicketKeyRotation)
+
 	go m.handleServiceSignals()
 
 	listener, err := net.Listen("tcp", m.Server.Addr)
@@ -226,8 +234,8 @@ func (m *ServerMux) ListenAndServeTLS(certFile, keyFile string) (err error) {
 	m.listener = listenerMux
 	m.mu.Unlock()
 
-	err = http.Serve(listenerMux,
-		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	tlsServer := &http.Server{
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			// We reach here when ListenerMux.ConnMux is not wrapped with tls.Server
 			if r.TLS == nil {
 				u := url.URL{
@@ -245,7 +253,10 @@ func (m *ServerMux) ListenAndServeTLS(certFile, keyFile string) (err error) {
 				m.Server.Handler.ServeHTTP(w, r)
 			}
 		}),
-	)
+		// Count TLS handshakes as connections serve their first request.
+		ConnState: globalTLSStats.connState,
+	}
+	err = tlsServer.Serve(listenerMux)
 	if nerr, ok := err.(*net.OpError); ok {
 		if nerr.Op == "accept" && nerr.Net == "tcp" {
 			return nil
@@ -282,6 +293,7 @@ func (m *ServerMux) ListenAndServe() error {
 func (m *ServerMux) Close() error {
 	m.mu.Lock()
 	if m.closed {
+		m.mu.Unlock()
 		return errors.New("Server has been closed")
 	}
 	// Closed completely.
@@ -303,6 +315,8 @@ func (m *ServerMux) Close() error {
 
 	// If the GracefulTimeout happens then forcefully close all connections
 	t := time.AfterFunc(m.GracefulTimeout, func() {
+		m.mu.Lock()
+		defer m.mu.Unlock()
 		for c := range m.conns {
 			c.Close()
 		}
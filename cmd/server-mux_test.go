@@ -149,6 +149,11 @@ func TestServerCloseBlocking(t *testing.T) {
 		t.Fatal("Should have 0 connections")
 	}
 	m.mu.Unlock()
+
+	// Closing again should fail without blocking.
+	if err = m.Close(); err == nil {
+		t.Fatal("Expected closing a closed server to fail")
+	}
 }
 
 func TestListenAndServePlain(t *testing.T) {
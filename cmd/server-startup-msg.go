@@ -21,6 +21,7 @@ import (
 	"fmt"
 	"os"
 	"runtime"
+	"sort"
 	"strings"
 	"time"
 
@@ -43,135 +44,210 @@ func getFormatStr(strLen int, padding int) string {
 	return "%" + formatStr
 }
 
-// Prints the formatted startup message.
-func printStartupMessage(endPoints []string) {
-	printServerCommonMsg(endPoints)
-	printCLIAccessMsg(endPoints[0])
-	printObjectAPIMsg()
+// storageInfoMsg - capacity and erasure parameters of the storage.
+type storageInfoMsg struct {
+	Backend string `json:"backend"`
+	Total   int64  `json:"total"`
+	Free    int64  `json:"free"`
+	// Following fields are only set for XL.
+	OnlineDisks  int `json:"onlineDisks,omitempty"`
+	OfflineDisks int `json:"offlineDisks,omitempty"`
+	ReadQuorum   int `json:"readQuorum,omitempty"`
+	WriteQuorum  int `json:"writeQuorum,omitempty"`
+	DataBlocks   int `json:"dataBlocks,omitempty"`
+	ParityBlocks int `json:"parityBlocks,omitempty"`
+	// Number of further disk failures reads can withstand.
+	TolerableFailures int `json:"tolerableFailures,omitempty"`
+}
 
-	objAPI := newObjectLayerFn()
-	if objAPI != nil {
-		printStorageInfo(objAPI.StorageInfo())
+// newStorageInfoMsg - returns the message of storageInfo.
+func newStorageInfoMsg(storageInfo StorageInfo) *storageInfoMsg {
+	msg := &storageInfoMsg{
+		Backend: "FS",
+		Total:   storageInfo.Total,
+		Free:    storageInfo.Free,
 	}
-
-	if isSSL() {
-		certs, err := readCertificateChain()
-		fatalIf(err, "Unable to read certificate chain.")
-		printCertificateMsg(certs)
+	if storageInfo.Backend.Type != XL {
+		return msg
 	}
+	msg.Backend = "XL"
+	msg.OnlineDisks = storageInfo.Backend.OnlineDisks
+	msg.OfflineDisks = storageInfo.Backend.OfflineDisks
+	msg.ReadQuorum = storageInfo.Backend.ReadQuorum
+	msg.WriteQuorum = storageInfo.Backend.WriteQuorum
+	// Objects are always erasure coded over all the disks, with
+	// half of them holding parity.
+	disks := msg.OnlineDisks + msg.OfflineDisks
+	msg.DataBlocks, msg.ParityBlocks = disks/2, disks/2
+	// Reads need read quorum, which may be configured above the default.
+	if failures := msg.OnlineDisks - msg.ReadQuorum; failures > 0 {
+		msg.TolerableFailures = failures
+	}
+	return msg
 }
 
-// Prints common server startup message. Prints credential, region and browser access.
-func printServerCommonMsg(endPoints []string) {
-	// Get saved credentials.
-	cred := serverConfig.GetCredential()
+// certificateMsg - certificate which expires soon.
+type certificateMsg struct {
+	CommonName string    `json:"commonName"`
+	NotAfter   time.Time `json:"notAfter"`
+}
+
+// Returns the certificates of the chain expiring within
+// globalMinioCertExpireWarnDays, from the root down.
+func getExpiringCertificates(certs []*x509.Certificate) []certificateMsg {
+	var expiring []certificateMsg
+	for i := len(certs) - 1; i >= 0; i-- {
+		cert := certs[i]
+		if cert.NotAfter.Before(time.Now().UTC().Add(globalMinioCertExpireWarnDays)) {
+			expiring = append(expiring, certificateMsg{cert.Subject.CommonName, cert.NotAfter})
+		}
+	}
+	return expiring
+}
 
-	// Get saved region.
-	region := serverConfig.GetRegion()
+// serverStartupMsg - startup banner of the server.
+type serverStartupMsg struct {
+	Endpoints []string `json:"endpoints"`
+	AccessKey string   `json:"accessKey"`
+	// Redacted if MINIO_SECURE_CONSOLE is 0.
+	SecretKey string   `json:"secretKey"`
+	Region    string   `json:"region"`
+	SQSARNs   []string `json:"sqsARNs"`
+	// Not set until the object layer is initialized.
+	Storage              *storageInfoMsg  `json:"storage,omitempty"`
+	ExpiringCertificates []certificateMsg `json:"expiringCertificates,omitempty"`
+}
 
-	endPointStr := strings.Join(endPoints, "  ")
-	// Colorize the message and print.
-	console.Println(colorBlue("\nEndpoint: ") + colorBold(fmt.Sprintf(getFormatStr(len(endPointStr), 1), endPointStr)))
-	console.Println(colorBlue("AccessKey: ") + colorBold(fmt.Sprintf("%s ", cred.AccessKeyID)))
-	secretKey := cred.SecretAccessKey
+// newServerStartupMsg - returns the startup banner of the server
+// listening on endPoints.
+func newServerStartupMsg(endPoints []string) serverStartupMsg {
+	cred := serverConfig.GetCredential()
+	msg := serverStartupMsg{
+		Endpoints: endPoints,
+		AccessKey: cred.AccessKeyID,
+		SecretKey: cred.SecretAccessKey,
+		Region:    serverConfig.GetRegion(),
+		SQSARNs:   []string{},
+	}
 	if os.Getenv("MINIO_SECURE_CONSOLE") == "0" {
-		secretKey = "*REDACTED*"
+		msg.SecretKey = redactedValue
 	}
-	console.Println(colorBlue("SecretKey: ") + colorBold(fmt.Sprintf("%s ", secretKey)))
-	console.Println(colorBlue("Region: ") + colorBold(fmt.Sprintf(getFormatStr(len(region), 3), region)))
-	printEventNotifiers()
-
-	console.Println(colorBlue("\nBrowser Access:"))
-	console.Println(fmt.Sprintf(getFormatStr(len(endPointStr), 3), endPointStr))
+	// In case initEventNotifier() was not done or failed.
+	if globalEventNotifier != nil {
+		for queueArn := range globalEventNotifier.external.targets {
+			msg.SQSARNs = append(msg.SQSARNs, queueArn)
+		}
+		sort.Strings(msg.SQSARNs)
+	}
+	objAPI := newObjectLayerFn()
+	if objAPI != nil {
+		msg.Storage = newStorageInfoMsg(objAPI.StorageInfo())
+	}
+	if isSSL() {
+		certs, err := readCertificateChain()
+		fatalIf(err, "Unable to read certificate chain.")
+		msg.ExpiringCertificates = getExpiringCertificates(certs)
+	}
+	return msg
 }
 
-// Prints bucket notification configurations.
-func printEventNotifiers() {
-	if globalEventNotifier == nil {
-		// In case initEventNotifier() was not done or failed.
-		return
+// String - formats the banner for the console. Prints credential,
+// region, browser, command line and object API access.
+func (m serverStartupMsg) String() string {
+	endPointStr := strings.Join(m.Endpoints, "  ")
+	lines := []string{
+		colorBlue("\nEndpoint: ") + colorBold(fmt.Sprintf(getFormatStr(len(endPointStr), 1), endPointStr)),
+		colorBlue("AccessKey: ") + colorBold(fmt.Sprintf("%s ", m.AccessKey)),
+		colorBlue("SecretKey: ") + colorBold(fmt.Sprintf("%s ", m.SecretKey)),
+		colorBlue("Region: ") + colorBold(fmt.Sprintf(getFormatStr(len(m.Region), 3), m.Region)),
 	}
+
+	// Bucket notification configurations.
 	arnMsg := colorBlue("SQS ARNs: ")
-	if len(globalEventNotifier.external.targets) == 0 {
+	if len(m.SQSARNs) == 0 {
 		arnMsg += colorBold(fmt.Sprintf(getFormatStr(len("<none>"), 1), "<none>"))
 	}
-	for queueArn := range globalEventNotifier.external.targets {
+	for _, queueArn := range m.SQSARNs {
 		arnMsg += colorBold(fmt.Sprintf(getFormatStr(len(queueArn), 1), queueArn))
 	}
-	console.Println(arnMsg)
-}
+	lines = append(lines, arnMsg)
 
-// Prints startup message for command line access. Prints link to our documentation
-// and custom platform specific message.
-func printCLIAccessMsg(endPoint string) {
-	// Get saved credentials.
-	cred := serverConfig.GetCredential()
+	lines = append(lines,
+		colorBlue("\nBrowser Access:"),
+		fmt.Sprintf(getFormatStr(len(endPointStr), 3), endPointStr))
 
 	// Configure 'mc', following block prints platform specific information for minio client.
-	console.Println(colorBlue("\nCommand-line Access: ") + mcQuickStartGuide)
-	secretKey := cred.SecretAccessKey
-	if os.Getenv("MINIO_SECURE_CONSOLE") == "0" {
-		secretKey = "*REDACTED*"
-	}
+	mcCommand := "mc"
 	if runtime.GOOS == "windows" {
-		mcMessage := fmt.Sprintf("$ mc.exe config host add myminio %s %s %s", endPoint, cred.AccessKeyID, secretKey)
-		console.Println(fmt.Sprintf(getFormatStr(len(mcMessage), 3), mcMessage))
-	} else {
-		mcMessage := fmt.Sprintf("$ mc config host add myminio %s %s %s", endPoint, cred.AccessKeyID, secretKey)
-		console.Println(fmt.Sprintf(getFormatStr(len(mcMessage), 3), mcMessage))
+		mcCommand = "mc.exe"
+	}
+	mcMessage := fmt.Sprintf("$ %s config host add myminio %s %s %s", mcCommand, m.Endpoints[0], m.AccessKey, m.SecretKey)
+	lines = append(lines,
+		colorBlue("\nCommand-line Access: ")+mcQuickStartGuide,
+		fmt.Sprintf(getFormatStr(len(mcMessage), 3), mcMessage))
+
+	// Object API access, links to our SDK documentation.
+	lines = append(lines,
+		colorBlue("\nObject API (Amazon S3 compatible):"),
+		colorBlue("   Go: ")+fmt.Sprintf(getFormatStr(len(goQuickStartGuide), 8), goQuickStartGuide),
+		colorBlue("   Java: ")+fmt.Sprintf(getFormatStr(len(javaQuickStartGuide), 6), javaQuickStartGuide),
+		colorBlue("   Python: ")+fmt.Sprintf(getFormatStr(len(pyQuickStartGuide), 4), pyQuickStartGuide),
+		colorBlue("   JavaScript: ")+jsQuickStartGuide)
+
+	if m.Storage != nil {
+		lines = append(lines, "\n"+m.Storage.String())
 	}
+	if len(m.ExpiringCertificates) > 0 {
+		lines = append(lines, formatCertificatesMsg(m.ExpiringCertificates))
+	}
+	return strings.Join(lines, "\n")
 }
 
-// Prints startup message for Object API acces, prints link to our SDK documentation.
-func printObjectAPIMsg() {
-	console.Println(colorBlue("\nObject API (Amazon S3 compatible):"))
-	console.Println(colorBlue("   Go: ") + fmt.Sprintf(getFormatStr(len(goQuickStartGuide), 8), goQuickStartGuide))
-	console.Println(colorBlue("   Java: ") + fmt.Sprintf(getFormatStr(len(javaQuickStartGuide), 6), javaQuickStartGuide))
-	console.Println(colorBlue("   Python: ") + fmt.Sprintf(getFormatStr(len(pyQuickStartGuide), 4), pyQuickStartGuide))
-	console.Println(colorBlue("   JavaScript: ") + jsQuickStartGuide)
+// JSON - formats the banner as a line of JSON.
+func (m serverStartupMsg) JSON() string {
+	return marshalStartupMsg(m)
 }
 
-// Get formatted disk/storage info message.
-func getStorageInfoMsg(storageInfo StorageInfo) string {
+// Prints the formatted startup message.
+func printStartupMessage(endPoints []string) {
+	console.Println(formatStartupMsg(newServerStartupMsg(endPoints)))
+}
+
+// String - formats disk/storage info for the console.
+func (m storageInfoMsg) String() string {
 	msg := fmt.Sprintf("%s %s Free, %s Total", colorBlue("Drive Capacity:"),
-		humanize.IBytes(uint64(storageInfo.Free)),
-		humanize.IBytes(uint64(storageInfo.Total)))
+		humanize.IBytes(uint64(m.Free)),
+		humanize.IBytes(uint64(m.Total)))
 	diskInfo := fmt.Sprintf(" %d Online, %d Offline. We can withstand [%d] more drive failure(s).",
-		storageInfo.Backend.OnlineDisks,
-		storageInfo.Backend.OfflineDisks,
-		storageInfo.Backend.ReadQuorum,
+		m.OnlineDisks,
+		m.OfflineDisks,
+		m.TolerableFailures,
 	)
-	if storageInfo.Backend.Type == XL {
+	if m.Backend == "XL" {
 		msg += colorBlue("\nStatus:") + fmt.Sprintf(getFormatStr(len(diskInfo), 8), diskInfo)
 	}
 	return msg
 }
 
-// Prints startup message of storage capacity and erasure information.
-func printStorageInfo(storageInfo StorageInfo) {
-	console.Println()
-	console.Println(getStorageInfoMsg(storageInfo))
+// Get formatted disk/storage info message.
+func getStorageInfoMsg(storageInfo StorageInfo) string {
+	return newStorageInfoMsg(storageInfo).String()
 }
 
-// Prints certificate expiry date warning
-func getCertificateChainMsg(certs []*x509.Certificate) string {
+// Formats the certificate expiry warning of expiring certificates.
+func formatCertificatesMsg(expiring []certificateMsg) string {
 	msg := colorBlue("\nCertificate expiry info:\n")
-	totalCerts := len(certs)
-	var expiringCerts int
-	for i := totalCerts - 1; i >= 0; i-- {
-		cert := certs[i]
-		if cert.NotAfter.Before(time.Now().UTC().Add(globalMinioCertExpireWarnDays)) {
-			expiringCerts++
-			msg += fmt.Sprintf(colorBold("#%d %s will expire on %s\n"), expiringCerts, cert.Subject.CommonName, cert.NotAfter)
-		}
-	}
-	if expiringCerts > 0 {
-		return msg
+	for i, cert := range expiring {
+		msg += fmt.Sprintf(colorBold("#%d %s will expire on %s\n"), i+1, cert.CommonName, cert.NotAfter)
 	}
-	return ""
+	return msg
 }
 
-// Prints the certificate expiry message.
-func printCertificateMsg(certs []*x509.Certificate) {
-	console.Println(getCertificateChainMsg(certs))
+// Prints certificate expiry date warning
+func getCertificateChainMsg(certs []*x509.Certificate) string {
+	expiring := getExpiringCertificates(certs)
+	if len(expiring) == 0 {
+		return ""
+	}
+	return formatCertificatesMsg(expiring)
 }
@@ -19,6 +19,7 @@ package cmd
 import (
 	"crypto/x509"
 	"crypto/x509/pkix"
+	"encoding/json"
 	"fmt"
 	"strings"
 	"testing"
@@ -92,3 +93,56 @@ func TestCertificateNotExpired(t *testing.T) {
 		t.Fatalf("Expected empty message was: %s", msg)
 	}
 }
+
+// Tests the startup banner as text and as JSON.
+func TestServerStartupMsg(t *testing.T) {
+	rootPath, err := newTestConfig("us-east-1")
+	if err != nil {
+		t.Fatal("Unable to initialize test config", err)
+	}
+	defer removeAll(rootPath)
+
+	msg := newServerStartupMsg([]string{"http://127.0.0.1:9000"})
+	msg.Storage = newStorageInfoMsg(StorageInfo{
+		Total: 1024 * 1024 * 1024 * 10,
+		Free:  1024 * 1024 * 1024 * 2,
+		Backend: struct {
+			Type         BackendType
+			OnlineDisks  int
+			OfflineDisks int
+			ReadQuorum   int
+			WriteQuorum  int
+		}{XL, 7, 1, 4, 5},
+	})
+
+	cred := serverConfig.GetCredential()
+	text := msg.String()
+	for _, expected := range []string{"http://127.0.0.1:9000", cred.AccessKeyID, "us-east-1", "7 Online, 1 Offline"} {
+		if !strings.Contains(text, expected) {
+			t.Fatalf("Expected %s in startup message, found: %s", expected, text)
+		}
+	}
+
+	var decoded serverStartupMsg
+	if err = json.Unmarshal([]byte(msg.JSON()), &decoded); err != nil {
+		t.Fatal("Unable to decode startup message", err)
+	}
+	if decoded.AccessKey != cred.AccessKeyID || decoded.Region != "us-east-1" || len(decoded.Endpoints) != 1 {
+		t.Fatalf("Unexpected startup message %#v", decoded)
+	}
+	expectedStorage := storageInfoMsg{
+		Backend:           "XL",
+		Total:             1024 * 1024 * 1024 * 10,
+		Free:              1024 * 1024 * 1024 * 2,
+		OnlineDisks:       7,
+		OfflineDisks:      1,
+		ReadQuorum:        4,
+		WriteQuorum:       5,
+		DataBlocks:        4,
+		ParityBlocks:      4,
+		TolerableFailures: 3,
+	}
+	if decoded.Storage == nil || *decoded.Storage != expectedStorage {
+		t.Fatalf("Expected storage %#v, got %#v", expectedStorage, decoded.Storage)
+	}
+}
@@ -1350,6 +1350,75 @@ func (s *TestSuiteCommon) TestListObjectsHandler(c *C) {
 
 }
 
+// TestListObjectsMetadata - lists objects along with their user
+// metadata with X-Minio-Extract.
+func (s *TestSuiteCommon) TestListObjectsMetadata(c *C) {
+	// generate a random bucket name.
+	bucketName := getRandomBucketName()
+	// HTTP request to create the bucket.
+	request, err := newTestSignedRequestV4("PUT", getMakeBucketURL(s.endPoint, bucketName),
+		0, nil, s.accessKey, s.secretKey)
+	c.Assert(err, IsNil)
+
+	client := http.Client{}
+	// execute the HTTP request to create bucket.
+	response, err := client.Do(request)
+	c.Assert(err, IsNil)
+	c.Assert(response.StatusCode, Equals, http.StatusOK)
+
+	buffer1 := bytes.NewReader([]byte("Hello World"))
+	request, err = newTestSignedRequestV4("PUT", getPutObjectURL(s.endPoint, bucketName, "bar"),
+		int64(buffer1.Len()), buffer1, s.accessKey, s.secretKey)
+	c.Assert(err, IsNil)
+	response, err = client.Do(request)
+	c.Assert(err, IsNil)
+	c.Assert(response.StatusCode, Equals, http.StatusOK)
+
+	// put an object with user metadata.
+	buffer2 := bytes.NewReader([]byte("Hello World"))
+	request, err = newTestRequest("PUT", getPutObjectURL(s.endPoint, bucketName, "baz"), int64(buffer2.Len()), buffer2)
+	c.Assert(err, IsNil)
+	request.Header.Set("X-Amz-Meta-Color", "red")
+	c.Assert(signRequestV4(request, s.accessKey, s.secretKey), IsNil)
+	response, err = client.Do(request)
+	c.Assert(err, IsNil)
+	c.Assert(response.StatusCode, Equals, http.StatusOK)
+
+	// list objects along with their user metadata, for both versions.
+	for _, listURL := range []string{getListObjectsV1URL(s.endPoint, bucketName, "1000"), getListObjectsV2URL(s.endPoint, bucketName, "1000", "")} {
+		request, err = newTestRequest("GET", listURL, 0, nil)
+		c.Assert(err, IsNil)
+		request.Header.Set(extractHeader, "metadata")
+		c.Assert(signRequestV4(request, s.accessKey, s.secretKey), IsNil)
+		response, err = client.Do(request)
+		c.Assert(err, IsNil)
+		c.Assert(response.StatusCode, Equals, http.StatusOK)
+
+		var listResponse ListObjectsResponse
+		c.Assert(xmlDecoder(response.Body, &listResponse, response.ContentLength), IsNil)
+		c.Assert(len(listResponse.Contents), Equals, 2)
+		c.Assert(listResponse.Contents[0].Key, Equals, "bar")
+		c.Assert(listResponse.Contents[1].Key, Equals, "baz")
+		c.Assert(listResponse.Contents[1].UserMetadata, NotNil)
+		var found bool
+		for _, entry := range listResponse.Contents[1].UserMetadata.Entries {
+			found = found || entry == MetadataEntry{"X-Amz-Meta-Color", "red"}
+		}
+		c.Assert(found, Equals, true)
+	}
+
+	// user metadata is not listed without X-Minio-Extract.
+	request, err = newTestSignedRequestV4("GET", getListObjectsV1URL(s.endPoint, bucketName, "1000"),
+		0, nil, s.accessKey, s.secretKey)
+	c.Assert(err, IsNil)
+	response, err = client.Do(request)
+	c.Assert(err, IsNil)
+	c.Assert(response.StatusCode, Equals, http.StatusOK)
+	getContent, err := ioutil.ReadAll(response.Body)
+	c.Assert(err, IsNil)
+	c.Assert(strings.Contains(string(getContent), "<UserMetadata>"), Equals, false)
+}
+
 // TestListObjectsHandlerErrors - Setting invalid parameters to List Objects
 // and then asserting the error response with the expected one.
 func (s *TestSuiteCommon) TestListObjectsHandlerErrors(c *C) {
@@ -20,7 +20,6 @@ import (
 	"encoding/xml"
 	"fmt"
 	"io/ioutil"
-	"net"
 	"net/http"
 
 	. "gopkg.in/check.v1"
@@ -102,17 +101,11 @@ func calculateStreamContentLength(dataLen, chunkSize int64) int64 {
 
 // Ask the kernel for a free open port.
 func getFreePort() int {
-	addr, err := net.ResolveTCPAddr("tcp", "localhost:0")
+	port, err := findFreePort()
 	if err != nil {
 		panic(err)
 	}
-
-	l, err := net.ListenTCP("tcp", addr)
-	if err != nil {
-		panic(err)
-	}
-	defer l.Close()
-	return l.Addr().(*net.TCPAddr).Port
+	return port
 }
 
 func verifyError(c *C, response *http.Response, code, description string, statusCode int) {
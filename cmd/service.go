@@ -19,6 +19,7 @@ package cmd
 import (
 	"os"
 	"os/exec"
+	"sync"
 	"syscall"
 )
 
@@ -50,6 +51,37 @@ func init() {
 	globalServiceSignalCh = make(chan serviceSignal, 1)
 }
 
+// Functions invoked upon stop or restart once all the in-flight
+// requests are drained, guarded by globalShutdownHooksMu.
+var (
+	globalShutdownHooksMu sync.Mutex
+	globalShutdownHooks   []cleanupOnExitFunc
+)
+
+// registerShutdownHook - registers a function to be invoked upon
+// stop or restart of the server.
+func registerShutdownHook(fn cleanupOnExitFunc) {
+	globalShutdownHooksMu.Lock()
+	defer globalShutdownHooksMu.Unlock()
+	globalShutdownHooks = append(globalShutdownHooks, fn)
+}
+
+// runShutdownHooks - invokes all the registered shutdown hooks in
+// the reverse order of their registration, returns the first error.
+func runShutdownHooks() (err error) {
+	globalShutdownHooksMu.Lock()
+	defer globalShutdownHooksMu.Unlock()
+	for i := len(globalShutdownHooks) - 1; i >= 0; i-- {
+		if hErr := globalShutdownHooks[i](); hErr != nil {
+			errorIf(hErr, "Unable to run shutdown hook.")
+			if err == nil {
+				err = hErr
+			}
+		}
+	}
+	return err
+}
+
 // restartProcess starts a new process passing it the active fd's. It
 // doesn't fork, but starts a new process using the same environment and
 // arguments as when it was originally started. This allows for a newly
@@ -98,17 +130,30 @@ func (m *ServerMux) handleServiceSignals() error {
 			case serviceStatus:
 				/// We don't do anything for this.
 			case serviceRestart:
+				// Let the peers retry and queue their calls while
+				// this node is away.
+				announceDraining(true)
+
+				// Drains all the in-flight requests.
 				if err := m.Close(); err != nil {
 					errorIf(err, "Unable to close server gracefully")
 				}
+				if err := runShutdownHooks(); err != nil {
+					errorIf(err, "Unable to run shutdown hooks.")
+				}
 				if err := restartProcess(); err != nil {
 					errorIf(err, "Unable to restart the server.")
 				}
 				runExitFn(nil)
 			case serviceStop:
+				// Drains all the in-flight requests, which also
+				// completes their pending event notifications.
 				if err := m.Close(); err != nil {
 					errorIf(err, "Unable to close server gracefully")
 				}
+				if err := runShutdownHooks(); err != nil {
+					errorIf(err, "Unable to run shutdown hooks.")
+				}
 				objAPI := newObjectLayerFn()
 				if objAPI == nil {
 					// Server not initialized yet, exit happily.
@@ -0,0 +1,58 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// Tests shutdown hooks are run in the reverse order of registration.
+func TestRunShutdownHooks(t *testing.T) {
+	globalShutdownHooksMu.Lock()
+	savedHooks := globalShutdownHooks
+	globalShutdownHooks = nil
+	globalShutdownHooksMu.Unlock()
+	defer func() {
+		globalShutdownHooksMu.Lock()
+		globalShutdownHooks = savedHooks
+		globalShutdownHooksMu.Unlock()
+	}()
+
+	var order []int
+	errHook := errors.New("hook failed")
+	registerShutdownHook(func() error {
+		order = append(order, 1)
+		return nil
+	})
+	registerShutdownHook(func() error {
+		order = append(order, 2)
+		return errHook
+	})
+	registerShutdownHook(func() error {
+		order = append(order, 3)
+		return nil
+	})
+
+	if err := runShutdownHooks(); err != errHook {
+		t.Fatalf("Expected %s, got %v", errHook, err)
+	}
+	if !reflect.DeepEqual(order, []int{3, 2, 1}) {
+		t.Fatalf("Expected hooks to run in reverse order, got %v", order)
+	}
+}
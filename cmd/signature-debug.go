@@ -0,0 +1,75 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/Sirupsen/logrus"
+)
+
+// Signed headers whose values are elided from signature debug logs.
+var signatureDebugElidedHeaders = []string{
+	"x-amz-security-token",
+}
+
+// Query parameters whose values are elided from signature debug logs,
+// presigned requests carry the session token in the query string.
+var signatureDebugElidedQueries = []string{
+	"x-amz-security-token",
+}
+
+// elideSignatureDebugSecrets - replaces values of sensitive headers and
+// query parameters in a canonical request or string to sign, one header
+// or canonical query string per line.
+func elideSignatureDebugSecrets(s string) string {
+	lines := strings.Split(s, "\n")
+	for i, line := range lines {
+		for _, header := range signatureDebugElidedHeaders {
+			if strings.HasPrefix(strings.ToLower(line), header+":") {
+				lines[i] = line[:len(header)+1] + "<elided>"
+			}
+		}
+		params := strings.Split(lines[i], "&")
+		for j, param := range params {
+			for _, query := range signatureDebugElidedQueries {
+				if strings.HasPrefix(strings.ToLower(param), query+"=") {
+					params[j] = param[:len(query)+1] + "<elided>"
+				}
+			}
+		}
+		lines[i] = strings.Join(params, "&")
+	}
+	return strings.Join(lines, "\n")
+}
+
+// logSignatureMismatch - logs what the server computed for a request
+// whose signature did not match, only if enabled by setting
+// MINIO_SIGNATURE_DEBUG=on. Signature V2 has no canonical request.
+func logSignatureMismatch(r *http.Request, canonicalRequest, stringToSign, signatureProvided string) {
+	if !globalSignatureDebug {
+		return
+	}
+	log.WithFields(logrus.Fields{
+		"method":            r.Method,
+		"path":              r.URL.Path,
+		"canonicalRequest":  elideSignatureDebugSecrets(canonicalRequest),
+		"stringToSign":      elideSignatureDebugSecrets(stringToSign),
+		"signatureProvided": signatureProvided,
+	}).Error("Signature does not match, see the server computed canonical request and string to sign.")
+}
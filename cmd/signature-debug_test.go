@@ -0,0 +1,70 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"bytes"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// Tests sensitive header values are elided.
+func TestElideSignatureDebugSecrets(t *testing.T) {
+	canonicalRequest := "GET\n/bucket/object\n\nhost:localhost:9000\nx-amz-date:20160919T000000Z\nx-amz-security-token:secret-token\n\nhost;x-amz-date;x-amz-security-token\nUNSIGNED-PAYLOAD"
+	expected := "GET\n/bucket/object\n\nhost:localhost:9000\nx-amz-date:20160919T000000Z\nx-amz-security-token:<elided>\n\nhost;x-amz-date;x-amz-security-token\nUNSIGNED-PAYLOAD"
+	if elided := elideSignatureDebugSecrets(canonicalRequest); elided != expected {
+		t.Fatalf("Expected %q, got %q", expected, elided)
+	}
+
+	// Presigned requests carry the session token in the query string.
+	canonicalRequest = "GET\n/bucket/object\nX-Amz-Algorithm=AWS4-HMAC-SHA256&X-Amz-Date=20160919T000000Z&X-Amz-Security-Token=secret-token&X-Amz-SignedHeaders=host\nhost:localhost:9000\n\nhost\nUNSIGNED-PAYLOAD"
+	expected = "GET\n/bucket/object\nX-Amz-Algorithm=AWS4-HMAC-SHA256&X-Amz-Date=20160919T000000Z&X-Amz-Security-Token=<elided>&X-Amz-SignedHeaders=host\nhost:localhost:9000\n\nhost\nUNSIGNED-PAYLOAD"
+	if elided := elideSignatureDebugSecrets(canonicalRequest); elided != expected {
+		t.Fatalf("Expected %q, got %q", expected, elided)
+	}
+}
+
+// Tests signature mismatches are only logged when enabled.
+func TestLogSignatureMismatch(t *testing.T) {
+	var buffer bytes.Buffer
+	savedOut := log.Out
+	log.Out = &buffer
+	defer func() {
+		log.Out = savedOut
+		globalSignatureDebug = false
+	}()
+
+	req, err := http.NewRequest("GET", "http://localhost:9000/bucket/object", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	globalSignatureDebug = false
+	logSignatureMismatch(req, "canonical-request", "string-to-sign", "signature")
+	if buffer.Len() != 0 {
+		t.Fatalf("Expected nothing to be logged, got %s", buffer.String())
+	}
+
+	globalSignatureDebug = true
+	logSignatureMismatch(req, "canonical-request", "string-to-sign", "signature")
+	for _, expected := range []string{"canonical-request", "string-to-sign", "signature", "/bucket/object"} {
+		if !strings.Contains(buffer.String(), expected) {
+			t.Fatalf("Expected %s to be logged, got %s", expected, buffer.String())
+		}
+	}
+}
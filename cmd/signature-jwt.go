@@ -37,10 +37,92 @@ const (
 	// Default JWT token for web handlers is one day.
 	defaultJWTExpiry time.Duration = time.Hour * 24
 
-	// Inter-node JWT token expiry is 100 years.
-	defaultInterNodeJWTExpiry time.Duration = time.Hour * 24 * 365 * 100
+	// Inter-node and admin JWT token expiry is one hour, RPC clients
+	// login again once expired.
+	defaultInterNodeJWTExpiry time.Duration = time.Hour
+
+	// Default scoped JWT token expiry is one hour.
+	defaultScopedJWTExpiry time.Duration = time.Hour
+
+	// Maximum scoped JWT token expiry is 7 days, same as presigned URLs.
+	maxScopedJWTExpiry time.Duration = time.Hour * 24 * 7
+
+	// Heal JWT tokens printed by the server expire in one hour.
+	defaultHealJWTExpiry time.Duration = time.Hour
+)
+
+// Claim holding the scope of a scoped JWT token.
+const jwtScopeClaim = "scope"
+
+// Audiences of JWT tokens, a token is only accepted by the handlers
+// of its audience.
+const (
+	jwtAudienceWeb       = "web"       // Browser.
+	jwtAudienceInterNode = "internode" // Storage, lock, S3 and browser peer RPC.
+	jwtAudienceAdmin     = "admin"     // Control RPC.
+	jwtAudienceHeal      = "heal"      // Heal control RPC only.
 )
 
+// Claim holding the identity of the node which issued a JWT token,
+// RPC tokens are only accepted by the node which issued them.
+const jwtNodeClaim = "node"
+
+// hasJWTAudience - returns true if a parsed token is meant for audience.
+func hasJWTAudience(token *jwtgo.Token, audience string) bool {
+	claims, ok := token.Claims.(jwtgo.MapClaims)
+	if !ok {
+		return false
+	}
+	aud, _ := claims["aud"].(string)
+	return aud == audience
+}
+
+// isJWTIssuedByNode - returns true if a parsed token was issued by
+// this node.
+func isJWTIssuedByNode(token *jwtgo.Token) bool {
+	claims, ok := token.Claims.(jwtgo.MapClaims)
+	if !ok {
+		return false
+	}
+	node, _ := claims[jwtNodeClaim].(string)
+	return node == globalNodeID
+}
+
+// jwtScope - restricts a JWT token to read-only access of the objects
+// under a prefix of a single bucket.
+type jwtScope struct {
+	Bucket string
+	Prefix string
+	// Expiry of the token, only set on scopes of parsed tokens.
+	Expiry time.Time
+}
+
+// allows - returns true if the scope grants access to objects with
+// the given prefix, which may be a single object name.
+func (scope *jwtScope) allows(bucket, prefix string) bool {
+	return scope != nil && bucket == scope.Bucket && strings.HasPrefix(prefix, scope.Prefix)
+}
+
+// getJWTScope - returns the scope of a parsed token, nil if the token
+// is not scoped.
+func getJWTScope(token *jwtgo.Token) *jwtScope {
+	claims, ok := token.Claims.(jwtgo.MapClaims)
+	if !ok {
+		return nil
+	}
+	scopeClaim, ok := claims[jwtScopeClaim].(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	scope := &jwtScope{}
+	scope.Bucket, _ = scopeClaim["bucket"].(string)
+	scope.Prefix, _ = scopeClaim["prefix"].(string)
+	if exp, ok := claims["exp"].(float64); ok {
+		scope.Expiry = time.Unix(int64(exp), 0).UTC()
+	}
+	return scope
+}
+
 // newJWT - returns new JWT object.
 func newJWT(expiry time.Duration) (*JWT, error) {
 	if serverConfig == nil {
@@ -52,28 +134,67 @@ func newJWT(expiry time.Duration) (*JWT, error) {
 	if !isValidAccessKey.MatchString(cred.AccessKeyID) {
 		return nil, errors.New("Invalid access key")
 	}
-	if !isValidSecretKey.MatchString(cred.SecretAccessKey) {
+	if !isValidSecretKey(cred.SecretAccessKey) {
 		return nil, errors.New("Invalid secret key")
 	}
 
 	return &JWT{cred, expiry}, nil
 }
 
-// GenerateToken - generates a new Json Web Token based on the incoming access key.
-func (jwt *JWT) GenerateToken(accessKey string) (string, error) {
+// GenerateToken - generates a new Json Web Token based on the incoming
+// access key, for the given audience.
+func (jwt *JWT) GenerateToken(accessKey, audience string) (string, error) {
+	// Trim spaces.
+	accessKey = strings.TrimSpace(accessKey)
+
+	if !isValidAccessKey.MatchString(accessKey) {
+		return "", errors.New("Invalid access key")
+	}
+
+	tUTCNow := time.Now().UTC()
+	token := jwtgo.NewWithClaims(jwtgo.SigningMethodHS512, jwtgo.MapClaims{
+		"exp":        tUTCNow.Add(jwt.expiry).Unix(),
+		"iat":        tUTCNow.Unix(),
+		"sub":        accessKey,
+		"aud":        audience,
+		jwtNodeClaim: globalNodeID,
+	})
+	return token.SignedString([]byte(jwt.SecretAccessKey))
+}
+
+// generateHealToken - generates a token only allowed to heal, printed
+// by servers waiting for heal in place of the credentials.
+func generateHealToken() (string, error) {
+	jwt, err := newJWT(defaultHealJWTExpiry)
+	if err != nil {
+		return "", err
+	}
+	return jwt.GenerateToken(jwt.AccessKeyID, jwtAudienceHeal)
+}
+
+// GenerateScopedToken - generates a new Json Web Token which only
+// grants read-only access to the objects within scope.
+func (jwt *JWT) GenerateScopedToken(accessKey string, scope jwtScope) (string, error) {
 	// Trim spaces.
 	accessKey = strings.TrimSpace(accessKey)
 
 	if !isValidAccessKey.MatchString(accessKey) {
 		return "", errors.New("Invalid access key")
 	}
+	if !IsValidBucketName(scope.Bucket) {
+		return "", BucketNameInvalid{Bucket: scope.Bucket}
+	}
 
 	tUTCNow := time.Now().UTC()
 	token := jwtgo.NewWithClaims(jwtgo.SigningMethodHS512, jwtgo.MapClaims{
-		// Token expires in 10hrs.
 		"exp": tUTCNow.Add(jwt.expiry).Unix(),
 		"iat": tUTCNow.Unix(),
 		"sub": accessKey,
+		"aud": jwtAudienceWeb,
+		jwtScopeClaim: map[string]interface{}{
+			"bucket": scope.Bucket,
+			"prefix": scope.Prefix,
+		},
 	})
 	return token.SignedString([]byte(jwt.SecretAccessKey))
 }
@@ -90,7 +211,7 @@ func (jwt *JWT) Authenticate(accessKey, secretKey string) error {
 	if !isValidAccessKey.MatchString(accessKey) {
 		return errors.New("Invalid access key")
 	}
-	if !isValidSecretKey.MatchString(secretKey) {
+	if !isValidSecretKey(secretKey) {
 		return errors.New("Invalid secret key")
 	}
 
@@ -156,7 +156,7 @@ func TestGenerateToken(t *testing.T) {
 
 	// Run tests.
 	for _, testCase := range testCases {
-		_, err := jwt.GenerateToken(testCase.accessKey)
+		_, err := jwt.GenerateToken(testCase.accessKey, jwtAudienceWeb)
 		if testCase.expectedErr != nil {
 			if err == nil {
 				t.Fatalf("%+v: expected: %s, got: <nil>", testCase, testCase.expectedErr)
@@ -171,6 +171,85 @@ func TestGenerateToken(t *testing.T) {
 	}
 }
 
+// Tests tokens are only accepted for their audience, and RPC tokens
+// only by the node which issued them.
+func TestJWTAudience(t *testing.T) {
+	testPath, err := newTestConfig("us-east-1")
+	if err != nil {
+		t.Fatalf("unable initialize config file, %s", err)
+	}
+	defer removeAll(testPath)
+
+	jwt, err := newJWT(defaultInterNodeJWTExpiry)
+	if err != nil {
+		t.Fatalf("unable get new JWT, %s", err)
+	}
+	accessKey := serverConfig.GetCredential().AccessKeyID
+	webToken, err := jwt.GenerateToken(accessKey, jwtAudienceWeb)
+	if err != nil {
+		t.Fatalf("unable to generate token, %s", err)
+	}
+	interNodeToken, err := jwt.GenerateToken(accessKey, jwtAudienceInterNode)
+	if err != nil {
+		t.Fatalf("unable to generate token, %s", err)
+	}
+	adminToken, err := jwt.GenerateToken(accessKey, jwtAudienceAdmin)
+	if err != nil {
+		t.Fatalf("unable to generate token, %s", err)
+	}
+
+	testCases := []struct {
+		token    string
+		audience string
+		valid    bool
+	}{
+		{interNodeToken, jwtAudienceInterNode, true},
+		{adminToken, jwtAudienceAdmin, true},
+		{webToken, jwtAudienceInterNode, false},
+		{webToken, jwtAudienceAdmin, false},
+		{interNodeToken, jwtAudienceAdmin, false},
+		{adminToken, jwtAudienceInterNode, false},
+	}
+	for i, testCase := range testCases {
+		if valid := isRPCTokenValid(testCase.token, testCase.audience); valid != testCase.valid {
+			t.Errorf("Test %d: Expected valid %v, got %v", i+1, testCase.valid, valid)
+		}
+	}
+	if !isJWTTokenValid(webToken) || isJWTTokenValid(interNodeToken) || isJWTTokenValid(adminToken) {
+		t.Error("Expected only web tokens to be accepted by web handlers")
+	}
+
+	healToken, err := generateHealToken()
+	if err != nil {
+		t.Fatalf("unable to generate heal token, %s", err)
+	}
+	if isRPCTokenValid(healToken, jwtAudienceAdmin) || isRPCTokenValid(healToken, jwtAudienceInterNode) || isJWTTokenValid(healToken) {
+		t.Error("Expected heal token to be rejected by non heal handlers")
+	}
+	if !isHealTokenValid(healToken) || !isHealTokenValid(adminToken) {
+		t.Error("Expected heal and admin tokens to be accepted by heal handlers")
+	}
+	if isHealTokenValid(webToken) || isHealTokenValid(interNodeToken) {
+		t.Error("Expected web and internode tokens to be rejected by heal handlers")
+	}
+
+	// Tokens issued by another node are rejected.
+	savedNodeID := globalNodeID
+	globalNodeID = getUUID()
+	defer func() {
+		globalNodeID = savedNodeID
+	}()
+	if isRPCTokenValid(interNodeToken, jwtAudienceInterNode) {
+		t.Error("Expected token issued by another node to be rejected")
+	}
+	if !isJWTTokenValid(webToken) {
+		t.Error("Expected web token to be accepted by all nodes")
+	}
+	if !isHealTokenValid(healToken) || isHealTokenValid(adminToken) {
+		t.Error("Expected only heal token to be accepted by other nodes heal handlers")
+	}
+}
+
 // Tests JWT.Authenticate()
 func TestAuthenticate(t *testing.T) {
 	testPath, err := newTestConfig("us-east-1")
@@ -115,6 +115,10 @@ func doesPresignV2SignatureMatch(r *http.Request) APIErrorCode {
 
 	expectedSignature := preSignatureV2(r.Method, encodedResource, strings.Join(filteredQueries, "&"), r.Header, expires)
 	if gotSignature != getURLEncodedName(expectedSignature) {
+		if globalSignatureDebug {
+			stringToSign := presignV2STS(r.Method, encodedResource, strings.Join(filteredQueries, "&"), r.Header, expires)
+			logSignatureMismatch(r, "", stringToSign, gotSignature)
+		}
 		return ErrSignatureDoesNotMatch
 	}
 
@@ -192,6 +196,10 @@ func doesSignV2Match(r *http.Request) APIErrorCode {
 
 	expectedAuth := signatureV2(r.Method, encodedResource, encodedQuery, r.Header)
 	if v2Auth != expectedAuth {
+		if globalSignatureDebug {
+			stringToSign := signV2STS(r.Method, encodedResource, encodedQuery, r.Header)
+			logSignatureMismatch(r, "", stringToSign, strings.TrimPrefix(v2Auth, signV2Algorithm+" "))
+		}
 		return ErrSignatureDoesNotMatch
 	}
 
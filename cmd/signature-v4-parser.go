@@ -178,10 +178,11 @@ func parsePreSignV4(query url.Values) (preSignValues, APIErrorCode) {
 	if err != ErrNone {
 		return preSignValues{}, err
 	}
-	// `host` is the only header used during the presigned request.
+	// `host` has to be signed, other headers like `content-type` may
+	// restrict the presigned request further.
 	// Malformed signed headers has be caught here, otherwise it'll lead to signature mismatch.
-	if preSignV4Values.SignedHeaders[0] != "host" {
-		return preSignValues{}, ErrUnsignedHeaders
+	if errCode := findHost(preSignV4Values.SignedHeaders); errCode != ErrNone {
+		return preSignValues{}, errCode
 	}
 
 	// Save signature.
@@ -23,8 +23,6 @@ import (
 	"regexp"
 	"strings"
 	"unicode/utf8"
-
-	"github.com/minio/sha256-simd"
 )
 
 // http Header "x-amz-content-sha256" == "UNSIGNED-PAYLOAD" indicates that the
@@ -54,7 +52,7 @@ func isValidRegion(reqRegion string, confRegion string) bool {
 
 // sumHMAC calculate hmac between two input byte array.
 func sumHMAC(key []byte, data []byte) []byte {
-	hash := hmac.New(sha256.New, key)
+	hash := hmac.New(newSHA256, key)
 	hash.Write(data)
 	return hash.Sum(nil)
 }
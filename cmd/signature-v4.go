@@ -33,8 +33,6 @@ import (
 	"strconv"
 	"strings"
 	"time"
-
-	"github.com/minio/sha256-simd"
 )
 
 // AWS Signature Version '4' constants.
@@ -126,8 +124,7 @@ func getScope(t time.Time, region string) string {
 func getStringToSign(canonicalRequest string, t time.Time, region string) string {
 	stringToSign := signV4Algorithm + "\n" + t.Format(iso8601Format) + "\n"
 	stringToSign = stringToSign + getScope(t, region) + "\n"
-	canonicalRequestBytes := sha256.Sum256([]byte(canonicalRequest))
-	stringToSign = stringToSign + hex.EncodeToString(canonicalRequestBytes[:])
+	stringToSign = stringToSign + hex.EncodeToString(sum256([]byte(canonicalRequest)))
 	return stringToSign
 }
 
@@ -309,6 +306,7 @@ func doesPresignedSignatureMatch(hashedPayload string, r *http.Request, region s
 
 	// Verify signature.
 	if req.URL.Query().Get("X-Amz-Signature") != newSignature {
+		logSignatureMismatch(r, presignedCanonicalReq, presignedStringToSign, req.URL.Query().Get("X-Amz-Signature"))
 		return ErrSignatureDoesNotMatch
 	}
 	return ErrNone
@@ -406,6 +404,7 @@ func doesSignatureMatch(hashedPayload string, r *http.Request, region string) AP
 
 	// Verify if signature match.
 	if newSignature != signV4Values.Signature {
+		logSignatureMismatch(r, canonicalRequest, stringToSign, signV4Values.Signature)
 		return ErrSignatureDoesNotMatch
 	}
 
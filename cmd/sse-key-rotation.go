@@ -0,0 +1,213 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package cmd
+
+import (
+	"errors"
+	"sort"
+	"strings"
+)
+
+// Maximum number of objects whose keys are rotated by one call.
+const rotateKeysMaxKeys = 1000
+
+// errSSEKeyNotRotated - object key is still sealed with an old master key.
+var errSSEKeyNotRotated = errors.New("Object key is not sealed with the current master key")
+
+// rotateObjectKey - re-seals the key of an encrypted object with the
+// current master key if it is sealed with an old one, the object data
+// is not encrypted again. With verify the key is only checked to be
+// sealed with the current master key.
+func rotateObjectKey(objAPI ObjectLayer, bucket, object string, verify bool) (encrypted, rotated bool, err error) {
+	err = objAPI.UpdateObjectMetadata(bucket, object, func(metadata map[string]string) (bool, error) {
+		if !isEncryptedObject(metadata) {
+			return false, nil
+		}
+		encrypted = true
+		objectKey, current, uErr := unsealObjectKeyAny(metadata[sseMetaSealedKey])
+		if uErr != nil {
+			return false, uErr
+		}
+		if current {
+			return false, nil
+		}
+		if verify {
+			return false, errSSEKeyNotRotated
+		}
+		sealedKey, sErr := sealObjectKey(globalSSEMasterKey, objectKey)
+		if sErr != nil {
+			return false, sErr
+		}
+		metadata[sseMetaSealedKey] = sealedKey
+		rotated = true
+		return true, nil
+	})
+	return encrypted, rotated, err
+}
+
+// RotateKeysArgs - arguments for RotateKeys RPC.
+type RotateKeysArgs struct {
+	// Authentication token generated by Login.
+	GenericArgs
+
+	// Bucket and prefix of the objects, all the buckets and the
+	// trash when Bucket is empty.
+	Bucket string
+	Prefix string
+	// Position to continue at, NextMarker of the previous reply.
+	Marker string
+	// Only check all the keys are sealed with the current master key.
+	Verify bool
+}
+
+// RotateKeysFailure - object whose key could not be rotated or verified.
+type RotateKeysFailure struct {
+	Bucket string
+	Object string
+	Error  string
+}
+
+// RotateKeysReply - reply by RotateKeys RPC.
+type RotateKeysReply struct {
+	Scanned   int
+	Encrypted int
+	Rotated   int
+	Failed    []RotateKeysFailure
+	// Position to continue at if IsTruncated.
+	NextMarker  string
+	IsTruncated bool
+}
+
+// rotateKeysTarget - bucket and prefix of objects to rotate.
+type rotateKeysTarget struct {
+	Bucket string
+	Prefix string
+}
+
+// getRotateKeysTargets - returns the buckets and prefixes of the
+// objects to rotate, sorted by bucket. All the buckets include the
+// trash, whose objects are encrypted as well.
+func getRotateKeysTargets(objAPI ObjectLayer, bucket, prefix string) ([]rotateKeysTarget, error) {
+	if bucket != "" {
+		return []rotateKeysTarget{{bucket, prefix}}, nil
+	}
+	buckets, err := objAPI.ListBuckets()
+	if err != nil {
+		return nil, err
+	}
+	targets := []rotateKeysTarget{{minioMetaBucket, trashPrefix + slashSeparator}}
+	for _, bucketInfo := range buckets {
+		targets = append(targets, rotateKeysTarget{bucketInfo.Name, ""})
+	}
+	sort.Sort(byRotateKeysBucket(targets))
+	return targets, nil
+}
+
+// byRotateKeysBucket - sorts rotation targets by bucket.
+type byRotateKeysBucket []rotateKeysTarget
+
+func (t byRotateKeysBucket) Len() int           { return len(t) }
+func (t byRotateKeysBucket) Swap(i, j int)      { t[i], t[j] = t[j], t[i] }
+func (t byRotateKeysBucket) Less(i, j int) bool { return t[i].Bucket < t[j].Bucket }
+
+// rotateKeys - rotates or verifies the keys of up to maxKeys objects
+// of targets, starting after marker of the form "bucket/object".
+// Objects which fail do not stop the others, they are reported.
+func rotateKeys(objAPI ObjectLayer, targets []rotateKeysTarget, marker string, maxKeys int, verify bool, isCancelled func() bool) (reply RotateKeysReply, err error) {
+	markerBucket, markerObject := marker, ""
+	if i := strings.Index(marker, slashSeparator); i >= 0 {
+		markerBucket, markerObject = marker[:i], marker[i+1:]
+	}
+	for _, target := range targets {
+		if target.Bucket < markerBucket {
+			continue
+		}
+		objectMarker := ""
+		if target.Bucket == markerBucket {
+			objectMarker = markerObject
+		}
+		for {
+			if isCancelled() {
+				return reply, errRPCCancelled
+			}
+			result, lErr := objAPI.ListObjects(target.Bucket, target.Prefix, objectMarker, "", maxKeys-reply.Scanned)
+			if lErr != nil {
+				return reply, lErr
+			}
+			for _, objInfo := range result.Objects {
+				objectMarker = objInfo.Name
+				if objInfo.IsDir {
+					continue
+				}
+				reply.Scanned++
+				encrypted, rotated, rErr := rotateObjectKey(objAPI, target.Bucket, objInfo.Name, verify)
+				if rErr != nil {
+					reply.Failed = append(reply.Failed, RotateKeysFailure{
+						Bucket: target.Bucket,
+						Object: objInfo.Name,
+						Error:  errorCause(rErr).Error(),
+					})
+				}
+				if encrypted {
+					reply.Encrypted++
+				}
+				if rotated {
+					reply.Rotated++
+				}
+			}
+			if reply.Scanned >= maxKeys {
+				reply.NextMarker = target.Bucket + slashSeparator + objectMarker
+				reply.IsTruncated = true
+				return reply, nil
+			}
+			if !result.IsTruncated {
+				break
+			}
+		}
+	}
+	return reply, nil
+}
+
+// RotateKeysHandler - RPC control handler re-sealing the keys of
+// encrypted objects with the current master key, or verifying they
+// all are with Verify. Called repeatedly with the NextMarker of the
+// previous reply until it is not truncated.
+func (c *controlAPIHandlers) RotateKeysHandler(args *RotateKeysArgs, reply *RotateKeysReply) (err error) {
+	defer encodeRPCError(&err)
+
+	objAPI := c.ObjectAPI()
+	if objAPI == nil {
+		return errServerNotInitialized
+	}
+	if !isRPCTokenValid(args.Token, jwtAudienceAdmin) {
+		return errInvalidToken
+	}
+	if globalSSEMasterKey == nil {
+		return errSSENotConfigured
+	}
+	// Retries get the result of the first call.
+	if replayed, replayErr := globalIdempotentCalls.Begin("Control.RotateKeysHandler", args.IdempotencyKey, reply); replayed {
+		return replayErr
+	}
+	defer globalIdempotentCalls.End("Control.RotateKeysHandler", args.IdempotencyKey, reply, &err)
+
+	targets, err := getRotateKeysTargets(objAPI, args.Bucket, args.Prefix)
+	if err != nil {
+		return err
+	}
+	*reply, err = rotateKeys(objAPI, targets, args.Marker, rotateKeysMaxKeys, args.Verify, args.isCancelled)
+	return err
+}
@@ -0,0 +1,145 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+)
+
+// Wrapper for calling key rotation tests for both XL and FS.
+func TestRotateKeys(t *testing.T) {
+	ExecObjectLayerTest(t, testRotateKeys)
+}
+
+// Tests keys of encrypted objects are re-sealed with the current
+// master key, objects stay readable.
+func testRotateKeys(obj ObjectLayer, instanceType string, t TestErrHandler) {
+	oldMasterKey := bytes.Repeat([]byte{1}, sseMasterKeySize)
+	newMasterKey := bytes.Repeat([]byte{2}, sseMasterKeySize)
+	defer func() {
+		globalSSEMasterKey = nil
+		globalSSEOldMasterKeys = nil
+	}()
+
+	// Deduplicated buckets keep the metadata in manifests.
+	dedupBucket := "rotate-dedup"
+	objAPI := newEncryptedObjects(newDedupObjects(obj))
+	for _, bucket := range []string{"rotate-bucket", dedupBucket} {
+		if err := obj.MakeBucket(bucket); err != nil {
+			t.Fatalf("%s: Unable to make bucket: %v", instanceType, err)
+		}
+	}
+	globalBucketSettings.SetBucketSettings(dedupBucket, &bucketSettings{Dedup: true})
+	defer globalBucketSettings.SetBucketSettings(dedupBucket, nil)
+
+	data := []byte("encrypted object data")
+	globalSSEMasterKey = oldMasterKey
+	for _, bucket := range []string{"rotate-bucket", dedupBucket} {
+		for i := 0; i < 3; i++ {
+			metadata := map[string]string{sseMetaAlgorithm: sseAlgorithmAES256}
+			if _, err := objAPI.PutObject(bucket, fmt.Sprintf("object%d", i), int64(len(data)), bytes.NewReader(data), metadata, ""); err != nil {
+				t.Fatalf("%s: Unable to put object: %v", instanceType, err)
+			}
+		}
+	}
+	if _, err := objAPI.PutObject("rotate-bucket", "plain", int64(len(data)), bytes.NewReader(data), nil, ""); err != nil {
+		t.Fatalf("%s: Unable to put object: %v", instanceType, err)
+	}
+
+	// Rotate to the new master key.
+	globalSSEMasterKey = newMasterKey
+	globalSSEOldMasterKeys = [][]byte{oldMasterKey}
+	notCancelled := func() bool { return false }
+	targets, err := getRotateKeysTargets(objAPI, "", "")
+	if err != nil {
+		t.Fatalf("%s: Unable to get targets: %v", instanceType, err)
+	}
+
+	reply, err := rotateKeys(objAPI, targets, "", rotateKeysMaxKeys, true, notCancelled)
+	if err != nil || reply.Scanned != 7 || reply.Encrypted != 6 || len(reply.Failed) != 6 {
+		t.Fatalf("%s: Unexpected verify reply %+v (%v)", instanceType, reply, err)
+	}
+
+	// Pages of two objects.
+	total := RotateKeysReply{}
+	var marker string
+	for pages := 0; ; pages++ {
+		if pages > 10 {
+			t.Fatalf("%s: Rotation does not end, marker %s", instanceType, marker)
+		}
+		reply, err = rotateKeys(objAPI, targets, marker, 2, false, notCancelled)
+		if err != nil {
+			t.Fatalf("%s: Unable to rotate keys: %v", instanceType, err)
+		}
+		total.Scanned += reply.Scanned
+		total.Encrypted += reply.Encrypted
+		total.Rotated += reply.Rotated
+		total.Failed = append(total.Failed, reply.Failed...)
+		if !reply.IsTruncated {
+			break
+		}
+		marker = reply.NextMarker
+	}
+	if total.Scanned != 7 || total.Encrypted != 6 || total.Rotated != 6 || len(total.Failed) != 0 {
+		t.Fatalf("%s: Unexpected rotation %+v", instanceType, total)
+	}
+
+	// Rotating again skips all the objects.
+	reply, err = rotateKeys(objAPI, targets, "", rotateKeysMaxKeys, false, notCancelled)
+	if err != nil || reply.Encrypted != 6 || reply.Rotated != 0 {
+		t.Fatalf("%s: Unexpected rotation %+v (%v)", instanceType, reply, err)
+	}
+
+	// Objects are readable without the old master key.
+	globalSSEOldMasterKeys = nil
+	reply, err = rotateKeys(objAPI, targets, "", rotateKeysMaxKeys, true, notCancelled)
+	if err != nil || len(reply.Failed) != 0 {
+		t.Fatalf("%s: Unexpected verify reply %+v (%v)", instanceType, reply, err)
+	}
+	for _, bucket := range []string{"rotate-bucket", dedupBucket} {
+		var buffer bytes.Buffer
+		if err = objAPI.GetObject(bucket, "object1", 0, int64(len(data)), &buffer); err != nil {
+			t.Fatalf("%s: Unable to get object: %v", instanceType, err)
+		}
+		if !bytes.Equal(buffer.Bytes(), data) {
+			t.Errorf("%s: Unexpected data %q", instanceType, buffer.Bytes())
+		}
+	}
+
+	// Rotating a second time keeps the chunks of deduplicated objects
+	// referenced, they survive the chunk collection.
+	globalSSEMasterKey = bytes.Repeat([]byte{3}, sseMasterKeySize)
+	globalSSEOldMasterKeys = [][]byte{newMasterKey}
+	reply, err = rotateKeys(objAPI, targets, "", rotateKeysMaxKeys, false, notCancelled)
+	if err != nil || reply.Rotated != 6 || len(reply.Failed) != 0 {
+		t.Fatalf("%s: Unexpected rotation %+v (%v)", instanceType, reply, err)
+	}
+	if _, err = deleteUnreferencedChunks(obj, 0); err != nil {
+		t.Fatalf("%s: Unable to delete unreferenced chunks: %v", instanceType, err)
+	}
+	globalSSEOldMasterKeys = nil
+	for i := 0; i < 3; i++ {
+		var buffer bytes.Buffer
+		if err = objAPI.GetObject(dedupBucket, fmt.Sprintf("object%d", i), 0, int64(len(data)), &buffer); err != nil {
+			t.Fatalf("%s: Unable to get object: %v", instanceType, err)
+		}
+		if !bytes.Equal(buffer.Bytes(), data) {
+			t.Errorf("%s: Unexpected data %q", instanceType, buffer.Bytes())
+		}
+	}
+}
@@ -0,0 +1,396 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+)
+
+// Parts of encrypted multipart uploads are encrypted as they are
+// uploaded, each with the key stream of the object key starting at an
+// offset given by its part number. Parts are at most 5GiB, below the
+// distance of key streams of consecutive parts. The object key of an
+// upload and the ETags of its plain parts are kept in minioMetaBucket
+// until the upload is completed or aborted.
+const (
+	sseUploadsPrefix    = "sse-uploads"
+	sseUploadJSONFile   = "upload.json"
+	sseUploadPartsDir   = "parts"
+	sseUploadVersion    = "1"
+	ssePartOffsetBits   = 36 // 64GiB.
+	sseMetaMultipart    = sseInternalMetaPrefix + "Multipart"
+	sseMultipartEnabled = "true"
+)
+
+// errSSEInvalidParts - parts of an encrypted multipart object do not
+// add up to the object.
+var errSSEInvalidParts = errors.New("Unable to decrypt the object, its parts are not recorded")
+
+// sseUpload - object key of an encrypted multipart upload.
+type sseUpload struct {
+	Version   string `json:"version"`
+	SealedKey string `json:"sealedKey"`
+	IV        string `json:"iv"`
+}
+
+// sseUploadPart - ETags of the plain and the stored data of a part of
+// an encrypted multipart upload.
+type sseUploadPart struct {
+	ETag       string `json:"etag"`
+	StoredETag string `json:"storedETag"`
+}
+
+// ssePartOffset - returns the offset of the key stream of a part.
+func ssePartOffset(partID int) int64 {
+	return int64(partID) << ssePartOffsetBits
+}
+
+// isValidSSEUploadID - returns false for upload IDs which are not a
+// single path element, they can't be of an upload anyway.
+func isValidSSEUploadID(uploadID string) bool {
+	return uploadID != "" && uploadID != "." && uploadID != ".." && !strings.Contains(uploadID, slashSeparator)
+}
+
+func sseUploadPath(uploadID string) string {
+	return path.Join(sseUploadsPrefix, uploadID, sseUploadJSONFile)
+}
+
+func sseUploadPartPath(uploadID string, partID int) string {
+	return path.Join(sseUploadsPrefix, uploadID, sseUploadPartsDir, fmt.Sprintf("%d.json", partID))
+}
+
+// writeSSEJSON - stores v JSON encoded as object of minioMetaBucket.
+func writeSSEJSON(objAPI ObjectLayer, object string, v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return traceError(err)
+	}
+	_, err = objAPI.PutObject(minioMetaBucket, object, int64(len(data)), bytes.NewReader(data), nil, "")
+	return err
+}
+
+// readSSEJSON - reads the JSON encoded object of minioMetaBucket into v.
+func readSSEJSON(objAPI ObjectLayer, object string, v interface{}) error {
+	data, err := readDedupObject(objAPI, minioMetaBucket, object)
+	if err != nil {
+		return err
+	}
+	return traceError(json.Unmarshal(data, v))
+}
+
+// readSSEUpload - reads the object key of an upload, returns false if
+// the upload is not encrypted.
+func readSSEUpload(objAPI ObjectLayer, uploadID string) (upload sseUpload, ok bool, err error) {
+	if !isValidSSEUploadID(uploadID) {
+		return upload, false, nil
+	}
+	if err = readSSEJSON(objAPI, sseUploadPath(uploadID), &upload); err != nil {
+		if _, ok = errorCause(err).(ObjectNotFound); ok {
+			return upload, false, nil
+		}
+		return upload, false, err
+	}
+	return upload, true, nil
+}
+
+// objectKey - unseals the object key of the upload.
+func (u sseUpload) objectKey() (objectKey, iv []byte, err error) {
+	if globalSSEMasterKey == nil {
+		return nil, nil, traceError(errSSENotConfigured)
+	}
+	if objectKey, _, err = unsealObjectKeyAny(u.SealedKey); err != nil {
+		return nil, nil, traceError(err)
+	}
+	iv, err = base64.StdEncoding.DecodeString(u.IV)
+	if err != nil || len(iv) != aes.BlockSize {
+		return nil, nil, traceError(errSSEKeyUnsealFailed)
+	}
+	return objectKey, iv, nil
+}
+
+// deleteSSEUpload - deletes the object key and the part ETags of an
+// upload.
+func deleteSSEUpload(objAPI ObjectLayer, uploadID string) error {
+	prefix := path.Join(sseUploadsPrefix, uploadID) + slashSeparator
+	marker := ""
+	for {
+		result, err := objAPI.ListObjects(minioMetaBucket, prefix, marker, "", maxObjectList)
+		if err != nil {
+			return err
+		}
+		for _, objInfo := range result.Objects {
+			if err = objAPI.DeleteObject(minioMetaBucket, objInfo.Name); err != nil {
+				if _, ok := errorCause(err).(ObjectNotFound); !ok {
+					return err
+				}
+			}
+		}
+		if !result.IsTruncated {
+			return nil
+		}
+		marker = result.NextMarker
+	}
+}
+
+// sseMultipartWriter - decrypts the parts of an encrypted multipart
+// object written from offset to writer.
+type sseMultipartWriter struct {
+	writer    io.Writer
+	objectKey []byte
+	iv        []byte
+	parts     []objectPartInfo
+	offset    int64
+	index     int
+	partStart int64
+	stream    cipher.Stream
+	buf       []byte
+}
+
+func (w *sseMultipartWriter) Write(p []byte) (int, error) {
+	written := 0
+	for len(p) > 0 {
+		// Move on to the part of offset.
+		for w.index < len(w.parts) && w.offset >= w.partStart+w.parts[w.index].Size {
+			w.partStart += w.parts[w.index].Size
+			w.index++
+			w.stream = nil
+		}
+		if w.index == len(w.parts) {
+			return written, errSSEInvalidParts
+		}
+		part := w.parts[w.index]
+		if w.stream == nil {
+			stream, err := newSSEStream(w.objectKey, w.iv, ssePartOffset(part.Number)+w.offset-w.partStart)
+			if err != nil {
+				return written, err
+			}
+			w.stream = stream
+		}
+		n := int64(len(p))
+		if rest := w.partStart + part.Size - w.offset; n > rest {
+			n = rest
+		}
+		if int64(len(w.buf)) < n {
+			w.buf = make([]byte, n)
+		}
+		w.stream.XORKeyStream(w.buf[:n], p[:n])
+		m, err := w.writer.Write(w.buf[:n])
+		written += m
+		w.offset += int64(m)
+		if err != nil {
+			return written, err
+		}
+		p = p[n:]
+	}
+	return written, nil
+}
+
+// getMultipartObject - writes length bytes of an encrypted multipart
+// object starting at startOffset to writer, decrypting each part.
+func (e encryptedObjects) getMultipartObject(bucket, object string, objInfo ObjectInfo, objectKey, iv []byte, startOffset, length int64, writer io.Writer) error {
+	parts, err := e.ObjectLayer.GetObjectParts(bucket, object)
+	if err != nil {
+		return err
+	}
+	var size int64
+	for _, part := range parts {
+		size += part.Size
+	}
+	if size != objInfo.Size {
+		return traceError(errSSEInvalidParts)
+	}
+	w := &sseMultipartWriter{
+		writer:    writer,
+		objectKey: objectKey,
+		iv:        iv,
+		parts:     parts,
+		offset:    startOffset,
+	}
+	return e.ObjectLayer.GetObject(bucket, object, startOffset, length, keepCacheBypassWriter(writer, w))
+}
+
+// NewMultipartUpload - initiates a multipart upload, with a new object
+// key if it asks for encryption.
+func (e encryptedObjects) NewMultipartUpload(bucket, object string, metadata map[string]string) (string, error) {
+	if err := checkEncryptedUpload(bucket, metadata); err != nil {
+		return "", traceError(err)
+	}
+	metadata = removeSSEMetadata(metadata)
+	if metadata[sseMetaAlgorithm] == "" {
+		return e.ObjectLayer.NewMultipartUpload(bucket, object, metadata)
+	}
+
+	_, iv, sealedKey, err := newSSEObjectKey()
+	if err != nil {
+		return "", err
+	}
+	upload := sseUpload{
+		Version:   sseUploadVersion,
+		SealedKey: sealedKey,
+		IV:        base64.StdEncoding.EncodeToString(iv),
+	}
+	metadata[sseMetaSealedKey] = upload.SealedKey
+	metadata[sseMetaIV] = upload.IV
+	metadata[sseMetaMultipart] = sseMultipartEnabled
+	uploadID, err := e.ObjectLayer.NewMultipartUpload(bucket, object, metadata)
+	if err != nil {
+		return "", err
+	}
+	if err = writeSSEJSON(e.ObjectLayer, sseUploadPath(uploadID), upload); err != nil {
+		errorIf(e.ObjectLayer.AbortMultipartUpload(bucket, object, uploadID), "Unable to abort upload %s of %s/%s.", uploadID, bucket, object)
+		return "", err
+	}
+	return uploadID, nil
+}
+
+// PutObjectPart - stores a part, encrypted if its upload is. Returns
+// the ETag of the plain data.
+func (e encryptedObjects) PutObjectPart(bucket, object, uploadID string, partID int, size int64, data io.Reader, md5Hex string, sha256sum string) (string, error) {
+	upload, ok, err := readSSEUpload(e.ObjectLayer, uploadID)
+	if err != nil {
+		return "", err
+	}
+	if !ok {
+		return e.ObjectLayer.PutObjectPart(bucket, object, uploadID, partID, size, data, md5Hex, sha256sum)
+	}
+	objectKey, iv, err := upload.objectKey()
+	if err != nil {
+		return "", err
+	}
+	stream, err := newSSEStream(objectKey, iv, ssePartOffset(partID))
+	if err != nil {
+		return "", traceError(err)
+	}
+
+	// Digests of the client are of the plain data.
+	if size > 0 {
+		data = io.LimitReader(data, size)
+	}
+	verifier := newSSEVerifyReader(data, size, md5Hex, sha256sum, nil)
+	storedETag, err := e.ObjectLayer.PutObjectPart(bucket, object, uploadID, partID, size, cipher.StreamReader{S: stream, R: verifier}, "", "")
+	if err != nil {
+		return "", err
+	}
+	part := sseUploadPart{
+		ETag:       verifier.etag.ETag(),
+		StoredETag: storedETag,
+	}
+	if err = writeSSEJSON(e.ObjectLayer, sseUploadPartPath(uploadID, partID), part); err != nil {
+		return "", err
+	}
+	return part.ETag, nil
+}
+
+// ListObjectParts - lists the parts of an upload, with the ETags of
+// the plain data of encrypted parts.
+func (e encryptedObjects) ListObjectParts(bucket, object, uploadID string, partNumberMarker int, maxParts int) (ListPartsInfo, error) {
+	result, err := e.ObjectLayer.ListObjectParts(bucket, object, uploadID, partNumberMarker, maxParts)
+	if err != nil {
+		return result, err
+	}
+	if _, ok, err := readSSEUpload(e.ObjectLayer, uploadID); err != nil || !ok {
+		return result, err
+	}
+	for i, partInfo := range result.Parts {
+		var part sseUploadPart
+		if err = readSSEJSON(e.ObjectLayer, sseUploadPartPath(uploadID, partInfo.PartNumber), &part); err != nil {
+			if _, ok := errorCause(err).(ObjectNotFound); ok {
+				// Part being uploaded right now.
+				continue
+			}
+			return ListPartsInfo{}, err
+		}
+		if part.StoredETag == partInfo.ETag {
+			result.Parts[i].ETag = part.ETag
+		}
+	}
+	return result, nil
+}
+
+// AbortMultipartUpload - aborts a multipart upload, deleting the object
+// key of encrypted uploads.
+func (e encryptedObjects) AbortMultipartUpload(bucket, object, uploadID string) error {
+	if err := e.ObjectLayer.AbortMultipartUpload(bucket, object, uploadID); err != nil {
+		return err
+	}
+	if isValidSSEUploadID(uploadID) {
+		errorIf(deleteSSEUpload(e.ObjectLayer, uploadID), "Unable to delete the object key of upload %s.", uploadID)
+	}
+	return nil
+}
+
+// CompleteMultipartUpload - completes a multipart upload. The parts of
+// encrypted uploads are identified by the ETags of their plain data,
+// the ETag of the object is then computed from them as well.
+func (e encryptedObjects) CompleteMultipartUpload(bucket, object, uploadID string, uploadedParts []completePart) (string, error) {
+	upload, ok, err := readSSEUpload(e.ObjectLayer, uploadID)
+	if err != nil {
+		return "", err
+	}
+	if !ok {
+		return e.ObjectLayer.CompleteMultipartUpload(bucket, object, uploadID, uploadedParts)
+	}
+
+	plainParts := make([]completePart, len(uploadedParts))
+	storedParts := make([]completePart, len(uploadedParts))
+	for i, uploadedPart := range uploadedParts {
+		var part sseUploadPart
+		if err = readSSEJSON(e.ObjectLayer, sseUploadPartPath(uploadID, uploadedPart.PartNumber), &part); err != nil {
+			if _, ok = errorCause(err).(ObjectNotFound); ok {
+				return "", traceError(InvalidPart{})
+			}
+			return "", err
+		}
+		etag := canonicalizeETag(uploadedPart.ETag)
+		if etag != part.ETag {
+			return "", traceError(InvalidPart{})
+		}
+		plainParts[i] = completePart{PartNumber: uploadedPart.PartNumber, ETag: etag}
+		storedParts[i] = completePart{PartNumber: uploadedPart.PartNumber, ETag: part.StoredETag}
+	}
+	md5Sum, err := completeMultipartMD5(plainParts...)
+	if err != nil {
+		return "", err
+	}
+	if _, err = e.ObjectLayer.CompleteMultipartUpload(bucket, object, uploadID, storedParts); err != nil {
+		return "", err
+	}
+	errorIf(deleteSSEUpload(e.ObjectLayer, uploadID), "Unable to delete the object key of upload %s.", uploadID)
+
+	// The ETag of the plain data replaces the one of the stored parts,
+	// unless the object was replaced in the meantime.
+	err = e.ObjectLayer.UpdateObjectMetadata(bucket, object, func(metadata map[string]string) (bool, error) {
+		if metadata[sseMetaSealedKey] != upload.SealedKey {
+			return false, nil
+		}
+		metadata[sseMetaETag] = md5Sum
+		return true, nil
+	})
+	if err != nil {
+		return "", err
+	}
+	return md5Sum, nil
+}
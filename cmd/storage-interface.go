@@ -31,6 +31,7 @@ type StorageAPI interface {
 	ListVols() (vols []VolInfo, err error)
 	StatVol(volume string) (vol VolInfo, err error)
 	DeleteVol(volume string) (err error)
+	RenameVol(srcVolume, dstVolume string) (err error)
 
 	// File operations.
 	ListDir(volume, dirPath string) ([]string, error)
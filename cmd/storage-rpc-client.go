@@ -184,6 +184,16 @@ func (n networkStorage) DeleteVol(volume string) error {
 	return nil
 }
 
+// RenameVol - Rename a volume.
+func (n networkStorage) RenameVol(srcVolume, dstVolume string) error {
+	reply := GenericReply{}
+	args := RenameVolArgs{SrcVol: srcVolume, DstVol: dstVolume}
+	if err := n.rpcClient.Call("Storage.RenameVolHandler", &args, &reply); err != nil {
+		return toStorageErr(err)
+	}
+	return nil
+}
+
 // File operations.
 
 // CreateFile - create file.
@@ -25,6 +25,18 @@ type GenericVolArgs struct {
 	Vol string
 }
 
+// RenameVolArgs represents rename vol RPC arguments.
+type RenameVolArgs struct {
+	// Authentication token generated by Login.
+	GenericArgs
+
+	// Name of source volume.
+	SrcVol string
+
+	// Name of destination volume.
+	DstVol string
+}
+
 // ListVolsReply represents list of vols RPC reply.
 type ListVolsReply struct {
 	// List of volumes stat information.
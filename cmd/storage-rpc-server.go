@@ -48,7 +48,7 @@ func (s *storageServer) LoginHandler(args *RPCLoginArgs, reply *RPCLoginReply) e
 	if err = jwt.Authenticate(args.Username, args.Password); err != nil {
 		return err
 	}
-	token, err := jwt.GenerateToken(args.Username)
+	token, err := jwt.GenerateToken(args.Username, jwtAudienceInterNode)
 	if err != nil {
 		return err
 	}
@@ -62,7 +62,7 @@ func (s *storageServer) LoginHandler(args *RPCLoginArgs, reply *RPCLoginReply) e
 
 // DiskInfoHandler - disk info handler is rpc wrapper for DiskInfo operation.
 func (s *storageServer) DiskInfoHandler(args *GenericArgs, reply *disk.Info) error {
-	if !isRPCTokenValid(args.Token) {
+	if !isRPCTokenValid(args.Token, jwtAudienceInterNode) {
 		return errInvalidToken
 	}
 	info, err := s.storage.DiskInfo()
@@ -74,7 +74,7 @@ func (s *storageServer) DiskInfoHandler(args *GenericArgs, reply *disk.Info) err
 
 // MakeVolHandler - make vol handler is rpc wrapper for MakeVol operation.
 func (s *storageServer) MakeVolHandler(args *GenericVolArgs, reply *GenericReply) error {
-	if !isRPCTokenValid(args.Token) {
+	if !isRPCTokenValid(args.Token, jwtAudienceInterNode) {
 		return errInvalidToken
 	}
 	return s.storage.MakeVol(args.Vol)
@@ -82,7 +82,7 @@ func (s *storageServer) MakeVolHandler(args *GenericVolArgs, reply *GenericReply
 
 // ListVolsHandler - list vols handler is rpc wrapper for ListVols operation.
 func (s *storageServer) ListVolsHandler(args *GenericArgs, reply *ListVolsReply) error {
-	if !isRPCTokenValid(args.Token) {
+	if !isRPCTokenValid(args.Token, jwtAudienceInterNode) {
 		return errInvalidToken
 	}
 	vols, err := s.storage.ListVols()
@@ -95,7 +95,7 @@ func (s *storageServer) ListVolsHandler(args *GenericArgs, reply *ListVolsReply)
 
 // StatVolHandler - stat vol handler is a rpc wrapper for StatVol operation.
 func (s *storageServer) StatVolHandler(args *GenericVolArgs, reply *VolInfo) error {
-	if !isRPCTokenValid(args.Token) {
+	if !isRPCTokenValid(args.Token, jwtAudienceInterNode) {
 		return errInvalidToken
 	}
 	volInfo, err := s.storage.StatVol(args.Vol)
@@ -109,17 +109,26 @@ func (s *storageServer) StatVolHandler(args *GenericVolArgs, reply *VolInfo) err
 // DeleteVolHandler - delete vol handler is a rpc wrapper for
 // DeleteVol operation.
 func (s *storageServer) DeleteVolHandler(args *GenericVolArgs, reply *GenericReply) error {
-	if !isRPCTokenValid(args.Token) {
+	if !isRPCTokenValid(args.Token, jwtAudienceInterNode) {
 		return errInvalidToken
 	}
 	return s.storage.DeleteVol(args.Vol)
 }
 
+// RenameVolHandler - rename vol handler is a rpc wrapper for
+// RenameVol operation.
+func (s *storageServer) RenameVolHandler(args *RenameVolArgs, reply *GenericReply) error {
+	if !isRPCTokenValid(args.Token, jwtAudienceInterNode) {
+		return errInvalidToken
+	}
+	return s.storage.RenameVol(args.SrcVol, args.DstVol)
+}
+
 /// File operations
 
 // StatFileHandler - stat file handler is rpc wrapper to stat file.
 func (s *storageServer) StatFileHandler(args *StatFileArgs, reply *FileInfo) error {
-	if !isRPCTokenValid(args.Token) {
+	if !isRPCTokenValid(args.Token, jwtAudienceInterNode) {
 		return errInvalidToken
 	}
 	fileInfo, err := s.storage.StatFile(args.Vol, args.Path)
@@ -132,7 +141,7 @@ func (s *storageServer) StatFileHandler(args *StatFileArgs, reply *FileInfo) err
 
 // ListDirHandler - list directory handler is rpc wrapper to list dir.
 func (s *storageServer) ListDirHandler(args *ListDirArgs, reply *[]string) error {
-	if !isRPCTokenValid(args.Token) {
+	if !isRPCTokenValid(args.Token, jwtAudienceInterNode) {
 		return errInvalidToken
 	}
 	entries, err := s.storage.ListDir(args.Vol, args.Path)
@@ -145,7 +154,7 @@ func (s *storageServer) ListDirHandler(args *ListDirArgs, reply *[]string) error
 
 // ReadAllHandler - read all handler is rpc wrapper to read all storage API.
 func (s *storageServer) ReadAllHandler(args *ReadFileArgs, reply *[]byte) error {
-	if !isRPCTokenValid(args.Token) {
+	if !isRPCTokenValid(args.Token, jwtAudienceInterNode) {
 		return errInvalidToken
 	}
 	buf, err := s.storage.ReadAll(args.Vol, args.Path)
@@ -164,7 +173,7 @@ func (s *storageServer) ReadFileHandler(args *ReadFileArgs, reply *[]byte) (err
 			err = bytes.ErrTooLarge
 		}
 	}() // Do not crash the server.
-	if !isRPCTokenValid(args.Token) {
+	if !isRPCTokenValid(args.Token, jwtAudienceInterNode) {
 		return errInvalidToken
 	}
 	// Allocate the requested buffer from the client.
@@ -184,7 +193,7 @@ func (s *storageServer) ReadFileHandler(args *ReadFileArgs, reply *[]byte) (err
 
 // AppendFileHandler - append file handler is rpc wrapper to append file.
 func (s *storageServer) AppendFileHandler(args *AppendFileArgs, reply *GenericReply) error {
-	if !isRPCTokenValid(args.Token) {
+	if !isRPCTokenValid(args.Token, jwtAudienceInterNode) {
 		return errInvalidToken
 	}
 	return s.storage.AppendFile(args.Vol, args.Path, args.Buffer)
@@ -192,7 +201,7 @@ func (s *storageServer) AppendFileHandler(args *AppendFileArgs, reply *GenericRe
 
 // DeleteFileHandler - delete file handler is rpc wrapper to delete file.
 func (s *storageServer) DeleteFileHandler(args *DeleteFileArgs, reply *GenericReply) error {
-	if !isRPCTokenValid(args.Token) {
+	if !isRPCTokenValid(args.Token, jwtAudienceInterNode) {
 		return errInvalidToken
 	}
 	return s.storage.DeleteFile(args.Vol, args.Path)
@@ -200,7 +209,7 @@ func (s *storageServer) DeleteFileHandler(args *DeleteFileArgs, reply *GenericRe
 
 // RenameFileHandler - rename file handler is rpc wrapper to rename file.
 func (s *storageServer) RenameFileHandler(args *RenameFileArgs, reply *GenericReply) error {
-	if !isRPCTokenValid(args.Token) {
+	if !isRPCTokenValid(args.Token, jwtAudienceInterNode) {
 		return errInvalidToken
 	}
 	return s.storage.RenameFile(args.SrcVol, args.SrcPath, args.DstVol, args.DstPath)
@@ -208,7 +217,7 @@ func (s *storageServer) RenameFileHandler(args *RenameFileArgs, reply *GenericRe
 
 // TryInitHandler - wake up storage server.
 func (s *storageServer) TryInitHandler(args *GenericArgs, reply *GenericReply) error {
-	if !isRPCTokenValid(args.Token) {
+	if !isRPCTokenValid(args.Token, jwtAudienceInterNode) {
 		return errInvalidToken
 	}
 	go func() {
@@ -273,7 +282,7 @@ func registerStorageRPCRouters(mux *router.Router, srvCmdConfig serverCmdConfig)
 		}
 		// Add minio storage routes.
 		storageRouter := mux.PathPrefix(reservedBucket).Subrouter()
-		storageRouter.Path(path.Join("/storage", stServer.path)).Handler(storageRPCServer)
+		storageRouter.Path(path.Join("/storage", stServer.path)).Handler(newRPCHandler(storageRPCServer))
 	}
 	return nil
 }
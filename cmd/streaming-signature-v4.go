@@ -27,8 +27,6 @@ import (
 	"io"
 	"net/http"
 	"time"
-
-	"github.com/minio/sha256-simd"
 )
 
 // Streaming AWS Signature Version '4' constants.
@@ -144,6 +142,7 @@ func calculateSeedSignature(r *http.Request) (signature string, date time.Time,
 
 	// Verify if signature match.
 	if newSignature != signV4Values.Signature {
+		logSignatureMismatch(r, canonicalRequest, stringToSign, signV4Values.Signature)
 		return "", time.Time{}, ErrSignatureDoesNotMatch
 	}
 
@@ -167,6 +166,7 @@ var errMalformedEncoding = errors.New("malformed chunked encoding")
 // automatically decodes chunking when reading response bodies.
 func newSignV4ChunkedReader(req *http.Request) (io.Reader, APIErrorCode) {
 	seedSignature, seedDate, errCode := calculateSeedSignature(req)
+	recordAuthResult(req, errCode)
 	if errCode != ErrNone {
 		return nil, errCode
 	}
@@ -174,7 +174,7 @@ func newSignV4ChunkedReader(req *http.Request) (io.Reader, APIErrorCode) {
 		reader:            bufio.NewReader(req.Body),
 		seedSignature:     seedSignature,
 		seedDate:          seedDate,
-		chunkSHA256Writer: sha256.New(),
+		chunkSHA256Writer: newSHA256(),
 		state:             readChunkHeader,
 	}, ErrNone
 }
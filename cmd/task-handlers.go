@@ -0,0 +1,117 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package cmd
+
+import "time"
+
+// TaskStatusArgs - argument for TaskStatus RPC handler.
+type TaskStatusArgs struct {
+	// Authentication token generated by Login.
+	GenericArgs
+}
+
+// NodeTaskStatus - background tasks of a node.
+type NodeTaskStatus struct {
+	Node  string
+	Tasks []TaskStatus
+	// Set instead of the tasks when the node could not be reached.
+	Error string
+}
+
+// TaskStatusReply - reply by TaskStatus RPC handler.
+type TaskStatusReply struct {
+	// Background tasks of this node, followed by all the remote
+	// nodes if args.Remote is set.
+	Nodes []NodeTaskStatus
+}
+
+// TaskStatusHandler - RPC control handler for `minio control tasks
+// list`, returns the status of the background tasks of all the nodes.
+func (c *controlAPIHandlers) TaskStatusHandler(args *TaskStatusArgs, reply *TaskStatusReply) (err error) {
+	defer encodeRPCError(&err)
+
+	if !isRPCTokenValid(args.Token, jwtAudienceAdmin) {
+		return errInvalidToken
+	}
+
+	reply.Nodes = []NodeTaskStatus{{
+		Node:  c.LocalNode,
+		Tasks: globalTaskManager.Status(time.Now()),
+	}}
+	if !args.Remote {
+		return nil
+	}
+	remoteControls := c.getRemoteControls()
+	replies := make([]TaskStatusReply, len(remoteControls))
+	remoteArgs := *args
+	remoteArgs.Remote = false
+	errsMap := callRemoteControls(remoteControls, "Control.TaskStatusHandler", &remoteArgs, func(index int) interface{} {
+		return &replies[index]
+	})
+	for index, client := range remoteControls {
+		if nodeErr, ok := errsMap[client.Node()]; ok {
+			reply.Nodes = append(reply.Nodes, NodeTaskStatus{Node: client.Node(), Error: nodeErr.Error()})
+			continue
+		}
+		for _, nodeStatus := range replies[index].Nodes {
+			// Remote nodes know themselves by their own address.
+			nodeStatus.Node = client.Node()
+			reply.Nodes = append(reply.Nodes, nodeStatus)
+		}
+	}
+	return nil
+}
+
+// TaskControlArgs - argument for TaskControl RPC handler.
+type TaskControlArgs struct {
+	// Authentication token generated by Login.
+	GenericArgs
+
+	// Name of the background task.
+	Task string
+
+	// Pause the task if set, resume it otherwise.
+	Pause bool
+}
+
+// TaskControlHandler - RPC control handler for `minio control tasks
+// pause` and `minio control tasks resume`, pauses or resumes a
+// background task on all the nodes if args.Remote is set.
+func (c *controlAPIHandlers) TaskControlHandler(args *TaskControlArgs, reply *GenericReply) (err error) {
+	defer encodeRPCError(&err)
+
+	if !isRPCTokenValid(args.Token, jwtAudienceAdmin) {
+		return errInvalidToken
+	}
+	// Retries get the result of the first call.
+	if replayed, replayErr := globalIdempotentCalls.Begin("Control.TaskControlHandler", args.IdempotencyKey, reply); replayed {
+		return replayErr
+	}
+	defer globalIdempotentCalls.End("Control.TaskControlHandler", args.IdempotencyKey, reply, &err)
+
+	if err = globalTaskManager.SetPaused(args.Task, args.Pause); err != nil {
+		return err
+	}
+	if args.Remote {
+		remoteArgs := *args
+		remoteArgs.Remote = false
+		errsMap := callRemoteControls(c.getRemoteControls(), "Control.TaskControlHandler", &remoteArgs, func(index int) interface{} {
+			return &GenericReply{}
+		})
+		return nodeErrorsToError(errsMap)
+	}
+	return nil
+}
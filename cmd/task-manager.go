@@ -0,0 +1,198 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package cmd
+
+import (
+	"errors"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Names of the background tasks run by the task manager.
+const (
+	tmpCleanupTask         = "tmp-cleanup"
+	dedupGCTask            = "dedup-gc"
+	trashPurgeTask         = "trash-purge"
+	resumablePurgeTask     = "resumable-purge"
+	browserUploadPurgeTask = "browser-upload-purge"
+	configCheckTask        = "config-check"
+	updateCheckTask        = "update-check"
+)
+
+// States of a background task.
+const (
+	taskIdle    = "idle"
+	taskRunning = "running"
+	taskPaused  = "paused"
+	// Waiting for MINIO_BACKGROUND_WINDOW to open.
+	taskWaiting = "waiting"
+)
+
+// errTaskNotFound - no background task by the name.
+var errTaskNotFound = errors.New("Background task not found")
+
+// TaskStatus - status of a background task on a node.
+type TaskStatus struct {
+	Name     string        `json:"name"`
+	State    string        `json:"state"`
+	Interval time.Duration `json:"interval"`
+	// Start and duration of the last run, zero if it never ran.
+	LastRun      time.Time     `json:"lastRun"`
+	LastDuration time.Duration `json:"lastDuration"`
+	// Error of the last run, empty if it succeeded.
+	LastError string `json:"lastError,omitempty"`
+	// Time of the next run, unless the task is paused or the
+	// background window is closed by then.
+	NextRun  time.Time `json:"nextRun"`
+	Runs     int64     `json:"runs"`
+	Failures int64     `json:"failures"`
+}
+
+// backgroundTask - task run periodically by the task manager.
+type backgroundTask struct {
+	run    func() error
+	status TaskStatus
+	paused bool
+}
+
+// taskManager - owns all the periodic background tasks of this
+// server, runs them in MINIO_BACKGROUND_WINDOW unless paused and
+// keeps their status.
+type taskManager struct {
+	mutex *sync.Mutex
+	tasks map[string]*backgroundTask
+}
+
+// Variable holding the background tasks of this server.
+var globalTaskManager = newTaskManager()
+
+// newTaskManager - returns a task manager without any tasks.
+func newTaskManager() *taskManager {
+	return &taskManager{
+		mutex: &sync.Mutex{},
+		tasks: make(map[string]*backgroundTask),
+	}
+}
+
+// Add - registers a task calling run every interval, without
+// starting it.
+func (m *taskManager) Add(name string, interval time.Duration, run func() error) *backgroundTask {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	task := &backgroundTask{
+		run: run,
+		status: TaskStatus{
+			Name:     name,
+			State:    taskIdle,
+			Interval: interval,
+			NextRun:  time.Now().Add(interval),
+		},
+	}
+	m.tasks[name] = task
+	return task
+}
+
+// Start - registers a task calling run every interval and starts it,
+// the task runs for the lifetime of the server.
+func (m *taskManager) Start(name string, interval time.Duration, run func() error) {
+	task := m.Add(name, interval, run)
+	go func() {
+		for {
+			m.mutex.Lock()
+			nextRun := task.status.NextRun
+			m.mutex.Unlock()
+			m.runOnce(task, <-time.After(nextRun.Sub(time.Now())))
+		}
+	}()
+}
+
+// runOnce - runs task once at now, unless it is paused or
+// MINIO_BACKGROUND_WINDOW is closed. The next run is one interval
+// later, or when the window opens if that is earlier, windows shorter
+// than the interval would be missed otherwise.
+func (m *taskManager) runOnce(task *backgroundTask, now time.Time) {
+	m.mutex.Lock()
+	task.status.NextRun = now.Add(task.status.Interval)
+	if task.paused {
+		m.mutex.Unlock()
+		return
+	}
+	if nextOpen := globalBackgroundSchedule.NextOpen(now); nextOpen.After(now) {
+		if nextOpen.Before(task.status.NextRun) {
+			task.status.NextRun = nextOpen
+		}
+		m.mutex.Unlock()
+		return
+	}
+	task.status.State = taskRunning
+	task.status.LastRun = now
+	m.mutex.Unlock()
+
+	err := task.run()
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	task.status.State = taskIdle
+	task.status.LastDuration = time.Since(now)
+	task.status.Runs++
+	task.status.LastError = ""
+	if err != nil {
+		task.status.Failures++
+		task.status.LastError = err.Error()
+	}
+}
+
+// SetPaused - pauses or resumes a task, a run in progress is
+// not interrupted.
+func (m *taskManager) SetPaused(name string, paused bool) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	task, ok := m.tasks[name]
+	if !ok {
+		return errTaskNotFound
+	}
+	task.paused = paused
+	return nil
+}
+
+// Status - returns the status of all the tasks at now, sorted by name.
+func (m *taskManager) Status(now time.Time) []TaskStatus {
+	windowOpen := globalBackgroundSchedule.IsOpen(now)
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	statuses := []TaskStatus{}
+	for _, task := range m.tasks {
+		status := task.status
+		if status.State != taskRunning {
+			if task.paused {
+				status.State = taskPaused
+			} else if !windowOpen {
+				status.State = taskWaiting
+			}
+		}
+		statuses = append(statuses, status)
+	}
+	sort.Sort(byTaskName(statuses))
+	return statuses
+}
+
+// byTaskName - sorts task statuses by name.
+type byTaskName []TaskStatus
+
+func (t byTaskName) Len() int           { return len(t) }
+func (t byTaskName) Swap(i, j int)      { t[i], t[j] = t[j], t[i] }
+func (t byTaskName) Less(i, j int) bool { return t[i].Name < t[j].Name }
@@ -0,0 +1,118 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package cmd
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+// Tests running, pausing and resuming background tasks.
+func TestTaskManager(t *testing.T) {
+	manager := newTaskManager()
+	var runs int
+	var runErr error
+	task := manager.Add("test-task", time.Minute, func() error {
+		runs++
+		return runErr
+	})
+	manager.Add("another-task", time.Hour, func() error { return nil })
+
+	now := time.Now()
+	manager.runOnce(task, now)
+	runErr = errors.New("disk full")
+	manager.runOnce(task, now.Add(time.Minute))
+
+	statuses := manager.Status(now)
+	if len(statuses) != 2 || statuses[0].Name != "another-task" || statuses[1].Name != "test-task" {
+		t.Fatalf("Expected tasks sorted by name, got %#v", statuses)
+	}
+	status := statuses[1]
+	if runs != 2 || status.Runs != 2 || status.Failures != 1 || status.LastError != "disk full" {
+		t.Fatalf("Unexpected status %#v after %d runs", status, runs)
+	}
+	if !status.LastRun.Equal(now.Add(time.Minute)) || !status.NextRun.Equal(now.Add(2*time.Minute)) {
+		t.Fatalf("Unexpected last run %s and next run %s", status.LastRun, status.NextRun)
+	}
+	if status.State != taskIdle {
+		t.Fatalf("Expected state %s, got %s", taskIdle, status.State)
+	}
+
+	if err := manager.SetPaused("missing-task", true); err != errTaskNotFound {
+		t.Fatalf("Expected %s, got %v", errTaskNotFound, err)
+	}
+	if err := manager.SetPaused("test-task", true); err != nil {
+		t.Fatal(err)
+	}
+	manager.runOnce(task, now.Add(2*time.Minute))
+	if runs != 2 {
+		t.Fatal("Expected paused task not to run")
+	}
+	if status = manager.Status(now)[1]; status.State != taskPaused {
+		t.Fatalf("Expected state %s, got %s", taskPaused, status.State)
+	}
+
+	if err := manager.SetPaused("test-task", false); err != nil {
+		t.Fatal(err)
+	}
+	runErr = nil
+	manager.runOnce(task, now.Add(3*time.Minute))
+	if status = manager.Status(now)[1]; runs != 3 || status.LastError != "" {
+		t.Fatalf("Unexpected status %#v after resume", status)
+	}
+}
+
+
+// Tests tasks outside MINIO_BACKGROUND_WINDOW run next when it opens,
+// even if the window is shorter than their interval.
+func TestTaskManagerWindow(t *testing.T) {
+	defer func() { globalBackgroundSchedule = newBackgroundSchedule() }()
+	globalBackgroundSchedule = newBackgroundSchedule()
+	globalBackgroundSchedule.SetWindow(backgroundWindow{start: 3 * time.Hour, end: 3*time.Hour + 30*time.Minute})
+
+	manager := newTaskManager()
+	var runs int
+	task := manager.Add("test-task", 24*time.Hour, func() error {
+		runs++
+		return nil
+	})
+	at := func(hour, min int) time.Time {
+		return time.Date(2016, 10, 1, hour, min, 0, 0, time.Local)
+	}
+
+	manager.runOnce(task, at(12, 0))
+	status := manager.Status(at(12, 0))[0]
+	if runs != 0 || status.State != taskWaiting {
+		t.Fatalf("Expected task to wait for the window, got %#v", status)
+	}
+	if opening := time.Date(2016, 10, 2, 3, 0, 0, 0, time.Local); !status.NextRun.Equal(opening) {
+		t.Fatalf("Expected next run at %s, got %s", opening, status.NextRun)
+	}
+
+	manager.runOnce(task, status.NextRun)
+	status = manager.Status(status.NextRun)[0]
+	if runs != 1 || !status.NextRun.Equal(time.Date(2016, 10, 3, 3, 0, 0, 0, time.Local)) {
+		t.Fatalf("Unexpected status %#v after %d runs", status, runs)
+	}
+
+	// Short intervals are not delayed by the window opening later.
+	task.status.Interval = time.Minute
+	manager.runOnce(task, at(12, 0))
+	if status = manager.Status(at(12, 0))[0]; !status.NextRun.Equal(at(12, 1)) {
+		t.Fatalf("Expected next run at %s, got %s", at(12, 1), status.NextRun)
+	}
+}
@@ -0,0 +1,141 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net"
+	"path/filepath"
+	"strconv"
+
+	"github.com/minio/cli"
+)
+
+var testS3Flags = []cli.Flag{
+	cli.StringFlag{
+		Name:  "backend",
+		Value: "fs",
+		Usage: "Specify storage backend, \"fs\" or \"xl\", defaults to \"fs\".",
+	},
+	cli.StringFlag{
+		Name:  "address",
+		Usage: "Specify custom server \"ADDRESS:PORT\", defaults to a random port on localhost.",
+	},
+}
+
+var testS3Cmd = cli.Command{
+	Name:   "test-s3",
+	Usage:  "Start an ephemeral object storage server for integration tests.",
+	Flags:  append(testS3Flags, globalFlags...),
+	Action: testS3Main,
+	CustomHelpTemplate: `NAME:
+  minio {{.Name}} - {{.Usage}}
+
+USAGE:
+  minio {{.Name}} [FLAGS]
+
+FLAGS:
+  {{range .Flags}}{{.}}
+  {{end}}
+DESCRIPTION:
+  Config and data live in a fresh temporary directory which is removed
+  when the server is stopped. Once the server is ready, its endpoint and
+  credentials are printed to stdout as shell variable assignments.
+
+ENVIRONMENT VARIABLES:
+  ACCESS:
+     MINIO_ACCESS_KEY: Custom username or access key of 5 to 20 characters in length.
+     MINIO_SECRET_KEY: Custom password or secret key of 8 to 40 characters in length.
+
+EXAMPLES:
+  1. Start an ephemeral server backed by a single directory.
+      $ minio {{.Name}}
+
+  2. Start an ephemeral erasure coded server on port 9001.
+      $ minio {{.Name}} --backend xl --address 127.0.0.1:9001
+
+`,
+}
+
+// Number of disks used for an ephemeral XL backend.
+const testS3XLDisks = 4
+
+// newTestS3Disks - creates the export directories for the requested
+// backend under rootPath.
+func newTestS3Disks(rootPath, backend string) ([]string, error) {
+	var count int
+	switch backend {
+	case "fs":
+		count = 1
+	case "xl":
+		count = testS3XLDisks
+	default:
+		return nil, errInvalidArgument
+	}
+	var disks []string
+	for i := 0; i < count; i++ {
+		disk := filepath.Join(rootPath, "export"+strconv.Itoa(i+1))
+		if err := mkdirAll(disk, 0700); err != nil {
+			return nil, err
+		}
+		disks = append(disks, disk)
+	}
+	return disks, nil
+}
+
+// testS3Main handler called for 'minio test-s3' command.
+func testS3Main(c *cli.Context) {
+	if c.Args().Present() {
+		cli.ShowCommandHelpAndExit(c, "test-s3", 1)
+	}
+
+	// Pick a random port unless an address was requested.
+	serverAddr := c.String("address")
+	if serverAddr == "" {
+		port, err := findFreePort()
+		fatalIf(err, "Unable to find a free port.")
+		serverAddr = net.JoinHostPort("127.0.0.1", strconv.Itoa(port))
+	}
+
+	rootPath, err := ioutil.TempDir("", "minio-test-s3-")
+	fatalIf(err, "Unable to create temporary directory.")
+
+	disks, err := newTestS3Disks(rootPath, c.String("backend"))
+	fatalIf(err, "Unable to initialize %s backend.", c.String("backend"))
+
+	// Use a fresh config so that nothing from the user's
+	// config directory leaks into the test server.
+	setGlobalConfigPath(filepath.Join(rootPath, "config"))
+	fatalIf(initConfig(), "Unable to initialize minio config.")
+	setCredentialFromEnv()
+
+	// Start the server.
+	startServer(c, serverAddr, disks, nil)
+
+	// Print the endpoint and credentials for the calling test suite.
+	cred := serverConfig.GetCredential()
+	fmt.Printf("MINIO_ENDPOINT=http://%s\n", serverAddr)
+	fmt.Printf("MINIO_ACCESS_KEY=%s\n", cred.AccessKeyID)
+	fmt.Printf("MINIO_SECRET_KEY=%s\n", cred.SecretAccessKey)
+
+	// Waits on the server.
+	<-globalServiceDoneCh
+
+	// Remove config and data once the server has stopped.
+	removeAll(rootPath)
+}
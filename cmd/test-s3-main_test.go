@@ -0,0 +1,56 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+// Tests export directory creation for the test-s3 backends.
+func TestNewTestS3Disks(t *testing.T) {
+	rootPath, err := ioutil.TempDir("", "minio-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer removeAll(rootPath)
+
+	testCases := []struct {
+		backend   string
+		diskCount int
+		err       error
+	}{
+		{"fs", 1, nil},
+		{"xl", testS3XLDisks, nil},
+		{"gcs", 0, errInvalidArgument},
+	}
+	for i, testCase := range testCases {
+		disks, err := newTestS3Disks(rootPath, testCase.backend)
+		if err != testCase.err {
+			t.Fatalf("Test %d: expected error %v, got %v", i+1, testCase.err, err)
+		}
+		if len(disks) != testCase.diskCount {
+			t.Fatalf("Test %d: expected %d disks, got %d", i+1, testCase.diskCount, len(disks))
+		}
+		for _, disk := range disks {
+			if fi, err := os.Stat(disk); err != nil || !fi.IsDir() {
+				t.Fatalf("Test %d: expected directory %s to exist", i+1, disk)
+			}
+		}
+	}
+}
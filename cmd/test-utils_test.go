@@ -54,6 +54,9 @@ func init() {
 
 	// Disable printing console messages during tests.
 	color.Output = ioutil.Discard
+
+	// Tests deliberately fail authentication from the same address.
+	globalAuthLockoutEnabled = false
 }
 
 func prepareFS() (ObjectLayer, string, error) {
@@ -1052,7 +1055,7 @@ func newTestSignedRequestV4(method, urlStr string, contentLength int64, body io.
 
 // Return new WebRPC request object.
 func newWebRPCRequest(methodRPC, authorization string, body io.ReadSeeker) (*http.Request, error) {
-	req, err := http.NewRequest("POST", "/minio/webrpc", nil)
+	req, err := http.NewRequest("POST", globalBrowserPrefix+"/webrpc", nil)
 	if err != nil {
 		return nil, err
 	}
@@ -1244,6 +1247,13 @@ func getGetObjectURL(endPoint, bucketName, objectName string) string {
 	return makeTestTargetURL(endPoint, bucketName, objectName, url.Values{})
 }
 
+// return URL for fetching object attributes.
+func getGetObjectAttributesURL(endPoint, bucketName, objectName string) string {
+	queryValue := url.Values{}
+	queryValue.Set("attributes", "")
+	return makeTestTargetURL(endPoint, bucketName, objectName, queryValue)
+}
+
 // return URL for deleting the object from the bucket.
 func getDeleteObjectURL(endPoint, bucketName, objectName string) string {
 	return makeTestTargetURL(endPoint, bucketName, objectName, url.Values{})
@@ -1302,6 +1312,55 @@ func getDeletePolicyURL(endPoint, bucketName string) string {
 	return makeTestTargetURL(endPoint, bucketName, "", queryValue)
 }
 
+// return URL for bucket settings.
+func getBucketSettingsURL(endPoint, bucketName string) string {
+	queryValue := url.Values{}
+	queryValue.Set("settings", "")
+	return makeTestTargetURL(endPoint, bucketName, "", queryValue)
+}
+
+// return URL for PUT/GET/DELETE of bucket encryption.
+func getBucketEncryptionURL(endPoint, bucketName string) string {
+	queryValue := url.Values{}
+	queryValue.Set("encryption", "")
+	return makeTestTargetURL(endPoint, bucketName, "", queryValue)
+}
+
+// return URL for searching objects of the bucket.
+func getSearchObjectsURL(endPoint, bucketName string) string {
+	queryValue := url.Values{}
+	queryValue.Set("search", "")
+	return makeTestTargetURL(endPoint, bucketName, "", queryValue)
+}
+
+// return URL for listing the trash of the bucket.
+func getListBucketTrashURL(endPoint, bucketName string) string {
+	queryValue := url.Values{}
+	queryValue.Set("trash", "")
+	return makeTestTargetURL(endPoint, bucketName, "", queryValue)
+}
+
+// return URL for undeleting an object.
+func getUndeleteObjectURL(endPoint, bucketName, objectName string) string {
+	queryValue := url.Values{}
+	queryValue.Set("undelete", "")
+	return makeTestTargetURL(endPoint, bucketName, objectName, queryValue)
+}
+
+// return URL for starting a resumable upload of an object.
+func getNewResumableUploadURL(endPoint, bucketName, objectName string) string {
+	queryValue := url.Values{}
+	queryValue.Set("resumable", "")
+	return makeTestTargetURL(endPoint, bucketName, objectName, queryValue)
+}
+
+// return URL of a resumable upload session.
+func getResumableUploadURL(endPoint, bucketName, objectName, sessionID string) string {
+	queryValue := url.Values{}
+	queryValue.Set("resumable", sessionID)
+	return makeTestTargetURL(endPoint, bucketName, objectName, queryValue)
+}
+
 // return URL for creating the bucket.
 func getMakeBucketURL(endPoint, bucketName string) string {
 	return makeTestTargetURL(endPoint, bucketName, "", url.Values{})
@@ -1312,6 +1371,13 @@ func getListBucketURL(endPoint string) string {
 	return makeTestTargetURL(endPoint, "", "", url.Values{})
 }
 
+// return URL for listing the error codes of the server.
+func getListErrorCodesURL(endPoint string) string {
+	queryValue := url.Values{}
+	queryValue.Set("errors", "")
+	return makeTestTargetURL(endPoint, "", "", queryValue)
+}
+
 // return URL for HEAD on the bucket.
 func getHEADBucketURL(endPoint, bucketName string) string {
 	return makeTestTargetURL(endPoint, bucketName, "", url.Values{})
@@ -1635,7 +1701,7 @@ func ExecObjectLayerAPIAnonTest(t *testing.T, testName, bucketName, objectName,
 		Statements: []policyStatement{policyFunc(bucketName, "")},
 	}
 
-	globalBucketPolicies.SetBucketPolicy(bucketName, policyChange{false, &policy})
+	globalBucketPolicies.SetBucketPolicy(bucketName, policyChange{BktPolicy: &policy})
 	// now call the handler again with the unsigned/anonymous request, it should be accepted.
 	rec = httptest.NewRecorder()
 
@@ -1833,6 +1899,19 @@ func registerBucketLevelFunc(bucket *router.Router, api objectAPIHandlers, apiFu
 			// Register GetObject handler.
 		case "GetObject":
 			bucket.Methods("GET").Path("/{object:.+}").HandlerFunc(api.GetObjectHandler)
+			// Register HeadObject handler.
+		case "HeadObject":
+			bucket.Methods("HEAD").Path("/{object:.+}").HandlerFunc(api.HeadObjectHandler)
+			// Register GetObjectAttributes handler.
+		case "GetObjectAttributes":
+			bucket.Methods("GET").Path("/{object:.+}").HandlerFunc(api.GetObjectAttributesHandler).Queries("attributes", "")
+			// Register GetArchive handler.
+		case "GetArchive":
+			bucket.Methods("GET").Path("/{object:.+}").HandlerFunc(api.GetArchiveHandler).Queries("archive", "{archive:.*}")
+			bucket.Methods("GET").HandlerFunc(api.GetArchiveHandler).Queries("archive", "{archive:.*}")
+			// Register GetObjectThumbnail handler.
+		case "GetObjectThumbnail":
+			bucket.Methods("GET").Path("/{object:.+}").HandlerFunc(api.GetObjectThumbnailHandler).Queries("thumbnail", "{thumbnail:.*}")
 			// Register PutObject handler.
 		case "PutObject":
 			bucket.Methods("PUT").Path("/{object:.+}").HandlerFunc(api.PutObjectHandler)
@@ -1851,6 +1930,45 @@ func registerBucketLevelFunc(bucket *router.Router, api objectAPIHandlers, apiFu
 			// Register Get Bucket policy HTTP Handler.
 		case "GetBucketPolicy":
 			bucket.Methods("GET").HandlerFunc(api.GetBucketPolicyHandler).Queries("policy", "")
+			// Register PutBucketSettings handler.
+		case "PutBucketSettings":
+			bucket.Methods("PUT").HandlerFunc(api.PutBucketSettingsHandler).Queries("settings", "")
+			// Register GetBucketSettings handler.
+		case "GetBucketSettings":
+			bucket.Methods("GET").HandlerFunc(api.GetBucketSettingsHandler).Queries("settings", "")
+			// Register DeleteBucketSettings handler.
+		case "DeleteBucketSettings":
+			bucket.Methods("DELETE").HandlerFunc(api.DeleteBucketSettingsHandler).Queries("settings", "")
+			// Register PutBucketEncryption handler.
+		case "PutBucketEncryption":
+			bucket.Methods("PUT").HandlerFunc(api.PutBucketEncryptionHandler).Queries("encryption", "")
+			// Register GetBucketEncryption handler.
+		case "GetBucketEncryption":
+			bucket.Methods("GET").HandlerFunc(api.GetBucketEncryptionHandler).Queries("encryption", "")
+			// Register DeleteBucketEncryption handler.
+		case "DeleteBucketEncryption":
+			bucket.Methods("DELETE").HandlerFunc(api.DeleteBucketEncryptionHandler).Queries("encryption", "")
+			// Register SearchObjects handler.
+		case "SearchObjects":
+			bucket.Methods("POST").HandlerFunc(api.SearchObjectsHandler).Queries("search", "")
+			// Register ListBucketTrash handler.
+		case "ListBucketTrash":
+			bucket.Methods("GET").HandlerFunc(api.ListBucketTrashHandler).Queries("trash", "")
+			// Register UndeleteObject handler.
+		case "UndeleteObject":
+			bucket.Methods("POST").Path("/{object:.+}").HandlerFunc(api.UndeleteObjectHandler).Queries("undelete", "")
+			// Register NewResumableUpload handler.
+		case "NewResumableUpload":
+			bucket.Methods("POST").Path("/{object:.+}").HandlerFunc(api.NewResumableUploadHandler).Queries("resumable", "")
+			// Register PutResumableUpload handler.
+		case "PutResumableUpload":
+			bucket.Methods("PUT").Path("/{object:.+}").HandlerFunc(api.PutResumableUploadHandler).Queries("resumable", "{resumable:.*}")
+			// Register HeadResumableUpload handler.
+		case "HeadResumableUpload":
+			bucket.Methods("HEAD").Path("/{object:.+}").HandlerFunc(api.HeadResumableUploadHandler).Queries("resumable", "{resumable:.*}")
+			// Register AbortResumableUpload handler.
+		case "AbortResumableUpload":
+			bucket.Methods("DELETE").Path("/{object:.+}").HandlerFunc(api.AbortResumableUploadHandler).Queries("resumable", "{resumable:.*}")
 			// Register GetBucketLocation handler.
 		case "GetBucketLocation":
 			bucket.Methods("GET").HandlerFunc(api.GetBucketLocationHandler).Queries("location", "")
@@ -1881,6 +1999,9 @@ func registerBucketLevelFunc(bucket *router.Router, api objectAPIHandlers, apiFu
 			// Register ListenBucketNotification Handler.
 		case "ListenBucketNotification":
 			bucket.Methods("GET").HandlerFunc(api.ListenBucketNotificationHandler).Queries("events", "{events:.*}")
+			// Register ListObjectsV2 Handler.
+		case "ListObjectsV2":
+			bucket.Methods("GET").HandlerFunc(api.ListObjectsV2Handler).Queries("list-type", "2")
 		}
 	}
 }
@@ -1907,6 +2028,8 @@ func registerAPIFunctions(muxRouter *router.Router, objLayer ObjectLayer, apiFun
 		ObjectAPI: newObjectLayerFn,
 	}
 
+	// Register ListErrorCodes handler.
+	apiRouter.Methods("GET").HandlerFunc(api.ListErrorCodesHandler).Queries("errors", "")
 	// Register ListBuckets	handler.
 	apiRouter.Methods("GET").HandlerFunc(api.ListBucketsHandler)
 	// Register all bucket level handlers.
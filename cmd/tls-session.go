@@ -0,0 +1,174 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package cmd
+
+import (
+	"crypto/rand"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// Interval after which a new session ticket key is used.
+	tlsTicketKeyRotation = 12 * time.Hour
+
+	// Number of session ticket keys kept, tickets issued with older
+	// keys are rejected and the client does a full handshake.
+	tlsTicketKeysKept = 3
+
+	// Number of sessions to other nodes kept by the RPC clients.
+	tlsClientSessionCacheSize = 64
+)
+
+// TLS sessions to other nodes, shared by all the RPC clients so that
+// reconnecting resumes the previous session.
+var globalTLSClientSessionCache = tls.NewLRUClientSessionCache(tlsClientSessionCacheSize)
+
+// tlsTicketKeys - session ticket keys of a TLS server config, newest
+// first. The newest key encrypts new tickets, all of them decrypt.
+type tlsTicketKeys struct {
+	config *tls.Config
+	keys   [][32]byte
+}
+
+// rotate - adds a new random key and drops the oldest one once more
+// than tlsTicketKeysKept keys are in use.
+func (t *tlsTicketKeys) rotate() error {
+	var key [32]byte
+	if _, err := io.ReadFull(rand.Reader, key[:]); err != nil {
+		return err
+	}
+	t.keys = append([][32]byte{key}, t.keys...)
+	if len(t.keys) > tlsTicketKeysKept {
+		t.keys = t.keys[:tlsTicketKeysKept]
+	}
+	t.config.SetSessionTicketKeys(t.keys)
+	return nil
+}
+
+// startTicketKeyRotation - periodically rotates the session ticket
+// keys, never returns.
+func (t *tlsTicketKeys) startTicketKeyRotation(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for range ticker.C {
+		errorIf(t.rotate(), "Unable to rotate TLS session ticket keys.")
+	}
+}
+
+// Names of the cipher suites reported by ServerInfo.
+var tlsCipherSuiteNames = map[uint16]string{
+	tls.TLS_RSA_WITH_RC4_128_SHA:                "TLS_RSA_WITH_RC4_128_SHA",
+	tls.TLS_RSA_WITH_3DES_EDE_CBC_SHA:           "TLS_RSA_WITH_3DES_EDE_CBC_SHA",
+	tls.TLS_RSA_WITH_AES_128_CBC_SHA:            "TLS_RSA_WITH_AES_128_CBC_SHA",
+	tls.TLS_RSA_WITH_AES_256_CBC_SHA:            "TLS_RSA_WITH_AES_256_CBC_SHA",
+	tls.TLS_RSA_WITH_AES_128_GCM_SHA256:         "TLS_RSA_WITH_AES_128_GCM_SHA256",
+	tls.TLS_RSA_WITH_AES_256_GCM_SHA384:         "TLS_RSA_WITH_AES_256_GCM_SHA384",
+	tls.TLS_ECDHE_ECDSA_WITH_RC4_128_SHA:        "TLS_ECDHE_ECDSA_WITH_RC4_128_SHA",
+	tls.TLS_ECDHE_ECDSA_WITH_AES_128_CBC_SHA:    "TLS_ECDHE_ECDSA_WITH_AES_128_CBC_SHA",
+	tls.TLS_ECDHE_ECDSA_WITH_AES_256_CBC_SHA:    "TLS_ECDHE_ECDSA_WITH_AES_256_CBC_SHA",
+	tls.TLS_ECDHE_RSA_WITH_RC4_128_SHA:          "TLS_ECDHE_RSA_WITH_RC4_128_SHA",
+	tls.TLS_ECDHE_RSA_WITH_3DES_EDE_CBC_SHA:     "TLS_ECDHE_RSA_WITH_3DES_EDE_CBC_SHA",
+	tls.TLS_ECDHE_RSA_WITH_AES_128_CBC_SHA:      "TLS_ECDHE_RSA_WITH_AES_128_CBC_SHA",
+	tls.TLS_ECDHE_RSA_WITH_AES_256_CBC_SHA:      "TLS_ECDHE_RSA_WITH_AES_256_CBC_SHA",
+	tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256:   "TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256",
+	tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256: "TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256",
+	tls.TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384:   "TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384",
+	tls.TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384: "TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384",
+}
+
+// getCipherSuiteName - returns the name of a cipher suite, unknown
+// ones are returned as their hex value.
+func getCipherSuiteName(id uint16) string {
+	if name, ok := tlsCipherSuiteNames[id]; ok {
+		return name
+	}
+	return fmt.Sprintf("0x%04X", id)
+}
+
+// tlsStats - TLS handshakes served since server start, reported by
+// ServerInfo.
+type tlsStats struct {
+	mu         sync.Mutex
+	handshakes int64
+	resumed    int64
+	ciphers    map[string]int64
+
+	// Connections whose handshake is already counted.
+	conns map[*tls.Conn]bool
+}
+
+func newTLSStats() *tlsStats {
+	return &tlsStats{
+		ciphers: make(map[string]int64),
+		conns:   make(map[*tls.Conn]bool),
+	}
+}
+
+// TLS statistics of this node.
+var globalTLSStats = newTLSStats()
+
+// connState - counts the handshake of a TLS connection once it served
+// its first request, set as ConnState of the HTTP server. Connections
+// closed before any request are not counted.
+func (s *tlsStats) connState(c net.Conn, state http.ConnState) {
+	tlsConn, ok := c.(*tls.Conn)
+	if !ok {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	switch state {
+	case http.StateActive:
+		if s.conns[tlsConn] {
+			return
+		}
+		s.conns[tlsConn] = true
+		connState := tlsConn.ConnectionState()
+		s.handshakes++
+		if connState.DidResume {
+			s.resumed++
+		}
+		s.ciphers[getCipherSuiteName(connState.CipherSuite)]++
+	case http.StateClosed, http.StateHijacked:
+		delete(s.conns, tlsConn)
+	}
+}
+
+// String - formats the statistics for ServerInfo, ciphers are sorted
+// by name.
+func (s *tlsStats) String() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	ratio := 0.0
+	if s.handshakes > 0 {
+		ratio = float64(s.resumed) * 100 / float64(s.handshakes)
+	}
+	var ciphers []string
+	for cipher, count := range s.ciphers {
+		ciphers = append(ciphers, fmt.Sprintf("%s=%d", cipher, count))
+	}
+	sort.Strings(ciphers)
+	return fmt.Sprintf("Handshakes: %d | Resumed: %d (%.1f%%) | Ciphers: %s",
+		s.handshakes, s.resumed, ratio, strings.Join(ciphers, ", "))
+}
@@ -0,0 +1,125 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package cmd
+
+import (
+	"crypto/tls"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// Tests that rotating keeps only the newest session ticket keys.
+func TestTLSTicketKeysRotate(t *testing.T) {
+	ticketKeys := &tlsTicketKeys{config: &tls.Config{}}
+	var previous [32]byte
+	for i := 1; i <= tlsTicketKeysKept+2; i++ {
+		if err := ticketKeys.rotate(); err != nil {
+			t.Fatalf("Rotation %d: Unexpected error %s", i, err)
+		}
+		expected := i
+		if expected > tlsTicketKeysKept {
+			expected = tlsTicketKeysKept
+		}
+		if len(ticketKeys.keys) != expected {
+			t.Fatalf("Rotation %d: Expected %d keys, got %d", i, expected, len(ticketKeys.keys))
+		}
+		if i > 1 && ticketKeys.keys[1] != previous {
+			t.Fatalf("Rotation %d: Expected previous key to be kept", i)
+		}
+		if ticketKeys.keys[0] == previous {
+			t.Fatalf("Rotation %d: Expected a new key", i)
+		}
+		previous = ticketKeys.keys[0]
+	}
+}
+
+// Tests that sessions are resumed until their ticket key is rotated
+// out, and that handshakes are counted.
+func TestTLSSessionResumption(t *testing.T) {
+	stats := newTLSStats()
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("hello"))
+	}))
+	server.TLS = newTLSConfig()
+	server.Config.ConnState = stats.connState
+	server.StartTLS()
+	defer server.Close()
+	// StartTLS clones the config.
+	ticketKeys := &tlsTicketKeys{config: server.TLS}
+	if err := ticketKeys.rotate(); err != nil {
+		t.Fatal(err)
+	}
+
+	client := &http.Client{Transport: &http.Transport{
+		TLSClientConfig: &tls.Config{
+			InsecureSkipVerify: true,
+			ClientSessionCache: tls.NewLRUClientSessionCache(1),
+		},
+		DisableKeepAlives: true,
+	}}
+	get := func() {
+		resp, err := client.Get(server.URL)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+	}
+
+	get()
+	get()
+	if stats.handshakes != 2 || stats.resumed != 1 {
+		t.Fatalf("Expected 2 handshakes with 1 resumed, got %d with %d resumed", stats.handshakes, stats.resumed)
+	}
+
+	// Ticket issued before the rotation still resumes.
+	if err := ticketKeys.rotate(); err != nil {
+		t.Fatal(err)
+	}
+	get()
+	if stats.resumed != 2 {
+		t.Fatalf("Expected session to resume after rotation, got %d resumed", stats.resumed)
+	}
+
+	// Once its key is dropped a full handshake is done.
+	for i := 0; i < tlsTicketKeysKept; i++ {
+		if err := ticketKeys.rotate(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	get()
+	if stats.handshakes != 4 || stats.resumed != 2 {
+		t.Fatalf("Expected 4 handshakes with 2 resumed, got %d with %d resumed", stats.handshakes, stats.resumed)
+	}
+
+	info := stats.String()
+	if !strings.HasPrefix(info, "Handshakes: 4 | Resumed: 2 (50.0%) | Ciphers: ") || !strings.HasSuffix(info, "=4") {
+		t.Fatalf("Unexpected TLS info %q", info)
+	}
+}
+
+// Tests naming of known and unknown cipher suites.
+func TestGetCipherSuiteName(t *testing.T) {
+	if name := getCipherSuiteName(tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256); name != "TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256" {
+		t.Errorf("Unexpected name %s", name)
+	}
+	if name := getCipherSuiteName(0xFEFE); name != "0xFEFE" {
+		t.Errorf("Unexpected name %s", name)
+	}
+}
@@ -0,0 +1,267 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// Interval at which tmp entries are checked for being orphaned.
+	tmpCleanupInterval = 1 * time.Hour
+	// Age after which tmp entries are considered orphaned, no request
+	// stays in flight this long.
+	tmpCleanupExpiry = 24 * time.Hour
+	// Age after which multipart uploads are considered abandoned.
+	staleUploadExpiry = 7 * 24 * time.Hour
+)
+
+// tmpCleanupStats - space reclaimed from orphaned tmp entries since
+// server start, reported by ServerInfo.
+type tmpCleanupStats struct {
+	mu      sync.Mutex
+	entries int64
+	bytes   int64
+	uploads int64
+	lastRun time.Time
+}
+
+// Tmp cleanup statistics of this node.
+var globalTmpCleanupStats = &tmpCleanupStats{}
+
+// update - adds reclaimed entries and bytes of a cleanup run.
+func (s *tmpCleanupStats) update(entries, bytes int64) {
+	s.mu.Lock()
+	s.entries += entries
+	s.bytes += bytes
+	s.lastRun = time.Now().UTC()
+	s.mu.Unlock()
+}
+
+// addUploads - adds aborted multipart uploads of a cleanup run.
+func (s *tmpCleanupStats) addUploads(uploads int64) {
+	s.mu.Lock()
+	s.uploads += uploads
+	s.mu.Unlock()
+}
+
+// get - returns reclaimed entries, bytes, aborted multipart uploads
+// and time of the last run.
+func (s *tmpCleanupStats) get() (entries, bytes, uploads int64, lastRun time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.entries, s.bytes, s.uploads, s.lastRun
+}
+
+// statTmpEntry - returns total size and latest modification time of
+// a tmp file, or of all the files under a tmp directory.
+func statTmpEntry(disk StorageAPI, entryPath string) (size int64, modTime time.Time, err error) {
+	if !strings.HasSuffix(entryPath, slashSeparator) {
+		fi, err := disk.StatFile(minioMetaBucket, entryPath)
+		if err != nil {
+			return 0, modTime, err
+		}
+		return fi.Size, fi.ModTime, nil
+	}
+	entries, err := disk.ListDir(minioMetaBucket, entryPath)
+	if err != nil {
+		return 0, modTime, err
+	}
+	for _, entry := range entries {
+		entrySize, entryModTime, err := statTmpEntry(disk, pathJoin(entryPath, entry))
+		if err != nil {
+			return 0, modTime, err
+		}
+		size += entrySize
+		if entryModTime.After(modTime) {
+			modTime = entryModTime
+		}
+	}
+	return size, modTime, nil
+}
+
+// Returns upload id if entry is the append-file or its metadata of an
+// FS multipart upload, see getFSAppendDataPath().
+func getFSAppendUploadID(entry string) (uploadID string, ok bool) {
+	for _, suffix := range []string{".json", ".data"} {
+		if strings.HasSuffix(entry, suffix) {
+			return strings.TrimSuffix(entry, suffix), true
+		}
+	}
+	return "", false
+}
+
+// cleanupTmpEntry - removes entry if it was not modified since cutoff.
+// The append-file of FS multipart uploads and its metadata are removed
+// together, under the same lock as appendParts(), so that complete
+// multipart never finds one without the other.
+func cleanupTmpEntry(disk StorageAPI, entry string, cutoff time.Time) (size int64, deleted bool, err error) {
+	entryPaths := []string{pathJoin(tmpMetaPrefix, entry)}
+	if uploadID, ok := getFSAppendUploadID(entry); ok {
+		fsAppendMetaPath := getFSAppendMetaPath(uploadID)
+		if !cutoff.IsZero() {
+			opsID := getOpsID()
+			nsMutex.Lock(minioMetaBucket, fsAppendMetaPath, opsID)
+			defer nsMutex.Unlock(minioMetaBucket, fsAppendMetaPath, opsID)
+		}
+		entryPaths = []string{fsAppendMetaPath, getFSAppendDataPath(uploadID)}
+	}
+
+	var found bool
+	for _, entryPath := range entryPaths {
+		entrySize, modTime, err := statTmpEntry(disk, entryPath)
+		if err != nil {
+			// Already removed along with its sibling, or by the request.
+			if err == errFileNotFound {
+				continue
+			}
+			return 0, false, err
+		}
+		if !cutoff.IsZero() && modTime.After(cutoff) {
+			return 0, false, nil
+		}
+		size += entrySize
+		found = true
+	}
+	if !found {
+		return 0, false, nil
+	}
+
+	for _, entryPath := range entryPaths {
+		if strings.HasSuffix(entryPath, slashSeparator) {
+			err = errorCause(cleanupDir(disk, minioMetaBucket, entryPath))
+		} else {
+			err = disk.DeleteFile(minioMetaBucket, entryPath)
+		}
+		if err != nil && err != errFileNotFound {
+			return size, false, err
+		}
+	}
+	return size, true, nil
+}
+
+// cleanupTmpEntries - removes all the tmp entries on disk which were not
+// modified for olderThan, returns the number of entries and bytes
+// reclaimed. A zero olderThan removes all the entries without locking,
+// only valid during startup when no requests are in flight.
+func cleanupTmpEntries(disk StorageAPI, olderThan time.Duration) (count, bytes int64, err error) {
+	entries, err := disk.ListDir(minioMetaBucket, retainSlash(tmpMetaPrefix))
+	if err != nil {
+		if err == errFileNotFound || err == errVolumeNotFound {
+			return 0, 0, nil
+		}
+		return 0, 0, traceError(err)
+	}
+	var cutoff time.Time
+	if olderThan > 0 {
+		cutoff = time.Now().UTC().Add(-olderThan)
+	}
+	for _, entry := range entries {
+		size, deleted, err := cleanupTmpEntry(disk, entry, cutoff)
+		if err != nil {
+			return count, bytes, traceError(err)
+		}
+		if deleted {
+			count++
+			bytes += size
+		}
+	}
+	return count, bytes, nil
+}
+
+// cleanupStaleUploads - aborts all the multipart uploads initiated at
+// least expiry ago, returns the number of aborted uploads.
+func cleanupStaleUploads(objAPI ObjectLayer, expiry time.Duration) (count int64, err error) {
+	buckets, err := objAPI.ListBuckets()
+	if err != nil {
+		return 0, err
+	}
+	for _, bucket := range buckets {
+		var stale []uploadMetadata
+		keyMarker, uploadIDMarker := "", ""
+		for {
+			result, err := objAPI.ListMultipartUploads(bucket.Name, "", keyMarker, uploadIDMarker, "", maxUploadsList)
+			if err != nil {
+				return count, err
+			}
+			for _, upload := range result.Uploads {
+				if time.Since(upload.Initiated) >= expiry {
+					stale = append(stale, upload)
+				}
+			}
+			if !result.IsTruncated {
+				break
+			}
+			keyMarker, uploadIDMarker = result.NextKeyMarker, result.NextUploadIDMarker
+		}
+		for _, upload := range stale {
+			err = objAPI.AbortMultipartUpload(bucket.Name, upload.Object, upload.UploadID)
+			switch errorCause(err).(type) {
+			case nil:
+				count++
+			case InvalidUploadID:
+				// Completed or aborted in the meantime.
+			default:
+				return count, err
+			}
+		}
+	}
+	return count, nil
+}
+
+// getLocalDisks - returns the disks of all the exports local to this
+// node, disks is expected to be in the same order as exports.
+func getLocalDisks(exports []string, disks []StorageAPI) (localDisks []StorageAPI) {
+	for index, disk := range disks {
+		if disk != nil && isLocalStorage(exports[index]) {
+			localDisks = append(localDisks, disk)
+		}
+	}
+	return localDisks
+}
+
+// startTmpCleanup - periodically removes orphaned tmp entries left
+// behind by crashes and aborted requests on the local disks of this
+// node. Abandoned multipart uploads are aborted as well if
+// cleanupUploads is set, which is expected on only one node of a
+// distributed setup.
+func startTmpCleanup(objAPI ObjectLayer, localDisks []StorageAPI, cleanupUploads bool, interval, expiry time.Duration) {
+	globalTaskManager.Start(tmpCleanupTask, interval, func() (lastErr error) {
+		var count, bytes int64
+		for _, disk := range localDisks {
+			diskCount, diskBytes, err := cleanupTmpEntries(disk, expiry)
+			errorIf(err, "Unable to cleanup tmp entries on %s", disk)
+			if err != nil {
+				lastErr = err
+			}
+			count += diskCount
+			bytes += diskBytes
+		}
+		globalTmpCleanupStats.update(count, bytes)
+		if cleanupUploads {
+			uploads, err := cleanupStaleUploads(objAPI, staleUploadExpiry)
+			errorIf(err, "Unable to abort abandoned multipart uploads.")
+			if err != nil {
+				lastErr = err
+			}
+			globalTmpCleanupStats.addUploads(uploads)
+		}
+		return lastErr
+	})
+}
@@ -0,0 +1,168 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// Tests removal of orphaned tmp entries.
+func TestCleanupTmpEntries(t *testing.T) {
+	disk, diskPath, err := newPosixTestSetup()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer removeAll(diskPath)
+
+	if err = disk.MakeVol(minioMetaBucket); err != nil {
+		t.Fatal(err)
+	}
+	data := []byte("hello")
+	for _, entryPath := range []string{
+		"stale-file",
+		"stale-dir/part.1",
+		"stale-dir/part.2",
+		"fresh-dir/part.1",
+		"stale-upload.json",
+		"stale-upload.data",
+		"fresh-upload.json",
+		"fresh-upload.data",
+	} {
+		if err = disk.AppendFile(minioMetaBucket, pathJoin(tmpMetaPrefix, entryPath), data); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Age all entries except the fresh ones. Only one file of an
+	// FS append-file pair being fresh keeps both.
+	old := time.Now().Add(-2 * time.Hour)
+	for _, entryPath := range []string{
+		"stale-file",
+		"stale-dir/part.1",
+		"stale-dir/part.2",
+		"stale-upload.json",
+		"stale-upload.data",
+		"fresh-upload.data",
+	} {
+		if err = os.Chtimes(filepath.Join(diskPath, minioMetaBucket, tmpMetaPrefix, entryPath), old, old); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	count, bytes, err := cleanupTmpEntries(disk, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// stale-file, stale-dir and the stale-upload pair.
+	if count != 3 {
+		t.Errorf("Expected 3 entries reclaimed, got %d", count)
+	}
+	if bytes != int64(5*len(data)) {
+		t.Errorf("Expected %d bytes reclaimed, got %d", 5*len(data), bytes)
+	}
+
+	entries, err := disk.ListDir(minioMetaBucket, retainSlash(tmpMetaPrefix))
+	if err != nil {
+		t.Fatal(err)
+	}
+	remaining := map[string]bool{}
+	for _, entry := range entries {
+		remaining[entry] = true
+	}
+	for _, entry := range []string{"fresh-dir/", "fresh-upload.json", "fresh-upload.data"} {
+		if !remaining[entry] {
+			t.Errorf("Expected %s to be kept", entry)
+		}
+	}
+	if len(entries) != 3 {
+		t.Errorf("Expected 3 entries to remain, got %v", entries)
+	}
+
+	// Zero expiry removes everything.
+	count, bytes, err = cleanupTmpEntries(disk, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 2 || bytes != int64(3*len(data)) {
+		t.Errorf("Expected 2 entries and %d bytes reclaimed, got %d and %d", 3*len(data), count, bytes)
+	}
+
+	// Missing tmp directory is not an error.
+	if _, _, err = cleanupTmpEntries(disk, 0); err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+}
+
+// Tests only disks of local exports are returned.
+func TestGetLocalDisks(t *testing.T) {
+	disk, diskPath, err := newPosixTestSetup()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer removeAll(diskPath)
+
+	exports := []string{diskPath, "192.0.2.1:/mnt/disk1", diskPath}
+	disks := []StorageAPI{disk, disk, nil}
+	if localDisks := getLocalDisks(exports, disks); len(localDisks) != 1 {
+		t.Errorf("Expected 1 local disk, got %d", len(localDisks))
+	}
+}
+
+// Wrapper for calling stale multipart upload cleanup tests for both XL and FS.
+func TestCleanupStaleUploads(t *testing.T) {
+	ExecObjectLayerTest(t, testCleanupStaleUploads)
+}
+
+// Tests abandoned multipart uploads are aborted.
+func testCleanupStaleUploads(obj ObjectLayer, instanceType string, t TestErrHandler) {
+	bucket := "bucket"
+	if err := obj.MakeBucket(bucket); err != nil {
+		t.Fatalf("%s: %s", instanceType, err)
+	}
+	for _, object := range []string{"object1", "dir/object2"} {
+		if _, err := obj.NewMultipartUpload(bucket, object, nil); err != nil {
+			t.Fatalf("%s: %s", instanceType, err)
+		}
+	}
+
+	// Fresh uploads are kept.
+	count, err := cleanupStaleUploads(obj, time.Hour)
+	if err != nil {
+		t.Fatalf("%s: %s", instanceType, err)
+	}
+	if count != 0 {
+		t.Errorf("%s: Expected no uploads aborted, got %d", instanceType, count)
+	}
+
+	count, err = cleanupStaleUploads(obj, 0)
+	if err != nil {
+		t.Fatalf("%s: %s", instanceType, err)
+	}
+	if count != 2 {
+		t.Errorf("%s: Expected 2 uploads aborted, got %d", instanceType, count)
+	}
+	result, err := obj.ListMultipartUploads(bucket, "", "", "", "", maxUploadsList)
+	if err != nil {
+		t.Fatalf("%s: %s", instanceType, err)
+	}
+	if len(result.Uploads) != 0 {
+		t.Errorf("%s: Expected no uploads left, got %v", instanceType, result.Uploads)
+	}
+}
@@ -17,13 +17,21 @@
 package cmd
 
 import (
-	"bytes"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"hash"
+	"io"
 	"io/ioutil"
 	"net/http"
+	"net/url"
 	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
 	"runtime"
 	"strings"
 	"time"
@@ -36,9 +44,38 @@ import (
 // command specific flags.
 var (
 	updateFlags = []cli.Flag{
+		cli.StringFlag{
+			Name:  "channel",
+			Value: "stable",
+			Usage: "Check for releases of this channel, one of stable, rc, nightly or experimental.",
+		},
 		cli.BoolFlag{
 			Name:  "experimental, E",
-			Usage: "Check experimental update.",
+			Usage: "Check experimental update, same as --channel experimental.",
+		},
+		cli.BoolFlag{
+			Name:  "apply",
+			Usage: "Download the new release and replace the running binary with it.",
+		},
+		cli.BoolFlag{
+			Name:  "full",
+			Usage: "Always download the whole release binary, instead of a patch from the running release.",
+		},
+		cli.StringFlag{
+			Name:  "mirror",
+			Usage: "Check for and download releases from this mirror URL instead, defaults to MINIO_UPDATE_URL.",
+		},
+		cli.StringFlag{
+			Name:  "public-key",
+			Usage: "Verify release signatures with this minisign public key instead of the embedded one.",
+		},
+		cli.StringFlag{
+			Name:  "proxy",
+			Usage: "Reach the update server through this proxy URL, defaults to MINIO_UPDATE_PROXY or HTTP_PROXY/HTTPS_PROXY.",
+		},
+		cli.StringFlag{
+			Name:  "restart",
+			Usage: "Restart the server at this URL after applying the update.",
 		},
 	}
 )
@@ -58,27 +95,128 @@ USAGE:
 FLAGS:
   {{range .Flags}}{{.}}
   {{end}}
+ENVIRONMENT VARIABLES:
+   MINIO_UPDATE_URL: Mirror URL releases are checked for and downloaded from, same as --mirror.
+
 EXAMPLES:
    1. Check for any new official release.
       $ minio {{.Name}}
 
    2. Check for any new experimental release.
       $ minio {{.Name}} --experimental
+
+   3. Check for any new release candidate, or official release if newer.
+      $ minio {{.Name}} --channel rc
+
+   4. Download and install the new official release in place of the current binary.
+      $ minio {{.Name}} --apply
+
+   5. Install the new official release and restart the local server to run it.
+      $ minio {{.Name}} --apply --restart http://localhost:9000/
+
+   6. Install from an internal mirror which signs releases with its own key.
+      $ minio {{.Name}} --apply --mirror https://mirror.example.com/minio/release \
+          --public-key RWQf6LRCGA9i53mlYecO4IzT51TGPpvWucNSCh1CBM0QTaLn73Y7GFO3
+
+   7. Check for any new official release through a corporate proxy.
+      $ minio {{.Name}} --proxy http://proxy.example.com:3128
+
+   8. Check for any new release on an internal mirror.
+      $ export MINIO_UPDATE_URL=https://mirror.example.com/minio/release
+      $ minio {{.Name}}
+
+   9. Check for any new official release, printing the result as JSON.
+      $ minio {{.Name}} --json
+
+  10. Install the new official release downloading the whole binary, never a patch.
+      $ minio {{.Name}} --apply --full
 `,
 }
 
 // update URL endpoints.
 const (
 	minioUpdateStableURL       = "https://dl.minio.io/server/minio/release"
+	minioUpdateRCURL           = "https://dl.minio.io/server/minio/rc"
+	minioUpdateNightlyURL      = "https://dl.minio.io/server/minio/nightly"
 	minioUpdateExperimentalURL = "https://dl.minio.io/server/minio/experimental"
 )
 
+// updateChannel - releases `minio update` can follow, each channel
+// publishes its own minio.shasum.
+type updateChannel struct {
+	// URL releases of the channel are published under.
+	URL string
+	// Tags of release names accepted from the channel, e.g RC in
+	// minio.RC.2016-10-07T01-16-39Z. Channels also accept the tags of
+	// more stable ones, as they publish them when newer.
+	Tags []string
+}
+
+// Release channels by name.
+var updateChannels = map[string]updateChannel{
+	// "OFFICIAL" tag is still kept for backward compatibility.
+	// We should remove this for the next release.
+	"stable":       {minioUpdateStableURL, []string{"RELEASE", "OFFICIAL"}},
+	"rc":           {minioUpdateRCURL, []string{"RC", "RELEASE", "OFFICIAL"}},
+	"nightly":      {minioUpdateNightlyURL, []string{"NIGHTLY", "RC", "RELEASE", "OFFICIAL"}},
+	"experimental": {minioUpdateExperimentalURL, []string{"RELEASE", "OFFICIAL"}},
+}
+
+// errUnknownUpdateChannel - no release channel by the name.
+var errUnknownUpdateChannel = errors.New("Unknown update channel, must be one of stable, rc, nightly or experimental")
+
+// onMirror - returns the channel with its releases published on the
+// mirror at mirrorURL instead.
+func (c updateChannel) onMirror(mirrorURL string) updateChannel {
+	c.URL = mirrorURL
+	return c
+}
+
+// onEnvMirror - returns the channel with its releases published on the
+// mirror set by MINIO_UPDATE_URL, if any.
+func (c updateChannel) onEnvMirror() (updateChannel, error) {
+	mirror := os.Getenv("MINIO_UPDATE_URL")
+	if mirror == "" {
+		return c, nil
+	}
+	mirrorURL, err := parseUpdateMirror(mirror)
+	if err != nil {
+		return c, err
+	}
+	return c.onMirror(mirrorURL), nil
+}
+
+// getUpdateChannel - returns the release channel by name.
+func getUpdateChannel(name string) (updateChannel, error) {
+	channel, ok := updateChannels[name]
+	if !ok {
+		return updateChannel{}, errUnknownUpdateChannel
+	}
+	return channel, nil
+}
+
+// Maximum time allowed to download a release binary.
+const minioUpdateDownloadTimeout = 10 * time.Minute
+
+// errUpdateChecksumMismatch - downloaded binary does not match the release shasum.
+var errUpdateChecksumMismatch = errors.New("Downloaded binary does not match the release checksum")
+
 // updateMessage container to hold update messages.
 type updateMessage struct {
 	Status   string `json:"status"`
 	Update   bool   `json:"update"`
 	Download string `json:"downloadURL"`
 	Version  string `json:"version"`
+
+	// Hex encoded checksums of the release binary, from minio.shasum,
+	// and of the running binary.
+	ReleaseChecksum string `json:"releaseChecksum,omitempty"`
+	LocalChecksum   string `json:"localChecksum,omitempty"`
+	// URL of the patch from the running release to this one.
+	patch string
+	// Name of the release in minio.shasum, its signature must carry
+	// it as trusted comment.
+	release string
 }
 
 // String colorized update message.
@@ -100,7 +238,51 @@ func (u updateMessage) JSON() string {
 	return string(updateMessageJSONBytes)
 }
 
-func parseReleaseData(data string) (time.Time, error) {
+// printUpdateMsg - prints the update message, as JSON with --json.
+func printUpdateMsg(u updateMessage) {
+	if globalJSON {
+		console.Println(u.JSON())
+		return
+	}
+	console.Println(u)
+}
+
+// updateAppliedMessage - printed once `minio update --apply` has
+// installed a new release.
+type updateAppliedMessage struct {
+	Status   string `json:"status"`
+	Path     string `json:"path"`
+	Download string `json:"downloadURL"`
+	// Set if the release was rebuilt from the patch at this URL.
+	Patch string `json:"patchURL,omitempty"`
+	// Set if the server at this URL was restarted.
+	Restarted string `json:"restarted,omitempty"`
+}
+
+// String colorized message.
+func (u updateAppliedMessage) String() string {
+	updatedMessage := color.New(color.FgGreen, color.Bold).SprintfFunc()
+	msg := updatedMessage("Installed the new release of ‘minio’ at %s.", u.Path)
+	if u.Patch != "" {
+		msg += "\n" + updatedMessage("Rebuilt the new release from the patch %s.", u.Patch)
+	}
+	if u.Restarted != "" {
+		msg += "\n" + updatedMessage("Restarted the server at %s.", u.Restarted)
+	}
+	return msg
+}
+
+// JSON jsonified message.
+func (u updateAppliedMessage) JSON() string {
+	u.Status = "success"
+	data, err := json.Marshal(u)
+	fatalIf(err, "Unable to marshal into JSON.")
+	return string(data)
+}
+
+// parseReleaseData - returns the date of the release in minio.shasum
+// of channel, whose name must carry one of the tags of channel.
+func parseReleaseData(data string, channel updateChannel) (time.Time, error) {
 	releaseStr := strings.Fields(data)
 	if len(releaseStr) < 2 {
 		return time.Time{}, errors.New("Update data malformed")
@@ -113,10 +295,8 @@ func parseReleaseData(data string) (time.Time, error) {
 	if releaseDateSplits[0] != "minio" {
 		return time.Time{}, (errors.New("Update data malformed, missing minio tag"))
 	}
-	// "OFFICIAL" tag is still kept for backward compatibility.
-	// We should remove this for the next release.
-	if releaseDateSplits[1] != "RELEASE" && releaseDateSplits[1] != "OFFICIAL" {
-		return time.Time{}, (errors.New("Update data malformed, missing RELEASE tag"))
+	if !contains(channel.Tags, releaseDateSplits[1]) {
+		return time.Time{}, fmt.Errorf("Update data malformed, missing %s tag", strings.Join(channel.Tags, " or "))
 	}
 	dateSplits := strings.SplitN(releaseDateSplits[2], "T", 2)
 	if len(dateSplits) < 2 {
@@ -132,6 +312,16 @@ func parseReleaseData(data string) (time.Time, error) {
 	return parsedDate, nil
 }
 
+// parseReleaseName - returns the name of the release in minio.shasum,
+// such as minio.RELEASE.2016-10-14T04-00-39Z, validated by
+// parseReleaseData.
+func parseReleaseName(data string) string {
+	if releaseStr := strings.Fields(data); len(releaseStr) >= 2 {
+		return releaseStr[1]
+	}
+	return ""
+}
+
 // User Agent should always following the below style.
 // Please open an issue to discuss any new changes here.
 //
@@ -142,20 +332,67 @@ var (
 	userAgent       = userAgentPrefix + userAgentSuffix
 )
 
-// Check if the operating system is a docker container.
-func isDocker() bool {
-	cgroup, err := ioutil.ReadFile("/proc/self/cgroup")
-	if err != nil && os.IsNotExist(err) {
-		return false
+// getContainerUserAgent returns the container runtime part of the
+// User-Agent of update checks, Docker/ is kept for older update
+// servers.
+func getContainerUserAgent() string {
+	name := getContainerRuntime()
+	if name == containerRuntimeNone {
+		name = "none"
+	}
+	return fmt.Sprintf("Docker/%t Container/%s", name == containerRuntimeDocker, name)
+}
+
+// parseUpdateMirror validates the --mirror URL and returns it without
+// a trailing slash.
+func parseUpdateMirror(mirror string) (string, error) {
+	mirrorURL, err := url.Parse(mirror)
+	if err != nil {
+		return "", err
+	}
+	if mirrorURL.Scheme != "http" && mirrorURL.Scheme != "https" {
+		return "", errors.New("Mirror URL scheme must be one of http or https")
+	}
+	if mirrorURL.Host == "" {
+		return "", errors.New("Mirror URL is missing a host")
+	}
+	return strings.TrimSuffix(mirror, "/"), nil
+}
+
+// parseReleaseChecksum returns the hex encoded checksum of minio.shasum,
+// mirrors must serve the same format as the official releases.
+func parseReleaseChecksum(data string) (string, error) {
+	fields := strings.Fields(data)
+	if len(fields) == 0 {
+		return "", errors.New("Update data malformed")
+	}
+	if _, err := hex.DecodeString(fields[0]); err != nil {
+		return "", errors.New("Update data malformed, checksum is not hex encoded")
+	}
+	if _, err := newReleaseHash(fields[0]); err != nil {
+		return "", err
+	}
+	return fields[0], nil
+}
+
+// newUpdateClient returns the client used to reach the update server,
+// through the configured proxy if any.
+func newUpdateClient(duration time.Duration) *http.Client {
+	transport := &http.Transport{
+		TLSClientConfig:     newOutboundTLSConfig(false),
+		TLSHandshakeTimeout: 10 * time.Second,
+	}
+	setTransportProxy(transport, globalUpdateProxyURL, duration)
+	return &http.Client{
+		Timeout:   duration,
+		Transport: transport,
 	}
-	fatalIf(err, "Unable to read `cgroup` file.")
-	return bytes.Contains(cgroup, []byte("docker"))
 }
 
 // verify updates for releases.
-func getReleaseUpdate(updateURL string, duration time.Duration) (updateMsg updateMessage, errMsg string, err error) {
+func getReleaseUpdate(channel updateChannel, duration time.Duration) (updateMsg updateMessage, errMsg string, err error) {
 	// Construct a new update url.
-	newUpdateURLPrefix := updateURL + "/" + runtime.GOOS + "-" + runtime.GOARCH
+	newUpdateURLPrefix := channel.URL + "/" + runtime.GOOS + "-" + runtime.GOARCH
 	newUpdateURL := newUpdateURLPrefix + "/minio.shasum"
 
 	// Get the downloadURL.
@@ -176,9 +413,7 @@ func getReleaseUpdate(updateURL string, duration time.Duration) (updateMsg updat
 	}
 
 	// Instantiate a new client with 3 sec timeout.
-	client := &http.Client{
-		Timeout: duration,
-	}
+	client := newUpdateClient(duration)
 
 	// Parse current minio version into RFC3339.
 	current, err := time.Parse(time.RFC3339, Version)
@@ -201,7 +436,7 @@ func getReleaseUpdate(updateURL string, duration time.Duration) (updateMsg updat
 	}
 
 	// Set user agent.
-	req.Header.Set("User-Agent", userAgent+" "+fmt.Sprintf("Docker/%t", isDocker()))
+	req.Header.Set("User-Agent", userAgent+" "+getContainerUserAgent())
 
 	// Fetch new update.
 	resp, err := client.Do(req)
@@ -228,7 +463,7 @@ func getReleaseUpdate(updateURL string, duration time.Duration) (updateMsg updat
 	errMsg = "Failed to retrieve update notice. Please try again later. Please report this issue at https://github.com/minio/minio/issues"
 
 	// Parse the date if its valid.
-	latest, err := parseReleaseData(string(updateBody))
+	latest, err := parseReleaseData(string(updateBody), channel)
 	if err != nil {
 		return
 	}
@@ -239,32 +474,268 @@ func getReleaseUpdate(updateURL string, duration time.Duration) (updateMsg updat
 		return
 	}
 
-	// Is the update latest?.
+	updateMsg.ReleaseChecksum, err = parseReleaseChecksum(string(updateBody))
+	if err != nil {
+		return
+	}
+	updateMsg.release = parseReleaseName(string(updateBody))
+
+	// Checksum of the running binary, left out if it cannot be read.
+	if exePath, perr := getExecutablePath(); perr == nil {
+		updateMsg.LocalChecksum, _ = fileChecksum(exePath, updateMsg.ReleaseChecksum)
+	}
+
+	// Is the update latest?. A locally patched binary of the same
+	// release is replaced too, but never by an older release.
 	if latest.After(current) {
 		updateMsg.Update = true
+	} else if !latest.Before(current) && updateMsg.LocalChecksum != "" &&
+		!strings.EqualFold(updateMsg.LocalChecksum, updateMsg.ReleaseChecksum) {
+		updateMsg.Update = true
 	}
+	updateMsg.patch = getUpdatePatchURL(downloadURL, ReleaseTag)
 
 	// Return update message.
 	return updateMsg, "", nil
 }
 
+// newReleaseHash returns the hash the release checksum was computed
+// with, minio.shasum carries either a sha1 or a sha256 hex digest.
+func newReleaseHash(checksum string) (hash.Hash, error) {
+	switch len(checksum) {
+	case hex.EncodedLen(sha1.Size):
+		return sha1.New(), nil
+	case hex.EncodedLen(sha256.Size):
+		return sha256.New(), nil
+	}
+	return nil, errors.New("Update data malformed, unrecognized checksum")
+}
+
+// fileChecksum returns the hex encoded checksum of the file at
+// filePath, with the hash releaseChecksum was computed with.
+func fileChecksum(filePath, releaseChecksum string) (string, error) {
+	h, err := newReleaseHash(releaseChecksum)
+	if err != nil {
+		return "", err
+	}
+	file, err := os.Open(filePath)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+	if _, err = io.Copy(h, file); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// fetchUpdate writes the body of url to writer.
+func fetchUpdate(client *http.Client, url string, writer io.Writer) error {
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("User-Agent", userAgent)
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return errors.New("http status : " + resp.Status)
+	}
+	_, err = io.Copy(writer, resp.Body)
+	return err
+}
+
+// downloadUpdate fetches the release binary into a temporary file in
+// the same directory as exePath, so that it can be renamed over it
+// atomically, and verifies it against the release checksum and the
+// release signature made by pubKey. The binary is rebuilt from a
+// patch against exePath if one is published, patched is set then.
+func downloadUpdate(updateMsg updateMessage, pubKey minisignPublicKey, exePath string, duration time.Duration) (string, bool, error) {
+	h, err := newReleaseHash(updateMsg.ReleaseChecksum)
+	if err != nil {
+		return "", false, err
+	}
+
+	// The new binary inherits the permissions of the current one.
+	fi, err := os.Stat(exePath)
+	if err != nil {
+		return "", false, err
+	}
+
+	tmpFile, err := ioutil.TempFile(filepath.Dir(exePath), ".minio.update.")
+	if err != nil {
+		return "", false, err
+	}
+	tmpPath := tmpFile.Name()
+	defer func() {
+		if err != nil {
+			tmpFile.Close()
+			os.Remove(tmpPath)
+		}
+	}()
+
+	// A patch is much smaller than the release binary, fall back to
+	// the full binary if it is not published or does not rebuild the
+	// release.
+	client := newUpdateClient(duration)
+	patched := patchUpdate(client, updateMsg, exePath, io.MultiWriter(tmpFile, h)) == nil &&
+		strings.EqualFold(hex.EncodeToString(h.Sum(nil)), updateMsg.ReleaseChecksum)
+	if !patched {
+		h.Reset()
+		if err = resetUpdateFile(tmpFile); err != nil {
+			return "", false, err
+		}
+		if err = fetchUpdate(client, updateMsg.Download, io.MultiWriter(tmpFile, h)); err != nil {
+			return "", false, err
+		}
+	}
+	if err = tmpFile.Sync(); err != nil {
+		return "", false, err
+	}
+	if err = tmpFile.Close(); err != nil {
+		return "", false, err
+	}
+	if err = os.Chmod(tmpPath, fi.Mode().Perm()); err != nil {
+		return "", false, err
+	}
+	if !strings.EqualFold(hex.EncodeToString(h.Sum(nil)), updateMsg.ReleaseChecksum) {
+		err = errUpdateChecksumMismatch
+		return "", false, err
+	}
+	if err = verifyReleaseSignature(client, updateMsg.Download, tmpPath, updateMsg.release, pubKey); err != nil {
+		return "", false, err
+	}
+	return tmpPath, patched, nil
+}
+
+// applyUpdate downloads the release described by updateMsg and
+// replaces the binary at exePath with it, returns if the release was
+// rebuilt from a patch.
+func applyUpdate(updateMsg updateMessage, pubKey minisignPublicKey, exePath string, duration time.Duration) (bool, error) {
+	tmpPath, patched, err := downloadUpdate(updateMsg, pubKey, exePath, duration)
+	if err != nil {
+		return false, err
+	}
+	if err = replaceExecutable(tmpPath, exePath); err != nil {
+		os.Remove(tmpPath)
+		return false, err
+	}
+	return patched, nil
+}
+
+// getExecutablePath returns the path of the running binary with
+// symlinks resolved, so that the link itself is left in place. It is
+// read from /proc/self/exe where available, otherwise looked up from
+// the name the binary was started with.
+func getExecutablePath() (string, error) {
+	exePath, err := os.Readlink("/proc/self/exe")
+	if err != nil {
+		if exePath, err = exec.LookPath(os.Args[0]); err != nil {
+			return "", err
+		}
+		if exePath, err = filepath.Abs(exePath); err != nil {
+			return "", err
+		}
+	}
+	return filepath.EvalSymlinks(exePath)
+}
+
+// restartServer restarts only the server at serverURL, the new binary
+// has been installed on this node alone.
+func restartServer(serverURL string) error {
+	parsedURL, err := url.Parse(serverURL)
+	if err != nil {
+		return err
+	}
+	authCfg := &authConfig{
+		accessKey:   serverConfig.GetCredential().AccessKeyID,
+		secretKey:   serverConfig.GetCredential().SecretAccessKey,
+		secureConn:  parsedURL.Scheme == "https",
+		address:     parsedURL.Host,
+		path:        path.Join(reservedBucket, controlPath),
+		loginMethod: "Control.LoginHandler",
+	}
+	client := newAuthClient(authCfg)
+	defer client.Close()
+
+	args := &ServiceArgs{
+		GenericArgs: GenericArgs{IdempotencyKey: getUUID()},
+		Signal:      serviceRestart,
+	}
+	return client.Call("Control.ServiceHandler", args, &ServiceReply{})
+}
+
 // main entry point for update command.
 func mainUpdate(ctx *cli.Context) {
+	setJSONOutput(ctx)
+
 	// Error out if 'update' command is issued for development based builds.
 	if Version == "DEVELOPMENT.GOGET" {
 		fatalIf(errors.New(""), "Update mechanism is not supported for ‘go get’ based binary builds. Please download official releases from https://minio.io/#minio")
 	}
 
-	// Check for update.
-	var updateMsg updateMessage
-	var errMsg string
-	var err error
-	var secs = time.Second * 3
+	if proxy := ctx.String("proxy"); proxy != "" {
+		proxyURL, err := parseProxyURL(proxy)
+		fatalIf(err, "Invalid proxy URL %s.", proxy)
+		globalUpdateProxyURL = proxyURL
+	}
+
+	channelName := ctx.String("channel")
 	if ctx.Bool("experimental") {
-		updateMsg, errMsg, err = getReleaseUpdate(minioUpdateExperimentalURL, secs)
-	} else {
-		updateMsg, errMsg, err = getReleaseUpdate(minioUpdateStableURL, secs)
+		if ctx.IsSet("channel") && channelName != "experimental" {
+			fatalIf(errInvalidArgument, "--experimental cannot be used with --channel %s.", channelName)
+		}
+		channelName = "experimental"
+	}
+	channel, err := getUpdateChannel(channelName)
+	fatalIf(err, "Invalid update channel %s.", channelName)
+
+	mirror := ctx.String("mirror")
+	if mirror == "" {
+		mirror = os.Getenv("MINIO_UPDATE_URL")
+	}
+	if mirror != "" {
+		mirrorURL, err := parseUpdateMirror(mirror)
+		fatalIf(err, "Invalid mirror URL %s.", mirror)
+		channel = channel.onMirror(mirrorURL)
 	}
+
+	// Check for update.
+	updateMsg, errMsg, err := getReleaseUpdate(channel, time.Second*3)
 	fatalIf(err, errMsg)
-	console.Println(updateMsg)
+	printUpdateMsg(updateMsg)
+	if !ctx.Bool("apply") || !updateMsg.Update {
+		return
+	}
+
+	if isContainer() {
+		fatalIf(errors.New(""), "Update mechanism is not supported for %s containers. Please pull the latest image instead.", getContainerRuntime())
+	}
+	pubKey, err := getReleasePublicKey(ctx.String("public-key"))
+	fatalIf(err, "Unable to load the release public key, please provide one with --public-key.")
+	exePath, err := getExecutablePath()
+	fatalIf(err, "Unable to locate the running ‘minio’ binary.")
+	if ctx.Bool("full") {
+		updateMsg.patch = ""
+	}
+	patched, err := applyUpdate(updateMsg, pubKey, exePath, minioUpdateDownloadTimeout)
+	fatalIf(err, "Unable to apply update from %s.", updateMsg.Download)
+	appliedMsg := updateAppliedMessage{Path: exePath, Download: updateMsg.Download}
+	if patched {
+		appliedMsg.Patch = updateMsg.patch
+	}
+
+	if serverURL := ctx.String("restart"); serverURL != "" {
+		err = restartServer(serverURL)
+		fatalIf(err, "Unable to restart the server at %s.", serverURL)
+		appliedMsg.Restarted = serverURL
+	}
+	if globalJSON {
+		console.Println(appliedMsg.JSON())
+	} else {
+		console.Println(appliedMsg)
+	}
 }
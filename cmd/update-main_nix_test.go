@@ -36,6 +36,14 @@ func TestReleaseUpdateVersion(t *testing.T) {
 	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		fmt.Fprintln(w, "fbe246edbd382902db9a4035df7dce8cb441357d minio.RELEASE.2016-10-07T01-16-39Z")
 	}))
+	exePath, err := getExecutablePath()
+	if err != nil {
+		t.Fatal(err)
+	}
+	localChecksum, err := fileChecksum(exePath, "fbe246edbd382902db9a4035df7dce8cb441357d")
+	if err != nil {
+		t.Fatal(err)
+	}
 	userAgentSuffix = "Minio/" + Version + " " + "Minio/" + ReleaseTag + " " + "Minio/" + CommitID
 	userAgentPrefix = "Minio (" + runtime.GOOS + "; " + runtime.GOARCH + ") "
 	userAgent = userAgentPrefix + userAgentSuffix
@@ -49,9 +57,13 @@ func TestReleaseUpdateVersion(t *testing.T) {
 		{
 			updateURL: ts.URL,
 			updateMsg: updateMessage{
-				Download: ts.URL + "/" + runtime.GOOS + "-" + runtime.GOARCH + "/minio",
-				Version:  "2016-10-06T00:08:32Z",
-				Update:   true,
+				Download:        ts.URL + "/" + runtime.GOOS + "-" + runtime.GOARCH + "/minio",
+				Version:         "2016-10-06T00:08:32Z",
+				Update:          true,
+				ReleaseChecksum: "fbe246edbd382902db9a4035df7dce8cb441357d",
+				LocalChecksum:   localChecksum,
+				patch:           ts.URL + "/" + runtime.GOOS + "-" + runtime.GOARCH + "/minio.RELEASE.2016-10-06T00-08-32Z.bsdiff",
+				release:         "minio.RELEASE.2016-10-07T01-16-39Z",
 			},
 			errMsg:     "",
 			shouldPass: true,
@@ -60,7 +72,7 @@ func TestReleaseUpdateVersion(t *testing.T) {
 
 	// Validates all the errors reported.
 	for i, testCase := range testCases {
-		updateMsg, errMsg, err := getReleaseUpdate(testCase.updateURL, time.Second*1)
+		updateMsg, errMsg, err := getReleaseUpdate(updateChannels["stable"].onMirror(testCase.updateURL), time.Second*1)
 		if testCase.shouldPass && err != nil {
 			t.Errorf("Test %d: Unable to fetch release update %s", i+1, err)
 		}
@@ -98,7 +110,7 @@ func TestReleaseUpdate(t *testing.T) {
 
 	// Validates all the errors reported.
 	for i, testCase := range testCases {
-		updateMsg, errMsg, err := getReleaseUpdate(testCase.updateURL, time.Second*1)
+		updateMsg, errMsg, err := getReleaseUpdate(updateChannels["stable"].onMirror(testCase.updateURL), time.Second*1)
 		if testCase.shouldPass && err != nil {
 			t.Errorf("Test %d: Unable to fetch release update %s", i+1, err)
 		}
@@ -0,0 +1,408 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Validates downloading, verifying and installing a release binary.
+func TestApplyUpdate(t *testing.T) {
+	newBinary := []byte("#!/bin/sh\necho new minio\n")
+	sha1Sum := sha1.Sum(newBinary)
+	sha256Sum := sha256.Sum256(newBinary)
+
+	priv, pubKey := newTestMinisignKey(t, "12345678")
+	otherPriv, _ := newTestMinisignKey(t, "87654321")
+	key, err := parseMinisignPublicKey(pubKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/minio", "/forged/minio", "/older/minio":
+			w.Write(newBinary)
+		case "/minio.minisig":
+			w.Write(signTestMinisign(priv, "12345678", testReleaseName, newBinary))
+		case "/forged/minio.minisig":
+			w.Write(signTestMinisign(otherPriv, "87654321", testReleaseName, newBinary))
+		case "/older/minio.minisig":
+			w.Write(signTestMinisign(priv, "12345678", "minio.RELEASE.2016-09-11T17-42-18Z", newBinary))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer ts.Close()
+
+	testCases := []struct {
+		download string
+		checksum string
+		err      error
+	}{
+		// Test case 1: sha1 checksum.
+		{ts.URL + "/minio", hex.EncodeToString(sha1Sum[:]), nil},
+		// Test case 2: sha256 checksum, upper case hex.
+		{ts.URL + "/minio", strings.ToUpper(hex.EncodeToString(sha256Sum[:])), nil},
+		// Test case 3: binary does not match the checksum.
+		{ts.URL + "/minio", "fbe246edbd382902db9a4035df7dce8cb441357d", errUpdateChecksumMismatch},
+		// Test case 4: binary signed by an unknown key.
+		{ts.URL + "/forged/minio", hex.EncodeToString(sha256Sum[:]), errUpdateSignatureKeyID},
+		// Test case 5: binary signed as another release.
+		{ts.URL + "/older/minio", hex.EncodeToString(sha256Sum[:]), errUpdateSignatureRelease},
+	}
+
+	for i, testCase := range testCases {
+		dir, err := ioutil.TempDir("", "minio-update-")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer removeAll(dir)
+
+		exePath := filepath.Join(dir, "minio")
+		if err = ioutil.WriteFile(exePath, []byte("old minio"), 0755); err != nil {
+			t.Fatal(err)
+		}
+
+		updateMsg := updateMessage{
+			Download:        testCase.download,
+			Update:          true,
+			ReleaseChecksum: testCase.checksum,
+			release:         testReleaseName,
+		}
+		_, err = applyUpdate(updateMsg, key, exePath, time.Second*5)
+		if err != testCase.err {
+			t.Fatalf("Test %d: Expected error %v, got %v", i+1, testCase.err, err)
+		}
+
+		data, err := ioutil.ReadFile(exePath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		expected := newBinary
+		if testCase.err != nil {
+			expected = []byte("old minio")
+		}
+		if string(data) != string(expected) {
+			t.Errorf("Test %d: Expected binary %q, got %q", i+1, expected, data)
+		}
+		fi, err := os.Stat(exePath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if fi.Mode().Perm() != 0755 {
+			t.Errorf("Test %d: Expected mode 0755, got %v", i+1, fi.Mode().Perm())
+		}
+
+		// The temporary download must never be left behind.
+		matches, err := filepath.Glob(filepath.Join(dir, ".minio.update.*"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(matches) != 0 {
+			t.Errorf("Test %d: Expected no leftover downloads, got %v", i+1, matches)
+		}
+	}
+}
+
+// Validates that failed downloads leave the current binary in place.
+func TestApplyUpdateFailure(t *testing.T) {
+	newBinary := []byte("#!/bin/sh\necho new minio\n")
+	sha256Sum := sha256.Sum256(newBinary)
+	_, pubKey := newTestMinisignKey(t, "12345678")
+	key, err := parseMinisignPublicKey(pubKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Serves the binary but no signature for it.
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/unsigned/minio" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write(newBinary)
+	}))
+	defer ts.Close()
+
+	dir, err := ioutil.TempDir("", "minio-update-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer removeAll(dir)
+	exePath := filepath.Join(dir, "minio")
+	if err = ioutil.WriteFile(exePath, []byte("old minio"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []updateMessage{
+		// Test case 1: release binary not found.
+		{Download: ts.URL + "/minio", ReleaseChecksum: "fbe246edbd382902db9a4035df7dce8cb441357d"},
+		// Test case 2: unrecognized checksum.
+		{Download: ts.URL + "/minio", ReleaseChecksum: "fbe246"},
+		// Test case 3: release signature not found.
+		{Download: ts.URL + "/unsigned/minio", ReleaseChecksum: hex.EncodeToString(sha256Sum[:])},
+	}
+	for i, updateMsg := range testCases {
+		if _, err = applyUpdate(updateMsg, key, exePath, time.Second*5); err == nil {
+			t.Errorf("Test %d: Expected update to fail", i+1)
+		}
+		data, err := ioutil.ReadFile(exePath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(data) != "old minio" {
+			t.Errorf("Test %d: Expected binary to be untouched, got %q", i+1, data)
+		}
+	}
+}
+
+// Validates that update checks are sent through the configured proxy.
+func TestReleaseUpdateProxy(t *testing.T) {
+	defer func(version string) { Version = version }(Version)
+	Version = "2016-10-06T00:08:32Z"
+
+	var proxied string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Proxied requests carry the absolute URL.
+		proxied = r.URL.String()
+		fmt.Fprintln(w, "fbe246edbd382902db9a4035df7dce8cb441357d minio.RELEASE.2016-10-07T01-16-39Z")
+	}))
+	defer proxy.Close()
+
+	proxyURL, err := parseProxyURL(proxy.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	globalUpdateProxyURL = proxyURL
+	defer func() { globalUpdateProxyURL = nil }()
+
+	updateURL := "http://dl.minio.invalid/server/minio/release"
+	updateMsg, _, err := getReleaseUpdate(updateChannels["stable"].onMirror(updateURL), time.Second*5)
+	if err != nil {
+		t.Fatalf("Unable to fetch release update through proxy %s", err)
+	}
+	if !updateMsg.Update {
+		t.Errorf("Expected an update to be available")
+	}
+	if updateMsg.release != testReleaseName {
+		t.Errorf("Expected release %s, got %s", testReleaseName, updateMsg.release)
+	}
+	expected := updateURL + "/" + runtime.GOOS + "-" + runtime.GOARCH + "/minio.shasum"
+	if proxied != expected {
+		t.Errorf("Expected proxy to receive %s, got %s", expected, proxied)
+	}
+}
+
+// Validates parsing of the --mirror flag and MINIO_UPDATE_URL.
+func TestParseUpdateMirror(t *testing.T) {
+	testCases := []struct {
+		mirror     string
+		expected   string
+		shouldPass bool
+	}{
+		{"https://mirror.example.com/minio/release", "https://mirror.example.com/minio/release", true},
+		{"http://10.0.0.1:8080/minio/release/", "http://10.0.0.1:8080/minio/release", true},
+		{"ftp://mirror.example.com/minio/release", "", false},
+		{"mirror.example.com/minio/release", "", false},
+		{"https://", "", false},
+	}
+	for i, testCase := range testCases {
+		mirrorURL, err := parseUpdateMirror(testCase.mirror)
+		if testCase.shouldPass && err != nil {
+			t.Errorf("Test %d: Expected to pass, got %s", i+1, err)
+		}
+		if !testCase.shouldPass && err == nil {
+			t.Errorf("Test %d: Expected to fail", i+1)
+		}
+		if mirrorURL != testCase.expected {
+			t.Errorf("Test %d: Expected %s, got %s", i+1, testCase.expected, mirrorURL)
+		}
+	}
+}
+
+// Validates MINIO_UPDATE_URL moves the channel to the mirror.
+func TestUpdateChannelOnEnvMirror(t *testing.T) {
+	defer os.Unsetenv("MINIO_UPDATE_URL")
+	stable := updateChannels["stable"]
+	testCases := []struct {
+		env        string
+		expected   string
+		shouldPass bool
+	}{
+		{"", stable.URL, true},
+		{"https://mirror.example.com/minio/release/", "https://mirror.example.com/minio/release", true},
+		{"ftp://mirror.example.com/minio/release", stable.URL, false},
+	}
+	for i, testCase := range testCases {
+		os.Setenv("MINIO_UPDATE_URL", testCase.env)
+		channel, err := stable.onEnvMirror()
+		if testCase.shouldPass && err != nil {
+			t.Errorf("Test %d: Expected to pass, got %s", i+1, err)
+		}
+		if !testCase.shouldPass && err == nil {
+			t.Errorf("Test %d: Expected to fail", i+1)
+		}
+		if channel.URL != testCase.expected || strings.Join(channel.Tags, ",") != strings.Join(stable.Tags, ",") {
+			t.Errorf("Test %d: Expected channel on %s, got %+v", i+1, testCase.expected, channel)
+		}
+	}
+}
+
+// Validates mirrors serving a malformed minio.shasum are rejected.
+func TestReleaseUpdateChecksumFormat(t *testing.T) {
+	defer func(version string) { Version = version }(Version)
+	Version = "2016-10-06T00:08:32Z"
+
+	testCases := []struct {
+		shasum     string
+		shouldPass bool
+	}{
+		{"fbe246edbd382902db9a4035df7dce8cb441357d minio.RELEASE.2016-10-07T01-16-39Z", true},
+		{"8e1a4c4cfb3fcbb88ef7ffcde9ba0c93c2ac2eedcbb4b3fb1b8ecb6e6f0bd0c9 minio.RELEASE.2016-10-07T01-16-39Z", true},
+		{"fbe246edbd382902db9a4035df7dce8cb44135 minio.RELEASE.2016-10-07T01-16-39Z", false},
+		{"zbe246edbd382902db9a4035df7dce8cb441357d minio.RELEASE.2016-10-07T01-16-39Z", false},
+		{"minio.RELEASE.2016-10-07T01-16-39Z fbe246edbd382902db9a4035df7dce8cb441357d", false},
+	}
+	for i, testCase := range testCases {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprintln(w, testCase.shasum)
+		}))
+		_, _, err := getReleaseUpdate(updateChannels["stable"].onMirror(ts.URL), time.Second*5)
+		ts.Close()
+		if testCase.shouldPass && err != nil {
+			t.Errorf("Test %d: Expected to pass, got %s", i+1, err)
+		}
+		if !testCase.shouldPass && err == nil {
+			t.Errorf("Test %d: Expected to fail", i+1)
+		}
+	}
+}
+
+// Validates that a running binary differing from the release is
+// updated, unless the release is older.
+func TestReleaseUpdateChecksum(t *testing.T) {
+	defer func(version string) { Version = version }(Version)
+	Version = "2016-10-06T00:08:32Z"
+
+	exePath, err := getExecutablePath()
+	if err != nil {
+		t.Fatal(err)
+	}
+	localChecksum, err := fileChecksum(exePath, "fbe246edbd382902db9a4035df7dce8cb441357d")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		shasum string
+		update bool
+	}{
+		// Test case 1: running binary is the release.
+		{localChecksum + " minio.RELEASE.2016-10-06T00-08-32Z", false},
+		// Test case 2: running binary is a patched build of the release.
+		{"fbe246edbd382902db9a4035df7dce8cb441357d minio.RELEASE.2016-10-06T00-08-32Z", true},
+		// Test case 3: release is older than the running binary.
+		{"fbe246edbd382902db9a4035df7dce8cb441357d minio.RELEASE.2016-09-11T17-42-18Z", false},
+	}
+	for i, testCase := range testCases {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprintln(w, testCase.shasum)
+		}))
+		updateMsg, _, err := getReleaseUpdate(updateChannels["stable"].onMirror(ts.URL), time.Second*5)
+		ts.Close()
+		if err != nil {
+			t.Fatalf("Test %d: Unable to fetch release update %s", i+1, err)
+		}
+		if updateMsg.Update != testCase.update {
+			t.Errorf("Test %d: Expected update %v, got %v", i+1, testCase.update, updateMsg.Update)
+		}
+		if updateMsg.LocalChecksum != localChecksum {
+			t.Errorf("Test %d: Expected local checksum %s, got %s", i+1, localChecksum, updateMsg.LocalChecksum)
+		}
+	}
+}
+
+// Validates the message printed once an update is applied.
+func TestUpdateAppliedMessage(t *testing.T) {
+	msg := updateAppliedMessage{
+		Path:      "/usr/local/bin/minio",
+		Download:  "https://dl.minio.io/server/minio/release/linux-amd64/minio",
+		Restarted: "http://localhost:9000/",
+	}
+	expected := `{"status":"success","path":"/usr/local/bin/minio","downloadURL":"https://dl.minio.io/server/minio/release/linux-amd64/minio","restarted":"http://localhost:9000/"}`
+	if msg.JSON() != expected {
+		t.Errorf("Expected %s, got %s", expected, msg.JSON())
+	}
+	if !strings.Contains(msg.String(), "/usr/local/bin/minio") || !strings.Contains(msg.String(), "http://localhost:9000/") {
+		t.Errorf("Expected message to mention the binary and the server, got %s", msg.String())
+	}
+}
+
+// Validates release names are only accepted from the channels
+// publishing them.
+func TestParseReleaseDataChannel(t *testing.T) {
+	testCases := []struct {
+		data       string
+		channel    string
+		shouldPass bool
+	}{
+		{"fbe246edbd382902db9a4035df7dce8cb441357d minio.RELEASE.2016-10-07T01-16-39Z", "stable", true},
+		{"fbe246edbd382902db9a4035df7dce8cb441357d minio.OFFICIAL.2016-10-07T01-16-39Z", "stable", true},
+		{"fbe246edbd382902db9a4035df7dce8cb441357d minio.RC.2016-10-07T01-16-39Z", "stable", false},
+		{"fbe246edbd382902db9a4035df7dce8cb441357d minio.NIGHTLY.2016-10-07T01-16-39Z", "stable", false},
+		{"fbe246edbd382902db9a4035df7dce8cb441357d minio.RC.2016-10-07T01-16-39Z", "rc", true},
+		{"fbe246edbd382902db9a4035df7dce8cb441357d minio.RELEASE.2016-10-07T01-16-39Z", "rc", true},
+		{"fbe246edbd382902db9a4035df7dce8cb441357d minio.NIGHTLY.2016-10-07T01-16-39Z", "rc", false},
+		{"fbe246edbd382902db9a4035df7dce8cb441357d minio.NIGHTLY.2016-10-07T01-16-39Z", "nightly", true},
+		{"fbe246edbd382902db9a4035df7dce8cb441357d minio.RC.2016-10-07T01-16-39Z", "nightly", true},
+		{"fbe246edbd382902db9a4035df7dce8cb441357d minio.RC.2016-10-07T01-16-39Z", "experimental", false},
+		{"fbe246edbd382902db9a4035df7dce8cb441357d minio.RELEASE.2016-10-07T01-16-39Z", "experimental", true},
+	}
+	expected := time.Date(2016, 10, 7, 1, 16, 39, 0, time.UTC)
+	for i, testCase := range testCases {
+		channel, err := getUpdateChannel(testCase.channel)
+		if err != nil {
+			t.Fatalf("Test %d: Unable to get channel %s, %s", i+1, testCase.channel, err)
+		}
+		date, err := parseReleaseData(testCase.data, channel)
+		if testCase.shouldPass && err != nil {
+			t.Errorf("Test %d: Expected to pass, got %s", i+1, err)
+		}
+		if !testCase.shouldPass && err == nil {
+			t.Errorf("Test %d: Expected to fail", i+1)
+		}
+		if testCase.shouldPass && !date.Equal(expected) {
+			t.Errorf("Test %d: Expected %s, got %s", i+1, expected, date)
+		}
+	}
+	if _, err := getUpdateChannel("beta"); err != errUnknownUpdateChannel {
+		t.Errorf("Expected %s, got %s", errUnknownUpdateChannel, err)
+	}
+}
@@ -20,11 +20,87 @@ import (
 	"fmt"
 	"runtime"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/cheggaaa/pb"
 	"github.com/fatih/color"
+	"github.com/minio/mc/pkg/console"
 )
 
+// Interval between background update checks of the server, set with
+// MINIO_UPDATE_CHECK_INTERVAL. Zero disables them.
+var globalUpdateCheckInterval time.Duration
+
+// Maximum time allowed for a background update check.
+const updateCheckTimeout = 10 * time.Second
+
+// updateNotifier - keeps the release found by the background update
+// checks of the server.
+type updateNotifier struct {
+	mutex   *sync.Mutex
+	channel updateChannel
+	// Zero until a newer release is found.
+	latest updateMessage
+}
+
+// Variable holding the release found by background update checks.
+var globalUpdateNotifier = newUpdateNotifier(updateChannels["stable"])
+
+// newUpdateNotifier - returns a notifier checking for releases of
+// channel.
+func newUpdateNotifier(channel updateChannel) *updateNotifier {
+	return &updateNotifier{
+		mutex:   &sync.Mutex{},
+		channel: channel,
+	}
+}
+
+// check - checks for a newer release, prints a one line notice on the
+// console the first time a release is found.
+func (n *updateNotifier) check() error {
+	updateMsg, errMsg, err := getReleaseUpdate(n.channel, updateCheckTimeout)
+	if err != nil {
+		if errMsg != "" {
+			return fmt.Errorf("%s %s", errMsg, err)
+		}
+		return err
+	}
+	if !updateMsg.Update {
+		return nil
+	}
+
+	n.mutex.Lock()
+	found := updateMsg.ReleaseChecksum != n.latest.ReleaseChecksum
+	n.latest = updateMsg
+	n.mutex.Unlock()
+
+	if found && globalJSON {
+		console.Println(updateMsg.JSON())
+	} else if found {
+		yellow := color.New(color.FgYellow, color.Bold).SprintfFunc()
+		console.Println(yellow("A new release of ‘minio’ is available at %s, run ‘minio update --apply’ to install it.", updateMsg.Download))
+	}
+	return nil
+}
+
+// Available - returns the download URL of the newer release found,
+// empty if none was found.
+func (n *updateNotifier) Available() string {
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+	return n.latest.Download
+}
+
+// startUpdateNotifier - checks for a newer release right away and then
+// every interval, for the lifetime of the server.
+func startUpdateNotifier(interval time.Duration) {
+	go func() {
+		errorIf(globalUpdateNotifier.check(), "Unable to check for a new release.")
+	}()
+	globalTaskManager.Start(updateCheckTask, interval, globalUpdateNotifier.check)
+}
+
 // colorizeUpdateMessage - inspired from Yeoman project npm package https://github.com/yeoman/update-notifier
 func colorizeUpdateMessage(updateString string) string {
 	// Initialize coloring.
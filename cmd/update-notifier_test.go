@@ -0,0 +1,77 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"testing"
+)
+
+// Tests background update checks keep the newer release found.
+func TestUpdateNotifierCheck(t *testing.T) {
+	defer func(version string) { Version = version }(Version)
+
+	exePath, err := getExecutablePath()
+	if err != nil {
+		t.Fatal(err)
+	}
+	localChecksum, err := fileChecksum(exePath, "fbe246edbd382902db9a4035df7dce8cb441357d")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The release published is the running binary.
+	shasum := localChecksum + " minio.RELEASE.2016-10-07T01-16-39Z"
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintln(w, shasum)
+	}))
+	defer ts.Close()
+	download := ts.URL + "/" + runtime.GOOS + "-" + runtime.GOARCH + "/minio"
+	if runtime.GOOS == "windows" {
+		download += ".exe"
+	}
+
+	testCases := []struct {
+		version   string
+		available string
+		shouldErr bool
+	}{
+		// Test 1: running the latest release.
+		{"2016-10-07T01:16:39Z", "", false},
+		// Test 2: a newer release is available.
+		{"2016-10-06T00:08:32Z", download, false},
+		// Test 3: custom builds cannot be checked.
+		{"DEVELOPMENT.GOGET", "", true},
+	}
+	for i, testCase := range testCases {
+		Version = testCase.version
+		notifier := newUpdateNotifier(updateChannels["stable"].onMirror(ts.URL))
+		err = notifier.check()
+		if testCase.shouldErr && err == nil {
+			t.Errorf("Test %d: Expected check to fail", i+1)
+		}
+		if !testCase.shouldErr && err != nil {
+			t.Errorf("Test %d: Unexpected error %s", i+1, err)
+		}
+		if available := notifier.Available(); available != testCase.available {
+			t.Errorf("Test %d: Expected %s to be available, got %s", i+1, testCase.available, available)
+		}
+	}
+}
@@ -0,0 +1,181 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package cmd
+
+import (
+	"bytes"
+	"compress/bzip2"
+	"encoding/binary"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// Patches are produced by bsdiff, in its BSDIFF40 format.
+const (
+	bsdiffMagic      = "BSDIFF40"
+	bsdiffHeaderSize = 32
+	bsdiffSuffix     = ".bsdiff"
+)
+
+// Largest patch fetched and largest binary rebuilt from a patch.
+const (
+	maxUpdatePatchSize   = 256 * 1024 * 1024
+	maxUpdatePatchedSize = 1024 * 1024 * 1024
+)
+
+var (
+	// errUpdatePatchUnavailable - no patch from the running release
+	// to the latest one is published.
+	errUpdatePatchUnavailable = errors.New("No patch available from the running release")
+	// errUpdatePatchMalformed - patch is not a valid bsdiff patch.
+	errUpdatePatchMalformed = errors.New("Malformed update patch")
+)
+
+// getUpdatePatchURL - returns the URL of the patch from releaseTag to
+// the release at downloadURL, published next to the release binary.
+// Only official releases can be patched.
+func getUpdatePatchURL(downloadURL, releaseTag string) string {
+	if !strings.HasPrefix(releaseTag, "RELEASE.") {
+		return ""
+	}
+	return downloadURL + "." + releaseTag + bsdiffSuffix
+}
+
+// bsdiffOfftin - decodes a sign-magnitude little endian integer of
+// a bsdiff patch.
+func bsdiffOfftin(buf []byte) int64 {
+	y := int64(binary.LittleEndian.Uint64(buf) &^ (1 << 63))
+	if buf[7]&0x80 != 0 {
+		y = -y
+	}
+	return y
+}
+
+// bspatch - rebuilds the new binary from old and a bsdiff patch.
+func bspatch(old, patch []byte) ([]byte, error) {
+	if len(patch) < bsdiffHeaderSize || string(patch[:len(bsdiffMagic)]) != bsdiffMagic {
+		return nil, errUpdatePatchMalformed
+	}
+	ctrlLen := bsdiffOfftin(patch[8:16])
+	diffLen := bsdiffOfftin(patch[16:24])
+	newSize := bsdiffOfftin(patch[24:32])
+	if ctrlLen < 0 || diffLen < 0 || newSize < 0 || newSize > maxUpdatePatchedSize ||
+		bsdiffHeaderSize+ctrlLen+diffLen > int64(len(patch)) {
+		return nil, errUpdatePatchMalformed
+	}
+	ctrlEnd := bsdiffHeaderSize + ctrlLen
+	diffEnd := ctrlEnd + diffLen
+	ctrlReader := bzip2.NewReader(bytes.NewReader(patch[bsdiffHeaderSize:ctrlEnd]))
+	diffReader := bzip2.NewReader(bytes.NewReader(patch[ctrlEnd:diffEnd]))
+	extraReader := bzip2.NewReader(bytes.NewReader(patch[diffEnd:]))
+
+	newData := make([]byte, newSize)
+	var ctrl [3]int64
+	var buf [8]byte
+	var oldPos, newPos int64
+	oldSize := int64(len(old))
+	for newPos < newSize {
+		// Every control triple adds diff bytes to old bytes, copies
+		// extra bytes and seeks in old.
+		for i := range ctrl {
+			if _, err := io.ReadFull(ctrlReader, buf[:]); err != nil {
+				return nil, errUpdatePatchMalformed
+			}
+			ctrl[i] = bsdiffOfftin(buf[:])
+		}
+		if ctrl[0] < 0 || ctrl[1] < 0 || newPos+ctrl[0] > newSize {
+			return nil, errUpdatePatchMalformed
+		}
+		if _, err := io.ReadFull(diffReader, newData[newPos:newPos+ctrl[0]]); err != nil {
+			return nil, errUpdatePatchMalformed
+		}
+		for i := int64(0); i < ctrl[0]; i++ {
+			if oldPos+i >= 0 && oldPos+i < oldSize {
+				newData[newPos+i] += old[oldPos+i]
+			}
+		}
+		newPos += ctrl[0]
+		oldPos += ctrl[0]
+
+		if newPos+ctrl[1] > newSize {
+			return nil, errUpdatePatchMalformed
+		}
+		if _, err := io.ReadFull(extraReader, newData[newPos:newPos+ctrl[1]]); err != nil {
+			return nil, errUpdatePatchMalformed
+		}
+		newPos += ctrl[1]
+		oldPos += ctrl[2]
+	}
+	return newData, nil
+}
+
+// patchUpdate fetches the patch from the running release at
+// updateMsg.patch and writes the binary rebuilt from exePath and
+// the patch to writer, errUpdatePatchUnavailable is returned if no
+// such patch is published.
+func patchUpdate(client *http.Client, updateMsg updateMessage, exePath string, writer io.Writer) error {
+	if updateMsg.patch == "" {
+		return errUpdatePatchUnavailable
+	}
+	req, err := http.NewRequest("GET", updateMsg.patch, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("User-Agent", userAgent)
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return errUpdatePatchUnavailable
+	}
+	if resp.StatusCode != http.StatusOK {
+		return errors.New("http status : " + resp.Status)
+	}
+	patch, err := ioutil.ReadAll(io.LimitReader(resp.Body, maxUpdatePatchSize+1))
+	if err != nil {
+		return err
+	}
+	if len(patch) > maxUpdatePatchSize {
+		return errUpdatePatchMalformed
+	}
+
+	old, err := ioutil.ReadFile(exePath)
+	if err != nil {
+		return err
+	}
+	newData, err := bspatch(old, patch)
+	if err != nil {
+		return err
+	}
+	_, err = writer.Write(newData)
+	return err
+}
+
+// resetUpdateFile truncates a partially written download, for the
+// full binary to be written after a patch failed.
+func resetUpdateFile(file *os.File) error {
+	if err := file.Truncate(0); err != nil {
+		return err
+	}
+	_, err := file.Seek(0, 0)
+	return err
+}
@@ -0,0 +1,172 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package cmd
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// bsdiff patch from testPatchOld to testPatchNew, exercising both
+// forward and backward seeks in the old binary.
+const testPatch = "QlNESUZGNDAyAAAAAAAAACcAAAAAAAAAJgAAAAAAAABCWmg5MUFZJlNZZDqA2AAAEcBAWwHAACAAMQwIEiDRcyOakFDQ13eLuSKcKEgyHUBsAEJaaDkxQVkmU1nRoyCCAAAAYABAACAAIAAhAIKDF3JFOFCQ0aMggkJaaDkxQVkmU1kzi35fAAAFUYAAEEAELkHEgCAAMQDQAUwAyQx8DmgZrIDXC7kinChIGcW/L4A="
+
+var (
+	testPatchOld = []byte("#!/bin/sh\necho old minio\n")
+	testPatchNew = []byte("#!/bin/sh\necho new minio, now patched\n")
+)
+
+// Validates rebuilding binaries from bsdiff patches.
+func TestBspatch(t *testing.T) {
+	patch, err := base64.StdEncoding.DecodeString(testPatch)
+	if err != nil {
+		t.Fatal(err)
+	}
+	newData, err := bspatch(testPatchOld, patch)
+	if err != nil {
+		t.Fatalf("Unable to apply patch, %s", err)
+	}
+	if string(newData) != string(testPatchNew) {
+		t.Errorf("Expected %q, got %q", testPatchNew, newData)
+	}
+
+	// Sizes past the end of the patch.
+	truncated := append([]byte{}, patch[:bsdiffHeaderSize+10]...)
+	// New size larger than allowed.
+	huge := append([]byte{}, patch...)
+	huge[31] = 0x7f
+	// Control asking for more bytes than the new size.
+	short := append([]byte{}, patch...)
+	short[24] = 4
+	testCases := [][]byte{
+		[]byte("BSDIFF"),
+		append([]byte("BSDIFF41"), patch[8:]...),
+		truncated,
+		huge,
+		short,
+	}
+	for i, testCase := range testCases {
+		if _, err = bspatch(testPatchOld, testCase); err != errUpdatePatchMalformed {
+			t.Errorf("Test %d: Expected %v, got %v", i+1, errUpdatePatchMalformed, err)
+		}
+	}
+}
+
+// Validates the patch URL is only set for official releases.
+func TestGetUpdatePatchURL(t *testing.T) {
+	downloadURL := "https://dl.minio.io/server/minio/release/linux-amd64/minio"
+	if patchURL := getUpdatePatchURL(downloadURL, "DEVELOPMENT.GOGET"); patchURL != "" {
+		t.Errorf("Expected no patch for development builds, got %s", patchURL)
+	}
+	expected := downloadURL + ".RELEASE.2016-10-07T01-16-39Z.bsdiff"
+	if patchURL := getUpdatePatchURL(downloadURL, "RELEASE.2016-10-07T01-16-39Z"); patchURL != expected {
+		t.Errorf("Expected %s, got %s", expected, patchURL)
+	}
+}
+
+// Validates updates are rebuilt from patches, falling back to the
+// whole binary.
+func TestApplyUpdatePatch(t *testing.T) {
+	sha256Sum := sha256.Sum256(testPatchNew)
+	patch, err := base64.StdEncoding.DecodeString(testPatch)
+	if err != nil {
+		t.Fatal(err)
+	}
+	priv, pubKey := newTestMinisignKey(t, "12345678")
+	key, err := parseMinisignPublicKey(pubKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var fullDownloads int
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/minio":
+			fullDownloads++
+			w.Write(testPatchNew)
+		case "/minio.minisig":
+			w.Write(signTestMinisign(priv, "12345678", testReleaseName, testPatchNew))
+		case "/minio.RELEASE.2016-10-07T01-16-39Z.bsdiff":
+			w.Write(patch)
+		case "/minio.RELEASE.2016-09-11T17-42-18Z.bsdiff":
+			w.Write([]byte("not a patch"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer ts.Close()
+
+	testCases := []struct {
+		old        []byte
+		releaseTag string
+		patched    bool
+	}{
+		// Test case 1: patch rebuilds the release.
+		{testPatchOld, "RELEASE.2016-10-07T01-16-39Z", true},
+		// Test case 2: running binary is not the release the patch is from.
+		{[]byte("#!/bin/bash\necho old minio\n"), "RELEASE.2016-10-07T01-16-39Z", false},
+		// Test case 3: malformed patch.
+		{testPatchOld, "RELEASE.2016-09-11T17-42-18Z", false},
+		// Test case 4: no patch published.
+		{testPatchOld, "RELEASE.2016-08-21T02-44-47Z", false},
+		// Test case 5: development builds are never patched.
+		{testPatchOld, "DEVELOPMENT.GOGET", false},
+	}
+	for i, testCase := range testCases {
+		dir, err := ioutil.TempDir("", "minio-update-")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer removeAll(dir)
+		exePath := filepath.Join(dir, "minio")
+		if err = ioutil.WriteFile(exePath, testCase.old, 0755); err != nil {
+			t.Fatal(err)
+		}
+
+		fullDownloads = 0
+		updateMsg := updateMessage{
+			Download:        ts.URL + "/minio",
+			Update:          true,
+			ReleaseChecksum: hex.EncodeToString(sha256Sum[:]),
+			patch:           getUpdatePatchURL(ts.URL+"/minio", testCase.releaseTag),
+			release:         testReleaseName,
+		}
+		patched, err := applyUpdate(updateMsg, key, exePath, time.Second*5)
+		if err != nil {
+			t.Fatalf("Test %d: Unable to apply update, %s", i+1, err)
+		}
+		if patched != testCase.patched {
+			t.Errorf("Test %d: Expected patched %v, got %v", i+1, testCase.patched, patched)
+		}
+		if expected := map[bool]int{true: 0, false: 1}[testCase.patched]; fullDownloads != expected {
+			t.Errorf("Test %d: Expected %d full downloads, got %d", i+1, expected, fullDownloads)
+		}
+		data, err := ioutil.ReadFile(exePath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(data) != string(testPatchNew) {
+			t.Errorf("Test %d: Expected binary %q, got %q", i+1, testPatchNew, data)
+		}
+	}
+}
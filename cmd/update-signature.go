@@ -0,0 +1,205 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	"golang.org/x/crypto/ed25519"
+)
+
+// Release binaries are signed with minisign (https://jedisct1.github.io/minisign/),
+// the signature is published next to the binary as minio.minisig.
+// Only pure Ed25519 signatures are understood, releases are signed
+// with ‘minisign -S -l -t minio.<release tag>’. The trusted comment
+// binds the signature to the release named in minio.shasum, so that
+// older signed releases cannot be passed off as newer ones.
+const (
+	minisignSuffix  = ".minisig"
+	maxMinisignSize = 4 * 1024
+)
+
+// minisign algorithm identifiers.
+var (
+	minisignAlgPure      = []byte("Ed")
+	minisignAlgPrehashed = []byte("ED")
+)
+
+var (
+	errUpdatePublicKeyMissing    = errors.New("No public key available to verify the release signature")
+	errUpdatePublicKeyMalformed  = errors.New("Release public key malformed")
+	errUpdateSignatureMalformed  = errors.New("Release signature malformed")
+	errUpdateSignatureUnknownAlg = errors.New("Release signature algorithm not supported")
+	errUpdateSignatureKeyID      = errors.New("Release signature was made by a different key")
+	errUpdateSignatureMismatch   = errors.New("Release signature verification failed")
+	errUpdateSignatureRelease    = errors.New("Release signature is for another release")
+)
+
+// minisignPublicKey - minisign public key, identified by its key id.
+type minisignPublicKey struct {
+	keyID []byte
+	key   ed25519.PublicKey
+}
+
+// minisignSignature - decoded minisign signature file.
+type minisignSignature struct {
+	algorithm       []byte
+	keyID           []byte
+	signature       []byte
+	trustedComment  string
+	globalSignature []byte
+}
+
+// minisignLines returns the lines of a minisign file, skipping the
+// untrusted comment a public key may be prefixed with.
+func minisignLines(data string) []string {
+	var lines []string
+	for _, line := range strings.Split(strings.Replace(data, "\r\n", "\n", -1), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		lines = append(lines, line)
+	}
+	if len(lines) > 0 && strings.HasPrefix(lines[0], "untrusted comment:") {
+		lines = lines[1:]
+	}
+	return lines
+}
+
+// parseMinisignPublicKey parses a public key either as the base64
+// encoded key alone or as the contents of a minisign .pub file.
+func parseMinisignPublicKey(data string) (minisignPublicKey, error) {
+	lines := minisignLines(data)
+	if len(lines) != 1 {
+		return minisignPublicKey{}, errUpdatePublicKeyMalformed
+	}
+	raw, err := base64.StdEncoding.DecodeString(lines[0])
+	if err != nil || len(raw) != 2+8+ed25519.PublicKeySize {
+		return minisignPublicKey{}, errUpdatePublicKeyMalformed
+	}
+	if !bytes.Equal(raw[:2], minisignAlgPure) {
+		return minisignPublicKey{}, errUpdatePublicKeyMalformed
+	}
+	return minisignPublicKey{
+		keyID: raw[2:10],
+		key:   ed25519.PublicKey(raw[10:]),
+	}, nil
+}
+
+// parseMinisignSignature parses the contents of a .minisig file.
+func parseMinisignSignature(data []byte) (minisignSignature, error) {
+	lines := minisignLines(string(data))
+	if len(lines) != 3 || !strings.HasPrefix(lines[1], "trusted comment: ") {
+		return minisignSignature{}, errUpdateSignatureMalformed
+	}
+	raw, err := base64.StdEncoding.DecodeString(lines[0])
+	if err != nil || len(raw) != 2+8+ed25519.SignatureSize {
+		return minisignSignature{}, errUpdateSignatureMalformed
+	}
+	globalSignature, err := base64.StdEncoding.DecodeString(lines[2])
+	if err != nil || len(globalSignature) != ed25519.SignatureSize {
+		return minisignSignature{}, errUpdateSignatureMalformed
+	}
+	return minisignSignature{
+		algorithm:       raw[:2],
+		keyID:           raw[2:10],
+		signature:       raw[10:],
+		trustedComment:  strings.TrimPrefix(lines[1], "trusted comment: "),
+		globalSignature: globalSignature,
+	}, nil
+}
+
+// verify checks that sig is a signature of message by this key,
+// including the global signature covering the trusted comment.
+func (k minisignPublicKey) verify(message []byte, sig minisignSignature) error {
+	if bytes.Equal(sig.algorithm, minisignAlgPrehashed) {
+		return errUpdateSignatureUnknownAlg
+	}
+	if !bytes.Equal(sig.algorithm, minisignAlgPure) {
+		return errUpdateSignatureMalformed
+	}
+	if !bytes.Equal(sig.keyID, k.keyID) {
+		return errUpdateSignatureKeyID
+	}
+	if !ed25519.Verify(k.key, message, sig.signature) {
+		return errUpdateSignatureMismatch
+	}
+	global := append(append([]byte{}, sig.signature...), sig.trustedComment...)
+	if !ed25519.Verify(k.key, global, sig.globalSignature) {
+		return errUpdateSignatureMismatch
+	}
+	return nil
+}
+
+// getReleasePublicKey returns the key release signatures are verified
+// with, override takes precedence over the key embedded at build time
+// so that air-gapped mirrors can re-sign the releases they serve.
+func getReleasePublicKey(override string) (minisignPublicKey, error) {
+	pubKey := ReleasePublicKey
+	if override != "" {
+		pubKey = override
+	}
+	if pubKey == "" {
+		return minisignPublicKey{}, errUpdatePublicKeyMissing
+	}
+	return parseMinisignPublicKey(pubKey)
+}
+
+// verifyReleaseSignature fetches the signature published for
+// downloadURL and verifies the downloaded binary at binPath with it,
+// the signature must be of the release named release.
+func verifyReleaseSignature(client *http.Client, downloadURL, binPath, release string, pubKey minisignPublicKey) error {
+	req, err := http.NewRequest("GET", downloadURL+minisignSuffix, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("User-Agent", userAgent)
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return errors.New("http status : " + resp.Status)
+	}
+	sigData, err := ioutil.ReadAll(io.LimitReader(resp.Body, maxMinisignSize))
+	if err != nil {
+		return err
+	}
+	sig, err := parseMinisignSignature(sigData)
+	if err != nil {
+		return err
+	}
+	message, err := ioutil.ReadFile(binPath)
+	if err != nil {
+		return err
+	}
+	if err = pubKey.verify(message, sig); err != nil {
+		return err
+	}
+	if sig.trustedComment != release {
+		return errUpdateSignatureRelease
+	}
+	return nil
+}
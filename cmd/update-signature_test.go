@@ -0,0 +1,166 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"encoding/base64"
+	"fmt"
+	"testing"
+
+	"golang.org/x/crypto/ed25519"
+)
+
+// newTestMinisignKey generates a minisign key pair, returning the
+// private key and the encoded public key as found in a .pub file.
+func newTestMinisignKey(t *testing.T, keyID string) (ed25519.PrivateKey, string) {
+	pub, priv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	raw := append(append([]byte("Ed"), keyID...), pub...)
+	return priv, "untrusted comment: minisign public key\n" + base64.StdEncoding.EncodeToString(raw) + "\n"
+}
+
+// Release the binaries served in tests are signed for.
+const testReleaseName = "minio.RELEASE.2016-10-07T01-16-39Z"
+
+// signTestMinisign returns the contents of a .minisig file for message,
+// with trustedComment naming the release.
+func signTestMinisign(priv ed25519.PrivateKey, keyID, trustedComment string, message []byte) []byte {
+	sig := ed25519.Sign(priv, message)
+	global := ed25519.Sign(priv, append(append([]byte{}, sig...), trustedComment...))
+	raw := append(append([]byte("Ed"), keyID...), sig...)
+	return []byte(fmt.Sprintf("untrusted comment: signature from minisign secret key\n%s\ntrusted comment: %s\n%s\n",
+		base64.StdEncoding.EncodeToString(raw), trustedComment, base64.StdEncoding.EncodeToString(global)))
+}
+
+// Validates parsing of minisign public keys.
+func TestParseMinisignPublicKey(t *testing.T) {
+	_, pubKey := newTestMinisignKey(t, "12345678")
+	parsed, err := parseMinisignPublicKey(pubKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(parsed.keyID) != "12345678" {
+		t.Errorf("Expected key id 12345678, got %q", parsed.keyID)
+	}
+	// The base64 line alone is accepted as well.
+	if _, err = parseMinisignPublicKey(minisignLines(pubKey)[0]); err != nil {
+		t.Error(err)
+	}
+
+	testCases := []string{
+		// Test case 1: empty key.
+		"",
+		// Test case 2: not base64.
+		"not a key",
+		// Test case 3: short key.
+		base64.StdEncoding.EncodeToString([]byte("Ed12345678")),
+		// Test case 4: unknown algorithm.
+		base64.StdEncoding.EncodeToString(append([]byte("XX12345678"), make([]byte, ed25519.PublicKeySize)...)),
+	}
+	for i, testCase := range testCases {
+		if _, err = parseMinisignPublicKey(testCase); err != errUpdatePublicKeyMalformed {
+			t.Errorf("Test %d: Expected %v, got %v", i+1, errUpdatePublicKeyMalformed, err)
+		}
+	}
+}
+
+// Validates verification of minisign signatures.
+func TestMinisignVerify(t *testing.T) {
+	message := []byte("minio release binary")
+	priv, pubKey := newTestMinisignKey(t, "12345678")
+	otherPriv, _ := newTestMinisignKey(t, "87654321")
+	key, err := parseMinisignPublicKey(pubKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	valid := signTestMinisign(priv, "12345678", testReleaseName, message)
+	sig, err := parseMinisignSignature(valid)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tamperedComment := sig
+	tamperedComment.trustedComment = "minio.RELEASE.2016-09-11T17-42-18Z"
+	prehashed := sig
+	prehashed.algorithm = minisignAlgPrehashed
+	otherKey, err := parseMinisignSignature(signTestMinisign(otherPriv, "87654321", testReleaseName, message))
+	if err != nil {
+		t.Fatal(err)
+	}
+	forged, err := parseMinisignSignature(signTestMinisign(otherPriv, "12345678", testReleaseName, message))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		message []byte
+		sig     minisignSignature
+		err     error
+	}{
+		// Test case 1: valid signature.
+		{message, sig, nil},
+		// Test case 2: tampered message.
+		{[]byte("minio tampered binary"), sig, errUpdateSignatureMismatch},
+		// Test case 3: tampered trusted comment.
+		{message, tamperedComment, errUpdateSignatureMismatch},
+		// Test case 4: prehashed signatures are not supported.
+		{message, prehashed, errUpdateSignatureUnknownAlg},
+		// Test case 5: signed by another key.
+		{message, otherKey, errUpdateSignatureKeyID},
+		// Test case 6: signed by another key claiming our key id.
+		{message, forged, errUpdateSignatureMismatch},
+	}
+	for i, testCase := range testCases {
+		if err = key.verify(testCase.message, testCase.sig); err != testCase.err {
+			t.Errorf("Test %d: Expected %v, got %v", i+1, testCase.err, err)
+		}
+	}
+
+	if _, err = parseMinisignSignature([]byte("untrusted comment: x\nbroken\n")); err != errUpdateSignatureMalformed {
+		t.Errorf("Expected %v, got %v", errUpdateSignatureMalformed, err)
+	}
+}
+
+// Validates selection of the release public key.
+func TestGetReleasePublicKey(t *testing.T) {
+	defer func(pubKey string) { ReleasePublicKey = pubKey }(ReleasePublicKey)
+
+	ReleasePublicKey = ""
+	if _, err := getReleasePublicKey(""); err != errUpdatePublicKeyMissing {
+		t.Errorf("Expected %v, got %v", errUpdatePublicKeyMissing, err)
+	}
+
+	_, embedded := newTestMinisignKey(t, "embedded")
+	_, override := newTestMinisignKey(t, "override")
+	ReleasePublicKey = minisignLines(embedded)[0]
+	key, err := getReleasePublicKey("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(key.keyID) != "embedded" {
+		t.Errorf("Expected embedded key, got %q", key.keyID)
+	}
+	key, err = getReleasePublicKey(override)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(key.keyID) != "override" {
+		t.Errorf("Expected override key, got %q", key.keyID)
+	}
+}
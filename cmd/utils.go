@@ -202,14 +202,14 @@ var globalProfiler interface {
 
 // dump the request into a string in JSON format.
 func dumpRequest(r *http.Request) string {
-	header := cloneHeader(r.Header)
+	header := redactHeader(r.Header)
 	header.Set("Host", r.Host)
 	req := struct {
 		Method string      `json:"method"`
 		Path   string      `json:"path"`
 		Query  string      `json:"query"`
 		Header http.Header `json:"header"`
-	}{r.Method, r.URL.Path, r.URL.RawQuery, header}
+	}{r.Method, r.URL.Path, redactSecrets(r.URL.RawQuery), header}
 	jsonBytes, err := json.Marshal(req)
 	if err != nil {
 		return "<error dumping request>"
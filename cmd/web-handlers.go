@@ -21,8 +21,10 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"net/http"
+	"net/url"
 	"os"
 	"path"
 	"runtime"
@@ -61,7 +63,61 @@ func isJWTReqAuthenticated(req *http.Request) bool {
 		errorIf(err, "token parsing failed")
 		return false
 	}
-	return token.Valid
+	// Scoped tokens are validated against their scope by the handlers
+	// accepting them.
+	return token.Valid && hasJWTAudience(token, jwtAudienceWeb) && getJWTScope(token) == nil
+}
+
+// isJWTTokenValid validates a JWT token passed as query parameter,
+// used by handlers which are not called through XHR.
+func isJWTTokenValid(tokenStr string) bool {
+	jwt, err := newJWT(defaultJWTExpiry)
+	if err != nil {
+		errorIf(err, "unable to initialize a new JWT")
+		return false
+	}
+
+	token, err := jwtgo.Parse(tokenStr, func(token *jwtgo.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwtgo.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("Unexpected signing method: %v", token.Header["alg"])
+		}
+		return []byte(jwt.SecretAccessKey), nil
+	})
+	if err != nil {
+		return false
+	}
+	return token.Valid && hasJWTAudience(token, jwtAudienceWeb) && getJWTScope(token) == nil
+}
+
+// getJWTTokenScope returns the scope of a valid scoped JWT token, nil
+// if the token is invalid or not scoped.
+func getJWTTokenScope(tokenStr string) *jwtScope {
+	jwt, err := newJWT(defaultScopedJWTExpiry)
+	if err != nil {
+		errorIf(err, "unable to initialize a new JWT")
+		return nil
+	}
+
+	token, err := jwtgo.Parse(tokenStr, func(token *jwtgo.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwtgo.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("Unexpected signing method: %v", token.Header["alg"])
+		}
+		return []byte(jwt.SecretAccessKey), nil
+	})
+	if err != nil || !token.Valid || !hasJWTAudience(token, jwtAudienceWeb) {
+		return nil
+	}
+	return getJWTScope(token)
+}
+
+// getJWTReqScope returns the scope of the JWT token in the
+// Authorization header of the request, see getJWTTokenScope.
+func getJWTReqScope(req *http.Request) *jwtScope {
+	tokenStr, err := jwtreq.AuthorizationHeaderExtractor.ExtractToken(req)
+	if err != nil {
+		return nil
+	}
+	return getJWTTokenScope(tokenStr)
 }
 
 // WebGenericArgs - empty struct for calls that don't accept arguments
@@ -76,12 +132,45 @@ type WebGenericRep struct {
 
 // ServerInfoRep - server info reply.
 type ServerInfoRep struct {
-	MinioVersion  string
-	MinioMemory   string
-	MinioPlatform string
-	MinioRuntime  string
-	MinioEnvVars  []string
-	UIVersion     string `json:"uiVersion"`
+	MinioVersion    string
+	MinioMemory     string
+	MinioPlatform   string
+	MinioContainer  string
+	MinioRuntime    string
+	MinioTmp        string
+	MinioAuth       string
+	MinioCrypto     string
+	MinioHashing    string
+	MinioTLS        string
+	MinioQuorum     string
+	MinioDurability string
+	MinioNotify     string
+	// Connectivity of the notification targets keyed by ARN.
+	MinioNotifyTargets map[string]eventTargetHealth `json:"notifyTargets"`
+	// Bucket configs held differently by peers, as found by the last
+	// check of the buckets owned by this server.
+	MinioConfigSync       string
+	MinioConfigDivergence []configDivergence `json:"configDivergence"`
+	MinioEnvVars          []string
+	// Download URL of a newer release found by the background update
+	// checks, empty if none was found.
+	MinioUpdate string `json:"update"`
+	UIVersion   string `json:"uiVersion"`
+}
+
+// getQuorumInfo - describes the effective read and write quorum for ServerInfo.
+func getQuorumInfo(objAPI ObjectLayer) string {
+	if objAPI == nil {
+		return ""
+	}
+	storageInfo := objAPI.StorageInfo()
+	if storageInfo.Backend.Type != XL {
+		return "Backend: FS"
+	}
+	return fmt.Sprintf("Backend: XL | Disks: %d | Read: %d | Write: %d",
+		storageInfo.Backend.OnlineDisks+storageInfo.Backend.OfflineDisks,
+		storageInfo.Backend.ReadQuorum,
+		storageInfo.Backend.WriteQuorum)
 }
 
 // ServerInfo - get server info.
@@ -105,12 +194,36 @@ func (web *webAPIHandlers) ServerInfo(r *http.Request, args *WebGenericArgs, rep
 		runtime.GOOS,
 		runtime.GOARCH)
 	goruntime := fmt.Sprintf("Version: %s | CPUs: %s", runtime.Version(), strconv.Itoa(runtime.NumCPU()))
+	tmpEntries, tmpBytes, tmpUploads, tmpLastRun := globalTmpCleanupStats.get()
+	tmp := fmt.Sprintf("Reclaimed: %s | Entries: %d | Aborted-Uploads: %d | Last-Cleanup: %s",
+		humanize.Bytes(uint64(tmpBytes)),
+		tmpEntries,
+		tmpUploads,
+		tmpLastRun.Format(time.RFC3339))
+	authFailures, authLockouts, authRejected := globalAuthLockout.getStats()
+	auth := fmt.Sprintf("Failures: %d | Lockouts: %d | Rejected: %d",
+		authFailures,
+		authLockouts,
+		authRejected)
 
 	reply.MinioEnvVars = os.Environ()
 	reply.MinioVersion = Version
 	reply.MinioMemory = mem
 	reply.MinioPlatform = platform
+	reply.MinioContainer = getContainerRuntime()
 	reply.MinioRuntime = goruntime
+	reply.MinioTmp = tmp
+	reply.MinioAuth = auth
+	reply.MinioCrypto = getCryptoPosture()
+	reply.MinioHashing = getHashingInfo()
+	reply.MinioTLS = globalTLSStats.String()
+	reply.MinioQuorum = getQuorumInfo(newObjectLayerFn())
+	reply.MinioDurability = globalDurabilityStats.String()
+	reply.MinioNotifyTargets = getEventTargetsHealth()
+	reply.MinioNotify = formatEventTargetsHealth(reply.MinioNotifyTargets)
+	reply.MinioConfigSync = globalConfigConvergence.String()
+	reply.MinioConfigDivergence = globalConfigConvergence.Divergences()
+	reply.MinioUpdate = globalUpdateNotifier.Available()
 	reply.UIVersion = miniobrowser.UIVersion
 	return nil
 }
@@ -150,7 +263,14 @@ func (web *webAPIHandlers) MakeBucket(r *http.Request, args *MakeBucketArgs, rep
 	if objectAPI == nil {
 		return &json2.Error{Message: "Server not initialized"}
 	}
-	if err := objectAPI.MakeBucket(args.BucketName); err != nil {
+	claimed, err := claimFederatedBucket(args.BucketName)
+	if err != nil {
+		return &json2.Error{Message: err.Error()}
+	}
+	if err = objectAPI.MakeBucket(args.BucketName); err != nil {
+		if claimed {
+			releaseFederatedBucket(args.BucketName)
+		}
 		return &json2.Error{Message: err.Error()}
 	}
 	return nil
@@ -223,7 +343,8 @@ type WebObjectInfo struct {
 // ListObjects - list objects api.
 func (web *webAPIHandlers) ListObjects(r *http.Request, args *ListObjectsArgs, reply *ListObjectsRep) error {
 	marker := ""
-	if !isJWTReqAuthenticated(r) {
+	// Scoped tokens may list the objects within their scope.
+	if !isJWTReqAuthenticated(r) && !getJWTReqScope(r).allows(args.BucketName, args.Prefix) {
 		return &json2.Error{Message: "Unauthorized request"}
 	}
 	for {
@@ -279,6 +400,132 @@ func (web *webAPIHandlers) RemoveObject(r *http.Request, args *RemoveObjectArgs,
 	return nil
 }
 
+// StatObjectArgs - stat object args.
+type StatObjectArgs struct {
+	BucketName string `json:"bucketName"`
+	ObjectName string `json:"objectName"`
+}
+
+// StatObjectRep - stat object reply.
+type StatObjectRep struct {
+	UIVersion       string            `json:"uiVersion"`
+	Key             string            `json:"name"`
+	LastModified    time.Time         `json:"lastModified"`
+	Size            int64             `json:"size"`
+	ETag            string            `json:"etag"`
+	ContentType     string            `json:"contentType"`
+	ContentEncoding string            `json:"contentEncoding"`
+	Metadata        map[string]string `json:"metadata"`
+}
+
+// Prefix of user metadata keys in object metadata.
+const userMetadataPrefix = "X-Amz-Meta-"
+
+// StatObject - returns object head info along with user metadata,
+// user metadata keys are returned without their prefix.
+func (web *webAPIHandlers) StatObject(r *http.Request, args *StatObjectArgs, reply *StatObjectRep) error {
+	if !isJWTReqAuthenticated(r) {
+		return &json2.Error{Message: "Unauthorized request"}
+	}
+	objectAPI := web.ObjectAPI()
+	if objectAPI == nil {
+		return &json2.Error{Message: "Server not initialized"}
+	}
+	objInfo, err := objectAPI.GetObjectInfo(args.BucketName, args.ObjectName)
+	if err != nil {
+		return &json2.Error{Message: err.Error()}
+	}
+
+	reply.UIVersion = miniobrowser.UIVersion
+	reply.Key = objInfo.Name
+	reply.LastModified = objInfo.ModTime
+	reply.Size = objInfo.Size
+	reply.ETag = objInfo.MD5Sum
+	reply.ContentType = objInfo.ContentType
+	reply.ContentEncoding = objInfo.ContentEncoding
+	reply.Metadata = make(map[string]string)
+	for key, value := range objInfo.UserDefined {
+		if strings.HasPrefix(key, userMetadataPrefix) {
+			reply.Metadata[strings.TrimPrefix(key, userMetadataPrefix)] = value
+		}
+	}
+	return nil
+}
+
+// SetObjectMetadataArgs - set object metadata args.
+type SetObjectMetadataArgs struct {
+	BucketName  string            `json:"bucketName"`
+	ObjectName  string            `json:"objectName"`
+	ContentType string            `json:"contentType"`
+	Metadata    map[string]string `json:"metadata"`
+}
+
+// SetObjectMetadata - replaces user metadata and optionally the
+// content type of an object by copying the object onto itself.
+func (web *webAPIHandlers) SetObjectMetadata(r *http.Request, args *SetObjectMetadataArgs, reply *WebGenericRep) error {
+	if !isJWTReqAuthenticated(r) {
+		return &json2.Error{Message: "Unauthorized request"}
+	}
+	objectAPI := web.ObjectAPI()
+	if objectAPI == nil {
+		return &json2.Error{Message: "Server not initialized"}
+	}
+	objInfo, err := objectAPI.GetObjectInfo(args.BucketName, args.ObjectName)
+	if err != nil {
+		return &json2.Error{Message: err.Error()}
+	}
+	if isMaxObjectSize(objInfo.Size) {
+		return &json2.Error{Message: getAPIError(ErrEntityTooLarge).Description}
+	}
+
+	// Keep all metadata other than user metadata, which is replaced.
+	metadata := make(map[string]string)
+	for key, value := range objInfo.UserDefined {
+		if !strings.HasPrefix(key, userMetadataPrefix) {
+			metadata[key] = value
+		}
+	}
+	for key, value := range args.Metadata {
+		metadata[http.CanonicalHeaderKey(userMetadataPrefix+key)] = value
+	}
+	if args.ContentType != "" {
+		metadata["content-type"] = args.ContentType
+	}
+	// Remove the etag as for a multipart object it is not the md5sum.
+	delete(metadata, "md5Sum")
+
+	pipeReader, pipeWriter := io.Pipe()
+	go func() {
+		gErr := objectAPI.GetObject(args.BucketName, args.ObjectName, 0, objInfo.Size, pipeWriter)
+		if gErr != nil {
+			errorIf(gErr, "Unable to read an object.")
+			pipeWriter.CloseWithError(gErr)
+			return
+		}
+		pipeWriter.Close()
+	}()
+
+	objInfo, err = objectAPI.PutObject(args.BucketName, args.ObjectName, objInfo.Size, pipeReader, metadata, "")
+	if err != nil {
+		pipeReader.CloseWithError(err)
+		return &json2.Error{Message: err.Error()}
+	}
+	pipeReader.Close()
+
+	// Notify object created event.
+	eventNotify(eventData{
+		Type:    ObjectCreatedCopy,
+		Bucket:  args.BucketName,
+		ObjInfo: objInfo,
+		ReqParams: map[string]string{
+			"sourceIPAddress": r.RemoteAddr,
+		},
+	})
+
+	reply.UIVersion = miniobrowser.UIVersion
+	return nil
+}
+
 // LoginArgs - login arguments.
 type LoginArgs struct {
 	Username string `json:"username" form:"username"`
@@ -293,16 +540,24 @@ type LoginRep struct {
 
 // Login - user login handler.
 func (web *webAPIHandlers) Login(r *http.Request, args *LoginArgs, reply *LoginRep) error {
+	sourceIP := getSourceIP(r)
+	if globalAuthLockout.isLocked(sourceIP, args.Username) {
+		return &json2.Error{Message: "Too many failed login attempts, please try again later."}
+	}
+
 	jwt, err := newJWT(defaultJWTExpiry)
 	if err != nil {
 		return &json2.Error{Message: err.Error()}
 	}
 
 	if err = jwt.Authenticate(args.Username, args.Password); err != nil {
+		globalRequestMetrics.AuthFailed()
+		globalAuthLockout.failed(sourceIP, args.Username)
 		return &json2.Error{Message: err.Error()}
 	}
+	globalAuthLockout.succeeded(sourceIP, args.Username)
 
-	token, err := jwt.GenerateToken(args.Username)
+	token, err := jwt.GenerateToken(args.Username, jwtAudienceWeb)
 	if err != nil {
 		return &json2.Error{Message: err.Error()}
 	}
@@ -350,8 +605,8 @@ func (web *webAPIHandlers) SetAuth(r *http.Request, args *SetAuthArgs, reply *Se
 	if !isValidAccessKey.MatchString(args.AccessKey) {
 		return &json2.Error{Message: "Invalid Access Key"}
 	}
-	if !isValidSecretKey.MatchString(args.SecretKey) {
-		return &json2.Error{Message: "Invalid Secret Key"}
+	if err := globalSecretKeyPolicy.validate(args.SecretKey); err != nil {
+		return &json2.Error{Message: err.Error()}
 	}
 
 	cred := credential{args.AccessKey, args.SecretKey}
@@ -410,7 +665,7 @@ func (web *webAPIHandlers) SetAuth(r *http.Request, args *SetAuthArgs, reply *Se
 	if err = jwt.Authenticate(args.AccessKey, args.SecretKey); err != nil {
 		return gaveUpMsg(err, moreErrors)
 	}
-	token, err := jwt.GenerateToken(args.AccessKey)
+	token, err := jwt.GenerateToken(args.AccessKey, jwtAudienceWeb)
 	if err != nil {
 		return gaveUpMsg(err, moreErrors)
 	}
@@ -451,14 +706,30 @@ func (web *webAPIHandlers) Upload(w http.ResponseWriter, r *http.Request) {
 
 	// Extract incoming metadata if any.
 	metadata := extractMetadataFromHeader(r.Header)
+	// Apply bucket defaults for any metadata not provided.
+	applyBucketDefaults(bucket, object, metadata)
 
 	objectAPI := web.ObjectAPI()
 	if objectAPI == nil {
 		writeWebErrorResponse(w, errors.New("Server not initialized"))
 		return
 	}
+
+	// Reject early if the upload would fill up the disks.
+	if err := globalDiskUsage.admit(r.ContentLength); err != nil {
+		writeWebErrorResponse(w, toObjectErr(err))
+		return
+	}
+
+	// Detect the content-type if the bucket asks for it.
+	reader, err := sniffContentType(bucket, object, metadata, r.Body)
+	if err != nil {
+		writeWebErrorResponse(w, err)
+		return
+	}
+
 	sha256sum := ""
-	if _, err := objectAPI.PutObject(bucket, object, -1, r.Body, metadata, sha256sum); err != nil {
+	if _, err = objectAPI.PutObject(bucket, object, -1, reader, metadata, sha256sum); err != nil {
 		writeWebErrorResponse(w, err)
 		return
 	}
@@ -481,182 +752,605 @@ func (web *webAPIHandlers) Upload(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
-// Download - file download handler.
-func (web *webAPIHandlers) Download(w http.ResponseWriter, r *http.Request) {
-	vars := mux.Vars(r)
-	bucket := vars["bucket"]
-	object := vars["object"]
-	tokenStr := r.URL.Query().Get("token")
-
-	jwt, err := newJWT(defaultJWTExpiry) // Expiry set to 24Hrs.
-	if err != nil {
-		errorIf(err, "error in getting new JWT")
-		return
-	}
-
-	token, e := jwtgo.Parse(tokenStr, func(token *jwtgo.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwtgo.SigningMethodHMAC); !ok {
-			return nil, fmt.Errorf("Unexpected signing method: %v", token.Header["alg"])
-		}
-		return []byte(jwt.SecretAccessKey), nil
-	})
-	if e != nil || !token.Valid {
+// UploadPart - uploads a part of a multipart upload started with
+// NewMultipartUpload, browsers upload large files in chunks so that
+// they can resume after a failure or a page refresh.
+func (web *webAPIHandlers) UploadPart(w http.ResponseWriter, r *http.Request) {
+	if !isJWTReqAuthenticated(r) {
 		writeWebErrorResponse(w, errInvalidToken)
 		return
 	}
-	// Add content disposition.
-	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s\"", path.Base(object)))
+	vars := mux.Vars(r)
+	bucket := vars["bucket"]
+	object := vars["object"]
+	uploadID := vars["uploadId"]
 
 	objectAPI := web.ObjectAPI()
 	if objectAPI == nil {
 		writeWebErrorResponse(w, errors.New("Server not initialized"))
 		return
 	}
-	objInfo, err := objectAPI.GetObjectInfo(bucket, object)
-	if err != nil {
-		writeWebErrorResponse(w, err)
+
+	partID, err := strconv.Atoi(vars["partNumber"])
+	if err != nil || isMaxPartID(partID) || partID < 1 {
+		writeWebErrorResponse(w, InvalidPart{})
 		return
 	}
-	offset := int64(0)
-	err = objectAPI.GetObject(bucket, object, offset, objInfo.Size, w)
-	if err != nil {
-		/// No need to print error, response writer already written to.
+	if r.ContentLength == -1 {
+		writeWebErrorResponse(w, errors.New(getAPIError(ErrMissingContentLength).Description))
 		return
 	}
-}
 
-// writeWebErrorResponse - set HTTP status code and write error description to the body.
-func writeWebErrorResponse(w http.ResponseWriter, err error) {
-	// Handle invalid token as a special case.
-	if err == errInvalidToken {
-		w.WriteHeader(http.StatusForbidden)
-		w.Write([]byte(err.Error()))
+	// Reject early if the part would fill up the disks.
+	if err = globalDiskUsage.admit(r.ContentLength); err != nil {
+		writeWebErrorResponse(w, toObjectErr(err))
 		return
 	}
-	// Convert error type to api error code.
-	var apiErrCode APIErrorCode
-	switch err.(type) {
-	case StorageFull:
-		apiErrCode = ErrStorageFull
-	case BucketNotFound:
-		apiErrCode = ErrNoSuchBucket
-	case BucketNameInvalid:
-		apiErrCode = ErrInvalidBucketName
-	case BadDigest:
-		apiErrCode = ErrBadDigest
-	case IncompleteBody:
-		apiErrCode = ErrIncompleteBody
-	case ObjectExistsAsDirectory:
-		apiErrCode = ErrObjectExistsAsDirectory
-	case ObjectNotFound:
-		apiErrCode = ErrNoSuchKey
-	case ObjectNameInvalid:
-		apiErrCode = ErrNoSuchKey
-	case InsufficientWriteQuorum:
-		apiErrCode = ErrWriteQuorum
-	case InsufficientReadQuorum:
-		apiErrCode = ErrReadQuorum
-	default:
-		apiErrCode = ErrInternalError
-	}
-	apiErr := getAPIError(apiErrCode)
-	w.WriteHeader(apiErr.HTTPStatusCode)
-	w.Write([]byte(apiErr.Description))
-}
-
-// GetBucketPolicyArgs - get bucket policy args.
-type GetBucketPolicyArgs struct {
-	BucketName string `json:"bucketName"`
-	Prefix     string `json:"prefix"`
-}
-
-// GetBucketPolicyRep - get bucket policy reply.
-type GetBucketPolicyRep struct {
-	UIVersion string              `json:"uiVersion"`
-	Policy    policy.BucketPolicy `json:"policy"`
-}
-
-func readBucketAccessPolicy(objAPI ObjectLayer, bucketName string) (policy.BucketAccessPolicy, error) {
-	bucketPolicyReader, err := readBucketPolicyJSON(bucketName, objAPI)
-	if err != nil {
-		if _, ok := err.(BucketPolicyNotFound); ok {
-			return policy.BucketAccessPolicy{Version: "2012-10-17"}, nil
-		}
-		return policy.BucketAccessPolicy{}, err
-	}
-
-	bucketPolicyBuf, err := ioutil.ReadAll(bucketPolicyReader)
-	if err != nil {
-		return policy.BucketAccessPolicy{}, err
-	}
 
-	policyInfo := policy.BucketAccessPolicy{}
-	err = json.Unmarshal(bucketPolicyBuf, &policyInfo)
+	partMD5, err := objectAPI.PutObjectPart(bucket, object, uploadID, partID, r.ContentLength, r.Body, "", "")
 	if err != nil {
-		return policy.BucketAccessPolicy{}, err
+		writeWebErrorResponse(w, err)
+		return
 	}
+	// Keep the upload from being purged as abandoned.
+	errorIf(saveBrowserUpload(objectAPI, bucket, object, uploadID), "Unable to record browser upload %s.", uploadID)
+	w.Header().Set("ETag", "\""+partMD5+"\"")
+}
 
-	return policyInfo, nil
+// NewMultipartUploadArgs - new multipart upload args.
+type NewMultipartUploadArgs struct {
+	BucketName  string            `json:"bucketName"`
+	ObjectName  string            `json:"objectName"`
+	ContentType string            `json:"contentType"`
+	Metadata    map[string]string `json:"metadata"`
+}
 
+// NewMultipartUploadRep - new multipart upload reply.
+type NewMultipartUploadRep struct {
+	UIVersion string `json:"uiVersion"`
+	UploadID  string `json:"uploadId"`
 }
 
-// GetBucketPolicy - get bucket policy.
-func (web *webAPIHandlers) GetBucketPolicy(r *http.Request, args *GetBucketPolicyArgs, reply *GetBucketPolicyRep) error {
+// NewMultipartUpload - starts a multipart upload for the browser to
+// upload a file in parts, abandoned uploads are aborted after
+// browserUploadExpiry.
+func (web *webAPIHandlers) NewMultipartUpload(r *http.Request, args *NewMultipartUploadArgs, reply *NewMultipartUploadRep) error {
 	if !isJWTReqAuthenticated(r) {
 		return &json2.Error{Message: "Unauthorized request"}
 	}
-
 	objectAPI := web.ObjectAPI()
 	if objectAPI == nil {
 		return &json2.Error{Message: "Server not initialized"}
 	}
-	policyInfo, err := readBucketAccessPolicy(objectAPI, args.BucketName)
+
+	metadata := make(map[string]string)
+	for key, value := range args.Metadata {
+		metadata[http.CanonicalHeaderKey(userMetadataPrefix+key)] = value
+	}
+	if args.ContentType != "" {
+		metadata["content-type"] = args.ContentType
+	}
+	// Apply bucket defaults for any metadata not provided.
+	applyBucketDefaults(args.BucketName, args.ObjectName, metadata)
+
+	uploadID, err := objectAPI.NewMultipartUpload(args.BucketName, args.ObjectName, metadata)
 	if err != nil {
 		return &json2.Error{Message: err.Error()}
 	}
-
+	if err = saveBrowserUpload(objectAPI, args.BucketName, args.ObjectName, uploadID); err != nil {
+		errorIf(objectAPI.AbortMultipartUpload(args.BucketName, args.ObjectName, uploadID), "Unable to abort multipart upload %s.", uploadID)
+		return &json2.Error{Message: err.Error()}
+	}
 	reply.UIVersion = miniobrowser.UIVersion
-	reply.Policy = policy.GetPolicy(policyInfo.Statements, args.BucketName, args.Prefix)
-
+	reply.UploadID = uploadID
 	return nil
 }
 
-// ListAllBucketPoliciesArgs - get all bucket policies.
-type ListAllBucketPoliciesArgs struct {
-	BucketName string `json:"bucketName"`
+// WebUploadPart - part of a multipart upload of the browser.
+type WebUploadPart struct {
+	PartNumber int    `json:"partNumber"`
+	ETag       string `json:"etag"`
+	Size       int64  `json:"size"`
 }
 
-// Collection of canned bucket policy at a given prefix.
-type bucketAccessPolicy struct {
-	Prefix string              `json:"prefix"`
-	Policy policy.BucketPolicy `json:"policy"`
+// ListUploadPartsArgs - list upload parts args.
+type ListUploadPartsArgs struct {
+	BucketName string `json:"bucketName"`
+	ObjectName string `json:"objectName"`
+	UploadID   string `json:"uploadId"`
 }
 
-// ListAllBucketPoliciesRep - get all bucket policy reply.
-type ListAllBucketPoliciesRep struct {
-	UIVersion string               `json:"uiVersion"`
-	Policies  []bucketAccessPolicy `json:"policies"`
+// ListUploadPartsRep - list upload parts reply.
+type ListUploadPartsRep struct {
+	UIVersion string          `json:"uiVersion"`
+	Parts     []WebUploadPart `json:"parts"`
 }
 
-// GetllBucketPolicy - get all bucket policy.
-func (web *webAPIHandlers) ListAllBucketPolicies(r *http.Request, args *ListAllBucketPoliciesArgs, reply *ListAllBucketPoliciesRep) error {
+// ListUploadParts - lists the parts uploaded so far, for the browser to
+// resume an upload whose id it kept across a page refresh. Uploads
+// which no longer exist fail with NoSuchUpload, the browser then starts
+// over.
+func (web *webAPIHandlers) ListUploadParts(r *http.Request, args *ListUploadPartsArgs, reply *ListUploadPartsRep) error {
 	if !isJWTReqAuthenticated(r) {
 		return &json2.Error{Message: "Unauthorized request"}
 	}
-
 	objectAPI := web.ObjectAPI()
 	if objectAPI == nil {
 		return &json2.Error{Message: "Server not initialized"}
 	}
-	policyInfo, err := readBucketAccessPolicy(objectAPI, args.BucketName)
-	if err != nil {
-		return &json2.Error{Message: err.Error()}
-	}
 
-	reply.UIVersion = miniobrowser.UIVersion
-	reply.Policies = []bucketAccessPolicy{}
-	for prefix, policy := range policy.GetPolicies(policyInfo.Statements, args.BucketName) {
-		reply.Policies = append(reply.Policies, bucketAccessPolicy{
+	reply.Parts = []WebUploadPart{}
+	partNumberMarker := 0
+	for {
+		result, err := objectAPI.ListObjectParts(args.BucketName, args.ObjectName, args.UploadID, partNumberMarker, maxPartsList)
+		if err != nil {
+			return &json2.Error{Message: err.Error()}
+		}
+		for _, part := range result.Parts {
+			reply.Parts = append(reply.Parts, WebUploadPart{
+				PartNumber: part.PartNumber,
+				ETag:       part.ETag,
+				Size:       part.Size,
+			})
+		}
+		if !result.IsTruncated {
+			break
+		}
+		partNumberMarker = result.NextPartNumberMarker
+	}
+	// Keep the upload from being purged as abandoned.
+	errorIf(saveBrowserUpload(objectAPI, args.BucketName, args.ObjectName, args.UploadID), "Unable to record browser upload %s.", args.UploadID)
+	reply.UIVersion = miniobrowser.UIVersion
+	return nil
+}
+
+// CompleteMultipartUploadArgs - complete multipart upload args.
+type CompleteMultipartUploadArgs struct {
+	BucketName string          `json:"bucketName"`
+	ObjectName string          `json:"objectName"`
+	UploadID   string          `json:"uploadId"`
+	Parts      []WebUploadPart `json:"parts"`
+}
+
+// CompleteMultipartUploadRep - complete multipart upload reply.
+type CompleteMultipartUploadRep struct {
+	UIVersion string `json:"uiVersion"`
+	ETag      string `json:"etag"`
+}
+
+// CompleteMultipartUpload - creates the object of a multipart upload of
+// the browser from its parts.
+func (web *webAPIHandlers) CompleteMultipartUpload(r *http.Request, args *CompleteMultipartUploadArgs, reply *CompleteMultipartUploadRep) error {
+	if !isJWTReqAuthenticated(r) {
+		return &json2.Error{Message: "Unauthorized request"}
+	}
+	objectAPI := web.ObjectAPI()
+	if objectAPI == nil {
+		return &json2.Error{Message: "Server not initialized"}
+	}
+
+	var parts []completePart
+	for _, part := range args.Parts {
+		parts = append(parts, completePart{
+			PartNumber: part.PartNumber,
+			ETag:       strings.Trim(part.ETag, "\""),
+		})
+	}
+	md5Sum, err := objectAPI.CompleteMultipartUpload(args.BucketName, args.ObjectName, args.UploadID, parts)
+	if err != nil {
+		return &json2.Error{Message: err.Error()}
+	}
+	errorIf(removeBrowserUpload(objectAPI, args.BucketName, args.UploadID), "Unable to remove record of browser upload %s.", args.UploadID)
+	reply.UIVersion = miniobrowser.UIVersion
+	reply.ETag = md5Sum
+
+	// Fetch object info for notifications.
+	objInfo, err := objectAPI.GetObjectInfo(args.BucketName, args.ObjectName)
+	if err != nil {
+		errorIf(err, "Unable to fetch object info for \"%s\"", path.Join(args.BucketName, args.ObjectName))
+		return nil
+	}
+
+	// Notify object created event.
+	eventNotify(eventData{
+		Type:    ObjectCreatedCompleteMultipartUpload,
+		Bucket:  args.BucketName,
+		ObjInfo: objInfo,
+		ReqParams: map[string]string{
+			"sourceIPAddress": r.RemoteAddr,
+		},
+	})
+	return nil
+}
+
+// AbortMultipartUploadArgs - abort multipart upload args.
+type AbortMultipartUploadArgs struct {
+	BucketName string `json:"bucketName"`
+	ObjectName string `json:"objectName"`
+	UploadID   string `json:"uploadId"`
+}
+
+// AbortMultipartUpload - aborts a multipart upload of the browser,
+// e.g. when the user cancels it.
+func (web *webAPIHandlers) AbortMultipartUpload(r *http.Request, args *AbortMultipartUploadArgs, reply *WebGenericRep) error {
+	if !isJWTReqAuthenticated(r) {
+		return &json2.Error{Message: "Unauthorized request"}
+	}
+	objectAPI := web.ObjectAPI()
+	if objectAPI == nil {
+		return &json2.Error{Message: "Server not initialized"}
+	}
+	if err := objectAPI.AbortMultipartUpload(args.BucketName, args.ObjectName, args.UploadID); err != nil {
+		return &json2.Error{Message: err.Error()}
+	}
+	errorIf(removeBrowserUpload(objectAPI, args.BucketName, args.UploadID), "Unable to remove record of browser upload %s.", args.UploadID)
+	reply.UIVersion = miniobrowser.UIVersion
+	return nil
+}
+
+// Download - file download handler.
+func (web *webAPIHandlers) Download(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	bucket := vars["bucket"]
+	object := vars["object"]
+	tokenStr := r.URL.Query().Get("token")
+
+	// Scoped tokens may read the objects within their scope.
+	if !isJWTTokenValid(tokenStr) && !getJWTTokenScope(tokenStr).allows(bucket, object) {
+		writeWebErrorResponse(w, errInvalidToken)
+		return
+	}
+	// Add content disposition.
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s\"", path.Base(object)))
+
+	objectAPI := web.ObjectAPI()
+	if objectAPI == nil {
+		writeWebErrorResponse(w, errors.New("Server not initialized"))
+		return
+	}
+	objInfo, err := objectAPI.GetObjectInfo(bucket, object)
+	if err != nil {
+		writeWebErrorResponse(w, err)
+		return
+	}
+	offset := int64(0)
+	err = objectAPI.GetObject(bucket, object, offset, objInfo.Size, w)
+	if err != nil {
+		/// No need to print error, response writer already written to.
+		return
+	}
+}
+
+// DownloadArchive - downloads all the objects under a prefix as a tar
+// or zip archive, for downloading folders in the browser.
+func (web *webAPIHandlers) DownloadArchive(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	bucket := vars["bucket"]
+	prefix := vars["object"]
+	tokenStr := r.URL.Query().Get("token")
+
+	// Scoped tokens may read the objects within their scope.
+	if !isJWTTokenValid(tokenStr) && !getJWTTokenScope(tokenStr).allows(bucket, prefix) {
+		writeWebErrorResponse(w, errInvalidToken)
+		return
+	}
+	format := vars["archive"]
+	if !isValidArchiveFormat(format) {
+		writeWebErrorResponse(w, errInvalidArgument)
+		return
+	}
+
+	objectAPI := web.ObjectAPI()
+	if objectAPI == nil {
+		writeWebErrorResponse(w, errors.New("Server not initialized"))
+		return
+	}
+	if _, err := objectAPI.GetBucketInfo(bucket); err != nil {
+		writeWebErrorResponse(w, err)
+		return
+	}
+	setArchiveHeaders(w, bucket, prefix, format)
+	err := writeObjectsArchive(w, objectAPI, bucket, prefix, format, func(object string) bool {
+		// Archives would bypass the transformation webhook.
+		return getBucketTransform(bucket, object) == nil
+	})
+	/// No need to report the error, response writer already written to.
+	errorIf(err, "Unable to write archive of %s/%s.", bucket, prefix)
+}
+
+// Maximum size of an object which can be previewed in the browser.
+const maxPreviewSize = 5 * 1024 * 1024 // 5MiB.
+
+// Errors returned on object preview.
+var (
+	errPreviewTooLarge    = errors.New("Object is too large to be previewed")
+	errPreviewUnsupported = errors.New("Object type cannot be previewed")
+)
+
+// Returns the content type an object is previewed with. Only text and
+// images are previewed, text is always served as plain text and SVG
+// is left out as both can carry scripts.
+func getPreviewContentType(contentType string) (string, error) {
+	switch {
+	case strings.HasPrefix(contentType, "text/"):
+		return "text/plain; charset=utf-8", nil
+	case strings.HasPrefix(contentType, "image/") && !strings.HasPrefix(contentType, "image/svg"):
+		return contentType, nil
+	}
+	return "", errPreviewUnsupported
+}
+
+// Preview - streams small text and image objects inline for
+// in-browser preview.
+func (web *webAPIHandlers) Preview(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	bucket := vars["bucket"]
+	object := vars["object"]
+	tokenStr := r.URL.Query().Get("token")
+
+	// Scoped tokens may read the objects within their scope.
+	if !isJWTTokenValid(tokenStr) && !getJWTTokenScope(tokenStr).allows(bucket, object) {
+		writeWebErrorResponse(w, errInvalidToken)
+		return
+	}
+
+	objectAPI := web.ObjectAPI()
+	if objectAPI == nil {
+		writeWebErrorResponse(w, errors.New("Server not initialized"))
+		return
+	}
+	objInfo, err := objectAPI.GetObjectInfo(bucket, object)
+	if err != nil {
+		writeWebErrorResponse(w, err)
+		return
+	}
+	contentType, err := getPreviewContentType(objInfo.ContentType)
+	if err != nil {
+		w.WriteHeader(http.StatusUnsupportedMediaType)
+		w.Write([]byte(err.Error()))
+		return
+	}
+	if objInfo.Size > maxPreviewSize {
+		w.WriteHeader(http.StatusRequestEntityTooLarge)
+		w.Write([]byte(errPreviewTooLarge.Error()))
+		return
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Length", strconv.FormatInt(objInfo.Size, 10))
+	w.Header().Set("Content-Disposition", fmt.Sprintf("inline; filename=\"%s\"", path.Base(object)))
+	// Never let the browser interpret previewed content.
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.Header().Set("Content-Security-Policy", "sandbox")
+
+	offset := int64(0)
+	err = objectAPI.GetObject(bucket, object, offset, objInfo.Size, w)
+	if err != nil {
+		/// No need to print error, response writer already written to.
+		return
+	}
+}
+
+// Maximum number of recent events replayed to a listener.
+const maxListenReplay = recentEventsPerBucket
+
+// errInvalidListenReplay - number of events to replay is invalid.
+var errInvalidListenReplay = fmt.Errorf("Number of events to replay must be between 0 and %d", maxListenReplay)
+
+// Listen - streams events of a bucket to the browser event console,
+// first replaying the recent events on this server when requested.
+// Events are filtered as for ListenBucketNotification, all events
+// are sent if none are given.
+func (web *webAPIHandlers) Listen(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	bucket := vars["bucket"]
+	tokenStr := r.URL.Query().Get("token")
+
+	if !isJWTTokenValid(tokenStr) {
+		writeWebErrorResponse(w, errInvalidToken)
+		return
+	}
+
+	objectAPI := web.ObjectAPI()
+	if objectAPI == nil {
+		writeWebErrorResponse(w, errors.New("Server not initialized"))
+		return
+	}
+
+	prefixes, suffixes, events := getListenBucketNotificationResources(r.URL.Query())
+	if len(events) == 0 {
+		events = []string{"s3:ObjectCreated:*", "s3:ObjectRemoved:*"}
+	}
+	apiErrCode := validateFilterValues(prefixes)
+	if apiErrCode == ErrNone {
+		apiErrCode = validateFilterValues(suffixes)
+	}
+	for _, event := range events {
+		if apiErrCode == ErrNone {
+			apiErrCode = checkEvent(event)
+		}
+	}
+	if apiErrCode != ErrNone {
+		apiErr := getAPIError(apiErrCode)
+		w.WriteHeader(apiErr.HTTPStatusCode)
+		w.Write([]byte(apiErr.Description))
+		return
+	}
+	var replay int
+	if replayStr := r.URL.Query().Get("replay"); replayStr != "" {
+		var err error
+		if replay, err = strconv.Atoi(replayStr); err != nil || replay < 0 || replay > maxListenReplay {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(errInvalidListenReplay.Error()))
+			return
+		}
+	}
+
+	if _, err := objectAPI.GetBucketInfo(bucket); err != nil {
+		writeWebErrorResponse(w, errorCause(err))
+		return
+	}
+
+	lc := newListenerConfig(prefixes, suffixes, events)
+	nEventCh := make(chan []NotificationEvent)
+	defer close(nEventCh)
+	if err := globalEventNotifier.AddListenerChan(lc.TopicConfig.TopicARN, nEventCh); err != nil {
+		writeWebErrorResponse(w, err)
+		return
+	}
+	defer globalEventNotifier.RemoveListenerChan(lc.TopicConfig.TopicARN)
+	if err := AddBucketListenerConfig(bucket, &lc, objectAPI); err != nil {
+		writeWebErrorResponse(w, errorCause(err))
+		return
+	}
+	defer RemoveBucketListenerConfig(bucket, &lc, objectAPI)
+
+	w.Header().Set("Content-Type", "application/json")
+	if replay > 0 {
+		recent := globalRecentEvents.Get(bucket, lc, replay)
+		if err := writeNotification(w, map[string][]NotificationEvent{"Records": recent}); err != nil {
+			return
+		}
+	}
+	sendBucketNotification(w, nEventCh)
+}
+
+// writeWebErrorResponse - set HTTP status code and write error description to the body.
+func writeWebErrorResponse(w http.ResponseWriter, err error) {
+	// Handle invalid token as a special case.
+	if err == errInvalidToken {
+		w.WriteHeader(http.StatusForbidden)
+		w.Write([]byte(err.Error()))
+		return
+	}
+	// Convert error type to api error code.
+	var apiErrCode APIErrorCode
+	switch errorCause(err).(type) {
+	case StorageFull:
+		apiErrCode = ErrStorageFull
+	case BucketNotFound:
+		apiErrCode = ErrNoSuchBucket
+	case BucketNameInvalid:
+		apiErrCode = ErrInvalidBucketName
+	case BadDigest:
+		apiErrCode = ErrBadDigest
+	case IncompleteBody:
+		apiErrCode = ErrIncompleteBody
+	case ObjectExistsAsDirectory:
+		apiErrCode = ErrObjectExistsAsDirectory
+	case ObjectNotFound:
+		apiErrCode = ErrNoSuchKey
+	case ObjectNameInvalid:
+		apiErrCode = ErrNoSuchKey
+	case InsufficientWriteQuorum:
+		apiErrCode = ErrWriteQuorum
+	case InsufficientReadQuorum:
+		apiErrCode = ErrReadQuorum
+	case InvalidUploadID:
+		apiErrCode = ErrNoSuchUpload
+	case InvalidPart:
+		apiErrCode = ErrInvalidPart
+	default:
+		apiErrCode = ErrInternalError
+	}
+	apiErr := getAPIError(apiErrCode)
+	w.WriteHeader(apiErr.HTTPStatusCode)
+	w.Write([]byte(apiErr.Description))
+}
+
+// GetBucketPolicyArgs - get bucket policy args.
+type GetBucketPolicyArgs struct {
+	BucketName string `json:"bucketName"`
+	Prefix     string `json:"prefix"`
+}
+
+// GetBucketPolicyRep - get bucket policy reply.
+type GetBucketPolicyRep struct {
+	UIVersion string              `json:"uiVersion"`
+	Policy    policy.BucketPolicy `json:"policy"`
+}
+
+func readBucketAccessPolicy(objAPI ObjectLayer, bucketName string) (policy.BucketAccessPolicy, error) {
+	bucketPolicyReader, err := readBucketPolicyJSON(bucketName, objAPI)
+	if err != nil {
+		if _, ok := err.(BucketPolicyNotFound); ok {
+			return policy.BucketAccessPolicy{Version: "2012-10-17"}, nil
+		}
+		return policy.BucketAccessPolicy{}, err
+	}
+
+	bucketPolicyBuf, err := ioutil.ReadAll(bucketPolicyReader)
+	if err != nil {
+		return policy.BucketAccessPolicy{}, err
+	}
+
+	policyInfo := policy.BucketAccessPolicy{}
+	err = json.Unmarshal(bucketPolicyBuf, &policyInfo)
+	if err != nil {
+		return policy.BucketAccessPolicy{}, err
+	}
+
+	return policyInfo, nil
+
+}
+
+// GetBucketPolicy - get bucket policy.
+func (web *webAPIHandlers) GetBucketPolicy(r *http.Request, args *GetBucketPolicyArgs, reply *GetBucketPolicyRep) error {
+	if !isJWTReqAuthenticated(r) {
+		return &json2.Error{Message: "Unauthorized request"}
+	}
+
+	objectAPI := web.ObjectAPI()
+	if objectAPI == nil {
+		return &json2.Error{Message: "Server not initialized"}
+	}
+	policyInfo, err := readBucketAccessPolicy(objectAPI, args.BucketName)
+	if err != nil {
+		return &json2.Error{Message: err.Error()}
+	}
+
+	reply.UIVersion = miniobrowser.UIVersion
+	reply.Policy = policy.GetPolicy(policyInfo.Statements, args.BucketName, args.Prefix)
+
+	return nil
+}
+
+// ListAllBucketPoliciesArgs - get all bucket policies.
+type ListAllBucketPoliciesArgs struct {
+	BucketName string `json:"bucketName"`
+}
+
+// Collection of canned bucket policy at a given prefix.
+type bucketAccessPolicy struct {
+	Prefix string              `json:"prefix"`
+	Policy policy.BucketPolicy `json:"policy"`
+}
+
+// ListAllBucketPoliciesRep - get all bucket policy reply.
+type ListAllBucketPoliciesRep struct {
+	UIVersion string               `json:"uiVersion"`
+	Policies  []bucketAccessPolicy `json:"policies"`
+}
+
+// GetllBucketPolicy - get all bucket policy.
+func (web *webAPIHandlers) ListAllBucketPolicies(r *http.Request, args *ListAllBucketPoliciesArgs, reply *ListAllBucketPoliciesRep) error {
+	if !isJWTReqAuthenticated(r) {
+		return &json2.Error{Message: "Unauthorized request"}
+	}
+
+	objectAPI := web.ObjectAPI()
+	if objectAPI == nil {
+		return &json2.Error{Message: "Server not initialized"}
+	}
+	policyInfo, err := readBucketAccessPolicy(objectAPI, args.BucketName)
+	if err != nil {
+		return &json2.Error{Message: err.Error()}
+	}
+
+	reply.UIVersion = miniobrowser.UIVersion
+	reply.Policies = []bucketAccessPolicy{}
+	for prefix, policy := range policy.GetPolicies(policyInfo.Statements, args.BucketName) {
+		reply.Policies = append(reply.Policies, bucketAccessPolicy{
 			Prefix: prefix,
 			Policy: policy,
 		})
@@ -686,45 +1380,187 @@ func (web *webAPIHandlers) SetBucketPolicy(r *http.Request, args *SetBucketPolic
 		return &json2.Error{Message: "Invalid policy " + args.Policy}
 	}
 
-	policyInfo, err := readBucketAccessPolicy(objectAPI, args.BucketName)
+	// The policy is read and merged with the change under the policy
+	// lock, concurrent changes of other statements are kept.
+	err := updateAndNotifyBucketPolicy(args.BucketName, objectAPI, func() (policyChange, error) {
+		policyInfo, err := readBucketAccessPolicy(objectAPI, args.BucketName)
+		if err != nil {
+			return policyChange{}, err
+		}
+		policyInfo.Statements = policy.SetPolicy(policyInfo.Statements, bucketP, args.BucketName, args.Prefix)
+		if len(policyInfo.Statements) == 0 {
+			return policyChange{IsRemove: true}, nil
+		}
+		data, err := json.Marshal(policyInfo)
+		if err != nil {
+			return policyChange{}, err
+		}
+
+		// Parse bucket policy.
+		var policy = &bucketPolicy{}
+		err = parseBucketPolicy(bytes.NewReader(data), policy)
+		if err != nil {
+			errorIf(err, "Unable to parse bucket policy.")
+			return policyChange{}, err
+		}
+
+		// Parse check bucket policy.
+		if s3Error := checkBucketPolicyResources(args.BucketName, policy); s3Error != ErrNone {
+			return policyChange{}, errors.New(getAPIError(s3Error).Description)
+		}
+		return policyChange{BktPolicy: policy}, nil
+	})
 	if err != nil {
 		return &json2.Error{Message: err.Error()}
 	}
-	policyInfo.Statements = policy.SetPolicy(policyInfo.Statements, bucketP, args.BucketName, args.Prefix)
-	if len(policyInfo.Statements) == 0 {
-		if err = persistAndNotifyBucketPolicyChange(args.BucketName, policyChange{true, nil}, objectAPI); err != nil {
-			return &json2.Error{Message: err.Error()}
-		}
-		return nil
+
+	reply.UIVersion = miniobrowser.UIVersion
+	return nil
+}
+
+// GetBucketNotificationArgs - get bucket notification args.
+type GetBucketNotificationArgs struct {
+	BucketName string `json:"bucketName"`
+}
+
+// GetBucketNotificationRep - get bucket notification reply.
+type GetBucketNotificationRep struct {
+	UIVersion string                      `json:"uiVersion"`
+	ETag      string                      `json:"etag"`
+	Rules     []queueConfig               `json:"rules"`
+	Stats     map[string]eventTargetStats `json:"stats"`
+}
+
+// GetBucketNotification - get bucket notification rules along with
+// the config ETag to be passed back on rule updates, and the event
+// statistics of this server per target ARN.
+func (web *webAPIHandlers) GetBucketNotification(r *http.Request, args *GetBucketNotificationArgs, reply *GetBucketNotificationRep) error {
+	if !isJWTReqAuthenticated(r) {
+		return &json2.Error{Message: "Unauthorized request"}
 	}
-	data, err := json.Marshal(policyInfo)
-	if err != nil {
+	objectAPI := web.ObjectAPI()
+	if objectAPI == nil {
+		return &json2.Error{Message: "Server not initialized"}
+	}
+	if _, err := objectAPI.GetBucketInfo(args.BucketName); err != nil {
 		return &json2.Error{Message: err.Error()}
 	}
 
-	// Parse bucket policy.
-	var policy = &bucketPolicy{}
-	err = parseBucketPolicy(bytes.NewReader(data), policy)
+	ncfg, err := loadNotificationConfig(args.BucketName, objectAPI)
+	if err != nil && err != errNoSuchNotifications {
+		return &json2.Error{Message: err.Error()}
+	}
+	etag, err := getNotificationConfigETag(ncfg)
 	if err != nil {
-		errorIf(err, "Unable to parse bucket policy.")
 		return &json2.Error{Message: err.Error()}
 	}
 
-	// Parse check bucket policy.
-	if s3Error := checkBucketPolicyResources(args.BucketName, policy); s3Error != ErrNone {
-		return &json2.Error{Message: getAPIError(s3Error).Description}
+	reply.UIVersion = miniobrowser.UIVersion
+	reply.ETag = etag
+	reply.Rules = []queueConfig{}
+	if ncfg != nil {
+		reply.Rules = append(reply.Rules, ncfg.QueueConfigs...)
+	}
+	reply.Stats = globalEventStats.Get(args.BucketName)
+	return nil
+}
+
+// AddBucketNotificationRuleArgs - add bucket notification rule args.
+type AddBucketNotificationRuleArgs struct {
+	BucketName string      `json:"bucketName"`
+	ETag       string      `json:"etag"`
+	Rule       queueConfig `json:"rule"`
+}
+
+// RemoveBucketNotificationRuleArgs - remove bucket notification rule args.
+type RemoveBucketNotificationRuleArgs struct {
+	BucketName string `json:"bucketName"`
+	ETag       string `json:"etag"`
+	ID         string `json:"id"`
+}
+
+// BucketNotificationRuleRep - add/remove bucket notification rule reply.
+type BucketNotificationRuleRep struct {
+	UIVersion string `json:"uiVersion"`
+	ETag      string `json:"etag"`
+}
+
+// AddBucketNotificationRule - add a single rule to the bucket
+// notification config, fails if the config changed since etag.
+func (web *webAPIHandlers) AddBucketNotificationRule(r *http.Request, args *AddBucketNotificationRuleArgs, reply *BucketNotificationRuleRep) error {
+	return web.updateBucketNotification(r, args.BucketName, args.ETag, addNotificationRule(args.Rule), reply)
+}
+
+// RemoveBucketNotificationRule - remove a single rule by id from the
+// bucket notification config, fails if the config changed since etag.
+func (web *webAPIHandlers) RemoveBucketNotificationRule(r *http.Request, args *RemoveBucketNotificationRuleArgs, reply *BucketNotificationRuleRep) error {
+	return web.updateBucketNotification(r, args.BucketName, args.ETag, removeNotificationRule(args.ID), reply)
+}
+
+// Common code for partial bucket notification updates.
+func (web *webAPIHandlers) updateBucketNotification(r *http.Request, bucket, etag string, update func(*notificationConfig) error, reply *BucketNotificationRuleRep) error {
+	if !isJWTReqAuthenticated(r) {
+		return &json2.Error{Message: "Unauthorized request"}
+	}
+	objectAPI := web.ObjectAPI()
+	if objectAPI == nil {
+		return &json2.Error{Message: "Server not initialized"}
+	}
+	if _, err := objectAPI.GetBucketInfo(bucket); err != nil {
+		return &json2.Error{Message: err.Error()}
 	}
 
-	// TODO: update policy statements according to bucket name,
-	// prefix and policy arguments.
-	if err := persistAndNotifyBucketPolicyChange(args.BucketName, policyChange{false, policy}, objectAPI); err != nil {
+	newETag, err := updateBucketNotificationConfig(bucket, etag, update, objectAPI)
+	if err != nil {
 		return &json2.Error{Message: err.Error()}
 	}
 
 	reply.UIVersion = miniobrowser.UIVersion
+	reply.ETag = newETag
 	return nil
 }
 
+// Maximum expiry of presigned URLs, as allowed by S3.
+const maxPresignedExpiry = 7 * 24 * time.Hour
+
+// Variable holding the maximum expiry of presigned URLs handed out by
+// the browser, also their default expiry.
+var globalPresignedMaxExpiry = maxPresignedExpiry
+
+// parsePresignedMaxExpiry - parses the maximum expiry of presigned URLs.
+func parsePresignedMaxExpiry(expiryStr string) (time.Duration, error) {
+	expiry, err := time.ParseDuration(expiryStr)
+	if err != nil {
+		return 0, err
+	}
+	if expiry < time.Second || expiry > maxPresignedExpiry {
+		return 0, errInvalidArgument
+	}
+	return expiry, nil
+}
+
+// Returns the expiry of a presigned URL requested for expirySecs
+// seconds, zero for the maximum. URLs handed out with a scoped token
+// expire with the token at the latest.
+func getPresignedExpiry(expirySecs int64, scope *jwtScope) (time.Duration, error) {
+	expiry := time.Duration(expirySecs) * time.Second
+	if expiry == 0 {
+		expiry = globalPresignedMaxExpiry
+	}
+	if expiry < time.Second || expiry > globalPresignedMaxExpiry {
+		return 0, fmt.Errorf("Expiry should be between 1 second and %s", globalPresignedMaxExpiry)
+	}
+	if scope != nil && !scope.Expiry.IsZero() {
+		if remaining := scope.Expiry.Sub(time.Now().UTC()); remaining < expiry {
+			expiry = remaining
+		}
+		if expiry < time.Second {
+			return 0, errors.New("Token expired")
+		}
+	}
+	return expiry, nil
+}
+
 // PresignedGetArgs - presigned-get API args.
 type PresignedGetArgs struct {
 	// Host header required for signed headers.
@@ -735,6 +1571,9 @@ type PresignedGetArgs struct {
 
 	// Object name to be presigned.
 	ObjectName string `json:"object"`
+
+	// Expiry of the URL in seconds, defaults to the maximum expiry.
+	Expiry int64 `json:"expiry"`
 }
 
 // PresignedGetRep - presigned-get URL reply.
@@ -745,48 +1584,193 @@ type PresignedGetRep struct {
 
 // PresignedGET - returns presigned-Get url.
 func (web *webAPIHandlers) PresignedGet(r *http.Request, args *PresignedGetArgs, reply *PresignedGetRep) error {
+	// Scoped tokens may presign the objects within their scope.
+	var scope *jwtScope
+	if !isJWTReqAuthenticated(r) {
+		scope = getJWTReqScope(r)
+		if !scope.allows(args.BucketName, args.ObjectName) {
+			return &json2.Error{Message: "Unauthorized request"}
+		}
+	}
+	if args.BucketName == "" || args.ObjectName == "" {
+		return &json2.Error{Message: "Required arguments: Host, Bucket, Object"}
+	}
+	expiry, err := getPresignedExpiry(args.Expiry, scope)
+	if err != nil {
+		return &json2.Error{Message: err.Error()}
+	}
+	reply.URL = presignedGet(args.HostName, args.BucketName, args.ObjectName, expiry)
+	return nil
+}
+
+// PresignedPutArgs - presigned-put API args.
+type PresignedPutArgs struct {
+	// Host header required for signed headers.
+	HostName string `json:"host"`
+
+	// Bucket name of the object to be uploaded.
+	BucketName string `json:"bucket"`
+
+	// Object name to be uploaded.
+	ObjectName string `json:"object"`
+
+	// Expiry of the URL in seconds, defaults to the maximum expiry.
+	Expiry int64 `json:"expiry"`
+
+	// Content type uploads have to be sent with, any if empty.
+	ContentType string `json:"contentType"`
+}
+
+// PresignedPutRep - presigned-put URL reply.
+type PresignedPutRep struct {
+	// Presigned URL to upload the object to.
+	URL string `json:"url"`
+	// Headers uploads have to be sent with.
+	Headers map[string]string `json:"headers"`
+}
+
+// PresignedPut - returns presigned-Put url, scoped tokens are read-only
+// and may not hand out upload links.
+func (web *webAPIHandlers) PresignedPut(r *http.Request, args *PresignedPutArgs, reply *PresignedPutRep) error {
 	if !isJWTReqAuthenticated(r) {
 		return &json2.Error{Message: "Unauthorized request"}
 	}
 	if args.BucketName == "" || args.ObjectName == "" {
 		return &json2.Error{Message: "Required arguments: Host, Bucket, Object"}
 	}
-	reply.URL = presignedGet(args.HostName, args.BucketName, args.ObjectName)
+	if !IsValidObjectName(args.ObjectName) {
+		return &json2.Error{Message: ObjectNameInvalid{Bucket: args.BucketName, Object: args.ObjectName}.Error()}
+	}
+	expiry, err := getPresignedExpiry(args.Expiry, nil)
+	if err != nil {
+		return &json2.Error{Message: err.Error()}
+	}
+	objectAPI := web.ObjectAPI()
+	if objectAPI == nil {
+		return &json2.Error{Message: "Server not initialized"}
+	}
+	if _, err = objectAPI.GetBucketInfo(args.BucketName); err != nil {
+		return &json2.Error{Message: err.Error()}
+	}
+	headers := make(http.Header)
+	if args.ContentType != "" {
+		headers.Set("Content-Type", args.ContentType)
+	}
+	reply.URL = presignedPut(args.HostName, args.BucketName, args.ObjectName, expiry, headers)
+	reply.Headers = make(map[string]string)
+	for k := range headers {
+		reply.Headers[k] = headers.Get(k)
+	}
+	return nil
+}
+
+// ShareTokenArgs - share-token API args.
+type ShareTokenArgs struct {
+	// Bucket name to be shared.
+	BucketName string `json:"bucket"`
+
+	// Prefix of the objects to be shared, empty for the whole bucket.
+	Prefix string `json:"prefix"`
+
+	// Expiry of the token in seconds, defaults to an hour.
+	Expiry int64 `json:"expiry"`
+}
+
+// ShareTokenRep - share-token reply.
+type ShareTokenRep struct {
+	// Token granting read-only access to the shared objects.
+	Token     string `json:"token"`
+	UIVersion string `json:"uiVersion"`
+}
+
+// ShareToken - returns a short lived token which can only list and
+// download objects under a prefix of a bucket, used for sharing links.
+func (web *webAPIHandlers) ShareToken(r *http.Request, args *ShareTokenArgs, reply *ShareTokenRep) error {
+	if !isJWTReqAuthenticated(r) {
+		return &json2.Error{Message: "Unauthorized request"}
+	}
+	expiry := time.Duration(args.Expiry) * time.Second
+	if expiry == 0 {
+		expiry = defaultScopedJWTExpiry
+	}
+	if expiry < 0 || expiry > maxScopedJWTExpiry {
+		return &json2.Error{Message: fmt.Sprintf("Expiry should be between 1 second and %s", maxScopedJWTExpiry)}
+	}
+	objectAPI := web.ObjectAPI()
+	if objectAPI == nil {
+		return &json2.Error{Message: "Server not initialized"}
+	}
+	if _, err := objectAPI.GetBucketInfo(args.BucketName); err != nil {
+		return &json2.Error{Message: err.Error()}
+	}
+	jwt, err := newJWT(expiry)
+	if err != nil {
+		return &json2.Error{Message: err.Error()}
+	}
+	token, err := jwt.GenerateScopedToken(jwt.AccessKeyID, jwtScope{
+		Bucket: args.BucketName,
+		Prefix: args.Prefix,
+	})
+	if err != nil {
+		return &json2.Error{Message: err.Error()}
+	}
+	reply.Token = token
+	reply.UIVersion = miniobrowser.UIVersion
 	return nil
 }
 
 // Returns presigned url for GET method.
-func presignedGet(host, bucket, object string) string {
+func presignedGet(host, bucket, object string, expiry time.Duration) string {
+	query := presignedGetQuery(host, bucket, object, expiry, nil)
+
+	// Construct the final presigned URL.
+	return host + "/" + path.Join(bucket, object) + "?" + query
+}
+
+// Returns presigned url for PUT method, uploads have to be sent with
+// headers.
+func presignedPut(host, bucket, object string, expiry time.Duration, headers http.Header) string {
+	query := presignedQuery("PUT", host, bucket, object, expiry, nil, headers)
+
+	// Construct the final presigned URL.
+	return host + "/" + path.Join(bucket, object) + "?" + query
+}
+
+// presignedGetQuery - returns the signed query string of a presigned
+// GET of object, extraQuery parameters are signed along.
+func presignedGetQuery(host, bucket, object string, expiry time.Duration, extraQuery url.Values) string {
+	return presignedQuery("GET", host, bucket, object, expiry, extraQuery, nil)
+}
+
+// presignedQuery - returns the signed query string of a presigned
+// request of object, extraQuery parameters and headers, besides host,
+// are signed along.
+func presignedQuery(method, host, bucket, object string, expiry time.Duration, extraQuery url.Values, headers http.Header) string {
 	cred := serverConfig.GetCredential()
 	region := serverConfig.GetRegion()
 
-	accessKey := cred.AccessKeyID
-	secretKey := cred.SecretAccessKey
-
 	date := time.Now().UTC()
-	dateStr := date.Format("20060102T150405Z")
-	credential := fmt.Sprintf("%s/%s", accessKey, getScope(date, region))
 
-	query := strings.Join([]string{
-		"X-Amz-Algorithm=" + signV4Algorithm,
-		"X-Amz-Credential=" + strings.Replace(credential, "/", "%2F", -1),
-		"X-Amz-Date=" + dateStr,
-		"X-Amz-Expires=" + "604800", // Default set to be expire in 7days.
-		"X-Amz-SignedHeaders=host",
-	}, "&")
+	query := url.Values{}
+	for k, v := range extraQuery {
+		query[k] = v
+	}
+	query.Set("X-Amz-Algorithm", signV4Algorithm)
+	query.Set("X-Amz-Credential", cred.AccessKeyID+"/"+getScope(date, region))
+	query.Set("X-Amz-Date", date.Format(iso8601Format))
+	query.Set("X-Amz-Expires", strconv.FormatInt(int64(expiry/time.Second), 10))
+	query.Set("X-Amz-SignedHeaders", getSignedHeaders(headers))
+	// Encode sorts by key as required for the canonical request.
+	encodedQuery := query.Encode()
 
 	path := "/" + path.Join(bucket, object)
 
-	// Headers are empty, since "host" is the only header required to be signed for Presigned URLs.
-	var extractedSignedHeaders http.Header
-
-	canonicalRequest := getCanonicalRequest(extractedSignedHeaders, unsignedPayload, query, path, "GET", host)
+	canonicalRequest := getCanonicalRequest(headers, unsignedPayload, encodedQuery, path, method, host)
 	stringToSign := getStringToSign(canonicalRequest, date, region)
-	signingKey := getSigningKey(secretKey, date, region)
+	signingKey := getSigningKey(cred.SecretAccessKey, date, region)
 	signature := getSignature(signingKey, stringToSign)
 
-	// Construct the final presigned URL.
-	return host + path + "?" + query + "&" + "X-Amz-Signature=" + signature
+	return encodedQuery + "&" + "X-Amz-Signature=" + signature
 }
 
 func (web *webAPIHandlers) _defaultHandler(w http.ResponseWriter, r *http.Request) {
@@ -799,7 +1783,7 @@ func (web *webAPIHandlers) redirectMinioHandler(w http.ResponseWriter, r *http.R
 		fmt.Fprintf(w, "<h1>Failed to get minio token:%s</h1>\n", err)
 	}
 
-	token, err := jwt.GenerateToken(jwt.credential.AccessKeyID)
+	token, err := jwt.GenerateToken(jwt.credential.AccessKeyID, jwtAudienceWeb)
 	if err != nil {
 		fmt.Fprintf(w, "<h1>Failed to get minio token:%s</h1>\n", err)
 	}
@@ -18,14 +18,18 @@ package cmd
 
 import (
 	"bytes"
+	"encoding/json"
 	"errors"
+	"fmt"
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
 	"reflect"
 	"strconv"
 	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/minio/minio-go/pkg/policy"
 	"github.com/minio/minio-go/pkg/set"
@@ -192,6 +196,9 @@ func testServerInfoWebHandler(obj ObjectLayer, instanceType string, t TestErrHan
 	if serverInfoReply.MinioVersion != Version {
 		t.Fatalf("Cannot get minio version from server info handler")
 	}
+	if serverInfoReply.MinioNotify == "" {
+		t.Fatalf("Cannot get notification targets from server info handler")
+	}
 }
 
 // Wrapper for calling MakeBucket Web Handler
@@ -660,6 +667,123 @@ func testUploadWebHandler(obj ObjectLayer, instanceType string, t TestErrHandler
 	}
 }
 
+// Wrapper for calling multipart upload web handlers
+func TestWebHandlerMultipartUpload(t *testing.T) {
+	ExecObjectLayerTest(t, testMultipartUploadWebHandler)
+}
+
+// testMultipartUploadWebHandler - Test uploading files in parts with
+// the multipart upload web handlers, resuming after a refresh.
+func testMultipartUploadWebHandler(obj ObjectLayer, instanceType string, t TestErrHandler) {
+	// Register the API end points with XL/FS object layer.
+	apiRouter := initTestWebRPCEndPoint(obj)
+	// initialize the server and obtain the credentials and root.
+	// credentials are necessary to sign the HTTP request.
+	rootPath, err := newTestConfig("us-east-1")
+	if err != nil {
+		t.Fatalf("Init Test config failed")
+	}
+	// remove the root folder after the test ends.
+	defer removeAll(rootPath)
+
+	credentials := serverConfig.GetCredential()
+	authorization, err := getWebRPCToken(apiRouter, credentials.AccessKeyID, credentials.SecretAccessKey)
+	if err != nil {
+		t.Fatal("Cannot authenticate")
+	}
+
+	objectName := "test.file"
+	bucketName := getRandomBucketName()
+	if err = obj.MakeBucket(bucketName); err != nil {
+		t.Fatalf("%s : %s", instanceType, err)
+	}
+
+	callRPC := func(method string, args interface{}, reply interface{}) error {
+		rec := httptest.NewRecorder()
+		req, rErr := newTestWebRPCRequest(method, authorization, args)
+		if rErr != nil {
+			t.Fatalf("Failed to create HTTP request: <ERROR> %v", rErr)
+		}
+		apiRouter.ServeHTTP(rec, req)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("Expected the response status to be 200, but instead found `%d`", rec.Code)
+		}
+		return getTestWebRPCResponse(rec, reply)
+	}
+	uploadPart := func(uploadID string, partNumber int, data []byte) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		req, rErr := http.NewRequest("PUT", fmt.Sprintf("/minio/upload/%s/%s?uploadId=%s&partNumber=%d", bucketName, objectName, uploadID, partNumber), bytes.NewReader(data))
+		if rErr != nil {
+			t.Fatalf("Cannot create upload request, %v", rErr)
+		}
+		req.Header.Set("Authorization", "Bearer "+authorization)
+		apiRouter.ServeHTTP(rec, req)
+		return rec
+	}
+
+	newReply := &NewMultipartUploadRep{}
+	newArgs := NewMultipartUploadArgs{BucketName: bucketName, ObjectName: objectName, ContentType: "text/plain"}
+	if err = callRPC("Web.NewMultipartUpload", newArgs, newReply); err != nil {
+		t.Fatalf("Failed, %v", err)
+	}
+
+	content := append(bytes.Repeat([]byte("a"), minPartSize), []byte("temporary file's content")...)
+	if rec := uploadPart(newReply.UploadID, 1, content[:minPartSize]); rec.Code != http.StatusOK {
+		t.Fatalf("Expected the response status to be 200, but instead found `%d`", rec.Code)
+	}
+	if rec := uploadPart("invalid-upload-id", 2, content[minPartSize:]); rec.Code != http.StatusNotFound {
+		t.Errorf("Expected the response status to be 404, but instead found `%d`", rec.Code)
+	}
+
+	// After a refresh the browser finds the parts uploaded so far.
+	listArgs := ListUploadPartsArgs{BucketName: bucketName, ObjectName: objectName, UploadID: newReply.UploadID}
+	listReply := &ListUploadPartsRep{}
+	if err = callRPC("Web.ListUploadParts", listArgs, listReply); err != nil {
+		t.Fatalf("Failed, %v", err)
+	}
+	if len(listReply.Parts) != 1 || listReply.Parts[0].PartNumber != 1 || listReply.Parts[0].Size != minPartSize {
+		t.Fatalf("Unexpected parts %v", listReply.Parts)
+	}
+
+	rec := uploadPart(newReply.UploadID, 2, content[minPartSize:])
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected the response status to be 200, but instead found `%d`", rec.Code)
+	}
+	parts := append(listReply.Parts, WebUploadPart{PartNumber: 2, ETag: rec.Header().Get("ETag")})
+	completeArgs := CompleteMultipartUploadArgs{BucketName: bucketName, ObjectName: objectName, UploadID: newReply.UploadID, Parts: parts}
+	if err = callRPC("Web.CompleteMultipartUpload", completeArgs, &CompleteMultipartUploadRep{}); err != nil {
+		t.Fatalf("Failed, %v", err)
+	}
+
+	var byteBuffer bytes.Buffer
+	if err = obj.GetObject(bucketName, objectName, 0, int64(len(content)), &byteBuffer); err != nil {
+		t.Fatalf("Failed, %v", err)
+	}
+	if !bytes.Equal(byteBuffer.Bytes(), content) {
+		t.Fatalf("The upload file is different from the download file")
+	}
+
+	// Completed uploads can no longer be resumed.
+	if err = callRPC("Web.ListUploadParts", listArgs, &ListUploadPartsRep{}); err == nil {
+		t.Fatal("Expected completed upload to be gone")
+	}
+	if _, err = obj.GetObjectInfo(minioMetaBucket, browserUploadPath(bucketName, newReply.UploadID)); !isObjectNotFound(err) {
+		t.Errorf("Expected browser upload record to be removed, got %v", err)
+	}
+
+	// Aborting an upload.
+	if err = callRPC("Web.NewMultipartUpload", newArgs, newReply); err != nil {
+		t.Fatalf("Failed, %v", err)
+	}
+	abortArgs := AbortMultipartUploadArgs{BucketName: bucketName, ObjectName: objectName, UploadID: newReply.UploadID}
+	if err = callRPC("Web.AbortMultipartUpload", abortArgs, &WebGenericRep{}); err != nil {
+		t.Fatalf("Failed, %v", err)
+	}
+	if err = callRPC("Web.AbortMultipartUpload", abortArgs, &WebGenericRep{}); err == nil {
+		t.Fatal("Expected aborted upload to be gone")
+	}
+}
+
 // Wrapper for calling Upload Handler
 func TestWebHandlerDownload(t *testing.T) {
 	ExecObjectLayerTest(t, testDownloadWebHandler)
@@ -717,6 +841,179 @@ func testDownloadWebHandler(obj ObjectLayer, instanceType string, t TestErrHandl
 	}
 }
 
+// Wrapper for calling StatObject and SetObjectMetadata handlers.
+func TestWebHandlerObjectMetadata(t *testing.T) {
+	ExecObjectLayerTest(t, testWebObjectMetadataHandler)
+}
+
+// testWebObjectMetadataHandler - Test StatObject and SetObjectMetadata web handlers.
+func testWebObjectMetadataHandler(obj ObjectLayer, instanceType string, t TestErrHandler) {
+	// Register the API end points with XL/FS object layer.
+	apiRouter := initTestWebRPCEndPoint(obj)
+	// initialize the server and obtain the credentials and root.
+	// credentials are necessary to sign the HTTP request.
+	rootPath, err := newTestConfig("us-east-1")
+	if err != nil {
+		t.Fatalf("Init Test config failed")
+	}
+	// remove the root folder after the test ends.
+	defer removeAll(rootPath)
+
+	credentials := serverConfig.GetCredential()
+
+	authorization, err := getWebRPCToken(apiRouter, credentials.AccessKeyID, credentials.SecretAccessKey)
+	if err != nil {
+		t.Fatal("Cannot authenticate")
+	}
+
+	objectName := "test.file"
+	bucketName := getRandomBucketName()
+	if err = obj.MakeBucket(bucketName); err != nil {
+		t.Fatalf("%s : %s", instanceType, err)
+	}
+	content := []byte("temporary file's content")
+	metadata := map[string]string{"content-type": "text/plain", "X-Amz-Meta-Owner": "alice"}
+	if _, err = obj.PutObject(bucketName, objectName, int64(len(content)), bytes.NewReader(content), metadata, ""); err != nil {
+		t.Fatalf("Was not able to upload an object, %v", err)
+	}
+
+	statObject := func() *StatObjectRep {
+		rec := httptest.NewRecorder()
+		reply := &StatObjectRep{}
+		req, rerr := newTestWebRPCRequest("Web.StatObject", authorization, &StatObjectArgs{BucketName: bucketName, ObjectName: objectName})
+		if rerr != nil {
+			t.Fatalf("Failed to create HTTP request: <ERROR> %v", rerr)
+		}
+		apiRouter.ServeHTTP(rec, req)
+		if rerr = getTestWebRPCResponse(rec, &reply); rerr != nil {
+			t.Fatalf("Failed, %v", rerr)
+		}
+		return reply
+	}
+
+	reply := statObject()
+	if reply.Size != int64(len(content)) || reply.ContentType != "text/plain" {
+		t.Fatalf("Unexpected object info %v", reply)
+	}
+	if !reflect.DeepEqual(reply.Metadata, map[string]string{"Owner": "alice"}) {
+		t.Fatalf("Unexpected metadata %v", reply.Metadata)
+	}
+
+	// Replace user metadata and content type.
+	rec := httptest.NewRecorder()
+	args := &SetObjectMetadataArgs{
+		BucketName:  bucketName,
+		ObjectName:  objectName,
+		ContentType: "text/csv",
+		Metadata:    map[string]string{"reviewer": "bob"},
+	}
+	req, err := newTestWebRPCRequest("Web.SetObjectMetadata", authorization, args)
+	if err != nil {
+		t.Fatalf("Failed to create HTTP request: <ERROR> %v", err)
+	}
+	apiRouter.ServeHTTP(rec, req)
+	if err = getTestWebRPCResponse(rec, &WebGenericRep{}); err != nil {
+		t.Fatalf("Failed, %v", err)
+	}
+
+	reply = statObject()
+	if reply.ContentType != "text/csv" {
+		t.Fatalf("Expected content type to be updated, got %s", reply.ContentType)
+	}
+	if !reflect.DeepEqual(reply.Metadata, map[string]string{"Reviewer": "bob"}) {
+		t.Fatalf("Unexpected metadata %v", reply.Metadata)
+	}
+
+	// Object content is left untouched.
+	var buffer bytes.Buffer
+	if err = obj.GetObject(bucketName, objectName, 0, int64(len(content)), &buffer); err != nil {
+		t.Fatalf("Unable to read object, %v", err)
+	}
+	if !bytes.Equal(buffer.Bytes(), content) {
+		t.Fatalf("Object content changed")
+	}
+}
+
+// Wrapper for calling Preview handler.
+func TestWebHandlerPreview(t *testing.T) {
+	ExecObjectLayerTest(t, testPreviewWebHandler)
+}
+
+// testPreviewWebHandler - Test Preview web handler
+func testPreviewWebHandler(obj ObjectLayer, instanceType string, t TestErrHandler) {
+	// Register the API end points with XL/FS object layer.
+	apiRouter := initTestWebRPCEndPoint(obj)
+	// initialize the server and obtain the credentials and root.
+	// credentials are necessary to sign the HTTP request.
+	rootPath, err := newTestConfig("us-east-1")
+	if err != nil {
+		t.Fatalf("Init Test config failed")
+	}
+	// remove the root folder after the test ends.
+	defer removeAll(rootPath)
+
+	credentials := serverConfig.GetCredential()
+
+	authorization, err := getWebRPCToken(apiRouter, credentials.AccessKeyID, credentials.SecretAccessKey)
+	if err != nil {
+		t.Fatal("Cannot authenticate")
+	}
+
+	bucketName := getRandomBucketName()
+	if err = obj.MakeBucket(bucketName); err != nil {
+		t.Fatalf("%s : %s", instanceType, err)
+	}
+	content := []byte("<html><script></script></html>")
+	objects := map[string]string{
+		"page.html":  "text/html",
+		"image.png":  "image/png",
+		"image.svg":  "image/svg+xml",
+		"binary.bin": "application/octet-stream",
+	}
+	for objectName, contentType := range objects {
+		_, err = obj.PutObject(bucketName, objectName, int64(len(content)), bytes.NewReader(content), map[string]string{"content-type": contentType}, "")
+		if err != nil {
+			t.Fatalf("Was not able to upload an object, %v", err)
+		}
+	}
+
+	testCases := []struct {
+		objectName          string
+		token               string
+		expectedStatus      int
+		expectedContentType string
+	}{
+		// Text is always previewed as plain text.
+		{"page.html", authorization, http.StatusOK, "text/plain; charset=utf-8"},
+		{"image.png", authorization, http.StatusOK, "image/png"},
+		// SVG and binary objects cannot be previewed.
+		{"image.svg", authorization, http.StatusUnsupportedMediaType, ""},
+		{"binary.bin", authorization, http.StatusUnsupportedMediaType, ""},
+		// Invalid token.
+		{"image.png", "foo", http.StatusForbidden, ""},
+	}
+	for i, testCase := range testCases {
+		rec := httptest.NewRecorder()
+		req, rerr := http.NewRequest("GET", "/minio/preview/"+bucketName+"/"+testCase.objectName+"?token="+testCase.token, nil)
+		if rerr != nil {
+			t.Fatalf("Test %d: Cannot create preview request, %v", i+1, rerr)
+		}
+		apiRouter.ServeHTTP(rec, req)
+		if rec.Code != testCase.expectedStatus {
+			t.Fatalf("Test %d: Expected the response status to be %d, but instead found `%d`", i+1, testCase.expectedStatus, rec.Code)
+		}
+		if testCase.expectedStatus != http.StatusOK {
+			continue
+		}
+		if contentType := rec.Header().Get("Content-Type"); contentType != testCase.expectedContentType {
+			t.Fatalf("Test %d: Expected content type %s, found %s", i+1, testCase.expectedContentType, contentType)
+		}
+		if !bytes.Equal(rec.Body.Bytes(), content) {
+			t.Fatalf("Test %d: The previewed content is corrupted", i+1)
+		}
+	}
+}
+
 // Wrapper for calling PresignedGet handler
 func TestWebHandlerPresignedGetHandler(t *testing.T) {
 	ExecObjectLayerTest(t, testWebPresignedGetHandler)
@@ -803,6 +1100,297 @@ func testWebPresignedGetHandler(obj ObjectLayer, instanceType string, t TestErrH
 	}
 }
 
+// Wrapper for calling PresignedPut handler
+func TestWebHandlerPresignedPutHandler(t *testing.T) {
+	ExecObjectLayerTest(t, testWebPresignedPutHandler)
+}
+
+func testWebPresignedPutHandler(obj ObjectLayer, instanceType string, t TestErrHandler) {
+	// Register the API end points with XL/FS object layer.
+	apiRouter := initTestWebRPCEndPoint(obj)
+	// initialize the server and obtain the credentials and root.
+	// credentials are necessary to sign the HTTP request.
+	rootPath, err := newTestConfig("us-east-1")
+	if err != nil {
+		t.Fatalf("Init Test config failed")
+	}
+	// remove the root folder after the test ends.
+	defer removeAll(rootPath)
+
+	credentials := serverConfig.GetCredential()
+
+	authorization, err := getWebRPCToken(apiRouter, credentials.AccessKeyID, credentials.SecretAccessKey)
+	if err != nil {
+		t.Fatal("Cannot authenticate")
+	}
+
+	bucketName := getRandomBucketName()
+	if err = obj.MakeBucket(bucketName); err != nil {
+		t.Fatalf("%s : %s", instanceType, err)
+	}
+
+	// Invalid requests are rejected.
+	for i, args := range []PresignedPutArgs{
+		{BucketName: bucketName, ObjectName: "object", Expiry: -1},
+		{BucketName: bucketName, ObjectName: "object", Expiry: int64(globalPresignedMaxExpiry/time.Second) + 1},
+		{BucketName: "nonexistent-bucket", ObjectName: "object"},
+		{BucketName: bucketName},
+	} {
+		rec := httptest.NewRecorder()
+		req, rErr := newTestWebRPCRequest("Web.PresignedPut", authorization, args)
+		if rErr != nil {
+			t.Fatalf("Failed to create HTTP request: <ERROR> %v", rErr)
+		}
+		apiRouter.ServeHTTP(rec, req)
+		if err = getTestWebRPCResponse(rec, &PresignedPutRep{}); err == nil {
+			t.Errorf("Test %d: Expected presigned put request to fail", i+1)
+		}
+	}
+
+	rec := httptest.NewRecorder()
+	req, err := newTestWebRPCRequest("Web.PresignedPut", authorization, PresignedPutArgs{
+		BucketName:  bucketName,
+		ObjectName:  "object",
+		Expiry:      3600,
+		ContentType: "text/plain",
+	})
+	if err != nil {
+		t.Fatalf("Failed to create HTTP request: <ERROR> %v", err)
+	}
+	apiRouter.ServeHTTP(rec, req)
+	presignPutRep := &PresignedPutRep{}
+	if err = getTestWebRPCResponse(rec, &presignPutRep); err != nil {
+		t.Fatalf("Failed, %v", err)
+	}
+	if presignPutRep.Headers["Content-Type"] != "text/plain" {
+		t.Fatalf("Expected the Content-Type header to be required, got %v", presignPutRep.Headers)
+	}
+	if !strings.Contains(presignPutRep.URL, "X-Amz-Expires=3600") {
+		t.Fatalf("Expected the URL to expire in an hour, got %s", presignPutRep.URL)
+	}
+
+	// Register the API end points with XL/FS object layer.
+	apiRouter = initTestAPIEndPoints(obj, []string{"PutObject"})
+
+	// Uploads have to be sent with the signed content type.
+	data := []byte("presigned upload")
+	for i, testCase := range []struct {
+		contentType    string
+		expectedStatus int
+	}{
+		{"", http.StatusBadRequest},
+		{"image/png", http.StatusForbidden},
+		{"text/plain", http.StatusOK},
+	} {
+		arec := httptest.NewRecorder()
+		req, err = newTestRequest("PUT", presignPutRep.URL, int64(len(data)), bytes.NewReader(data))
+		if err != nil {
+			t.Fatal("Failed to initialized a new request", err)
+		}
+		// Only the signed headers may be sent.
+		req.Header.Del("x-amz-content-sha256")
+		req.Header.Del("Content-Md5")
+		if testCase.contentType != "" {
+			req.Header.Set("Content-Type", testCase.contentType)
+		}
+		apiRouter.ServeHTTP(arec, req)
+		if arec.Code != testCase.expectedStatus {
+			t.Errorf("Test %d: Expected the response status to be %d, but instead found `%d`", i+1, testCase.expectedStatus, arec.Code)
+		}
+	}
+	var buf bytes.Buffer
+	if err = obj.GetObject(bucketName, "object", 0, int64(len(data)), &buf); err != nil {
+		t.Fatalf("Unable to read the uploaded object, %v", err)
+	}
+	if !bytes.Equal(buf.Bytes(), data) {
+		t.Fatal("Read data is not equal was what was expected")
+	}
+}
+
+// Tests presigning with scoped tokens, which are limited to their
+// scope and may not hand out upload links.
+func TestWebHandlerPresignedScoped(t *testing.T) {
+	ExecObjectLayerTest(t, testWebPresignedScopedHandler)
+}
+
+func testWebPresignedScopedHandler(obj ObjectLayer, instanceType string, t TestErrHandler) {
+	apiRouter := initTestWebRPCEndPoint(obj)
+	rootPath, err := newTestConfig("us-east-1")
+	if err != nil {
+		t.Fatalf("Init Test config failed")
+	}
+	defer removeAll(rootPath)
+
+	bucketName := getRandomBucketName()
+	if err = obj.MakeBucket(bucketName); err != nil {
+		t.Fatalf("%s : %s", instanceType, err)
+	}
+	jwt, err := newJWT(time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	token, err := jwt.GenerateScopedToken(jwt.AccessKeyID, jwtScope{Bucket: bucketName, Prefix: "shared/"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		method    string
+		args      interface{}
+		shouldErr bool
+	}{
+		{"Web.PresignedGet", PresignedGetArgs{BucketName: bucketName, ObjectName: "shared/a.txt"}, false},
+		{"Web.PresignedGet", PresignedGetArgs{BucketName: bucketName, ObjectName: "private/b.txt"}, true},
+		{"Web.PresignedPut", PresignedPutArgs{BucketName: bucketName, ObjectName: "shared/a.txt"}, true},
+	}
+	for i, testCase := range testCases {
+		rec := httptest.NewRecorder()
+		req, rErr := newTestWebRPCRequest(testCase.method, token, testCase.args)
+		if rErr != nil {
+			t.Fatalf("Failed to create HTTP request: <ERROR> %v", rErr)
+		}
+		apiRouter.ServeHTTP(rec, req)
+		rep := &PresignedGetRep{}
+		err = getTestWebRPCResponse(rec, &rep)
+		if testCase.shouldErr != (err != nil) {
+			t.Errorf("Test %d: Expected error %v, got %v", i+1, testCase.shouldErr, err)
+		}
+		// URLs expire with the token at the latest.
+		if err == nil && !strings.Contains(rep.URL, "X-Amz-Expires=3600") && !strings.Contains(rep.URL, "X-Amz-Expires=3599") {
+			t.Errorf("Test %d: Expected the URL to expire with the token, got %s", i+1, rep.URL)
+		}
+	}
+}
+
+// Wrapper for calling ShareToken handler
+func TestWebHandlerShareToken(t *testing.T) {
+	ExecObjectLayerTest(t, testWebShareTokenHandler)
+}
+
+// testWebShareTokenHandler - Test ShareToken web handler and the access
+// granted by scoped tokens.
+func testWebShareTokenHandler(obj ObjectLayer, instanceType string, t TestErrHandler) {
+	// Register the API end points with XL/FS object layer.
+	apiRouter := initTestWebRPCEndPoint(obj)
+	// initialize the server and obtain the credentials and root.
+	// credentials are necessary to sign the HTTP request.
+	rootPath, err := newTestConfig("us-east-1")
+	if err != nil {
+		t.Fatalf("Init Test config failed")
+	}
+	// remove the root folder after the test ends.
+	defer removeAll(rootPath)
+
+	credentials := serverConfig.GetCredential()
+
+	authorization, err := getWebRPCToken(apiRouter, credentials.AccessKeyID, credentials.SecretAccessKey)
+	if err != nil {
+		t.Fatal("Cannot authenticate")
+	}
+
+	bucketName := getRandomBucketName()
+	if err = obj.MakeBucket(bucketName); err != nil {
+		t.Fatalf("%s : %s", instanceType, err)
+	}
+	content := []byte("shared content")
+	for _, objectName := range []string{"shared/a.txt", "private/b.txt"} {
+		_, err = obj.PutObject(bucketName, objectName, int64(len(content)), bytes.NewReader(content), nil, "")
+		if err != nil {
+			t.Fatalf("Was not able to upload an object, %v", err)
+		}
+	}
+
+	// Invalid requests are rejected.
+	for i, args := range []ShareTokenArgs{
+		{BucketName: bucketName, Expiry: -1},
+		{BucketName: bucketName, Expiry: int64(maxScopedJWTExpiry/time.Second) + 1},
+		{BucketName: "nonexistent-bucket"},
+	} {
+		rec := httptest.NewRecorder()
+		req, rErr := newTestWebRPCRequest("Web.ShareToken", authorization, args)
+		if rErr != nil {
+			t.Fatalf("Failed to create HTTP request: <ERROR> %v", rErr)
+		}
+		apiRouter.ServeHTTP(rec, req)
+		if err = getTestWebRPCResponse(rec, &ShareTokenRep{}); err == nil {
+			t.Errorf("Test %d: Expected share token request to fail", i+1)
+		}
+	}
+
+	rec := httptest.NewRecorder()
+	req, err := newTestWebRPCRequest("Web.ShareToken", authorization, ShareTokenArgs{
+		BucketName: bucketName,
+		Prefix:     "shared/",
+	})
+	if err != nil {
+		t.Fatalf("Failed to create HTTP request: <ERROR> %v", err)
+	}
+	apiRouter.ServeHTTP(rec, req)
+	shareTokenRep := &ShareTokenRep{}
+	if err = getTestWebRPCResponse(rec, &shareTokenRep); err != nil {
+		t.Fatalf("Failed, %v", err)
+	}
+	token := shareTokenRep.Token
+
+	// Scoped tokens may only download objects within their scope.
+	downloadCases := []struct {
+		objectName     string
+		expectedStatus int
+	}{
+		{"shared/a.txt", http.StatusOK},
+		{"private/b.txt", http.StatusForbidden},
+	}
+	for i, testCase := range downloadCases {
+		rec = httptest.NewRecorder()
+		req, err = http.NewRequest("GET", "/minio/download/"+bucketName+"/"+testCase.objectName+"?token="+token, nil)
+		if err != nil {
+			t.Fatalf("Cannot create download request, %v", err)
+		}
+		apiRouter.ServeHTTP(rec, req)
+		if rec.Code != testCase.expectedStatus {
+			t.Errorf("Test %d: Expected the response status to be %d, but instead found `%d`", i+1, testCase.expectedStatus, rec.Code)
+		}
+	}
+
+	// Scoped tokens may only list objects within their scope.
+	listCases := []struct {
+		args      ListObjectsArgs
+		shouldErr bool
+	}{
+		{ListObjectsArgs{BucketName: bucketName, Prefix: "shared/"}, false},
+		{ListObjectsArgs{BucketName: bucketName, Prefix: "private/"}, true},
+		{ListObjectsArgs{BucketName: bucketName}, true},
+	}
+	for i, testCase := range listCases {
+		rec = httptest.NewRecorder()
+		req, err = newTestWebRPCRequest("Web.ListObjects", token, testCase.args)
+		if err != nil {
+			t.Fatalf("Failed to create HTTP request: <ERROR> %v", err)
+		}
+		apiRouter.ServeHTTP(rec, req)
+		err = getTestWebRPCResponse(rec, &ListObjectsRep{})
+		if testCase.shouldErr != (err != nil) {
+			t.Errorf("Test %d: Expected error %v, got %v", i+1, testCase.shouldErr, err)
+		}
+	}
+
+	// Scoped tokens cannot be used for anything else.
+	for _, rpcMethod := range []string{"Web.ListBuckets", "Web.ShareToken"} {
+		rec = httptest.NewRecorder()
+		req, err = newTestWebRPCRequest(rpcMethod, token, ShareTokenArgs{BucketName: bucketName})
+		if err != nil {
+			t.Fatalf("Failed to create HTTP request: <ERROR> %v", err)
+		}
+		apiRouter.ServeHTTP(rec, req)
+		if err = getTestWebRPCResponse(rec, &ShareTokenRep{}); err == nil {
+			t.Errorf("%s: Expected scoped token to be rejected", rpcMethod)
+		}
+	}
+	if isRPCTokenValid(token, jwtAudienceInterNode) {
+		t.Error("Expected scoped token to be rejected by RPC servers")
+	}
+}
+
 // Wrapper for calling GetBucketPolicy Handler
 func TestWebHandlerGetBucketPolicyHandler(t *testing.T) {
 	ExecObjectLayerTest(t, testWebGetBucketPolicyHandler)
@@ -1067,6 +1655,217 @@ func testWebSetBucketPolicyHandler(obj ObjectLayer, instanceType string, t TestE
 			t.Fatalf("Test %d: Should fail it didn't", i+1)
 		}
 	}
+
+	// Concurrent changes of different prefixes are all kept.
+	prefixes := []string{"a", "b", "c", "d", "e", "f"}
+	var wg sync.WaitGroup
+	for _, prefix := range prefixes {
+		wg.Add(1)
+		go func(prefix string) {
+			defer wg.Done()
+			args := &SetBucketPolicyArgs{BucketName: bucketName, Prefix: prefix, Policy: "readonly"}
+			req, rErr := newTestWebRPCRequest("Web.SetBucketPolicy", authorization, args)
+			if rErr != nil {
+				t.Errorf("Prefix %s: Failed to create HTTP request: <ERROR> %v", prefix, rErr)
+				return
+			}
+			prefixRec := httptest.NewRecorder()
+			apiRouter.ServeHTTP(prefixRec, req)
+			if rErr = getTestWebRPCResponse(prefixRec, &WebGenericRep{}); rErr != nil {
+				t.Errorf("Prefix %s: Should succeed but it didn't, %v", prefix, rErr)
+			}
+		}(prefix)
+	}
+	wg.Wait()
+	policyInfo, err := readBucketAccessPolicy(obj, bucketName)
+	if err != nil {
+		t.Fatalf("Unable to read bucket policy: %v", err)
+	}
+	for _, prefix := range prefixes {
+		if got := policy.GetPolicy(policyInfo.Statements, bucketName, prefix); got != policy.BucketPolicyReadOnly {
+			t.Errorf("Prefix %s: Expected policy %s, got %s", prefix, policy.BucketPolicyReadOnly, got)
+		}
+	}
+}
+
+// Wrapper for calling bucket notification rule web handlers.
+func TestWebHandlerBucketNotificationRules(t *testing.T) {
+	ExecObjectLayerTest(t, testWebBucketNotificationRules)
+}
+
+// testWebBucketNotificationRules - Test partial updates of bucket
+// notification config through web handlers.
+func testWebBucketNotificationRules(obj ObjectLayer, instanceType string, t TestErrHandler) {
+	// Register the API end points with XL/FS object layer.
+	apiRouter := initTestWebRPCEndPoint(obj)
+	// initialize the server and obtain the credentials and root.
+	// credentials are necessary to sign the HTTP request.
+	rootPath, err := newTestConfig("us-east-1")
+	if err != nil {
+		t.Fatalf("Init Test config failed")
+	}
+	// remove the root folder after the test ends.
+	defer removeAll(rootPath)
+
+	credentials := serverConfig.GetCredential()
+
+	authorization, err := getWebRPCToken(apiRouter, credentials.AccessKeyID, credentials.SecretAccessKey)
+	if err != nil {
+		t.Fatal("Cannot authenticate")
+	}
+
+	// Create a bucket
+	bucketName := getRandomBucketName()
+	if err = obj.MakeBucket(bucketName); err != nil {
+		t.Fatal("Unexpected error: ", err)
+	}
+
+	// Calls web rpc and returns the error if any.
+	callWebRPC := func(method string, args interface{}, reply interface{}) error {
+		rec := httptest.NewRecorder()
+		req, rerr := newTestWebRPCRequest(method, authorization, args)
+		if rerr != nil {
+			t.Fatalf("%s: Failed to create HTTP request: <ERROR> %v", method, rerr)
+		}
+		apiRouter.ServeHTTP(rec, req)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("%s: Expected the response status to be 200, but instead found `%d`", method, rec.Code)
+		}
+		return getTestWebRPCResponse(rec, reply)
+	}
+
+	newRule := func(id, arn string) queueConfig {
+		return queueConfig{
+			ServiceConfig: ServiceConfig{
+				Events: []string{"s3:ObjectCreated:*"},
+				ID:     id,
+			},
+			QueueARN: arn,
+		}
+	}
+
+	getReply := &GetBucketNotificationRep{}
+	if err = callWebRPC("Web.GetBucketNotification", &GetBucketNotificationArgs{BucketName: bucketName}, getReply); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if getReply.ETag != "" || len(getReply.Rules) != 0 {
+		t.Fatalf("Expected no notification config, got %v", getReply)
+	}
+
+	// Rules are validated against reachable targets when added,
+	// persist the initial ones directly.
+	ncfg := &notificationConfig{
+		QueueConfigs: []queueConfig{
+			newRule("1", "arn:minio:sqs:us-east-1:1:redis"),
+			newRule("2", "arn:minio:sqs:us-east-1:1:amqp"),
+		},
+	}
+	if err = persistNotificationConfig(bucketName, ncfg, obj); err != nil {
+		t.Fatalf("Unable to persist notification config: %v", err)
+	}
+	if err = callWebRPC("Web.GetBucketNotification", &GetBucketNotificationArgs{BucketName: bucketName}, getReply); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(getReply.Rules) != 2 {
+		t.Fatalf("Expected 2 rules, got %v", getReply.Rules)
+	}
+	etag := getReply.ETag
+
+	testCases := []struct {
+		method string
+		args   interface{}
+		err    error
+	}{
+		// Stale ETag is rejected.
+		{"Web.RemoveBucketNotificationRule", &RemoveBucketNotificationRuleArgs{BucketName: bucketName, ETag: "", ID: "1"}, errNotificationConfigModified},
+		// Duplicate rule id.
+		{"Web.AddBucketNotificationRule", &AddBucketNotificationRuleArgs{BucketName: bucketName, ETag: etag, Rule: newRule("1", "arn:minio:sqs:us-east-1:1:elasticsearch")}, errNotificationRuleExists},
+		// Duplicate queue ARN.
+		{"Web.AddBucketNotificationRule", &AddBucketNotificationRuleArgs{BucketName: bucketName, ETag: etag, Rule: newRule("3", "arn:minio:sqs:us-east-1:1:redis")}, errors.New(getAPIError(ErrOverlappingConfigs).Description)},
+		// Inexistent rule id.
+		{"Web.RemoveBucketNotificationRule", &RemoveBucketNotificationRuleArgs{BucketName: bucketName, ETag: etag, ID: "3"}, errNoSuchNotificationRule},
+		// Invalid queue ARN.
+		{"Web.AddBucketNotificationRule", &AddBucketNotificationRuleArgs{BucketName: bucketName, ETag: etag, Rule: newRule("3", "arn:minio:sqs:us-east-1:1:foo")}, errors.New(getAPIError(ErrARNNotification).Description)},
+		// Inexistent bucket.
+		{"Web.RemoveBucketNotificationRule", &RemoveBucketNotificationRuleArgs{BucketName: "fooo", ID: "1"}, BucketNotFound{Bucket: "fooo"}},
+		// Valid removal.
+		{"Web.RemoveBucketNotificationRule", &RemoveBucketNotificationRuleArgs{BucketName: bucketName, ETag: etag, ID: "1"}, nil},
+	}
+	for i, testCase := range testCases {
+		err = callWebRPC(testCase.method, testCase.args, &BucketNotificationRuleRep{})
+		if testCase.err == nil && err != nil {
+			t.Fatalf("Test %d: Should succeed but it didn't, %v", i+1, err)
+		}
+		if testCase.err != nil && (err == nil || !strings.Contains(err.Error(), testCase.err.Error())) {
+			t.Fatalf("Test %d: Expected error `%v`, got `%v`", i+1, testCase.err, err)
+		}
+	}
+
+	if err = callWebRPC("Web.GetBucketNotification", &GetBucketNotificationArgs{BucketName: bucketName}, getReply); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(getReply.Rules) != 1 || getReply.Rules[0].ID != "2" {
+		t.Fatalf("Expected only rule `2` to remain, got %v", getReply.Rules)
+	}
+	if getReply.ETag == etag {
+		t.Fatalf("Expected ETag to change after rule removal")
+	}
+}
+
+// TestWebHandlerBrowserPrefix - Test web handlers served from a custom prefix.
+func TestWebHandlerBrowserPrefix(t *testing.T) {
+	saved := globalBrowserPrefix
+	globalBrowserPrefix = "/console"
+	defer func() { globalBrowserPrefix = saved }()
+
+	obj, fsDir, err := prepareFS()
+	if err != nil {
+		t.Fatalf("Initialization of object layer failed for FS setup: %s", err)
+	}
+	defer removeAll(fsDir)
+
+	apiRouter := initTestWebRPCEndPoint(obj)
+	rootPath, err := newTestConfig("us-east-1")
+	if err != nil {
+		t.Fatal("Init Test config failed", err)
+	}
+	defer removeAll(rootPath)
+
+	credentials := serverConfig.GetCredential()
+	// Login is served from the custom prefix.
+	authorization, err := getWebRPCToken(apiRouter, credentials.AccessKeyID, credentials.SecretAccessKey)
+	if err != nil {
+		t.Fatal("Cannot authenticate", err)
+	}
+
+	bucketName := getRandomBucketName()
+	if err = obj.MakeBucket(bucketName); err != nil {
+		t.Fatal("Unexpected error: ", err)
+	}
+	content := []byte("temporary file's content")
+	if _, err = obj.PutObject(bucketName, "object", int64(len(content)), bytes.NewReader(content), nil, ""); err != nil {
+		t.Fatalf("Was not able to upload an object, %v", err)
+	}
+
+	testCases := []struct {
+		path           string
+		expectedStatus int
+	}{
+		{"/console/download/" + bucketName + "/object?token=" + authorization, http.StatusOK},
+		// Browser is not served from the default prefix anymore.
+		{"/minio/download/" + bucketName + "/object?token=" + authorization, http.StatusNotFound},
+	}
+	for i, testCase := range testCases {
+		rec := httptest.NewRecorder()
+		req, rerr := http.NewRequest("GET", testCase.path, nil)
+		if rerr != nil {
+			t.Fatalf("Test %d: Cannot create request, %v", i+1, rerr)
+		}
+		apiRouter.ServeHTTP(rec, req)
+		if rec.Code != testCase.expectedStatus {
+			t.Fatalf("Test %d: Expected the response status to be %d, but instead found `%d`", i+1, testCase.expectedStatus, rec.Code)
+		}
+	}
 }
 
 // TestWebCheckAuthorization - Test Authorization for all web handlers
@@ -1094,7 +1893,8 @@ func TestWebCheckAuthorization(t *testing.T) {
 
 	// Check if web rpc calls return unauthorized request with an incorrect token
 	webRPCs := []string{"ServerInfo", "StorageInfo", "MakeBucket", "ListBuckets", "ListObjects", "RemoveObject", "GenerateAuth",
-		"SetAuth", "GetAuth", "GetBucketPolicy", "SetBucketPolicy"}
+		"SetAuth", "GetAuth", "GetBucketPolicy", "SetBucketPolicy", "GetBucketNotification",
+		"AddBucketNotificationRule", "RemoveBucketNotificationRule", "StatObject", "SetObjectMetadata"}
 	for _, rpcCall := range webRPCs {
 		args := &GenericArgs{}
 		reply := &WebGenericRep{}
@@ -1176,7 +1976,8 @@ func TestWebObjectLayerNotReady(t *testing.T) {
 	// Check if web rpc calls return Server not initialized. ServerInfo, GenerateAuth,
 	// SetAuth and GetAuth are not concerned
 	webRPCs := []string{"StorageInfo", "MakeBucket", "ListBuckets", "ListObjects", "RemoveObject",
-		"GetBucketPolicy", "SetBucketPolicy"}
+		"GetBucketPolicy", "SetBucketPolicy", "GetBucketNotification", "AddBucketNotificationRule",
+		"RemoveBucketNotificationRule", "StatObject", "SetObjectMetadata"}
 	for _, rpcCall := range webRPCs {
 		args := &GenericArgs{}
 		reply := &WebGenericRep{}
@@ -1271,7 +2072,8 @@ func TestWebObjectLayerFaultyDisks(t *testing.T) {
 
 	// Check if web rpc calls return errors with faulty disks.  ServerInfo, GenerateAuth, SetAuth, GetAuth are not concerned
 	webRPCs := []string{"MakeBucket", "ListBuckets", "ListObjects", "RemoveObject",
-		"GetBucketPolicy", "SetBucketPolicy"}
+		"GetBucketPolicy", "SetBucketPolicy", "GetBucketNotification", "AddBucketNotificationRule",
+		"RemoveBucketNotificationRule"}
 
 	for _, rpcCall := range webRPCs {
 		args := &GenericArgs{}
@@ -1343,3 +2145,97 @@ func TestWebObjectLayerFaultyDisks(t *testing.T) {
 		t.Fatalf("Unexpected error message, expected: `Invalid token`, found: `%s`", resp)
 	}
 }
+
+// closedResponseRecorder - records the first write and then fails, as
+// if the client disconnected.
+type closedResponseRecorder struct {
+	*httptest.ResponseRecorder
+}
+
+func (rec closedResponseRecorder) Write(b []byte) (int, error) {
+	rec.ResponseRecorder.Write(b)
+	return 0, errors.New("client disconnected")
+}
+
+// Wrapper for calling Listen Web Handler
+func TestWebHandlerListen(t *testing.T) {
+	ExecObjectLayerTest(t, testWebListenHandler)
+}
+
+// testWebListenHandler - Test Listen web handler
+func testWebListenHandler(obj ObjectLayer, instanceType string, t TestErrHandler) {
+	// Register the API end points with XL/FS object layer.
+	apiRouter := initTestWebRPCEndPoint(obj)
+	// initialize the server and obtain the credentials and root.
+	// credentials are necessary to sign the HTTP request.
+	rootPath, err := newTestConfig("us-east-1")
+	if err != nil {
+		t.Fatalf("Init Test config failed")
+	}
+	// remove the root folder after the test ends.
+	defer removeAll(rootPath)
+
+	credentials := serverConfig.GetCredential()
+
+	authorization, err := getWebRPCToken(apiRouter, credentials.AccessKeyID, credentials.SecretAccessKey)
+	if err != nil {
+		t.Fatal("Cannot authenticate")
+	}
+	if err = initEventNotifier(obj); err != nil {
+		t.Fatalf("%s: Unable to initialize event notifier: %v", instanceType, err)
+	}
+
+	bucketName := getRandomBucketName()
+	if err = obj.MakeBucket(bucketName); err != nil {
+		t.Fatalf("%s : %s", instanceType, err)
+	}
+	defer globalRecentEvents.Remove(bucketName)
+	for _, object := range []string{"a.jpg", "b.txt", "c.jpg"} {
+		eventNotify(eventData{
+			Type:    ObjectCreatedPut,
+			Bucket:  bucketName,
+			ObjInfo: ObjectInfo{Name: object},
+		})
+	}
+
+	testCases := []struct {
+		bucket   string
+		query    string
+		token    string
+		code     int
+		expected []string
+	}{
+		{bucketName, "replay=10&suffix=.jpg", authorization, http.StatusOK, []string{"a.jpg", "c.jpg"}},
+		{bucketName, "replay=1", authorization, http.StatusOK, []string{"c.jpg"}},
+		{bucketName, "replay=10&events=s3:ObjectRemoved:*", authorization, http.StatusOK, nil},
+		{bucketName, "replay=1", "invalid-token", http.StatusForbidden, nil},
+		{bucketName, "replay=1000", authorization, http.StatusBadRequest, nil},
+		{bucketName, "events=s3:Invalid", authorization, http.StatusBadRequest, nil},
+		{"nonexistent-bucket", "replay=1", authorization, http.StatusNotFound, nil},
+	}
+	for i, testCase := range testCases {
+		rec := httptest.NewRecorder()
+		req, rErr := http.NewRequest("GET", "/minio/listen/"+testCase.bucket+"?token="+testCase.token+"&"+testCase.query, nil)
+		if rErr != nil {
+			t.Fatalf("%s: Test %d: Cannot create listen request: %v", instanceType, i+1, rErr)
+		}
+		apiRouter.ServeHTTP(closedResponseRecorder{rec}, req)
+		if rec.Code != testCase.code {
+			t.Fatalf("%s: Test %d: Expected %d, got %d", instanceType, i+1, testCase.code, rec.Code)
+		}
+		if testCase.code != http.StatusOK {
+			continue
+		}
+		var notification map[string][]NotificationEvent
+		if err = json.Unmarshal(rec.Body.Bytes(), &notification); err != nil {
+			t.Fatalf("%s: Test %d: Unable to parse replayed events: %v", instanceType, i+1, err)
+		}
+		var keys []string
+		for _, event := range notification["Records"] {
+			keys = append(keys, event.S3.Object.Key)
+		}
+		if !reflect.DeepEqual(keys, testCase.expected) {
+			t.Errorf("%s: Test %d: Expected %v, got %v", instanceType, i+1, testCase.expected, keys)
+		}
+	}
+}
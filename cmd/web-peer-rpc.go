@@ -30,7 +30,7 @@ func (br *browserAPIHandlers) LoginHandler(args *RPCLoginArgs, reply *RPCLoginRe
 	if err = jwt.Authenticate(args.Username, args.Password); err != nil {
 		return err
 	}
-	token, err := jwt.GenerateToken(args.Username)
+	token, err := jwt.GenerateToken(args.Username, jwtAudienceInterNode)
 	if err != nil {
 		return err
 	}
@@ -56,17 +56,23 @@ type SetAuthPeerArgs struct {
 // will be forced to re-establish connections. Connections will be
 // re-established only when the sending client has also updated its
 // credentials.
-func (br *browserAPIHandlers) SetAuthPeer(args SetAuthPeerArgs, reply *GenericReply) error {
+func (br *browserAPIHandlers) SetAuthPeer(args SetAuthPeerArgs, reply *GenericReply) (err error) {
 	// Check auth
-	if !isRPCTokenValid(args.Token) {
+	if !isRPCTokenValid(args.Token, jwtAudienceInterNode) {
 		return errInvalidToken
 	}
 
+	// Retries get the result of the first call.
+	if replayed, replayErr := globalIdempotentCalls.Begin("Browser.SetAuthPeer", args.IdempotencyKey, reply); replayed {
+		return replayErr
+	}
+	defer globalIdempotentCalls.End("Browser.SetAuthPeer", args.IdempotencyKey, reply, &err)
+
 	// Update credentials in memory
 	serverConfig.SetCredential(args.Creds)
 
 	// Save credentials to config file
-	if err := serverConfig.Save(); err != nil {
+	if err = serverConfig.Save(); err != nil {
 		errorIf(err, "Error updating config file with new credentials sent from browser RPC.")
 		return err
 	}
@@ -83,6 +89,9 @@ func updateCredsOnPeers(creds credential) map[string]error {
 	errs := make([]error, len(peers))
 	var wg sync.WaitGroup
 
+	// Retries below must not apply the credentials twice.
+	idempotencyKey := getUUID()
+
 	// Launch go routines to send request to each peer in
 	// parallel.
 	for ix := range peers {
@@ -104,10 +113,14 @@ func updateCredsOnPeers(creds credential) map[string]error {
 				address:     peers[ix],
 				path:        path.Join(reservedBucket, browserPath),
 				loginMethod: "Browser.LoginHandler",
+				timeout:     peerRPCTimeout,
 			})
 
 			// Construct RPC call arguments.
-			args := SetAuthPeerArgs{Creds: creds}
+			args := SetAuthPeerArgs{
+				GenericArgs: GenericArgs{IdempotencyKey: idempotencyKey},
+				Creds:       creds,
+			}
 
 			// Make RPC call - we only care about error
 			// response and not the reply.
@@ -17,8 +17,13 @@
 package cmd
 
 import (
+	"crypto/md5"
+	"encoding/hex"
 	"fmt"
 	"net/http"
+	"path"
+	"strings"
+	"sync"
 
 	"github.com/elazarl/go-bindata-assetfs"
 	"github.com/gorilla/handlers"
@@ -40,7 +45,7 @@ type indexHandler struct {
 }
 
 func (h indexHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
-	r.URL.Path = reservedBucket + "/"
+	r.URL.Path = globalBrowserPrefix + "/"
 	h.handler.ServeHTTP(w, r)
 }
 
@@ -55,6 +60,54 @@ func assetFS() *assetfs.AssetFS {
 	}
 }
 
+// assetETagHandler - sets an ETag computed from the content of the
+// requested asset, http.FileServer then replies with 304 (not modified)
+// to requests with a matching If-None-Match header.
+type assetETagHandler struct {
+	handler http.Handler
+	asset   func(name string) ([]byte, error)
+	mutex   *sync.Mutex
+	etags   map[string]string
+}
+
+func newAssetETagHandler(h http.Handler, asset func(name string) ([]byte, error)) http.Handler {
+	return assetETagHandler{
+		handler: h,
+		asset:   asset,
+		mutex:   &sync.Mutex{},
+		etags:   make(map[string]string),
+	}
+}
+
+// getETag - returns the ETag of an asset, empty if there is no such
+// asset. Assets are compressed on the fly so the ETag is weak.
+func (h assetETagHandler) getETag(name string) string {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	if etag, ok := h.etags[name]; ok {
+		return etag
+	}
+	data, err := h.asset(path.Join(assetPrefix, name))
+	if err != nil || data == nil {
+		return ""
+	}
+	sum := md5.Sum(data)
+	etag := "W/\"" + hex.EncodeToString(sum[:]) + "\""
+	h.etags[name] = etag
+	return etag
+}
+
+func (h assetETagHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	name := r.URL.Path
+	if strings.HasSuffix(name, slashSeparator) {
+		name += "index.html"
+	}
+	if etag := h.getETag(name); etag != "" {
+		w.Header().Set("ETag", etag)
+	}
+	h.handler.ServeHTTP(w, r)
+}
+
 // specialAssets are files which are unique files not embedded inside index_bundle.js.
 const specialAssets = "loader.css|logo.svg|firefox.png|safari.png|chrome.png|favicon.ico"
 
@@ -69,7 +122,7 @@ func registerWebRouter(mux *router.Router) error {
 	codec := json2.NewCodec()
 
 	// Minio browser router.
-	webBrowserRouter := mux.NewRoute().PathPrefix(reservedBucket).Subrouter()
+	webBrowserRouter := mux.NewRoute().PathPrefix(globalBrowserPrefix).Subrouter()
 
 	// Initialize json rpc handlers.
 	webRPC := jsonrpc.NewServer()
@@ -83,8 +136,12 @@ func registerWebRouter(mux *router.Router) error {
 
 	// RPC handler at URI - /minio/webrpc
 	webBrowserRouter.Methods("POST").Path("/webrpc").Handler(webRPC)
+	webBrowserRouter.Methods("PUT").Path("/upload/{bucket}/{object:.+}").Queries("uploadId", "{uploadId:.*}", "partNumber", "{partNumber:[0-9]+}").HandlerFunc(web.UploadPart)
 	webBrowserRouter.Methods("PUT").Path("/upload/{bucket}/{object:.+}").HandlerFunc(web.Upload)
+	webBrowserRouter.Methods("GET").Path("/download/{bucket}/{object:.+}").Queries("token", "{token:.*}", "archive", "{archive:.*}").HandlerFunc(web.DownloadArchive)
 	webBrowserRouter.Methods("GET").Path("/download/{bucket}/{object:.+}").Queries("token", "{token:.*}").HandlerFunc(web.Download)
+	webBrowserRouter.Methods("GET").Path("/preview/{bucket}/{object:.+}").Queries("token", "{token:.*}").HandlerFunc(web.Preview)
+	webBrowserRouter.Methods("GET").Path("/listen/{bucket}").Queries("token", "{token:.*}").HandlerFunc(web.Listen)
 
 	// 2016.9.18 Mingfeng: Move authboss setup from api-router to here
 	myauthboss.SetupStorer()
@@ -95,14 +152,17 @@ func registerWebRouter(mux *router.Router) error {
 	// 2016.9.18 Mingfeng: Redirect from authboss to minio
 	mux.Path("/redirectMinio").HandlerFunc(web.redirectMinioHandler)
 
+	// Serve assets with ETags so that they can be revalidated.
+	assets := newAssetETagHandler(http.FileServer(assetFS()), miniobrowser.Asset)
+
 	// Add compression for assets.
-	compressedAssets := handlers.CompressHandler(http.StripPrefix(reservedBucket, http.FileServer(assetFS())))
+	compressedAssets := handlers.CompressHandler(http.StripPrefix(globalBrowserPrefix, assets))
 
 	// Serve javascript files and favicon from assets.
 	webBrowserRouter.Path(fmt.Sprintf("/{assets:[^/]+.js|%s}", specialAssets)).Handler(compressedAssets)
 
 	// Serve index.html for rest of the requests.
-	webBrowserRouter.Path("/{index:.*}").Handler(indexHandler{http.StripPrefix(reservedBucket, http.FileServer(assetFS()))})
+	webBrowserRouter.Path("/{index:.*}").Handler(indexHandler{http.StripPrefix(globalBrowserPrefix, assets)})
 
 	return nil
 }
@@ -0,0 +1,90 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package cmd
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Tests that browser assets are served with ETags and revalidated.
+func TestAssetETagHandler(t *testing.T) {
+	assets := map[string]string{
+		"index.html":      "<html></html>",
+		"index_bundle.js": "var a;",
+	}
+	asset := func(name string) ([]byte, error) {
+		if data, ok := assets[strings.TrimPrefix(name, assetPrefix+"/")]; ok {
+			return []byte(data), nil
+		}
+		return nil, errors.New("not found")
+	}
+	modTime := time.Unix(1475025183, 0)
+	fileServer := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		name := r.URL.Path
+		if strings.HasSuffix(name, "/") {
+			name += "index.html"
+		}
+		data, ok := assets[strings.TrimPrefix(name, "/")]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		http.ServeContent(w, r, path.Base(name), modTime, strings.NewReader(data))
+	})
+	handler := newAssetETagHandler(fileServer, asset)
+
+	testCases := []struct {
+		path         string
+		ifNoneMatch  bool
+		expectedCode int
+		expectETag   bool
+	}{
+		{"/index_bundle.js", false, http.StatusOK, true},
+		{"/index_bundle.js", true, http.StatusNotModified, true},
+		{"/", false, http.StatusOK, true},
+		{"/", true, http.StatusNotModified, true},
+		{"/missing.js", false, http.StatusNotFound, false},
+	}
+	for i, testCase := range testCases {
+		req := httptest.NewRequest("GET", testCase.path, nil)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		etag := rec.Header().Get("ETag")
+		if testCase.ifNoneMatch {
+			req = httptest.NewRequest("GET", testCase.path, nil)
+			req.Header.Set("If-None-Match", etag)
+			rec = httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+		}
+		if rec.Code != testCase.expectedCode {
+			t.Errorf("Test %d: Expected status %d, got %d", i+1, testCase.expectedCode, rec.Code)
+		}
+		if (etag != "") != testCase.expectETag {
+			t.Errorf("Test %d: Expected ETag %v, got %q", i+1, testCase.expectETag, etag)
+		}
+	}
+
+	// Different assets have different ETags.
+	if h := handler.(assetETagHandler); h.getETag("/index.html") == h.getETag("/index_bundle.js") {
+		t.Error("Expected different ETags for different assets")
+	}
+}
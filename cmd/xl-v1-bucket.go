@@ -122,6 +122,104 @@ func (xl xlObjects) undoMakeBucket(bucket string) {
 	wg.Wait()
 }
 
+// RenameBucket - renames a bucket along with its metadata on all disks.
+func (xl xlObjects) RenameBucket(srcBucket, dstBucket string) error {
+	// Verify if buckets are valid.
+	if !IsValidBucketName(srcBucket) {
+		return traceError(BucketNameInvalid{Bucket: srcBucket})
+	}
+	if !IsValidBucketName(dstBucket) {
+		return traceError(BucketNameInvalid{Bucket: dstBucket})
+	}
+
+	// get a random ID for lock instrumentation.
+	opsID := getOpsID()
+
+	// Always lock in the same order to avoid deadlocks.
+	buckets := []string{srcBucket, dstBucket}
+	sort.Strings(buckets)
+	for _, bucket := range buckets {
+		nsMutex.Lock(bucket, "", opsID)
+		defer nsMutex.Unlock(bucket, "", opsID)
+	}
+
+	// Check both buckets upfront, the renames below fail on every
+	// disk otherwise, which reads as lost quorum.
+	if !xl.isBucketExist(srcBucket) {
+		return traceError(BucketNotFound{Bucket: srcBucket})
+	}
+	if xl.isBucketExist(dstBucket) {
+		return traceError(BucketExists{Bucket: dstBucket})
+	}
+
+	// Initialize sync waitgroup.
+	var wg = &sync.WaitGroup{}
+
+	// Initialize list of errors.
+	var dErrs = make([]error, len(xl.storageDisks))
+
+	// Disks on which the volume was renamed.
+	var renamed = make([]bool, len(xl.storageDisks))
+
+	// Rename the volume and its metadata on all underlying storage disks.
+	for index, disk := range xl.storageDisks {
+		if disk == nil {
+			dErrs[index] = traceError(errDiskNotFound)
+			continue
+		}
+		wg.Add(1)
+		go func(index int, disk StorageAPI) {
+			defer wg.Done()
+			if err := disk.RenameVol(srcBucket, dstBucket); err != nil {
+				dErrs[index] = traceError(err)
+				return
+			}
+			renamed[index] = true
+			dErrs[index] = renameBucketMetadata(disk, srcBucket, dstBucket)
+		}(index, disk)
+	}
+
+	// Wait for all the renames to finish.
+	wg.Wait()
+
+	// Do we have write quorum?.
+	if !isDiskQuorum(dErrs, xl.writeQuorum) {
+		// Rename the bucket back on the disks where it was renamed.
+		xl.undoRenameBucket(srcBucket, dstBucket, renamed)
+		return toObjectErr(traceError(errXLWriteQuorum), srcBucket)
+	}
+
+	if reducedErr := reduceErrs(dErrs, []error{
+		errDiskNotFound,
+		errFaultyDisk,
+		errDiskAccessDenied,
+	}); reducedErr != nil {
+		return renameBucketErr(reducedErr, srcBucket, dstBucket)
+	}
+	return nil
+}
+
+// undo rename bucket operation upon quorum failure, on the disks
+// where the volume was renamed.
+func (xl xlObjects) undoRenameBucket(srcBucket, dstBucket string, renamed []bool) {
+	// Initialize sync waitgroup.
+	var wg = &sync.WaitGroup{}
+	for index, disk := range xl.storageDisks {
+		if disk == nil || !renamed[index] {
+			continue
+		}
+		wg.Add(1)
+		go func(index int, disk StorageAPI) {
+			defer wg.Done()
+			_ = renameBucketMetadata(disk, dstBucket, srcBucket)
+			_ = disk.RenameVol(dstBucket, srcBucket)
+		}(index, disk)
+	}
+
+	// Wait for all the renames to finish.
+	wg.Wait()
+}
+
 // list all errors that can be ignored in a bucket metadata operation.
 var bucketMetadataOpIgnoredErrs = []error{
 	errDiskNotFound,
@@ -27,6 +27,14 @@ var errXLMinDisks = errors.New("Minimum '4' disks are required to enable erasure
 // errXLNumDisks - returned for odd number of disks.
 var errXLNumDisks = errors.New("Total number of disks should be multiples of '2'")
 
+// errXLInvalidReadQuorum - returned for a configured read quorum weaker than
+// the default or higher than the number of disks.
+var errXLInvalidReadQuorum = errors.New("Read quorum should be between half of the disks and all the disks")
+
+// errXLInvalidWriteQuorum - returned for a configured write quorum weaker than
+// the default or higher than the number of disks.
+var errXLInvalidWriteQuorum = errors.New("Write quorum should be between half of the disks plus one and all the disks")
+
 // errXLReadQuorum - did not meet read quorum.
 var errXLReadQuorum = errors.New("Read failed. Insufficient number of disks online")
 
@@ -16,7 +16,10 @@
 
 package cmd
 
-import "sync"
+import (
+	"encoding/hex"
+	"sync"
+)
 
 // Heals a bucket if it doesn't exist on one of the disks.
 func (xl xlObjects) HealBucket(bucket string) error {
@@ -224,3 +227,133 @@ func (xl xlObjects) HealObject(bucket, object string) error {
 	}
 	return nil
 }
+
+// getCorruptedDisks - returns the metadata and the online disks of
+// object, along with the disks whose parts don't match the checksums
+// recorded in their `xl.json`. Disks failing to be read are not
+// considered corrupted. The object lock must be held by the caller.
+func (xl xlObjects) getCorruptedDisks(bucket, object string) (metaArr []xlMetaV1, onlineDisks []StorageAPI, corrupted []bool, err error) {
+	metaArr, errs := readAllXLMetadata(xl.storageDisks, bucket, object)
+	if !isDiskQuorum(errs, xl.readQuorum) {
+		return nil, nil, nil, traceError(InsufficientReadQuorum{}, errs...)
+	}
+	if reducedErr := reduceErrs(errs, []error{
+		errDiskNotFound,
+		errFaultyDisk,
+		errDiskAccessDenied,
+	}); reducedErr != nil {
+		return nil, nil, nil, toObjectErr(reducedErr, bucket, object)
+	}
+	onlineDisks, _ = listOnlineDisks(xl.storageDisks, metaArr, errs)
+
+	corrupted = make([]bool, len(onlineDisks))
+	var wg = &sync.WaitGroup{}
+	for index, disk := range onlineDisks {
+		if disk == nil {
+			continue
+		}
+		wg.Add(1)
+		go func(index int, disk StorageAPI) {
+			defer wg.Done()
+			for _, part := range metaArr[index].Parts {
+				sumInfo, sErr := metaArr[index].Erasure.GetCheckSumInfo(part.Name)
+				if sErr != nil {
+					corrupted[index] = true
+					return
+				}
+				hashBytes, hErr := hashSum(disk, bucket, pathJoin(object, part.Name), newHash(sumInfo.Algorithm))
+				if errorCause(hErr) == errFileNotFound {
+					corrupted[index] = true
+					return
+				}
+				if hErr != nil {
+					errorIf(hErr, "Unable to calculate checksum %s/%s", bucket, pathJoin(object, part.Name))
+					return
+				}
+				if hex.EncodeToString(hashBytes) != sumInfo.Hash {
+					corrupted[index] = true
+					return
+				}
+			}
+		}(index, disk)
+	}
+	wg.Wait()
+	return metaArr, onlineDisks, corrupted, nil
+}
+
+// VerifyObject - verifies the checksums of all the parts of object on
+// all the disks holding it, returns ObjectCorrupted if any of them
+// doesn't match. Corrupted copies are healed in the background.
+func (xl xlObjects) VerifyObject(bucket, object string) error {
+	// Verify if bucket is valid.
+	if !IsValidBucketName(bucket) {
+		return traceError(BucketNameInvalid{Bucket: bucket})
+	}
+	// Verify if object is valid.
+	if !IsValidObjectName(object) {
+		return traceError(ObjectNameInvalid{Bucket: bucket, Object: object})
+	}
+
+	// get a random ID for lock instrumentation.
+	opsID := getOpsID()
+
+	nsMutex.RLock(bucket, object, opsID)
+	_, _, corrupted, err := xl.getCorruptedDisks(bucket, object)
+	nsMutex.RUnlock(bucket, object, opsID)
+	if err != nil {
+		return err
+	}
+	for _, isCorrupted := range corrupted {
+		if isCorrupted {
+			go func() {
+				errorIf(xl.healCorruptedObject(bucket, object), "Unable to heal corrupted object %s/%s", bucket, object)
+			}()
+			return traceError(ObjectCorrupted{Bucket: bucket, Object: object})
+		}
+	}
+	return nil
+}
+
+// healCorruptedObject - removes the corrupted copies of object, then
+// heals them. Copies are only removed if enough intact ones remain to
+// read the object.
+func (xl xlObjects) healCorruptedObject(bucket, object string) error {
+	// get a random ID for lock instrumentation.
+	opsID := getOpsID()
+
+	nsMutex.Lock(bucket, object, opsID)
+	metaArr, onlineDisks, corrupted, err := xl.getCorruptedDisks(bucket, object)
+	if err != nil {
+		nsMutex.Unlock(bucket, object, opsID)
+		return err
+	}
+	intact := 0
+	for index, disk := range onlineDisks {
+		if disk != nil && !corrupted[index] {
+			intact++
+		}
+	}
+	if intact < xl.readQuorum {
+		nsMutex.Unlock(bucket, object, opsID)
+		return traceError(errXLReadQuorum)
+	}
+	for index, disk := range onlineDisks {
+		if disk == nil || !corrupted[index] {
+			continue
+		}
+		// Removed copies are then healed as missing ones.
+		for _, part := range metaArr[index].Parts {
+			if dErr := disk.DeleteFile(bucket, pathJoin(object, part.Name)); dErr != nil && dErr != errFileNotFound {
+				nsMutex.Unlock(bucket, object, opsID)
+				return traceError(dErr)
+			}
+		}
+		if dErr := disk.DeleteFile(bucket, pathJoin(object, xlMetaJSONFile)); dErr != nil {
+			nsMutex.Unlock(bucket, object, opsID)
+			return traceError(dErr)
+		}
+	}
+	nsMutex.Unlock(bucket, object, opsID)
+
+	return xl.HealObject(bucket, object)
+}
@@ -94,17 +94,18 @@ func (xl xlObjects) listObjects(bucket, prefix, marker, delimiter string, maxKey
 			continue
 		}
 		result.Objects = append(result.Objects, ObjectInfo{
-			Name:    objInfo.Name,
-			ModTime: objInfo.ModTime,
-			Size:    objInfo.Size,
-			MD5Sum:  objInfo.MD5Sum,
-			IsDir:   false,
+			Name:        objInfo.Name,
+			ModTime:     objInfo.ModTime,
+			Size:        objInfo.Size,
+			MD5Sum:      objInfo.MD5Sum,
+			IsDir:       false,
+			UserDefined: objInfo.UserDefined,
 		})
 	}
 	return result, nil
 }
 
-// ListObjects - list all objects at prefix, delimited by '/'.
+// ListObjects - list all objects at prefix, optionally delimited.
 func (xl xlObjects) ListObjects(bucket, prefix, marker, delimiter string, maxKeys int) (ListObjectsInfo, error) {
 	// Verify if bucket is valid.
 	if !IsValidBucketName(bucket) {
@@ -117,12 +118,6 @@ func (xl xlObjects) ListObjects(bucket, prefix, marker, delimiter string, maxKey
 	if !IsValidObjectPrefix(prefix) {
 		return ListObjectsInfo{}, traceError(ObjectNameInvalid{Bucket: bucket, Object: prefix})
 	}
-	// Verify if delimiter is anything other than '/', which we do not support.
-	if delimiter != "" && delimiter != slashSeparator {
-		return ListObjectsInfo{}, traceError(UnsupportedDelimiter{
-			Delimiter: delimiter,
-		})
-	}
 	// Verify if marker has prefix.
 	if marker != "" {
 		if !strings.HasPrefix(marker, prefix) {
@@ -151,6 +146,13 @@ func (xl xlObjects) ListObjects(bucket, prefix, marker, delimiter string, maxKey
 		maxKeys = maxObjectList
 	}
 
+	// Roll up recursively listed keys for delimiters other than '/'.
+	if delimiter != "" && delimiter != slashSeparator {
+		return listObjectsWithDelimiter(func(marker string, maxKeys int) (ListObjectsInfo, error) {
+			return xl.ListObjects(bucket, prefix, marker, "", maxKeys)
+		}, prefix, marker, delimiter, maxKeys)
+	}
+
 	// Initiate a list operation, if successful filter and return quickly.
 	listObjInfo, err := xl.listObjects(bucket, prefix, marker, delimiter, maxKeys)
 	if err == nil {
@@ -17,8 +17,6 @@
 package cmd
 
 import (
-	"crypto/md5"
-	"crypto/sha256"
 	"encoding/hex"
 	"fmt"
 	"hash"
@@ -82,7 +80,9 @@ func (xl xlObjects) listMultipartUploads(bucket, prefix, keyMarker, uploadIDMark
 			break
 		}
 		nsMutex.RUnlock(minioMetaBucket, pathJoin(mpartMetaPrefix, bucket, keyMarker), opsID)
-		if err != nil {
+		// All uploads of keyMarker may have been completed or
+		// aborted since the previous listing, continue the walk.
+		if err != nil && !isErrIgnored(err, xlTreeWalkIgnoredErrs) {
 			return ListMultipartsInfo{}, err
 		}
 		maxUploads = maxUploads - len(uploads)
@@ -113,7 +113,7 @@ func (xl xlObjects) listMultipartUploads(bucket, prefix, keyMarker, uploadIDMark
 				if isErrIgnored(walkResult.err, xlTreeWalkIgnoredErrs) {
 					continue
 				}
-				return ListMultipartsInfo{}, err
+				return ListMultipartsInfo{}, walkResult.err
 			}
 			entry := strings.TrimPrefix(walkResult.entry, retainSlash(pathJoin(mpartMetaPrefix, bucket)))
 			// For an entry looking like a directory, store and
@@ -124,7 +124,7 @@ func (xl xlObjects) listMultipartUploads(bucket, prefix, keyMarker, uploadIDMark
 				})
 				maxUploads--
 				if maxUploads == 0 {
-					eof = true
+					eof = walkResult.end
 					break
 				}
 				continue
@@ -185,10 +185,11 @@ func (xl xlObjects) listMultipartUploads(bucket, prefix, keyMarker, uploadIDMark
 		result.NextUploadIDMarker = uploadID
 	}
 
-	if !eof {
+	if !eof && walkerCh != nil {
 		// Save the go-routine state in the pool so that it can continue from where it left off on
-		// the next request.
-		xl.listPool.Set(listParams{bucket, recursive, result.NextKeyMarker, prefix, heal}, walkerCh, walkerDoneCh)
+		// the next request, keyed the same way it is released above.
+		nextMarkerPath := pathJoin(mpartMetaPrefix, bucket, result.NextKeyMarker)
+		xl.listPool.Set(listParams{minioMetaBucket, recursive, nextMarkerPath, multipartPrefixPath, heal}, walkerCh, walkerDoneCh)
 	}
 
 	result.IsTruncated = !eof
@@ -278,6 +279,17 @@ func (xl xlObjects) newMultipartUpload(bucket string, object string, meta map[st
 	xlMeta.Stat.ModTime = time.Now().UTC()
 	xlMeta.Meta = meta
 
+	uploadID = getUUID()
+	initiated := time.Now().UTC()
+	uploadIDPath := path.Join(mpartMetaPrefix, bucket, object, uploadID)
+	tempUploadIDPath := path.Join(tmpMetaPrefix, uploadID)
+	// Write updated `xl.json` to all disks, this is done before
+	// taking the lock on `uploads.json` so that concurrent uploads
+	// of the same object only serialize on the update below.
+	if err = writeSameXLMetadata(xl.storageDisks, minioMetaBucket, tempUploadIDPath, xlMeta, xl.writeQuorum, xl.readQuorum); err != nil {
+		return "", toObjectErr(err, minioMetaBucket, tempUploadIDPath)
+	}
+
 	// get a random ID for lock instrumentation.
 	opsID := getOpsID()
 
@@ -285,18 +297,11 @@ func (xl xlObjects) newMultipartUpload(bucket string, object string, meta map[st
 	nsMutex.Lock(minioMetaBucket, pathJoin(mpartMetaPrefix, bucket, object), opsID)
 	defer nsMutex.Unlock(minioMetaBucket, pathJoin(mpartMetaPrefix, bucket, object), opsID)
 
-	uploadID = getUUID()
-	initiated := time.Now().UTC()
 	// Create 'uploads.json'
 	if err = xl.writeUploadJSON(bucket, object, uploadID, initiated); err != nil {
+		xl.deleteObject(minioMetaBucket, tempUploadIDPath)
 		return "", err
 	}
-	uploadIDPath := path.Join(mpartMetaPrefix, bucket, object, uploadID)
-	tempUploadIDPath := path.Join(tmpMetaPrefix, uploadID)
-	// Write updated `xl.json` to all disks.
-	if err = writeSameXLMetadata(xl.storageDisks, minioMetaBucket, tempUploadIDPath, xlMeta, xl.writeQuorum, xl.readQuorum); err != nil {
-		return "", toObjectErr(err, minioMetaBucket, tempUploadIDPath)
-	}
 	rErr := renameObject(xl.storageDisks, minioMetaBucket, tempUploadIDPath, minioMetaBucket, uploadIDPath, xl.writeQuorum)
 	if rErr == nil {
 		// Return success.
@@ -388,14 +393,15 @@ func (xl xlObjects) PutObjectPart(bucket, object, uploadID string, partID int, s
 
 	lreader := data
 
-	// Initialize md5 writer.
-	md5Writer := md5.New()
+	// Initialize md5 writer, computing the ETag and the md5sum of
+	// Content-MD5.
+	md5Writer := newETagWriter(md5Hex)
 
 	writers := []io.Writer{md5Writer}
 
 	var sha256Writer hash.Hash
 	if sha256sum != "" {
-		sha256Writer = sha256.New()
+		sha256Writer = newSHA256()
 		writers = append(writers, sha256Writer)
 	}
 
@@ -429,13 +435,13 @@ func (xl xlObjects) PutObjectPart(bucket, object, uploadID string, partID int, s
 	}
 
 	// Calculate new md5sum.
-	newMD5Hex := hex.EncodeToString(md5Writer.Sum(nil))
+	newMD5Hex := md5Writer.ETag()
 	if md5Hex != "" {
-		if newMD5Hex != md5Hex {
+		if gotMD5Hex := md5Writer.MD5(); gotMD5Hex != md5Hex {
 			// MD5 mismatch, delete the temporary object.
 			xl.deleteObject(minioMetaBucket, tmpPartPath)
 			// Returns md5 mismatch.
-			return "", traceError(BadDigest{md5Hex, newMD5Hex})
+			return "", traceError(BadDigest{md5Hex, gotMD5Hex})
 		}
 	}
 
@@ -544,11 +550,7 @@ func (xl xlObjects) listObjectParts(bucket, object, uploadID string, partNumberM
 	}
 
 	// Only parts with higher part numbers will be listed.
-	partIdx := objectPartIndex(xlParts, partNumberMarker)
-	parts := xlParts
-	if partIdx != -1 {
-		parts = xlParts[partIdx+1:]
-	}
+	parts := getPartsAfterMarker(xlParts, partNumberMarker)
 	count := maxParts
 	for _, part := range parts {
 		var fi FileInfo
@@ -691,7 +693,7 @@ func (xl xlObjects) CompleteMultipartUpload(bucket string, object string, upload
 
 		// All parts should have same ETag as previously generated.
 		if currentXLMeta.Parts[partIdx].ETag != part.ETag {
-			return "", traceError(BadDigest{})
+			return "", traceError(InvalidPart{})
 		}
 
 		// All parts except the last part has to be atleast 5MB.
@@ -753,37 +755,6 @@ func (xl xlObjects) CompleteMultipartUpload(bucket string, object string, upload
 		return "", toObjectErr(rErr, minioMetaBucket, uploadIDPath)
 	}
 
-	// get a random ID for lock instrumentation.
-	opsID = getOpsID()
-
-	// Hold write lock on the destination before rename.
-	nsMutex.Lock(bucket, object, opsID)
-	defer func() {
-		// A new complete multipart upload invalidates any
-		// previously cached object in memory.
-		xl.objCache.Delete(path.Join(bucket, object))
-
-		// This lock also protects the cache namespace.
-		nsMutex.Unlock(bucket, object, opsID)
-
-		// Prefetch the object from disk by triggering a fake GetObject call
-		// Unlike a regular single PutObject,  multipart PutObject is comes in
-		// stages and it is harder to cache.
-		go xl.GetObject(bucket, object, 0, objectSize, ioutil.Discard)
-	}()
-
-	// Rename if an object already exists to temporary location.
-	uniqueID := getUUID()
-	if xl.isObject(bucket, object) {
-		// NOTE: Do not use online disks slice here.
-		// The reason is that existing object should be purged
-		// regardless of `xl.json` status and rolled back in case of errors.
-		err = renameObject(xl.storageDisks, bucket, object, minioMetaBucket, path.Join(tmpMetaPrefix, uniqueID), xl.writeQuorum)
-		if err != nil {
-			return "", toObjectErr(err, bucket, object)
-		}
-	}
-
 	// Remove parts that weren't present in CompleteMultipartUpload request.
 	for _, curpart := range currentXLMeta.Parts {
 		if objectPartIndex(xlMeta.Parts, curpart.Number) == -1 {
@@ -798,13 +769,10 @@ func (xl xlObjects) CompleteMultipartUpload(bucket string, object string, upload
 	}
 
 	// Rename the multipart object to final location.
-	if err = renameObject(onlineDisks, minioMetaBucket, uploadIDPath, bucket, object, xl.writeQuorum); err != nil {
-		return "", toObjectErr(err, bucket, object)
+	if err = xl.commitMultipartObject(onlineDisks, bucket, object, uploadIDPath, objectSize); err != nil {
+		return "", err
 	}
 
-	// Delete the previously successfully renamed object.
-	xl.deleteObject(minioMetaBucket, path.Join(tmpMetaPrefix, uniqueID))
-
 	// get a random ID for lock instrumentation.
 	opsID = getOpsID()
 
@@ -841,6 +809,52 @@ func (xl xlObjects) CompleteMultipartUpload(bucket string, object string, upload
 	return s3MD5, nil
 }
 
+// commitMultipartObject - renames the completed multipart upload at
+// uploadIDPath to its final location, replacing any existing object.
+// The write lock on the object is held only for the rename, the upload
+// itself is protected by the lock on its uploadID.
+func (xl xlObjects) commitMultipartObject(onlineDisks []StorageAPI, bucket, object, uploadIDPath string, objectSize int64) error {
+	// get a random ID for lock instrumentation.
+	opsID := getOpsID()
+
+	// Hold write lock on the destination before rename.
+	nsMutex.Lock(bucket, object, opsID)
+	defer func() {
+		// A new complete multipart upload invalidates any
+		// previously cached object in memory.
+		xl.objCache.Delete(path.Join(bucket, object))
+
+		// This lock also protects the cache namespace.
+		nsMutex.Unlock(bucket, object, opsID)
+
+		// Prefetch the object from disk by triggering a fake GetObject call
+		// Unlike a regular single PutObject,  multipart PutObject is comes in
+		// stages and it is harder to cache.
+		go xl.GetObject(bucket, object, 0, objectSize, ioutil.Discard)
+	}()
+
+	// Rename if an object already exists to temporary location.
+	uniqueID := getUUID()
+	if xl.isObject(bucket, object) {
+		// NOTE: Do not use online disks slice here.
+		// The reason is that existing object should be purged
+		// regardless of `xl.json` status and rolled back in case of errors.
+		err := renameObject(xl.storageDisks, bucket, object, minioMetaBucket, path.Join(tmpMetaPrefix, uniqueID), xl.writeQuorum)
+		if err != nil {
+			return toObjectErr(err, bucket, object)
+		}
+	}
+
+	// Rename the multipart object to final location.
+	if err := renameObject(onlineDisks, minioMetaBucket, uploadIDPath, bucket, object, xl.writeQuorum); err != nil {
+		return toObjectErr(err, bucket, object)
+	}
+
+	// Delete the previously successfully renamed object.
+	xl.deleteObject(minioMetaBucket, path.Join(tmpMetaPrefix, uniqueID))
+	return nil
+}
+
 // abortMultipartUpload - wrapper for purging an ongoing multipart
 // transaction, deletes uploadID entry from `uploads.json` and purges
 // the directory at '.minio.sys/multipart/bucket/object/uploadID' holding
@@ -0,0 +1,140 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Returns true if any operation is blocked on the lock of volume, path.
+func isLockBlocked(volume, path string) bool {
+	lockState, err := getSystemLockState()
+	if err != nil {
+		return false
+	}
+	for _, lockInfo := range lockState.LocksInfoPerObject {
+		if lockInfo.Bucket == volume && lockInfo.Object == path && lockInfo.TotalBlockedLocks > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// Returns the number of locks held or waited for on bucket, including
+// the locks on its multipart uploads.
+func getBucketLocks(bucket string) (locks int64) {
+	lockState, err := getSystemLockState()
+	if err != nil {
+		return 0
+	}
+	for _, lockInfo := range lockState.LocksInfoPerObject {
+		if lockInfo.Bucket == bucket || strings.HasPrefix(lockInfo.Object, pathJoin(mpartMetaPrefix, bucket)+"/") {
+			locks += lockInfo.LocksOnObject
+		}
+	}
+	return locks
+}
+
+// Runs fn and fails the test if it does not return in time.
+func runWithTimeout(t *testing.T, name string, fn func() error) {
+	done := make(chan error, 1)
+	go func() {
+		done <- fn()
+	}()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("%s failed with %s", name, err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("%s is blocked", name)
+	}
+}
+
+// Tests multipart uploads only hold the lock on the object while
+// the completed upload is renamed into place.
+func TestXLMultipartLockScope(t *testing.T) {
+	obj, fsDirs, err := prepareXL()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer removeRoots(fsDirs)
+
+	bucket, object := "bucket", "dir/object"
+	if err = obj.MakeBucket(bucket); err != nil {
+		t.Fatal(err)
+	}
+	uploadID, err := obj.NewMultipartUpload(bucket, object, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data := []byte("hello, world")
+	md5Sum, err := obj.PutObjectPart(bucket, object, uploadID, 1, int64(len(data)), bytes.NewReader(data), "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// No locks are held between the requests of an upload.
+	if locks := getBucketLocks(bucket); locks != 0 {
+		t.Fatalf("Expected no locks held on %s, got %d", bucket, locks)
+	}
+
+	// An upload in progress only holds its uploadID, other writes to
+	// the object and its siblings proceed.
+	uploadIDPath := pathJoin(mpartMetaPrefix, bucket, object, uploadID)
+	nsMutex.Lock(minioMetaBucket, uploadIDPath, "upload")
+	runWithTimeout(t, "PutObject", func() error {
+		_, err := obj.PutObject(bucket, object, int64(len(data)), bytes.NewReader(data), nil, "")
+		return err
+	})
+	runWithTimeout(t, "PutObject on sibling", func() error {
+		_, err := obj.PutObject(bucket, "dir/sibling", int64(len(data)), bytes.NewReader(data), nil, "")
+		return err
+	})
+	runWithTimeout(t, "NewMultipartUpload", func() error {
+		_, err := obj.NewMultipartUpload(bucket, object, nil)
+		return err
+	})
+	nsMutex.Unlock(minioMetaBucket, uploadIDPath, "upload")
+
+	// Complete releases the lock on the object before it updates
+	// `uploads.json`, block it there and write the object meanwhile.
+	uploadsPath := pathJoin(mpartMetaPrefix, bucket, object)
+	nsMutex.Lock(minioMetaBucket, uploadsPath, "uploads")
+	completeErr := make(chan error, 1)
+	go func() {
+		_, err := obj.CompleteMultipartUpload(bucket, object, uploadID, []completePart{{PartNumber: 1, ETag: md5Sum}})
+		completeErr <- err
+	}()
+	for i := 0; !isLockBlocked(minioMetaBucket, uploadsPath); i++ {
+		if i == 500 {
+			nsMutex.Unlock(minioMetaBucket, uploadsPath, "uploads")
+			t.Fatal("Expected complete multipart upload to block on uploads.json")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	runWithTimeout(t, "PutObject during complete", func() error {
+		_, err := obj.PutObject(bucket, object, int64(len(data)), bytes.NewReader(data), nil, "")
+		return err
+	})
+	nsMutex.Unlock(minioMetaBucket, uploadsPath, "uploads")
+	if err = <-completeErr; err != nil {
+		t.Fatalf("Complete multipart upload failed with %s", err)
+	}
+}
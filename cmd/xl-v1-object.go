@@ -17,8 +17,6 @@
 package cmd
 
 import (
-	"crypto/md5"
-	"crypto/sha256"
 	"encoding/hex"
 	"hash"
 	"io"
@@ -119,7 +117,7 @@ func (xl xlObjects) GetObject(bucket, object string, startOffset int64, length i
 	mw := writer
 
 	// Object cache enabled block.
-	if xlMeta.Stat.Size > 0 && xl.objCacheEnabled {
+	if xlMeta.Stat.Size > 0 && xl.objCacheEnabled && !isCacheBypassWriter(writer) {
 		// Validate if we have previous cache.
 		var cachedBuffer io.ReadSeeker
 		cachedBuffer, err = xl.objCache.Open(path.Join(bucket, object), modTime)
@@ -140,7 +138,7 @@ func (xl xlObjects) GetObject(bucket, object string, startOffset int64, length i
 		} // Cache has not been found, fill the cache.
 
 		// Cache is only set if whole object is being read.
-		if startOffset == 0 && length == xlMeta.Stat.Size {
+		if startOffset == 0 && length == xlMeta.Stat.Size && objCacheAdmits(bucket, length) {
 			// Proceed to set the cache.
 			var newBuffer io.WriteCloser
 			// Create a new entry in memory of length.
@@ -241,6 +239,30 @@ func (xl xlObjects) GetObjectInfo(bucket, object string) (ObjectInfo, error) {
 	return info, nil
 }
 
+// GetObjectParts - returns the individual parts recorded in `xl.json`
+// for an object, useful to validate multipart ETags on the client.
+func (xl xlObjects) GetObjectParts(bucket, object string) ([]objectPartInfo, error) {
+	// Verify if bucket is valid.
+	if !IsValidBucketName(bucket) {
+		return nil, traceError(BucketNameInvalid{Bucket: bucket})
+	}
+	// Verify if object is valid.
+	if !IsValidObjectName(object) {
+		return nil, traceError(ObjectNameInvalid{Bucket: bucket, Object: object})
+	}
+
+	// get a random ID for lock instrumentation.
+	opsID := getOpsID()
+
+	nsMutex.RLock(bucket, object, opsID)
+	defer nsMutex.RUnlock(bucket, object, opsID)
+	parts, err := xl.readXLMetaParts(bucket, object)
+	if err != nil {
+		return nil, toObjectErr(err, bucket, object)
+	}
+	return parts, nil
+}
+
 // getObjectInfo - wrapper for reading object metadata and constructs ObjectInfo.
 func (xl xlObjects) getObjectInfo(bucket, object string) (objInfo ObjectInfo, err error) {
 	// returns xl meta map and stat info.
@@ -386,20 +408,22 @@ func (xl xlObjects) PutObject(bucket string, object string, size int64, data io.
 	if metadata == nil {
 		metadata = make(map[string]string)
 	}
+	bypassCache := takeCacheBypass(metadata)
 
 	uniqueID := getUUID()
 	tempErasureObj := path.Join(tmpMetaPrefix, uniqueID, "part.1")
 	minioMetaTmpBucket := path.Join(minioMetaBucket, tmpMetaPrefix)
 	tempObj := uniqueID
 
-	// Initialize md5 writer.
-	md5Writer := md5.New()
+	// Initialize md5 writer, computing the ETag and the md5sum of
+	// Content-MD5.
+	md5Writer := newETagWriter(metadata["md5Sum"])
 
 	writers := []io.Writer{md5Writer}
 
 	var sha256Writer hash.Hash
 	if sha256sum != "" {
-		sha256Writer = sha256.New()
+		sha256Writer = newSHA256()
 		writers = append(writers, sha256Writer)
 	}
 
@@ -411,16 +435,18 @@ func (xl xlObjects) PutObject(bucket string, object string, size int64, data io.
 		// PutObject invalidates any previously cached object in memory.
 		xl.objCache.Delete(path.Join(bucket, object))
 
-		// Create a new entry in memory of size.
-		newBuffer, err = xl.objCache.Create(path.Join(bucket, object), size)
-		if err == nil {
-			// Create a multi writer to write to both memory and client response.
-			writers = append(writers, newBuffer)
-		}
-		// Ignore error if cache is full, proceed to write the object.
-		if err != nil && err != objcache.ErrCacheFull {
-			// For any other error return here.
-			return ObjectInfo{}, toObjectErr(traceError(err), bucket, object)
+		if objCacheAdmits(bucket, size) && !bypassCache {
+			// Create a new entry in memory of size.
+			newBuffer, err = xl.objCache.Create(path.Join(bucket, object), size)
+			if err == nil {
+				// Create a multi writer to write to both memory and client response.
+				writers = append(writers, newBuffer)
+			}
+			// Ignore error if cache is full, proceed to write the object.
+			if err != nil && err != objcache.ErrCacheFull {
+				// For any other error return here.
+				return ObjectInfo{}, toObjectErr(traceError(err), bucket, object)
+			}
 		}
 	}
 
@@ -469,11 +495,20 @@ func (xl xlObjects) PutObject(bucket string, object string, size int64, data io.
 	// Save additional erasureMetadata.
 	modTime := time.Now().UTC()
 
-	newMD5Hex := hex.EncodeToString(md5Writer.Sum(nil))
-	// Update the md5sum if not set with the newly calculated one.
-	if len(metadata["md5Sum"]) == 0 {
-		metadata["md5Sum"] = newMD5Hex
+	newMD5Hex := md5Writer.ETag()
+
+	// md5Hex representation.
+	md5Hex := metadata["md5Sum"]
+	if md5Hex != "" {
+		if gotMD5Hex := md5Writer.MD5(); gotMD5Hex != md5Hex {
+			// MD5 mismatch, delete the temporary object.
+			xl.deleteObject(minioMetaTmpBucket, tempObj)
+			// Returns md5 mismatch.
+			return ObjectInfo{}, traceError(BadDigest{md5Hex, gotMD5Hex})
+		}
 	}
+	// Update the md5sum with the newly calculated ETag.
+	metadata["md5Sum"] = newMD5Hex
 
 	// Guess content-type from the extension if possible.
 	if metadata["content-type"] == "" {
@@ -484,17 +519,6 @@ func (xl xlObjects) PutObject(bucket string, object string, size int64, data io.
 		}
 	}
 
-	// md5Hex representation.
-	md5Hex := metadata["md5Sum"]
-	if md5Hex != "" {
-		if newMD5Hex != md5Hex {
-			// MD5 mismatch, delete the temporary object.
-			xl.deleteObject(minioMetaTmpBucket, tempObj)
-			// Returns md5 mismatch.
-			return ObjectInfo{}, traceError(BadDigest{md5Hex, newMD5Hex})
-		}
-	}
-
 	if sha256sum != "" {
 		newSHA256sum := hex.EncodeToString(sha256Writer.Sum(nil))
 		if newSHA256sum != sha256sum {
@@ -658,3 +682,67 @@ func (xl xlObjects) DeleteObject(bucket, object string) (err error) {
 	// Success.
 	return nil
 }
+
+// UpdateObjectMetadata - calls update with the metadata of an object
+// under its lock, the metadata is saved on all the disks holding the
+// latest version of the object if update changed it. Object data is
+// left untouched.
+func (xl xlObjects) UpdateObjectMetadata(bucket, object string, update func(metadata map[string]string) (bool, error)) error {
+	// Verify if bucket is valid.
+	if !IsValidBucketName(bucket) {
+		return traceError(BucketNameInvalid{Bucket: bucket})
+	}
+	if !IsValidObjectName(object) {
+		return traceError(ObjectNameInvalid{Bucket: bucket, Object: object})
+	}
+
+	// get a random ID for lock instrumentation.
+	opsID := getOpsID()
+
+	nsMutex.Lock(bucket, object, opsID)
+	defer nsMutex.Unlock(bucket, object, opsID)
+
+	// Read metadata associated with the object from all disks.
+	partsMetadata, errs := readAllXLMetadata(xl.storageDisks, bucket, object)
+	// Do we have read quorum?
+	if !isDiskQuorum(errs, xl.readQuorum) {
+		return traceError(InsufficientReadQuorum{}, errs...)
+	}
+	if reducedErr := reduceErrs(errs, []error{
+		errDiskNotFound,
+		errFaultyDisk,
+		errDiskAccessDenied,
+	}); reducedErr != nil {
+		return toObjectErr(reducedErr, bucket, object)
+	}
+
+	// List all online disks, holding the latest version.
+	onlineDisks, modTime := listOnlineDisks(xl.storageDisks, partsMetadata, errs)
+
+	// Pick latest valid metadata.
+	xlMeta := pickValidXLMeta(partsMetadata, modTime)
+	metadata := make(map[string]string, len(xlMeta.Meta))
+	for k, v := range xlMeta.Meta {
+		metadata[k] = v
+	}
+	changed, err := update(metadata)
+	if err != nil || !changed {
+		return err
+	}
+	for index := range partsMetadata {
+		partsMetadata[index].Meta = metadata
+	}
+
+	// Write the updated `xl.json` to a temporary location and
+	// rename it over the current one.
+	tempXLMetaPath := path.Join(tmpMetaPrefix, getUUID())
+	if err = writeUniqueXLMetadata(onlineDisks, minioMetaBucket, tempXLMetaPath, partsMetadata, xl.writeQuorum); err != nil {
+		return toObjectErr(err, bucket, object)
+	}
+	err = renamePart(onlineDisks, minioMetaBucket, path.Join(tempXLMetaPath, xlMetaJSONFile), bucket, path.Join(object, xlMetaJSONFile), xl.writeQuorum)
+	xl.deleteObject(minioMetaBucket, tempXLMetaPath)
+	if err != nil {
+		return toObjectErr(err, bucket, object)
+	}
+	return nil
+}
@@ -364,3 +364,121 @@ func TestHealing(t *testing.T) {
 		t.Fatal(err)
 	}
 }
+
+// Waits for the background heal of a corrupted object to complete.
+func waitForVerifyObject(obj ObjectLayer, bucket, object string) error {
+	var err error
+	for i := 0; i < 100; i++ {
+		if err = obj.VerifyObject(bucket, object); err == nil {
+			return nil
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	return err
+}
+
+// Tests corrupted copies of objects are detected and healed.
+func TestXLVerifyObject(t *testing.T) {
+	obj, fsDirs, err := prepareXL()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer removeRoots(fsDirs)
+
+	bucket := "bucket"
+	object := "object"
+	if err = obj.MakeBucket(bucket); err != nil {
+		t.Fatal(err)
+	}
+	data := make([]byte, 1*1024*1024)
+	rand.Read(data)
+	if _, err = obj.PutObject(bucket, object, int64(len(data)), bytes.NewReader(data), nil, ""); err != nil {
+		t.Fatal(err)
+	}
+	if err = obj.VerifyObject(bucket, object); err != nil {
+		t.Fatalf("Expected intact object to be verified, got %v", err)
+	}
+
+	// Flip a byte of the part on one disk.
+	partPath := path.Join(fsDirs[1], bucket, object, "part.1")
+	part, err := ioutil.ReadFile(partPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	part[0] ^= 0xff
+	if err = ioutil.WriteFile(partPath, part, 0644); err != nil {
+		t.Fatal(err)
+	}
+	err = obj.VerifyObject(bucket, object)
+	if _, ok := errorCause(err).(ObjectCorrupted); !ok {
+		t.Fatalf("Expected ObjectCorrupted, got %v", err)
+	}
+
+	// Corrupted copy is healed in the background.
+	if err = waitForVerifyObject(obj, bucket, object); err != nil {
+		t.Fatalf("Expected corrupted object to be healed, got %v", err)
+	}
+	healed, err := ioutil.ReadFile(partPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	part[0] ^= 0xff
+	if !bytes.Equal(healed, part) {
+		t.Error("Expected the healed part to be restored")
+	}
+	var buffer bytes.Buffer
+	if err = obj.GetObject(bucket, object, 0, int64(len(data)), &buffer); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(buffer.Bytes(), data) {
+		t.Error("Expected object data to be unchanged")
+	}
+
+	// Missing objects can't be verified.
+	if err = obj.VerifyObject(bucket, "missing"); err == nil {
+		t.Error("Expected verifying a missing object to fail")
+	}
+}
+
+// Tests chunks of deduplicated objects are verified.
+func TestXLVerifyDedupObject(t *testing.T) {
+	obj, fsDirs, err := prepareXL()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer removeRoots(fsDirs)
+
+	bucket := "dedup-bucket"
+	object := "object"
+	if err = obj.MakeBucket(bucket); err != nil {
+		t.Fatal(err)
+	}
+	globalBucketSettings.SetBucketSettings(bucket, &bucketSettings{Dedup: true})
+	defer globalBucketSettings.SetBucketSettings(bucket, nil)
+
+	dedupObj := newDedupObjects(obj)
+	data := make([]byte, 1024)
+	rand.Read(data)
+	if _, err = dedupObj.PutObject(bucket, object, int64(len(data)), bytes.NewReader(data), nil, ""); err != nil {
+		t.Fatal(err)
+	}
+	if err = dedupObj.VerifyObject(bucket, object); err != nil {
+		t.Fatalf("Expected intact object to be verified, got %v", err)
+	}
+
+	manifest, err := readManifest(obj, bucket, object)
+	if err != nil {
+		t.Fatal(err)
+	}
+	partPath := path.Join(fsDirs[0], minioMetaBucket, dedupChunkPath(bucket, manifest.Chunks[0].Hash), "part.1")
+	if err = os.Remove(partPath); err != nil {
+		t.Fatal(err)
+	}
+	err = dedupObj.VerifyObject(bucket, object)
+	if oErr, ok := errorCause(err).(ObjectCorrupted); !ok || oErr.Bucket != bucket || oErr.Object != object {
+		t.Fatalf("Expected ObjectCorrupted of the object, got %v", err)
+	}
+	if err = waitForVerifyObject(dedupObj, bucket, object); err != nil {
+		t.Fatalf("Expected corrupted chunk to be healed, got %v", err)
+	}
+}
@@ -105,6 +105,27 @@ func healFormatXL(storageDisks []StorageAPI) error {
 	return nil
 }
 
+// getXLQuorum - returns the read and write quorum for totalDisks. The
+// defaults of N/2 and N/2+1 disks can be raised with MINIO_READ_QUORUM
+// and MINIO_WRITE_QUORUM, but never lowered since data blocks are N/2.
+func getXLQuorum(totalDisks int) (readQuorum, writeQuorum int, err error) {
+	readQuorum = totalDisks / 2
+	writeQuorum = totalDisks/2 + 1
+	if globalReadQuorum != 0 {
+		if globalReadQuorum < readQuorum || globalReadQuorum > totalDisks {
+			return 0, 0, errXLInvalidReadQuorum
+		}
+		readQuorum = globalReadQuorum
+	}
+	if globalWriteQuorum != 0 {
+		if globalWriteQuorum < writeQuorum || globalWriteQuorum > totalDisks {
+			return 0, 0, errXLInvalidWriteQuorum
+		}
+		writeQuorum = globalWriteQuorum
+	}
+	return readQuorum, writeQuorum, nil
+}
+
 // newXLObjects - initialize new xl object layer.
 func newXLObjects(storageDisks []StorageAPI) (ObjectLayer, error) {
 	if storageDisks == nil {
@@ -116,11 +137,13 @@ func newXLObjects(storageDisks []StorageAPI) (ObjectLayer, error) {
 		return nil, err
 	}
 
-	readQuorum := len(storageDisks) / 2
-	writeQuorum := len(storageDisks)/2 + 1
+	readQuorum, writeQuorum, err := getXLQuorum(len(storageDisks))
+	if err != nil {
+		return nil, err
+	}
 
 	// Load saved XL format.json and validate.
-	newStorageDisks, err := loadFormatXL(storageDisks, readQuorum)
+	newStorageDisks, err := loadFormatXL(storageDisks, len(storageDisks)/2)
 	if err != nil {
 		return nil, fmt.Errorf("Unable to recognize backend format, %s", err)
 	}
@@ -242,3 +265,12 @@ func (xl xlObjects) StorageInfo() StorageInfo {
 	storageInfo.Backend.WriteQuorum = xl.writeQuorum
 	return storageInfo
 }
+
+// CacheStats - returns usage and counters of the object cache, all
+// zero if the cache is disabled.
+func (xl xlObjects) CacheStats() (objcache.Stats, error) {
+	if !xl.objCacheEnabled {
+		return objcache.Stats{}, nil
+	}
+	return xl.objCache.Stats(), nil
+}
@@ -172,3 +172,56 @@ func TestNewXL(t *testing.T) {
 		t.Fatalf("Unable to initialize erasure, %s", err)
 	}
 }
+
+// TestGetXLQuorum - tests configured quorum is validated against the number of disks.
+func TestGetXLQuorum(t *testing.T) {
+	defer func() {
+		globalReadQuorum = 0
+		globalWriteQuorum = 0
+	}()
+
+	testCases := []struct {
+		readQuorum, writeQuorum                 int
+		expectedReadQuorum, expectedWriteQuorum int
+		expectedErr                             error
+	}{
+		// Defaults.
+		{0, 0, 8, 9, nil},
+		// Stronger than default quorum.
+		{10, 10, 10, 10, nil},
+		{16, 16, 16, 16, nil},
+		// Weaker than default quorum.
+		{7, 0, 0, 0, errXLInvalidReadQuorum},
+		{0, 8, 0, 0, errXLInvalidWriteQuorum},
+		// More disks than available.
+		{17, 0, 0, 0, errXLInvalidReadQuorum},
+		{0, 17, 0, 0, errXLInvalidWriteQuorum},
+	}
+	for i, testCase := range testCases {
+		globalReadQuorum = testCase.readQuorum
+		globalWriteQuorum = testCase.writeQuorum
+		readQuorum, writeQuorum, err := getXLQuorum(16)
+		if err != testCase.expectedErr {
+			t.Errorf("Test %d: Expected %v, got %v", i+1, testCase.expectedErr, err)
+		}
+		if readQuorum != testCase.expectedReadQuorum || writeQuorum != testCase.expectedWriteQuorum {
+			t.Errorf("Test %d: Expected quorum %d/%d, got %d/%d", i+1, testCase.expectedReadQuorum, testCase.expectedWriteQuorum, readQuorum, writeQuorum)
+		}
+	}
+
+	// Configured quorum is reported by the object layer.
+	globalReadQuorum = 10
+	globalWriteQuorum = 12
+	obj, fsDirs, err := prepareXL()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer removeRoots(fsDirs)
+	storageInfo := obj.StorageInfo()
+	if storageInfo.Backend.ReadQuorum != 10 || storageInfo.Backend.WriteQuorum != 12 {
+		t.Errorf("Expected quorum 10/12, got %d/%d", storageInfo.Backend.ReadQuorum, storageInfo.Backend.WriteQuorum)
+	}
+	if info := getQuorumInfo(obj); info != "Backend: XL | Disks: 16 | Read: 10 | Write: 12" {
+		t.Errorf("Unexpected quorum info %s", info)
+	}
+}
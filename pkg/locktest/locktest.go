@@ -0,0 +1,163 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package locktest provides helpers asserting the state of the
+// namespace locks, as reported by the lock instrumentation and
+// `minio control lock`, in tests of servers embedding them.
+package locktest
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Lock types reported by the lock instrumentation.
+const (
+	ReadLock  = "RLock"
+	WriteLock = "WLock"
+)
+
+// Lock statuses reported by the lock instrumentation.
+const (
+	StatusRunning = "Running"
+	StatusReady   = "Ready"
+	StatusBlocked = "Blocked"
+)
+
+// SystemState - lock state of a node, in the JSON layout of the lock
+// state reported by `minio control lock`.
+type SystemState struct {
+	TotalLocks         int64         `json:"totalLocks"`
+	TotalBlockedLocks  int64         `json:"totalBlockedLocks"`
+	TotalAcquiredLocks int64         `json:"totalAcquiredLocks"`
+	LocksInfoPerObject []ObjectState `json:"locksInfoPerObject"`
+	Error              string        `json:"error,omitempty"`
+}
+
+// ObjectState - lock state of a <volume, path> pair.
+type ObjectState struct {
+	Bucket                string           `json:"bucket"`
+	Object                string           `json:"object"`
+	LocksOnObject         int64            `json:"locksOnObject"`
+	LocksAcquiredOnObject int64            `json:"locksAcquiredOnObject"`
+	TotalBlockedLocks     int64            `json:"locksBlockedOnObject"`
+	LockDetailsOnObject   []OperationState `json:"lockDetailsOnObject"`
+}
+
+// OperationState - lock state of an operation.
+type OperationState struct {
+	OperationID string        `json:"opsID"`
+	LockOrigin  string        `json:"lockOrigin"`
+	LockType    string        `json:"lockType"`
+	Status      string        `json:"status"`
+	Since       time.Time     `json:"statusSince"`
+	Duration    time.Duration `json:"statusDuration"`
+}
+
+// ParseState - converts v to a SystemState, v is either the JSON of a
+// lock state or a value marshalling to it, like the lock state replied
+// by the Control.LockInfo RPC.
+func ParseState(v interface{}) (SystemState, error) {
+	buf, ok := v.([]byte)
+	if !ok {
+		var err error
+		if buf, err = json.Marshal(v); err != nil {
+			return SystemState{}, err
+		}
+	}
+	state := SystemState{}
+	err := json.Unmarshal(buf, &state)
+	return state, err
+}
+
+// Case - expected lock state after an operation locked a <volume,
+// path> pair.
+type Case struct {
+	Volume string
+	Path   string
+	OpsID  string
+	// Expected lock type and status of the operation.
+	LockType string
+	Status   string
+
+	// Expected counts of all the locks of the node, blocked and
+	// running ones.
+	GlobalLocks  int
+	BlockedLocks int
+	RunningLocks int
+
+	// Expected counts of the locks of the <volume, path> pair.
+	PathLocks   int
+	PathBlocked int
+	PathRunning int
+}
+
+// TestingT - the subset of *testing.T used to report failures.
+type TestingT interface {
+	Errorf(format string, args ...interface{})
+}
+
+// VerifyCounts - asserts the counts of all the locks of state.
+func VerifyCounts(t TestingT, testNum int, c Case, state SystemState) {
+	if state.TotalLocks != int64(c.GlobalLocks) {
+		t.Errorf("Test %d: Expected the global lock counter to be %v, but got %v", testNum, c.GlobalLocks, state.TotalLocks)
+	}
+	if state.TotalBlockedLocks != int64(c.BlockedLocks) {
+		t.Errorf("Test %d: Expected the total blocked lock counter to be %v, but got %v", testNum, c.BlockedLocks, state.TotalBlockedLocks)
+	}
+	if state.TotalAcquiredLocks != int64(c.RunningLocks) {
+		t.Errorf("Test %d: Expected the total running lock counter to be %v, but got %v", testNum, c.RunningLocks, state.TotalAcquiredLocks)
+	}
+}
+
+// Verify - asserts the counts of all the locks of state, and the
+// counts and the operation of the <volume, path> pair of c.
+func Verify(t TestingT, testNum int, c Case, state SystemState) {
+	VerifyCounts(t, testNum, c, state)
+
+	for _, object := range state.LocksInfoPerObject {
+		if object.Bucket != c.Volume || object.Object != c.Path {
+			continue
+		}
+		if object.LocksOnObject != int64(c.PathLocks) {
+			t.Errorf("Test %d: Expected the total lock count for bucket: \"%s\", object: \"%s\" to be %v, but got %v", testNum,
+				c.Volume, c.Path, c.PathLocks, object.LocksOnObject)
+		}
+		if object.LocksAcquiredOnObject != int64(c.PathRunning) {
+			t.Errorf("Test %d: Expected the acquired lock count for bucket: \"%s\", object: \"%s\" to be %v, but got %v", testNum,
+				c.Volume, c.Path, c.PathRunning, object.LocksAcquiredOnObject)
+		}
+		if object.TotalBlockedLocks != int64(c.PathBlocked) {
+			t.Errorf("Test %d: Expected the blocked lock count for bucket: \"%s\", object: \"%s\" to be %v, but got %v", testNum,
+				c.Volume, c.Path, c.PathBlocked, object.TotalBlockedLocks)
+		}
+		for _, op := range object.LockDetailsOnObject {
+			if op.OperationID != c.OpsID {
+				continue
+			}
+			if op.LockType != c.LockType {
+				t.Errorf("Test %d: Expected the lock type to be \"%s\", got \"%s\"", testNum, c.LockType, op.LockType)
+			}
+			if op.Status != c.Status {
+				t.Errorf("Test %d: Expected the status of the operation to be \"%s\", got \"%s\"", testNum, c.Status, op.Status)
+			}
+			return
+		}
+		t.Errorf("Test %d: Entry for OpsId: \"%s\" not found in <bucket>: \"%s\", <path>: \"%s\"", testNum, c.OpsID, c.Volume, c.Path)
+		return
+	}
+	t.Errorf("Test %d: Entry for <bucket>: \"%s\", <object>: \"%s\" doesn't exist", testNum, c.Volume, c.Path)
+}
@@ -0,0 +1,121 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package locktest
+
+import (
+	"fmt"
+	"testing"
+)
+
+// Records the failures reported by the helpers.
+type recorder struct {
+	failures []string
+}
+
+func (r *recorder) Errorf(format string, args ...interface{}) {
+	r.failures = append(r.failures, fmt.Sprintf(format, args...))
+}
+
+// Lock state in the JSON layout of `minio control lock`.
+const testStateJSON = `{
+	"totalLocks": 2,
+	"totalBlockedLocks": 1,
+	"totalAcquiredLocks": 1,
+	"locksInfoPerObject": [{
+		"bucket": "my-bucket",
+		"object": "my-object",
+		"locksOnObject": 2,
+		"locksAcquiredOnObject": 1,
+		"locksBlockedOnObject": 1,
+		"lockDetailsOnObject": [
+			{"opsID": "abcd1234", "lockOrigin": "[lock held] in github.com/minio/minio/cmd.(*xlObjects).GetObject[xl-v1-object.go:116]", "lockType": "RLock", "status": "Running"},
+			{"opsID": "efgh5678", "lockOrigin": "[lock held] in github.com/minio/minio/cmd.(*xlObjects).GetObject[xl-v1-object.go:116]", "lockType": "WLock", "status": "Blocked"}
+		]
+	}]
+}`
+
+// Tests parsing lock states from JSON and from values marshalling to it.
+func TestParseState(t *testing.T) {
+	state, err := ParseState([]byte(testStateJSON))
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	if state.TotalLocks != 2 || len(state.LocksInfoPerObject) != 1 || len(state.LocksInfoPerObject[0].LockDetailsOnObject) != 2 {
+		t.Fatalf("Unexpected lock state %+v", state)
+	}
+
+	reparsed, err := ParseState(&state)
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	if reparsed.LocksInfoPerObject[0].LockDetailsOnObject[1].Status != StatusBlocked {
+		t.Fatalf("Unexpected lock state %+v", reparsed)
+	}
+
+	if _, err = ParseState([]byte("{")); err == nil {
+		t.Fatal("Expected invalid JSON to fail")
+	}
+}
+
+// Tests the helpers report exactly the mismatching expectations.
+func TestVerify(t *testing.T) {
+	state, err := ParseState([]byte(testStateJSON))
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	matching := Case{
+		Volume:       "my-bucket",
+		Path:         "my-object",
+		OpsID:        "efgh5678",
+		LockType:     WriteLock,
+		Status:       StatusBlocked,
+		GlobalLocks:  2,
+		BlockedLocks: 1,
+		RunningLocks: 1,
+		PathLocks:    2,
+		PathBlocked:  1,
+		PathRunning:  1,
+	}
+
+	testCases := []struct {
+		update   func(c *Case)
+		failures int
+	}{
+		// All expectations match.
+		{func(c *Case) {}, 0},
+		// Other operation on the same pair.
+		{func(c *Case) { c.OpsID, c.LockType, c.Status = "abcd1234", ReadLock, StatusRunning }, 0},
+		// Wrong global count.
+		{func(c *Case) { c.GlobalLocks = 3 }, 1},
+		// Wrong path counts.
+		{func(c *Case) { c.PathLocks, c.PathRunning = 1, 0 }, 2},
+		// Wrong lock type and status.
+		{func(c *Case) { c.LockType, c.Status = ReadLock, StatusRunning }, 2},
+		// Unknown operation.
+		{func(c *Case) { c.OpsID = "unknown" }, 1},
+		// Unknown pair.
+		{func(c *Case) { c.Path = "other-object" }, 1},
+	}
+	for i, testCase := range testCases {
+		c := matching
+		testCase.update(&c)
+		r := &recorder{}
+		Verify(r, i+1, c, state)
+		if len(r.failures) != testCase.failures {
+			t.Errorf("Test %d: Expected %d failures, got %v", i+1, testCase.failures, r.failures)
+		}
+	}
+}
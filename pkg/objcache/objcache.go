@@ -58,6 +58,9 @@ type Cache struct {
 	// totalEvicted counter to keep track of total expirys
 	totalEvicted int
 
+	// Counters reported by Stats.
+	hits, misses, evictions, rejections uint64
+
 	// map of objectName and its contents
 	entries map[string]*buffer
 
@@ -97,6 +100,38 @@ var ErrCacheFull = errors.New("Not enough space in cache")
 // ErrExcessData - excess data was attempted to be written on cache.
 var ErrExcessData = errors.New("Attempted excess write on cache")
 
+// Stats - usage and effectiveness counters of a cache.
+type Stats struct {
+	// Total size allowed for the cache and size currently used.
+	MaxSize     uint64
+	CurrentSize uint64
+	// Number of entries in the cache.
+	Entries int
+	// Lookups served from the cache and lookups which were not.
+	Hits   uint64
+	Misses uint64
+	// Entries removed because they expired or became stale, entries
+	// deleted explicitly are not counted.
+	Evictions uint64
+	// Entries not saved for lack of space.
+	Rejections uint64
+}
+
+// Stats - returns the current usage and counters of the cache.
+func (c *Cache) Stats() Stats {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return Stats{
+		MaxSize:     c.maxSize,
+		CurrentSize: c.currentSize,
+		Entries:     len(c.entries),
+		Hits:        c.hits,
+		Misses:      c.misses,
+		Evictions:   c.evictions,
+		Rejections:  c.rejections,
+	}
+}
+
 // Used for adding entry to the object cache. Implements io.WriteCloser
 type cacheBuffer struct {
 	*bytes.Buffer // Implements io.Writer
@@ -125,6 +160,9 @@ func (c *Cache) Create(key string, size int64) (w io.WriteCloser, err error) {
 	valueLen := uint64(size)
 	// Check if the size of the object is not bigger than the capacity of the cache.
 	if c.maxSize > 0 && valueLen > c.maxSize {
+		c.mutex.Lock()
+		c.rejections++
+		c.mutex.Unlock()
 		return nil, ErrCacheFull
 	}
 
@@ -141,6 +179,7 @@ func (c *Cache) Create(key string, size int64) (w io.WriteCloser, err error) {
 			return io.ErrShortBuffer
 		}
 		if c.maxSize > 0 && c.currentSize+valueLen > c.maxSize {
+			c.rejections++
 			return ErrExcessData
 		}
 		// Full object available in buf, save it to cache.
@@ -171,13 +210,17 @@ func (c *Cache) Open(key string, objModTime time.Time) (io.ReadSeeker, error) {
 	defer c.mutex.Unlock()
 	buf, ok := c.entries[key]
 	if !ok {
+		c.misses++
 		return nil, ErrKeyNotFoundInCache
 	}
 	// Check if buf is recent copy of the object on disk.
 	if buf.lastAccessed.Before(objModTime) {
 		c.delete(key)
+		c.misses++
+		c.evictions++
 		return nil, ErrKeyNotFoundInCache
 	}
+	c.hits++
 	buf.lastAccessed = time.Now().UTC()
 	return bytes.NewReader(buf.value), nil
 }
@@ -199,6 +242,7 @@ func (c *Cache) gc() {
 	for k, v := range c.entries {
 		if c.expiry > 0 && time.Now().UTC().Sub(v.lastAccessed) > c.expiry {
 			c.delete(k)
+			c.evictions++
 			evictedEntries = append(evictedEntries, k)
 		}
 	}
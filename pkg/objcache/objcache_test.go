@@ -262,3 +262,55 @@ func TestStaleEntryPurge(t *testing.T) {
 		t.Errorf("Test case expected to return ErrKeyNotFoundInCache, instead returned %s", err)
 	}
 }
+
+// TestCacheStats - tests if hits, misses, evictions and rejections are accounted.
+func TestCacheStats(t *testing.T) {
+	cache := New(10, NoExpiry)
+	w, err := cache.Create("test", 5)
+	if err != nil {
+		t.Fatalf("Unable to create cache entry, %s", err)
+	}
+	w.Write([]byte("Hello"))
+	if err = w.Close(); err != nil {
+		t.Fatalf("Unable to save cache entry, %s", err)
+	}
+
+	// One hit.
+	if _, err = cache.Open("test", time.Time{}); err != nil {
+		t.Fatalf("Expected cache hit, got %s", err)
+	}
+	// One miss.
+	if _, err = cache.Open("missing", time.Time{}); err != ErrKeyNotFoundInCache {
+		t.Fatalf("Expected ErrKeyNotFoundInCache, got %v", err)
+	}
+	// Object larger than the cache is rejected.
+	if _, err = cache.Create("large", 11); err != ErrCacheFull {
+		t.Fatalf("Expected ErrCacheFull, got %v", err)
+	}
+
+	stats := cache.Stats()
+	expected := Stats{
+		MaxSize:     10,
+		CurrentSize: 5,
+		Entries:     1,
+		Hits:        1,
+		Misses:      1,
+		Rejections:  1,
+	}
+	if stats != expected {
+		t.Fatalf("Expected %+v, got %+v", expected, stats)
+	}
+
+	// Stale entry counts as a miss and an eviction.
+	if _, err = cache.Open("test", time.Now().AddDate(0, 0, 1).UTC()); err != ErrKeyNotFoundInCache {
+		t.Fatalf("Expected ErrKeyNotFoundInCache, got %v", err)
+	}
+	stats = cache.Stats()
+	expected.CurrentSize = 0
+	expected.Entries = 0
+	expected.Misses = 2
+	expected.Evictions = 1
+	if stats != expected {
+		t.Fatalf("Expected %+v, got %+v", expected, stats)
+	}
+}